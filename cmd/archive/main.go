@@ -0,0 +1,200 @@
+// Command archive exports articles from a running node as a CARv1 archive
+// and imports archives into another node, using the node's HTTP API.
+//
+//	archive export -ids a,b -o news.car
+//	archive export -author alice -from 2024-01-01T00:00:00Z -o alice.car
+//	archive import news.car
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const usage = `Usage:
+  archive export [flags]          export articles to a CAR file
+  archive import [flags] <file>   import a CAR file (requires an admin token)
+
+Run "archive <command> -h" for command flags.
+`
+
+// client talks to a node's HTTP API
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = runExport(os.Args[2:])
+	case "import":
+		err = runImport(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// commonFlags registers the connection flags shared by every command
+func commonFlags(fs *flag.FlagSet) (*string, *string) {
+	server := fs.String("server", envOr("NEWS_SERVER", "http://localhost:12345"), "node API address")
+	token := fs.String("token", os.Getenv("NEWS_TOKEN"), "JWT access token (or NEWS_TOKEN)")
+	return server, token
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	server, token := commonFlags(fs)
+	ids := fs.String("ids", "", "comma-separated article IDs")
+	author := fs.String("author", "", "export articles by this author")
+	tags := fs.String("tags", "", "comma-separated tags to filter by")
+	from := fs.String("from", "", "earliest creation time (RFC3339)")
+	to := fs.String("to", "", "latest creation time (RFC3339)")
+	out := fs.String("o", "", "output file (default newsp2p-<root>.car)")
+	fs.Parse(args)
+
+	query := url.Values{}
+	for key, value := range map[string]string{"ids": *ids, "author": *author, "tags": *tags, "from": *from, "to": *to} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	c := newClient(*server, *token)
+	resp, err := c.do(http.MethodGet, "/api/v1/archive/export?"+query.Encode(), "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	root := resp.Header.Get("X-Archive-Root")
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("newsp2p-%s.car", root)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	fmt.Printf("Exported %s articles (root %s, %d bytes) to %s\n",
+		resp.Header.Get("X-Archive-Articles"), root, n, path)
+	return nil
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	server, token := commonFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import takes exactly one CAR file")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	c := newClient(*server, *token)
+	resp, err := c.do(http.MethodPost, "/api/v1/admin/archive/import", "application/vnd.ipld.car", file)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Data domain.ArchiveImportReport `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	report := body.Data
+	fmt.Printf("Roots:    %s\n", strings.Join(report.Roots, ", "))
+	fmt.Printf("Imported: %d\n", len(report.Imported))
+	fmt.Printf("Skipped:  %d (already present)\n", len(report.Skipped))
+	if len(report.Failed) > 0 {
+		fmt.Printf("Failed:   %s\n", strings.Join(report.Failed, ", "))
+	}
+	for _, warning := range report.Warnings {
+		fmt.Printf("Warning:  %s\n", warning)
+	}
+	return nil
+}
+
+func newClient(server, token string) *client {
+	return &client{
+		server: strings.TrimRight(server, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// do sends a request and turns non-2xx responses into errors
+func (c *client) do(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error == "" {
+			apiErr.Error = resp.Status
+		}
+		return nil, fmt.Errorf("%s %s: %s", method, path, apiErr.Error)
+	}
+	return resp, nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
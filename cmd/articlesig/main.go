@@ -0,0 +1,379 @@
+// Command articlesig signs articles with a local key and verifies article
+// signatures without running a node. Articles named by CID are fetched
+// from an IPFS gateway, block by block, and checked against the CID, so
+// neither the gateway nor any node has to be trusted.
+//
+//	articlesig sign -key alice.json -o signed.json draft.json
+//	articlesig verify signed.json
+//	articlesig verify -author-key did:key:... bafkrei...
+//	articlesig verify -ipfs-api http://localhost:5001 bafkrei...
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ipfs/go-cid"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+const usage = `Usage:
+  articlesig sign [flags] <article.json|->        sign an article with a key file
+  articlesig verify [flags] <file|cid|->...       verify article signatures
+
+Run "articlesig <command> -h" for command flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "sign":
+		err = runSign(os.Args[2:])
+	case "verify":
+		err = runVerify(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "key file to sign with, as written by keygen (asks for its passphrase, or NEWS_KEY_PASSPHRASE)")
+	author := fs.String("author", "", "author name (default the key file's username, then the article's)")
+	out := fs.String("o", "", "file to write the signed article to (default stdout)")
+	fs.Parse(args)
+	if *keyPath == "" || fs.NArg() != 1 {
+		return fmt.Errorf("sign takes -key and exactly one article file")
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	article, err := domain.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("%s is not an article: %w", fs.Arg(0), err)
+	}
+
+	file, err := crypto.ReadKeyFile(*keyPath)
+	if err != nil {
+		return err
+	}
+	var passphrase string
+	if file.Encrypted() {
+		if passphrase, err = secret("NEWS_KEY_PASSPHRASE", "Passphrase for "+*keyPath+": "); err != nil {
+			return err
+		}
+	}
+	privateKey, err := file.Key(passphrase)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *keyPath, err)
+	}
+
+	switch {
+	case *author != "":
+		article.Author = *author
+	case file.Username != "":
+		article.Author = file.Username
+	}
+	// Fill in what the node would for a new article; fields already set
+	// are kept so an existing article can be re-signed
+	now := time.Now()
+	if article.ID == "" {
+		article.ID = uuid.New().String()
+	}
+	if article.Timestamp.IsZero() {
+		article.Timestamp = now
+	}
+	if article.Version == 0 {
+		article.Version = 1
+	}
+	if article.CreatedAt.IsZero() {
+		article.CreatedAt = now
+	}
+	article.UpdatedAt = now
+	article.AuthorPubKey = crypto.PublicKeyToString(privateKey.Public().(ed25519.PublicKey))
+	// The CID names the stored content, which changes with the signature
+	article.CID = ""
+	article.NodeCID = ""
+
+	if err := article.Validate(); err != nil {
+		return err
+	}
+	if err := auth.NewArticleSigner().SignArticle(article, privateKey); err != nil {
+		return err
+	}
+
+	signed, err := json.MarshalIndent(article, "", "  ")
+	if err != nil {
+		return err
+	}
+	signed = append(signed, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(signed)
+		return err
+	}
+	if err := os.WriteFile(*out, signed, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Signed %q as %s (%s)\n", article.Title, article.Author, crypto.DIDKey(privateKey.Public().(ed25519.PublicKey)))
+	return nil
+}
+
+// verification is the outcome of checking one article
+type verification struct {
+	Source string `json:"source"`
+	// CID is set when the article was fetched by CID, in which case its
+	// content was checked against it
+	CID         string `json:"cid,omitempty"`
+	Valid       bool   `json:"valid"`
+	Title       string `json:"title,omitempty"`
+	Author      string `json:"author,omitempty"`
+	PublicKey   string `json:"public_key,omitempty"`
+	DID         string `json:"did,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// verifier checks articles from files or fetched by CID
+type verifier struct {
+	gateway   *ipfs.Gateway
+	daemon    *ipfs.Client
+	authorKey ed25519.PublicKey
+}
+
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	gateway := fs.String("gateway", "https://ipfs.io", "IPFS gateway to fetch CIDs from")
+	ipfsAPI := fs.String("ipfs-api", "", "fetch CIDs through this IPFS daemon API instead of the gateway")
+	timeout := fs.Duration("timeout", time.Minute, "how long to wait for each article fetched by CID")
+	authorKey := fs.String("author-key", "", "also require articles to be signed by this key (base64 public key or did:key)")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("verify takes at least one article file or CID")
+	}
+
+	v := &verifier{}
+	if *authorKey != "" {
+		key, err := parsePublicKey(*authorKey)
+		if err != nil {
+			return fmt.Errorf("-author-key: %w", err)
+		}
+		v.authorKey = key
+	}
+	if *ipfsAPI != "" {
+		log, err := logger.New("error", "console")
+		if err != nil {
+			return err
+		}
+		v.daemon = ipfs.NewClient(*ipfsAPI, *timeout, false, log)
+	} else {
+		v.gateway = ipfs.NewGateway(*gateway, *timeout)
+	}
+
+	var results []verification
+	failed := 0
+	for _, source := range fs.Args() {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		r := v.verify(ctx, source)
+		cancel()
+		if !r.Valid {
+			failed++
+		}
+		results = append(results, r)
+		if !*asJSON {
+			printVerification(r)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d articles failed verification", failed, len(results))
+	}
+	return nil
+}
+
+func (v *verifier) verify(ctx context.Context, source string) verification {
+	r := verification{Source: source}
+	article, err := v.load(ctx, source, &r)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Title = article.Title
+	r.Author = article.Author
+
+	publicKey, err := crypto.PublicKeyFromString(article.AuthorPubKey)
+	if err != nil {
+		r.Error = fmt.Sprintf("article carries no usable author key: %v", err)
+		return r
+	}
+	r.PublicKey = article.AuthorPubKey
+	r.DID = crypto.DIDKey(publicKey)
+	r.Fingerprint = crypto.Fingerprint(publicKey)
+
+	if err := auth.NewArticleSigner().VerifyArticle(article); err != nil {
+		if errors.Is(err, domain.ErrInvalidSignature) {
+			err = errors.New("signature does not match the content; the article was altered or signed by another key")
+		}
+		r.Error = err.Error()
+		return r
+	}
+	if v.authorKey != nil && !publicKey.Equal(v.authorKey) {
+		r.Error = "validly signed, but by " + r.DID + ", not the expected author key"
+		return r
+	}
+	r.Valid = true
+	return r
+}
+
+// load reads an article from a file, or from IPFS when source is a CID
+// and no such file exists
+func (v *verifier) load(ctx context.Context, source string, r *verification) (*domain.Article, error) {
+	data, err := readInput(source)
+	if errors.Is(err, os.ErrNotExist) {
+		if strings.HasPrefix(source, "local-") {
+			return nil, fmt.Errorf("%s was stored without IPFS and can only be read from the node that holds it", source)
+		}
+		if _, parseErr := cid.Decode(strings.TrimPrefix(source, "/ipfs/")); parseErr != nil {
+			return nil, fmt.Errorf("%s is neither a file nor a CID", source)
+		}
+		r.CID = strings.TrimPrefix(source, "/ipfs/")
+		data, err = v.fetch(ctx, r.CID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := parseArticle(data)
+	if err != nil {
+		return nil, err
+	}
+	if r.CID != "" {
+		article.CID = r.CID
+	}
+	return article, nil
+}
+
+func (v *verifier) fetch(ctx context.Context, ref string) ([]byte, error) {
+	if v.daemon != nil {
+		return v.daemon.Cat(ctx, ref)
+	}
+	return v.gateway.Cat(ctx, ref)
+}
+
+// parseArticle accepts an article as stored in IPFS or as returned by the
+// API, wrapped in its response envelope
+func parseArticle(data []byte) (*domain.Article, error) {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(data, &envelope) == nil && len(envelope.Data) > 0 && envelope.Data[0] == '{' {
+		data = envelope.Data
+	}
+	article, err := domain.FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("not an article: %w", err)
+	}
+	if article.Signature == "" {
+		return nil, errors.New("article is not signed")
+	}
+	return article, nil
+}
+
+func printVerification(r verification) {
+	if !r.Valid {
+		fmt.Printf("FAIL  %s\n      %s\n", r.Source, r.Error)
+		if r.DID != "" {
+			fmt.Printf("      claimed signer %s (%s)\n", r.DID, r.Fingerprint)
+		}
+		return
+	}
+	fmt.Printf("OK    %s\n", r.Source)
+	fmt.Printf("      %q by %s\n", r.Title, r.Author)
+	fmt.Printf("      signed by %s\n", r.DID)
+	fmt.Printf("      fingerprint %s\n", r.Fingerprint)
+	if r.CID != "" {
+		fmt.Printf("      content matches %s\n", r.CID)
+	}
+}
+
+// parsePublicKey accepts a base64 public key or a did:key identifier
+func parsePublicKey(s string) (ed25519.PublicKey, error) {
+	if strings.HasPrefix(s, "did:") {
+		return crypto.PublicKeyFromDID(s)
+	}
+	return crypto.PublicKeyFromString(s)
+}
+
+// readInput reads a file, or stdin for "-"
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// stdin is shared by the article reader and the passphrase prompt
+var stdin = bufio.NewReader(os.Stdin)
+
+// secret reads a passphrase from env, else from the terminal with echo
+// turned off. Where stty isn't available the line is read as typed.
+func secret(env, prompt string) (string, error) {
+	if value := os.Getenv(env); value != "" {
+		return value, nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
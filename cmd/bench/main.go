@@ -0,0 +1,670 @@
+// Command bench measures how articles spread through a network of
+// in-process nodes, so protocol changes can be compared before release.
+// Every node runs the real broadcaster, sync service and article store,
+// connected over an in-memory libp2p network instead of sockets.
+//
+//	bench
+//	bench -nodes 50 -degree 4 -articles 500 -rate 50 -latency 20ms
+//	bench -late 3 -sync-interval 5s -json
+//
+// It publishes synthetic signed articles from a few nodes at a target
+// rate, then reports how long gossip took to deliver them, how long the
+// network took to converge, how long nodes that join afterwards take to
+// catch up through sync, and what the run cost in CPU and memory.
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// options are the command line flags
+type options struct {
+	nodes        int
+	degree       int
+	late         int
+	publishers   int
+	articles     int
+	rate         float64
+	latency      time.Duration
+	syncInterval time.Duration
+	timeout      time.Duration
+	seed         int64
+	asJSON       bool
+}
+
+func main() {
+	var opts options
+	flag.IntVar(&opts.nodes, "nodes", 10, "nodes in the network while articles are published")
+	flag.IntVar(&opts.degree, "degree", 3, "peers each node connects to")
+	flag.IntVar(&opts.late, "late", 2, "nodes that join after publishing and must catch up through sync")
+	flag.IntVar(&opts.publishers, "publishers", 3, "nodes that publish articles, in turn")
+	flag.IntVar(&opts.articles, "articles", 40, "synthetic articles to publish")
+	flag.Float64Var(&opts.rate, "rate", 10, "articles published per second")
+	flag.DurationVar(&opts.latency, "latency", 0, "one-way latency of every link")
+	flag.DurationVar(&opts.syncInterval, "sync-interval", 10*time.Second, "time between sync rounds on each node")
+	flag.DurationVar(&opts.timeout, "timeout", 2*time.Minute, "how long to wait for the network to converge")
+	flag.Int64Var(&opts.seed, "seed", 0, "topology seed, for repeatable runs (default random)")
+	flag.BoolVar(&opts.asJSON, "json", false, "print the report as JSON")
+	flag.Parse()
+
+	if err := opts.check(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	if opts.seed == 0 {
+		opts.seed = time.Now().UnixNano()
+	}
+
+	report, err := run(context.Background(), &opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	if opts.asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		report.print()
+	}
+	if !report.Converged {
+		os.Exit(1)
+	}
+}
+
+func (o *options) check() error {
+	switch {
+	case o.nodes < 2:
+		return errors.New("-nodes must be at least 2")
+	case o.degree < 1 || o.degree >= o.nodes:
+		return errors.New("-degree must be between 1 and -nodes minus 1")
+	case o.late < 0:
+		return errors.New("-late must not be negative")
+	case o.publishers < 1 || o.publishers > o.nodes:
+		return errors.New("-publishers must be between 1 and -nodes")
+	case o.articles < 1:
+		return errors.New("-articles must be at least 1")
+	case o.rate <= 0:
+		return errors.New("-rate must be positive")
+	case o.syncInterval <= 0:
+		return errors.New("-sync-interval must be positive")
+	}
+	return nil
+}
+
+// arrival is when, and by which path, a node first stored an article
+type arrival struct {
+	at  time.Time
+	via string // "gossip" or "sync"
+}
+
+// recorder collects publish and arrival times from every node
+type recorder struct {
+	mu        sync.Mutex
+	published map[string]time.Time       // article ID -> broadcast time
+	arrivals  map[string]map[int]arrival // article ID -> node -> first arrival
+	counts    map[int]int                // node -> articles stored
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		published: make(map[string]time.Time),
+		arrivals:  make(map[string]map[int]arrival),
+		counts:    make(map[int]int),
+	}
+}
+
+func (r *recorder) publish(id string, node int, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.published[id] = at
+	r.arrivals[id] = map[int]arrival{node: {at: at, via: "local"}}
+	r.counts[node]++
+}
+
+func (r *recorder) arrive(id string, node int, via string) {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nodes, ok := r.arrivals[id]
+	if !ok {
+		// Gossip can outrun the publisher's own bookkeeping
+		nodes = make(map[int]arrival)
+		r.arrivals[id] = nodes
+	}
+	if _, seen := nodes[node]; seen {
+		return
+	}
+	nodes[node] = arrival{at: now, via: via}
+	r.counts[node]++
+}
+
+// complete reports whether every node in nodes holds all total articles
+func (r *recorder) complete(nodes []int, total int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range nodes {
+		if r.counts[n] < total {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *recorder) count(node int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[node]
+}
+
+// benchNode is one node of the network, wired like the server wires its
+// P2P side
+type benchNode struct {
+	index       int
+	node        *p2p.P2PNode
+	broadcaster *p2p.Broadcaster
+	sync        *p2p.SyncService
+	articles    *service.ArticleService
+	db          *badger.DB
+}
+
+// recordingReceiver stores articles through the article service and
+// records the first time each one reached the node
+type recordingReceiver struct {
+	n   *benchNode
+	rec *recorder
+	via string
+}
+
+func (r *recordingReceiver) HandleIncomingArticle(article *domain.Article) error {
+	fresh := !r.n.articles.HasArticle(context.Background(), article.ID)
+	if err := r.n.articles.HandleIncomingArticle(article); err != nil {
+		return err
+	}
+	if fresh {
+		r.rec.arrive(article.ID, r.n.index, r.via)
+	}
+	return nil
+}
+
+// bench holds a run's network and measurements
+type bench struct {
+	opts  *options
+	log   *logger.Logger
+	net   mocknet.Mocknet
+	dir   string
+	rng   *rand.Rand
+	rec   *recorder
+	nodes []*benchNode
+	edges int
+}
+
+func run(ctx context.Context, opts *options) (*Report, error) {
+	log, err := logger.New("error", "console")
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "newsp2p-bench-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	b := &bench{
+		opts: opts,
+		log:  log,
+		net:  mocknet.New(),
+		dir:  dir,
+		rng:  rand.New(rand.NewSource(opts.seed)),
+		rec:  newRecorder(),
+	}
+	b.net.SetLinkDefaults(mocknet.LinkOptions{Latency: opts.latency})
+	defer b.close()
+
+	usage := startUsage()
+	started := time.Now()
+
+	// Build the network; each node links to one earlier node so the graph
+	// is connected, then to random others up to the degree
+	for i := 0; i < opts.nodes; i++ {
+		n, err := b.addNode(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			if err := b.connect(ctx, n, b.nodes[b.rng.Intn(i)]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, n := range b.nodes {
+		for tries := 0; len(n.node.GetConnectedPeers()) < opts.degree && tries < 4*opts.degree; tries++ {
+			if err := b.connect(ctx, n, b.nodes[b.rng.Intn(len(b.nodes))]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := b.awaitMesh(30 * time.Second); err != nil {
+		return nil, err
+	}
+
+	// Publish at the target rate
+	keys := make([]ed25519.PrivateKey, opts.publishers)
+	for i := range keys {
+		pair, err := crypto.GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = pair.PrivateKey
+	}
+	signer := auth.NewArticleSigner()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.rate))
+	publishStart := time.Now()
+	for i := 0; i < opts.articles; i++ {
+		if i > 0 {
+			<-ticker.C
+		}
+		usage.sample()
+		publisher := i % opts.publishers
+		if err := b.publish(b.nodes[publisher], keys[publisher], signer, i); err != nil {
+			ticker.Stop()
+			return nil, err
+		}
+	}
+	ticker.Stop()
+	publishEnd := time.Now()
+
+	// Nodes joining now only see the articles through sync
+	onTime := make([]int, len(b.nodes))
+	for i := range onTime {
+		onTime[i] = i
+	}
+	lateJoined := make(map[int]time.Time)
+	for i := 0; i < opts.late; i++ {
+		n, err := b.addNode(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, peer := range b.rng.Perm(opts.nodes)[:opts.degree] {
+			if err := b.connect(ctx, n, b.nodes[peer]); err != nil {
+				return nil, err
+			}
+		}
+		lateJoined[n.index] = time.Now()
+	}
+
+	// Wait for every node to hold every article
+	deadline := time.Now().Add(opts.timeout)
+	gossipDone, lateDone := time.Time{}, make(map[int]time.Time)
+	for time.Now().Before(deadline) {
+		usage.sample()
+		now := time.Now()
+		if gossipDone.IsZero() && b.rec.complete(onTime, opts.articles) {
+			gossipDone = now
+		}
+		for index := range lateJoined {
+			if _, done := lateDone[index]; !done && b.rec.count(index) >= opts.articles {
+				lateDone[index] = now
+			}
+		}
+		if !gossipDone.IsZero() && len(lateDone) == len(lateJoined) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	report := b.report(publishStart, publishEnd, gossipDone, lateJoined, lateDone)
+	report.Resources = usage.finish(time.Since(started))
+	return report, nil
+}
+
+func (b *bench) addNode(ctx context.Context) (*benchNode, error) {
+	index := len(b.nodes)
+	h, err := b.net.GenPeer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create host: %w", err)
+	}
+	dataDir := filepath.Join(b.dir, strconv.Itoa(index))
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return nil, err
+	}
+
+	node, err := p2p.NewP2PNode(ctx, &p2p.Config{
+		Host:       h,
+		Rendezvous: "newsp2p-bench",
+		DataDir:    dataDir,
+	}, b.log)
+	if err != nil {
+		return nil, err
+	}
+	db, err := badger.New(filepath.Join(dataDir, "db"))
+	if err != nil {
+		node.Close()
+		return nil, err
+	}
+
+	n := &benchNode{index: index, node: node, db: db}
+	// Received articles are only verified and stored, so no IPFS client,
+	// user store or search index is needed
+	n.articles = service.NewArticleService(badger.NewArticleRepo(db), nil, nil, nil, auth.NewArticleSigner(), nil, b.log)
+	n.broadcaster = p2p.NewBroadcaster(node, b.log)
+	gossip := &recordingReceiver{n: n, rec: b.rec, via: "gossip"}
+	n.broadcaster.OnArticle(func(msg *p2p.ArticleMessage) error {
+		if msg.Article != nil {
+			return gossip.HandleIncomingArticle(msg.Article)
+		}
+		return nil
+	})
+	if err := n.broadcaster.Start(); err != nil {
+		node.Close()
+		db.Close()
+		return nil, err
+	}
+	n.sync = p2p.NewSyncService(h, n.articles, &recordingReceiver{n: n, rec: b.rec, via: "sync"}, b.log)
+	n.sync.SetSyncInterval(b.opts.syncInterval)
+	n.sync.Start()
+
+	b.nodes = append(b.nodes, n)
+	return n, nil
+}
+
+// connect links two nodes on the in-memory network and dials between them
+func (b *bench) connect(ctx context.Context, from, to *benchNode) error {
+	a, c := from.node.GetPeerID(), to.node.GetPeerID()
+	if a == c || len(b.net.LinksBetweenPeers(a, c)) > 0 {
+		return nil
+	}
+	if _, err := b.net.LinkPeers(a, c); err != nil {
+		return fmt.Errorf("failed to link nodes %d and %d: %w", from.index, to.index, err)
+	}
+	if _, err := b.net.ConnectPeers(a, c); err != nil {
+		return fmt.Errorf("failed to connect nodes %d and %d: %w", from.index, to.index, err)
+	}
+	b.edges++
+	return nil
+}
+
+// awaitMesh waits until every node sees a peer on the articles topic, then
+// for a gossipsub heartbeat so the mesh forms before publishing starts
+func (b *bench) awaitMesh(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for _, n := range b.nodes {
+		topic, err := n.node.JoinTopic(p2p.TopicArticles)
+		if err != nil {
+			return err
+		}
+		for len(topic.ListPeers()) == 0 {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("node %d found no peers on %s within %s", n.index, p2p.TopicArticles, timeout)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	time.Sleep(time.Second)
+	return nil
+}
+
+// publish stores a synthetic article on n and broadcasts it
+func (b *bench) publish(n *benchNode, key ed25519.PrivateKey, signer *auth.ArticleSigner, seq int) error {
+	now := time.Now().UTC()
+	article := &domain.Article{
+		ID:           uuid.NewString(),
+		Title:        fmt.Sprintf("Benchmark article %d", seq),
+		Body:         fmt.Sprintf("Synthetic article %d, published by node %d to measure propagation.", seq, n.index),
+		Author:       fmt.Sprintf("bench-%d", n.index),
+		AuthorPubKey: crypto.PublicKeyToString(key.Public().(ed25519.PublicKey)),
+		Timestamp:    now,
+		Tags:         []string{"bench"},
+		Category:     "technology",
+		Version:      1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	article.CID = "bench-" + article.ID
+	if err := signer.SignArticle(article, key); err != nil {
+		return err
+	}
+	if err := n.articles.HandleIncomingArticle(article); err != nil {
+		return fmt.Errorf("node %d failed to store article %d: %w", n.index, seq, err)
+	}
+	b.rec.publish(article.ID, n.index, time.Now())
+	if err := n.broadcaster.BroadcastArticle("new", article); err != nil {
+		return fmt.Errorf("node %d failed to broadcast article %d: %w", n.index, seq, err)
+	}
+	return nil
+}
+
+func (b *bench) close() {
+	for _, n := range b.nodes {
+		n.sync.Stop()
+		n.broadcaster.Stop()
+		n.node.Close()
+		n.db.Close()
+	}
+	b.net.Close()
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Nodes      int     `json:"nodes"`
+	LateNodes  int     `json:"late_nodes"`
+	Links      int     `json:"links"`
+	Articles   int     `json:"articles"`
+	TargetRate float64 `json:"target_rate"`
+	ActualRate float64 `json:"actual_rate"`
+	Seed       int64   `json:"seed"`
+
+	// Delivery to nodes present while publishing, publisher excluded
+	Deliveries      int       `json:"deliveries"`
+	Expected        int       `json:"expected"`
+	ViaGossip       int       `json:"via_gossip"`
+	ViaSync         int       `json:"via_sync"`
+	GossipLatency   Latencies `json:"gossip_latency"`
+	ConvergenceTime Duration  `json:"convergence_time"` // after the last publish; 0 if never
+	Converged       bool      `json:"converged"`
+
+	// Catch-up of nodes that joined after publishing
+	CatchUp []CatchUp `json:"catch_up,omitempty"`
+
+	Pubsub    p2p.TopicStats `json:"pubsub"` // articles topic, summed over nodes
+	Resources Resources      `json:"resources"`
+}
+
+// CatchUp is how long a late node took to hold every article
+type CatchUp struct {
+	Node     int      `json:"node"`
+	Articles int      `json:"articles"`
+	Time     Duration `json:"time"` // 0 if it never caught up
+}
+
+// Latencies summarises delivery latencies
+type Latencies struct {
+	P50 Duration `json:"p50"`
+	P90 Duration `json:"p90"`
+	P99 Duration `json:"p99"`
+	Max Duration `json:"max"`
+}
+
+// Duration marshals as a string like "1.5s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).Round(100 * time.Microsecond).String()
+}
+
+func (b *bench) report(publishStart, publishEnd, gossipDone time.Time, lateJoined, lateDone map[int]time.Time) *Report {
+	o := b.opts
+	r := &Report{
+		Nodes:      o.nodes,
+		LateNodes:  o.late,
+		Links:      b.edges,
+		Articles:   o.articles,
+		TargetRate: o.rate,
+		Seed:       o.seed,
+		Expected:   o.articles * (o.nodes - 1),
+	}
+	if elapsed := publishEnd.Sub(publishStart); elapsed > 0 && o.articles > 1 {
+		r.ActualRate = float64(o.articles-1) / elapsed.Seconds()
+	}
+
+	b.rec.mu.Lock()
+	var latencies []time.Duration
+	for id, published := range b.rec.published {
+		for node, a := range b.rec.arrivals[id] {
+			if _, late := lateJoined[node]; late || a.via == "local" {
+				continue
+			}
+			r.Deliveries++
+			switch a.via {
+			case "gossip":
+				r.ViaGossip++
+				latencies = append(latencies, a.at.Sub(published))
+			case "sync":
+				r.ViaSync++
+			}
+		}
+	}
+	b.rec.mu.Unlock()
+	r.GossipLatency = summarize(latencies)
+
+	if !gossipDone.IsZero() {
+		r.ConvergenceTime = Duration(gossipDone.Sub(publishEnd))
+	}
+	r.Converged = !gossipDone.IsZero() && len(lateDone) == len(lateJoined)
+
+	for index, joined := range lateJoined {
+		c := CatchUp{Node: index, Articles: b.rec.count(index)}
+		if done, ok := lateDone[index]; ok {
+			c.Time = Duration(done.Sub(joined))
+		}
+		r.CatchUp = append(r.CatchUp, c)
+	}
+	sort.Slice(r.CatchUp, func(i, j int) bool { return r.CatchUp[i].Node < r.CatchUp[j].Node })
+
+	for _, n := range b.nodes {
+		stats := n.node.PubsubStats()[p2p.TopicArticles]
+		r.Pubsub.Published += stats.Published
+		r.Pubsub.Received += stats.Received
+		r.Pubsub.Rejected += stats.Rejected
+		r.Pubsub.Duplicate += stats.Duplicate
+		r.Pubsub.Undeliverable += stats.Undeliverable
+	}
+	return r
+}
+
+func summarize(latencies []time.Duration) Latencies {
+	if len(latencies) == 0 {
+		return Latencies{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	at := func(q float64) Duration {
+		return Duration(latencies[int(q*float64(len(latencies)-1))])
+	}
+	return Latencies{P50: at(0.5), P90: at(0.9), P99: at(0.99), Max: at(1)}
+}
+
+func (r *Report) print() {
+	fmt.Printf("Network      %d nodes, %d links, %d late joiners (seed %d)\n", r.Nodes, r.Links, r.LateNodes, r.Seed)
+	fmt.Printf("Published    %d articles at %.1f/s (target %.1f/s)\n", r.Articles, r.ActualRate, r.TargetRate)
+	fmt.Printf("Delivered    %d of %d (%d by gossip, %d by sync)\n", r.Deliveries, r.Expected, r.ViaGossip, r.ViaSync)
+	fmt.Printf("Gossip       p50 %s  p90 %s  p99 %s  max %s\n", r.GossipLatency.P50, r.GossipLatency.P90, r.GossipLatency.P99, r.GossipLatency.Max)
+	if r.Deliveries == r.Expected {
+		fmt.Printf("Converged    %s after the last publish\n", r.ConvergenceTime)
+	} else {
+		fmt.Printf("Converged    no, %d deliveries missing\n", r.Expected-r.Deliveries)
+	}
+	for _, c := range r.CatchUp {
+		if c.Time > 0 {
+			fmt.Printf("Late node %-3d caught up in %s\n", c.Node, c.Time)
+		} else {
+			fmt.Printf("Late node %-3d has %d of %d articles\n", c.Node, c.Articles, r.Articles)
+		}
+	}
+	fmt.Printf("Pubsub       %d received, %d duplicate, %d rejected, %d undeliverable\n", r.Pubsub.Received, r.Pubsub.Duplicate, r.Pubsub.Rejected, r.Pubsub.Undeliverable)
+	res := r.Resources
+	fmt.Printf("Resources    %s wall, %.2fs CPU, peak heap %.1f MiB, %.1f MiB allocated, peak %d goroutines\n",
+		res.Wall, res.CPUSeconds, float64(res.PeakHeapBytes)/(1<<20), float64(res.TotalAllocBytes)/(1<<20), res.PeakGoroutines)
+}
+
+// Resources is what the whole run cost the process
+type Resources struct {
+	Wall            Duration `json:"wall"`
+	CPUSeconds      float64  `json:"cpu_seconds"`
+	PeakHeapBytes   uint64   `json:"peak_heap_bytes"`
+	TotalAllocBytes uint64   `json:"total_alloc_bytes"`
+	PeakGoroutines  int      `json:"peak_goroutines"`
+}
+
+// usage samples the process while the run is in progress
+type usage struct {
+	startCPU   float64
+	startAlloc uint64
+	peakHeap   uint64
+	peakGo     int
+}
+
+func startUsage() *usage {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return &usage{startCPU: cpuSeconds(), startAlloc: m.TotalAlloc}
+}
+
+func (u *usage) sample() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	u.peakHeap = max(u.peakHeap, m.HeapAlloc)
+	u.peakGo = max(u.peakGo, runtime.NumGoroutine())
+}
+
+func (u *usage) finish(wall time.Duration) Resources {
+	u.sample()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return Resources{
+		Wall:            Duration(wall),
+		CPUSeconds:      cpuSeconds() - u.startCPU,
+		PeakHeapBytes:   u.peakHeap,
+		TotalAllocBytes: m.TotalAlloc - u.startAlloc,
+		PeakGoroutines:  u.peakGo,
+	}
+}
+
+// cpuSeconds is the CPU time the Go runtime estimates the process has used
+func cpuSeconds() float64 {
+	sample := []metrics.Sample{{Name: "/cpu/classes/total:cpu-seconds"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	return sample[0].Value.Float64()
+}
@@ -0,0 +1,535 @@
+// Command blogimport moves a WordPress (WXR) or Ghost export onto a node.
+// Posts become articles signed with a key file and dated with their
+// original publish time. Images they embed are copied to IPFS and linked
+// through a gateway. Articles are sent to the node as CAR archives of
+// -batch articles each through the archive import endpoint, so the node
+// verifies every signature as it would for articles from peers.
+//
+//	blogimport -key alice.json wordpress.xml
+//	blogimport -key alice.json -site https://blog.example.com ghost.json
+//	blogimport -dry-run wordpress.xml
+//
+// Article IDs are derived from the post's identity in the export, so
+// running the import again skips the posts already imported.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// articleNamespace derives stable article IDs from post identities
+var articleNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/amiyamandal-dev/newsp2p/blogimport"))
+
+// importer converts posts and sends them to a node
+type importer struct {
+	author     string
+	privateKey ed25519.PrivateKey
+	signer     *auth.ArticleSigner
+	ipfs       *ipfs.Client
+	http       *http.Client
+	server     string
+	token      string
+	gateway    string
+	site       *url.URL
+	category   string
+	media      bool
+	maxMedia   int64
+
+	// uploaded maps source URLs to their IPFS CIDs, so media shared by
+	// several posts is fetched once; pending holds the CIDs the next
+	// archive links
+	uploaded map[string]string
+	pending  map[string]bool
+}
+
+// options are the command's flags
+type options struct {
+	server, token   string
+	keyPath, author string
+	format          string
+	ipfsAPI         string
+	gateway, site   string
+	category        string
+	media           bool
+	maxMedia        int64
+	drafts          bool
+	batch           int
+	dryRun          bool
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.server, "server", envOr("NEWS_SERVER", "http://localhost:12345"), "node API address")
+	flag.StringVar(&opts.token, "token", os.Getenv("NEWS_TOKEN"), "admin JWT access token (or NEWS_TOKEN)")
+	flag.StringVar(&opts.keyPath, "key", "", "key file to sign with, as written by keygen (asks for its passphrase, or NEWS_KEY_PASSPHRASE)")
+	flag.StringVar(&opts.author, "author", "", "author name (default the key file's username)")
+	flag.StringVar(&opts.format, "format", "auto", "export format: wxr, ghost or auto")
+	flag.StringVar(&opts.ipfsAPI, "ipfs-api", envOr("NEWS_IPFS_API_ENDPOINT", "http://localhost:5001"), "IPFS daemon API to upload articles and media to")
+	flag.StringVar(&opts.gateway, "gateway", "https://ipfs.io", "IPFS gateway imported images are linked through")
+	flag.StringVar(&opts.site, "site", "", "the blog's address, for relative links and Ghost's __GHOST_URL__")
+	flag.StringVar(&opts.category, "category", "", "category for posts whose categories match none of the node's")
+	flag.BoolVar(&opts.media, "media", true, "copy embedded images to IPFS; with -media=false they keep their original links")
+	flag.Int64Var(&opts.maxMedia, "max-media-size", 25<<20, "largest image to copy, in bytes")
+	flag.BoolVar(&opts.drafts, "drafts", false, "also import unpublished posts")
+	flag.IntVar(&opts.batch, "batch", 50, "articles per archive sent to the node")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "list what would be imported without uploading or signing anything")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: blogimport [flags] <export.xml|export.json>\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), opts); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(exportPath string, opts options) error {
+	if opts.batch < 1 || opts.batch > domain.MaxArchiveArticles {
+		return fmt.Errorf("-batch must be between 1 and %d", domain.MaxArchiveArticles)
+	}
+	if !domain.AllowedCategories[opts.category] {
+		return fmt.Errorf("-category %q is not a known category", opts.category)
+	}
+
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		return err
+	}
+	posts, err := readExport(data, opts.format)
+	if err != nil {
+		return err
+	}
+
+	var selected []post
+	for _, p := range posts {
+		switch {
+		case p.Draft && !opts.drafts:
+		case strings.TrimSpace(p.HTML) == "" && p.Image == "":
+			fmt.Fprintf(os.Stderr, "Skipping %q: no content\n", p.Title)
+		default:
+			selected = append(selected, p)
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("%s has no posts to import", exportPath)
+	}
+
+	if opts.dryRun {
+		for _, p := range selected {
+			fmt.Printf("%s  %s\n", p.Published.Format("2006-01-02"), p.Title)
+		}
+		fmt.Printf("%d of %d posts would be imported\n", len(selected), len(posts))
+		return nil
+	}
+
+	if opts.keyPath == "" {
+		return fmt.Errorf("-key is required to sign the imported articles")
+	}
+	imp := &importer{
+		signer:   auth.NewArticleSigner(),
+		http:     &http.Client{Timeout: 10 * time.Minute},
+		server:   strings.TrimRight(opts.server, "/"),
+		token:    opts.token,
+		gateway:  strings.TrimRight(opts.gateway, "/"),
+		category: opts.category,
+		media:    opts.media,
+		maxMedia: opts.maxMedia,
+		uploaded: make(map[string]string),
+		pending:  make(map[string]bool),
+	}
+	if opts.site != "" {
+		if imp.site, err = url.Parse(strings.TrimRight(opts.site, "/") + "/"); err != nil {
+			return fmt.Errorf("-site: %w", err)
+		}
+	}
+	if err := imp.loadKey(opts.keyPath, opts.author); err != nil {
+		return err
+	}
+
+	log, err := logger.New("error", "console")
+	if err != nil {
+		return err
+	}
+	imp.ipfs = ipfs.NewClient(opts.ipfsAPI, 5*time.Minute, false, log)
+	ctx := context.Background()
+	if !imp.ipfs.IsHealthy(ctx) {
+		return fmt.Errorf("IPFS daemon at %s is not reachable", opts.ipfsAPI)
+	}
+
+	var imported, skipped, failed int
+	var batch []*domain.Article
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		report, err := imp.send(ctx, batch)
+		if err != nil {
+			return err
+		}
+		imported += len(report.Imported)
+		skipped += len(report.Skipped)
+		failed += len(report.Failed)
+		for _, warning := range report.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for _, p := range selected {
+		article, err := imp.convert(ctx, p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %q: %v\n", p.Title, err)
+			failed++
+			continue
+		}
+		batch = append(batch, article)
+		if len(batch) == opts.batch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported: %d\n", imported)
+	fmt.Printf("Skipped:  %d (already present)\n", skipped)
+	fmt.Printf("Media:    %d files copied to IPFS\n", len(imp.uploaded))
+	if failed > 0 {
+		return fmt.Errorf("%d posts failed to import", failed)
+	}
+	return nil
+}
+
+// loadKey reads the signing key and settles the author name
+func (imp *importer) loadKey(keyPath, author string) error {
+	file, err := crypto.ReadKeyFile(keyPath)
+	if err != nil {
+		return err
+	}
+	var passphrase string
+	if file.Encrypted() {
+		if passphrase, err = secret("NEWS_KEY_PASSPHRASE", "Passphrase for "+keyPath+": "); err != nil {
+			return err
+		}
+	}
+	if imp.privateKey, err = file.Key(passphrase); err != nil {
+		return fmt.Errorf("%s: %w", keyPath, err)
+	}
+
+	imp.author = firstNonEmpty(author, file.Username)
+	if imp.author == "" {
+		return fmt.Errorf("%s has no username; name the author with -author", keyPath)
+	}
+	return nil
+}
+
+// convert turns a post into a signed article dated with its original
+// publish time, copying its images to IPFS on the way
+func (imp *importer) convert(ctx context.Context, p post) (*domain.Article, error) {
+	source := p.HTML
+	if p.Image != "" {
+		source = fmt.Sprintf(`<p><img src="%s" alt=""></p>`, p.Image) + source
+	}
+
+	var mediaErr error
+	body, err := toMarkdown(source, func(ref string) string {
+		target, err := imp.rewrite(ctx, ref)
+		if err != nil && mediaErr == nil {
+			mediaErr = err
+		}
+		return target
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mediaErr != nil {
+		return nil, mediaErr
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, errors.New("no text left after conversion")
+	}
+
+	published := p.Published
+	if published.IsZero() {
+		return nil, errors.New("no publish date")
+	}
+	updated := p.Modified
+	if updated.Before(published) {
+		updated = published
+	}
+
+	article := &domain.Article{
+		ID:           uuid.NewSHA1(articleNamespace, []byte(p.Source)).String(),
+		Title:        truncate(firstNonEmpty(p.Title, "Untitled"), 200),
+		Body:         body,
+		Author:       imp.author,
+		AuthorPubKey: crypto.PublicKeyToString(imp.privateKey.Public().(ed25519.PublicKey)),
+		Timestamp:    published.UTC(),
+		Tags:         importTags(p.Tags),
+		Category:     imp.pickCategory(p.Categories),
+		Version:      1,
+		CreatedAt:    published.UTC(),
+		UpdatedAt:    updated.UTC(),
+	}
+	if err := article.Validate(); err != nil {
+		return nil, err
+	}
+	if err := imp.signer.SignArticle(article, imp.privateKey); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// rewrite resolves a link against the blog's address and, for images
+// the blog hosts, replaces it with a gateway link to an IPFS copy
+func (imp *importer) rewrite(ctx context.Context, ref string) (string, error) {
+	if imp.site != nil {
+		ref = strings.ReplaceAll(ref, "__GHOST_URL__/", imp.site.String())
+		if u, err := imp.site.Parse(ref); err == nil {
+			ref = u.String()
+		}
+	}
+	if !imp.media || !isImage(ref) {
+		return ref, nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ref, nil
+	}
+
+	cid, ok := imp.uploaded[ref]
+	if !ok {
+		if cid, err = imp.copyMedia(ctx, ref); err != nil {
+			return ref, fmt.Errorf("image %s: %w", ref, err)
+		}
+		imp.uploaded[ref] = cid
+	}
+	imp.pending[cid] = true
+	return fmt.Sprintf("%s/ipfs/%s?filename=%s", imp.gateway, cid, url.QueryEscape(path.Base(u.Path))), nil
+}
+
+// copyMedia downloads a file and adds it to IPFS
+func (imp *importer) copyMedia(ctx context.Context, ref string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := imp.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, imp.maxMedia+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > imp.maxMedia {
+		return "", fmt.Errorf("larger than %d bytes", imp.maxMedia)
+	}
+	return imp.ipfs.Add(ctx, data)
+}
+
+// send uploads a batch of articles and their images as one archive and
+// has the node import it
+func (imp *importer) send(ctx context.Context, articles []*domain.Article) (*domain.ArchiveImportReport, error) {
+	manifest := domain.ArchiveManifest{
+		Version:   domain.ArchiveManifestVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+	var entries []ipfs.DirectoryEntry
+	for _, article := range articles {
+		data, err := article.ToJSON()
+		if err != nil {
+			return nil, err
+		}
+		cid, err := imp.ipfs.Add(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload %q: %w", article.Title, err)
+		}
+		entry := domain.ArchiveArticleEntry{
+			ID:     article.ID,
+			CID:    cid,
+			Title:  article.Title,
+			Author: article.Author,
+			Path:   path.Join(domain.ArchiveArticlesDir, article.ID+".json"),
+		}
+		manifest.Articles = append(manifest.Articles, entry)
+		entries = append(entries, ipfs.DirectoryEntry{Path: entry.Path, CID: cid})
+	}
+	// Images travel in the archive so the node holds them even if it
+	// doesn't share a daemon with this tool
+	for cid := range imp.pending {
+		entries = append(entries, ipfs.DirectoryEntry{Path: path.Join(domain.ArchiveAttachmentsDir, cid), CID: cid})
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	manifestCID, err := imp.ipfs.Add(ctx, manifestJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload manifest: %w", err)
+	}
+	entries = append(entries, ipfs.DirectoryEntry{Path: domain.ArchiveManifestPath, CID: manifestCID})
+
+	root, err := imp.ipfs.BuildDirectory(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	var car bytes.Buffer
+	if err := imp.ipfs.ExportCAR(ctx, root, &car); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, imp.server+"/api/v1/admin/archive/import", &car)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.ipld.car")
+	if imp.token != "" {
+		req.Header.Set("Authorization", "Bearer "+imp.token)
+	}
+	resp, err := imp.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Data  domain.ArchiveImportReport `json:"data"`
+		Error string                     `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil && resp.StatusCode/100 == 2 {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("archive import: %s", firstNonEmpty(body.Error, resp.Status))
+	}
+	imp.pending = make(map[string]bool)
+	fmt.Printf("Sent %d articles (archive %s)\n", len(articles), root)
+	return &body.Data, nil
+}
+
+// pickCategory returns the first post category the node knows
+func (imp *importer) pickCategory(categories []string) string {
+	for _, c := range categories {
+		if c = strings.ToLower(c); c != "" && domain.AllowedCategories[c] {
+			return c
+		}
+	}
+	return imp.category
+}
+
+// importTags keeps the tags an article may carry: at most 10, none
+// empty or longer than 50 characters
+func importTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, tag := range tags {
+		tag = truncate(strings.TrimSpace(tag), 50)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		out = append(out, tag)
+		if len(out) == 10 {
+			break
+		}
+	}
+	return out
+}
+
+// truncate shortens s to at most n bytes without splitting a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func isImage(ref string) bool {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".svg", ".avif":
+		return true
+	}
+	return false
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// secret reads a passphrase from env, else from the terminal with echo
+// turned off. Where stty isn't available the line is read as typed.
+func secret(env, prompt string) (string, error) {
+	if value := os.Getenv(env); value != "" {
+		return value, nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
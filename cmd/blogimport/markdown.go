@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// toMarkdown converts post HTML to the markdown articles are written in.
+// Raw HTML is dropped when articles are rendered, so everything a reader
+// should see has to become markdown. Unknown elements keep their text.
+// link rewrites image and link targets, e.g. to point at IPFS copies.
+func toMarkdown(source string, link func(string) string) (string, error) {
+	root, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return "", err
+	}
+	c := &converter{link: link}
+	c.children(root)
+	return tidyMarkdown(c.b.String()), nil
+}
+
+type converter struct {
+	b      strings.Builder
+	link   func(string) string
+	lists  []int // per open list: 0 for bullets, else the next number
+	quotes int
+	pre    bool
+}
+
+func (c *converter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+func (c *converter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		c.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Iframe, atom.Noscript:
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Figure, atom.Header, atom.Footer:
+		c.block()
+		c.children(n)
+		c.block()
+	case atom.Figcaption:
+		c.block()
+		c.write("*")
+		c.children(n)
+		c.write("*")
+		c.block()
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		c.block()
+		c.write(strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		c.children(n)
+		c.block()
+	case atom.Br:
+		c.write("  \n")
+		c.indent()
+	case atom.Hr:
+		c.block()
+		c.write("---")
+		c.block()
+	case atom.Strong, atom.B:
+		c.write("**")
+		c.children(n)
+		c.write("**")
+	case atom.Em, atom.I:
+		c.write("*")
+		c.children(n)
+		c.write("*")
+	case atom.Del, atom.S, atom.Strike:
+		c.write("~~")
+		c.children(n)
+		c.write("~~")
+	case atom.Code:
+		if c.pre {
+			c.children(n)
+			return
+		}
+		c.write("`")
+		c.children(n)
+		c.write("`")
+	case atom.Pre:
+		c.block()
+		c.write("```\n")
+		c.pre = true
+		c.children(n)
+		c.pre = false
+		c.write("\n```")
+		c.block()
+	case atom.A:
+		href := attr(n, "href")
+		if href == "" {
+			c.children(n)
+			return
+		}
+		c.write("[")
+		c.children(n)
+		c.write("](" + c.link(href) + ")")
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" {
+			return
+		}
+		c.write(fmt.Sprintf("![%s](%s)", escapeText(attr(n, "alt")), c.link(src)))
+	case atom.Blockquote:
+		c.block()
+		c.quotes++
+		c.indent()
+		c.children(n)
+		c.quotes--
+		c.block()
+	case atom.Ul, atom.Ol:
+		// Nested lists continue their parent item; each item starts its own line
+		nested := len(c.lists) > 0
+		if !nested {
+			c.block()
+		}
+		next := 0
+		if n.DataAtom == atom.Ol {
+			next = 1
+		}
+		c.lists = append(c.lists, next)
+		c.children(n)
+		c.lists = c.lists[:len(c.lists)-1]
+		if !nested {
+			c.block()
+		}
+	case atom.Li:
+		c.newline()
+		depth := len(c.lists)
+		if depth == 0 {
+			c.lists = append(c.lists, 0)
+			depth = 1
+			defer func() { c.lists = c.lists[:0] }()
+		}
+		c.write(strings.Repeat("   ", depth-1))
+		if next := c.lists[depth-1]; next > 0 {
+			c.write(fmt.Sprintf("%d. ", next))
+			c.lists[depth-1]++
+		} else {
+			c.write("- ")
+		}
+		c.children(n)
+	default:
+		c.children(n)
+	}
+}
+
+func (c *converter) text(s string) {
+	if c.pre {
+		c.b.WriteString(s)
+		return
+	}
+	s = whitespace.ReplaceAllString(s, " ")
+	if strings.HasSuffix(c.b.String(), "\n") || c.b.Len() == 0 {
+		s = strings.TrimLeft(s, " ")
+	}
+	c.write(escapeText(s))
+}
+
+func (c *converter) write(s string) {
+	c.b.WriteString(s)
+}
+
+// block ends the current paragraph
+func (c *converter) block() {
+	c.newline()
+	c.newline()
+}
+
+func (c *converter) newline() {
+	c.write("\n")
+	c.indent()
+}
+
+// indent continues an open blockquote on a new line
+func (c *converter) indent() {
+	if c.quotes > 0 {
+		c.write(strings.Repeat("> ", c.quotes))
+	}
+}
+
+var (
+	whitespace = regexp.MustCompile(`\s+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	// markdownSpecial are characters that would start markup mid-text
+	markdownSpecial = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+)
+
+func escapeText(s string) string {
+	return markdownSpecial.Replace(s)
+}
+
+// tidyMarkdown drops trailing spaces, except hard line breaks, and
+// collapses the runs of blank lines the block handling leaves
+func tidyMarkdown(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		trimmed := strings.TrimRight(line, " ")
+		switch {
+		case strings.Trim(trimmed, "> ") == "":
+			// blank, or a blank line inside a blockquote; one is enough
+			if n := len(lines); n > 0 && lines[n-1] == trimmed {
+				continue
+			}
+			lines = append(lines, trimmed)
+		case strings.HasSuffix(line, "  "):
+			lines = append(lines, trimmed+"  ")
+		default:
+			lines = append(lines, trimmed)
+		}
+	}
+	s = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s) + "\n"
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
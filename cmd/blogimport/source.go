@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// post is a blog post read from an export, before conversion
+type post struct {
+	Source     string // stable identifier in the export, used to derive the article ID
+	Title      string
+	HTML       string
+	Published  time.Time
+	Modified   time.Time
+	Draft      bool
+	Tags       []string
+	Categories []string
+	Image      string // feature image, placed above the body
+}
+
+// readExport parses a WordPress WXR or Ghost JSON export. format is "wxr",
+// "ghost" or "auto", which decides from the content.
+func readExport(data []byte, format string) ([]post, error) {
+	if format == "auto" {
+		format = "wxr"
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = "ghost"
+		}
+	}
+
+	switch format {
+	case "wxr":
+		return readWXR(data)
+	case "ghost":
+		return readGhost(data)
+	default:
+		return nil, fmt.Errorf("unknown export format %q (want wxr, ghost or auto)", format)
+	}
+}
+
+// wxrItem is the part of a WXR <item> the importer uses. Elements are
+// matched by local name, since the wp namespace URI changes with the WXR
+// version; content:encoded is matched by namespace because excerpt:encoded
+// shares its local name.
+type wxrItem struct {
+	Title      string `xml:"title"`
+	Link       string `xml:"link"`
+	GUID       string `xml:"guid"`
+	PubDate    string `xml:"pubDate"`
+	Content    string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PostID     string `xml:"post_id"`
+	PostDate   string `xml:"post_date_gmt"`
+	Modified   string `xml:"post_modified_gmt"`
+	Status     string `xml:"status"`
+	PostType   string `xml:"post_type"`
+	Categories []struct {
+		Domain   string `xml:"domain,attr"`
+		Nicename string `xml:"nicename,attr"`
+		Name     string `xml:",chardata"`
+	} `xml:"category"`
+}
+
+// wxrTimeLayout is the format of wp:post_date_gmt and wp:post_modified_gmt
+const wxrTimeLayout = "2006-01-02 15:04:05"
+
+func readWXR(data []byte) ([]post, error) {
+	var doc struct {
+		Items []wxrItem `xml:"channel>item"`
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// WordPress declares UTF-8; anything else is read as-is
+	decoder.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("not a WXR export: %w", err)
+	}
+
+	var posts []post
+	for _, item := range doc.Items {
+		if item.PostType != "" && item.PostType != "post" {
+			continue
+		}
+		p := post{
+			Source: firstNonEmpty(item.GUID, item.Link, "wp:"+item.PostID),
+			Title:  strings.TrimSpace(item.Title),
+			HTML:   wpAutoP(item.Content),
+			Draft:  item.Status != "" && item.Status != "publish",
+		}
+
+		// Drafts carry 0000-00-00 00:00:00, which fails to parse and
+		// falls through to pubDate
+		if t, err := time.Parse(wxrTimeLayout, item.PostDate); err == nil {
+			p.Published = t
+		} else if t, err := time.Parse(time.RFC1123Z, item.PubDate); err == nil {
+			p.Published = t.UTC()
+		}
+		if t, err := time.Parse(wxrTimeLayout, item.Modified); err == nil {
+			p.Modified = t
+		}
+
+		for _, c := range item.Categories {
+			name := strings.TrimSpace(c.Name)
+			switch c.Domain {
+			case "category":
+				p.Categories = append(p.Categories, firstNonEmpty(c.Nicename, name))
+			case "post_tag":
+				p.Tags = append(p.Tags, name)
+			}
+		}
+		posts = append(posts, p)
+	}
+	return posts, nil
+}
+
+// wpAutoP wraps WordPress's newline-separated paragraphs in <p>, as
+// WordPress itself does when displaying content stored without markup.
+// Content that already uses block elements is left alone.
+func wpAutoP(content string) string {
+	if strings.Contains(content, "<p>") || strings.Contains(content, "<!-- wp:") {
+		return content
+	}
+	var b strings.Builder
+	for _, para := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			b.WriteString("<p>" + strings.ReplaceAll(para, "\n", "<br>") + "</p>\n")
+		}
+	}
+	return b.String()
+}
+
+// ghostData is the part of a Ghost export's data object the importer uses
+type ghostData struct {
+	Posts []struct {
+		ID           string  `json:"id"`
+		UUID         string  `json:"uuid"`
+		Title        string  `json:"title"`
+		HTML         *string `json:"html"`
+		FeatureImage *string `json:"feature_image"`
+		Status       string  `json:"status"`
+		Type         string  `json:"type"`
+		PublishedAt  *string `json:"published_at"`
+		CreatedAt    string  `json:"created_at"`
+		UpdatedAt    string  `json:"updated_at"`
+	} `json:"posts"`
+	Tags []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"tags"`
+	PostsTags []struct {
+		PostID    string `json:"post_id"`
+		TagID     string `json:"tag_id"`
+		SortOrder int    `json:"sort_order"`
+	} `json:"posts_tags"`
+}
+
+// readGhost accepts both the {"db": [{"data": ...}]} file Ghost's admin
+// exports and the bare {"data": ...} form
+func readGhost(data []byte) ([]post, error) {
+	var doc struct {
+		DB []struct {
+			Data ghostData `json:"data"`
+		} `json:"db"`
+		Data *ghostData `json:"data"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("not a Ghost export: %w", err)
+	}
+	g := doc.Data
+	if g == nil {
+		if len(doc.DB) == 0 {
+			return nil, fmt.Errorf("not a Ghost export: no data")
+		}
+		g = &doc.DB[0].Data
+	}
+
+	tagNames := make(map[string]string, len(g.Tags))
+	for _, tag := range g.Tags {
+		// Ghost's internal tags (#name) are for theming, not readers
+		if !strings.HasPrefix(tag.Name, "#") {
+			tagNames[tag.ID] = tag.Name
+		}
+	}
+	links := append(g.PostsTags[:0:0], g.PostsTags...)
+	sort.SliceStable(links, func(i, j int) bool { return links[i].SortOrder < links[j].SortOrder })
+	postTags := make(map[string][]string)
+	for _, link := range links {
+		if name, ok := tagNames[link.TagID]; ok {
+			postTags[link.PostID] = append(postTags[link.PostID], name)
+		}
+	}
+
+	var posts []post
+	for _, gp := range g.Posts {
+		if gp.Type != "" && gp.Type != "post" {
+			continue
+		}
+		p := post{
+			Source: "ghost:" + firstNonEmpty(gp.UUID, gp.ID),
+			Title:  strings.TrimSpace(gp.Title),
+			Draft:  gp.Status != "published",
+			Tags:   postTags[gp.ID],
+		}
+		if gp.HTML != nil {
+			p.HTML = *gp.HTML
+		}
+		if gp.FeatureImage != nil {
+			p.Image = *gp.FeatureImage
+		}
+		if gp.PublishedAt != nil {
+			p.Published = parseGhostTime(*gp.PublishedAt)
+		}
+		if p.Published.IsZero() {
+			p.Published = parseGhostTime(gp.CreatedAt)
+		}
+		p.Modified = parseGhostTime(gp.UpdatedAt)
+		posts = append(posts, p)
+	}
+	return posts, nil
+}
+
+// parseGhostTime reads Ghost's timestamps, which older versions write
+// without a zone
+func parseGhostTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
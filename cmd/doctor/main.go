@@ -0,0 +1,598 @@
+// Command doctor diagnoses why a node can't start or can't find peers. It
+// reads the same configuration as the server, then checks it, the HTTP
+// ports, the IPFS daemon, the bootstrap peers, the DHT and NAT
+// reachability, printing a fix for each problem found.
+//
+//	doctor
+//	doctor -profile node2 -timeout 1m
+//	doctor -json
+//
+// It exits non-zero when any check fails. Running it next to a live
+// server is fine; checks that would collide with the server are skipped.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// Check outcomes
+const (
+	statusOK   = "ok"
+	statusWarn = "warn"
+	statusFail = "fail"
+	statusSkip = "skip"
+)
+
+// result is the outcome of one check
+type result struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// doctor runs the checks in order, each able to rely on what the ones
+// before it found
+type doctor struct {
+	overrides config.Overrides
+	timeout   time.Duration
+	asJSON    bool
+
+	cfg           *config.Config
+	log           *logger.Logger
+	serverRunning bool
+	p2pPortsBusy  bool
+	results       []result
+}
+
+func main() {
+	d := &doctor{}
+	flag.StringVar(&d.overrides.Profile, "profile", "", "node profile, as given to the server")
+	flag.StringVar(&d.overrides.DataRoot, "data-root", "", "data root, as given to the server")
+	flag.DurationVar(&d.timeout, "timeout", 30*time.Second, "how long to wait for peers, the DHT and NAT detection")
+	flag.BoolVar(&d.asJSON, "json", false, "print the results as JSON")
+	flag.Parse()
+
+	log, err := logger.New("error", "console")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	d.log = log
+
+	d.run(context.Background())
+
+	if d.asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(d.results)
+	} else {
+		d.summary()
+	}
+	for _, r := range d.results {
+		if r.Status == statusFail {
+			os.Exit(1)
+		}
+	}
+}
+
+func (d *doctor) run(ctx context.Context) {
+	if !d.checkConfig() {
+		return
+	}
+	d.checkDataDir()
+	d.checkServer()
+	d.checkIPFS(ctx)
+
+	if !d.cfg.P2P.Enabled {
+		d.report("p2p", statusSkip, "p2p.enabled is false; the node runs without peers", "")
+		return
+	}
+	listenAddrs, ok := d.checkListenAddrs()
+	if !ok {
+		return
+	}
+	d.checkNetwork(ctx, listenAddrs)
+}
+
+// report records a result, printing it straight away unless the results
+// are wanted as JSON
+func (d *doctor) report(check, status, detail, fix string) {
+	d.results = append(d.results, result{Check: check, Status: status, Detail: detail, Fix: fix})
+	if d.asJSON {
+		return
+	}
+	fmt.Printf("%-5s %-16s %s\n", strings.ToUpper(status), check, detail)
+	if fix != "" {
+		fmt.Printf("      %-16s fix: %s\n", "", fix)
+	}
+}
+
+func (d *doctor) summary() {
+	counts := map[string]int{}
+	for _, r := range d.results {
+		counts[r.Status]++
+	}
+	fmt.Println()
+	switch {
+	case counts[statusFail] > 0:
+		fmt.Printf("%s and %s found\n", plural(counts[statusFail], "problem"), plural(counts[statusWarn], "warning"))
+	case counts[statusWarn] > 0:
+		fmt.Printf("No problems, %s\n", plural(counts[statusWarn], "warning"))
+	default:
+		fmt.Println("No problems found")
+	}
+}
+
+// configKeyPattern finds the setting a validation error names
+var configKeyPattern = regexp.MustCompile(`\b([a-z_]+(?:\.[a-z_]+)+)\b`)
+
+func (d *doctor) checkConfig() bool {
+	cfg, err := config.LoadWithOverrides(d.overrides)
+	if err != nil {
+		fix := "fix the setting in configs/config.yaml (the server reads it from the directory it starts in)"
+		if key := configKeyPattern.FindString(err.Error()); key != "" {
+			env := "NEWS_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+			fix = fmt.Sprintf("set %s in configs/config.yaml or %s", key, env)
+		}
+		d.report("config", statusFail, err.Error(), fix)
+		return false
+	}
+	d.cfg = cfg
+
+	detail := "valid"
+	if cfg.Data.Profile != "" {
+		detail += fmt.Sprintf(", profile %s", cfg.Data.Profile)
+	}
+	d.report("config", statusOK, detail, "")
+	return true
+}
+
+func (d *doctor) checkDataDir() {
+	dir := d.cfg.Data.Dir()
+	info, err := os.Stat(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		d.report("data dir", statusOK, dir+" will be created on first start", "")
+		return
+	case err != nil:
+		d.report("data dir", statusFail, err.Error(), "check the permissions on "+dir)
+		return
+	case !info.IsDir():
+		d.report("data dir", statusFail, dir+" is not a directory", "point data.root (or -data-root) at a directory")
+		return
+	}
+
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		d.report("data dir", statusFail, dir+" is not writable: "+err.Error(),
+			"run the server as a user that owns "+dir+", or choose another data.root")
+		return
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	if _, err := os.Stat(filepath.Join(dir, p2p.NodeKeyFile)); err != nil {
+		d.report("data dir", statusOK, dir+" is writable; a node key will be generated on first start", "")
+		return
+	}
+	d.report("data dir", statusOK, dir+" is writable", "")
+}
+
+// checkServer looks for a server already answering on the HTTP port,
+// otherwise makes sure the port can be bound
+func (d *doctor) checkServer() {
+	host := d.cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	healthURL := fmt.Sprintf("http://%s/health", net.JoinHostPort(host, fmt.Sprint(d.cfg.Server.Port)))
+	client := &http.Client{Timeout: 2 * time.Second}
+	if resp, err := client.Get(healthURL); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			d.serverRunning = true
+			d.report("http port", statusOK, "a server is already running at "+healthURL, "")
+			return
+		}
+	}
+
+	d.checkBind("http port", "tcp", net.JoinHostPort(d.cfg.Server.Host, fmt.Sprint(d.cfg.Server.Port)),
+		"stop whatever holds it, or set server.port (NEWS_SERVER_PORT)")
+	if d.cfg.GRPC.Enabled {
+		d.checkBind("grpc port", "tcp", net.JoinHostPort(d.cfg.Server.Host, fmt.Sprint(d.cfg.GRPC.Port)),
+			"stop whatever holds it, or set grpc.port (NEWS_GRPC_PORT)")
+	}
+}
+
+// checkBind makes sure an address can be listened on
+func (d *doctor) checkBind(check, network, addr, fix string) bool {
+	var err error
+	if network == "udp" {
+		var conn net.PacketConn
+		if conn, err = net.ListenPacket(network, addr); err == nil {
+			conn.Close()
+		}
+	} else {
+		var listener net.Listener
+		if listener, err = net.Listen(network, addr); err == nil {
+			listener.Close()
+		}
+	}
+	if err != nil {
+		d.report(check, statusFail, fmt.Sprintf("can't listen on %s/%s: %v", network, addr, err), fix)
+		return false
+	}
+	d.report(check, statusOK, fmt.Sprintf("%s/%s is free", network, addr), "")
+	return true
+}
+
+func (d *doctor) checkIPFS(ctx context.Context) {
+	client := ipfs.NewClient(d.cfg.IPFS.APIEndpoint, d.cfg.IPFS.Timeout, d.cfg.IPFS.PinArticles, d.log)
+	id, err := client.GetID(ctx)
+	if err != nil {
+		d.report("ipfs", statusFail, fmt.Sprintf("daemon at %s is not reachable: %v", d.cfg.IPFS.APIEndpoint, err),
+			"start it with `ipfs daemon`, or point ipfs.api_endpoint (NEWS_IPFS_API_ENDPOINT) at a running one")
+		return
+	}
+	d.report("ipfs", statusOK, fmt.Sprintf("daemon at %s, peer %s", d.cfg.IPFS.APIEndpoint, id), "")
+}
+
+// checkListenAddrs parses p2p.listen_addrs and, when the server isn't
+// holding them, makes sure fixed ports can be bound
+func (d *doctor) checkListenAddrs() ([]multiaddr.Multiaddr, bool) {
+	var addrs []multiaddr.Multiaddr
+	fixedPorts := false
+	for _, raw := range d.cfg.P2P.ListenAddrs {
+		addr, err := multiaddr.NewMultiaddr(raw)
+		if err != nil {
+			d.report("p2p listen", statusFail, fmt.Sprintf("invalid address %q: %v", raw, err),
+				"use multiaddrs like /ip4/0.0.0.0/tcp/4001 in p2p.listen_addrs")
+			return nil, false
+		}
+		addrs = append(addrs, addr)
+
+		network, hostPort, err := manet.DialArgs(addr)
+		if err != nil || strings.HasSuffix(hostPort, ":0") {
+			continue
+		}
+		fixedPorts = true
+		if d.serverRunning {
+			continue
+		}
+		network = strings.TrimRight(network, "46")
+		if !d.checkBind("p2p listen", network, hostPort,
+			"stop whatever holds the port, or change it in p2p.listen_addrs") {
+			d.p2pPortsBusy = true
+		}
+	}
+
+	switch {
+	case d.serverRunning:
+		d.report("p2p listen", statusSkip, "in use by the running server", "")
+	case !fixedPorts:
+		d.report("p2p listen", statusOK, "random ports ("+strings.Join(d.cfg.P2P.ListenAddrs, ", ")+")", "")
+	}
+	return addrs, true
+}
+
+// checkNetwork starts a throwaway libp2p host, with a fresh identity so
+// it can't clash with the node's, and uses it to test the bootstrap
+// peers, the DHT and NAT reachability the way the node would see them
+func (d *doctor) checkNetwork(ctx context.Context, listenAddrs []multiaddr.Multiaddr) {
+	opts := []libp2p.Option{
+		libp2p.NATPortMap(),
+		libp2p.EnableRelay(),
+	}
+	// Listen where the node would, so NAT detection covers its ports,
+	// unless something already has them
+	if d.serverRunning || d.p2pPortsBusy {
+		opts = append(opts, libp2p.DefaultListenAddrs)
+	} else {
+		opts = append(opts, libp2p.ListenAddrs(listenAddrs...))
+	}
+	h, err := libp2p.New(opts...)
+	if err != nil {
+		d.report("p2p host", statusFail, err.Error(), "check p2p.listen_addrs")
+		return
+	}
+	defer h.Close()
+
+	// Subscribe before dialing anyone; AutoNAT reports as soon as peers
+	// have tried dialing back
+	sub, err := h.EventBus().Subscribe([]interface{}{
+		new(event.EvtLocalReachabilityChanged),
+		new(event.EvtNATDeviceTypeChanged),
+	})
+	if err != nil {
+		d.report("nat", statusFail, err.Error(), "")
+		return
+	}
+	defer sub.Close()
+
+	connected := d.checkBootstrap(ctx, h)
+	if len(connected) == 0 {
+		d.report("dht", statusSkip, "no bootstrap peer to join through", "")
+		d.report("nat", statusSkip, "no peers to test reachability with", "")
+		return
+	}
+	d.checkDHT(ctx, h, connected)
+	d.checkNAT(ctx, h, sub)
+}
+
+// checkBootstrap dials the configured bootstrap peers and those offered
+// by bootstrap servers, returning the ones that answered
+func (d *doctor) checkBootstrap(ctx context.Context, h host.Host) []peer.AddrInfo {
+	var candidates []peer.AddrInfo
+	for _, raw := range d.cfg.P2P.BootstrapPeers {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		info, err := peer.AddrInfoFromString(raw)
+		if err != nil {
+			d.report("bootstrap", statusFail, fmt.Sprintf("invalid peer %q: %v", raw, err),
+				"bootstrap peers need a /p2p/<peer ID> suffix")
+			continue
+		}
+		candidates = append(candidates, *info)
+	}
+	// Several servers may offer the same peer
+	seen := map[peer.ID]bool{}
+	for _, info := range candidates {
+		seen[info.ID] = true
+	}
+	for _, info := range d.bootstrapServerPeers(ctx) {
+		if !seen[info.ID] {
+			seen[info.ID] = true
+			candidates = append(candidates, info)
+		}
+	}
+	if len(candidates) == 0 {
+		d.report("bootstrap", statusFail, "no bootstrap peers configured and no bootstrap server found",
+			"add peers to p2p.bootstrap_peers, or run cmd/bootstrap and set BOOTSTRAP_URL")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var connected []peer.AddrInfo
+	failures := map[peer.ID]error{}
+	for _, info := range candidates {
+		wg.Add(1)
+		go func(info peer.AddrInfo) {
+			defer wg.Done()
+			err := h.Connect(ctx, info)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[info.ID] = err
+				return
+			}
+			connected = append(connected, info)
+		}(info)
+	}
+	wg.Wait()
+
+	for _, info := range candidates {
+		if err, failed := failures[info.ID]; failed {
+			d.report("bootstrap", statusWarn, fmt.Sprintf("%s unreachable: %v", shortID(info.ID), firstLine(err)), "")
+		}
+	}
+	switch {
+	case len(connected) == 0:
+		d.report("bootstrap", statusFail, fmt.Sprintf("none of %d bootstrap peers answered", len(candidates)),
+			"check that outbound TCP/UDP is allowed and DNS works, and replace dead entries in p2p.bootstrap_peers")
+	default:
+		d.report("bootstrap", statusOK, fmt.Sprintf("connected to %d of %d bootstrap peers", len(connected), len(candidates)), "")
+	}
+	return connected
+}
+
+// bootstrapServerPeers asks the bootstrap servers auto-discovery uses for
+// their peer addresses. The local default is expected to be absent on
+// most machines, so only an explicit BOOTSTRAP_URL is reported.
+func (d *doctor) bootstrapServerPeers(ctx context.Context) []peer.AddrInfo {
+	client := &http.Client{Timeout: 5 * time.Second}
+	explicit := os.Getenv("BOOTSTRAP_URL")
+
+	var peers []peer.AddrInfo
+	for _, url := range p2p.DefaultBootstrapURLs() {
+		info, err := fetchBootstrapInfo(ctx, client, url)
+		if err != nil {
+			if url == explicit {
+				d.report("bootstrap server", statusWarn, fmt.Sprintf("%s: %v", url, err),
+					"start cmd/bootstrap there, or correct BOOTSTRAP_URL")
+			}
+			continue
+		}
+		id, err := peer.Decode(info.PeerID)
+		if err != nil {
+			d.report("bootstrap server", statusWarn, fmt.Sprintf("%s sent an invalid peer ID: %v", url, err), "")
+			continue
+		}
+		addrInfo := peer.AddrInfo{ID: id}
+		for _, raw := range info.Addresses {
+			if addr, err := multiaddr.NewMultiaddr(raw); err == nil {
+				addrInfo.Addrs = append(addrInfo.Addrs, addr)
+			}
+		}
+		d.report("bootstrap server", statusOK, fmt.Sprintf("%s offers peer %s", url, shortID(id)), "")
+		peers = append(peers, addrInfo)
+	}
+	return peers
+}
+
+func fetchBootstrapInfo(ctx context.Context, client *http.Client, url string) (*p2p.BootstrapInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	var info p2p.BootstrapInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// checkDHT joins the node's DHT through the connected bootstrap peers.
+// Public IPFS peers don't serve it, so reaching them isn't enough.
+func (d *doctor) checkDHT(ctx context.Context, h host.Host, bootstrap []peer.AddrInfo) {
+	kdht, err := dht.New(ctx, h,
+		dht.Mode(dht.ModeClient),
+		dht.ProtocolPrefix(p2p.DHTProtocolPrefix),
+		dht.BootstrapPeers(bootstrap...),
+	)
+	if err != nil {
+		d.report("dht", statusFail, err.Error(), "")
+		return
+	}
+	defer kdht.Close()
+
+	if err := kdht.Bootstrap(ctx); err != nil {
+		d.report("dht", statusFail, err.Error(), "")
+		return
+	}
+
+	deadline := time.Now().Add(d.timeout)
+	for kdht.RoutingTable().Size() == 0 && time.Now().Before(deadline) {
+		time.Sleep(500 * time.Millisecond)
+	}
+	if size := kdht.RoutingTable().Size(); size > 0 {
+		d.report("dht", statusOK, fmt.Sprintf("joined, %s in the routing table", plural(size, "peer")), "")
+		return
+	}
+	d.report("dht", statusFail,
+		fmt.Sprintf("no peer answered on the %s DHT within %s", p2p.DHTProtocolPrefix, d.timeout),
+		"the bootstrap peers don't run newsp2p; add a newsp2p node or cmd/bootstrap to p2p.bootstrap_peers")
+}
+
+// checkNAT waits for AutoNAT to decide whether peers can dial this
+// machine
+func (d *doctor) checkNAT(ctx context.Context, h host.Host, sub event.Subscription) {
+	reachability := network.ReachabilityUnknown
+	var natTypes []string
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+wait:
+	for {
+		select {
+		case e := <-sub.Out():
+			switch evt := e.(type) {
+			case event.EvtLocalReachabilityChanged:
+				reachability = evt.Reachability
+				if reachability == network.ReachabilityPublic {
+					break wait
+				}
+			case event.EvtNATDeviceTypeChanged:
+				natTypes = append(natTypes, fmt.Sprintf("%s %s", evt.TransportProtocol, evt.NatDeviceType))
+			}
+		case <-timer.C:
+			break wait
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	forwardFix := "forward the p2p ports on the router (or enable UPnP); peers can still reach this node through relays"
+	if !d.hasFixedPorts() {
+		forwardFix = "set fixed ports in p2p.listen_addrs (e.g. /ip4/0.0.0.0/tcp/4001) and forward them on the router, or enable UPnP; peers can still reach this node through relays"
+	}
+
+	switch reachability {
+	case network.ReachabilityPublic:
+		d.report("nat", statusOK, "publicly reachable at "+publicAddrs(h), "")
+	case network.ReachabilityPrivate:
+		detail := "behind NAT; peers can't dial this node directly"
+		if len(natTypes) > 0 {
+			detail += " (" + strings.Join(natTypes, ", ") + ")"
+		}
+		d.report("nat", statusWarn, detail, forwardFix)
+	default:
+		d.report("nat", statusWarn, fmt.Sprintf("reachability still unknown after %s; too few peers tried dialing back", d.timeout),
+			"run again with a longer -timeout; if it stays unknown, "+forwardFix)
+	}
+}
+
+func (d *doctor) hasFixedPorts() bool {
+	for _, raw := range d.cfg.P2P.ListenAddrs {
+		if !strings.HasSuffix(raw, "/0") && !strings.Contains(raw, "/0/") {
+			return true
+		}
+	}
+	return false
+}
+
+func publicAddrs(h host.Host) string {
+	var addrs []string
+	for _, addr := range h.Addrs() {
+		if manet.IsPublicAddr(addr) {
+			addrs = append(addrs, addr.String())
+		}
+	}
+	if len(addrs) == 0 {
+		return "(addresses not yet observed)"
+	}
+	return strings.Join(addrs, ", ")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func shortID(id peer.ID) string {
+	s := id.String()
+	if len(s) > 12 {
+		return s[:6] + "…" + s[len(s)-6:]
+	}
+	return s
+}
+
+// firstLine trims multi-line dial errors to their summary
+func firstLine(err error) string {
+	s := err.Error()
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
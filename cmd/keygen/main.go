@@ -0,0 +1,655 @@
+// Command keygen generates, inspects, exports and imports the Ed25519 keys
+// behind node and user identities. It reads the same configuration as the
+// server to find the node key and database; commands that touch users
+// open the database, so the server must be stopped first.
+//
+//	keygen inspect                       the node's peer ID and DID
+//	keygen export -o node.json           back up the node key
+//	keygen import -force node.json       restore it, on this or another machine
+//	keygen export -user alice -o alice.json
+//	keygen import -user alice alice.json move a user's identity to this node
+//	keygen sign-policy -key ops.json policy.json sign a network policy to publish
+//	keygen sign-blocklist -key curator.json list.json sign a blocklist to publish
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+const usage = `Usage:
+  keygen generate [flags]          create a node key, or a key file with -o
+  keygen inspect [flags] [file]    show the peer ID and DID of a key
+  keygen export [flags]            write the node's or a user's key to a key file
+  keygen import [flags] <file>     install a key file as the node key or a new user
+  keygen sign-policy [flags] <file> sign a network policy document with a key file
+  keygen sign-blocklist [flags] <file> sign a curator's blocklist with a key file
+
+Run "keygen <command> -h" for command flags.
+`
+
+const (
+	kindNode = "node"
+	kindUser = "user"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	commands := map[string]func([]string) error{
+		"generate":       runGenerate,
+		"inspect":        runInspect,
+		"export":         runExport,
+		"import":         runImport,
+		"sign-policy":    runSignPolicy,
+		"sign-blocklist": runSignBlocklist,
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// nodeFlags locate the node whose keys are managed
+type nodeFlags struct {
+	profile  string
+	dataRoot string
+	key      string
+}
+
+func commonFlags(fs *flag.FlagSet) *nodeFlags {
+	f := &nodeFlags{}
+	fs.StringVar(&f.profile, "profile", "", "data profile name, as given to the server")
+	fs.StringVar(&f.dataRoot, "data-root", "", "root directory for node state, as given to the server")
+	fs.StringVar(&f.key, "key", "", "node key file (default <data dir>/"+p2p.NodeKeyFile+")")
+	return f
+}
+
+func (f *nodeFlags) config() (*config.Config, error) {
+	cfg, err := config.LoadWithOverrides(config.Overrides{DataRoot: f.dataRoot, Profile: f.profile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// keyPath is the node key file, from -key or the configured data directory
+func (f *nodeFlags) keyPath() (string, error) {
+	if f.key != "" {
+		return f.key, nil
+	}
+	cfg, err := f.config()
+	if err != nil {
+		return "", fmt.Errorf("%w (or pass -key)", err)
+	}
+	dir := cfg.Data.Dir()
+	if dir == "" {
+		dir = "data"
+	}
+	return filepath.Join(dir, p2p.NodeKeyFile), nil
+}
+
+// nodeStore is the node's database, opened for user commands
+type nodeStore struct {
+	db    *badger.DB
+	repo  repository.UserRepository
+	users *service.UserService
+}
+
+func (f *nodeFlags) openStore() (*nodeStore, error) {
+	cfg, err := f.config()
+	if err != nil {
+		return nil, err
+	}
+	key, err := cfg.Database.EncryptionKeyBytes()
+	if err != nil {
+		return nil, err
+	}
+	db, err := badger.NewEncrypted(cfg.Database.Path, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w (is the server still running?)", err)
+	}
+	log, err := logger.New("error", "console")
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	repo := badger.NewUserRepo(db)
+	return &nodeStore{
+		db:    db,
+		repo:  repo,
+		users: service.NewUserService(repo, nil, cfg.Auth.BcryptCost, log),
+	}, nil
+}
+
+func (s *nodeStore) Close() error {
+	return s.db.Close()
+}
+
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	node := commonFlags(fs)
+	out := fs.String("o", "", "write a key file here instead of replacing the node key")
+	unencrypted := fs.Bool("unencrypted", false, "with -o, leave the private key unencrypted")
+	force := fs.Bool("force", false, "replace an existing node key, giving the node a new identity")
+	fs.Parse(args)
+
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return err
+	}
+
+	if *out != "" {
+		file, err := newKeyFile(kindUser, "", keyPair.PrivateKey, !*unencrypted)
+		if err != nil {
+			return err
+		}
+		if err := file.Write(*out); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote a new key to %s\n", *out)
+		printIdentity(file)
+		return nil
+	}
+
+	path, err := node.keyPath()
+	if err != nil {
+		return err
+	}
+	if err := installNodeKey(path, keyPair.PrivateKey, *force); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote a new node key to %s\n", path)
+	return inspectNodeKey(path)
+}
+
+func runInspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	node := commonFlags(fs)
+	username := fs.String("user", "", "inspect this user's key in the database")
+	publicKey := fs.String("pub", "", "inspect a base64 public key")
+	fs.Parse(args)
+
+	switch {
+	case *publicKey != "":
+		key, err := crypto.PublicKeyFromString(*publicKey)
+		if err != nil {
+			return err
+		}
+		file, err := describe("", "", key)
+		if err != nil {
+			return err
+		}
+		printIdentity(file)
+		return nil
+
+	case *username != "":
+		store, err := node.openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		user, err := store.repo.GetByUsername(context.Background(), *username)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", *username, err)
+		}
+		key, err := crypto.PublicKeyFromString(user.PublicKey)
+		if err != nil {
+			// Node users store the node's marshaled key
+			return fmt.Errorf("user %s has no Ed25519 signing key of their own: %w", *username, err)
+		}
+		file, err := describe(kindUser, user.Username, key)
+		if err != nil {
+			return err
+		}
+		printIdentity(file)
+		return nil
+
+	case fs.NArg() == 1:
+		data, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		var file crypto.KeyFile
+		if json.Unmarshal(data, &file) == nil && file.PublicKey != "" {
+			// Derive the identities rather than trusting the file's
+			key, err := crypto.PublicKeyFromString(file.PublicKey)
+			if err != nil {
+				return err
+			}
+			derived, err := describe(file.Kind, file.Username, key)
+			if err != nil {
+				return err
+			}
+			derived.PrivateKey, derived.EncryptedPrivateKey = file.PrivateKey, file.EncryptedPrivateKey
+			printIdentity(derived)
+			return nil
+		}
+		// Not a key file, so perhaps a node key like data/node_key
+		return inspectNodeKey(fs.Arg(0))
+
+	default:
+		path, err := node.keyPath()
+		if err != nil {
+			return err
+		}
+		return inspectNodeKey(path)
+	}
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	node := commonFlags(fs)
+	username := fs.String("user", "", "export this user's key (asks for their password, or NEWS_PASSWORD)")
+	out := fs.String("o", "", "key file to write (default stdout)")
+	unencrypted := fs.Bool("unencrypted", false, "leave the private key unencrypted")
+	fs.Parse(args)
+
+	var file *crypto.KeyFile
+	if *username != "" {
+		store, err := node.openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		password, err := secret("NEWS_PASSWORD", fmt.Sprintf("Password for %s: ", *username), false)
+		if err != nil {
+			return err
+		}
+		user, privateKey, err := store.users.ExportIdentity(context.Background(), *username, password)
+		if err != nil {
+			return err
+		}
+		if file, err = newKeyFile(kindUser, user.Username, privateKey, !*unencrypted); err != nil {
+			return err
+		}
+	} else {
+		path, err := node.keyPath()
+		if err != nil {
+			return err
+		}
+		privateKey, err := readNodeKey(path)
+		if err != nil {
+			return err
+		}
+		if file, err = newKeyFile(kindNode, "", privateKey, !*unencrypted); err != nil {
+			return err
+		}
+	}
+
+	if *out == "" {
+		return json.NewEncoder(os.Stdout).Encode(file)
+	}
+	if err := file.Write(*out); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %s key %s to %s\n", file.Kind, file.PeerID, *out)
+	return nil
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	node := commonFlags(fs)
+	username := fs.String("user", "", "register the key as a new user with this name (asks for a password, or NEWS_PASSWORD)")
+	email := fs.String("email", "", "with -user, the new user's email")
+	force := fs.Bool("force", false, "replace an existing node key, giving the node a new identity")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import takes exactly one key file")
+	}
+
+	privateKey, err := readKeyFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *username == "" {
+		path, err := node.keyPath()
+		if err != nil {
+			return err
+		}
+		if err := installNodeKey(path, privateKey, *force); err != nil {
+			return err
+		}
+		fmt.Printf("Installed node key at %s\n", path)
+		return inspectNodeKey(path)
+	}
+
+	store, err := node.openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	password, err := secret("NEWS_PASSWORD", fmt.Sprintf("New password for %s: ", *username), true)
+	if err != nil {
+		return err
+	}
+	user, err := store.users.ImportIdentity(context.Background(), &domain.UserRegisterRequest{
+		Username: *username,
+		Email:    *email,
+		Password: password,
+	}, privateKey)
+	if errors.Is(err, domain.ErrUserAlreadyExists) {
+		return fmt.Errorf("a user with that name, email or key already exists on this node")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported user %s (%s)\n", user.Username, user.ID)
+	return nil
+}
+
+// runSignPolicy signs a network policy document for publishing over IPNS.
+// Nodes only apply it when network.policy.publisher_key is the key's public
+// half.
+func runSignPolicy(args []string) error {
+	fs := flag.NewFlagSet("sign-policy", flag.ExitOnError)
+	keyPath := fs.String("key", "", "key file of the policy publisher")
+	out := fs.String("o", "", "signed policy to write (default stdout)")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *keyPath == "" {
+		return fmt.Errorf("sign-policy takes -key and exactly one policy file")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var policy domain.NetworkPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	if policy.Version <= 0 {
+		return fmt.Errorf("%s: version must be positive and increase with every edition", fs.Arg(0))
+	}
+	if policy.IssuedAt.IsZero() {
+		policy.IssuedAt = time.Now().UTC()
+	}
+
+	privateKey, err := readKeyFile(*keyPath)
+	if err != nil {
+		return err
+	}
+	content, err := policy.SignableContent()
+	if err != nil {
+		return err
+	}
+	if policy.Signature, err = crypto.Sign(content, privateKey); err != nil {
+		return err
+	}
+
+	signed, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = fmt.Println(string(signed))
+		return err
+	}
+	if err := os.WriteFile(*out, append(signed, '\n'), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Signed policy version %d with %s\n", policy.Version, crypto.PublicKeyToString(privateKey.Public().(ed25519.PublicKey)))
+	return nil
+}
+
+func runSignBlocklist(args []string) error {
+	fs := flag.NewFlagSet("sign-blocklist", flag.ExitOnError)
+	keyPath := fs.String("key", "", "key file of the curator")
+	out := fs.String("o", "", "signed blocklist to write (default stdout)")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *keyPath == "" {
+		return fmt.Errorf("sign-blocklist takes -key and exactly one blocklist file")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var list domain.Blocklist
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	if list.Version <= 0 {
+		return fmt.Errorf("%s: version must be positive and increase with every edition", fs.Arg(0))
+	}
+	if list.IssuedAt.IsZero() {
+		list.IssuedAt = time.Now().UTC()
+	}
+
+	privateKey, err := readKeyFile(*keyPath)
+	if err != nil {
+		return err
+	}
+	content, err := list.SignableContent()
+	if err != nil {
+		return err
+	}
+	if list.Signature, err = crypto.Sign(content, privateKey); err != nil {
+		return err
+	}
+
+	signed, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = fmt.Println(string(signed))
+		return err
+	}
+	if err := os.WriteFile(*out, append(signed, '\n'), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Signed blocklist version %d with %s\n", list.Version, crypto.PublicKeyToString(privateKey.Public().(ed25519.PublicKey)))
+	return nil
+}
+
+// describe derives the identities of a public key
+func describe(kind, username string, publicKey ed25519.PublicKey) (*crypto.KeyFile, error) {
+	libp2pKey, err := libp2pcrypto.UnmarshalEd25519PublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	peerID, err := peer.IDFromPublicKey(libp2pKey)
+	if err != nil {
+		return nil, err
+	}
+	return &crypto.KeyFile{
+		Kind:      kind,
+		Username:  username,
+		PeerID:    peerID.String(),
+		DID:       crypto.DIDKey(publicKey),
+		PublicKey: crypto.PublicKeyToString(publicKey),
+	}, nil
+}
+
+// newKeyFile describes a private key, encrypting it with a passphrase
+// from NEWS_KEY_PASSPHRASE or the terminal when asked to
+func newKeyFile(kind, username string, privateKey ed25519.PrivateKey, encrypt bool) (*crypto.KeyFile, error) {
+	file, err := describe(kind, username, privateKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	if !encrypt {
+		file.PrivateKey = crypto.PrivateKeyToString(privateKey)
+		return file, nil
+	}
+
+	passphrase, err := secret("NEWS_KEY_PASSPHRASE", "Passphrase for the key file: ", true)
+	if err != nil {
+		return nil, err
+	}
+	if file.EncryptedPrivateKey, err = crypto.EncryptPrivateKey(privateKey, passphrase); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// readKeyFile returns the private key in a key file, asking for its
+// passphrase if it is encrypted
+func readKeyFile(path string) (ed25519.PrivateKey, error) {
+	file, err := crypto.ReadKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var passphrase string
+	if file.Encrypted() {
+		if passphrase, err = secret("NEWS_KEY_PASSPHRASE", "Passphrase for "+path+": ", false); err != nil {
+			return nil, err
+		}
+	}
+	privateKey, err := file.Key(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return privateKey, nil
+}
+
+// readNodeKey returns the Ed25519 key in a node key file
+func readNodeKey(path string) (ed25519.PrivateKey, error) {
+	key, err := p2p.LoadNodeKey(path)
+	if err != nil {
+		return nil, err
+	}
+	if key.Type() != libp2pcrypto.Ed25519 {
+		return nil, fmt.Errorf("%s holds a %s key, not Ed25519", path, key.Type())
+	}
+	raw, err := key.Raw()
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.PrivateKey(raw), nil
+}
+
+// installNodeKey writes a node key file. An existing key is only replaced
+// with force: it is the node's identity on the network.
+func installNodeKey(path string, privateKey ed25519.PrivateKey, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists; pass -force to replace the node's identity", path)
+	}
+	key, err := libp2pcrypto.UnmarshalEd25519PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	return p2p.SaveNodeKey(path, key)
+}
+
+func inspectNodeKey(path string) error {
+	privateKey, err := readNodeKey(path)
+	if err != nil {
+		return err
+	}
+	file, err := describe(kindNode, "", privateKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		return err
+	}
+	printIdentity(file)
+	return nil
+}
+
+func printIdentity(file *crypto.KeyFile) {
+	if file.Kind != "" {
+		fmt.Printf("Kind:        %s\n", file.Kind)
+	}
+	if file.Username != "" {
+		fmt.Printf("Username:    %s\n", file.Username)
+	}
+	fmt.Printf("Peer ID:     %s\n", file.PeerID)
+	fmt.Printf("DID:         %s\n", file.DID)
+	fmt.Printf("Public key:  %s\n", file.PublicKey)
+	if key, err := crypto.PublicKeyFromString(file.PublicKey); err == nil {
+		fmt.Printf("Fingerprint: %s\n", crypto.Fingerprint(key))
+	}
+	if file.EncryptedPrivateKey != "" {
+		fmt.Println("Private key: encrypted")
+	} else if file.PrivateKey != "" {
+		fmt.Println("Private key: unencrypted")
+	}
+}
+
+// secret reads a password or passphrase from env, else from the terminal
+// with echo turned off. New secrets are asked for twice.
+func secret(env, prompt string, confirm bool) (string, error) {
+	if value := os.Getenv(env); value != "" {
+		return value, nil
+	}
+
+	value, err := promptHidden(prompt)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", fmt.Errorf("empty %s", strings.TrimSuffix(strings.ToLower(prompt), ": "))
+	}
+	if confirm {
+		again, err := promptHidden("Again: ")
+		if err != nil {
+			return "", err
+		}
+		if again != value {
+			return "", fmt.Errorf("the two entries don't match")
+		}
+	}
+	return value, nil
+}
+
+// stdin is shared by every prompt, so buffered input isn't lost between
+// them
+var stdin = bufio.NewReader(os.Stdin)
+
+// promptHidden reads a line with echo turned off. Where stty isn't
+// available the line is read as typed.
+func promptHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+const adminUsage = `Usage:
+  newsp2p admin reindex [flags]              rebuild the search index
+  newsp2p admin verify [flags]               check signatures, CIDs and indexes (-repair fixes indexes)
+  newsp2p admin users [flags]                list users
+  newsp2p admin deactivate [flags] <user>    stop a user logging in and posting
+  newsp2p admin activate [flags] <user>      re-enable a user
+  newsp2p admin backup [flags]               write a backup of the node's store
+  newsp2p admin restore [flags] <file>       load a backup into a stopped node (-local only)
+  newsp2p admin prune [flags]                unpin content past its retention (-dry-run lists it)
+  newsp2p admin audit [flags]                show the audit log (-verify checks its hash chain)
+  newsp2p admin loglevel [flags] [<component> <level>]
+                                             show or change log levels on the running node;
+                                             component "default" sets the default, level
+                                             "reset" removes a component's override
+
+Commands use the admin API with the profile's token, or with -local work
+on the node's data directory directly; the server must be stopped for that.
+`
+
+// adminTimeout bounds admin API calls, which walk the whole store
+const adminTimeout = 30 * time.Minute
+
+// adminBackend runs maintenance on a node, through its admin API or on
+// its data directory
+type adminBackend interface {
+	reindex(ctx context.Context) (*service.ReindexReport, error)
+	verify(ctx context.Context, repair bool) (*domain.IntegrityReport, error)
+	users(ctx context.Context) ([]*domain.UserResponse, error)
+	setActive(ctx context.Context, ref string, active bool) (*domain.UserResponse, error)
+	backup(ctx context.Context, w io.Writer, since uint64) (uint64, error)
+	prune(ctx context.Context, dryRun, repoGC bool) (*domain.GCReport, error)
+	audit(ctx context.Context, filter *domain.AuditListFilter) ([]*domain.AuditEntry, error)
+	verifyAudit(ctx context.Context) (*domain.AuditVerifyReport, error)
+	// logLevels shows the running node's levels, changing them first
+	// unless req is nil
+	logLevels(ctx context.Context, req *handlers.LogLevelsRequest) (*logger.LevelSnapshot, error)
+	Close() error
+}
+
+// adminFlags pick the backend: the API by default, the data directory
+// with -local
+type adminFlags struct {
+	conn        *connFlags
+	local       bool
+	dataRoot    string
+	dataProfile string
+	asJSON      bool
+}
+
+func newAdminFlags(fs *flag.FlagSet) *adminFlags {
+	f := &adminFlags{conn: commonFlags(fs)}
+	fs.BoolVar(&f.local, "local", false, "work on the node's data directory instead of its API")
+	fs.StringVar(&f.dataRoot, "data-root", "", "with -local, the server's --data-root")
+	fs.StringVar(&f.dataProfile, "data-profile", "", "with -local, the server's --profile")
+	fs.BoolVar(&f.asJSON, "json", false, "print the result as JSON")
+	return f
+}
+
+func (f *adminFlags) open() (adminBackend, error) {
+	if f.local {
+		return openLocalAdmin(f.dataRoot, f.dataProfile)
+	}
+	c, err := connect(f.conn)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requireLogin(); err != nil {
+		return nil, err
+	}
+	c.http.Timeout = adminTimeout
+	return &apiAdmin{c: c}, nil
+}
+
+func runAdmin(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, adminUsage)
+		os.Exit(2)
+	}
+	op, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("admin "+op, flag.ExitOnError)
+	flags := newAdminFlags(fs)
+	repair := fs.Bool("repair", false, "verify: fix index inconsistencies")
+	dryRun := fs.Bool("dry-run", false, "prune: report what would be unpinned without unpinning")
+	repoGC := fs.Bool("repo-gc", false, "prune: also run IPFS repo GC to reclaim disk space")
+	out := fs.String("o", "", "backup: file to write (default newsp2p-<time>.badger)")
+	since := fs.Uint64("since", 0, "backup: only changes after this backup version")
+	force := fs.Bool("force", false, "restore: load into a store that already holds data")
+	verifyChain := fs.Bool("verify", false, "audit: check the hash chain instead of listing entries")
+	action := fs.String("action", "", "audit: only entries with this action, e.g. user.deactivate")
+	actor := fs.String("actor", "", "audit: only entries by this username or user ID")
+	limit := fs.Int("limit", 50, "audit: entries to show")
+	fs.Parse(args)
+
+	if op == "restore" {
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: newsp2p admin restore [flags] <file>")
+		}
+		return restoreLocal(flags.dataRoot, flags.dataProfile, fs.Arg(0), *force)
+	}
+
+	var run func(ctx context.Context, admin adminBackend) (interface{}, error)
+	switch op {
+	case "reindex":
+		run = func(ctx context.Context, admin adminBackend) (interface{}, error) {
+			return admin.reindex(ctx)
+		}
+	case "verify":
+		run = func(ctx context.Context, admin adminBackend) (interface{}, error) {
+			return admin.verify(ctx, *repair)
+		}
+	case "users":
+		run = func(ctx context.Context, admin adminBackend) (interface{}, error) {
+			return admin.users(ctx)
+		}
+	case "activate", "deactivate":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: newsp2p admin %s [flags] <user ID or username>", op)
+		}
+		run = func(ctx context.Context, admin adminBackend) (interface{}, error) {
+			return admin.setActive(ctx, fs.Arg(0), op == "activate")
+		}
+	case "backup":
+		run = func(ctx context.Context, admin adminBackend) (interface{}, error) {
+			return backup(ctx, admin, *out, *since)
+		}
+	case "prune":
+		run = func(ctx context.Context, admin adminBackend) (interface{}, error) {
+			return admin.prune(ctx, *dryRun, *repoGC)
+		}
+	case "audit":
+		run = func(ctx context.Context, admin adminBackend) (interface{}, error) {
+			if *verifyChain {
+				return admin.verifyAudit(ctx)
+			}
+			return admin.audit(ctx, &domain.AuditListFilter{Action: *action, Actor: *actor, Page: 1, Limit: *limit})
+		}
+	case "loglevel":
+		var req *handlers.LogLevelsRequest
+		switch {
+		case fs.NArg() == 2 && fs.Arg(0) == "default":
+			req = &handlers.LogLevelsRequest{Level: fs.Arg(1)}
+		case fs.NArg() == 2:
+			level := fs.Arg(1)
+			if level == "reset" {
+				level = ""
+			}
+			req = &handlers.LogLevelsRequest{Components: map[string]string{fs.Arg(0): level}}
+		case fs.NArg() != 0:
+			return fmt.Errorf("usage: newsp2p admin loglevel [flags] [<component>|default <level>]")
+		}
+		run = func(ctx context.Context, admin adminBackend) (interface{}, error) {
+			return admin.logLevels(ctx, req)
+		}
+	default:
+		fmt.Fprint(os.Stderr, adminUsage)
+		os.Exit(2)
+	}
+
+	admin, err := flags.open()
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	result, err := run(context.Background(), admin)
+	if err != nil {
+		return err
+	}
+	if flags.asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	printAdminResult(result)
+	return nil
+}
+
+// backupResult describes a written backup
+type backupResult struct {
+	Path    string `json:"path"`
+	Bytes   int64  `json:"bytes"`
+	Version uint64 `json:"version"`
+}
+
+func backup(ctx context.Context, admin adminBackend, path string, since uint64) (*backupResult, error) {
+	if path == "" {
+		path = fmt.Sprintf("newsp2p-%s.badger", time.Now().UTC().Format("20060102-150405"))
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	counter := &countingWriter{w: file}
+	version, err := admin.backup(ctx, counter, since)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("backup failed: %w", err)
+	}
+	return &backupResult{Path: path, Bytes: counter.n, Version: version}, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func printAdminResult(result interface{}) {
+	switch r := result.(type) {
+	case *service.ReindexReport:
+		fmt.Printf("Reindexed %d documents in %dms\n", r.Documents, r.DurationMs)
+
+	case *domain.IntegrityReport:
+		fmt.Printf("Checked %d articles and %d CIDs: %d issues, %d repaired\n",
+			r.ArticlesChecked, r.CIDsChecked, len(r.Issues), r.Repaired)
+		for _, issue := range r.Issues {
+			ref := issue.ArticleID
+			if ref == "" {
+				ref = issue.Key
+			}
+			status := ""
+			if issue.Repaired {
+				status = " (repaired)"
+			}
+			fmt.Printf("  %-18s %s: %s%s\n", issue.Kind, ref, issue.Detail, status)
+		}
+		for _, warning := range r.Warnings {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+
+	case []*domain.UserResponse:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "USERNAME\tID\tACTIVE\tCREATED")
+		for _, user := range r {
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", user.Username, user.ID, user.IsActive, user.CreatedAt.Format("2006-01-02"))
+		}
+		w.Flush()
+
+	case *domain.UserResponse:
+		state := "deactivated"
+		if r.IsActive {
+			state = "active"
+		}
+		fmt.Printf("%s (%s) is %s\n", r.Username, r.ID, state)
+
+	case *backupResult:
+		fmt.Printf("Wrote %d bytes to %s\n", r.Bytes, r.Path)
+		if r.Version > 0 {
+			fmt.Printf("Next incremental backup: -since %d\n", r.Version)
+		}
+
+	case *domain.GCReport:
+		verb := "Unpinned"
+		if r.DryRun {
+			verb = "Would unpin"
+		}
+		fmt.Printf("%s %d CIDs (%d failed)\n", verb, len(r.Unpinned), r.Failed)
+		for _, unpin := range r.Unpinned {
+			fmt.Printf("  %s %s\n", unpin.CID, unpin.Reason)
+		}
+		if r.RepoGC {
+			fmt.Printf("Repo GC removed %d blocks, reclaiming %d bytes\n", r.RemovedBlocks, r.Reclaimed)
+		}
+		for _, warning := range r.Warnings {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+
+	case []*domain.AuditEntry:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "SEQ\tTIME\tACTOR\tACTION\tTARGET\tDETAILS")
+		for _, entry := range r {
+			details := make([]string, 0, len(entry.Details))
+			for k, v := range entry.Details {
+				details = append(details, k+"="+strconv.Quote(v))
+			}
+			sort.Strings(details)
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", entry.Seq, entry.Time.Local().Format(time.DateTime),
+				entry.Actor, entry.Action, entry.Target, strings.Join(details, " "))
+		}
+		w.Flush()
+
+	case *domain.AuditVerifyReport:
+		if r.Valid {
+			fmt.Printf("Audit log intact: %d entries, head %s\n", r.Entries, r.Head)
+		} else {
+			fmt.Printf("Audit log BROKEN at entry %d: %s (%d entries verified before it)\n", r.BrokenAt, r.Problem, r.Entries)
+		}
+
+	case *logger.LevelSnapshot:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "COMPONENT\tLEVEL")
+		fmt.Fprintf(w, "(default)\t%s\n", r.Level)
+		for _, component := range r.Known {
+			if level, ok := r.Components[component]; ok {
+				fmt.Fprintf(w, "%s\t%s\n", component, level)
+			} else {
+				fmt.Fprintf(w, "%s\t-\n", component)
+			}
+		}
+		// Overrides for components that have not logged yet
+		for _, component := range slices.Sorted(maps.Keys(r.Components)) {
+			if !slices.Contains(r.Known, component) {
+				fmt.Fprintf(w, "%s\t%s\n", component, r.Components[component])
+			}
+		}
+		w.Flush()
+	}
+}
+
+// apiAdmin runs maintenance through the admin API
+type apiAdmin struct {
+	c *client
+}
+
+// reindex starts a reindex on the server and waits for it to finish
+func (a *apiAdmin) reindex(ctx context.Context) (*service.ReindexReport, error) {
+	var report envelope[*service.ReindexReport]
+	if err := a.c.call(http.MethodPost, "/api/v1/admin/reindex", nil, &report); err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for report.Data != nil && report.Data.Running {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		report = envelope[*service.ReindexReport]{}
+		if err := a.c.call(http.MethodGet, "/api/v1/admin/reindex", nil, &report); err != nil {
+			return nil, err
+		}
+	}
+	if report.Data != nil && report.Data.Error != "" {
+		return nil, fmt.Errorf("reindex failed: %s", report.Data.Error)
+	}
+	return report.Data, nil
+}
+
+func (a *apiAdmin) verify(ctx context.Context, repair bool) (*domain.IntegrityReport, error) {
+	var report envelope[*domain.IntegrityReport]
+	return report.Data, a.c.call(http.MethodPost, "/api/v1/admin/verify?repair="+strconv.FormatBool(repair), nil, &report)
+}
+
+func (a *apiAdmin) users(ctx context.Context) ([]*domain.UserResponse, error) {
+	var users envelope[[]*domain.UserResponse]
+	return users.Data, a.c.call(http.MethodGet, "/api/v1/admin/users", nil, &users)
+}
+
+func (a *apiAdmin) setActive(ctx context.Context, ref string, active bool) (*domain.UserResponse, error) {
+	action := "deactivate"
+	if active {
+		action = "activate"
+	}
+	var user envelope[*domain.UserResponse]
+	return user.Data, a.c.call(http.MethodPost, "/api/v1/admin/users/"+url.PathEscape(ref)+"/"+action, nil, &user)
+}
+
+func (a *apiAdmin) backup(ctx context.Context, w io.Writer, since uint64) (uint64, error) {
+	// Backups stream for as long as the store takes
+	a.c.http.Timeout = 0
+	resp, err := a.c.do(http.MethodGet, "/api/v1/admin/backup?since="+strconv.FormatUint(since, 10), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return 0, err
+	}
+	// The server only sends the version once the whole store is written
+	raw := resp.Trailer.Get("X-Backup-Version")
+	if raw == "" {
+		return 0, errors.New("the server did not finish the backup; check its log")
+	}
+	return strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+}
+
+func (a *apiAdmin) prune(ctx context.Context, dryRun, repoGC bool) (*domain.GCReport, error) {
+	query := url.Values{}
+	query.Set("dry_run", strconv.FormatBool(dryRun))
+	query.Set("repo_gc", strconv.FormatBool(repoGC))
+	var report envelope[*domain.GCReport]
+	return report.Data, a.c.call(http.MethodPost, "/api/v1/admin/gc?"+query.Encode(), nil, &report)
+}
+
+func (a *apiAdmin) audit(ctx context.Context, filter *domain.AuditListFilter) ([]*domain.AuditEntry, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(filter.Page))
+	query.Set("limit", strconv.Itoa(filter.Limit))
+	if filter.Action != "" {
+		query.Set("action", filter.Action)
+	}
+	if filter.Actor != "" {
+		query.Set("actor", filter.Actor)
+	}
+	var entries envelope[[]*domain.AuditEntry]
+	return entries.Data, a.c.call(http.MethodGet, "/api/v1/admin/audit?"+query.Encode(), nil, &entries)
+}
+
+func (a *apiAdmin) verifyAudit(ctx context.Context) (*domain.AuditVerifyReport, error) {
+	var report envelope[*domain.AuditVerifyReport]
+	return report.Data, a.c.call(http.MethodGet, "/api/v1/admin/audit/verify", nil, &report)
+}
+
+func (a *apiAdmin) logLevels(ctx context.Context, req *handlers.LogLevelsRequest) (*logger.LevelSnapshot, error) {
+	var levels envelope[*logger.LevelSnapshot]
+	if req == nil {
+		return levels.Data, a.c.call(http.MethodGet, "/api/v1/admin/log-levels", nil, &levels)
+	}
+	return levels.Data, a.c.call(http.MethodPut, "/api/v1/admin/log-levels", req, &levels)
+}
+
+func (a *apiAdmin) Close() error {
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// restoreBatch is the number of pending writes Badger buffers while
+// loading a backup
+const restoreBatch = 256
+
+// localAdmin runs maintenance directly on a stopped node's data
+// directory, wiring the same services the server does
+type localAdmin struct {
+	cfg *config.Config
+	db  *badger.DB
+	log *logger.Logger
+}
+
+func loadNodeConfig(dataRoot, profile string) (*config.Config, error) {
+	cfg, err := config.LoadWithOverrides(config.Overrides{DataRoot: dataRoot, Profile: profile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+func openNodeDB(cfg *config.Config) (*badger.DB, error) {
+	key, err := cfg.Database.EncryptionKeyBytes()
+	if err != nil {
+		return nil, err
+	}
+	db, err := badger.NewEncrypted(cfg.Database.Path, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w (is the server still running?)", err)
+	}
+	return db, nil
+}
+
+func openLocalAdmin(dataRoot, profile string) (*localAdmin, error) {
+	cfg, err := loadNodeConfig(dataRoot, profile)
+	if err != nil {
+		return nil, err
+	}
+	log, err := logger.New("error", "console")
+	if err != nil {
+		return nil, err
+	}
+	db, err := openNodeDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &localAdmin{cfg: cfg, db: db, log: log}, nil
+}
+
+func (a *localAdmin) ipfsClient() (*ipfs.Client, error) {
+	client := ipfs.NewClient(a.cfg.IPFS.APIEndpoint, a.cfg.IPFS.Timeout, a.cfg.IPFS.PinArticles, a.log)
+	if err := client.SetAddOptions(ipfs.AddOptions{
+		CIDVersion: a.cfg.IPFS.Add.CIDVersion,
+		RawLeaves:  a.cfg.IPFS.Add.RawLeaves,
+		Hash:       a.cfg.IPFS.Add.Hash,
+	}); err != nil {
+		return nil, fmt.Errorf("invalid ipfs.add options: %w", err)
+	}
+	client.SetTimeouts(ipfs.Timeouts{
+		Add:  a.cfg.IPFS.Timeouts.Add,
+		Cat:  a.cfg.IPFS.Timeouts.Cat,
+		Pin:  a.cfg.IPFS.Timeouts.Pin,
+		IPNS: a.cfg.IPFS.Timeouts.IPNS,
+	})
+	return client, nil
+}
+
+// reindex rebuilds the index without the trust signals the server adds
+// from peer reputation; those fill in as articles are next indexed
+func (a *localAdmin) reindex(ctx context.Context) (*service.ReindexReport, error) {
+	index := search.NewBleveIndex(a.log)
+	if err := index.Open(a.cfg.Search.IndexPath); err != nil {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+	defer index.Close()
+
+	searchService := service.NewSearchService(index, badger.NewArticleRepo(a.db), a.cfg.Search.MaxFuzziness, a.log)
+	return searchService.Reindex(ctx)
+}
+
+func (a *localAdmin) verify(ctx context.Context, repair bool) (*domain.IntegrityReport, error) {
+	client, err := a.ipfsClient()
+	if err != nil {
+		return nil, err
+	}
+	articles := badger.NewArticleRepo(a.db)
+	integrity := service.NewIntegrityService(articles, articles, client, auth.NewArticleSigner(), a.log)
+	return integrity.Verify(ctx, repair)
+}
+
+func (a *localAdmin) users(ctx context.Context) ([]*domain.UserResponse, error) {
+	return a.userService().ListUsers(ctx)
+}
+
+func (a *localAdmin) setActive(ctx context.Context, ref string, active bool) (*domain.UserResponse, error) {
+	users := a.userService()
+	users.SetAuditLog(a.auditService())
+	return users.SetActive(localActor(ctx), ref, active)
+}
+
+func (a *localAdmin) audit(ctx context.Context, filter *domain.AuditListFilter) ([]*domain.AuditEntry, error) {
+	entries, _, err := a.auditService().List(ctx, filter)
+	return entries, err
+}
+
+func (a *localAdmin) verifyAudit(ctx context.Context) (*domain.AuditVerifyReport, error) {
+	return a.auditService().Verify(ctx)
+}
+
+func (a *localAdmin) auditService() *service.AuditService {
+	return service.NewAuditService(badger.NewAuditRepo(a.db), a.log)
+}
+
+// localActor attributes changes made with -local, which has no login, to
+// the operating system user running the command
+func localActor(ctx context.Context) context.Context {
+	name := "local"
+	if u, err := user.Current(); err == nil {
+		name = "local:" + u.Username
+	}
+	return auth.WithActor(ctx, "", name)
+}
+
+func (a *localAdmin) userService() *service.UserService {
+	return service.NewUserService(badger.NewUserRepo(a.db), nil, a.cfg.Auth.BcryptCost, a.log)
+}
+
+func (a *localAdmin) backup(ctx context.Context, w io.Writer, since uint64) (uint64, error) {
+	return a.db.Backup(w, since)
+}
+
+func (a *localAdmin) prune(ctx context.Context, dryRun, repoGC bool) (*domain.GCReport, error) {
+	if !a.cfg.IPFS.PinArticles {
+		return nil, errors.New("ipfs.pin_articles is off, so this node pins nothing to prune")
+	}
+	client, err := a.ipfsClient()
+	if err != nil {
+		return nil, err
+	}
+	gc := service.NewGCService(badger.NewPinRepo(a.db), badger.NewArticleRepo(a.db), client, service.RetentionPolicy{
+		RevisionRetention: a.cfg.IPFS.GC.RevisionRetention,
+		ArticleMaxAge:     a.cfg.IPFS.GC.ArticleMaxAge,
+		RepoGC:            a.cfg.IPFS.GC.RepoGC,
+	}, a.log)
+	return gc.Run(ctx, dryRun, repoGC)
+}
+
+func (a *localAdmin) logLevels(ctx context.Context, req *handlers.LogLevelsRequest) (*logger.LevelSnapshot, error) {
+	return nil, errors.New("log levels belong to a running node; drop -local")
+}
+
+func (a *localAdmin) Close() error {
+	return a.db.Close()
+}
+
+// restoreLocal loads a backup into a stopped node's store. Badger can't
+// load into a store another process has open, so there is no API
+// equivalent.
+func restoreLocal(dataRoot, profile, path string, force bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	cfg, err := loadNodeConfig(dataRoot, profile)
+	if err != nil {
+		return err
+	}
+	db, err := openNodeDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Loading over existing data merges the two, keeping the newer
+	// version of each key; only do that when asked to
+	empty, err := db.Empty()
+	if err != nil {
+		return err
+	}
+	if !empty && !force {
+		return fmt.Errorf("%s already holds data; restore into a fresh data directory or pass -force to merge", cfg.Database.Path)
+	}
+
+	if err := db.Load(file, restoreBatch); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	fmt.Printf("Restored %s into %s\n", path, cfg.Database.Path)
+	fmt.Println("The search index is not part of a backup; run \"newsp2p admin reindex -local\" before starting the server.")
+	return nil
+}
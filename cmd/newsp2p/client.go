@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// refreshMargin is how close to expiry an access token is refreshed
+// before use
+const refreshMargin = time.Minute
+
+// envelope is the API's response body
+type envelope[T any] struct {
+	Data       T                    `json:"data"`
+	Pagination *response.Pagination `json:"pagination,omitempty"`
+}
+
+// connFlags are the connection flags shared by every command
+type connFlags struct {
+	profile string
+	server  string
+	token   string
+}
+
+func commonFlags(fs *flag.FlagSet) *connFlags {
+	f := &connFlags{}
+	fs.StringVar(&f.profile, "profile", "", "profile to use (or NEWS_PROFILE; default the current profile)")
+	fs.StringVar(&f.server, "server", "", "node API address, overriding the profile's")
+	fs.StringVar(&f.token, "token", os.Getenv("NEWS_TOKEN"), "JWT access token, overriding the profile's (or NEWS_TOKEN)")
+	return f
+}
+
+// client talks to a node's HTTP API
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+
+	// The profile the session came from, refreshed and saved in place
+	name  string
+	prof  *profile
+	store *profileStore
+}
+
+// connect builds a client from a command's connection flags
+func connect(f *connFlags) (*client, error) {
+	store, err := loadStore()
+	if err != nil {
+		return nil, err
+	}
+	name := store.selected(f.profile)
+	prof, err := store.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{
+		server: prof.Server,
+		token:  prof.AccessToken,
+		http:   &http.Client{Timeout: time.Minute},
+		name:   name,
+		prof:   prof,
+		store:  store,
+	}
+	if f.server != "" {
+		c.server = f.server
+	}
+	if f.token != "" {
+		// An explicit token isn't the profile's session
+		c.token = f.token
+		c.prof = nil
+	}
+	c.server = strings.TrimRight(c.server, "/")
+	return c, nil
+}
+
+// requireLogin makes sure there is a token to send, refreshing the
+// profile's session if it is about to expire
+func (c *client) requireLogin() error {
+	if c.prof != nil && c.prof.RefreshToken != "" && time.Until(c.prof.ExpiresAt) < refreshMargin {
+		var tokens envelope[domain.AuthTokens]
+		err := c.call(http.MethodPost, "/api/v1/auth/refresh", map[string]string{"refresh_token": c.prof.RefreshToken}, &tokens)
+		if err != nil {
+			return fmt.Errorf("session expired, run \"newsp2p login\" again: %w", err)
+		}
+		c.prof.AccessToken = tokens.Data.AccessToken
+		c.prof.RefreshToken = tokens.Data.RefreshToken
+		c.prof.ExpiresAt = tokens.Data.ExpiresAt
+		c.token = c.prof.AccessToken
+		if err := c.store.save(); err != nil {
+			return err
+		}
+	}
+
+	if c.token == "" {
+		return fmt.Errorf("not logged in to profile %q, run \"newsp2p login\" first", c.name)
+	}
+	return nil
+}
+
+// call sends in as JSON (when not nil) and decodes the response into out
+// (when not nil)
+func (c *client) call(method, path string, in, out interface{}) error {
+	resp, err := c.do(method, path, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// do sends in as JSON (when not nil) and turns non-2xx responses into
+// errors. The caller closes the body.
+func (c *client) do(method, path string, in interface{}) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		// v1 errors carry "error", problem details carry "detail"
+		var apiErr struct {
+			Error  string `json:"error"`
+			Detail string `json:"detail"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		message := apiErr.Error
+		if message == "" {
+			message = apiErr.Detail
+		}
+		if message == "" {
+			message = resp.Status
+		}
+		return nil, fmt.Errorf("%s %s: %s", method, path, message)
+	}
+	return resp, nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
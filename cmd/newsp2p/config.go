@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/multiformats/go-multiaddr"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/alerts"
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func runConfig(args []string) error {
+	const configUsage = "usage: newsp2p config validate [flags]"
+	if len(args) == 0 || args[0] != "validate" {
+		return errors.New(configUsage)
+	}
+	return runConfigValidate(args[1:])
+}
+
+// configCheck prints one line per setting checked and remembers failures
+type configCheck struct {
+	failed int
+}
+
+func (c *configCheck) report(setting string, err error, detail string) {
+	if err != nil {
+		c.failed++
+		fmt.Printf("FAIL  %-28s %v\n", setting, err)
+		return
+	}
+	fmt.Printf("ok    %-28s %s\n", setting, detail)
+}
+
+// runConfigValidate loads the node configuration the way the server does
+// and checks what loading can't: that multiaddrs and notification URLs
+// parse and that the IPFS daemon and cluster answer. Nothing is started or
+// written, so it is safe to run before restarting a live node.
+func runConfigValidate(args []string) error {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	var overrides config.Overrides
+	fs.StringVar(&overrides.ConfigFile, "config", "", "config file, as given to the server")
+	fs.StringVar(&overrides.DataRoot, "data-dir", "", "data directory, as given to the server")
+	fs.StringVar(&overrides.Profile, "profile", "", "data profile, as given to the server")
+	offline := fs.Bool("offline", false, "skip the IPFS and cluster reachability probes")
+	timeout := fs.Duration("timeout", 5*time.Second, "how long each probe may take")
+	fs.Parse(args)
+
+	cfg, err := config.LoadWithOverrides(overrides)
+	if err != nil {
+		return err
+	}
+	c := &configCheck{}
+	source := config.FileUsed()
+	if source == "" {
+		source = "no file; defaults and environment"
+	}
+	c.report("config", nil, source)
+	if overlay := config.ProfileFileUsed(); overlay != "" {
+		c.report("profile "+cfg.Data.Profile, nil, overlay)
+	}
+
+	if cfg.P2P.Enabled {
+		network := "private"
+		if _, ok := config.Networks[cfg.Network.Name]; ok {
+			network = "built-in"
+		}
+		c.report("network "+cfg.Network.Name, nil, network+", rendezvous "+cfg.P2P.Rendezvous)
+		for i, addr := range cfg.P2P.ListenAddrs {
+			_, err := multiaddr.NewMultiaddr(addr)
+			c.report(fmt.Sprintf("p2p.listen_addrs[%d]", i), err, addr)
+		}
+		for i, addr := range cfg.P2P.BootstrapPeers {
+			c.report(fmt.Sprintf("p2p.bootstrap_peers[%d]", i), checkBootstrapPeers([]string{addr}), addr)
+		}
+	}
+	if cfg.Alerts.Enabled {
+		for i, target := range cfg.Alerts.Notify {
+			notifier, err := alerts.NewNotifier(target, cfg.Alerts.Secret)
+			detail := ""
+			if err == nil {
+				detail = notifier.String()
+			}
+			c.report(fmt.Sprintf("alerts.notify[%d]", i), err, detail)
+		}
+	}
+
+	if !*offline {
+		log, err := logger.New("error", "console")
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		client := ipfs.NewClient(cfg.IPFS.APIEndpoint, *timeout, cfg.IPFS.PinArticles, log)
+		id, err := client.GetID(ctx)
+		if err != nil {
+			err = fmt.Errorf("%s is not reachable: %w", cfg.IPFS.APIEndpoint, err)
+		}
+		c.report("ipfs.api_endpoint", err, cfg.IPFS.APIEndpoint+", peer "+id)
+
+		if cfg.IPFS.Cluster.Endpoint != "" {
+			cluster := ipfs.NewClusterClient(cfg.IPFS.Cluster.Endpoint, 0, 0, *timeout, log)
+			cluster.SetBasicAuth(cfg.IPFS.Cluster.Username, cfg.IPFS.Cluster.Password)
+			id, err := cluster.ID(ctx)
+			if err != nil {
+				err = fmt.Errorf("%s is not reachable: %w", cfg.IPFS.Cluster.Endpoint, err)
+			}
+			c.report("ipfs.cluster.endpoint", err, cfg.IPFS.Cluster.Endpoint+", peer "+id)
+		}
+	}
+
+	if c.failed > 0 {
+		return fmt.Errorf("%d setting(s) failed validation", c.failed)
+	}
+	return nil
+}
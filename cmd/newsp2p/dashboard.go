@@ -0,0 +1,545 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+)
+
+// dashboardRequestTimeout keeps one slow endpoint from freezing the
+// dashboard; the next refresh tries again
+const dashboardRequestTimeout = 5 * time.Second
+
+// dashboardArticles is how many recent articles the dashboard lists
+const dashboardArticles = 8
+
+func runDashboard(args []string) error {
+	fs := flag.NewFlagSet("dashboard", flag.ExitOnError)
+	conn := commonFlags(fs)
+	interval := fs.Duration("interval", 2*time.Second, "refresh interval")
+	fs.Parse(args)
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	// The pin queue needs an admin session; everything else is public, so
+	// a missing or expired session only hides that panel
+	if c.token != "" && c.requireLogin() != nil {
+		c.token = ""
+	}
+	c.http.Timeout = dashboardRequestTimeout
+
+	_, err = tea.NewProgram(newDashboard(c, *interval), tea.WithAltScreen()).Run()
+	return err
+}
+
+// nodeSnapshot is everything the dashboard shows, fetched in one refresh.
+// Each panel's error is kept separately so one failing endpoint doesn't
+// blank the rest.
+type nodeSnapshot struct {
+	at time.Time
+
+	stats    networkStats
+	statsErr error
+
+	topology    *p2p.Topology
+	topologyErr error
+
+	topics    map[string]p2p.TopicStats
+	topicsErr error
+
+	sync    syncStatus
+	syncErr error
+
+	pins    pinQueue
+	pinsErr error
+
+	articles    []*domain.Article
+	articlesErr error
+}
+
+type networkStats struct {
+	Status    string `json:"status"`
+	PeerID    string `json:"peer_id"`
+	PeerCount int    `json:"peer_count"`
+}
+
+type syncStatus struct {
+	Status   string                  `json:"status"`
+	LastSync time.Time               `json:"last_sync"`
+	Interval float64                 `json:"interval_seconds"`
+	Stalled  bool                    `json:"stalled"`
+	Peers    map[string]p2p.PeerSync `json:"peers"`
+}
+
+type pinQueue struct {
+	Summary struct {
+		Total    int            `json:"total"`
+		ByStatus map[string]int `json:"by_status"`
+	} `json:"summary"`
+	Pins []domain.PinRecord `json:"pins"`
+}
+
+// snapshotMsg carries a finished refresh into the model
+type snapshotMsg *nodeSnapshot
+
+// tickMsg starts the next refresh
+type tickMsg time.Time
+
+func fetchSnapshot(c *client) tea.Cmd {
+	return func() tea.Msg {
+		snap := &nodeSnapshot{at: time.Now()}
+
+		var stats envelope[networkStats]
+		snap.statsErr = c.call(http.MethodGet, "/api/v1/network/stats", nil, &stats)
+		snap.stats = stats.Data
+
+		var topology envelope[*p2p.Topology]
+		snap.topologyErr = c.call(http.MethodGet, "/api/v1/network/topology", nil, &topology)
+		snap.topology = topology.Data
+
+		var pubsub envelope[struct {
+			Topics map[string]p2p.TopicStats `json:"topics"`
+		}]
+		snap.topicsErr = c.call(http.MethodGet, "/api/v1/network/pubsub", nil, &pubsub)
+		snap.topics = pubsub.Data.Topics
+
+		var sync envelope[syncStatus]
+		snap.syncErr = c.call(http.MethodGet, "/api/v1/network/sync/status", nil, &sync)
+		snap.sync = sync.Data
+
+		if c.token == "" {
+			snap.pinsErr = fmt.Errorf("log in as an admin to see the pin queue")
+		} else {
+			var pins envelope[pinQueue]
+			snap.pinsErr = c.call(http.MethodGet, "/api/v1/admin/pins?status="+domain.PinPending, nil, &pins)
+			snap.pins = pins.Data
+		}
+
+		var articles envelope[[]*domain.Article]
+		snap.articlesErr = c.call(http.MethodGet, fmt.Sprintf("/api/v1/articles?limit=%d", dashboardArticles), nil, &articles)
+		snap.articles = articles.Data
+
+		return snapshotMsg(snap)
+	}
+}
+
+func tick(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// topicRate is a topic's message rate between the last two refreshes
+type topicRate struct {
+	in, out float64
+}
+
+// dashboard is the bubbletea model behind "newsp2p dashboard"
+type dashboard struct {
+	client   *client
+	interval time.Duration
+
+	width int
+
+	snap     *nodeSnapshot
+	prev     *nodeSnapshot
+	rates    map[string]topicRate
+	fetching bool
+}
+
+func newDashboard(c *client, interval time.Duration) *dashboard {
+	return &dashboard{client: c, interval: interval, rates: map[string]topicRate{}, fetching: true}
+}
+
+func (d *dashboard) Init() tea.Cmd {
+	return fetchSnapshot(d.client)
+}
+
+func (d *dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return d, tea.Quit
+		case "r":
+			if !d.fetching {
+				d.fetching = true
+				return d, fetchSnapshot(d.client)
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+
+	case snapshotMsg:
+		d.prev, d.snap = d.snap, msg
+		d.updateRates()
+		d.fetching = false
+		return d, tick(d.interval)
+
+	case tickMsg:
+		if !d.fetching {
+			d.fetching = true
+			return d, fetchSnapshot(d.client)
+		}
+	}
+	return d, nil
+}
+
+// updateRates turns the counters in the last two snapshots into
+// per-second rates
+func (d *dashboard) updateRates() {
+	if d.prev == nil || d.prev.topics == nil || d.snap.topics == nil {
+		return
+	}
+	elapsed := d.snap.at.Sub(d.prev.at).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	for topic, now := range d.snap.topics {
+		before := d.prev.topics[topic]
+		d.rates[topic] = topicRate{
+			in:  float64(now.Received-before.Received) / elapsed,
+			out: float64(now.Published-before.Published) / elapsed,
+		}
+	}
+}
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+	headerStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	warnStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	errStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	okStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	panelStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")).Padding(0, 1)
+	panelSpacing = 1
+)
+
+func (d *dashboard) View() string {
+	if d.snap == nil {
+		return "Connecting to " + d.client.server + "…"
+	}
+
+	width := d.width
+	if width == 0 {
+		width = 100
+	}
+	// Two columns when there's room, one otherwise
+	columnWidth := width - 2
+	twoColumns := width >= 120
+	if twoColumns {
+		columnWidth = width/2 - 2
+	}
+
+	panels := []string{
+		d.panel("Peers", d.peersView(), columnWidth),
+		d.panel("Pubsub", d.pubsubView(), columnWidth),
+		d.panel("Sync", d.syncView(), columnWidth),
+		d.panel("Pin queue", d.pinsView(), columnWidth),
+	}
+
+	var body string
+	if twoColumns {
+		left := lipgloss.JoinVertical(lipgloss.Left, panels[0], panels[2])
+		right := lipgloss.JoinVertical(lipgloss.Left, panels[1], panels[3])
+		body = lipgloss.JoinHorizontal(lipgloss.Top, left, strings.Repeat(" ", panelSpacing), right)
+	} else {
+		body = lipgloss.JoinVertical(lipgloss.Left, panels...)
+	}
+	articles := d.panel("Recent articles", d.articlesView(), width-2)
+
+	return lipgloss.JoinVertical(lipgloss.Left, d.headerView(), body, articles, d.footerView())
+}
+
+func (d *dashboard) panel(title, content string, width int) string {
+	return panelStyle.Width(width - 2).Render(headerStyle.Render(title) + "\n" + content)
+}
+
+func (d *dashboard) headerView() string {
+	s := d.snap
+	if s.statsErr != nil {
+		return errStyle.Render(fmt.Sprintf("%s unreachable: %v", d.client.server, s.statsErr))
+	}
+	status := okStyle.Render(s.stats.Status)
+	if s.stats.Status != "active" {
+		status = warnStyle.Render(s.stats.Status)
+	}
+	peerID := s.stats.PeerID
+	if peerID == "" {
+		peerID = "p2p disabled"
+	}
+	return titleStyle.Render("newsp2p") + "  " + d.client.server + "  " + peerID + "  " + status
+}
+
+func (d *dashboard) footerView() string {
+	updated := "updated " + d.snap.at.Format("15:04:05")
+	if d.fetching {
+		updated = "refreshing…"
+	}
+	return dimStyle.Render(fmt.Sprintf("%s · every %s · r refresh · q quit", updated, d.interval))
+}
+
+func (d *dashboard) peersView() string {
+	s := d.snap
+	if s.topologyErr != nil {
+		return dimStyle.Render(firstLine(s.topologyErr.Error()))
+	}
+	if len(s.topology.Peers) == 0 {
+		return dimStyle.Render("No peers connected")
+	}
+
+	rows := [][]string{{"PEER", "LINK", "LATENCY", "IN/s", "OUT/s", "LAST SYNC"}}
+	for i, tp := range s.topology.Peers {
+		link := s.topology.Links[i]
+		lastSync := "-"
+		if ps, ok := s.sync.Peers[tp.ID]; ok {
+			lastSync = ago(ps.At)
+			if ps.Error != "" {
+				lastSync = errStyle.Render("failed " + lastSync)
+			}
+		}
+		rows = append(rows, []string{
+			shortPeer(tp.ID),
+			link.Transport + " " + link.Direction,
+			fmt.Sprintf("%.0fms", link.LatencyMS),
+			bytesRate(link.Bandwidth.RateIn),
+			bytesRate(link.Bandwidth.RateOut),
+			lastSync,
+		})
+	}
+	return fmt.Sprintf("%d connected\n", len(s.topology.Peers)) + table(rows)
+}
+
+func (d *dashboard) pubsubView() string {
+	s := d.snap
+	if s.topicsErr != nil {
+		return dimStyle.Render(firstLine(s.topicsErr.Error()))
+	}
+	if len(s.topics) == 0 {
+		return dimStyle.Render("No topics joined")
+	}
+
+	names := make([]string, 0, len(s.topics))
+	for name := range s.topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := [][]string{{"TOPIC", "PEERS", "IN/s", "OUT/s", "RECV", "REJECTED", "INVALID", "DUPES", "LAST"}}
+	for _, name := range names {
+		stats := s.topics[name]
+		rate := d.rates[name]
+		peers := fmt.Sprint(stats.Peers)
+		if stats.Joined && stats.Peers == 0 {
+			peers = warnStyle.Render(peers)
+		}
+		rejected := fmt.Sprint(stats.Rejected)
+		if stats.Rejected > 0 {
+			rejected = warnStyle.Render(rejected)
+		}
+		invalid := fmt.Sprint(stats.Invalid)
+		if stats.Invalid > 0 {
+			invalid = errStyle.Render(invalid)
+		}
+		last := "-"
+		if stats.LastReceived != nil {
+			last = ago(*stats.LastReceived)
+		}
+		rows = append(rows, []string{
+			name,
+			peers,
+			fmt.Sprintf("%.1f", rate.in),
+			fmt.Sprintf("%.1f", rate.out),
+			fmt.Sprint(stats.Received),
+			rejected,
+			invalid,
+			fmt.Sprint(stats.Duplicate),
+			last,
+		})
+	}
+	return table(rows)
+}
+
+func (d *dashboard) syncView() string {
+	s := d.snap
+	if s.syncErr != nil {
+		return dimStyle.Render(firstLine(s.syncErr.Error()))
+	}
+	if s.sync.Status != "active" {
+		return dimStyle.Render("Sync disabled")
+	}
+
+	var b strings.Builder
+	last := "never"
+	if !s.sync.LastSync.IsZero() {
+		last = ago(s.sync.LastSync)
+	}
+	fmt.Fprintf(&b, "Last round %s, every %s", last, time.Duration(s.sync.Interval*float64(time.Second)))
+	if s.sync.Stalled {
+		b.WriteString("  " + warnStyle.Render("stalled"))
+	}
+	b.WriteString("\n")
+
+	// Most recent outcomes first
+	syncs := make([]p2p.PeerSync, 0, len(s.sync.Peers))
+	for _, ps := range s.sync.Peers {
+		syncs = append(syncs, ps)
+	}
+	sort.Slice(syncs, func(i, j int) bool { return syncs[i].At.After(syncs[j].At) })
+	if len(syncs) > 5 {
+		syncs = syncs[:5]
+	}
+	rows := [][]string{{"PEER", "WHEN", "RECEIVED", "NEW", "ERROR"}}
+	for _, ps := range syncs {
+		rows = append(rows, []string{shortPeer(ps.PeerID), ago(ps.At), fmt.Sprint(ps.Received), fmt.Sprint(ps.New), errStyle.Render(truncate(ps.Error, 30))})
+	}
+	if len(syncs) > 0 {
+		b.WriteString(table(rows))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (d *dashboard) pinsView() string {
+	s := d.snap
+	if s.pinsErr != nil {
+		return dimStyle.Render(firstLine(s.pinsErr.Error()))
+	}
+
+	counts := s.pins.Summary.ByStatus
+	failed := fmt.Sprint(counts[domain.PinFailed])
+	if counts[domain.PinFailed] > 0 {
+		failed = errStyle.Render(failed)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d pending · %d pinned · %s failed\n", counts[domain.PinPending], counts[domain.PinPinned], failed)
+
+	pins := s.pins.Pins
+	sort.Slice(pins, func(i, j int) bool { return pins[i].NextAttempt.Before(pins[j].NextAttempt) })
+	if len(pins) > 5 {
+		pins = pins[:5]
+	}
+	rows := [][]string{{"CID", "ATTEMPTS", "NEXT", "LAST ERROR"}}
+	for _, pin := range pins {
+		rows = append(rows, []string{truncate(pin.CID, 20), fmt.Sprint(pin.Attempts), until(pin.NextAttempt), truncate(pin.LastError, 30)})
+	}
+	if len(pins) > 0 {
+		b.WriteString(table(rows))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (d *dashboard) articlesView() string {
+	s := d.snap
+	if s.articlesErr != nil {
+		return dimStyle.Render(firstLine(s.articlesErr.Error()))
+	}
+	if len(s.articles) == 0 {
+		return dimStyle.Render("No articles")
+	}
+	rows := [][]string{{"WHEN", "AUTHOR", "TITLE"}}
+	for _, article := range s.articles {
+		rows = append(rows, []string{ago(article.Timestamp), truncate(article.Author, 16), truncate(article.Title, 60)})
+	}
+	return table(rows)
+}
+
+// table lays out rows in left-aligned columns, the first row as a header
+func table(rows [][]string) string {
+	widths := map[int]int{}
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := lipgloss.Width(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	var b strings.Builder
+	for r, row := range rows {
+		for i, cell := range row {
+			pad := widths[i] - lipgloss.Width(cell)
+			if r == 0 {
+				cell = dimStyle.Render(cell)
+			}
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", pad+2))
+			}
+		}
+		if r < len(rows)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func shortPeer(id string) string {
+	if len(id) > 14 {
+		return id[:6] + "…" + id[len(id)-6:]
+	}
+	return id
+}
+
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return humanDuration(time.Since(t)) + " ago"
+}
+
+func until(t time.Time) string {
+	d := time.Until(t)
+	if d <= 0 {
+		return "now"
+	}
+	return "in " + humanDuration(d)
+}
+
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func bytesRate(bytesPerSecond float64) string {
+	switch {
+	case bytesPerSecond >= 1<<20:
+		return fmt.Sprintf("%.1fM", bytesPerSecond/(1<<20))
+	case bytesPerSecond >= 1<<10:
+		return fmt.Sprintf("%.1fK", bytesPerSecond/(1<<10))
+	default:
+		return fmt.Sprintf("%.0fB", bytesPerSecond)
+	}
+}
+
+func truncate(s string, n int) string {
+	s = firstLine(s)
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
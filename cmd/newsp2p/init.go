@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+)
+
+// nodeSetup is what the init wizard asks for. Everything else in the
+// written file is left to the server's defaults.
+type nodeSetup struct {
+	Port           int
+	GRPC           bool
+	GRPCPort       int
+	DataRoot       string
+	IPFSEndpoint   string
+	JWTSecret      string // empty when the secret is kept in the environment
+	AdminUsers     []string
+	P2P            bool
+	P2PPort        int
+	Network        string
+	BootstrapPeers []string // empty takes the network's built-in peers
+	Rendezvous     string   // private networks only
+}
+
+// runInit writes a node configuration from answers to a few questions,
+// then loads it the way the server will to check it is usable
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	out := fs.String("o", "configs/config.yaml", "file to write")
+	force := fs.Bool("force", false, "overwrite an existing file")
+	defaults := fs.Bool("defaults", false, "don't ask, write the default answers")
+	fs.Parse(args)
+
+	if _, err := os.Stat(*out); err == nil && !*force {
+		return fmt.Errorf("%s already exists, pass -force to overwrite it", *out)
+	}
+
+	secret, err := newJWTSecret()
+	if err != nil {
+		return err
+	}
+	setup := &nodeSetup{
+		Port:         12345,
+		GRPCPort:     50051,
+		DataRoot:     "./data",
+		IPFSEndpoint: "http://localhost:5001",
+		JWTSecret:    secret,
+		P2P:          true,
+		P2PPort:      4001,
+		Network:      config.NetworkMainnet,
+	}
+	if !*defaults {
+		if err := ask(newPrompter(os.Stdin, os.Stderr), setup); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		return err
+	}
+	// The file may hold the JWT secret
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := configTemplate.Execute(f, setup); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", *out, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	// A secret kept out of the file has to be in the environment when the
+	// server starts; stand in for it here so the rest can be checked
+	if setup.JWTSecret == "" && os.Getenv("NEWS_AUTH_JWT_SECRET") == "" {
+		os.Setenv("NEWS_AUTH_JWT_SECRET", secret)
+		defer fmt.Printf("\nSet the JWT secret before starting the server:\n  export NEWS_AUTH_JWT_SECRET=%s\n", secret)
+	}
+	if _, err := config.LoadWithOverrides(config.Overrides{ConfigFile: *out}); err != nil {
+		return fmt.Errorf("wrote %s, but it doesn't load: %w", *out, err)
+	}
+
+	fmt.Printf("Wrote %s\n", *out)
+	fmt.Println("Run doctor to check the IPFS daemon and peer connectivity before starting the server.")
+	return nil
+}
+
+func ask(p *prompter, s *nodeSetup) error {
+	var err error
+	if s.Port, err = p.port("HTTP port", s.Port); err != nil {
+		return err
+	}
+	if s.GRPC, err = p.yesNo("Serve the gRPC API", s.GRPC); err != nil {
+		return err
+	}
+	if s.GRPC {
+		for {
+			if s.GRPCPort, err = p.port("gRPC port", s.GRPCPort); err != nil {
+				return err
+			}
+			if s.GRPCPort != s.Port {
+				break
+			}
+			p.complain("the gRPC port must differ from the HTTP port")
+		}
+	}
+	if s.DataRoot, err = p.text("Data directory", s.DataRoot); err != nil {
+		return err
+	}
+	if s.IPFSEndpoint, err = p.text("IPFS API endpoint", s.IPFSEndpoint); err != nil {
+		return err
+	}
+	inFile, err := p.yesNo("Store the generated JWT secret in the config file (no: use NEWS_AUTH_JWT_SECRET)", true)
+	if err != nil {
+		return err
+	}
+	if !inFile {
+		s.JWTSecret = ""
+	}
+	admins, err := p.text("Admin usernames, comma-separated", "")
+	if err != nil {
+		return err
+	}
+	s.AdminUsers = splitList(admins)
+
+	if s.P2P, err = p.yesNo("Join the P2P network", s.P2P); err != nil {
+		return err
+	}
+	if !s.P2P {
+		return nil
+	}
+	if s.P2PPort, err = p.port("P2P listen port (TCP and QUIC)", s.P2PPort); err != nil {
+		return err
+	}
+	if s.Network, err = p.text("Network (mainnet, testnet, or a private network's name)", s.Network); err != nil {
+		return err
+	}
+	_, builtIn := config.Networks[s.Network]
+	if !builtIn {
+		if s.Rendezvous, err = p.text("Rendezvous string peers on this network share", s.Network); err != nil {
+			return err
+		}
+	}
+	for {
+		question := "Bootstrap peers, comma-separated multiaddrs (blank for the network's own)"
+		if !builtIn {
+			question = "Bootstrap peers, comma-separated multiaddrs"
+		}
+		answer, err := p.text(question, "")
+		if err != nil {
+			return err
+		}
+		peers := splitList(answer)
+		if err := checkBootstrapPeers(peers); err != nil {
+			p.complain(err.Error())
+			continue
+		}
+		if len(peers) > 0 {
+			s.BootstrapPeers = peers
+		}
+		return nil
+	}
+}
+
+// checkBootstrapPeers rejects addresses the server couldn't dial. A
+// /dnsaddr name may leave the peer IDs to its TXT records.
+func checkBootstrapPeers(peers []string) error {
+	for _, addr := range peers {
+		ma, err := multiaddr.NewMultiaddr(addr)
+		if err != nil {
+			return fmt.Errorf("%s: %v", addr, err)
+		}
+		if _, err := ma.ValueForProtocol(multiaddr.P_DNSADDR); err == nil {
+			continue
+		}
+		if _, err := peer.AddrInfoFromP2pAddr(ma); err != nil {
+			return fmt.Errorf("%s: must end in /p2p/<peer-id>", addr)
+		}
+	}
+	return nil
+}
+
+func newJWTSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate JWT secret: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// prompter asks questions on out and reads the answers from in. A blank
+// answer takes the default shown in brackets.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+func (p *prompter) text(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", errors.New("input ended before setup was complete")
+		}
+		return "", err
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+func (p *prompter) yesNo(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	for {
+		answer, err := p.text(question+"? ("+choices+")", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		p.complain("answer y or n")
+	}
+}
+
+func (p *prompter) port(question string, def int) (int, error) {
+	for {
+		answer, err := p.text(question, strconv.Itoa(def))
+		if err != nil {
+			return 0, err
+		}
+		if port, err := strconv.Atoi(answer); err == nil && port >= 1 && port <= 65535 {
+			return port, nil
+		}
+		p.complain("a port is a number from 1 to 65535")
+	}
+}
+
+func (p *prompter) complain(msg string) {
+	fmt.Fprintf(p.out, "  %s\n", msg)
+}
+
+// configTemplate lays the answers out like configs/config.yaml so the
+// file reads the same as the documented one
+var configTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`# Written by "newsp2p init". Settings not listed here take the server's
+# defaults; see the repository's configs/config.yaml for all of them.
+
+server:
+  host: 0.0.0.0
+  port: {{.Port}}
+  mode: release
+
+grpc:
+  enabled: {{.GRPC}}
+  port: {{.GRPCPort}}
+
+database:
+  mode: distributed
+  path: {{quote (printf "%s/badger_db" .DataRoot)}}
+
+data:
+  root: {{quote .DataRoot}}
+
+search:
+  index_path: {{quote (printf "%s/search.bleve" .DataRoot)}}
+
+ipfs:
+  api_endpoint: {{quote .IPFSEndpoint}}
+
+auth:
+{{- if .JWTSecret}}
+  jwt_secret: {{quote .JWTSecret}}
+{{- else}}
+  jwt_secret: ""  # set the NEWS_AUTH_JWT_SECRET environment variable
+{{- end}}
+  admin_users: [{{range $i, $u := .AdminUsers}}{{if $i}}, {{end}}{{quote $u}}{{end}}]
+
+logging:
+  level: info
+  format: text
+
+cors:
+  allowed_origins:
+    - http://localhost:{{.Port}}
+
+network:
+  name: {{quote .Network}}
+
+p2p:
+  enabled: {{.P2P}}
+  listen_addrs:
+    - /ip4/0.0.0.0/tcp/{{.P2PPort}}
+    - /ip4/0.0.0.0/udp/{{.P2PPort}}/quic-v1
+{{- if .BootstrapPeers}}
+  bootstrap_peers:
+{{- range .BootstrapPeers}}
+    - {{.}}
+{{- end}}
+{{- end}}
+{{- if .Rendezvous}}
+  rendezvous: {{quote .Rendezvous}}
+{{- end}}
+`))
@@ -0,0 +1,552 @@
+// Command newsp2p is a command-line client for a node's REST API. It keeps
+// a profile per node, with the session logged in there, so scripts can
+// post and read articles without handling tokens.
+//
+//	newsp2p init
+//	newsp2p config validate -config /etc/newsp2p/node.yaml
+//	newsp2p profile add home -server http://localhost:12345
+//	newsp2p login -u alice
+//	newsp2p post -tags go,p2p story.md
+//	newsp2p list -author alice
+//	newsp2p search -sort newest ipfs
+//	newsp2p get <cid>
+//	newsp2p vote <cid> up
+//	newsp2p seed -follow k51qzi5uqu5d...
+//	newsp2p admin verify -repair
+//	newsp2p dashboard
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const usage = `Usage:
+  newsp2p init [flags]                  write a node config (configs/config.yaml) by answering a few questions
+  newsp2p config validate [flags]       check a node config, and that its IPFS daemon answers, before a restart
+  newsp2p profile add|use|list|remove   manage the nodes the client talks to
+  newsp2p login [flags]                 log in and save the session to the profile
+  newsp2p logout [flags]                forget the profile's session
+  newsp2p post [flags] <file|->         publish a markdown file as an article
+  newsp2p list [flags]                  list articles, newest first
+  newsp2p search [flags] <query>        search articles
+  newsp2p get [flags] <cid>             show an article
+  newsp2p vote [flags] <cid> up|down    vote on an article
+  newsp2p seed [flags] <ipns-name>      mirror another node's feed: store and pin all its articles
+  newsp2p admin <command> [flags]       node maintenance: reindex, verify, users, backup, restore, prune
+  newsp2p dashboard [flags]             live view of peers, pubsub, sync, pins and new articles
+
+Run "newsp2p <command> -h" for command flags. Profiles are kept in
+NEWS_CLI_CONFIG, or newsp2p/profiles.json in the user config directory.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	commands := map[string]func([]string) error{
+		"init":      runInit,
+		"config":    runConfig,
+		"profile":   runProfile,
+		"login":     runLogin,
+		"logout":    runLogout,
+		"post":      runPost,
+		"list":      runList,
+		"search":    runSearch,
+		"get":       runGet,
+		"vote":      runVote,
+		"seed":      runSeed,
+		"admin":     runAdmin,
+		"dashboard": runDashboard,
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func runProfile(args []string) error {
+	const profileUsage = "usage: newsp2p profile add <name> -server <url> | use <name> | list | remove <name>"
+	if len(args) == 0 {
+		return errors.New(profileUsage)
+	}
+	store, err := loadStore()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("profile add", flag.ExitOnError)
+		server := fs.String("server", "", "node API address")
+		name, rest := splitName(args[1:])
+		fs.Parse(rest)
+		if name == "" || *server == "" {
+			return fmt.Errorf("usage: newsp2p profile add <name> -server <url>")
+		}
+		if _, err := url.ParseRequestURI(*server); err != nil {
+			return fmt.Errorf("invalid server address: %w", err)
+		}
+		if _, ok := store.Profiles[name]; ok {
+			return fmt.Errorf("profile %q already exists", name)
+		}
+		store.Profiles[name] = &profile{Server: strings.TrimRight(*server, "/")}
+		if store.Current == "" {
+			store.Current = name
+		}
+		fmt.Printf("Added profile %s (%s)\n", name, *server)
+
+	case "use":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: newsp2p profile use <name>")
+		}
+		if _, err := store.get(args[1]); err != nil {
+			return err
+		}
+		store.Current = args[1]
+		fmt.Printf("Using profile %s\n", args[1])
+
+	case "list":
+		current := store.selected("")
+		if _, ok := store.Profiles[current]; !ok && current == defaultProfile {
+			store.get(defaultProfile)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "\tPROFILE\tSERVER\tUSER")
+		for _, name := range store.names() {
+			marker := ""
+			if name == current {
+				marker = "*"
+			}
+			p := store.Profiles[name]
+			user := p.Username
+			if p.AccessToken == "" {
+				user = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, p.Server, user)
+		}
+		// Listing doesn't save, so the implicit default profile isn't
+		// written out
+		return w.Flush()
+
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: newsp2p profile remove <name>")
+		}
+		if _, ok := store.Profiles[args[1]]; !ok {
+			return fmt.Errorf("no profile %q", args[1])
+		}
+		delete(store.Profiles, args[1])
+		if store.Current == args[1] {
+			store.Current = ""
+		}
+		fmt.Printf("Removed profile %s\n", args[1])
+
+	default:
+		return errors.New(profileUsage)
+	}
+	return store.save()
+}
+
+// splitName takes a leading positional name off args, so it can come
+// before the flags
+func splitName(args []string) (string, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", args
+	}
+	return args[0], args[1:]
+}
+
+func runLogin(args []string) error {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	conn := commonFlags(fs)
+	username := fs.String("u", "", "username (default the profile's last user)")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	fs.Parse(args)
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	if c.prof == nil {
+		return fmt.Errorf("login saves a session to a profile, it can't be combined with -token")
+	}
+
+	user := *username
+	if user == "" {
+		user = c.prof.Username
+	}
+	if user == "" {
+		return fmt.Errorf("usage: newsp2p login -u <username>")
+	}
+	password := os.Getenv("NEWS_PASSWORD")
+	switch {
+	case *passwordStdin:
+		password, err = readLine(os.Stdin)
+	case password == "":
+		password, err = promptPassword(fmt.Sprintf("Password for %s@%s: ", user, c.server))
+	}
+	if err != nil {
+		return err
+	}
+
+	var login envelope[domain.LoginResponse]
+	err = c.call(http.MethodPost, "/api/v1/auth/login", domain.UserLoginRequest{Username: user, Password: password}, &login)
+	if err != nil {
+		return err
+	}
+	if login.Data.Tokens == nil {
+		return fmt.Errorf("login response carried no tokens")
+	}
+
+	c.prof.Server = c.server
+	c.prof.Username = user
+	c.prof.AccessToken = login.Data.Tokens.AccessToken
+	c.prof.RefreshToken = login.Data.Tokens.RefreshToken
+	c.prof.ExpiresAt = login.Data.Tokens.ExpiresAt
+	if err := c.store.save(); err != nil {
+		return err
+	}
+	fmt.Printf("Logged in to %s as %s (profile %s)\n", c.server, user, c.name)
+	return nil
+}
+
+func runLogout(args []string) error {
+	fs := flag.NewFlagSet("logout", flag.ExitOnError)
+	conn := commonFlags(fs)
+	fs.Parse(args)
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	if c.prof == nil {
+		return fmt.Errorf("logout clears a profile's session, it can't be combined with -token")
+	}
+	c.prof.AccessToken = ""
+	c.prof.RefreshToken = ""
+	c.prof.ExpiresAt = time.Time{}
+	if err := c.store.save(); err != nil {
+		return err
+	}
+	fmt.Printf("Logged out of profile %s\n", c.name)
+	return nil
+}
+
+func runPost(args []string) error {
+	fs := flag.NewFlagSet("post", flag.ExitOnError)
+	conn := commonFlags(fs)
+	title := fs.String("title", "", "article title (default the file's first # heading)")
+	tags := fs.String("tags", "", "comma-separated tags")
+	category := fs.String("category", "", "article category")
+	asJSON := fs.Bool("json", false, "print the created article as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("post takes exactly one markdown file, or - for stdin")
+	}
+
+	var data []byte
+	var err error
+	if fs.Arg(0) == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	req := domain.ArticleCreateRequest{Title: *title, Body: string(data), Category: *category}
+	if req.Title == "" {
+		req.Title, req.Body = splitTitle(req.Body)
+	}
+	if req.Title == "" {
+		return fmt.Errorf("no title: pass -title or start the file with a # heading")
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return fmt.Errorf("the article has no body")
+	}
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			req.Tags = append(req.Tags, tag)
+		}
+	}
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	if err := c.requireLogin(); err != nil {
+		return err
+	}
+
+	var created envelope[json.RawMessage]
+	if err := c.call(http.MethodPost, "/api/v1/articles", req, &created); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(created.Data)
+	}
+
+	var article domain.Article
+	if err := json.Unmarshal(created.Data, &article); err != nil {
+		return fmt.Errorf("failed to decode article: %w", err)
+	}
+	fmt.Printf("Published %q\n", article.Title)
+	fmt.Printf("ID:  %s\n", article.ID)
+	fmt.Printf("CID: %s\n", article.CID)
+	return nil
+}
+
+// splitTitle takes a leading "# " heading off a markdown document
+func splitTitle(doc string) (string, string) {
+	trimmed := strings.TrimLeft(doc, " \t\r\n")
+	if !strings.HasPrefix(trimmed, "# ") {
+		return "", doc
+	}
+	line, rest, _ := strings.Cut(trimmed, "\n")
+	return strings.TrimSpace(strings.TrimPrefix(line, "# ")), strings.TrimLeft(rest, "\r\n")
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	conn := commonFlags(fs)
+	author := fs.String("author", "", "only articles by this author")
+	category := fs.String("category", "", "only articles in this category")
+	page := fs.Int("page", 1, "page number")
+	limit := fs.Int("limit", 20, "articles per page")
+	asJSON := fs.Bool("json", false, "print the articles as JSON")
+	fs.Parse(args)
+
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(*page))
+	query.Set("limit", strconv.Itoa(*limit))
+	if *author != "" {
+		query.Set("author", *author)
+	}
+	if *category != "" {
+		query.Set("category", *category)
+	}
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	var list envelope[json.RawMessage]
+	if err := c.call(http.MethodGet, "/api/v1/articles?"+query.Encode(), nil, &list); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(list.Data)
+	}
+
+	var articles []*domain.Article
+	if err := json.Unmarshal(list.Data, &articles); err != nil {
+		return fmt.Errorf("failed to decode articles: %w", err)
+	}
+	printArticles(articles)
+	if p := list.Pagination; p != nil {
+		fmt.Printf("\nPage %d of %d (%d articles)\n", p.Page, p.TotalPages, p.Total)
+	}
+	return nil
+}
+
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	conn := commonFlags(fs)
+	author := fs.String("author", "", "only articles by this author")
+	tags := fs.String("tags", "", "comma-separated tags to filter by")
+	sort := fs.String("sort", "", "relevance, newest, oldest, most_voted or trust")
+	network := fs.Bool("network", false, "also search connected peers")
+	page := fs.Int("page", 1, "page number")
+	limit := fs.Int("limit", 20, "results per page")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+	fs.Parse(args)
+
+	query := url.Values{}
+	query.Set("q", strings.Join(fs.Args(), " "))
+	query.Set("page", strconv.Itoa(*page))
+	query.Set("limit", strconv.Itoa(*limit))
+	for key, value := range map[string]string{"author": *author, "tags": *tags, "sort": *sort} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if *network {
+		query.Set("scope", "network")
+	}
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	var result envelope[json.RawMessage]
+	if err := c.call(http.MethodGet, "/api/v1/search?"+query.Encode(), nil, &result); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result.Data)
+	}
+
+	var data struct {
+		Results    []*domain.Article `json:"results"`
+		Pagination struct {
+			Page       int `json:"page"`
+			Total      int `json:"total"`
+			TotalPages int `json:"total_pages"`
+		} `json:"pagination"`
+	}
+	if err := json.Unmarshal(result.Data, &data); err != nil {
+		return fmt.Errorf("failed to decode results: %w", err)
+	}
+	printArticles(data.Results)
+	fmt.Printf("\nPage %d of %d (%d results)\n", data.Pagination.Page, data.Pagination.TotalPages, data.Pagination.Total)
+	return nil
+}
+
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	conn := commonFlags(fs)
+	asJSON := fs.Bool("json", false, "print the article as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("get takes exactly one CID")
+	}
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	var got envelope[json.RawMessage]
+	if err := c.call(http.MethodGet, "/api/v1/articles/"+url.PathEscape(fs.Arg(0)), nil, &got); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(got.Data)
+	}
+
+	var article domain.Article
+	if err := json.Unmarshal(got.Data, &article); err != nil {
+		return fmt.Errorf("failed to decode article: %w", err)
+	}
+	fmt.Printf("# %s\n\n", article.Title)
+	fmt.Printf("Author: %s\n", article.Author)
+	fmt.Printf("Date:   %s\n", article.Timestamp.Format(time.RFC1123))
+	if len(article.Tags) > 0 {
+		fmt.Printf("Tags:   %s\n", strings.Join(article.Tags, ", "))
+	}
+	fmt.Printf("CID:    %s\n\n", article.CID)
+	fmt.Println(article.Body)
+	return nil
+}
+
+func runVote(args []string) error {
+	fs := flag.NewFlagSet("vote", flag.ExitOnError)
+	conn := commonFlags(fs)
+	reason := fs.String("reason", "", "why, at most 280 characters")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: newsp2p vote [flags] <cid> up|down")
+	}
+
+	req := domain.VoteRequest{Reason: *reason}
+	switch fs.Arg(1) {
+	case "up", "+1", "1":
+		req.Vote = 1
+	case "down", "-1":
+		req.Vote = -1
+	default:
+		return fmt.Errorf("vote must be up or down, not %q", fs.Arg(1))
+	}
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	if err := c.requireLogin(); err != nil {
+		return err
+	}
+
+	var voted envelope[struct {
+		Tally domain.VoteTally `json:"tally"`
+	}]
+	if err := c.call(http.MethodPost, "/api/v1/articles/"+url.PathEscape(fs.Arg(0))+"/vote", req, &voted); err != nil {
+		return err
+	}
+	tally := voted.Data.Tally
+	fmt.Printf("Voted. Score %d (%d up, %d down), %.2f weighted by reputation\n", tally.Score, tally.Up, tally.Down, tally.Weighted)
+	return nil
+}
+
+// printArticles writes a table of articles
+func printArticles(articles []*domain.Article) {
+	if len(articles) == 0 {
+		fmt.Println("No articles")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CID\tDATE\tAUTHOR\tTITLE")
+	for _, article := range articles {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", article.CID, article.Timestamp.Format("2006-01-02"), article.Author, article.Title)
+	}
+	w.Flush()
+}
+
+func printJSON(data json.RawMessage) error {
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// promptPassword reads a password from the terminal with echo turned off.
+// Where stty isn't available the password is read as typed.
+func promptPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	return readLine(os.Stdin)
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+func readLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
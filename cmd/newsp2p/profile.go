@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// defaultProfile is used until another profile is added and selected
+const defaultProfile = "default"
+
+// profile is one node the client talks to, with the session logged in
+// there
+type profile struct {
+	Server       string    `json:"server"`
+	Username     string    `json:"username,omitempty"`
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// profileStore is the client's config file. It holds tokens, so it is
+// only readable by its owner.
+type profileStore struct {
+	Current  string              `json:"current"`
+	Profiles map[string]*profile `json:"profiles"`
+
+	path string
+}
+
+// storePath is NEWS_CLI_CONFIG, or newsp2p/profiles.json in the user's
+// config directory
+func storePath() (string, error) {
+	if path := os.Getenv("NEWS_CLI_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "newsp2p", "profiles.json"), nil
+}
+
+// loadStore reads the config file; a missing file is an empty store
+func loadStore() (*profileStore, error) {
+	path, err := storePath()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &profileStore{Profiles: make(map[string]*profile), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if store.Profiles == nil {
+		store.Profiles = make(map[string]*profile)
+	}
+	return store, nil
+}
+
+func (s *profileStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, append(data, '\n'), 0600)
+}
+
+// selected names the profile to use: the one asked for, else
+// NEWS_PROFILE, else the current one
+func (s *profileStore) selected(name string) string {
+	if name != "" {
+		return name
+	}
+	if name := os.Getenv("NEWS_PROFILE"); name != "" {
+		return name
+	}
+	if s.Current != "" {
+		return s.Current
+	}
+	return defaultProfile
+}
+
+// get returns the named profile. The default profile exists implicitly,
+// pointing at NEWS_SERVER or a local node.
+func (s *profileStore) get(name string) (*profile, error) {
+	if p, ok := s.Profiles[name]; ok {
+		return p, nil
+	}
+	if name != defaultProfile {
+		return nil, fmt.Errorf("no profile %q (see \"newsp2p profile list\")", name)
+	}
+	p := &profile{Server: envOr("NEWS_SERVER", "http://localhost:12345")}
+	s.Profiles[name] = p
+	return p, nil
+}
+
+// names lists the profiles in order
+func (s *profileStore) names() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
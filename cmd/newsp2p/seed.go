@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// seedTimeout bounds one seeding pass, which fetches every article the
+// feed lists
+const seedTimeout = 30 * time.Minute
+
+// runSeed makes the node a mirror of another node's feed. With -follow it
+// seeds again every -interval, so new articles are picked up as the feed
+// publishes them.
+func runSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	conn := commonFlags(fs)
+	follow := fs.Bool("follow", false, "keep seeding the feed until interrupted")
+	interval := fs.Duration("interval", 5*time.Minute, "how often to check a followed feed for new articles")
+	asJSON := fs.Bool("json", false, "print each report as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: newsp2p seed [flags] <ipns-name>")
+	}
+	if *follow && *interval < time.Minute {
+		return fmt.Errorf("-interval must be at least a minute")
+	}
+
+	c, err := connect(conn)
+	if err != nil {
+		return err
+	}
+	if err := c.requireLogin(); err != nil {
+		return err
+	}
+	c.http.Timeout = seedTimeout
+
+	seed := func() error {
+		var result envelope[json.RawMessage]
+		if err := c.call(http.MethodPost, "/api/v1/feeds/seed", domain.FeedSeedRequest{Name: fs.Arg(0)}, &result); err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(result.Data)
+		}
+		var report domain.FeedSeedReport
+		if err := json.Unmarshal(result.Data, &report); err != nil {
+			return fmt.Errorf("failed to decode report: %w", err)
+		}
+		printSeedReport(&report)
+		return nil
+	}
+
+	if err := seed(); err != nil || !*follow {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	fmt.Fprintf(os.Stderr, "Following %s every %s, Ctrl+C to stop\n", fs.Arg(0), *interval)
+	for {
+		select {
+		case <-ticker.C:
+			// Keep following through a failed pass; the feed's publisher
+			// may just be offline for a while
+			if err := c.requireLogin(); err != nil {
+				return err
+			}
+			if err := seed(); err != nil {
+				fmt.Fprintf(os.Stderr, "%s  %v\n", time.Now().Format(time.TimeOnly), err)
+			}
+		case <-interrupt:
+			return nil
+		}
+	}
+}
+
+func printSeedReport(r *domain.FeedSeedReport) {
+	fmt.Printf("%s  %s -> %s\n", time.Now().Format(time.TimeOnly), r.Name, r.ManifestCID)
+	fmt.Printf("  articles %d, new %d, already stored %d, pinned %d CIDs\n", r.Articles, len(r.Fetched), r.Present, r.Pinned)
+	for _, failure := range r.Failed {
+		fmt.Printf("  failed   %s: %s\n", failure.CID, failure.Error)
+	}
+}
@@ -2,31 +2,56 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	shell "github.com/ipfs/go-ipfs-api"
+	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/amiyamandal-dev/newsp2p/internal/alerts"
 	"github.com/amiyamandal-dev/newsp2p/internal/api"
 	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
 	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/classifier"
 	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/internal/grpcapi"
 	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/media"
+	"github.com/amiyamandal-dev/newsp2p/internal/metrics"
 	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
 	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
 	"github.com/amiyamandal-dev/newsp2p/internal/search"
 	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/internal/tracing"
 	"github.com/amiyamandal-dev/newsp2p/internal/web"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
 
 func main() {
+	// Parse command line flags; each overrides the config file and environment
+	var overrides config.Overrides
+	flag.StringVar(&overrides.ConfigFile, "config", "", "config file to read instead of ./configs/config.yaml or ./config.yaml")
+	flag.IntVar(&overrides.Port, "port", 0, "HTTP port (server.port)")
+	flag.StringVar(&overrides.DataRoot, "data-dir", "", "root directory for node state (data.root, default ./data)")
+	flag.StringVar(&overrides.DataRoot, "data-root", "", "same as -data-dir")
+	flag.StringVar(&overrides.Profile, "profile", "", "data profile name; isolates all node state under <data-dir>/profiles/<name>")
+	flag.StringVar(&overrides.LogLevel, "log-level", "", "log level: debug, info, warn or error (logging.level)")
+	flag.Var(&switchFlag{value: &overrides.P2PEnabled}, "p2p", "run the P2P network: on or off (p2p.enabled)")
+	flag.Parse()
+
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadWithOverrides(overrides)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
 		fmt.Fprintf(os.Stderr, "\n💡 Tip: Make sure to set NEWS_AUTH_JWT_SECRET environment variable\n")
@@ -35,17 +60,34 @@ func main() {
 	}
 
 	// Initialize logger
-	log, err := logger.New(cfg.Logging.Level, cfg.Logging.Format)
+	log, err := logger.NewWithFile(cfg.Logging.Level, cfg.Logging.Format, logger.FileOptions{
+		Path:       cfg.Logging.File.Path,
+		MaxSizeMB:  cfg.Logging.File.MaxSizeMB,
+		MaxBackups: cfg.Logging.File.MaxBackups,
+		MaxAgeDays: cfg.Logging.File.MaxAgeDays,
+		Compress:   cfg.Logging.File.Compress,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "❌ Failed to create logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer log.Sync()
+	if err := log.Levels().Replace(cfg.Logging.Level, cfg.Logging.Components); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Invalid log levels: %v\n", err)
+		os.Exit(1)
+	}
 
 	log.Info("🚀 Starting distributed news platform server",
 		"version", "1.0.0",
 		"mode", cfg.Server.Mode,
+		"profile", cfg.Data.Profile,
+		"profile_config", config.ProfileFileUsed(),
+		"data_dir", cfg.Data.Dir(),
 	)
+	// Show what the file, environment and flags resolved to, secrets as digests
+	if cfg.Server.Mode == "debug" || cfg.Logging.Level == "debug" {
+		log.Info("Effective configuration", "config_file", config.FileUsed(), "settings", cfg.Settings())
+	}
 
 	// Ensure required directories exist
 	if err := ensureDirectories(cfg, log); err != nil {
@@ -54,14 +96,43 @@ func main() {
 	}
 
 	// Initialize database (BadgerDB)
-	db, err := badger.New(cfg.Database.Path)
+	encryptionKey, err := cfg.Database.EncryptionKeyBytes()
+	if err != nil {
+		log.Error("Failed to initialize database", "error", err)
+		os.Exit(1)
+	}
+	db, err := badger.NewEncrypted(cfg.Database.Path, encryptionKey)
 	if err != nil {
 		log.Error("Failed to initialize database", "error", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	log.Info("✅ Database initialized (BadgerDB)", "path", cfg.Database.Path)
+	log.Info("✅ Database initialized (BadgerDB)", "path", cfg.Database.Path, "encrypted", encryptionKey != nil)
+
+	// Prometheus metrics; each subsystem is registered as it starts
+	var nodeMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		nodeMetrics = metrics.New()
+		log.Info("✅ Metrics enabled", "path", cfg.Metrics.Path)
+	}
+
+	// OpenTelemetry traces; spans are no-ops unless this installs an exporter
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "1.0.0")
+		if err != nil {
+			log.Error("Failed to set up tracing", "error", err)
+			os.Exit(1)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				log.Warn("Failed to flush traces", "error", err)
+			}
+		}()
+		log.Info("✅ Tracing enabled", "endpoint", cfg.Tracing.Endpoint, "sample_ratio", cfg.Tracing.SampleRatio)
+	}
 
 	// Initialize IPFS client
 	ipfsClient := ipfs.NewClient(
@@ -70,6 +141,24 @@ func main() {
 		cfg.IPFS.PinArticles,
 		log,
 	)
+	if err := ipfsClient.SetAddOptions(ipfs.AddOptions{
+		CIDVersion: cfg.IPFS.Add.CIDVersion,
+		RawLeaves:  cfg.IPFS.Add.RawLeaves,
+		Hash:       cfg.IPFS.Add.Hash,
+	}); err != nil {
+		log.Error("Invalid ipfs.add options", "error", err)
+		os.Exit(1)
+	}
+	ipfsClient.SetTimeouts(ipfs.Timeouts{
+		Add:  cfg.IPFS.Timeouts.Add,
+		Cat:  cfg.IPFS.Timeouts.Cat,
+		Pin:  cfg.IPFS.Timeouts.Pin,
+		IPNS: cfg.IPFS.Timeouts.IPNS,
+	})
+
+	if nodeMetrics != nil {
+		nodeMetrics.RegisterIPFS(ipfsClient.Metrics())
+	}
 
 	// Check IPFS connectivity (non-blocking)
 	ctx := context.Background()
@@ -85,20 +174,60 @@ func main() {
 		log.Warn("💡 To start IPFS: ipfs daemon")
 	}
 
+	// Replicate pins through IPFS Cluster when configured
+	if clusterCfg := cfg.IPFS.Cluster; clusterCfg.Endpoint != "" {
+		cluster := ipfs.NewClusterClient(clusterCfg.Endpoint, clusterCfg.ReplicationMin, clusterCfg.ReplicationMax, cfg.IPFS.Timeout, log)
+		if clusterCfg.Username != "" {
+			cluster.SetBasicAuth(clusterCfg.Username, clusterCfg.Password)
+		}
+		if clusterCfg.Token != "" {
+			cluster.SetToken(clusterCfg.Token)
+		}
+		ipfsClient.SetCluster(cluster)
+
+		if clusterID, err := cluster.ID(ctx); err != nil {
+			log.Warn("⚠️  IPFS Cluster is not reachable - pins will fail until it is", "endpoint", clusterCfg.Endpoint, "error", err)
+		} else {
+			log.Info("✅ Connected to IPFS Cluster", "endpoint", clusterCfg.Endpoint, "peer_id", clusterID,
+				"replication_min", clusterCfg.ReplicationMin, "replication_max", clusterCfg.ReplicationMax)
+		}
+	}
+
 	// Initialize IPNS manager
 	ipfsShell := shell.NewShell(cfg.IPFS.APIEndpoint)
 	ipnsManager := ipfs.NewIPNSManager(ipfsShell, log)
+	ipnsManager.SetResolveTimeout(cfg.IPFS.IPNS.ResolveTimeout)
+	ipnsManager.SetPublishTimeout(cfg.IPFS.Timeouts.IPNS)
+	ipnsManager.SetMetrics(ipfsClient.Metrics())
+	if ipfsHealthy && cfg.IPFS.IPNS.Pubsub {
+		if active, err := ipnsManager.EnablePubsub(ctx); err != nil {
+			log.Warn("⚠️  Could not enable IPNS pubsub - feeds resolve through the DHT", "error", err)
+		} else if active {
+			log.Info("✅ IPNS-over-PubSub active")
+		}
+
+		// Join the pubsub topics of followed feeds so their updates arrive pushed
+		for _, name := range cfg.IPFS.IPNS.Follow {
+			go func(name string) {
+				if err := ipnsManager.Follow(ctx, name); err != nil {
+					log.Warn("Failed to follow IPNS name", "name", name, "error", err)
+				}
+			}(name)
+		}
+	}
 
 	// Initialize P2P node (if enabled)
 	var p2pNode *p2p.P2PNode
 	var broadcaster *p2p.Broadcaster
 	var reputationSys *p2p.ReputationSystem
+	var reputationSync *p2p.ReputationSync
 
 	if cfg.P2P.Enabled {
 		p2pCfg := &p2p.Config{
 			ListenAddrs:    cfg.P2P.ListenAddrs,
 			BootstrapPeers: cfg.P2P.BootstrapPeers,
 			Rendezvous:     cfg.P2P.Rendezvous,
+			DataDir:        cfg.Data.Dir(),
 		}
 
 		var err error
@@ -106,7 +235,7 @@ func main() {
 		if err != nil {
 			log.Warn("⚠️  Failed to start P2P node - continuing without P2P", "error", err)
 		} else {
-			log.Info("✅ P2P node started", "peer_id", p2pNode.GetPeerID().String())
+			log.Info("✅ P2P node started", "peer_id", p2pNode.GetPeerID().String(), "network", cfg.Network.Name, "rendezvous", cfg.P2P.Rendezvous)
 
 			// Initialize broadcaster
 			broadcaster = p2p.NewBroadcaster(p2pNode, log)
@@ -116,12 +245,41 @@ func main() {
 				log.Info("✅ P2P broadcaster started")
 			}
 
-			// Initialize reputation system
+			if nodeMetrics != nil {
+				nodeMetrics.RegisterPubsub(p2pNode)
+				nodeMetrics.RegisterNetwork(p2pNode)
+			}
+
+			// Initialize reputation system, restoring the scores saved
+			// before the last shutdown
 			reputationSys = p2p.NewReputationSystem(log)
+			reputationSys.SetStore(badger.NewReputationRepo(db))
+			if err := reputationSys.Load(ctx); err != nil {
+				log.Warn("Failed to restore reputation - starting from scratch", "error", err)
+			}
+			reputationSys.SetLocalWeight(cfg.P2P.Reputation.LocalWeight)
 			log.Info("✅ Reputation system initialized")
 
-			// Use reputation system (prevent unused variable warning)
-			_ = reputationSys
+			// Make sybil posting cost something
+			if difficulty := cfg.P2P.Reputation.ProofDifficulty; difficulty > 0 {
+				if err := broadcaster.RequireProofOfWork(reputationSys, difficulty); err != nil {
+					log.Warn("Failed to require proof of work on articles", "error", err)
+				}
+			}
+
+			// Blend in the summaries of trusted peers
+			if len(cfg.P2P.Reputation.TrustedPeers) > 0 {
+				trusted := make([]peer.ID, 0, len(cfg.P2P.Reputation.TrustedPeers))
+				for _, s := range cfg.P2P.Reputation.TrustedPeers {
+					id, err := peer.Decode(s)
+					if err != nil {
+						log.Error("Invalid peer ID in p2p.reputation.trusted_peers", "peer", s, "error", err)
+						os.Exit(1)
+					}
+					trusted = append(trusted, id)
+				}
+				reputationSync = p2p.NewReputationSync(p2pNode, reputationSys, trusted, log)
+			}
 
 			defer func() {
 				if broadcaster != nil {
@@ -144,13 +302,58 @@ func main() {
 	}
 	defer searchIndex.Close()
 
+	if nodeMetrics != nil {
+		nodeMetrics.RegisterSearchIndex(searchIndex)
+	}
+
 	count, _ := searchIndex.Count()
 	log.Info("✅ Search index opened", "path", cfg.Search.IndexPath, "document_count", count)
 
+	// Initialize article signer
+	articleSigner := auth.NewArticleSigner()
+
 	// Initialize repositories (BadgerDB)
-	articleRepo := badger.NewArticleRepo(db)
-	userRepo := badger.NewUserRepo(db)
+	var articleRepo repository.ArticleRepository = badger.NewArticleRepo(db)
+	var distributedRepo *badger.DistributedArticleRepo
+	if cfg.Database.Mode == "distributed" {
+		if broadcaster != nil {
+			distributedRepo = badger.NewDistributedArticleRepo(db, broadcaster, articleSigner, p2pNode.GetPeerID().String(), log)
+			broadcaster.OnOp(func(op *domain.ArticleOp) error {
+				return distributedRepo.ApplyRemote(ctx, op)
+			})
+			articleRepo = distributedRepo
+			log.Info("✅ Distributed article store enabled", "topic", p2p.TopicOpLog)
+		} else {
+			log.Warn("⚠️  database.mode=distributed requires P2P - using local article store")
+		}
+	}
+	// Time and trace the store itself, beneath the read cache
+	var repoObserver repository.OpObserver
+	if nodeMetrics != nil {
+		repoObserver = nodeMetrics
+	}
+	instrumentRepos := nodeMetrics != nil || cfg.Tracing.Enabled
+	if instrumentRepos {
+		articleRepo = repository.NewInstrumentedArticleRepo(articleRepo, repoObserver)
+	}
+	if cfg.Database.CacheSize > 0 {
+		cachedRepo, err := repository.NewCachedArticleRepo(articleRepo, cfg.Database.CacheSize)
+		if err != nil {
+			log.Error("Failed to create article cache", "error", err)
+			os.Exit(1)
+		}
+		if distributedRepo != nil {
+			distributedRepo.OnRemoteChange(cachedRepo.Invalidate)
+		}
+		articleRepo = cachedRepo
+		log.Info("✅ Article read cache enabled", "size", cfg.Database.CacheSize)
+	}
+	var userRepo repository.UserRepository = badger.NewUserRepo(db)
+	if instrumentRepos {
+		userRepo = repository.NewInstrumentedUserRepo(userRepo, repoObserver)
+	}
 	feedRepo := badger.NewFeedRepo(db)
+	mediaRepo := badger.NewMediaRepo(db)
 
 	// Initialize JWT manager
 	jwtManager := auth.NewJWTManager(
@@ -159,12 +362,39 @@ func main() {
 		cfg.Auth.RefreshTokenExpiry,
 	)
 
-	// Initialize article signer
-	articleSigner := auth.NewArticleSigner()
-
 	// Initialize services
-	searchService := service.NewSearchService(searchIndex, articleRepo, log)
+	searchService := service.NewSearchService(searchIndex, articleRepo, cfg.Search.MaxFuzziness, log)
+	searchService.SetResultCache(cfg.Search.CacheSize, cfg.Search.CacheTTL)
+	if nodeMetrics != nil {
+		searchService.SetObserver(nodeMetrics)
+	}
+
+	// Filter min_trust searches by current author reputation
+	if reputationSys != nil {
+		searchService.SetTrustScorer(reputationSys)
+	}
+
+	// Forward scope=network searches to connected peers
+	if p2pNode != nil && cfg.Search.FederatedPeers > 0 {
+		federatedSearch := p2p.NewFederatedSearch(
+			p2pNode.GetHost(),
+			searchService,
+			cfg.Search.FederatedPeers,
+			cfg.Search.FederatedTimeout,
+			log,
+		)
+		searchService.SetNetworkSearcher(federatedSearch)
+		log.Info("✅ Federated search enabled", "peers", cfg.Search.FederatedPeers)
+	}
+	// Append-only audit log of moderation, bans, roles and config changes
+	auditService := service.NewAuditService(badger.NewAuditRepo(db), log)
+	if err := auditService.RecordSettings(ctx, cfg.Settings()); err != nil {
+		log.Error("Failed to record configuration in the audit log", "error", err)
+		os.Exit(1)
+	}
+
 	userService := service.NewUserService(userRepo, jwtManager, cfg.Auth.BcryptCost, log)
+	userService.SetAuditLog(auditService)
 	articleService := service.NewArticleService(
 		articleRepo,
 		userRepo,
@@ -174,25 +404,217 @@ func main() {
 		searchService,
 		log,
 	)
+	if ipfsHealthy {
+		articleService.SetDAGStore(ipfsClient)
+	}
+	articleService.SetMediaCatalog(mediaRepo)
+	if distributedRepo != nil {
+		// Replicated puts pass the same checks as gossiped articles
+		distributedRepo.SetIngester(articleService)
+	}
+
+	// Event bus for real-time clients and sinks
+	eventBus := events.NewBus(log)
+	defer eventBus.Close()
+	articleService.SetEventPublisher(eventBus)
+	if nodeMetrics != nil {
+		eventBus.AddSink("metrics", nodeMetrics)
+	}
+	for i, hook := range cfg.Events.Webhooks {
+		eventBus.AddSink(fmt.Sprintf("webhook[%d]", i), events.NewWebhookSink(hook.URL, hook.Secret, hook.Timeout), hook.Types...)
+	}
+	if len(cfg.Events.Audit) > 0 {
+		eventBus.AddSink("audit", events.NewAuditSink(auditService), cfg.Events.Audit...)
+	}
+
+	// Fetch missing articles from peers, and announce stored ones in the DHT
+	if p2pNode != nil {
+		articleService.SetPeerFetcher(p2p.NewArticleFetcher(p2pNode, articleService, log))
+	}
+
+	// Pin ledger: retry failed pins and reconcile against the pinset
+	var pinLedger *service.PinLedgerService
+	var gcService *service.GCService
+	if cfg.IPFS.PinArticles {
+		pinRepo := badger.NewPinRepo(db)
+		pinLedger = service.NewPinLedgerService(pinRepo, ipfsClient, service.PinLedgerConfig{
+			RetryInterval:     cfg.IPFS.PinRetryInterval,
+			ReconcileInterval: cfg.IPFS.PinReconcileInterval,
+			MaxBackoff:        cfg.IPFS.PinMaxBackoff,
+		}, log)
+		articleService.SetPinTracker(pinLedger)
+		if nodeMetrics != nil {
+			nodeMetrics.RegisterPinLedger(pinLedger)
+		}
+
+		gcService = service.NewGCService(pinRepo, articleRepo, ipfsClient, service.RetentionPolicy{
+			RevisionRetention: cfg.IPFS.GC.RevisionRetention,
+			ArticleMaxAge:     cfg.IPFS.GC.ArticleMaxAge,
+			RepoGC:            cfg.IPFS.GC.RepoGC,
+		}, log)
+	}
+
+	// Votes, credited to author reputation and pushed to live clients
+	voteService := service.NewVoteService(articleRepo, userRepo, articleSigner, log)
+	voteService.SetEventPublisher(eventBus)
+	if reputationSys != nil {
+		voteService.SetReputation(reputationSys)
+	}
+	voteService.SetIndexer(searchService)
+
+	// Store weighted vote scores and author trust with indexed articles for
+	// most-voted and trust-ordered search
+	searchIndex.SetSignalProvider(search.SignalFunc(func(article *domain.Article) search.Signals {
+		signals := search.Signals{Votes: voteService.WeightedScore(article.ID)}
+		if reputationSys != nil {
+			signals.TrustScore = reputationSys.ArticleTrust(article)
+		}
+		return signals
+	}))
+
+	// Endorsements, counted toward the subject's reputation
+	var endorsementService *service.EndorsementService
+	if reputationSys != nil {
+		endorsementService = service.NewEndorsementService(badger.NewEndorsementRepo(db), articleRepo, userRepo, articleSigner, reputationSys, log)
+		if err := endorsementService.Load(ctx); err != nil {
+			log.Warn("Failed to load endorsements", "error", err)
+		}
+	}
+
+	// Comments, searchable with doc_type=comment
+	commentService := service.NewCommentService(badger.NewCommentRepo(db), articleRepo, userRepo, log)
+	commentService.SetIndexer(searchService)
+
+	// Users' trust graph personalizes feeds and hides blocked identities' comments
+	trustService := service.NewTrustGraphService(badger.NewTrustEdgeRepo(db), userRepo, articleRepo, cfg.Trust.MaxDepth, cfg.Trust.Decay, log)
+
+	// Moderation queue for reported articles
+	moderationService := service.NewModerationService(badger.NewReportRepo(db), badger.NewModerationCaseRepo(db), articleRepo, log)
+	moderationService.SetAuditLog(auditService)
+	moderationService.SetEventPublisher(eventBus)
+	moderationService.SetIndexer(searchService)
+	moderationService.SetUnpinner(ipfsClient)
+	if pinLedger != nil {
+		moderationService.SetPinTracker(pinLedger)
+	}
+	if reputationSys != nil {
+		moderationService.SetReputation(reputationSys)
+	}
+
+	// Authors appeal moderation of their articles; reversals restore them
+	appealService := service.NewAppealService(badger.NewAppealRepo(db), articleRepo, userRepo, articleSigner, moderationService, log)
+	appealService.SetAuditLog(auditService)
+
+	// Operator filter lists screen articles published here and received from peers
+	filterService := service.NewFilterService(badger.NewFilterListRepo(db), log)
+	filterService.SetAuditLog(auditService)
+	if err := filterService.Load(ctx); err != nil {
+		// Serving without the operator's filters could break local law
+		log.Error("Failed to load filter lists", "error", err)
+		os.Exit(1)
+	}
+	articleService.SetContentFilter(filterService, moderationService)
+
+	// Blocklists from curators the operator subscribed to refuse peers, authors and articles
+	blocklistService := service.NewBlocklistService(badger.NewBlocklistRepo(db), ipnsManager, ipfsClient, log)
+	blocklistService.SetAuditLog(auditService)
+	if err := blocklistService.Load(ctx); err != nil {
+		log.Error("Failed to load blocklists", "error", err)
+		os.Exit(1)
+	}
+	articleService.SetBlocklist(blocklistService)
+	if p2pNode != nil {
+		p2pNode.SetPeerBlocker(blocklistService)
+		blocklistService.SetDisconnector(p2pNode)
+	}
+
+	// The operator's acceptance policy decides which articles from peers are kept
+	policyEngine := service.NewPolicyEngine(domain.AcceptancePolicy{
+		Categories:               cfg.Acceptance.Categories,
+		Languages:                cfg.Acceptance.Languages,
+		MaxArticleSize:           cfg.Acceptance.MaxArticleSize,
+		MinAuthorReputation:      cfg.Acceptance.MinAuthorReputation,
+		RequireAttachmentsPinned: cfg.Acceptance.RequireAttachmentsPinned,
+	}, log)
+	policyEngine.SetPinner(ipfsClient)
+	if reputationSys != nil {
+		policyEngine.SetReputation(reputationSys)
+	} else if cfg.Acceptance.MinAuthorReputation > 0 {
+		log.Warn("acceptance.min_author_reputation needs P2P reputation; the rule is not enforced")
+	}
+	articleService.SetAcceptancePolicy(policyEngine)
+	// Peers' search results are held to the same checks as gossiped articles
+	searchService.SetRemoteChecker(articleService)
+
+	// An operator's classifier scores articles from peers before the filter lists see them
+	if cfg.Classifier.Endpoint != "" {
+		contentClassifier, err := classifier.New(cfg.Classifier.Endpoint, cfg.Classifier.Timeout)
+		if err != nil {
+			log.Error("Failed to set up the content classifier", "error", err)
+			os.Exit(1)
+		}
+		articleService.SetClassifier(contentClassifier, cfg.Classifier.Required)
+		log.Info("Content classifier enabled", "endpoint", cfg.Classifier.Endpoint, "required", cfg.Classifier.Required)
+	}
+
+	// Circles encrypt articles for their members; the plaintext stays on members' nodes
+	circleService := service.NewCircleService(badger.NewCircleRepo(db), userRepo, articleRepo, articleSigner, log)
+	circleService.SetReceiver(articleService)
+	articleService.SetCircles(circleService)
 
 	// Register P2P handlers
 	var p2pSyncService *p2p.SyncService
 	if broadcaster != nil {
-		broadcaster.OnArticle(func(msg *p2p.ArticleMessage) error {
-			if msg.Article != nil {
-				return articleService.HandleIncomingArticle(msg.Article)
+		broadcaster.SetEventPublisher(eventBus)
+		eventBus.AddSink("article-ingest", articleService, domain.EventArticleGossip)
+
+		moderationService.SetBroadcaster(broadcaster)
+		broadcaster.OnModeration(func(msg *p2p.ModerationMessage) error {
+			return moderationService.HandlePeerAction(ctx, msg.ArticleID, msg.Action, msg.Reason, msg.ReporterDID, time.Unix(msg.Timestamp, 0))
+		})
+
+		voteService.SetBroadcaster(broadcaster)
+		broadcaster.OnVote(func(msg *p2p.VoteMessage) error {
+			return voteService.HandleIncomingVote(ctx, msg.ToDomain())
+		})
+
+		if endorsementService != nil {
+			endorsementService.SetBroadcaster(broadcaster)
+			broadcaster.OnEndorsement(func(endorsement *domain.Endorsement) error {
+				return endorsementService.HandleIncomingEndorsement(ctx, endorsement)
+			})
+		}
+
+		circleService.SetBroadcaster(broadcaster)
+		broadcaster.OnCircle(func(msg *p2p.CircleMessage) error {
+			switch {
+			case msg.Type == p2p.CircleMessageKeys && msg.Keys != nil:
+				return circleService.HandleIncomingKeys(ctx, msg.Keys)
+			case msg.Type == p2p.CircleMessageArticle && msg.Article != nil:
+				return circleService.HandleIncomingSealed(ctx, msg.CID, msg.Article)
 			}
-			return nil
+			return fmt.Errorf("malformed circle message %q", msg.Type)
 		})
 
 		// Initialize P2P sync service for periodic article pulling
 		if p2pNode != nil {
+			p2pNode.OnPeerChange(func(id peer.ID, connected bool, peers int) {
+				eventType := domain.EventPeerDisconnected
+				if connected {
+					eventType = domain.EventPeerConnected
+				}
+				eventBus.Publish(eventType, domain.PeerEvent{PeerID: id.String(), Peers: peers})
+			})
+
 			p2pSyncService = p2p.NewSyncService(
 				p2pNode.GetHost(),
 				articleService,
 				articleService,
 				log,
 			)
+			p2pSyncService.OnProgress(func(progress domain.SyncProgress) {
+				eventBus.Publish(domain.EventSyncProgress, progress)
+			})
 			p2pSyncService.Start()
 			log.Info("✅ P2P sync service started", "interval", "30s")
 
@@ -200,21 +622,125 @@ func main() {
 		}
 	}
 
-	feedService := service.NewFeedService(feedRepo, articleRepo, ipnsManager, log)
+	integrityService := service.NewIntegrityService(
+		articleRepo,
+		badger.NewArticleRepo(db),
+		ipfsClient,
+		articleSigner,
+		log,
+	)
+
+	// Index maintenance (window is validated in config when enabled)
+	optimizeStart, optimizeEnd, _ := cfg.Search.Optimize.Window()
+	indexMaintenance := service.NewIndexMaintenanceService(searchIndex, service.IndexMaintenanceConfig{
+		WindowStart:      optimizeStart,
+		WindowEnd:        optimizeEnd,
+		MinSegments:      uint64(cfg.Search.Optimize.MinSegments),
+		MinFragmentation: cfg.Search.Optimize.MinFragmentation,
+	}, log)
+
+	// Storage quota for synced articles; stats are served even when the
+	// sweeper is off
+	retentionService := service.NewRetentionService(articleRepo, userRepo, service.StorageQuota{
+		MaxDiskUsage:      cfg.Retention.MaxDiskUsage,
+		MaxSyncedArticles: cfg.Retention.MaxSyncedArticles,
+		CategoryMaxAge:    cfg.Retention.Categories,
+		Paths:             []string{cfg.Database.Path, cfg.Search.IndexPath},
+	}, log)
+	retentionService.SetIndexer(searchService)
+
+	// Network policy published by the network's operators over IPNS
+	var policyService *service.NetworkPolicyService
+	if cfg.Network.Policy.Name != "" {
+		policyService, err = service.NewNetworkPolicyService(cfg.Network.Policy.Name, cfg.Network.Policy.PublisherKey, p2p.ProtocolVersion, ipnsManager, ipfsClient, log)
+		if err != nil {
+			log.Error("Failed to configure network policy", "error", err)
+			os.Exit(1)
+		}
+		if p2pNode != nil {
+			policyService.SetBootstrapper(p2pNode)
+		}
+		articleService.SetCategoryPolicy(policyService)
+	}
+
+	archiveService := service.NewArchiveService(articleRepo, ipfsClient, articleService, log)
+
+	feedService := service.NewFeedService(feedRepo, articleRepo, ipfsClient, ipnsManager, log)
+	feedService.SetSeeder(articleService)
 	syncService := service.NewSyncService(feedRepo, articleRepo, ipfsClient, ipnsManager, log)
+	if cfg.IPFS.MFSFeedRoot != "" {
+		syncService.SetMirror(ipfsClient, cfg.IPFS.MFSFeedRoot)
+	}
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userService, log)
 	articleHandler := handlers.NewArticleHandler(articleService, log)
+	articleHandler.SetMaxBatchSize(cfg.Server.MaxBatchSize)
+	if reputationSys != nil {
+		articleHandler.SetTrustScorer(reputationSys)
+	}
+	articleHandler.SetCircles(circleService)
 	feedHandler := handlers.NewFeedHandler(feedService, syncService, log)
 	searchHandler := handlers.NewSearchHandler(searchService, log)
 	healthHandler := handlers.NewHealthHandler(db, ipfsClient, searchIndex, log)
-	uploadHandler := handlers.NewUploadHandler(ipfsClient, log)
+	healthHandler.SetNetwork(p2pNode, p2pSyncService)
+	uploadService := service.NewUploadService(ipfsClient, cfg.Upload.ChunkSize, cfg.Upload.MaxSize, log)
+	uploadService.SetMediaCatalog(mediaRepo, cfg.Upload.TempDir)
+	if command := cfg.Upload.Transcode.Command; len(command) > 0 {
+		transcoder, err := media.NewCommandTranscoder(command)
+		if err != nil {
+			log.Error("Failed to configure transcoder", "error", err)
+			os.Exit(1)
+		}
+		uploadService.SetTranscoder(transcoder, cfg.Upload.Transcode.Timeout)
+		log.Info("✅ Media transcoding enabled", "command", command[0])
+	}
+	uploadHandler := handlers.NewUploadHandler(ipfsClient, uploadService, log)
 	networkHandler := handlers.NewNetworkHandler(p2pNode, p2pSyncService, log)
+	if policyService != nil {
+		networkHandler.SetPolicy(policyService)
+	}
+	integrityHandler := handlers.NewIntegrityHandler(integrityService, log)
+	adminHandler := handlers.NewAdminHandler(userService, db, log)
+	adminHandler.SetAuditLog(auditService)
+	adminHandler.SetRetention(retentionService)
+	adminHandler.SetFilters(filterService)
+	adminHandler.SetBlocklists(blocklistService)
+	adminHandler.SetAcceptance(policyEngine)
+	indexHandler := handlers.NewIndexMaintenanceHandler(indexMaintenance, log)
+	archiveHandler := handlers.NewArchiveHandler(archiveService, log)
+	eventsHandler := handlers.NewEventsHandler(eventBus, cfg.CORS.AllowedOrigins, log)
+	v2Handler := handlers.NewV2Handler(articleService, searchService, log)
+	moderationHandler := handlers.NewModerationHandler(moderationService, log)
+	moderationHandler.SetAppeals(appealService)
+	voteHandler := handlers.NewVoteHandler(voteService, log)
+	commentHandler := handlers.NewCommentHandler(commentService, log)
+	commentHandler.SetTrustGraph(trustService)
+	reputationHandler := handlers.NewReputationHandler(reputationSys, log)
+	reputationHandler.SetTrustGraph(trustService)
+	if reputationSync != nil {
+		reputationHandler.SetSync(reputationSync)
+	}
+	if endorsementService != nil {
+		reputationHandler.SetEndorsements(endorsementService)
+	}
+	var pinHandler *handlers.PinLedgerHandler
+	if pinLedger != nil {
+		pinHandler = handlers.NewPinLedgerHandler(pinLedger, gcService, log)
+	}
 
 	// Initialize web handler
 	webHandler := web.NewWebHandler(articleService, userService, searchService, jwtManager, db, p2pNode, ipfsClient, log)
 
+	webHandler.SetModerators(append(append([]string{}, cfg.Auth.AdminUsers...), cfg.Auth.Moderators...))
+	webHandler.SetComments(commentService)
+	webHandler.SetDashboard(voteService, pinLedger)
+	webHandler.SetModeration(moderationService)
+	webHandler.SetSyncService(p2pSyncService)
+	if reputationSys != nil {
+		webHandler.SetTrustScorer(reputationSys)
+	}
+
 	// Initialize router
 	router := api.NewRouter(
 		authHandler,
@@ -224,6 +750,17 @@ func main() {
 		healthHandler,
 		uploadHandler,
 		networkHandler,
+		integrityHandler,
+		indexHandler,
+		archiveHandler,
+		pinHandler,
+		eventsHandler,
+		v2Handler,
+		moderationHandler,
+		voteHandler,
+		commentHandler,
+		reputationHandler,
+		adminHandler,
 		webHandler,
 		jwtManager,
 		userService,
@@ -231,6 +768,10 @@ func main() {
 		log,
 	)
 
+	if nodeMetrics != nil {
+		router.SetMetrics(nodeMetrics)
+	}
+
 	// Setup routes
 	engine := router.Setup()
 
@@ -267,6 +808,85 @@ func main() {
 	// Start background sync service
 	go syncService.Start(ctx, 15) // Sync every 15 minutes
 
+	// Indexes from older versions lack fields newer queries rely on
+	if searchIndex.Outdated() {
+		log.Warn("⚠️  Search index mapping is outdated - rebuilding in the background")
+		if _, err := searchService.StartReindex(ctx); err != nil {
+			log.Error("Failed to start search index rebuild", "error", err)
+		}
+	}
+
+	// Start scheduled index optimization
+	if cfg.Search.Optimize.Enabled {
+		go indexMaintenance.Start(ctx, 10*time.Minute)
+	}
+
+	// Start pin retry and reconciliation
+	if pinLedger != nil {
+		go pinLedger.Start(ctx)
+	}
+	if gcService != nil && cfg.IPFS.GC.Enabled {
+		go gcService.Start(ctx, cfg.IPFS.GC.Interval)
+	}
+	if cfg.Retention.Enabled {
+		go retentionService.Start(ctx, cfg.Retention.Interval)
+	}
+	if policyService != nil {
+		go policyService.Start(ctx, cfg.Network.Policy.Interval)
+	}
+	go blocklistService.Start(ctx, cfg.Blocklists.Interval)
+	if reputationSys != nil {
+		go reputationSys.Start(ctx, cfg.P2P.Reputation.SnapshotInterval)
+	}
+	if reputationSync != nil {
+		go reputationSync.Start(ctx, cfg.P2P.Reputation.SyncInterval)
+	}
+
+	// Start alert checks
+	var alertManager *alerts.Manager
+	if cfg.Alerts.Enabled {
+		alertManager, err = newAlertManager(cfg, ipfsClient, p2pNode, p2pSyncService, log)
+		if err != nil {
+			log.Error("Failed to set up alerts", "error", err)
+			os.Exit(1)
+		}
+		go alertManager.Start(ctx, cfg.Alerts.Interval)
+	}
+
+	// Start the gRPC API on its own port
+	var grpcServer *grpcapi.Server
+	if cfg.GRPC.Enabled {
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.GRPC.Port)
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Error("❌ gRPC server failed to start", "address", grpcAddr, "error", err)
+			os.Exit(1)
+		}
+		grpcServer = grpcapi.NewServer(articleService, searchService, jwtManager, log)
+		grpcServer.SetNetwork(p2pNode, p2pSyncService)
+		grpcServer.SetEventBus(eventBus)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Error("gRPC server stopped", "error", err)
+			}
+		}()
+	}
+
+	// Runtime profiles on their own loopback listener; without one they are
+	// served under the admin API
+	var pprofServer *http.Server
+	if cfg.Pprof.Enabled && cfg.Pprof.ListenAddr != "" {
+		pprofServer = &http.Server{Addr: cfg.Pprof.ListenAddr, Handler: api.PprofEngine()}
+		go func() {
+			log.Info("🔬 pprof listening", "address", cfg.Pprof.ListenAddr)
+			if err := pprofServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error("pprof server stopped", "error", err)
+			}
+		}()
+	} else if cfg.Pprof.Enabled {
+		log.Info("🔬 pprof enabled for admins", "path", "/api/v1/admin/debug/pprof/")
+	}
+
 	// Start server in goroutine
 	go func() {
 		log.Info("🌐 HTTP server starting", "address", addr)
@@ -290,6 +910,24 @@ func main() {
 	}
 	log.Info("Press Ctrl+C to stop")
 
+	// SIGHUP re-reads log levels from the config file, replacing any set
+	// through the admin API
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reloaded, err := config.LoadWithOverrides(overrides)
+			if err == nil {
+				err = log.Levels().Replace(reloaded.Logging.Level, reloaded.Logging.Components)
+			}
+			if err != nil {
+				log.Error("Failed to reload log levels", "error", err)
+				continue
+			}
+			log.Info("Log levels reloaded", "level", reloaded.Logging.Level, "components", reloaded.Logging.Components)
+		}
+	}()
+
 	// Wait for interrupt signal for graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -299,6 +937,28 @@ func main() {
 
 	// Stop background services
 	syncService.Stop()
+	if cfg.Search.Optimize.Enabled {
+		indexMaintenance.Stop()
+	}
+	if pinLedger != nil {
+		pinLedger.Stop()
+	}
+	if gcService != nil && cfg.IPFS.GC.Enabled {
+		gcService.Stop()
+	}
+	if cfg.Retention.Enabled {
+		retentionService.Stop()
+	}
+	if policyService != nil {
+		policyService.Stop()
+	}
+	blocklistService.Stop()
+	if reputationSync != nil {
+		reputationSync.Stop()
+	}
+	if alertManager != nil {
+		alertManager.Stop()
+	}
 
 	// Graceful shutdown with timeout
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
@@ -307,16 +967,108 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Error("Server forced to shutdown", "error", err)
 	}
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+			log.Error("gRPC server forced to shutdown", "error", err)
+		}
+	}
+	if pprofServer != nil {
+		// A CPU profile or trace in progress is cut short
+		pprofServer.Close()
+	}
+	// Saved last, once the servers stop taking votes
+	if reputationSys != nil {
+		reputationSys.Stop()
+	}
 
 	log.Info("✅ Server stopped gracefully")
 }
 
+// switchFlag is a boolean flag that also accepts on and off, as in -p2p=off.
+// It stays nil unless given.
+type switchFlag struct {
+	value **bool
+}
+
+func (f *switchFlag) String() string {
+	if f.value == nil || *f.value == nil {
+		return ""
+	}
+	if **f.value {
+		return "on"
+	}
+	return "off"
+}
+
+func (f *switchFlag) Set(s string) error {
+	var on bool
+	switch strings.ToLower(s) {
+	case "on", "true", "yes", "1":
+		on = true
+	case "off", "false", "no", "0":
+	default:
+		return fmt.Errorf("want on or off, got %q", s)
+	}
+	*f.value = &on
+	return nil
+}
+
+func (f *switchFlag) IsBoolFlag() bool { return true }
+
+// newAlertManager builds the configured alert rules and notifiers. Rules
+// about the P2P network are skipped when it isn't running.
+func newAlertManager(cfg *config.Config, ipfsClient *ipfs.Client, node *p2p.P2PNode, syncService *p2p.SyncService, log *logger.Logger) (*alerts.Manager, error) {
+	notifiers := make([]alerts.Notifier, 0, len(cfg.Alerts.Notify))
+	for i, target := range cfg.Alerts.Notify {
+		notifier, err := alerts.NewNotifier(target, cfg.Alerts.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("alerts.notify[%d]: %w", i, err)
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	name, _ := os.Hostname()
+	if node != nil {
+		name = node.GetPeerID().String()
+	}
+	manager := alerts.NewManager(name, notifiers, log)
+
+	for _, rule := range cfg.Alerts.Rules {
+		var condition alerts.Condition
+		switch rule.Kind {
+		case config.AlertPeersBelow:
+			if node != nil {
+				condition = alerts.PeersBelow(node, int(rule.Threshold))
+			}
+		case config.AlertIPFSUnhealthy:
+			condition = alerts.IPFSUnhealthy(ipfsClient)
+		case config.AlertDiskAbove:
+			path := rule.Path
+			if path == "" {
+				path = cfg.Data.Root
+			}
+			condition = alerts.DiskAbove(path, rule.Threshold)
+		case config.AlertSyncStalled:
+			if syncService != nil {
+				condition = alerts.SyncStalled(syncService)
+			}
+		}
+		if condition == nil {
+			log.Warn("Skipping alert rule, P2P is not running", "rule", rule.RuleName())
+			continue
+		}
+		manager.AddRule(alerts.Rule{Name: rule.RuleName(), Condition: condition, For: rule.For})
+	}
+	return manager, nil
+}
+
 // ensureDirectories creates required directories if they don't exist
 func ensureDirectories(cfg *config.Config, log *logger.Logger) error {
 	dirs := []struct {
 		path string
 		name string
 	}{
+		{cfg.Data.Dir(), "data directory"},
 		{filepath.Dir(cfg.Database.Path), "database directory"},
 		{filepath.Dir(cfg.Search.IndexPath), "search index directory"},
 	}
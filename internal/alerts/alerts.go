@@ -0,0 +1,199 @@
+// Package alerts checks the node's health on an interval and notifies
+// operators when a rule starts or stops firing.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// Alert statuses
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Condition reports whether a rule's problem is present, with a short
+// description of what was seen
+type Condition func(ctx context.Context) (bool, string, error)
+
+// Rule is a condition that must hold for For before its alert fires
+type Rule struct {
+	Name      string
+	Condition Condition
+	For       time.Duration
+}
+
+// Alert is one notification: a rule started or stopped firing
+type Alert struct {
+	Rule   string    `json:"rule"`
+	Status string    `json:"status"`
+	Detail string    `json:"detail"`
+	Node   string    `json:"node,omitempty"`
+	Since  time.Time `json:"since"` // when the condition was first seen
+	At     time.Time `json:"at"`
+}
+
+// Text renders the alert for chat and email
+func (a Alert) Text() string {
+	node := ""
+	if a.Node != "" {
+		node = " on " + a.Node
+	}
+	if a.Status == StatusResolved {
+		return fmt.Sprintf("[RESOLVED] %s%s after %s", a.Rule, node, a.At.Sub(a.Since).Round(time.Second))
+	}
+	return fmt.Sprintf("[FIRING] %s%s: %s", a.Rule, node, a.Detail)
+}
+
+// ruleState tracks one rule between checks
+type ruleState struct {
+	since  time.Time // when the condition started holding; zero if it isn't
+	firing bool
+}
+
+// Manager checks rules on an interval and notifies on every transition
+type Manager struct {
+	node      string
+	notifiers []Notifier
+	logger    *logger.Logger
+
+	mu    sync.Mutex
+	rules []Rule
+	state map[string]*ruleState
+
+	stopChan chan struct{}
+}
+
+// NewManager creates a manager sending alerts about node to notifiers
+func NewManager(node string, notifiers []Notifier, logger *logger.Logger) *Manager {
+	return &Manager{
+		node:      node,
+		notifiers: notifiers,
+		logger:    logger.WithComponent("alerts"),
+		state:     make(map[string]*ruleState),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// AddRule adds a rule to every later check
+func (m *Manager) AddRule(rule Rule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rule)
+	m.state[rule.Name] = &ruleState{}
+}
+
+// Start checks the rules every interval until stopped
+func (m *Manager) Start(ctx context.Context, interval time.Duration) {
+	m.logger.Ctx(ctx).Info("Starting alert checks", "rules", len(m.rules), "notifiers", len(m.notifiers), "interval", interval.String())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.Check(ctx)
+		case <-m.stopChan:
+			m.logger.Ctx(ctx).Info("Stopping alert checks")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the check loop
+func (m *Manager) Stop() {
+	close(m.stopChan)
+}
+
+// Check evaluates every rule once and notifies about those that started
+// or stopped firing, returning the alerts sent. A rule whose condition
+// can't be evaluated keeps its state until the next check.
+func (m *Manager) Check(ctx context.Context) []Alert {
+	m.mu.Lock()
+	rules := append([]Rule(nil), m.rules...)
+	m.mu.Unlock()
+
+	var alerts []Alert
+	for _, rule := range rules {
+		present, detail, err := rule.Condition(ctx)
+		if err != nil {
+			m.logger.Ctx(ctx).Warn("Failed to check alert rule", "rule", rule.Name, "error", err)
+			continue
+		}
+		if alert, ok := m.transition(rule, present, detail); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	for _, alert := range alerts {
+		m.notify(ctx, alert)
+	}
+	return alerts
+}
+
+// transition updates a rule's state and returns the alert to send, if any
+func (m *Manager) transition(rule Rule, present bool, detail string) (Alert, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	state := m.state[rule.Name]
+	alert := Alert{Rule: rule.Name, Detail: detail, Node: m.node, Since: state.since, At: now}
+
+	if !present {
+		wasFiring := state.firing
+		state.since, state.firing = time.Time{}, false
+		alert.Status = StatusResolved
+		return alert, wasFiring
+	}
+
+	if state.since.IsZero() {
+		state.since = now
+		alert.Since = now
+	}
+	if state.firing || now.Sub(state.since) < rule.For {
+		return alert, false
+	}
+	state.firing = true
+	alert.Status = StatusFiring
+	return alert, true
+}
+
+// notify sends an alert to every notifier, logging failures
+func (m *Manager) notify(ctx context.Context, alert Alert) {
+	if alert.Status == StatusFiring {
+		m.logger.Ctx(ctx).Warn("Alert firing", "rule", alert.Rule, "detail", alert.Detail)
+	} else {
+		m.logger.Ctx(ctx).Info("Alert resolved", "rule", alert.Rule)
+	}
+
+	for _, notifier := range m.notifiers {
+		if err := notifier.Notify(ctx, alert); err != nil {
+			m.logger.Ctx(ctx).Error("Failed to send alert", "rule", alert.Rule, "notifier", notifier.String(), "error", err)
+		}
+	}
+}
+
+// Firing returns the names of the rules currently firing
+func (m *Manager) Firing() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var names []string
+	for name, state := range m.state {
+		if state.firing {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package alerts
+
+import "errors"
+
+func diskUsage(path string) (float64, error) {
+	return 0, errors.New("disk usage is not supported on this platform")
+}
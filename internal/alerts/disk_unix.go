@@ -0,0 +1,20 @@
+//go:build linux || darwin
+
+package alerts
+
+import "syscall"
+
+// diskUsage returns how full the filesystem holding path is, in percent
+// of the space available to unprivileged users
+func diskUsage(path string) (float64, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return 0, err
+	}
+	used := fs.Blocks - fs.Bfree
+	total := used + fs.Bavail
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(used) / float64(total) * 100, nil
+}
@@ -0,0 +1,63 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PeerCounter reports how many peers the node is connected to
+type PeerCounter interface {
+	GetPeerCount() int
+}
+
+// HealthChecker reports whether a dependency is reachable
+type HealthChecker interface {
+	IsHealthy(ctx context.Context) bool
+}
+
+// SyncClock reports when article sync last ran and how often it should
+type SyncClock interface {
+	GetLastSyncTime() time.Time
+	SyncInterval() time.Duration
+}
+
+// PeersBelow is present while fewer than min peers are connected
+func PeersBelow(node PeerCounter, min int) Condition {
+	return func(ctx context.Context) (bool, string, error) {
+		peers := node.GetPeerCount()
+		return peers < min, fmt.Sprintf("%d connected peers, want at least %d", peers, min), nil
+	}
+}
+
+// IPFSUnhealthy is present while the IPFS daemon can't be reached
+func IPFSUnhealthy(ipfs HealthChecker) Condition {
+	return func(ctx context.Context) (bool, string, error) {
+		return !ipfs.IsHealthy(ctx), "IPFS daemon is unreachable", nil
+	}
+}
+
+// DiskAbove is present while the filesystem holding path is more than
+// percent full
+func DiskAbove(path string, percent float64) Condition {
+	return func(ctx context.Context) (bool, string, error) {
+		used, err := diskUsage(path)
+		if err != nil {
+			return false, "", err
+		}
+		return used > percent, fmt.Sprintf("%s is %.1f%% full, threshold %.0f%%", path, used, percent), nil
+	}
+}
+
+// SyncStalled is present once article sync has missed two rounds, the
+// same test the health check uses
+func SyncStalled(sync SyncClock) Condition {
+	return func(ctx context.Context) (bool, string, error) {
+		last, interval := sync.GetLastSyncTime(), sync.SyncInterval()
+		if last.IsZero() {
+			return false, "", nil
+		}
+		since := time.Since(last)
+		return since > 2*interval, fmt.Sprintf("last sync round %s ago, interval %s", since.Round(time.Second), interval), nil
+	}
+}
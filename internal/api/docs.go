@@ -0,0 +1,227 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/openapi"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// apiInfo heads the generated OpenAPI document
+var apiInfo = openapi.Info{
+	Title:       "Liberation News API",
+	Description: "REST API for the Decentralized News Platform",
+	Version:     "1.0.0",
+}
+
+// Shared query parameters
+var (
+	pageParams = []openapi.Param{
+		{Name: "page", Type: "integer", Description: "Page number, from 1"},
+		{Name: "limit", Type: "integer", Description: "Items per page, at most 100"},
+	}
+	filterParams = []openapi.Param{
+		{Name: "author", Description: "Author username"},
+		{Name: "category"},
+		{Name: "from", Description: "Earliest timestamp, RFC 3339"},
+		{Name: "to", Description: "Latest timestamp, RFC 3339"},
+	}
+)
+
+func params(groups ...[]openapi.Param) []openapi.Param {
+	var out []openapi.Param
+	for _, g := range groups {
+		out = append(out, g...)
+	}
+	return out
+}
+
+// routeDocs describes the API routes, keyed by method and gin path. Every
+// /api and /health route is listed in the generated document whether or not
+// it appears here, but one missing from this table is logged at startup and
+// marked x-undocumented.
+var routeDocs = map[string]openapi.Operation{
+	// Health
+	"GET /health":       {Summary: "Per-component health: healthy, degraded or unhealthy (503)", Response: handlers.HealthReport{}, ContentType: "application/json"},
+	"GET /health/ready": {Summary: "Readiness probe"},
+	"GET /health/live":  {Summary: "Liveness probe"},
+
+	// Auth
+	"POST /api/v1/auth/register": {Summary: "Register a new identity", Body: domain.UserRegisterRequest{}, Response: domain.UserResponse{}, Status: http.StatusCreated},
+	"POST /api/v1/auth/login":    {Summary: "Log in and receive tokens", Body: domain.UserLoginRequest{}, Response: domain.LoginResponse{}},
+	"POST /api/v1/auth/refresh": {Summary: "Exchange a refresh token for new tokens", Body: struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}{}, Response: domain.AuthTokens{}},
+	"GET /api/v1/auth/me": {Summary: "The authenticated user", Auth: true, Response: domain.UserResponse{}},
+
+	// Uploads and media
+	"POST /api/v1/upload/image":              {Summary: "Upload an image to IPFS", Auth: true, File: "image"},
+	"POST /api/v1/upload/media":              {Summary: "Stream an audio, video or image upload to IPFS", Auth: true, File: "file", Params: []openapi.Param{{Name: "upload_id", Description: "Reserved ID for progress reporting"}, {Name: "size", Type: "integer", Description: "Expected size in bytes"}}},
+	"POST /api/v1/upload/media/reservations": {Summary: "Reserve an upload ID to follow before posting the file", Auth: true, Status: http.StatusCreated},
+	"GET /api/v1/upload/media/:id/progress":  {Summary: "Progress of one of the caller's reserved or started uploads as server-sent events", Auth: true, ContentType: "text/event-stream"},
+	"GET /api/v1/media/:cid":                 {Summary: "Uploaded media metadata and renditions", Response: domain.Media{}},
+	"GET /api/v1/network/stats":              {Summary: "P2P network statistics"},
+	"GET /api/v1/network/peers":              {Summary: "Connected peers"},
+	"GET /api/v1/network/peers/:id":          {Summary: "Details of a connected peer"},
+	"GET /api/v1/network/topology":           {Summary: "Peers, links and topic membership", Response: p2p.Topology{}},
+	"GET /api/v1/network/pubsub":             {Summary: "Message counts, subscribed peers and last message times per pubsub topic"},
+	"GET /api/v1/network/policy":             {Summary: "Network policy in force and the state of its IPNS subscription", Response: domain.NetworkPolicyStatus{}},
+	"GET /api/v1/network/dht/findpeer/:id":   {Summary: "Look up a peer's addresses in the DHT"},
+	"GET /api/v1/network/dht/findprovs/:cid": {Summary: "Find providers of a CID in the DHT", Params: []openapi.Param{{Name: "limit", Type: "integer"}}},
+	"POST /api/v1/network/connect":           {Summary: "Connect to a peer by multiaddr", Body: handlers.ConnectPeerRequest{}},
+	"POST /api/v1/network/sync":              {Summary: "Trigger a sync with peers"},
+	"GET /api/v1/network/sync/status":        {Summary: "Sync service status and each peer's last sync"},
+	"GET /api/v1/network/sync/stats":         {Summary: "Articles offered, accepted and rejected and pull durations per peer", Response: p2p.SyncStats{}},
+
+	// Articles
+	"GET /api/v1/articles":                   {Summary: "List articles", Params: params(pageParams, filterParams), Response: domain.Article{}, Paginated: true},
+	"POST /api/v1/articles":                  {Summary: "Publish an article", Auth: true, Body: domain.ArticleCreateRequest{}, Response: domain.Article{}, Status: http.StatusCreated},
+	"POST /api/v1/articles/batch":            {Summary: "Publish several articles at once", Auth: true, Body: domain.ArticleBatchRequest{}, Response: domain.ArticleBatchResult{}},
+	"GET /api/v1/articles/:cid":              {Summary: "Get an article by CID", Response: domain.Article{}},
+	"PUT /api/v1/articles/:id":               {Summary: "Update an article", Auth: true, Body: domain.ArticleUpdateRequest{}, Response: domain.Article{}},
+	"DELETE /api/v1/articles/:id":            {Summary: "Delete an article", Auth: true},
+	"GET /api/v1/articles/stream":            {Summary: "New articles as server-sent events", Params: []openapi.Param{{Name: "Last-Event-ID", In: "header", Description: "Resume after this event"}, {Name: "last_event_id"}}, ContentType: "text/event-stream"},
+	"POST /api/v1/articles/fetch":            {Summary: "Fetch a missing article from the network", Body: domain.ArticleFetchRequest{}, Response: domain.ArticleFetchResult{}},
+	"POST /api/v1/articles/:cid/verify":      {Summary: "Verify an article's signature"},
+	"GET /api/v1/articles/:cid/verification": {Summary: "Signature, signer and revision chain details", Response: domain.ArticleVerification{}},
+	"GET /api/v1/articles/:cid/export":       {Summary: "Export an article", Params: []openapi.Param{{Name: "format", Required: true, Description: "markdown, html, epub or pdf"}}, ContentType: "application/octet-stream"},
+	"GET /api/v1/articles/:cid/revisions":    {Summary: "An article's revision history", Response: []domain.ArticleRevision{}},
+	"GET /api/v1/articles/:cid/node/*path":   {Summary: "Resolve a path inside an article's IPLD node"},
+	"GET /api/v1/articles/:cid/votes":        {Summary: "Vote tally for an article", Response: domain.VoteTally{}},
+	"POST /api/v1/articles/:cid/vote":        {Summary: "Vote on an article", Auth: true, Body: domain.VoteRequest{}},
+	"POST /api/v1/articles/:cid/report":      {Summary: "Report an article to moderators", Auth: true, Body: domain.ReportCreateRequest{}, Response: domain.Report{}, Status: http.StatusCreated},
+	"POST /api/v1/articles/:cid/appeal":      {Summary: "Appeal the moderation of your article", Auth: true, Body: domain.AppealCreateRequest{}, Response: domain.Appeal{}, Status: http.StatusCreated},
+	"GET /api/v1/articles/:cid/comments":     {Summary: "Comments on an article; signed-in readers don't see identities they block", Params: params(pageParams, []openapi.Param{{Name: "blocked", Description: "include shows blocked identities' comments"}}), Response: domain.Comment{}, Paginated: true},
+	"POST /api/v1/articles/:cid/comments":    {Summary: "Comment on an article", Auth: true, Body: domain.CommentCreateRequest{}, Response: domain.Comment{}, Status: http.StatusCreated},
+
+	// Circles
+	"GET /api/v1/circles":                          {Summary: "Circles you are a member of", Auth: true, Response: []domain.Circle{}},
+	"POST /api/v1/circles":                         {Summary: "Create a circle; articles published to it are encrypted for its members", Auth: true, Body: domain.CircleCreateRequest{}, Response: domain.Circle{}, Status: http.StatusCreated},
+	"GET /api/v1/circles/key-card":                 {Summary: "Your signed key card, to share with circle owners on other nodes", Auth: true, Response: domain.CircleKeyCard{}},
+	"GET /api/v1/circles/:id":                      {Summary: "Get a circle you are a member of", Auth: true, Response: domain.Circle{}},
+	"GET /api/v1/circles/:id/articles":             {Summary: "Articles published to a circle you are a member of, newest first", Auth: true, Params: pageParams, Response: domain.Article{}, Paginated: true},
+	"POST /api/v1/circles/:id/members":             {Summary: "Add a member to your circle by username or key card, rotating its key", Auth: true, Body: domain.CircleMemberRequest{}, Response: domain.Circle{}},
+	"DELETE /api/v1/circles/:id/members/:username": {Summary: "Remove a member from your circle, rotating its key", Auth: true, Response: domain.Circle{}},
+
+	// Feeds
+	"GET /api/v1/feeds":                {Summary: "List feeds", Response: []domain.Feed{}},
+	"POST /api/v1/feeds":               {Summary: "Create a feed", Auth: true, Body: domain.FeedCreateRequest{}, Response: domain.Feed{}, Status: http.StatusCreated},
+	"GET /api/v1/feeds/resolve":        {Summary: "Resolve a remote feed by IPNS name", Params: []openapi.Param{{Name: "name", Required: true}}, Response: domain.RemoteFeed{}},
+	"POST /api/v1/feeds/seed":          {Summary: "Mirror a remote feed: store and pin every article it lists", Auth: true, Body: domain.FeedSeedRequest{}, Response: domain.FeedSeedReport{}},
+	"GET /api/v1/feeds/:name":          {Summary: "Get a feed", Response: domain.Feed{}},
+	"PUT /api/v1/feeds/:name":          {Summary: "Update a feed", Auth: true, Body: domain.FeedUpdateRequest{}, Response: domain.Feed{}},
+	"DELETE /api/v1/feeds/:name":       {Summary: "Delete a feed", Auth: true, Params: []openapi.Param{{Name: "keep_key", Type: "boolean", Description: "Keep the feed's IPNS key"}}},
+	"GET /api/v1/feeds/:name/articles": {Summary: "Articles in a feed", Params: pageParams, Response: domain.FeedArticle{}, Paginated: true},
+	"POST /api/v1/feeds/:name/sync":    {Summary: "Sync a feed now", Auth: true},
+
+	// Archives, events and search
+	"GET /api/v1/archive/export": {Summary: "Export articles as a CAR archive", Auth: true, Params: params([]openapi.Param{{Name: "ids", Description: "Comma-separated article IDs"}, {Name: "tags"}}, filterParams), ContentType: "application/vnd.ipld.car"},
+	"GET /api/v1/ws":             {Summary: "WebSocket stream of node events", Auth: true, Params: []openapi.Param{{Name: "types", Description: "Comma-separated event types"}}, Status: http.StatusSwitchingProtocols, ContentType: "application/json"},
+	"GET /api/v1/search": {Summary: "Search articles and comments", Params: params(pageParams, filterParams, []openapi.Param{
+		{Name: "q"}, {Name: "tags"},
+		{Name: "fuzzy", Type: "integer", Description: "Edit distance for fuzzy matching"},
+		{Name: "prefix", Type: "boolean"},
+		{Name: "sort", Description: "relevance, newest, oldest, most_voted or trust"},
+		{Name: "min_trust", Type: "number", Description: "Hide authors below this trust score"},
+		{Name: "label", Description: "Comma-separated labels every result must carry"},
+		{Name: "exclude_label", Description: "Comma-separated labels to leave out"},
+		{Name: "scope", Description: "local or network"},
+		{Name: "type", Description: "article, comment or all"},
+	})},
+	"GET /api/v1/search/suggest": {Summary: "Autocomplete suggestions", Params: []openapi.Param{{Name: "q", Required: true}, {Name: "limit", Type: "integer"}}, Response: []search.Suggestion{}},
+
+	// Reputation and comments
+	"GET /api/v1/reputation/top":               {Summary: "Highest-reputation users", Params: []openapi.Param{{Name: "limit", Type: "integer"}}, Response: []p2p.ReputationScore{}},
+	"GET /api/v1/reputation/:did":              {Summary: "Reputation of a DID", Response: p2p.ReputationScore{}},
+	"GET /api/v1/reputation/peers":             {Summary: "Reputation exchange with each trusted peer", Response: []p2p.ReputationPeerStatus{}},
+	"GET /api/v1/reputation/:did/endorsements": {Summary: "Endorsements of a DID in effect", Response: []domain.Endorsement{}},
+	"POST /api/v1/reputation/:did/endorse":     {Summary: "Endorse a DID; requires an established reputation", Auth: true, Body: domain.EndorsementRequest{}, Response: domain.Endorsement{}},
+	"DELETE /api/v1/reputation/:did/endorse":   {Summary: "Revoke your endorsement of a DID", Auth: true, Response: domain.Endorsement{}},
+	"GET /api/v1/comments/:id":                 {Summary: "Get a comment", Response: domain.Comment{}},
+	"PUT /api/v1/comments/:id":                 {Summary: "Edit a comment", Auth: true, Body: domain.CommentUpdateRequest{}, Response: domain.Comment{}},
+	"DELETE /api/v1/comments/:id":              {Summary: "Delete a comment; moderators may delete any", Auth: true},
+
+	// Trust graph
+	"GET /api/v1/trust/edges":         {Summary: "Your trust marks, or with direction=in the users who trust you", Auth: true, Params: []openapi.Param{{Name: "direction", Description: "out (default) or in"}}, Response: []domain.TrustEdge{}},
+	"PUT /api/v1/trust/edges/:did":    {Summary: "Mark a DID as trusted or blocked", Auth: true, Body: domain.TrustEdgeRequest{}, Response: domain.TrustEdge{}},
+	"DELETE /api/v1/trust/edges/:did": {Summary: "Remove your mark on a DID", Auth: true},
+	"GET /api/v1/trust/network":       {Summary: "Your transitive trust in every identity within reach", Auth: true, Response: []domain.TrustScore{}},
+	"GET /api/v1/trust/network/:did":  {Summary: "Your transitive trust in a DID", Auth: true, Response: domain.TrustScore{}},
+	"GET /api/v1/trust/feed":          {Summary: "Articles by the identities you trust, newest first", Auth: true, Params: pageParams, Response: domain.TrustFeedItem{}, Paginated: true},
+
+	// Moderation
+	"GET /api/v1/moderation/reports":              {Summary: "Moderation queue", Auth: true, Params: params(pageParams, []openapi.Param{{Name: "status"}}), Response: domain.Report{}, Paginated: true},
+	"GET /api/v1/moderation/reports/:id":          {Summary: "Get a report", Auth: true, Response: domain.Report{}},
+	"POST /api/v1/moderation/reports/:id/resolve": {Summary: "Resolve a report", Auth: true, Body: domain.ReportDecisionRequest{}, Response: domain.Report{}},
+	"POST /api/v1/moderation/reports/:id/dismiss": {Summary: "Dismiss a report", Auth: true, Body: domain.ReportDecisionRequest{}, Response: domain.Report{}},
+	"GET /api/v1/moderation/cases":                {Summary: "Moderation cases, one per reported article", Auth: true, Params: params(pageParams, []openapi.Param{{Name: "status", Description: "open, reviewing, actioned, dismissed, reversed or all; defaults to open and reviewing"}, {Name: "assignee"}}), Response: domain.ModerationCase{}, Paginated: true},
+	"GET /api/v1/moderation/cases/:id":            {Summary: "Get a moderation case", Auth: true, Response: domain.ModerationCase{}},
+	"POST /api/v1/moderation/cases/:id/assign":    {Summary: "Assign a case to a moderator, by default the caller", Auth: true, Body: domain.CaseAssignRequest{}, Response: domain.ModerationCase{}},
+	"POST /api/v1/moderation/cases/:id/action":    {Summary: "Hide, unlist or unpin the article and close the case", Auth: true, Body: domain.CaseDecisionRequest{}, Response: domain.ModerationCase{}},
+	"POST /api/v1/moderation/cases/:id/dismiss":   {Summary: "Close a case without action", Auth: true, Body: domain.CaseDecisionRequest{}, Response: domain.ModerationCase{}},
+
+	// Appeals
+	"POST /api/v1/appeals":                        {Summary: "Submit an appeal signed by the article's author", Body: domain.SignedAppealRequest{}, Response: domain.Appeal{}, Status: http.StatusCreated},
+	"GET /api/v1/moderation/appeals":              {Summary: "Appeal queue", Auth: true, Params: params(pageParams, []openapi.Param{{Name: "status", Description: "open, upheld, reversed or all; defaults to open"}}), Response: domain.Appeal{}, Paginated: true},
+	"GET /api/v1/moderation/appeals/:id":          {Summary: "Get an appeal", Auth: true, Response: domain.Appeal{}},
+	"POST /api/v1/moderation/appeals/:id/uphold":  {Summary: "Let the moderation decision stand", Auth: true, Body: domain.AppealDecisionRequest{}, Response: domain.Appeal{}},
+	"POST /api/v1/moderation/appeals/:id/reverse": {Summary: "Undo the moderation decision, restoring the article and the author's reputation", Auth: true, Body: domain.AppealDecisionRequest{}, Response: domain.Appeal{}},
+
+	// Admin
+	"POST /api/v1/admin/reindex":              {Summary: "Start rebuilding the search index in the background", Auth: true, Response: service.ReindexReport{}, Status: http.StatusAccepted},
+	"GET /api/v1/admin/reindex":               {Summary: "Running or most recent reindex", Auth: true, Response: service.ReindexReport{}},
+	"GET /api/v1/admin/ipfs/metrics":          {Summary: "IPFS operation latency and errors", Auth: true},
+	"GET /api/v1/admin/debug/pprof/*profile":  {Summary: "Go runtime profile by name (goroutine, heap, profile, trace...); blank for the index", Auth: true, Params: []openapi.Param{{Name: "debug", Type: "integer", Description: "1 or 2 for text output"}, {Name: "seconds", Type: "integer", Description: "Duration of CPU profiles and traces"}}},
+	"POST /api/v1/admin/debug/pprof/*profile": {Summary: "Look up symbols for program counters (profile=symbol)", Auth: true},
+	"POST /api/v1/admin/verify":               {Summary: "Run the data integrity check", Auth: true, Params: []openapi.Param{{Name: "repair", Type: "boolean"}}, Response: domain.IntegrityReport{}},
+	"GET /api/v1/admin/verify":                {Summary: "Last integrity report", Auth: true, Response: domain.IntegrityReport{}},
+	"GET /api/v1/admin/search/stats":          {Summary: "Search index statistics", Auth: true},
+	"POST /api/v1/admin/search/optimize":      {Summary: "Compact the search index", Auth: true, Response: search.OptimizeReport{}},
+	"POST /api/v1/admin/archive/import":       {Summary: "Import a CAR archive", Auth: true, File: "archive", Response: domain.ArchiveImportReport{}},
+	"GET /api/v1/admin/pins":                  {Summary: "Pin ledger", Auth: true, Params: []openapi.Param{{Name: "status", Description: "pending, pinned or failed"}}},
+	"POST /api/v1/admin/pins/reconcile":       {Summary: "Reconcile the pin ledger with IPFS", Auth: true, Response: domain.PinReconcileReport{}},
+	"POST /api/v1/admin/gc":                   {Summary: "Run garbage collection", Auth: true, Response: domain.GCReport{}},
+	"GET /api/v1/admin/gc":                    {Summary: "Last garbage collection report", Auth: true, Response: domain.GCReport{}},
+	"GET /api/v1/admin/users":                 {Summary: "Every user on the node", Auth: true, Response: []domain.UserResponse{}},
+	"POST /api/v1/admin/users/:id/activate":   {Summary: "Re-enable a user, by ID or username", Auth: true, Response: domain.UserResponse{}},
+	"POST /api/v1/admin/users/:id/deactivate": {Summary: "Stop a user logging in and posting, by ID or username", Auth: true, Response: domain.UserResponse{}},
+	"GET /api/v1/admin/backup":                {Summary: "Stream a backup of the Badger store", Auth: true, Params: []openapi.Param{{Name: "since", Type: "integer", Description: "Only changes after this backup version"}}, ContentType: "application/octet-stream"},
+	"GET /api/v1/admin/audit":                 {Summary: "Audit log of moderation decisions, bans, role and config changes, newest first", Auth: true, Params: params(pageParams, []openapi.Param{{Name: "action"}, {Name: "actor", Description: "Username or user ID"}, {Name: "target"}}), Response: domain.AuditEntry{}, Paginated: true},
+	"GET /api/v1/admin/audit/verify":          {Summary: "Check the audit log's hash chain", Auth: true, Response: domain.AuditVerifyReport{}},
+	"GET /api/v1/admin/log-levels":            {Summary: "Default log level and per-component overrides", Auth: true, Response: logger.LevelSnapshot{}},
+	"PUT /api/v1/admin/log-levels":            {Summary: "Change log levels until the next restart or SIGHUP; an empty component level removes its override", Auth: true, Body: handlers.LogLevelsRequest{}, Response: logger.LevelSnapshot{}},
+	"GET /api/v1/admin/storage":               {Summary: "Disk usage and synced articles against the storage quota", Auth: true, Response: domain.StorageStats{}},
+	"GET /api/v1/admin/reputation/export":     {Summary: "Every reputation score, keyed by DID", Auth: true, Response: map[string]p2p.ReputationScore{}},
+	"POST /api/v1/admin/reputation/import":    {Summary: "Replace every reputation score with an export", Auth: true, Body: map[string]p2p.ReputationScore{}},
+	"POST /api/v1/admin/storage/sweep":        {Summary: "Remove synced articles the storage quota no longer allows", Auth: true, Params: []openapi.Param{{Name: "dry_run", Type: "boolean"}}, Response: domain.RetentionReport{}},
+	"GET /api/v1/admin/filters":               {Summary: "Content filter lists, ordered by name", Auth: true, Response: []domain.FilterList{}},
+	"POST /api/v1/admin/filters/check":        {Summary: "Screen a draft against the filter lists without publishing it", Auth: true, Body: domain.FilterCheckRequest{}, Response: domain.FilterVerdict{}},
+	"GET /api/v1/admin/filters/:name":         {Summary: "Get a content filter list", Auth: true, Response: domain.FilterList{}},
+	"PUT /api/v1/admin/filters/:name":         {Summary: "Create or replace a content filter list", Auth: true, Body: domain.FilterListRequest{}, Response: domain.FilterList{}},
+	"DELETE /api/v1/admin/filters/:name":      {Summary: "Delete a content filter list", Auth: true},
+
+	// Blocklists
+	"GET /api/v1/admin/blocklists":                {Summary: "Blocklist subscriptions, ordered by name", Auth: true, Response: []domain.BlocklistSubscription{}},
+	"GET /api/v1/admin/blocklists/:name":          {Summary: "Get a blocklist subscription and the edition in force", Auth: true, Response: domain.BlocklistSubscription{}},
+	"PUT /api/v1/admin/blocklists/:name":          {Summary: "Subscribe to a curator's blocklist and fetch it", Auth: true, Body: domain.BlocklistRequest{}, Response: domain.BlocklistSubscription{}},
+	"DELETE /api/v1/admin/blocklists/:name":       {Summary: "Unsubscribe from a blocklist", Auth: true},
+	"POST /api/v1/admin/blocklists/:name/refresh": {Summary: "Fetch a blocklist now", Auth: true, Response: domain.BlocklistSubscription{}},
+
+	// Acceptance policy
+	"GET /api/v1/admin/acceptance": {Summary: "Acceptance policy for articles from peers and its refusals since start, by rule", Auth: true, Response: domain.AcceptanceStatus{}},
+
+	// API v2
+	"GET /api/v2/articles":      {Summary: "List articles", Params: params([]openapi.Param{{Name: "cursor"}, {Name: "limit", Type: "integer"}}, filterParams), Response: domain.Article{}, Paginated: true},
+	"GET /api/v2/articles/:cid": {Summary: "Get an article by CID", Response: domain.Article{}},
+	"POST /api/v2/articles":     {Summary: "Publish an article", Auth: true, Body: domain.ArticleCreateRequest{}, Response: domain.Article{}, Status: http.StatusCreated},
+	"GET /api/v2/search": {Summary: "Search articles", Params: params([]openapi.Param{
+		{Name: "q"}, {Name: "cursor"}, {Name: "limit", Type: "integer"}, {Name: "tags"},
+		{Name: "sort", Description: "relevance, newest, oldest, most_voted or trust"},
+		{Name: "scope", Description: "local or network"},
+	}, filterParams), Response: domain.Article{}, Paginated: true},
+}
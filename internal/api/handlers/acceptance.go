@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// Acceptance returns the acceptance policy in force for articles from
+// peers and how many it refused since the node started, by rule
+func (h *AdminHandler) Acceptance(c *gin.Context) {
+	if h.acceptance == nil {
+		response.Error(c, http.StatusServiceUnavailable, "The acceptance policy is not enabled")
+		return
+	}
+
+	response.Success(c, h.acceptance.Status())
+}
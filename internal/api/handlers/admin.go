@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// Backupper streams a backup of the node's store. Only keys written after
+// since are included; the returned version is the since for the next
+// incremental backup.
+type Backupper interface {
+	Backup(w io.Writer, since uint64) (uint64, error)
+}
+
+// AdminHandler handles node maintenance requests that have no other home:
+// user accounts, store backups, the audit log, content filter lists,
+// blocklist subscriptions and the acceptance policy
+type AdminHandler struct {
+	userService *service.UserService
+	store       Backupper
+	audit       *service.AuditService     // optional; set with SetAuditLog
+	retention   *service.RetentionService // optional; set with SetRetention
+	filters     *service.FilterService    // optional; set with SetFilters
+	blocklists  *service.BlocklistService // optional; set with SetBlocklists
+	acceptance  *service.PolicyEngine     // optional; set with SetAcceptance
+	logger      *logger.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(userService *service.UserService, store Backupper, logger *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		userService: userService,
+		store:       store,
+		logger:      logger.WithComponent("admin-handler"),
+	}
+}
+
+// SetAuditLog serves the audit log to admins
+func (h *AdminHandler) SetAuditLog(audit *service.AuditService) {
+	h.audit = audit
+}
+
+// SetRetention serves storage usage and runs retention sweeps for admins
+func (h *AdminHandler) SetRetention(retention *service.RetentionService) {
+	h.retention = retention
+}
+
+// SetFilters lets admins manage the node's content filter lists
+func (h *AdminHandler) SetFilters(filters *service.FilterService) {
+	h.filters = filters
+}
+
+// SetBlocklists lets admins manage the node's blocklist subscriptions
+func (h *AdminHandler) SetBlocklists(blocklists *service.BlocklistService) {
+	h.blocklists = blocklists
+}
+
+// SetAcceptance shows admins the acceptance policy and what it refused
+func (h *AdminHandler) SetAcceptance(acceptance *service.PolicyEngine) {
+	h.acceptance = acceptance
+}
+
+// ListUsers returns every user on the node
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	users, err := h.userService.ListUsers(c.Request.Context())
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list users", "error", err)
+		response.InternalServerError(c, "Failed to list users")
+		return
+	}
+
+	response.Success(c, users)
+}
+
+// ActivateUser re-enables a deactivated user; :id also accepts a username
+func (h *AdminHandler) ActivateUser(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+// DeactivateUser stops a user from logging in and posting; :id also
+// accepts a username
+func (h *AdminHandler) DeactivateUser(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+func (h *AdminHandler) setActive(c *gin.Context, active bool) {
+	user, err := h.userService.SetActive(c.Request.Context(), c.Param("id"), active)
+	if err != nil {
+		if err == domain.ErrUserNotFound {
+			response.NotFound(c, "User not found")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to update user", "ref", c.Param("id"), "error", err)
+		response.InternalServerError(c, "Failed to update user")
+		return
+	}
+
+	response.Success(c, user)
+}
+
+// Backup streams a backup of the Badger store. ?since=<version> limits it
+// to changes after an earlier backup, whose version is returned in the
+// X-Backup-Version trailer. The search index is not included; rebuild it
+// with a reindex after restoring.
+func (h *AdminHandler) Backup(c *gin.Context) {
+	var since uint64
+	if raw := c.Query("since"); raw != "" {
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "since must be a backup version")
+			return
+		}
+		since = v
+	}
+
+	// Large stores take longer than the server's write timeout
+	extendDeadlines(c)
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="newsp2p-%s.badger"`, time.Now().UTC().Format("20060102-150405")))
+	c.Header("Trailer", "X-Backup-Version")
+	c.Status(http.StatusOK)
+
+	// Headers are sent by now, so a failure can only be logged
+	version, err := h.store.Backup(c.Writer, since)
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to stream backup", "since", since, "error", err)
+		return
+	}
+	c.Writer.Header().Set("X-Backup-Version", strconv.FormatUint(version, 10))
+}
+
+// ListAudit returns audit entries newest first, filtered by ?action=,
+// ?actor= (username or user ID) and ?target=
+func (h *AdminHandler) ListAudit(c *gin.Context) {
+	if h.audit == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Audit log not available")
+		return
+	}
+
+	parser := NewQueryParamParser(c)
+	pagination := parser.Pagination(50)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	entries, total, err := h.audit.List(c.Request.Context(), &domain.AuditListFilter{
+		Action: c.Query("action"),
+		Actor:  c.Query("actor"),
+		Target: c.Query("target"),
+		Page:   pagination.Page,
+		Limit:  pagination.Limit,
+	})
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list audit entries", "error", err)
+		response.InternalServerError(c, "Failed to read audit log")
+		return
+	}
+
+	response.Paginated(c, entries, pagination.Page, pagination.Limit, total)
+}
+
+// VerifyAudit walks the audit log's hash chain and reports the first
+// entry that was altered, removed or inserted
+func (h *AdminHandler) VerifyAudit(c *gin.Context) {
+	if h.audit == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Audit log not available")
+		return
+	}
+
+	report, err := h.audit.Verify(c.Request.Context())
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to verify audit log", "error", err)
+		response.InternalServerError(c, "Failed to verify audit log")
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// LogLevelsRequest changes log levels at runtime. Level sets the default;
+// each entry in Components overrides one component, and an empty value
+// removes the override. Omitted settings are left as they are.
+type LogLevelsRequest struct {
+	Level      string            `json:"level,omitempty"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// LogLevels returns the default log level, per-component overrides and
+// the components that have logged so far
+func (h *AdminHandler) LogLevels(c *gin.Context) {
+	levels := h.logger.Levels()
+	if levels == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Log levels cannot be changed at runtime")
+		return
+	}
+	response.Success(c, levels.Snapshot())
+}
+
+// SetLogLevels applies a LogLevelsRequest until the next restart or
+// SIGHUP, which go back to the config file
+func (h *AdminHandler) SetLogLevels(c *gin.Context) {
+	levels := h.logger.Levels()
+	if levels == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Log levels cannot be changed at runtime")
+		return
+	}
+
+	var req LogLevelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	before := levels.Snapshot()
+	level := before.Level
+	if req.Level != "" {
+		level = req.Level
+	}
+	components := maps.Clone(before.Components)
+	for component, value := range req.Components {
+		if value == "" {
+			delete(components, component)
+		} else {
+			components[component] = value
+		}
+	}
+	if err := levels.Replace(level, components); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	after := levels.Snapshot()
+	h.logger.Ctx(c.Request.Context()).Info("Log levels changed", "level", after.Level, "components", after.Components)
+	if h.audit != nil {
+		h.recordLevelChange(c, "logging.level", before.Level, after.Level)
+		for component := range req.Components {
+			h.recordLevelChange(c, "logging.components."+component, before.Components[component], after.Components[component])
+		}
+	}
+
+	response.Success(c, after)
+}
+
+func (h *AdminHandler) recordLevelChange(c *gin.Context, setting, was, now string) {
+	if was == now {
+		return
+	}
+	details := map[string]string{"old": was, "new": now, "runtime": "true"}
+	if err := h.audit.Record(c.Request.Context(), domain.AuditConfigChange, setting, details); err != nil {
+		h.logger.Ctx(c.Request.Context()).Warn("Failed to audit log level change", "setting", setting, "error", err)
+	}
+}
+
+// Storage reports disk usage and synced articles against the storage
+// quota, with the most recent retention sweep
+func (h *AdminHandler) Storage(c *gin.Context) {
+	if h.retention == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Storage stats not available")
+		return
+	}
+
+	stats, err := h.retention.Stats(c.Request.Context())
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to read storage stats", "error", err)
+		response.InternalServerError(c, "Failed to read storage stats")
+		return
+	}
+
+	response.Success(c, stats)
+}
+
+// Sweep removes synced articles the storage quota no longer allows.
+// ?dry_run=true only reports what would be removed.
+func (h *AdminHandler) Sweep(c *gin.Context) {
+	if h.retention == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Retention sweeper not available")
+		return
+	}
+
+	parser := NewQueryParamParser(c)
+	dryRun := parser.Bool("dry_run", false)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.retention.Run(c.Request.Context(), dryRun)
+	if err != nil {
+		if err == service.ErrSweepRunning {
+			response.Conflict(c, "Retention sweep already running")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Retention sweep failed", "error", err)
+		response.InternalServerError(c, "Retention sweep failed")
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// Appeal appeals the moderation of one of the caller's articles,
+// identified by ID or CID. The node signs the appeal with their key.
+func (h *ModerationHandler) Appeal(c *gin.Context) {
+	if !h.appealsAvailable(c) {
+		return
+	}
+
+	var req domain.AppealCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: reason is required (at most 2000 characters)")
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	appeal, err := h.appeals.File(c.Request.Context(), c.Param("cid"), userID, &req)
+	if err != nil {
+		h.appealError(c, err)
+		return
+	}
+
+	response.Created(c, appeal)
+}
+
+// SubmitAppeal accepts an appeal the author signed with the key their
+// article is signed with, so authors whose accounts live on other nodes
+// can appeal here
+func (h *ModerationHandler) SubmitAppeal(c *gin.Context) {
+	if !h.appealsAvailable(c) {
+		return
+	}
+
+	var req domain.SignedAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: article_id, author, reason (at most 2000 characters), timestamp and signature are required")
+		return
+	}
+
+	appeal, err := h.appeals.Submit(c.Request.Context(), &req)
+	if err != nil {
+		h.appealError(c, err)
+		return
+	}
+
+	response.Created(c, appeal)
+}
+
+// ListAppeals returns the appeal queue, newest first. ?status= filters by
+// open, upheld or reversed and defaults to open; ?status=all lists every
+// appeal.
+func (h *ModerationHandler) ListAppeals(c *gin.Context) {
+	if !h.appealsAvailable(c) {
+		return
+	}
+
+	parser := NewQueryParamParser(c)
+	pagination := parser.Pagination(20)
+	status := parser.String("status", domain.AppealOpen)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	switch status {
+	case "all":
+		status = ""
+	case domain.AppealOpen, domain.AppealUpheld, domain.AppealReversed:
+	default:
+		response.BadRequest(c, "status must be one of open, upheld, reversed, all")
+		return
+	}
+
+	appeals, total, err := h.appeals.List(c.Request.Context(), &domain.AppealListFilter{
+		Status: status,
+		Page:   pagination.Page,
+		Limit:  pagination.Limit,
+	})
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list appeals", "error", err)
+		response.InternalServerError(c, "Failed to read appeal queue")
+		return
+	}
+
+	response.Paginated(c, appeals, pagination.Page, pagination.Limit, total)
+}
+
+// GetAppeal returns a single appeal
+func (h *ModerationHandler) GetAppeal(c *gin.Context) {
+	if !h.appealsAvailable(c) {
+		return
+	}
+
+	appeal, err := h.appeals.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.appealError(c, err)
+		return
+	}
+
+	response.Success(c, appeal)
+}
+
+// UpholdAppeal closes an appeal leaving the moderation decision in place
+func (h *ModerationHandler) UpholdAppeal(c *gin.Context) {
+	h.decideAppeal(c, h.appeals.Uphold)
+}
+
+// ReverseAppeal closes an appeal by undoing the moderation decision,
+// restoring the article and lifting the author's reputation penalty
+func (h *ModerationHandler) ReverseAppeal(c *gin.Context) {
+	h.decideAppeal(c, h.appeals.Reverse)
+}
+
+// decideAppeal closes an appeal with the given decision. The body is
+// optional.
+func (h *ModerationHandler) decideAppeal(c *gin.Context, decide func(context.Context, string, string, *domain.AppealDecisionRequest) (*domain.Appeal, error)) {
+	if !h.appealsAvailable(c) {
+		return
+	}
+
+	var req domain.AppealDecisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body: note must be at most 1000 characters")
+			return
+		}
+	}
+
+	appeal, err := decide(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		h.appealError(c, err)
+		return
+	}
+
+	response.Success(c, appeal)
+}
+
+// appealError writes the response for a failed appeal request
+func (h *ModerationHandler) appealError(c *gin.Context, err error) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.Is(err, domain.ErrArticleNotFound):
+		response.NotFound(c, "Article not found")
+	case errors.Is(err, domain.ErrAppealNotFound):
+		response.NotFound(c, "Appeal not found")
+	case errors.Is(err, domain.ErrForbidden):
+		response.Forbidden(c, "Only the article's author can appeal")
+	case errors.Is(err, domain.ErrInvalidSignature):
+		response.Unauthorized(c, "Appeal is not signed by the article's author")
+	case errors.Is(err, domain.ErrUserNotActive):
+		response.Forbidden(c, "User account is not active")
+	case errors.Is(err, domain.ErrNotAppealable):
+		response.Conflict(c, "Article has no moderation decision to appeal")
+	case errors.Is(err, domain.ErrAppealExists):
+		response.Conflict(c, "This moderation decision has already been appealed")
+	case errors.Is(err, domain.ErrAppealClosed):
+		response.Conflict(c, "Appeal has already been decided")
+	case errors.Is(err, domain.ErrCaseClosed):
+		response.Conflict(c, "Moderation case can no longer be reversed")
+	case errors.As(err, &validationErr):
+		response.BadRequest(c, validationErr.Message)
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Appeal request failed", "cid", c.Param("cid"), "id", c.Param("id"), "error", err)
+		response.InternalServerError(c, "Failed to read or update appeal")
+	}
+}
+
+func (h *ModerationHandler) appealsAvailable(c *gin.Context) bool {
+	if h.appeals == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Appeals are not enabled")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// contentTypeCAR is the media type for CARv1 archives
+const contentTypeCAR = "application/vnd.ipld.car"
+
+// maxArchiveUpload bounds the size of an imported CAR
+const maxArchiveUpload = 512 << 20
+
+// ArchiveHandler handles CAR archive export and import requests
+type ArchiveHandler struct {
+	archiveService *service.ArchiveService
+	logger         *logger.Logger
+}
+
+// NewArchiveHandler creates a new archive handler
+func NewArchiveHandler(archiveService *service.ArchiveService, logger *logger.Logger) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+		logger:         logger.WithComponent("archive-handler"),
+	}
+}
+
+// Export streams the selected articles and their attachments as a CARv1 archive.
+// Select with ?ids=a,b or with the author, tags, from and to filters.
+func (h *ArchiveHandler) Export(c *gin.Context) {
+	parser := NewQueryParamParser(c)
+	sel := service.ArchiveSelection{
+		IDs:    parser.Tags("ids"),
+		Author: parser.String("author", ""),
+		Tags:   parser.Tags("tags"),
+	}
+	dateRange := parser.DateRange("from", "to")
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	sel.Since = dateRange.From
+	sel.Until = dateRange.To
+
+	root, manifest, err := h.archiveService.Prepare(c.Request.Context(), sel)
+	if err != nil {
+		switch err {
+		case service.ErrArchiveEmpty:
+			response.NotFound(c, "No articles matched the selection")
+		case service.ErrArchiveTooLarge:
+			response.BadRequest(c, "Selection too large; narrow it with ids or filters")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to prepare archive", "error", err)
+			response.InternalServerError(c, "Failed to prepare archive. Is the IPFS daemon running?")
+		}
+		return
+	}
+
+	c.Header("Content-Type", contentTypeCAR)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="newsp2p-%s.car"`, root))
+	c.Header("X-Archive-Root", root)
+	c.Header("X-Archive-Articles", fmt.Sprint(len(manifest.Articles)))
+	c.Status(http.StatusOK)
+
+	// Headers are sent by now, so a failure can only be logged
+	if err := h.archiveService.WriteCAR(c.Request.Context(), root, c.Writer); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to stream archive", "root", root, "error", err)
+	}
+}
+
+// Import loads a CAR archive sent either as the raw request body or as the
+// "archive" field of a multipart form
+func (h *ArchiveHandler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveUpload)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("archive")
+		if err != nil {
+			response.BadRequest(c, "Archive file is required")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := h.archiveService.Import(c.Request.Context(), body)
+	if err != nil {
+		if err == service.ErrArchiveManifest {
+			response.BadRequest(c, "Archive has no readable manifest")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to import archive", "error", err)
+		response.InternalServerError(c, "Failed to import archive")
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -1,29 +1,82 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/export"
 	"github.com/amiyamandal-dev/newsp2p/internal/service"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 	"github.com/amiyamandal-dev/newsp2p/pkg/response"
 )
 
+// defaultMaxBatchSize caps POST /articles/batch unless configured otherwise
+const defaultMaxBatchSize = 100
+
 // ArticleHandler handles article-related requests
 type ArticleHandler struct {
 	articleService *service.ArticleService
+	trust          service.TrustScorer    // optional; adds author_trust to responses
+	circles        *service.CircleService // optional; serves circles to their members
+	maxBatchSize   int
 	logger         *logger.Logger
 }
 
+// articleResponse is an article with its author's current trust score,
+// which clients use for trust badges
+type articleResponse struct {
+	*domain.Article
+	AuthorTrust *float64 `json:"author_trust,omitempty"`
+}
+
+// fetchResponse is a fetched article and where it was found
+type fetchResponse struct {
+	Article articleResponse `json:"article"`
+	Source  string          `json:"source"`
+	Peer    string          `json:"peer,omitempty"`
+	Cached  bool            `json:"cached"`
+}
+
 // NewArticleHandler creates a new article handler
 func NewArticleHandler(articleService *service.ArticleService, logger *logger.Logger) *ArticleHandler {
 	return &ArticleHandler{
 		articleService: articleService,
+		maxBatchSize:   defaultMaxBatchSize,
 		logger:         logger.WithComponent("article-handler"),
 	}
 }
 
+// SetTrustScorer includes the author's trust score in article responses
+func (h *ArticleHandler) SetTrustScorer(trust service.TrustScorer) {
+	h.trust = trust
+}
+
+// withTrust attaches author trust scores when a scorer is configured
+func (h *ArticleHandler) withTrust(articles ...*domain.Article) []articleResponse {
+	out := make([]articleResponse, len(articles))
+	for i, article := range articles {
+		out[i] = articleResponse{Article: article}
+		if h.trust != nil {
+			score := h.trust.ArticleTrust(article)
+			out[i].AuthorTrust = &score
+		}
+	}
+	return out
+}
+
+// SetMaxBatchSize limits how many articles one batch request may create
+func (h *ArticleHandler) SetMaxBatchSize(n int) {
+	h.maxBatchSize = n
+}
+
 // Create handles article creation
 func (h *ArticleHandler) Create(c *gin.Context) {
 	var req domain.ArticleCreateRequest
@@ -41,14 +94,68 @@ func (h *ArticleHandler) Create(c *gin.Context) {
 
 	article, err := h.articleService.Create(c.Request.Context(), &req, userID, c.ClientIP())
 	if err != nil {
-		h.logger.Error("Failed to create article", "error", err)
-		response.InternalServerError(c, "Failed to create article")
+		var validationErr *domain.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.BadRequest(c, validationErr.Message)
+		case errors.Is(err, domain.ErrNotCircleMember):
+			response.Forbidden(c, "Only the circle's members can publish to it")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to create article", "error", err)
+			response.InternalServerError(c, "Failed to create article")
+		}
 		return
 	}
 
 	response.Created(c, article)
 }
 
+// CreateBatch creates several articles at once and reports each item's
+// outcome, so importers can retry just the items that failed
+func (h *ArticleHandler) CreateBatch(c *gin.Context) {
+	var req domain.ArticleBatchRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: articles must be a non-empty list")
+		return
+	}
+	if len(req.Articles) > h.maxBatchSize {
+		response.Error(c, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("A batch may contain at most %d articles, got %d", h.maxBatchSize, len(req.Articles)))
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	results, err := h.articleService.CreateBatch(c.Request.Context(), req.Articles, userID, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotActive) {
+			response.Forbidden(c, "User account is not active")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to create article batch", "error", err)
+		response.InternalServerError(c, "Failed to create articles")
+		return
+	}
+
+	created := 0
+	for _, result := range results {
+		if result.Article != nil {
+			created++
+		}
+	}
+
+	response.Success(c, gin.H{
+		"created": created,
+		"failed":  len(results) - created,
+		"results": results,
+	})
+}
+
 // GetByCID retrieves an article by CID
 func (h *ArticleHandler) GetByCID(c *gin.Context) {
 	cid := c.Param("cid")
@@ -59,16 +166,108 @@ func (h *ArticleHandler) GetByCID(c *gin.Context) {
 
 	article, err := h.articleService.GetByCID(c.Request.Context(), cid)
 	if err != nil {
-		if err == domain.ErrArticleNotFound {
+		switch err {
+		case domain.ErrArticleNotFound:
 			response.NotFound(c, "Article not found")
 			return
+		case domain.ErrCIDMismatch:
+			response.Error(c, http.StatusBadGateway, "Content retrieved from IPFS does not match its CID")
+			return
 		}
-		h.logger.Error("Failed to get article", "cid", cid, "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get article", "cid", cid, "error", err)
 		response.InternalServerError(c, "Failed to retrieve article")
 		return
 	}
 
-	response.Success(c, article)
+	resp := h.withTrust(article)[0]
+	c.Header("ETag", articleETag(article, resp.AuthorTrust))
+	response.Success(c, resp)
+}
+
+// articleETag is a weak validator for an article as this node serves it.
+// The CID only covers the signed content; moderation, filter labels,
+// classifications and the author's trust score are added locally and can
+// change at any time, so a digest of them is part of the tag.
+func articleETag(article *domain.Article, trust *float64) string {
+	local := sha256.New()
+	fmt.Fprintf(local, "%s\n%s\n", article.Visibility, strings.Join(article.Labels, ","))
+	json.NewEncoder(local).Encode(article.Classifications)
+	if trust != nil {
+		fmt.Fprintf(local, "%.2f", *trust)
+	}
+	return fmt.Sprintf(`W/"%s-%x"`, article.CID, local.Sum(nil)[:6])
+}
+
+// Fetch retrieves an article this node may not have yet, from IPFS or
+// from peers, storing it locally once its signature checks out
+func (h *ArticleHandler) Fetch(c *gin.Context) {
+	var req domain.ArticleFetchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: cid is required")
+		return
+	}
+
+	result, err := h.articleService.Fetch(c.Request.Context(), req.CID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrArticleNotFound):
+			response.NotFound(c, "Article not found locally, on IPFS or on any peer")
+		case errors.Is(err, domain.ErrArticleDeleted):
+			response.Error(c, http.StatusGone, "Article was deleted by its author")
+		case errors.Is(err, domain.ErrInvalidSignature):
+			response.Error(c, http.StatusBadGateway, "Only copies with an invalid signature were found")
+		case errors.Is(err, domain.ErrCIDMismatch):
+			response.Error(c, http.StatusBadGateway, "Only copies that do not match the CID were found")
+		case errors.As(err, new(*domain.ValidationError)), errors.Is(err, domain.ErrArticleBlocked), errors.Is(err, domain.ErrArticleNotAccepted):
+			response.Error(c, http.StatusForbidden, "Article refused by this node's policy: "+err.Error())
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to fetch article", "cid", req.CID, "error", err)
+			response.InternalServerError(c, "Failed to fetch article")
+		}
+		return
+	}
+
+	response.Success(c, fetchResponse{
+		Article: h.withTrust(result.Article)[0],
+		Source:  result.Source,
+		Peer:    result.Peer,
+		Cached:  result.Cached,
+	})
+}
+
+// Export downloads an article as markdown, HTML, EPUB or PDF
+func (h *ArticleHandler) Export(c *gin.Context) {
+	format, ok := export.ParseFormat(c.Query("format"))
+	if !ok {
+		response.BadRequest(c, "format must be one of markdown, html, epub, pdf")
+		return
+	}
+
+	cid := c.Param("cid")
+	article, err := h.articleService.GetByCID(c.Request.Context(), cid)
+	if err != nil {
+		switch err {
+		case domain.ErrArticleNotFound:
+			response.NotFound(c, "Article not found")
+			return
+		case domain.ErrCIDMismatch:
+			response.Error(c, http.StatusBadGateway, "Content retrieved from IPFS does not match its CID")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get article for export", "cid", cid, "error", err)
+		response.InternalServerError(c, "Failed to retrieve article")
+		return
+	}
+
+	file, err := export.Render(export.NewDocument(article), format)
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to export article", "cid", cid, "format", format, "error", err)
+		response.InternalServerError(c, "Failed to export article")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
 }
 
 // List retrieves articles with pagination and filtering
@@ -96,12 +295,12 @@ func (h *ArticleHandler) List(c *gin.Context) {
 
 	articles, total, err := h.articleService.List(c.Request.Context(), filter)
 	if err != nil {
-		h.logger.Error("Failed to list articles", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list articles", "error", err)
 		response.InternalServerError(c, "Failed to list articles")
 		return
 	}
 
-	response.Paginated(c, articles, pagination.Page, pagination.Limit, total)
+	response.Paginated(c, h.withTrust(articles...), pagination.Page, pagination.Limit, total)
 }
 
 // Update handles article updates
@@ -134,7 +333,16 @@ func (h *ArticleHandler) Update(c *gin.Context) {
 			response.Forbidden(c, "You can only update your own articles")
 			return
 		}
-		h.logger.Error("Failed to update article", "id", id, "error", err)
+		if errors.Is(err, domain.ErrNotCircleMember) {
+			response.Forbidden(c, "Only the circle's members can publish to it")
+			return
+		}
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to update article", "id", id, "error", err)
 		response.InternalServerError(c, "Failed to update article")
 		return
 	}
@@ -165,7 +373,7 @@ func (h *ArticleHandler) Delete(c *gin.Context) {
 			response.Forbidden(c, "You can only delete your own articles")
 			return
 		}
-		h.logger.Error("Failed to delete article", "id", id, "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to delete article", "id", id, "error", err)
 		response.InternalServerError(c, "Failed to delete article")
 		return
 	}
@@ -187,10 +395,78 @@ func (h *ArticleHandler) VerifySignature(c *gin.Context) {
 			response.NotFound(c, "Article not found")
 			return
 		}
-		h.logger.Error("Failed to verify signature", "cid", cid, "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to verify signature", "cid", cid, "error", err)
 		response.InternalServerError(c, "Failed to verify signature")
 		return
 	}
 
 	response.Success(c, gin.H{"valid": valid})
 }
+
+// Verification reports who signed an article and whether its signature
+// and revision chain hold up
+func (h *ArticleHandler) Verification(c *gin.Context) {
+	cid := c.Param("cid")
+
+	result, err := h.articleService.Verification(c.Request.Context(), cid)
+	if err != nil {
+		if errors.Is(err, domain.ErrArticleNotFound) {
+			response.NotFound(c, "Article not found")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to verify article", "cid", cid, "error", err)
+		response.InternalServerError(c, "Failed to verify article")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Revisions returns the article's verified revision history, newest first
+func (h *ArticleHandler) Revisions(c *gin.Context) {
+	cid := c.Param("cid")
+
+	revisions, err := h.articleService.Revisions(c.Request.Context(), cid)
+	if err != nil {
+		switch err {
+		case domain.ErrArticleNotFound:
+			response.NotFound(c, "Article not found")
+		case service.ErrRevisionsUnavailable:
+			response.NotFound(c, "Article has no revision graph")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to load revisions", "cid", cid, "error", err)
+			response.InternalServerError(c, "Failed to load revisions")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{
+		"revisions": revisions,
+		"count":     len(revisions),
+	})
+}
+
+// NodeField returns one field of the article's latest revision node,
+// e.g. /articles/:cid/node/title
+func (h *ArticleHandler) NodeField(c *gin.Context) {
+	cid := c.Param("cid")
+	path := c.Param("path")
+
+	value, err := h.articleService.NodeField(c.Request.Context(), cid, path)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidNodePath:
+			response.BadRequest(c, "Invalid node path")
+		case domain.ErrArticleNotFound:
+			response.NotFound(c, "Article not found")
+		case service.ErrRevisionsUnavailable:
+			response.NotFound(c, "Article has no revision graph")
+		default:
+			h.logger.Ctx(c.Request.Context()).Warn("Failed to resolve node path", "cid", cid, "path", path, "error", err)
+			response.NotFound(c, "Node path not found")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"path": path, "value": value})
+}
@@ -39,7 +39,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			response.Conflict(c, "Username or email already exists")
 			return
 		}
-		h.logger.Error("Registration failed", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Registration failed", "error", err)
 		response.InternalServerError(c, "Failed to register user")
 		return
 	}
@@ -66,7 +66,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			response.Forbidden(c, "User account is not active")
 			return
 		}
-		h.logger.Error("Login failed", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Login failed", "error", err)
 		response.InternalServerError(c, "Failed to login")
 		return
 	}
@@ -108,7 +108,7 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 			response.NotFound(c, "User not found")
 			return
 		}
-		h.logger.Error("Failed to get user", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get user", "error", err)
 		response.InternalServerError(c, "Failed to get user")
 		return
 	}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// blocklistsAvailable writes a 503 and returns false unless blocklist
+// subscriptions are enabled
+func (h *AdminHandler) blocklistsAvailable(c *gin.Context) bool {
+	if h.blocklists == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Blocklists not available")
+		return false
+	}
+	return true
+}
+
+// ListBlocklists returns every blocklist subscription, ordered by name
+func (h *AdminHandler) ListBlocklists(c *gin.Context) {
+	if !h.blocklistsAvailable(c) {
+		return
+	}
+
+	subs, err := h.blocklists.List(c.Request.Context())
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list blocklists", "error", err)
+		response.InternalServerError(c, "Failed to list blocklists")
+		return
+	}
+	if subs == nil {
+		subs = []*domain.BlocklistSubscription{}
+	}
+
+	response.Success(c, subs)
+}
+
+// GetBlocklist returns one blocklist subscription with the edition in force
+func (h *AdminHandler) GetBlocklist(c *gin.Context) {
+	if !h.blocklistsAvailable(c) {
+		return
+	}
+
+	sub, err := h.blocklists.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.blocklistError(c, err)
+		return
+	}
+
+	response.Success(c, sub)
+}
+
+// PutBlocklist subscribes to a blocklist, or changes a subscription, and
+// fetches it. A failed fetch is reported in last_error and retried later.
+func (h *AdminHandler) PutBlocklist(c *gin.Context) {
+	if !h.blocklistsAvailable(c) {
+		return
+	}
+
+	var req domain.BlocklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: source and curator are required")
+		return
+	}
+
+	sub, err := h.blocklists.Subscribe(c.Request.Context(), c.Param("name"), &req)
+	if err != nil {
+		h.blocklistError(c, err)
+		return
+	}
+
+	response.Success(c, sub)
+}
+
+// DeleteBlocklist unsubscribes from a blocklist. Articles it refused
+// can arrive again; peers it blocked can connect again.
+func (h *AdminHandler) DeleteBlocklist(c *gin.Context) {
+	if !h.blocklistsAvailable(c) {
+		return
+	}
+
+	if err := h.blocklists.Unsubscribe(c.Request.Context(), c.Param("name")); err != nil {
+		h.blocklistError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Blocklist subscription removed"})
+}
+
+// RefreshBlocklist fetches a blocklist now instead of on the next interval
+func (h *AdminHandler) RefreshBlocklist(c *gin.Context) {
+	if !h.blocklistsAvailable(c) {
+		return
+	}
+
+	sub, err := h.blocklists.RefreshOne(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.blocklistError(c, err)
+		return
+	}
+
+	response.Success(c, sub)
+}
+
+// blocklistError writes the response for a failed blocklist request
+func (h *AdminHandler) blocklistError(c *gin.Context, err error) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.Is(err, domain.ErrBlocklistNotFound):
+		response.NotFound(c, "Blocklist subscription not found")
+	case errors.As(err, &validationErr):
+		response.BadRequest(c, validationErr.Message)
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Blocklist request failed", "name", c.Param("name"), "error", err)
+		response.InternalServerError(c, "Failed to update blocklists")
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// SetCircles serves circles, and the articles published to them, to
+// their members
+func (h *ArticleHandler) SetCircles(circles *service.CircleService) {
+	h.circles = circles
+}
+
+// CircleKeyCard returns the caller's signed key card. Users on other
+// nodes share it with a circle's owner to be added.
+func (h *ArticleHandler) CircleKeyCard(c *gin.Context) {
+	if !h.circlesAvailable(c) {
+		return
+	}
+
+	card, err := h.circles.KeyCard(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		h.circleError(c, err)
+		return
+	}
+
+	response.Success(c, card)
+}
+
+// ListCircles returns the circles the caller is a member of
+func (h *ArticleHandler) ListCircles(c *gin.Context) {
+	if !h.circlesAvailable(c) {
+		return
+	}
+
+	circles, err := h.circles.List(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		h.circleError(c, err)
+		return
+	}
+
+	response.Success(c, circles)
+}
+
+// CreateCircle creates a circle owned by the caller
+func (h *ArticleHandler) CreateCircle(c *gin.Context) {
+	if !h.circlesAvailable(c) {
+		return
+	}
+
+	var req domain.CircleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: name is required (at most 100 characters)")
+		return
+	}
+
+	circle, err := h.circles.Create(c.Request.Context(), middleware.GetUserID(c), &req)
+	if err != nil {
+		h.circleError(c, err)
+		return
+	}
+
+	response.Created(c, circle)
+}
+
+// GetCircle returns a circle the caller is a member of
+func (h *ArticleHandler) GetCircle(c *gin.Context) {
+	if !h.circlesAvailable(c) {
+		return
+	}
+
+	circle, err := h.circles.Get(c.Request.Context(), c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		h.circleError(c, err)
+		return
+	}
+
+	response.Success(c, circle)
+}
+
+// AddCircleMember adds a member to the caller's circle, by username for
+// users on this node or by key card, and rotates the circle's key
+func (h *ArticleHandler) AddCircleMember(c *gin.Context) {
+	if !h.circlesAvailable(c) {
+		return
+	}
+
+	var req domain.CircleMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: username or card is required")
+		return
+	}
+
+	circle, err := h.circles.AddMember(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		h.circleError(c, err)
+		return
+	}
+
+	response.Success(c, circle)
+}
+
+// RemoveCircleMember removes a member from the caller's circle and
+// rotates the circle's key
+func (h *ArticleHandler) RemoveCircleMember(c *gin.Context) {
+	if !h.circlesAvailable(c) {
+		return
+	}
+
+	circle, err := h.circles.RemoveMember(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), c.Param("username"))
+	if err != nil {
+		h.circleError(c, err)
+		return
+	}
+
+	response.Success(c, circle)
+}
+
+// CircleArticles lists the articles published to a circle the caller is
+// a member of, newest first
+func (h *ArticleHandler) CircleArticles(c *gin.Context) {
+	if !h.circlesAvailable(c) {
+		return
+	}
+
+	parser := NewQueryParamParser(c)
+	pagination := parser.Pagination(20)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	articles, total, err := h.circles.Articles(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), pagination.Page, pagination.Limit)
+	if err != nil {
+		h.circleError(c, err)
+		return
+	}
+
+	response.Paginated(c, h.withTrust(articles...), pagination.Page, pagination.Limit, total)
+}
+
+// circleError writes the response for a failed circle request
+func (h *ArticleHandler) circleError(c *gin.Context, err error) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.Is(err, domain.ErrCircleNotFound):
+		response.NotFound(c, "Circle not found")
+	case errors.Is(err, domain.ErrNotCircleMember):
+		response.NotFound(c, "User is not a member of the circle")
+	case errors.Is(err, domain.ErrNotCircleOwner):
+		response.Forbidden(c, "Only the circle's owner can change its members")
+	case errors.Is(err, domain.ErrCircleMemberExists):
+		response.Conflict(c, "User is already a member of the circle")
+	case errors.Is(err, domain.ErrInvalidSignature):
+		response.BadRequest(c, "Key card is not signed by the key it names, or names another user's key")
+	case errors.Is(err, domain.ErrUserNotActive):
+		response.Forbidden(c, "User account is not active")
+	case errors.As(err, &validationErr):
+		response.BadRequest(c, validationErr.Message)
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Circle request failed", "id", c.Param("id"), "error", err)
+		response.InternalServerError(c, "Failed to read or update circle")
+	}
+}
+
+func (h *ArticleHandler) circlesAvailable(c *gin.Context) bool {
+	if h.circles == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Circles are not enabled")
+		return false
+	}
+	return true
+}
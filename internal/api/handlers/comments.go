@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// CommentHandler handles article comments
+type CommentHandler struct {
+	commentService *service.CommentService
+	trust          *service.TrustGraphService // optional; set with SetTrustGraph
+	logger         *logger.Logger
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(commentService *service.CommentService, logger *logger.Logger) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		logger:         logger.WithComponent("comment-handler"),
+	}
+}
+
+// SetTrustGraph hides comments by identities a signed-in reader blocks,
+// directly or through the identities they trust
+func (h *CommentHandler) SetTrustGraph(trust *service.TrustGraphService) {
+	h.trust = trust
+}
+
+// Create comments on an article, identified by ID or CID
+func (h *CommentHandler) Create(c *gin.Context) {
+	ref := c.Param("cid")
+
+	var req domain.CommentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: body is required (at most 5000 characters)")
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	comment, err := h.commentService.Create(c.Request.Context(), ref, &req, userID)
+	if err != nil {
+		h.writeError(c, "create", err)
+		return
+	}
+
+	response.Created(c, comment)
+}
+
+// List returns an article's comments, oldest first. Signed-in readers
+// don't see the comments of identities they block unless blocked=include.
+func (h *CommentHandler) List(c *gin.Context) {
+	parser := NewQueryParamParser(c)
+	pagination := parser.Pagination(50)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	comments, total, err := h.commentService.List(c.Request.Context(), c.Param("cid"), pagination.Page, pagination.Limit)
+	if err != nil {
+		h.writeError(c, "list", err)
+		return
+	}
+
+	// Readers see blocked identities' comments only when they ask to
+	if viewer := middleware.GetUsername(c); viewer != "" && h.trust != nil && c.Query("blocked") != "include" {
+		blocked, err := h.trust.Blocked(c.Request.Context(), viewer)
+		if err != nil {
+			h.writeError(c, "list", err)
+			return
+		}
+		comments = slices.DeleteFunc(comments, func(comment *domain.Comment) bool { return blocked[comment.Author] })
+	}
+
+	response.Paginated(c, comments, pagination.Page, pagination.Limit, total)
+}
+
+// Get returns a single comment
+func (h *CommentHandler) Get(c *gin.Context) {
+	comment, err := h.commentService.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, "get", err)
+		return
+	}
+
+	response.Success(c, comment)
+}
+
+// Update edits the user's own comment
+func (h *CommentHandler) Update(c *gin.Context) {
+	var req domain.CommentUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: body is required (at most 5000 characters)")
+		return
+	}
+
+	comment, err := h.commentService.Update(c.Request.Context(), c.Param("id"), &req, middleware.GetUserID(c))
+	if err != nil {
+		h.writeError(c, "update", err)
+		return
+	}
+
+	response.Success(c, comment)
+}
+
+// Delete removes a comment and its replies. Requires IdentifyModerators so
+// moderators can delete comments they didn't write.
+func (h *CommentHandler) Delete(c *gin.Context) {
+	err := h.commentService.Delete(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), middleware.IsModerator(c))
+	if err != nil {
+		h.writeError(c, "delete", err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Comment deleted successfully"})
+}
+
+func (h *CommentHandler) writeError(c *gin.Context, action string, err error) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.Is(err, domain.ErrArticleNotFound):
+		response.NotFound(c, "Article not found")
+	case errors.Is(err, domain.ErrCommentNotFound):
+		response.NotFound(c, "Comment not found")
+	case errors.Is(err, domain.ErrForbidden):
+		response.Forbidden(c, "You can only "+action+" your own comments")
+	case errors.Is(err, domain.ErrUserNotActive):
+		response.Forbidden(c, "User account is not active")
+	case errors.As(err, &validationErr):
+		response.BadRequest(c, validationErr.Message)
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Comment request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action+" comment")
+	}
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+const (
+	// wsWriteWait bounds each write to a WebSocket client
+	wsWriteWait = 10 * time.Second
+	// wsPongWait is how long a client may stay silent before it is dropped
+	wsPongWait = 60 * time.Second
+	// wsPingPeriod must be shorter than wsPongWait
+	wsPingPeriod = wsPongWait * 9 / 10
+
+	// sseKeepAlive is how often an idle SSE stream sends a comment so that
+	// proxies don't close it
+	sseKeepAlive = 30 * time.Second
+)
+
+// EventsHandler streams node events to WebSocket and SSE clients
+type EventsHandler struct {
+	bus      *events.Bus
+	upgrader websocket.Upgrader
+	logger   *logger.Logger
+}
+
+// NewEventsHandler creates a new events handler. Browsers may connect from
+// the node's own origin or any of allowedOrigins.
+func NewEventsHandler(bus *events.Bus, allowedOrigins []string, logger *logger.Logger) *EventsHandler {
+	return &EventsHandler{
+		bus: bus,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			CheckOrigin:     checkOrigin(allowedOrigins),
+		},
+		logger: logger.WithComponent("events-handler"),
+	}
+}
+
+// checkOrigin accepts same-origin requests, clients that send no Origin
+// (i.e. not browsers) and the configured CORS origins
+func checkOrigin(allowedOrigins []string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, allowed := range allowedOrigins {
+			if allowed == "*" || allowed == origin {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Stream upgrades the request to a WebSocket and sends each event as a JSON
+// message. ?types=article.received,vote.tally limits the event types.
+func (h *EventsHandler) Stream(c *gin.Context) {
+	var types []string
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+
+	extendDeadlines(c)
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written the error response
+		h.logger.Ctx(c.Request.Context()).Debug("WebSocket upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := h.bus.Subscribe(types...)
+	defer unsubscribe()
+
+	// Clients only send control frames; reading is still needed to process
+	// pongs and notice when the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				h.logger.Ctx(c.Request.Context()).Debug("WebSocket write failed", "error", err)
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// ArticleStream sends each article created here or received from a peer as
+// a Server-Sent Event whose id is the event ID. A client reconnecting with
+// Last-Event-ID (or ?last_event_id=) first receives the articles it missed,
+// as far back as the node's recent event history goes.
+func (h *EventsHandler) ArticleStream(c *gin.Context) {
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	var afterID uint64
+	if lastID != "" {
+		id, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid Last-Event-ID")
+			return
+		}
+		afterID = id
+	}
+
+	missed, events, unsubscribe := h.bus.SubscribeAfter(afterID, domain.EventArticleCreated, domain.EventArticleReceived)
+	defer unsubscribe()
+
+	extendDeadlines(c)
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	send := func(event domain.Event) {
+		c.Render(-1, sse.Event{
+			Id:    strconv.FormatUint(event.ID, 10),
+			Event: event.Type,
+			Data:  event.Data,
+		})
+	}
+	for _, event := range missed {
+		send(event)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			send(event)
+			return true
+		case <-ticker.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
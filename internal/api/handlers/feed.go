@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
@@ -29,7 +32,7 @@ func NewFeedHandler(feedService *service.FeedService, syncService *service.SyncS
 func (h *FeedHandler) List(c *gin.Context) {
 	feeds, err := h.feedService.List(c.Request.Context())
 	if err != nil {
-		h.logger.Error("Failed to list feeds", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list feeds", "error", err)
 		response.InternalServerError(c, "Failed to list feeds")
 		return
 	}
@@ -37,6 +40,82 @@ func (h *FeedHandler) List(c *gin.Context) {
 	response.Success(c, feeds)
 }
 
+// Create creates a feed and generates the IPNS key it is published under
+func (h *FeedHandler) Create(c *gin.Context) {
+	var req domain.FeedCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: name and sync_interval (minutes) are required")
+		return
+	}
+
+	feed, err := h.feedService.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.writeError(c, "create", req.Name, err)
+		return
+	}
+
+	response.Created(c, feed)
+}
+
+// Update changes a feed's sync interval
+func (h *FeedHandler) Update(c *gin.Context) {
+	name := c.Param("name")
+
+	var req domain.FeedUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	if req.SyncInterval == 0 {
+		response.BadRequest(c, "Nothing to update: set sync_interval")
+		return
+	}
+
+	feed, err := h.feedService.Update(c.Request.Context(), name, &req)
+	if err != nil {
+		h.writeError(c, "update", name, err)
+		return
+	}
+
+	response.Success(c, feed)
+}
+
+// Delete deletes a feed and its IPNS key, unless ?keep_key=true
+func (h *FeedHandler) Delete(c *gin.Context) {
+	name := c.Param("name")
+
+	parser := NewQueryParamParser(c)
+	keepKey := parser.Bool("keep_key", false)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.feedService.Delete(c.Request.Context(), name, keepKey); err != nil {
+		h.writeError(c, "delete", name, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Feed deleted successfully", "key_kept": keepKey})
+}
+
+// writeError maps feed management errors to responses
+func (h *FeedHandler) writeError(c *gin.Context, action, name string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrFeedNotFound):
+		response.NotFound(c, "Feed not found")
+	case errors.Is(err, domain.ErrFeedAlreadyExists):
+		response.Conflict(c, "A feed with this name already exists")
+	case errors.Is(err, domain.ErrInvalidFeed):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, domain.ErrIPNSKeyFailed):
+		response.Error(c, http.StatusBadGateway, "Failed to manage the feed's IPNS key")
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Failed to "+action+" feed", "name", name, "error", err)
+		response.InternalServerError(c, "Failed to "+action+" feed")
+	}
+}
+
 // Get retrieves a feed by name
 func (h *FeedHandler) Get(c *gin.Context) {
 	name := c.Param("name")
@@ -51,7 +130,7 @@ func (h *FeedHandler) Get(c *gin.Context) {
 			response.NotFound(c, "Feed not found")
 			return
 		}
-		h.logger.Error("Failed to get feed", "name", name, "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get feed", "name", name, "error", err)
 		response.InternalServerError(c, "Failed to get feed")
 		return
 	}
@@ -81,7 +160,7 @@ func (h *FeedHandler) GetArticles(c *gin.Context) {
 			response.NotFound(c, "Feed not found")
 			return
 		}
-		h.logger.Error("Failed to get feed articles", "name", name, "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get feed articles", "name", name, "error", err)
 		response.InternalServerError(c, "Failed to get feed articles")
 		return
 	}
@@ -102,10 +181,67 @@ func (h *FeedHandler) TriggerSync(c *gin.Context) {
 			response.NotFound(c, "Feed not found")
 			return
 		}
-		h.logger.Error("Failed to trigger feed sync", "name", name, "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to trigger feed sync", "name", name, "error", err)
 		response.InternalServerError(c, "Failed to trigger feed sync")
 		return
 	}
 
 	response.Success(c, gin.H{"message": "Feed sync triggered successfully"})
 }
+
+// ResolveRemote resolves another node's feed from its IPNS name (?name=)
+// and returns the manifest it currently points at
+func (h *FeedHandler) ResolveRemote(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		response.BadRequest(c, "IPNS name is required")
+		return
+	}
+
+	feed, err := h.feedService.ResolveRemote(c.Request.Context(), name)
+	if err != nil {
+		h.writeResolveError(c, name, err)
+		return
+	}
+
+	response.Success(c, feed)
+}
+
+// Seed mirrors another node's feed: every article its manifest lists is
+// stored on this node and pinned
+func (h *FeedHandler) Seed(c *gin.Context) {
+	var req domain.FeedSeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: name (an IPNS name) is required")
+		return
+	}
+
+	report, err := h.feedService.Seed(c.Request.Context(), req.Name)
+	if err != nil {
+		if errors.Is(err, service.ErrSeedingUnavailable) {
+			response.Error(c, http.StatusServiceUnavailable, "Feed seeding is not available on this node")
+			return
+		}
+		h.writeResolveError(c, req.Name, err)
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// writeResolveError maps a failure to resolve a remote feed to a response
+func (h *FeedHandler) writeResolveError(c *gin.Context, name string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidIPNSName):
+		response.BadRequest(c, "Invalid IPNS name")
+	case errors.Is(err, domain.ErrIPNSResolveFailed):
+		response.NotFound(c, "IPNS name could not be resolved")
+	case errors.Is(err, service.ErrInvalidManifest):
+		response.Error(c, http.StatusUnprocessableEntity, "IPNS name does not point at a feed manifest")
+	case errors.Is(err, domain.ErrCIDMismatch):
+		response.Error(c, http.StatusBadGateway, "Feed manifest retrieved from IPFS does not match its CID")
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Failed to resolve remote feed", "name", name, "error", err)
+		response.Error(c, http.StatusBadGateway, "Failed to fetch feed manifest from IPFS")
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// filtersAvailable writes a 503 and returns false unless filter lists are
+// enabled
+func (h *AdminHandler) filtersAvailable(c *gin.Context) bool {
+	if h.filters == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Content filters not available")
+		return false
+	}
+	return true
+}
+
+// ListFilters returns every content filter list, ordered by name
+func (h *AdminHandler) ListFilters(c *gin.Context) {
+	if !h.filtersAvailable(c) {
+		return
+	}
+
+	lists, err := h.filters.List(c.Request.Context())
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list filter lists", "error", err)
+		response.InternalServerError(c, "Failed to list filter lists")
+		return
+	}
+	if lists == nil {
+		lists = []*domain.FilterList{}
+	}
+
+	response.Success(c, lists)
+}
+
+// GetFilter returns one content filter list
+func (h *AdminHandler) GetFilter(c *gin.Context) {
+	if !h.filtersAvailable(c) {
+		return
+	}
+
+	list, err := h.filters.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.filterError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// PutFilter creates or replaces a content filter list. It applies to the
+// next article screened.
+func (h *AdminHandler) PutFilter(c *gin.Context) {
+	if !h.filtersAvailable(c) {
+		return
+	}
+
+	var req domain.FilterListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: action is required")
+		return
+	}
+
+	list, err := h.filters.Put(c.Request.Context(), c.Param("name"), &req)
+	if err != nil {
+		h.filterError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// DeleteFilter removes a content filter list. Articles it already
+// rejected, quarantined or labelled stay as they are.
+func (h *AdminHandler) DeleteFilter(c *gin.Context) {
+	if !h.filtersAvailable(c) {
+		return
+	}
+
+	if err := h.filters.Delete(c.Request.Context(), c.Param("name")); err != nil {
+		h.filterError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Filter list deleted"})
+}
+
+// CheckFilters screens a draft against every list without publishing it
+func (h *AdminHandler) CheckFilters(c *gin.Context) {
+	if !h.filtersAvailable(c) {
+		return
+	}
+
+	var req domain.FilterCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	response.Success(c, h.filters.Check(&req))
+}
+
+// filterError writes the response for a failed filter list request
+func (h *AdminHandler) filterError(c *gin.Context, err error) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.Is(err, domain.ErrFilterListNotFound):
+		response.NotFound(c, "Filter list not found")
+	case errors.As(err, &validationErr):
+		response.BadRequest(c, validationErr.Message)
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Filter list request failed", "name", c.Param("name"), "error", err)
+		response.InternalServerError(c, "Failed to update filter lists")
+	}
+}
@@ -2,21 +2,57 @@ package handlers
 
 import (
 	"context"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
 	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
 	"github.com/amiyamandal-dev/newsp2p/internal/search"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
 )
 
+// Health states, from best to worst
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+)
+
+const (
+	// healthCheckTimeout bounds the dependency checks behind /health
+	healthCheckTimeout = 3 * time.Second
+	// staleSyncIntervals is how many missed sync intervals make sync stale
+	staleSyncIntervals = 3
+)
+
+// ComponentHealth is the state of one dependency. Only required components
+// can make the node unhealthy; the rest degrade it.
+type ComponentHealth struct {
+	Status   string                 `json:"status"`
+	Required bool                   `json:"required"`
+	Message  string                 `json:"message,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthReport is the body of GET /health
+type HealthReport struct {
+	Status     string                      `json:"status"`
+	Components map[string]*ComponentHealth `json:"components"`
+	CheckedAt  time.Time                   `json:"checked_at"`
+}
+
 // HealthHandler handles health check requests
 type HealthHandler struct {
 	db          *badger.DB
 	ipfsClient  *ipfs.Client
 	searchIndex search.Index
+	node        *p2p.P2PNode     // optional; set with SetNetwork
+	syncService *p2p.SyncService // optional; set with SetNetwork
 	logger      *logger.Logger
 }
 
@@ -30,11 +66,126 @@ func NewHealthHandler(db *badger.DB, ipfsClient *ipfs.Client, searchIndex search
 	}
 }
 
-// Health returns basic health status
+// SetNetwork adds P2P peer count and sync age to the health report
+func (h *HealthHandler) SetNetwork(node *p2p.P2PNode, syncService *p2p.SyncService) {
+	h.node = node
+	h.syncService = syncService
+}
+
+// Health reports each component and an overall state: healthy, degraded
+// (an optional component such as IPFS is down, but cached reads are still
+// served) or unhealthy. Unhealthy answers 503 so orchestrators restart or
+// drain the node; degraded still answers 200.
 func (h *HealthHandler) Health(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"status": "ok",
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	report := h.check(ctx)
+	code := http.StatusOK
+	if report.Status == HealthUnhealthy {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
+}
+
+func (h *HealthHandler) check(ctx context.Context) *HealthReport {
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		components = make(map[string]*ComponentHealth)
+	)
+	run := func(name string, probe func() *ComponentHealth) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := probe()
+			mu.Lock()
+			components[name] = result
+			mu.Unlock()
+		}()
+	}
+
+	run("database", func() *ComponentHealth {
+		if err := h.db.HealthCheck(); err != nil {
+			return &ComponentHealth{Status: HealthUnhealthy, Required: true, Message: err.Error()}
+		}
+		return &ComponentHealth{Status: HealthHealthy, Required: true}
+	})
+
+	run("search", func() *ComponentHealth {
+		count, err := h.searchIndex.Count()
+		if err != nil {
+			return &ComponentHealth{Status: HealthUnhealthy, Required: true, Message: err.Error()}
+		}
+		return &ComponentHealth{Status: HealthHealthy, Required: true, Details: map[string]interface{}{"document_count": count}}
+	})
+
+	run("ipfs", func() *ComponentHealth {
+		if !h.ipfsClient.IsHealthy(ctx) {
+			return &ComponentHealth{Status: HealthDegraded, Message: "IPFS unreachable; serving cached articles only, uploads and IPNS disabled"}
+		}
+		return &ComponentHealth{Status: HealthHealthy}
 	})
+
+	if cluster := h.ipfsClient.Cluster(); cluster != nil {
+		run("ipfs_cluster", func() *ComponentHealth {
+			if _, err := cluster.ID(ctx); err != nil {
+				return &ComponentHealth{Status: HealthDegraded, Message: "IPFS Cluster unreachable; pins are not replicated"}
+			}
+			return &ComponentHealth{Status: HealthHealthy}
+		})
+	}
+
+	if h.node != nil {
+		peers := h.node.GetPeerCount()
+		p2pHealth := &ComponentHealth{Status: HealthHealthy, Details: map[string]interface{}{"peer_count": peers}}
+		if peers == 0 {
+			p2pHealth.Status = HealthDegraded
+			p2pHealth.Message = "No connected peers"
+		}
+		components["p2p"] = p2pHealth
+	}
+
+	if h.syncService != nil {
+		components["sync"] = h.syncHealth(components["p2p"])
+	}
+
+	wg.Wait()
+
+	report := &HealthReport{Status: HealthHealthy, Components: components, CheckedAt: time.Now().UTC()}
+	for _, component := range components {
+		switch {
+		case component.Status == HealthHealthy:
+		case component.Required:
+			report.Status = HealthUnhealthy
+		case report.Status == HealthHealthy:
+			report.Status = HealthDegraded
+		}
+	}
+	return report
+}
+
+// syncHealth reports how long ago articles were last synced with peers.
+// Sync is stale once several intervals pass without one while peers are
+// connected; without peers there is nobody to sync with.
+func (h *HealthHandler) syncHealth(p2pHealth *ComponentHealth) *ComponentHealth {
+	last := h.syncService.GetLastSyncTime()
+	interval := h.syncService.SyncInterval()
+	health := &ComponentHealth{Status: HealthHealthy, Details: map[string]interface{}{"interval_seconds": int(interval.Seconds())}}
+	if last.IsZero() {
+		health.Message = "No sync has completed yet"
+		return health
+	}
+
+	age := time.Since(last)
+	health.Details["last_sync"] = last.UTC()
+	health.Details["age_seconds"] = int(age.Seconds())
+	connected := p2pHealth == nil || p2pHealth.Status == HealthHealthy
+	if connected && age > staleSyncIntervals*interval {
+		health.Status = HealthDegraded
+		health.Message = "Last sync is stale"
+	}
+	return health
 }
 
 // Readiness checks if the service is ready to handle requests
@@ -71,6 +222,18 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 		searchHealthy = err == nil
 	}()
 
+	// Check IPFS Cluster (optional, only when configured)
+	cluster := h.ipfsClient.Cluster()
+	clusterHealthy := false
+	if cluster != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cluster.ID(ctx)
+			clusterHealthy = err == nil
+		}()
+	}
+
 	wg.Wait()
 
 	checks := map[string]interface{}{
@@ -90,6 +253,14 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 		},
 	}
 
+	if cluster != nil {
+		checks["ipfs_cluster"] = map[string]interface{}{
+			"healthy":  clusterHealthy,
+			"required": false,
+			"note":     "Pins are replicated through the cluster",
+		}
+	}
+
 	// Overall status - only required services must be healthy
 	ready := dbHealthy && searchHealthy
 
@@ -113,6 +284,14 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 	c.JSON(code, response)
 }
 
+// IPFSMetrics returns latency and error counters for each kind of IPFS
+// operation since startup
+func (h *HealthHandler) IPFSMetrics(c *gin.Context) {
+	response.Success(c, gin.H{
+		"operations": h.ipfsClient.Metrics().Snapshot(),
+	})
+}
+
 // Liveness checks if the service is alive
 func (h *HealthHandler) Liveness(c *gin.Context) {
 	c.JSON(200, gin.H{
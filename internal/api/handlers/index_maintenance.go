@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// IndexMaintenanceHandler handles search index maintenance requests
+type IndexMaintenanceHandler struct {
+	maintenanceService *service.IndexMaintenanceService
+	logger             *logger.Logger
+}
+
+// NewIndexMaintenanceHandler creates a new index maintenance handler
+func NewIndexMaintenanceHandler(maintenanceService *service.IndexMaintenanceService, logger *logger.Logger) *IndexMaintenanceHandler {
+	return &IndexMaintenanceHandler{
+		maintenanceService: maintenanceService,
+		logger:             logger.WithComponent("index-maintenance-handler"),
+	}
+}
+
+// Stats returns index fragmentation and the last optimization report
+func (h *IndexMaintenanceHandler) Stats(c *gin.Context) {
+	stats, err := h.maintenanceService.Stats()
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to read index stats", "error", err)
+		response.InternalServerError(c, "Failed to read index stats")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"stats":         stats,
+		"last_optimize": h.maintenanceService.LastReport(),
+	})
+}
+
+// Optimize compacts the search index immediately
+func (h *IndexMaintenanceHandler) Optimize(c *gin.Context) {
+	report, err := h.maintenanceService.Optimize(c.Request.Context())
+	if err != nil {
+		switch err {
+		case service.ErrOptimizeRunning:
+			response.Conflict(c, "Optimization already running")
+		case search.ErrOptimizeUnsupported:
+			response.Error(c, http.StatusNotImplemented, "Search index does not support optimization")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Index optimization failed", "error", err)
+			response.InternalServerError(c, "Index optimization failed")
+		}
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// IntegrityHandler handles data integrity verification requests
+type IntegrityHandler struct {
+	integrityService *service.IntegrityService
+	logger           *logger.Logger
+}
+
+// NewIntegrityHandler creates a new integrity handler
+func NewIntegrityHandler(integrityService *service.IntegrityService, logger *logger.Logger) *IntegrityHandler {
+	return &IntegrityHandler{
+		integrityService: integrityService,
+		logger:           logger.WithComponent("integrity-handler"),
+	}
+}
+
+// Verify runs a verification pass; ?repair=true fixes index inconsistencies
+func (h *IntegrityHandler) Verify(c *gin.Context) {
+	repair := c.Query("repair") == "true"
+
+	report, err := h.integrityService.Verify(c.Request.Context(), repair)
+	if err != nil {
+		if err == service.ErrVerificationRunning {
+			response.Conflict(c, "Verification already running")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Integrity verification failed", "error", err)
+		response.InternalServerError(c, "Integrity verification failed")
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// LastReport returns the most recent verification report
+func (h *IntegrityHandler) LastReport(c *gin.Context) {
+	report := h.integrityService.LastReport()
+	if report == nil {
+		response.NotFound(c, "No verification has been run yet")
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// ModerationHandler handles content reports and the moderation queue
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+	appeals           *service.AppealService // optional; set with SetAppeals
+	logger            *logger.Logger
+}
+
+// NewModerationHandler creates a new moderation handler
+func NewModerationHandler(moderationService *service.ModerationService, logger *logger.Logger) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+		logger:            logger.WithComponent("moderation-handler"),
+	}
+}
+
+// SetAppeals lets authors appeal moderation decisions and moderators
+// decide the appeals
+func (h *ModerationHandler) SetAppeals(appeals *service.AppealService) {
+	h.appeals = appeals
+}
+
+// Report files a report against an article, identified by ID or CID
+func (h *ModerationHandler) Report(c *gin.Context) {
+	ref := c.Param("cid")
+
+	var req domain.ReportCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: reason is required (at most 1000 characters)")
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	report, err := h.moderationService.Report(c.Request.Context(), ref, userID, req.Reason)
+	if err != nil {
+		var validationErr *domain.ValidationError
+		switch {
+		case errors.Is(err, domain.ErrArticleNotFound):
+			response.NotFound(c, "Article not found")
+		case errors.Is(err, domain.ErrAlreadyReported):
+			response.Conflict(c, "You have already reported this article")
+		case errors.As(err, &validationErr):
+			response.BadRequest(c, validationErr.Message)
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to report article", "ref", ref, "error", err)
+			response.InternalServerError(c, "Failed to report article")
+		}
+		return
+	}
+
+	response.Created(c, report)
+}
+
+// List returns the moderation queue, newest first. ?status= filters by
+// open, resolved or dismissed and defaults to open; ?status=all lists
+// every report.
+func (h *ModerationHandler) List(c *gin.Context) {
+	parser := NewQueryParamParser(c)
+	pagination := parser.Pagination(20)
+	status := parser.String("status", domain.ReportOpen)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	switch status {
+	case "all":
+		status = ""
+	case domain.ReportOpen, domain.ReportResolved, domain.ReportDismissed:
+	default:
+		response.BadRequest(c, "status must be one of open, resolved, dismissed, all")
+		return
+	}
+
+	reports, total, err := h.moderationService.List(c.Request.Context(), &domain.ReportListFilter{
+		Status: status,
+		Page:   pagination.Page,
+		Limit:  pagination.Limit,
+	})
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list reports", "error", err)
+		response.InternalServerError(c, "Failed to read moderation queue")
+		return
+	}
+
+	response.Paginated(c, reports, pagination.Page, pagination.Limit, total)
+}
+
+// Get returns a single report
+func (h *ModerationHandler) Get(c *gin.Context) {
+	report, err := h.moderationService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrReportNotFound) {
+			response.NotFound(c, "Report not found")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get report", "id", c.Param("id"), "error", err)
+		response.InternalServerError(c, "Failed to get report")
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// Resolve upholds a report; with "broadcast": true the article is
+// flagged to peers
+func (h *ModerationHandler) Resolve(c *gin.Context) {
+	h.decide(c, h.moderationService.Resolve)
+}
+
+// Dismiss closes a report without action; with "broadcast": true peers
+// are told it was dismissed
+func (h *ModerationHandler) Dismiss(c *gin.Context) {
+	h.decide(c, h.moderationService.Dismiss)
+}
+
+// decide closes a report with the given decision. The body is optional.
+func (h *ModerationHandler) decide(c *gin.Context, decide func(context.Context, string, string, *domain.ReportDecisionRequest) (*domain.Report, error)) {
+	var req domain.ReportDecisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body: note must be at most 1000 characters")
+			return
+		}
+	}
+
+	id := c.Param("id")
+	report, err := decide(c.Request.Context(), id, middleware.GetUserID(c), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrReportNotFound):
+			response.NotFound(c, "Report not found")
+		case errors.Is(err, domain.ErrReportClosed):
+			response.Conflict(c, "Report has already been resolved or dismissed")
+		case errors.Is(err, service.ErrModerationOffline):
+			response.Error(c, http.StatusServiceUnavailable, "P2P is disabled; the decision can't be broadcast")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to close report", "id", id, "error", err)
+			response.InternalServerError(c, "Failed to close report")
+		}
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// ListCases returns moderation cases, newest first. ?status= filters by
+// open, reviewing, actioned, dismissed or reversed and defaults to the
+// undecided ones, open and reviewing; ?status=all lists every case.
+// ?assignee= narrows to one moderator's cases.
+func (h *ModerationHandler) ListCases(c *gin.Context) {
+	parser := NewQueryParamParser(c)
+	pagination := parser.Pagination(20)
+	status := parser.String("status", "")
+	assignee := parser.String("assignee", "")
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	var statuses []string
+	switch status {
+	case "":
+		statuses = []string{domain.CaseOpen, domain.CaseReviewing}
+	case "all":
+	case domain.CaseOpen, domain.CaseReviewing, domain.CaseActioned, domain.CaseDismissed, domain.CaseReversed:
+		statuses = []string{status}
+	default:
+		response.BadRequest(c, "status must be one of open, reviewing, actioned, dismissed, reversed, all")
+		return
+	}
+
+	cases, total, err := h.moderationService.ListCases(c.Request.Context(), &domain.CaseListFilter{
+		Statuses: statuses,
+		Assignee: assignee,
+		Page:     pagination.Page,
+		Limit:    pagination.Limit,
+	})
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list moderation cases", "error", err)
+		response.InternalServerError(c, "Failed to read moderation cases")
+		return
+	}
+
+	response.Paginated(c, cases, pagination.Page, pagination.Limit, total)
+}
+
+// GetCase returns a single moderation case
+func (h *ModerationHandler) GetCase(c *gin.Context) {
+	mc, err := h.moderationService.GetCase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrCaseNotFound) {
+			response.NotFound(c, "Moderation case not found")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get moderation case", "id", c.Param("id"), "error", err)
+		response.InternalServerError(c, "Failed to get moderation case")
+		return
+	}
+
+	response.Success(c, mc)
+}
+
+// AssignCase puts a case under review by a moderator, by default the
+// caller. The body is optional.
+func (h *ModerationHandler) AssignCase(c *gin.Context) {
+	var req domain.CaseAssignRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body")
+			return
+		}
+	}
+	if req.Moderator == "" {
+		req.Moderator = middleware.GetUsername(c)
+	}
+
+	id := c.Param("id")
+	mc, err := h.moderationService.AssignCase(c.Request.Context(), id, req.Moderator)
+	if err != nil {
+		h.caseError(c, id, err)
+		return
+	}
+
+	response.Success(c, mc)
+}
+
+// ActionCase closes a case by hiding, unlisting or unpinning the article;
+// with "broadcast": true the action is published to peers
+func (h *ModerationHandler) ActionCase(c *gin.Context) {
+	h.decideCase(c, h.moderationService.ActionCase)
+}
+
+// DismissCase closes a case without action; with "broadcast": true peers
+// are told it was dismissed
+func (h *ModerationHandler) DismissCase(c *gin.Context) {
+	h.decideCase(c, h.moderationService.DismissCase)
+}
+
+// decideCase closes a case with the given decision. Dismissing takes an
+// optional body.
+func (h *ModerationHandler) decideCase(c *gin.Context, decide func(context.Context, string, string, *domain.CaseDecisionRequest) (*domain.ModerationCase, error)) {
+	var req domain.CaseDecisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body: action must be one of hide, unlist, unpin and note at most 1000 characters")
+			return
+		}
+	}
+
+	id := c.Param("id")
+	mc, err := decide(c.Request.Context(), id, middleware.GetUserID(c), &req)
+	if err != nil {
+		h.caseError(c, id, err)
+		return
+	}
+
+	response.Success(c, mc)
+}
+
+// caseError writes the response for a failed case update
+func (h *ModerationHandler) caseError(c *gin.Context, id string, err error) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.Is(err, domain.ErrCaseNotFound):
+		response.NotFound(c, "Moderation case not found")
+	case errors.Is(err, domain.ErrCaseClosed):
+		response.Conflict(c, "Moderation case has already been decided")
+	case errors.Is(err, service.ErrModerationOffline):
+		response.Error(c, http.StatusServiceUnavailable, "P2P is disabled; the decision can't be broadcast")
+	case errors.As(err, &validationErr):
+		response.BadRequest(c, validationErr.Message)
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Failed to update moderation case", "id", id, "error", err)
+		response.InternalServerError(c, "Failed to update moderation case")
+	}
+}
@@ -1,21 +1,36 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/routing"
 	"github.com/multiformats/go-multiaddr"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 	"github.com/amiyamandal-dev/newsp2p/pkg/response"
 )
 
+const (
+	// dhtQueryTimeout bounds a single DHT lookup
+	dhtQueryTimeout = 30 * time.Second
+	// maxDHTProviders caps how many providers a lookup collects
+	maxDHTProviders = 100
+)
+
 // NetworkHandler handles network-related requests
 type NetworkHandler struct {
 	node        *p2p.P2PNode
 	syncService *p2p.SyncService
+	policy      *service.NetworkPolicyService // optional; set with SetPolicy
 	logger      *logger.Logger
 }
 
@@ -28,6 +43,11 @@ func NewNetworkHandler(node *p2p.P2PNode, syncService *p2p.SyncService, logger *
 	}
 }
 
+// SetPolicy serves the network policy the node follows
+func (h *NetworkHandler) SetPolicy(policy *service.NetworkPolicyService) {
+	h.policy = policy
+}
+
 // GetStats returns network statistics
 func (h *NetworkHandler) GetStats(c *gin.Context) {
 	if h.node == nil {
@@ -95,7 +115,7 @@ func (h *NetworkHandler) GetPeerInfo(c *gin.Context) {
 
 	// Check if connected
 	connectedness := h.node.GetHost().Network().Connectedness(pid)
-	
+
 	// Get addresses
 	addrs := h.node.GetHost().Peerstore().Addrs(pid)
 	addrStrings := make([]string, len(addrs))
@@ -110,6 +130,101 @@ func (h *NetworkHandler) GetPeerInfo(c *gin.Context) {
 	})
 }
 
+// GetTopology returns the peers this node is connected to, with their
+// capabilities and the transport and latency of each link, for drawing
+// the network graph
+func (h *NetworkHandler) GetTopology(c *gin.Context) {
+	if h.node == nil {
+		response.Error(c, http.StatusServiceUnavailable, "P2P node not initialized")
+		return
+	}
+
+	response.Success(c, h.node.Topology())
+}
+
+// FindPeer looks a peer up in the DHT, to debug why it isn't reachable
+func (h *NetworkHandler) FindPeer(c *gin.Context) {
+	if h.node == nil {
+		response.Error(c, http.StatusServiceUnavailable, "P2P node not initialized")
+		return
+	}
+
+	pid, err := peer.Decode(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid peer ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), dhtQueryTimeout)
+	defer cancel()
+
+	found, err := h.node.FindPeer(ctx, pid)
+	if err != nil {
+		h.writeDHTError(c, err, "Peer not found in the DHT")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"peer":               found,
+		"routing_table_size": h.node.RoutingTableSize(),
+	})
+}
+
+// FindProviders lists peers the DHT says provide a CID, to debug why
+// content isn't retrievable
+func (h *NetworkHandler) FindProviders(c *gin.Context) {
+	if h.node == nil {
+		response.Error(c, http.StatusServiceUnavailable, "P2P node not initialized")
+		return
+	}
+
+	contentID, err := cid.Decode(c.Param("cid"))
+	if err != nil {
+		response.BadRequest(c, "Invalid CID")
+		return
+	}
+
+	parser := NewQueryParamParser(c)
+	limit := parser.Int("limit", 20)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if limit < 1 || limit > maxDHTProviders {
+		response.BadRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxDHTProviders))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), dhtQueryTimeout)
+	defer cancel()
+
+	providers, err := h.node.FindProviders(ctx, contentID, limit)
+	if err != nil && len(providers) == 0 {
+		h.writeDHTError(c, err, "")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"cid":                contentID.String(),
+		"providers":          providers,
+		"count":              len(providers),
+		"complete":           err == nil,
+		"routing_table_size": h.node.RoutingTableSize(),
+	})
+}
+
+func (h *NetworkHandler) writeDHTError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, routing.ErrNotFound) && notFound != "":
+		response.NotFound(c, notFound)
+	case errors.Is(err, context.DeadlineExceeded):
+		response.Error(c, http.StatusGatewayTimeout, "DHT query timed out")
+	default:
+		h.logger.Ctx(c.Request.Context()).Warn("DHT query failed", "error", err)
+		response.Error(c, http.StatusBadGateway, fmt.Sprintf("DHT query failed: %v", err))
+	}
+}
+
 // ConnectPeerRequest represents a request to connect to a peer
 type ConnectPeerRequest struct {
 	Address string `json:"address" binding:"required"`
@@ -144,12 +259,12 @@ func (h *NetworkHandler) ConnectPeer(c *gin.Context) {
 
 	// Connect to peer
 	if err := h.node.GetHost().Connect(c.Request.Context(), *peerInfo); err != nil {
-		h.logger.Error("Failed to connect to peer", "peer", peerInfo.ID, "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to connect to peer", "peer", peerInfo.ID, "error", err)
 		response.InternalServerError(c, fmt.Sprintf("Failed to connect: %v", err))
 		return
 	}
 
-	h.logger.Info("Connected to peer manually", "peer", peerInfo.ID)
+	h.logger.Ctx(c.Request.Context()).Info("Connected to peer manually", "peer", peerInfo.ID)
 
 	response.Success(c, gin.H{
 		"message": "Connected successfully",
@@ -184,9 +299,49 @@ func (h *NetworkHandler) GetSyncStatus(c *gin.Context) {
 	}
 
 	lastSync := h.syncService.GetLastSyncTime()
+	interval := h.syncService.SyncInterval()
 
 	response.Success(c, gin.H{
-		"status":    "active",
-		"last_sync": lastSync,
+		"status":           "active",
+		"last_sync":        lastSync,
+		"interval_seconds": interval.Seconds(),
+		// Two missed rounds means the sync loop is stuck
+		"stalled": !lastSync.IsZero() && time.Since(lastSync) > 2*interval,
+		"peers":   h.syncService.PeerSyncs(),
+	})
+}
+
+// GetSyncStats returns per-peer counts of articles offered, accepted and
+// rejected, failed pulls and pull durations since the node started
+func (h *NetworkHandler) GetSyncStats(c *gin.Context) {
+	if h.syncService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Sync service not available")
+		return
+	}
+
+	response.Success(c, h.syncService.SyncStats())
+}
+
+// GetPubsubStats returns message counts for every pubsub topic seen since
+// the node started
+func (h *NetworkHandler) GetPubsubStats(c *gin.Context) {
+	if h.node == nil {
+		response.Error(c, http.StatusServiceUnavailable, "P2P node not initialized")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"topics": h.node.PubsubStats(),
 	})
 }
+
+// GetPolicy returns the network policy in force and the state of the
+// subscription
+func (h *NetworkHandler) GetPolicy(c *gin.Context) {
+	if h.policy == nil {
+		response.Error(c, http.StatusServiceUnavailable, "No network policy configured")
+		return
+	}
+
+	response.Success(c, h.policy.Status())
+}
@@ -46,7 +46,12 @@ func (p *QueryParamParser) Pagination(defaultLimit int) PaginationParams {
 	page := 1
 	limit := defaultLimit
 
-	if pageStr := p.c.Query("page"); pageStr != "" {
+	// Page-numbered responses hand out the neighbouring pages as cursors
+	pageStr := p.c.Query("page")
+	if pageStr == "" {
+		pageStr = p.c.Query("cursor")
+	}
+	if pageStr != "" {
 		parsed, err := strconv.Atoi(pageStr)
 		if err != nil {
 			p.err = fmt.Errorf("invalid 'page' parameter: must be a number")
@@ -149,3 +154,60 @@ func (p *QueryParamParser) String(key, defaultValue string) string {
 	}
 	return strings.TrimSpace(value)
 }
+
+// Int gets an integer parameter with optional default
+func (p *QueryParamParser) Int(key string, defaultValue int) int {
+	if p.err != nil {
+		return defaultValue
+	}
+
+	value := p.c.Query(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		p.err = fmt.Errorf("invalid '%s' parameter: must be a number", key)
+		return defaultValue
+	}
+	return parsed
+}
+
+// Float gets a floating point parameter with optional default
+func (p *QueryParamParser) Float(key string, defaultValue float64) float64 {
+	if p.err != nil {
+		return defaultValue
+	}
+
+	value := p.c.Query(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		p.err = fmt.Errorf("invalid '%s' parameter: must be a number", key)
+		return defaultValue
+	}
+	return parsed
+}
+
+// Bool gets a boolean parameter with optional default
+func (p *QueryParamParser) Bool(key string, defaultValue bool) bool {
+	if p.err != nil {
+		return defaultValue
+	}
+
+	value := p.c.Query(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		p.err = fmt.Errorf("invalid '%s' parameter: must be true or false", key)
+		return defaultValue
+	}
+	return parsed
+}
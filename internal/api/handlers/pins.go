@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// PinLedgerHandler exposes the pin ledger and garbage collection to admins
+type PinLedgerHandler struct {
+	pinLedger *service.PinLedgerService
+	gc        *service.GCService
+	logger    *logger.Logger
+}
+
+// NewPinLedgerHandler creates a new pin ledger handler
+func NewPinLedgerHandler(pinLedger *service.PinLedgerService, gc *service.GCService, logger *logger.Logger) *PinLedgerHandler {
+	return &PinLedgerHandler{
+		pinLedger: pinLedger,
+		gc:        gc,
+		logger:    logger.WithComponent("pin-ledger-handler"),
+	}
+}
+
+// List returns ledger counts and the entries, optionally filtered by ?status=
+func (h *PinLedgerHandler) List(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", domain.PinPending, domain.PinPinned, domain.PinFailed:
+	default:
+		response.BadRequest(c, "status must be one of pending, pinned, failed")
+		return
+	}
+
+	summary, err := h.pinLedger.Summary(c.Request.Context())
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to read pin ledger", "error", err)
+		response.InternalServerError(c, "Failed to read pin ledger")
+		return
+	}
+	pins, err := h.pinLedger.List(c.Request.Context(), status)
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list pins", "error", err)
+		response.InternalServerError(c, "Failed to read pin ledger")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"summary": summary,
+		"pins":    pins,
+	})
+}
+
+// Reconcile checks the ledger against the IPFS pinset immediately
+func (h *PinLedgerHandler) Reconcile(c *gin.Context) {
+	report, err := h.pinLedger.Reconcile(c.Request.Context())
+	if err != nil {
+		if err == service.ErrReconcileRunning {
+			response.Conflict(c, "Reconciliation already running")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Pin reconciliation failed", "error", err)
+		response.InternalServerError(c, "Pin reconciliation failed. Is IPFS running?")
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// CollectGarbage unpins content the retention policy no longer requires.
+// ?dry_run=true only lists candidates; ?repo_gc=true also runs repo GC on
+// the daemon and reports the space reclaimed.
+func (h *PinLedgerHandler) CollectGarbage(c *gin.Context) {
+	parser := NewQueryParamParser(c)
+	dryRun := parser.Bool("dry_run", false)
+	repoGC := parser.Bool("repo_gc", false)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// Repo GC can take longer than the server's write timeout
+	extendDeadlines(c)
+
+	report, err := h.gc.Run(c.Request.Context(), dryRun, repoGC)
+	if err != nil {
+		if err == service.ErrGCRunning {
+			response.Conflict(c, "Garbage collection already running")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Garbage collection failed", "error", err)
+		response.InternalServerError(c, "Garbage collection failed")
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// LastGarbageCollection returns the report of the most recent GC run
+func (h *PinLedgerHandler) LastGarbageCollection(c *gin.Context) {
+	report := h.gc.LastReport()
+	if report == nil {
+		response.NotFound(c, "No garbage collection has run yet")
+		return
+	}
+	response.Success(c, report)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pprof serves the Go runtime profiles of net/http/pprof beneath any
+// prefix. Mount it on a route ending in /*profile; the index page's links
+// are relative, so they work wherever it is mounted.
+func Pprof(c *gin.Context) {
+	switch name := strings.TrimPrefix(c.Param("profile"), "/"); name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// goroutine, heap, allocs, block, mutex, threadcreate
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
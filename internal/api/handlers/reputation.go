@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+const (
+	// maxTopReputation caps GET /reputation/top
+	maxTopReputation = 100
+	// maxReputationImport caps the body of a reputation import
+	maxReputationImport = 64 << 20
+)
+
+// ReputationHandler exposes the reputation system
+type ReputationHandler struct {
+	reputation   *p2p.ReputationSystem
+	sync         *p2p.ReputationSync         // optional; set with SetSync
+	endorsements *service.EndorsementService // optional; set with SetEndorsements
+	trust        *service.TrustGraphService  // optional; set with SetTrustGraph
+	logger       *logger.Logger
+}
+
+// NewReputationHandler creates a new reputation handler. reputation is nil
+// when P2P is disabled.
+func NewReputationHandler(reputation *p2p.ReputationSystem, logger *logger.Logger) *ReputationHandler {
+	return &ReputationHandler{
+		reputation: reputation,
+		logger:     logger.WithComponent("reputation-handler"),
+	}
+}
+
+// SetSync serves the state of the exchange with trusted peers
+func (h *ReputationHandler) SetSync(sync *p2p.ReputationSync) {
+	h.sync = sync
+}
+
+// SetEndorsements serves endorsements and lets users make them
+func (h *ReputationHandler) SetEndorsements(endorsements *service.EndorsementService) {
+	h.endorsements = endorsements
+}
+
+// SetTrustGraph lets users manage and query their trust graph
+func (h *ReputationHandler) SetTrustGraph(trust *service.TrustGraphService) {
+	h.trust = trust
+}
+
+// Get returns the reputation of a DID. Unknown DIDs have the initial score.
+func (h *ReputationHandler) Get(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	response.Success(c, h.reputation.GetScore(c.Param("did")))
+}
+
+// Top returns the highest-reputation users, best first
+func (h *ReputationHandler) Top(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	parser := NewQueryParamParser(c)
+	limit := parser.Int("limit", 10)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if limit < 1 || limit > maxTopReputation {
+		response.BadRequest(c, "limit must be between 1 and 100")
+		return
+	}
+
+	response.Success(c, h.reputation.GetTopUsers(limit))
+}
+
+// Peers returns the state of the reputation exchange with each trusted peer
+func (h *ReputationHandler) Peers(c *gin.Context) {
+	if h.sync == nil {
+		response.Error(c, http.StatusServiceUnavailable, "No trusted reputation peers configured")
+		return
+	}
+
+	response.Success(c, h.sync.Status())
+}
+
+// Endorsements lists the endorsements of a DID in effect
+func (h *ReputationHandler) Endorsements(c *gin.Context) {
+	if !h.endorsementsAvailable(c) {
+		return
+	}
+
+	endorsements, err := h.endorsements.List(c.Request.Context(), c.Param("did"))
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list endorsements", "did", c.Param("did"), "error", err)
+		response.InternalServerError(c, "Failed to list endorsements")
+		return
+	}
+
+	response.Success(c, endorsements)
+}
+
+// Endorse signs and publishes the user's endorsement of a DID, replacing
+// any earlier one of theirs
+func (h *ReputationHandler) Endorse(c *gin.Context) {
+	if !h.endorsementsAvailable(c) {
+		return
+	}
+
+	var req domain.EndorsementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: note must be at most 280 characters")
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	endorsement, err := h.endorsements.Endorse(c.Request.Context(), userID, c.Param("did"), &req)
+	if err != nil {
+		h.endorsementError(c, err)
+		return
+	}
+
+	response.Success(c, endorsement)
+}
+
+// RevokeEndorsement withdraws the user's endorsement of a DID
+func (h *ReputationHandler) RevokeEndorsement(c *gin.Context) {
+	if !h.endorsementsAvailable(c) {
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	endorsement, err := h.endorsements.Revoke(c.Request.Context(), userID, c.Param("did"))
+	if err != nil {
+		h.endorsementError(c, err)
+		return
+	}
+
+	response.Success(c, endorsement)
+}
+
+func (h *ReputationHandler) endorsementError(c *gin.Context, err error) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		response.BadRequest(c, validationErr.Message)
+	case errors.Is(err, domain.ErrEndorsementNotFound):
+		response.NotFound(c, "You haven't endorsed this user")
+	case errors.Is(err, domain.ErrNotEstablished):
+		response.Forbidden(c, "Only users with an established reputation can endorse")
+	case errors.Is(err, domain.ErrUserNotActive):
+		response.Forbidden(c, "User account is not active")
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Failed to record endorsement", "did", c.Param("did"), "error", err)
+		response.InternalServerError(c, "Failed to record endorsement")
+	}
+}
+
+// Export returns every score in the format Import accepts, for backups and
+// moving reputation between nodes
+func (h *ReputationHandler) Export(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	data, err := h.reputation.Export()
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to export reputation", "error", err)
+		response.InternalServerError(c, "Failed to export reputation")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="reputation-%s.json"`, time.Now().UTC().Format("20060102-150405")))
+	c.Data(http.StatusOK, "application/json", data)
+}
+
+// Import replaces every score with an export, from this node or another,
+// and saves it straight away
+func (h *ReputationHandler) Import(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxReputationImport))
+	if err != nil {
+		response.BadRequest(c, "Failed to read reputation export")
+		return
+	}
+	if err := h.reputation.Import(data); err != nil {
+		response.BadRequest(c, "Body is not a reputation export")
+		return
+	}
+	if err := h.reputation.Snapshot(c.Request.Context()); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to save imported reputation", "error", err)
+		response.InternalServerError(c, "Imported reputation could not be saved; it will be retried at the next snapshot")
+		return
+	}
+
+	response.Success(c, gin.H{"imported": h.reputation.Count()})
+}
+
+func (h *ReputationHandler) endorsementsAvailable(c *gin.Context) bool {
+	if h.endorsements == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Endorsements require P2P to be enabled")
+		return false
+	}
+	return true
+}
+
+func (h *ReputationHandler) available(c *gin.Context) bool {
+	if h.reputation == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Reputation requires P2P to be enabled")
+		return false
+	}
+	return true
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
 	"github.com/amiyamandal-dev/newsp2p/internal/search"
 	"github.com/amiyamandal-dev/newsp2p/internal/service"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
@@ -33,12 +37,44 @@ func (h *SearchHandler) Search(c *gin.Context) {
 	tags := parser.Tags("tags")
 	pagination := parser.Pagination(20)
 	dateRange := parser.DateRange("from", "to")
+	fuzziness := parser.Int("fuzzy", 0)
+	prefix := parser.Bool("prefix", false)
+	minTrust := parser.Float("min_trust", 0)
+	labels := parser.Tags("label")
+	excludeLabels := parser.Tags("exclude_label")
 
 	if err := parser.Error(); err != nil {
 		response.BadRequest(c, err.Error())
 		return
 	}
 
+	sortBy, ok := search.ParseSortOrder(c.Query("sort"))
+	if !ok {
+		response.BadRequest(c, "sort must be one of relevance, newest, oldest, most_voted, trust")
+		return
+	}
+
+	if minTrust < 0 || minTrust > 100 {
+		response.BadRequest(c, "min_trust must be between 0 and 100")
+		return
+	}
+
+	scope := parser.String("scope", search.ScopeLocal)
+	if scope != search.ScopeLocal && scope != search.ScopeNetwork {
+		response.BadRequest(c, "scope must be local or network")
+		return
+	}
+
+	docType := parser.String("type", search.DocTypeArticle)
+	if docType != search.DocTypeArticle && docType != search.DocTypeComment && docType != search.DocTypeAll {
+		response.BadRequest(c, "type must be article, comment or all")
+		return
+	}
+	if minTrust > 0 && docType != search.DocTypeArticle {
+		response.BadRequest(c, "min_trust only applies to article searches")
+		return
+	}
+
 	// Build search query
 	query := &search.SearchQuery{
 		Query:    q,
@@ -49,27 +85,111 @@ func (h *SearchHandler) Search(c *gin.Context) {
 		ToDate:   dateRange.To,
 		Page:     pagination.Page,
 		Limit:    pagination.Limit,
+
+		Fuzziness: fuzziness,
+		Prefix:    prefix,
+		SortBy:    sortBy,
+		Scope:     scope,
+		DocType:   docType,
+		MinTrust:  minTrust,
+
+		Labels:        labels,
+		ExcludeLabels: excludeLabels,
 	}
 
 	// Perform search
 	result, err := h.searchService.Search(c.Request.Context(), query)
 	if err != nil {
-		h.logger.Error("Search failed", "query", q, "error", err)
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Search failed", "query", q, "error", err)
 		response.InternalServerError(c, "Search failed")
 		return
 	}
 
+	page := response.NewPagination(result.Page, result.Limit, result.Total)
+	response.SetPageLinks(c, page)
+
+	data := gin.H{
+		"results":       result.Articles,
+		"highlights":    result.Highlights,
+		"sort":          query.SortBy,
+		"pagination":    page,
+		"query_time_ms": result.QueryTime,
+	}
+	if minTrust > 0 {
+		data["filtered_low_trust"] = result.FilteredLowTrust
+	}
+	if docType != search.DocTypeArticle {
+		data["comments"] = result.Comments
+	}
+	if scope == search.ScopeNetwork {
+		data["network"] = gin.H{
+			"peers_responded": result.PeersResponded,
+			"remote_results":  result.RemoteResults,
+		}
+	}
+
 	c.JSON(200, gin.H{
 		"success": true,
-		"data": gin.H{
-			"results": result.Articles,
-			"pagination": gin.H{
-				"page":        result.Page,
-				"limit":       result.Limit,
-				"total":       result.Total,
-				"total_pages": result.TotalPages,
-			},
-			"query_time_ms": result.QueryTime,
-		},
+		"data":    data,
+	})
+}
+
+// Suggest returns autocomplete suggestions for the search box
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	parser := NewQueryParamParser(c)
+
+	q := c.Query("q")
+	limit := parser.Int("limit", 10)
+
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	suggestions, err := h.searchService.Suggest(c.Request.Context(), q, limit)
+	if err != nil {
+		response.InternalServerError(c, "Suggest failed")
+		return
+	}
+
+	response.Success(c, suggestions)
+}
+
+// Reindex starts rebuilding the search index from the article repository
+// in the background (admin only)
+func (h *SearchHandler) Reindex(c *gin.Context) {
+	report, err := h.searchService.StartReindex(c.Request.Context())
+	if err != nil {
+		switch err {
+		case search.ErrRebuildInProgress:
+			response.Conflict(c, "Reindex already running")
+		case service.ErrReindexUnsupported:
+			response.Error(c, http.StatusNotImplemented, "Search index does not support reindexing")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Reindex failed", "error", err)
+			response.InternalServerError(c, "Reindex failed")
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    report,
 	})
 }
+
+// ReindexStatus returns the running or most recent reindex (admin only)
+func (h *SearchHandler) ReindexStatus(c *gin.Context) {
+	report := h.searchService.ReindexStatus()
+	if report == nil {
+		response.NotFound(c, "No reindex has been run yet")
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// TrustEdges returns the caller's marks on other identities, or with
+// direction=in the users who trust the caller
+func (h *ReputationHandler) TrustEdges(c *gin.Context) {
+	if !h.trustAvailable(c) {
+		return
+	}
+
+	viewer := middleware.GetUsername(c)
+	var (
+		edges []*domain.TrustEdge
+		err   error
+	)
+	switch c.DefaultQuery("direction", "out") {
+	case "out":
+		edges, err = h.trust.Edges(c.Request.Context(), viewer)
+	case "in":
+		edges, err = h.trust.Trusters(c.Request.Context(), viewer)
+	default:
+		response.BadRequest(c, "direction must be in or out")
+		return
+	}
+	if err != nil {
+		h.trustError(c, err)
+		return
+	}
+	if edges == nil {
+		edges = []*domain.TrustEdge{}
+	}
+
+	response.Success(c, edges)
+}
+
+// SetTrustEdge marks a DID as trusted or blocked by the caller, replacing
+// any earlier mark of theirs
+func (h *ReputationHandler) SetTrustEdge(c *gin.Context) {
+	if !h.trustAvailable(c) {
+		return
+	}
+
+	var req domain.TrustEdgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: kind must be trusted or blocked, note at most 280 characters")
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	edge, err := h.trust.SetEdge(c.Request.Context(), userID, c.Param("did"), &req)
+	if err != nil {
+		h.trustError(c, err)
+		return
+	}
+
+	response.Success(c, edge)
+}
+
+// RemoveTrustEdge withdraws the caller's mark on a DID
+func (h *ReputationHandler) RemoveTrustEdge(c *gin.Context) {
+	if !h.trustAvailable(c) {
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.trust.RemoveEdge(c.Request.Context(), userID, c.Param("did")); err != nil {
+		h.trustError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Trust edge removed"})
+}
+
+// TrustNetwork returns the caller's trust in every identity within reach
+// of their trust graph
+func (h *ReputationHandler) TrustNetwork(c *gin.Context) {
+	if !h.trustAvailable(c) {
+		return
+	}
+
+	network, err := h.trust.Network(c.Request.Context(), middleware.GetUsername(c))
+	if err != nil {
+		h.trustError(c, err)
+		return
+	}
+
+	response.Success(c, network)
+}
+
+// TrustScore returns the caller's trust in one DID
+func (h *ReputationHandler) TrustScore(c *gin.Context) {
+	if !h.trustAvailable(c) {
+		return
+	}
+
+	score, err := h.trust.Score(c.Request.Context(), middleware.GetUsername(c), c.Param("did"))
+	if err != nil {
+		h.trustError(c, err)
+		return
+	}
+
+	response.Success(c, score)
+}
+
+// TrustFeed lists articles by the identities the caller trusts, directly
+// or through others, leaving out the ones they block
+func (h *ReputationHandler) TrustFeed(c *gin.Context) {
+	if !h.trustAvailable(c) {
+		return
+	}
+
+	parser := NewQueryParamParser(c)
+	pagination := parser.Pagination(20)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	items, total, err := h.trust.Feed(c.Request.Context(), middleware.GetUsername(c), pagination.Page, pagination.Limit)
+	if err != nil {
+		h.trustError(c, err)
+		return
+	}
+
+	response.Paginated(c, items, pagination.Page, pagination.Limit, total)
+}
+
+func (h *ReputationHandler) trustError(c *gin.Context, err error) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		response.BadRequest(c, validationErr.Message)
+	case errors.Is(err, domain.ErrTrustEdgeNotFound):
+		response.NotFound(c, "You haven't marked this user")
+	case errors.Is(err, domain.ErrUserNotActive):
+		response.Forbidden(c, "User account is not active")
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Trust graph request failed", "did", c.Param("did"), "error", err)
+		response.InternalServerError(c, "Failed to read or update the trust graph")
+	}
+}
+
+func (h *ReputationHandler) trustAvailable(c *gin.Context) bool {
+	if h.trust == nil {
+		response.Error(c, http.StatusServiceUnavailable, "The trust graph is not enabled")
+		return false
+	}
+	return true
+}
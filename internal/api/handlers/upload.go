@@ -1,29 +1,46 @@
 package handlers
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
 	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 	"github.com/amiyamandal-dev/newsp2p/pkg/response"
 )
 
+// uploadIDPattern rejects malformed upload IDs before they are looked up;
+// valid ones are issued by ReserveUpload
+var uploadIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
 // UploadHandler handles file uploads
 type UploadHandler struct {
-	ipfsClient *ipfs.Client
-	logger     *logger.Logger
+	ipfsClient    *ipfs.Client
+	uploadService *service.UploadService
+	logger        *logger.Logger
 }
 
 // NewUploadHandler creates a new upload handler
-func NewUploadHandler(ipfsClient *ipfs.Client, logger *logger.Logger) *UploadHandler {
+func NewUploadHandler(ipfsClient *ipfs.Client, uploadService *service.UploadService, logger *logger.Logger) *UploadHandler {
 	return &UploadHandler{
-		ipfsClient: ipfsClient,
-		logger:     logger.WithComponent("upload-handler"),
+		ipfsClient:    ipfsClient,
+		uploadService: uploadService,
+		logger:        logger.WithComponent("upload-handler"),
 	}
 }
 
@@ -36,7 +53,7 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 	}
 
 	if !h.ipfsClient.IsHealthy(c.Request.Context()) {
-		h.logger.Warn("IPFS daemon not available for image upload")
+		h.logger.Ctx(c.Request.Context()).Warn("IPFS daemon not available for image upload")
 		response.InternalServerError(c, "IPFS daemon not running. Please start IPFS with: ipfs daemon")
 		return
 	}
@@ -64,7 +81,7 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 	// Read file content
 	data, err := io.ReadAll(file)
 	if err != nil {
-		h.logger.Error("Failed to read image file", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to read image file", "error", err)
 		response.InternalServerError(c, "Failed to process image")
 		return
 	}
@@ -72,12 +89,12 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 	// Upload to IPFS
 	cid, err := h.ipfsClient.Add(c.Request.Context(), data)
 	if err != nil {
-		h.logger.Error("Failed to upload image to IPFS", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to upload image to IPFS", "error", err)
 		response.InternalServerError(c, "Failed to upload to IPFS. Is the daemon running?")
 		return
 	}
 
-	h.logger.Info("Image uploaded to IPFS", "cid", cid, "size", len(data), "filename", header.Filename)
+	h.logger.Ctx(c.Request.Context()).Info("Image uploaded to IPFS", "cid", cid, "size", len(data), "filename", header.Filename)
 
 	// Return IPFS URL (assuming a public gateway or local gateway for viewing)
 	// For now, we return the CID and a gateway URL structure
@@ -89,3 +106,166 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 		"url": url,
 	})
 }
+
+// ReserveUpload returns a new upload ID for the caller, whose progress can
+// be followed at /upload/media/:id/progress before the file is posted
+func (h *UploadHandler) ReserveUpload(c *gin.Context) {
+	id := h.uploadService.Reserve(middleware.GetUserID(c))
+	response.Created(c, gin.H{"upload_id": id})
+}
+
+// UploadMedia streams a large file (multipart field "file") to IPFS without
+// buffering it. Pass a reserved ?upload_id= and subscribe to
+// /upload/media/:id/progress to follow it; ?size= lets progress report a
+// percentage.
+func (h *UploadHandler) UploadMedia(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	id := c.Query("upload_id")
+	if id == "" {
+		id = uuid.New().String()
+	} else if !uploadIDPattern.MatchString(id) {
+		response.BadRequest(c, "upload_id must be 8-64 letters, digits, '-' or '_'")
+		return
+	}
+
+	parser := NewQueryParamParser(c)
+	total := int64(parser.Int("size", 0))
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	part, err := filePart(c, "file")
+	if err != nil {
+		response.BadRequest(c, "Multipart field \"file\" is required")
+		return
+	}
+	defer part.Close()
+
+	// Large bodies outlive the server-wide timeouts
+	extendDeadlines(c)
+
+	body := bufio.NewReaderSize(part, 512)
+	mimeType := mediaType(body, part.Header.Get("Content-Type"))
+
+	result, err := h.uploadService.Upload(c.Request.Context(), userID, id, body, mimeType, total)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnsupportedMedia):
+			response.Error(c, http.StatusUnsupportedMediaType, fmt.Sprintf("Uploads of type %s are not accepted", mimeType))
+		case errors.Is(err, domain.ErrUploadTooLarge):
+			response.Error(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("File too large for type %s", mimeType))
+		case errors.Is(err, domain.ErrConflict):
+			response.Conflict(c, "Upload ID already in use")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Media upload failed", "upload_id", id, "error", err)
+			response.InternalServerError(c, "Failed to upload to IPFS. Is the daemon running?")
+		}
+		return
+	}
+
+	resp := gin.H{
+		"upload_id": id,
+		"cid":       result.CID,
+		"url":       fmt.Sprintf("https://ipfs.io/ipfs/%s", result.CID),
+		"size":      result.Sent,
+		"mime_type": mimeType,
+	}
+	if result.Media != nil {
+		resp["duration"] = result.Media.Duration
+		resp["state"] = result.Media.State
+	}
+	response.Success(c, resp)
+}
+
+// GetMedia returns what is known about an uploaded audio or video file:
+// its duration, whether transcoding has finished and the renditions it
+// produced
+func (h *UploadHandler) GetMedia(c *gin.Context) {
+	record, err := h.uploadService.Media(c.Request.Context(), c.Param("cid"))
+	if err != nil {
+		if errors.Is(err, domain.ErrMediaNotFound) {
+			response.NotFound(c, "Media not found")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get media", "cid", c.Param("cid"), "error", err)
+		response.InternalServerError(c, "Failed to get media")
+		return
+	}
+	response.Success(c, record)
+}
+
+// UploadProgress streams progress for one of the caller's uploads as
+// server-sent "progress" events until the upload finishes
+func (h *UploadHandler) UploadProgress(c *gin.Context) {
+	id := c.Param("id")
+	if !uploadIDPattern.MatchString(id) {
+		response.BadRequest(c, "Invalid upload ID")
+		return
+	}
+
+	updates, unsubscribe, err := h.uploadService.Subscribe(middleware.GetUserID(c), id)
+	if err != nil {
+		response.NotFound(c, "Upload not found")
+		return
+	}
+	defer unsubscribe()
+
+	extendDeadlines(c)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case progress, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("progress", progress)
+			return !progress.Finished()
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// filePart returns the named file part of a multipart body without reading
+// the parts after it
+func filePart(c *gin.Context, field string) (*multipart.Part, error) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == field && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// mediaType sniffs the content type from the first bytes, falling back to
+// the type the client declared when sniffing is inconclusive
+func mediaType(body *bufio.Reader, declared string) string {
+	head, _ := body.Peek(512)
+	sniffed := http.DetectContentType(head)
+	if sniffed == "application/octet-stream" && declared != "" {
+		sniffed = declared
+	}
+	if parsed, _, err := mime.ParseMediaType(sniffed); err == nil {
+		return parsed
+	}
+	return sniffed
+}
+
+// extendDeadlines lifts the server's read/write timeouts for a long-running request
+func extendDeadlines(c *gin.Context) {
+	rc := http.NewResponseController(c.Writer)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+}
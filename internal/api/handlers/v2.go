@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// Cursor kinds, so a cursor from one collection is rejected by another
+const (
+	cursorArticles = "a"
+	cursorSearch   = "s"
+)
+
+// errInvalidCursor is reported for cursors that were not issued by this API
+var errInvalidCursor = errors.New("invalid cursor")
+
+// V2Handler serves /api/v2: cursor pagination, one response envelope for
+// every success and RFC 7807 problem details for every error
+type V2Handler struct {
+	articleService *service.ArticleService
+	searchService  *service.SearchService
+	logger         *logger.Logger
+}
+
+// NewV2Handler creates a new v2 API handler
+func NewV2Handler(articleService *service.ArticleService, searchService *service.SearchService, logger *logger.Logger) *V2Handler {
+	return &V2Handler{
+		articleService: articleService,
+		searchService:  searchService,
+		logger:         logger.WithComponent("v2-handler"),
+	}
+}
+
+// ListArticles pages through articles newest first. Cursors are keyset
+// positions, so articles published while a client pages are not repeated
+// or skipped.
+func (h *V2Handler) ListArticles(c *gin.Context) {
+	parser := NewQueryParamParser(c)
+	limit := pageLimit(parser.Int("limit", 20))
+	dateRange := parser.DateRange("from", "to")
+	author := parser.String("author", "")
+	category := parser.String("category", "")
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filter := &domain.ArticleListFilter{
+		Author:   author,
+		Category: category,
+		FromDate: dateRange.From,
+		ToDate:   dateRange.To,
+		Page:     1,
+		Limit:    limit,
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		after, err := decodeArticleCursor(raw)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		filter.After = after
+	}
+
+	articles, remaining, err := h.articleService.List(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list articles", "error", err)
+		response.InternalServerError(c, "Failed to list articles")
+		return
+	}
+
+	meta := &response.Meta{Limit: limit, HasMore: remaining > len(articles)}
+	if meta.HasMore {
+		last := articles[len(articles)-1]
+		meta.NextCursor = encodeCursor(cursorArticles, strconv.FormatInt(last.Timestamp.UnixNano(), 10), last.ID)
+	}
+	response.WriteEnvelope(c, http.StatusOK, articles, meta, pageLinks(c, meta.NextCursor))
+}
+
+// GetArticle retrieves an article by CID
+func (h *V2Handler) GetArticle(c *gin.Context) {
+	cid := c.Param("cid")
+	article, err := h.articleService.GetByCID(c.Request.Context(), cid)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrArticleNotFound):
+			response.NotFound(c, "Article not found")
+		case errors.Is(err, domain.ErrCIDMismatch):
+			response.Error(c, http.StatusBadGateway, "Content retrieved from IPFS does not match its CID")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to get article", "cid", cid, "error", err)
+			response.InternalServerError(c, "Failed to retrieve article")
+		}
+		return
+	}
+
+	c.Header("ETag", articleETag(article, nil))
+	response.WriteEnvelope(c, http.StatusOK, article, nil, map[string]string{"self": c.Request.URL.Path})
+}
+
+// CreateArticle publishes an article as the authenticated user
+func (h *V2Handler) CreateArticle(c *gin.Context) {
+	var req domain.ArticleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Well-formed JSON that fails field validation is unprocessable
+		// rather than malformed
+		var invalid validator.ValidationErrors
+		if errors.As(err, &invalid) {
+			response.Error(c, http.StatusUnprocessableEntity, invalid.Error())
+			return
+		}
+		response.BadRequest(c, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	article, err := h.articleService.Create(c.Request.Context(), &req, middleware.GetUserID(c), c.ClientIP())
+	if err != nil {
+		var validation *domain.ValidationError
+		switch {
+		case errors.As(err, &validation):
+			response.Error(c, http.StatusUnprocessableEntity, validation.Error())
+		case errors.Is(err, domain.ErrUserNotActive):
+			response.Forbidden(c, "User account is not active")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to create article", "error", err)
+			response.InternalServerError(c, "Failed to create article")
+		}
+		return
+	}
+
+	self := "/api/v2/articles/" + article.CID
+	c.Header("Location", self)
+	response.WriteEnvelope(c, http.StatusCreated, article, nil, map[string]string{"self": self})
+}
+
+// Search runs a full-text article search. The cursor keeps the page size
+// it was issued with.
+func (h *V2Handler) Search(c *gin.Context) {
+	parser := NewQueryParamParser(c)
+	limit := pageLimit(parser.Int("limit", 20))
+	tags := parser.Tags("tags")
+	dateRange := parser.DateRange("from", "to")
+	scope := parser.String("scope", search.ScopeLocal)
+	if err := parser.Error(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if scope != search.ScopeLocal && scope != search.ScopeNetwork {
+		response.BadRequest(c, "scope must be local or network")
+		return
+	}
+	sortBy, ok := search.ParseSortOrder(c.Query("sort"))
+	if !ok {
+		response.BadRequest(c, "sort must be one of relevance, newest, oldest, most_voted, trust")
+		return
+	}
+
+	page := 1
+	if raw := c.Query("cursor"); raw != "" {
+		var err error
+		if page, limit, err = decodeSearchCursor(raw); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	result, err := h.searchService.Search(c.Request.Context(), &search.SearchQuery{
+		Query:    c.Query("q"),
+		Author:   c.Query("author"),
+		Category: c.Query("category"),
+		Tags:     tags,
+		FromDate: dateRange.From,
+		ToDate:   dateRange.To,
+		Page:     page,
+		Limit:    limit,
+		SortBy:   sortBy,
+		Scope:    scope,
+	})
+	if err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Search failed", "query", c.Query("q"), "error", err)
+		response.InternalServerError(c, "Search failed")
+		return
+	}
+
+	total := result.Total
+	meta := &response.Meta{Limit: limit, Total: &total, HasMore: page < result.TotalPages}
+	if meta.HasMore {
+		meta.NextCursor = encodeCursor(cursorSearch, strconv.Itoa(page+1), strconv.Itoa(limit))
+	}
+	response.WriteEnvelope(c, http.StatusOK, result.Articles, meta, pageLinks(c, meta.NextCursor))
+}
+
+// pageLimit clamps a requested page size to 1-100
+func pageLimit(limit int) int {
+	return min(max(limit, 1), 100)
+}
+
+// pageLinks returns the self link and, when there is a next page, the
+// same request with the next cursor
+func pageLinks(c *gin.Context, nextCursor string) map[string]string {
+	links := map[string]string{"self": c.Request.URL.RequestURI()}
+	if nextCursor != "" {
+		next := *c.Request.URL
+		query := next.Query()
+		query.Set("cursor", nextCursor)
+		next.RawQuery = query.Encode()
+		links["next"] = next.RequestURI()
+	}
+	return links
+}
+
+// encodeCursor packs a cursor kind and its fields into an opaque token
+func encodeCursor(kind string, fields ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(kind + ":" + strings.Join(fields, ":")))
+}
+
+// decodeCursor unpacks a token of the given kind into n fields
+func decodeCursor(raw, kind string, n int) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	parts := strings.SplitN(string(data), ":", n+1)
+	if len(parts) != n+1 || parts[0] != kind {
+		return nil, errInvalidCursor
+	}
+	return parts[1:], nil
+}
+
+func decodeArticleCursor(raw string) (*domain.ArticleCursor, error) {
+	fields, err := decodeCursor(raw, cursorArticles, 2)
+	if err != nil {
+		return nil, err
+	}
+	nanos, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || fields[1] == "" {
+		return nil, errInvalidCursor
+	}
+	return &domain.ArticleCursor{Timestamp: time.Unix(0, nanos), ID: fields[1]}, nil
+}
+
+func decodeSearchCursor(raw string) (page, limit int, err error) {
+	fields, err := decodeCursor(raw, cursorSearch, 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	page, perr := strconv.Atoi(fields[0])
+	limit, lerr := strconv.Atoi(fields[1])
+	if perr != nil || lerr != nil || page < 1 || limit != pageLimit(limit) {
+		return 0, 0, errInvalidCursor
+	}
+	return page, limit, nil
+}
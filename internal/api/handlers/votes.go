@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// VoteHandler handles article votes
+type VoteHandler struct {
+	voteService *service.VoteService
+	logger      *logger.Logger
+}
+
+// NewVoteHandler creates a new vote handler
+func NewVoteHandler(voteService *service.VoteService, logger *logger.Logger) *VoteHandler {
+	return &VoteHandler{
+		voteService: voteService,
+		logger:      logger.WithComponent("vote-handler"),
+	}
+}
+
+// Vote casts the user's +1 or -1 on an article, identified by ID or CID,
+// replacing any earlier vote of theirs
+func (h *VoteHandler) Vote(c *gin.Context) {
+	ref := c.Param("cid")
+
+	var req domain.VoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body: vote must be 1 or -1 and reason at most 280 characters")
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	vote, tally, err := h.voteService.Vote(c.Request.Context(), ref, userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrArticleNotFound):
+			response.NotFound(c, "Article not found")
+		case errors.Is(err, service.ErrSelfVote):
+			response.Forbidden(c, "You can't vote on your own article")
+		case errors.Is(err, domain.ErrUserNotActive):
+			response.Forbidden(c, "User account is not active")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to record vote", "ref", ref, "error", err)
+			response.InternalServerError(c, "Failed to record vote")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{
+		"vote":  vote,
+		"tally": tally,
+	})
+}
+
+// Tally returns an article's current vote tally
+func (h *VoteHandler) Tally(c *gin.Context) {
+	tally, err := h.voteService.Tally(c.Request.Context(), c.Param("cid"))
+	if err != nil {
+		if errors.Is(err, domain.ErrArticleNotFound) {
+			response.NotFound(c, "Article not found")
+			return
+		}
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get vote tally", "ref", c.Param("cid"), "error", err)
+		response.InternalServerError(c, "Failed to get vote tally")
+		return
+	}
+
+	response.Success(c, tally)
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// AdminMiddleware restricts a route group to node operators. It must run
+// after AuthMiddleware; admins are matched by user ID or username.
+func AdminMiddleware(admins []string) gin.HandlerFunc {
+	return requireUsers(admins, "Admin access required")
+}
+
+// ModeratorMiddleware restricts a route group to moderators. Admins can
+// always moderate.
+func ModeratorMiddleware(admins, moderators []string) gin.HandlerFunc {
+	return requireUsers(append(append([]string{}, admins...), moderators...), "Moderator access required")
+}
+
+// IdentifyModerators records whether the authenticated user is a moderator
+// or admin, for handlers that allow moderators more than other users. It
+// rejects no one; read the result with IsModerator.
+func IdentifyModerators(admins, moderators []string) gin.HandlerFunc {
+	allowed := userSet(append(append([]string{}, admins...), moderators...))
+
+	return func(c *gin.Context) {
+		c.Set("is_moderator", allowed[GetUserID(c)] || allowed[GetUsername(c)])
+		c.Next()
+	}
+}
+
+// IsModerator reports whether IdentifyModerators found the user to be a
+// moderator or admin
+func IsModerator(c *gin.Context) bool {
+	return c.GetBool("is_moderator")
+}
+
+// requireUsers rejects authenticated users whose ID or username is not
+// in users
+func requireUsers(users []string, message string) gin.HandlerFunc {
+	allowed := userSet(users)
+
+	return func(c *gin.Context) {
+		if !allowed[GetUserID(c)] && !allowed[GetUsername(c)] {
+			response.Forbidden(c, message)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func userSet(users []string) map[string]bool {
+	set := make(map[string]bool, len(users))
+	for _, user := range users {
+		set[user] = true
+	}
+	return set
+}
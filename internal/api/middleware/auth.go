@@ -12,27 +12,9 @@ import (
 // AuthMiddleware creates JWT authentication middleware
 func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var token string
+		token := requestToken(c)
 
-		// Get token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" {
-			// Extract token from "Bearer <token>" format
-			parts := strings.Split(authHeader, " ")
-			if len(parts) == 2 && parts[0] == "Bearer" {
-				token = parts[1]
-			}
-		}
-
-		// If header missing or invalid, check cookie
-		if token == "" {
-			cookieToken, err := c.Cookie("access_token")
-			if err == nil && cookieToken != "" {
-				token = cookieToken
-			}
-		}
-
-		// If still no token, unauthorized
+		// If no token, unauthorized
 		if token == "" {
 			response.Unauthorized(c, "Missing authorization")
 			c.Abort()
@@ -47,15 +29,52 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// Set user claims in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("email", claims.Email)
+		setClaims(c, claims)
+		c.Next()
+	}
+}
 
+// OptionalAuthMiddleware identifies the user on public routes that
+// personalize their response. Requests without a valid token go through
+// anonymously.
+func OptionalAuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := requestToken(c); token != "" {
+			if claims, err := jwtManager.ValidateToken(token); err == nil {
+				setClaims(c, claims)
+			}
+		}
 		c.Next()
 	}
 }
 
+// requestToken returns the bearer token from the Authorization header, or
+// else the access_token cookie
+func requestToken(c *gin.Context) string {
+	// Extract token from "Bearer <token>" format
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			return parts[1]
+		}
+	}
+
+	// If header missing or invalid, check cookie
+	if cookieToken, err := c.Cookie("access_token"); err == nil {
+		return cookieToken
+	}
+	return ""
+}
+
+// setClaims puts the user's claims in the request context
+func setClaims(c *gin.Context, claims *auth.Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("email", claims.Email)
+	// Services attribute audited actions to the user on the context
+	c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), claims.UserID, claims.Username))
+}
+
 // GetUserID retrieves the user ID from the request context
 func GetUserID(c *gin.Context) string {
 	userID, exists := c.Get("user_id")
@@ -19,7 +19,9 @@ func LoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
 		duration := time.Since(startTime)
 		statusCode := c.Writer.Status()
 
-		log.Info("HTTP Request",
+		// Ctx tags the entry with the request ID and, when tracing, the trace ID
+		reqLog := log.Ctx(c.Request.Context())
+		reqLog.Info("HTTP Request",
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
 			"status", statusCode,
@@ -30,7 +32,7 @@ func LoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
 
 		// Log errors if any
 		if len(c.Errors) > 0 {
-			log.Error("Request errors", "errors", c.Errors.String())
+			reqLog.Error("Request errors", "errors", c.Errors.String())
 		}
 	}
 }
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestObserver records served HTTP requests
+type RequestObserver interface {
+	ObserveHTTP(method, route string, status int, d time.Duration)
+}
+
+// MetricsMiddleware reports every request to observer under its route
+// pattern. Requests that match no route share the "unmatched" label.
+func MetricsMiddleware(observer RequestObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		observer.ObserveHTTP(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -1,6 +1,10 @@
 package middleware
 
 import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,12 +19,20 @@ type rateLimitEntry struct {
 	resetTime time.Time
 }
 
+// RateLimitStatus describes a client's window once a request is counted
+type RateLimitStatus struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
 // RateLimiter implements a simple in-memory rate limiter
 type RateLimiter struct {
-	mu               sync.RWMutex
-	clients          map[string]*rateLimitEntry
-	requestsPerMin   int
-	cleanupInterval  time.Duration
+	mu              sync.RWMutex
+	clients         map[string]*rateLimitEntry
+	requestsPerMin  int
+	cleanupInterval time.Duration
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -52,6 +64,12 @@ func (rl *RateLimiter) cleanup() {
 
 // Allow checks if a request is allowed for the given client
 func (rl *RateLimiter) Allow(clientIP string) bool {
+	return rl.Take(clientIP).Allowed
+}
+
+// Take counts a request for the given client and reports what is left of
+// its window
+func (rl *RateLimiter) Take(clientIP string) RateLimitStatus {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -60,42 +78,107 @@ func (rl *RateLimiter) Allow(clientIP string) bool {
 
 	if !exists || now.After(entry.resetTime) {
 		// New client or window expired - create new entry
-		rl.clients[clientIP] = &rateLimitEntry{
-			count:     1,
-			resetTime: now.Add(time.Minute),
-		}
-		return true
+		entry = &rateLimitEntry{resetTime: now.Add(time.Minute)}
+		rl.clients[clientIP] = entry
 	}
 
+	status := RateLimitStatus{Limit: rl.requestsPerMin, Reset: entry.resetTime}
+
 	// Check if limit exceeded
 	if entry.count >= rl.requestsPerMin {
-		return false
+		return status
 	}
 
 	// Increment count
 	entry.count++
-	return true
+	status.Allowed = true
+	status.Remaining = rl.requestsPerMin - entry.count
+	return status
+}
+
+// RateLimit is a per-client allowance of RequestsPerMinute plus Burst
+// requests in each one-minute window
+type RateLimit struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// newLimiter creates a limiter for the combined allowance
+func (l RateLimit) newLimiter() *RateLimiter {
+	effectiveLimit := l.RequestsPerMinute
+	if l.Burst > 0 {
+		effectiveLimit = l.RequestsPerMinute + l.Burst
+	}
+	return NewRateLimiter(effectiveLimit)
+}
+
+// RateLimitGroup names the budget a request is charged to
+type RateLimitGroup string
+
+const (
+	RateLimitReads  RateLimitGroup = "reads"
+	RateLimitWrites RateLimitGroup = "writes"
+	RateLimitSearch RateLimitGroup = "search"
+)
+
+// RateLimitGroupOf sorts a request into a budget. Search and suggest are
+// costlier than other reads, so they are metered on their own; anything
+// that isn't a safe method counts as a write.
+func RateLimitGroupOf(c *gin.Context) RateLimitGroup {
+	route := c.FullPath()
+	if strings.HasSuffix(route, "/search") || strings.HasSuffix(route, "/search/suggest") {
+		return RateLimitSearch
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RateLimitReads
+	default:
+		return RateLimitWrites
+	}
 }
 
 // RateLimitMiddleware creates rate limiting middleware
 func RateLimitMiddleware(requestsPerMinute, burst int) gin.HandlerFunc {
-	// Combine requests and burst for effective limit
-	effectiveLimit := requestsPerMinute
-	if burst > 0 {
-		effectiveLimit = requestsPerMinute + burst
+	limiter := RateLimit{RequestsPerMinute: requestsPerMinute, Burst: burst}.newLimiter()
+
+	return func(c *gin.Context) {
+		enforceRateLimit(c, limiter)
 	}
+}
 
-	limiter := NewRateLimiter(effectiveLimit)
+// GroupRateLimitMiddleware meters reads, writes and searches against
+// separate budgets, so a burst of one kind can't starve the others
+func GroupRateLimitMiddleware(reads, writes, search RateLimit) gin.HandlerFunc {
+	limiters := map[RateLimitGroup]*RateLimiter{
+		RateLimitReads:  reads.newLimiter(),
+		RateLimitWrites: writes.newLimiter(),
+		RateLimitSearch: search.newLimiter(),
+	}
 
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
+		enforceRateLimit(c, limiters[RateLimitGroupOf(c)])
+	}
+}
 
-		if !limiter.Allow(clientIP) {
-			response.Error(c, 429, "Rate limit exceeded. Please try again later.")
-			c.Abort()
-			return
-		}
+// enforceRateLimit counts the request and sets the RateLimit-* headers,
+// rejecting it with a retry hint once the window is spent
+func enforceRateLimit(c *gin.Context, limiter *RateLimiter) {
+	status := limiter.Take(c.ClientIP())
 
-		c.Next()
+	// Seconds until the window resets, rounded up so clients never retry early
+	reset := int(math.Ceil(time.Until(status.Reset).Seconds()))
+	if reset < 1 {
+		reset = 1
+	}
+	c.Header("RateLimit-Limit", strconv.Itoa(status.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+	if !status.Allowed {
+		response.TooManyRequests(c, reset, "Rate limit exceeded. Please try again later.")
+		c.Abort()
+		return
 	}
+
+	c.Next()
 }
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+const (
+	// RequestIDHeader carries the request ID in both directions
+	RequestIDHeader = "X-Request-ID"
+	// requestIDKey stores the request ID in the gin context
+	requestIDKey = "request_id"
+	// maxRequestIDLength bounds IDs accepted from clients and proxies
+	maxRequestIDLength = 128
+)
+
+// RequestIDMiddleware reuses the caller's X-Request-ID, or assigns a new
+// one, echoes it in the response and puts it on the request context so
+// handlers and services log it via logger.Ctx
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
+
+// GetRequestID returns the request ID assigned by RequestIDMiddleware
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// validRequestID accepts short IDs of printable ASCII, so a forwarded ID
+// cannot forge log lines or response headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+// Schema is an OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawJSONType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaSet derives schemas from Go types, defining each named struct once
+// under components/schemas
+type schemaSet struct {
+	defs map[string]*Schema
+}
+
+func newSchemaSet() *schemaSet {
+	s := &schemaSet{defs: make(map[string]*Schema)}
+	s.defs["Error"] = s.structSchema(reflect.TypeOf(response.Response{}))
+	for _, v := range []interface{}{response.Pagination{}, response.Problem{}, response.Meta{}} {
+		s.of(v)
+	}
+	return s
+}
+
+// of returns the schema for v's type; nil documents a free-form object
+func (s *schemaSet) of(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{Type: "object"}
+	}
+	return s.typeSchema(reflect.TypeOf(v))
+}
+
+func (s *schemaSet) ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *schemaSet) typeSchema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.typeSchema(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := s.defs[name]; !ok {
+			s.defs[name] = &Schema{Type: "object"} // placeholder for recursive types
+			s.defs[name] = s.structSchema(t)
+		}
+		return s.ref(name)
+	default:
+		return &Schema{Type: "object"}
+	}
+}
+
+func (s *schemaSet) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *schemaSet) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.addFields(schema, embedded)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = s.typeSchema(field.Type)
+		if strings.Contains(field.Tag.Get("binding"), "required") ||
+			strings.Contains(field.Tag.Get("validate"), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// envelopeV1 wraps data in the v1 {success, data[, pagination]} body
+func (s *schemaSet) envelopeV1(data interface{}, paginated bool) *Schema {
+	dataSchema := s.of(data)
+	if paginated {
+		dataSchema = &Schema{Type: "array", Items: dataSchema}
+	}
+	env := &Schema{Type: "object", Properties: map[string]*Schema{
+		"success": {Type: "boolean"},
+		"data":    dataSchema,
+	}}
+	if paginated {
+		env.Properties["pagination"] = s.ref("Pagination")
+	}
+	return env
+}
+
+// envelopeV2 wraps data in the v2 {data, meta, links} body
+func (s *schemaSet) envelopeV2(data interface{}, paginated bool) *Schema {
+	dataSchema := s.of(data)
+	if paginated {
+		dataSchema = &Schema{Type: "array", Items: dataSchema}
+	}
+	return &Schema{Type: "object", Properties: map[string]*Schema{
+		"data":  dataSchema,
+		"meta":  s.ref("Meta"),
+		"links": {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+	}}
+}
+
+// schemaName qualifies types outside the domain package with their package
+// name, so handlers.ConnectPeerRequest and p2p.Topology cannot collide with
+// domain types
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+	if pkg == "domain" || pkg == "response" {
+		return t.Name()
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+}
@@ -0,0 +1,286 @@
+// Package openapi builds the API's OpenAPI 3.0 document from the routes
+// registered on the gin engine, so the published spec cannot drift from
+// what the server actually serves.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Param documents a query or header parameter. Path parameters are taken
+// from the route itself.
+type Param struct {
+	Name        string
+	In          string // "query" (default) or "header"
+	Type        string // "string" (default), "integer", "number" or "boolean"
+	Description string
+	Required    bool
+}
+
+// Operation documents one route
+type Operation struct {
+	Summary     string
+	Description string
+	Auth        bool // requires a bearer token
+	Params      []Param
+	Body        interface{} // request body; a zero value of its Go type
+	File        string      // multipart form field carrying an upload
+	Response    interface{} // success payload; a zero value of its Go type
+	Paginated   bool        // page-numbered list (v1) or cursor page (v2)
+	Status      int         // success status, 200 when zero
+	ContentType string      // non-JSON success content, e.g. text/event-stream
+}
+
+// Info describes the API as a whole
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Document is an OpenAPI 3.0 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components components                       `json:"components"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type operation struct {
+	Summary      string                `json:"summary"`
+	Description  string                `json:"description,omitempty"`
+	OperationID  string                `json:"operationId"`
+	Tags         []string              `json:"tags,omitempty"`
+	Parameters   []parameter           `json:"parameters,omitempty"`
+	RequestBody  *requestBody          `json:"requestBody,omitempty"`
+	Responses    map[string]*apiReturn `json:"responses"`
+	Security     []map[string][]string `json:"security,omitempty"`
+	Undocumented bool                  `json:"x-undocumented,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type apiReturn struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// bearerAuth names the JWT security scheme
+const bearerAuth = "BearerAuth"
+
+// Build documents every route under /api and /health. Routes without an
+// entry in ops are still listed, with a summary derived from the handler
+// name and marked x-undocumented, so a new endpoint shows up even before
+// it is described.
+func Build(info Info, routes gin.RoutesInfo, ops map[string]Operation) *Document {
+	schemas := newSchemaSet()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]map[string]*operation),
+		Components: components{
+			Schemas: schemas.defs,
+			SecuritySchemes: map[string]securityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	sorted := append(gin.RoutesInfo(nil), routes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Method < sorted[j].Method
+	})
+
+	for _, route := range sorted {
+		if !Documented(route.Path) {
+			continue
+		}
+		op, ok := ops[Key(route.Method, route.Path)]
+		if !ok {
+			op = Operation{Summary: handlerSummary(route.Handler)}
+		}
+
+		path, pathParams := convertPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*operation)
+		}
+		built := buildOperation(schemas, route, op, pathParams)
+		built.Undocumented = !ok
+		doc.Paths[path][strings.ToLower(route.Method)] = built
+	}
+
+	return doc
+}
+
+// Key identifies a route in the ops table
+func Key(method, path string) string {
+	return method + " " + path
+}
+
+// Missing lists the documented routes that have no entry in ops
+func Missing(routes gin.RoutesInfo, ops map[string]Operation) []string {
+	var missing []string
+	for _, route := range routes {
+		if _, ok := ops[Key(route.Method, route.Path)]; !ok && Documented(route.Path) {
+			missing = append(missing, Key(route.Method, route.Path))
+		}
+	}
+	return missing
+}
+
+// Documented reports whether a route belongs in the API document
+func Documented(path string) bool {
+	return strings.HasPrefix(path, "/api/") || path == "/health" || strings.HasPrefix(path, "/health/")
+}
+
+func buildOperation(schemas *schemaSet, route gin.RouteInfo, op Operation, pathParams []string) *operation {
+	v2 := strings.HasPrefix(route.Path, "/api/v2/")
+
+	out := &operation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: operationID(route.Method, route.Path),
+		Tags:        []string{tag(route.Path)},
+		Responses:   make(map[string]*apiReturn),
+	}
+
+	for _, name := range pathParams {
+		out.Parameters = append(out.Parameters, parameter{
+			Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	for _, p := range op.Params {
+		in, typ := p.In, p.Type
+		if in == "" {
+			in = "query"
+		}
+		if typ == "" {
+			typ = "string"
+		}
+		out.Parameters = append(out.Parameters, parameter{
+			Name: p.Name, In: in, Description: p.Description, Required: p.Required, Schema: &Schema{Type: typ},
+		})
+	}
+
+	if op.Body != nil {
+		out.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]mediaType{"application/json": {Schema: schemas.of(op.Body)}},
+		}
+	}
+	if op.File != "" {
+		out.RequestBody = &requestBody{
+			Required: true,
+			Content: map[string]mediaType{"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{op.File: {Type: "string", Format: "binary"}},
+				Required:   []string{op.File},
+			}}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &apiReturn{Description: http.StatusText(status)}
+	switch {
+	case op.ContentType != "":
+		success.Content = map[string]mediaType{op.ContentType: {}}
+	case v2:
+		success.Content = map[string]mediaType{"application/json": {Schema: schemas.envelopeV2(op.Response, op.Paginated)}}
+	default:
+		success.Content = map[string]mediaType{"application/json": {Schema: schemas.envelopeV1(op.Response, op.Paginated)}}
+	}
+	out.Responses[strconv.Itoa(status)] = success
+
+	errorType, errorSchema := "application/json", schemas.ref("Error")
+	if v2 {
+		errorType, errorSchema = "application/problem+json", schemas.ref("Problem")
+	}
+	out.Responses["default"] = &apiReturn{
+		Description: "Error",
+		Content:     map[string]mediaType{errorType: {Schema: errorSchema}},
+	}
+
+	if op.Auth {
+		out.Security = []map[string][]string{{bearerAuth: {}}}
+	}
+	return out
+}
+
+// convertPath rewrites gin's :name and *name segments as {name}
+func convertPath(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			params = append(params, seg[1:])
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// tag groups an operation by the first path segment after the API version
+func tag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 3 && segments[0] == "api" {
+		return segments[2]
+	}
+	return segments[0]
+}
+
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, seg := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '_' }) {
+		seg = strings.TrimLeft(seg, ":*")
+		if seg == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(seg[:1]) + seg[1:])
+	}
+	return b.String()
+}
+
+// handlerSummary turns ".../handlers.(*ArticleHandler).GetByCID-fm" into
+// "GetByCID"
+func handlerSummary(handler string) string {
+	name := handler[strings.LastIndex(handler, ".")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
@@ -1,32 +1,51 @@
 package api
 
 import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
 	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/openapi"
 	"github.com/amiyamandal-dev/newsp2p/internal/auth"
 	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/internal/metrics"
 	"github.com/amiyamandal-dev/newsp2p/internal/service"
 	"github.com/amiyamandal-dev/newsp2p/internal/web"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
 )
 
 // Router sets up the HTTP router with all routes and middleware
 type Router struct {
-	engine         *gin.Engine
-	authHandler    *handlers.AuthHandler
-	articleHandler *handlers.ArticleHandler
-	feedHandler    *handlers.FeedHandler
-	searchHandler  *handlers.SearchHandler
-	healthHandler  *handlers.HealthHandler
-	uploadHandler  *handlers.UploadHandler
-	networkHandler *handlers.NetworkHandler
-	webHandler     *web.WebHandler
-	jwtManager     *auth.JWTManager
-	userService    *service.UserService
-	cfg            *config.Config
-	logger         *logger.Logger
+	engine            *gin.Engine
+	authHandler       *handlers.AuthHandler
+	articleHandler    *handlers.ArticleHandler
+	feedHandler       *handlers.FeedHandler
+	searchHandler     *handlers.SearchHandler
+	healthHandler     *handlers.HealthHandler
+	uploadHandler     *handlers.UploadHandler
+	networkHandler    *handlers.NetworkHandler
+	integrityHandler  *handlers.IntegrityHandler
+	adminHandler      *handlers.AdminHandler
+	indexHandler      *handlers.IndexMaintenanceHandler
+	archiveHandler    *handlers.ArchiveHandler
+	pinHandler        *handlers.PinLedgerHandler
+	eventsHandler     *handlers.EventsHandler
+	v2Handler         *handlers.V2Handler
+	moderationHandler *handlers.ModerationHandler
+	voteHandler       *handlers.VoteHandler
+	commentHandler    *handlers.CommentHandler
+	reputationHandler *handlers.ReputationHandler
+	webHandler        *web.WebHandler
+	jwtManager        *auth.JWTManager
+	userService       *service.UserService
+	metrics           *metrics.Metrics // optional; set with SetMetrics
+	cfg               *config.Config
+	logger            *logger.Logger
 }
 
 // NewRouter creates a new router
@@ -38,6 +57,17 @@ func NewRouter(
 	healthHandler *handlers.HealthHandler,
 	uploadHandler *handlers.UploadHandler,
 	networkHandler *handlers.NetworkHandler,
+	integrityHandler *handlers.IntegrityHandler,
+	indexHandler *handlers.IndexMaintenanceHandler,
+	archiveHandler *handlers.ArchiveHandler,
+	pinHandler *handlers.PinLedgerHandler,
+	eventsHandler *handlers.EventsHandler,
+	v2Handler *handlers.V2Handler,
+	moderationHandler *handlers.ModerationHandler,
+	voteHandler *handlers.VoteHandler,
+	commentHandler *handlers.CommentHandler,
+	reputationHandler *handlers.ReputationHandler,
+	adminHandler *handlers.AdminHandler,
 	webHandler *web.WebHandler,
 	jwtManager *auth.JWTManager,
 	userService *service.UserService,
@@ -45,21 +75,38 @@ func NewRouter(
 	logger *logger.Logger,
 ) *Router {
 	return &Router{
-		authHandler:    authHandler,
-		articleHandler: articleHandler,
-		feedHandler:    feedHandler,
-		searchHandler:  searchHandler,
-		healthHandler:  healthHandler,
-		uploadHandler:  uploadHandler,
-		networkHandler: networkHandler,
-		webHandler:     webHandler,
-		jwtManager:     jwtManager,
-		userService:    userService,
-		cfg:            cfg,
-		logger:         logger,
+		authHandler:       authHandler,
+		articleHandler:    articleHandler,
+		feedHandler:       feedHandler,
+		searchHandler:     searchHandler,
+		healthHandler:     healthHandler,
+		uploadHandler:     uploadHandler,
+		networkHandler:    networkHandler,
+		integrityHandler:  integrityHandler,
+		adminHandler:      adminHandler,
+		indexHandler:      indexHandler,
+		archiveHandler:    archiveHandler,
+		pinHandler:        pinHandler,
+		eventsHandler:     eventsHandler,
+		v2Handler:         v2Handler,
+		moderationHandler: moderationHandler,
+		voteHandler:       voteHandler,
+		commentHandler:    commentHandler,
+		reputationHandler: reputationHandler,
+		webHandler:        webHandler,
+		jwtManager:        jwtManager,
+		userService:       userService,
+		cfg:               cfg,
+		logger:            logger,
 	}
 }
 
+// SetMetrics records request metrics and serves every registered metric
+// on cfg.Metrics.Path. Call before Setup.
+func (r *Router) SetMetrics(m *metrics.Metrics) {
+	r.metrics = m
+}
+
 // Setup configures all routes and middleware
 func (r *Router) Setup() *gin.Engine {
 	// Set Gin mode
@@ -74,42 +121,28 @@ func (r *Router) Setup() *gin.Engine {
 	// CORS middleware (global)
 	r.engine.Use(middleware.CORSMiddleware(r.cfg.CORS.AllowedOrigins))
 
+	// Request IDs (global), ahead of logging so every entry carries one
+	r.engine.Use(middleware.RequestIDMiddleware())
+
+	// Tracing (global), ahead of logging so entries carry the trace ID
+	if r.cfg.Tracing.Enabled {
+		r.engine.Use(middleware.TracingMiddleware())
+	}
+
 	// Logger middleware (global)
 	r.engine.Use(middleware.LoggerMiddleware(r.logger))
 
+	// Prometheus metrics (no rate limiting, no auth)
+	if r.metrics != nil {
+		r.engine.Use(middleware.MetricsMiddleware(r.metrics))
+		r.engine.GET(r.cfg.Metrics.Path, gin.WrapH(r.metrics.Handler()))
+	}
+
 	// Health check endpoints (no rate limiting, no auth)
 	r.engine.GET("/health", r.healthHandler.Health)
 	r.engine.GET("/health/ready", r.healthHandler.Readiness)
 	r.engine.GET("/health/live", r.healthHandler.Liveness)
 
-	// Serve API Documentation
-	r.engine.StaticFile("/docs/openapi.yaml", "./docs/openapi.yaml")
-	r.engine.GET("/docs", func(c *gin.Context) {
-		c.Header("Content-Type", "text/html")
-		c.String(200, `<!DOCTYPE html>
-<html lang="en">
-<head>
-  <meta charset="utf-8" />
-  <meta name="viewport" content="width=device-width, initial-scale=1" />
-  <meta name="description" content="SwaggerUI" />
-  <title>Liberation News API Docs</title>
-  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui.css" />
-</head>
-<body>
-<div id="swagger-ui"></div>
-<script src="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-bundle.js" crossorigin></script>
-<script>
-  window.onload = () => {
-    window.ui = SwaggerUIBundle({
-      url: '/docs/openapi.yaml',
-      dom_id: '#swagger-ui',
-    });
-  };
-</script>
-</body>
-</html>`)
-	})
-
 	// Web UI routes (if webHandler is available)
 	if r.webHandler != nil {
 		// Create a web routes group with web auth middleware
@@ -117,7 +150,13 @@ func (r *Router) Setup() *gin.Engine {
 		webRoutes.Use(web.AuthMiddleware(r.jwtManager, r.userService))
 		{
 			webRoutes.GET("/", r.webHandler.HomePage)
+			webRoutes.GET("/manifest.webmanifest", r.webHandler.Manifest)
+			webRoutes.GET("/sw.js", r.webHandler.ServiceWorker)
+			webRoutes.GET("/icon.svg", r.webHandler.Icon)
 			webRoutes.GET("/explore", r.webHandler.ExplorePage)
+			webRoutes.GET("/tag/:name", r.webHandler.TagPage)
+			webRoutes.GET("/search", r.webHandler.WebSearch)
+			webRoutes.GET("/search/suggest", r.webHandler.WebSuggest)
 			webRoutes.GET("/login", r.webHandler.LoginPage)
 			webRoutes.POST("/login", r.webHandler.WebLogin)
 			webRoutes.GET("/logout", r.webHandler.WebLogout)
@@ -125,34 +164,71 @@ func (r *Router) Setup() *gin.Engine {
 			webRoutes.POST("/register", r.webHandler.WebRegister)
 			webRoutes.GET("/create", r.webHandler.CreateArticlePage)
 			webRoutes.POST("/create", r.webHandler.WebCreateArticle)
+			webRoutes.POST("/preview", r.webHandler.Preview)
 			webRoutes.GET("/article/:cid", r.webHandler.ArticlePage)
+			webRoutes.GET("/article/:cid/reader", r.webHandler.ReaderPage)
+			webRoutes.GET("/article/:cid/edit", r.webHandler.EditArticlePage)
+			webRoutes.POST("/article/:cid/edit", r.webHandler.WebEditArticle)
+			webRoutes.POST("/article/:cid/delete", r.webHandler.WebDeleteArticle)
+			webRoutes.GET("/dashboard", r.webHandler.DashboardPage)
+			webRoutes.GET("/moderation", r.webHandler.ModerationPage)
+			webRoutes.POST("/moderation/:id", r.webHandler.WebModerate)
+			webRoutes.GET("/article/:cid/comments", r.webHandler.WebComments)
+			webRoutes.POST("/article/:cid/comments", r.webHandler.WebCreateComment)
+			webRoutes.DELETE("/article/:cid/comments/:id", r.webHandler.WebDeleteComment)
+			webRoutes.GET("/author/:name", r.webHandler.AuthorPage)
+			webRoutes.GET("/author/:name/rss", r.webHandler.AuthorFeed)
 			webRoutes.GET("/network", r.webHandler.NetworkPage)
+			webRoutes.GET("/network/live", r.webHandler.NetworkLive)
+			webRoutes.POST("/language", r.webHandler.SetLanguage)
 		}
 	}
 
+	// API routes share their rate limits across versions; reads, writes and
+	// searches each have a budget of their own
+	limits := r.cfg.RateLimit
+	budget := func(group config.RateLimitBudget) middleware.RateLimit {
+		b := limits.Budget(group)
+		return middleware.RateLimit{RequestsPerMinute: b.RequestsPerMinute, Burst: b.Burst}
+	}
+	rateLimit := middleware.GroupRateLimitMiddleware(
+		budget(limits.Reads),
+		budget(limits.Writes),
+		budget(limits.Search),
+	)
+
 	// API v1 routes (with rate limiting)
 	v1 := r.engine.Group("/api/v1")
-	v1.Use(middleware.RateLimitMiddleware(
-		r.cfg.RateLimit.RequestsPerMinute,
-		r.cfg.RateLimit.Burst,
-	))
+	v1.Use(rateLimit)
 	{
 		// Upload routes
 		upload := v1.Group("/upload")
 		upload.Use(middleware.AuthMiddleware(r.jwtManager))
 		{
 			upload.POST("/image", r.uploadHandler.UploadImage)
+			upload.POST("/media", r.uploadHandler.UploadMedia)
+			upload.POST("/media/reservations", r.uploadHandler.ReserveUpload)
+			upload.GET("/media/:id/progress", r.uploadHandler.UploadProgress)
 		}
 
+		// Uploaded audio and video, public so readers can find renditions
+		v1.GET("/media/:cid", r.uploadHandler.GetMedia)
+
 		// Network routes
 		network := v1.Group("/network")
 		{
 			network.GET("/stats", r.networkHandler.GetStats)
 			network.GET("/peers", r.networkHandler.GetPeers)
 			network.GET("/peers/:id", r.networkHandler.GetPeerInfo)
+			network.GET("/topology", r.networkHandler.GetTopology)
+			network.GET("/pubsub", r.networkHandler.GetPubsubStats)
+			network.GET("/policy", r.networkHandler.GetPolicy)
+			network.GET("/dht/findpeer/:id", r.networkHandler.FindPeer)
+			network.GET("/dht/findprovs/:cid", r.networkHandler.FindProviders)
 			network.POST("/connect", r.networkHandler.ConnectPeer)
 			network.POST("/sync", r.networkHandler.TriggerSync)
 			network.GET("/sync/status", r.networkHandler.GetSyncStatus)
+			network.GET("/sync/stats", r.networkHandler.GetSyncStats)
 		}
 
 		// Auth routes (no auth required)
@@ -174,27 +250,66 @@ func (r *Router) Setup() *gin.Engine {
 		articles := v1.Group("/articles")
 		{
 			// Public article routes
-			articles.GET("/:cid", r.articleHandler.GetByCID)
-			articles.GET("", r.articleHandler.List)
+			articles.GET("/:cid", middleware.ETag(middleware.CacheRevalidate), r.articleHandler.GetByCID)
+			articles.GET("", middleware.ETag(middleware.CacheRevalidate), r.articleHandler.List)
+			if r.eventsHandler != nil {
+				articles.GET("/stream", r.eventsHandler.ArticleStream)
+			}
+			articles.POST("/fetch", r.articleHandler.Fetch)
 			articles.POST("/:cid/verify", r.articleHandler.VerifySignature)
+			articles.GET("/:cid/verification", r.articleHandler.Verification)
+			articles.GET("/:cid/export", middleware.ETag(middleware.CacheRevalidate), r.articleHandler.Export)
+			articles.GET("/:cid/revisions", r.articleHandler.Revisions)
+			articles.GET("/:cid/node/*path", r.articleHandler.NodeField)
+			if r.voteHandler != nil {
+				articles.GET("/:cid/votes", r.voteHandler.Tally)
+			}
+			if r.commentHandler != nil {
+				articles.GET("/:cid/comments", middleware.OptionalAuthMiddleware(r.jwtManager), r.commentHandler.List)
+			}
 
 			// Protected article routes
 			articlesProtected := articles.Group("")
 			articlesProtected.Use(middleware.AuthMiddleware(r.jwtManager))
 			{
 				articlesProtected.POST("", r.articleHandler.Create)
+				articlesProtected.POST("/batch", r.articleHandler.CreateBatch)
 				articlesProtected.PUT("/:id", r.articleHandler.Update)
 				articlesProtected.DELETE("/:id", r.articleHandler.Delete)
+				if r.moderationHandler != nil {
+					articlesProtected.POST("/:cid/report", r.moderationHandler.Report) // :cid also accepts an article ID
+					articlesProtected.POST("/:cid/appeal", r.moderationHandler.Appeal) // :cid also accepts an article ID
+				}
+				if r.voteHandler != nil {
+					articlesProtected.POST("/:cid/vote", r.voteHandler.Vote) // :cid also accepts an article ID
+				}
+				if r.commentHandler != nil {
+					articlesProtected.POST("/:cid/comments", r.commentHandler.Create) // :cid also accepts an article ID
+				}
 			}
 		}
 
+		// Circle routes: groups whose articles are encrypted for their members
+		circles := v1.Group("/circles")
+		circles.Use(middleware.AuthMiddleware(r.jwtManager))
+		{
+			circles.GET("", r.articleHandler.ListCircles)
+			circles.POST("", r.articleHandler.CreateCircle)
+			circles.GET("/key-card", r.articleHandler.CircleKeyCard)
+			circles.GET("/:id", r.articleHandler.GetCircle)
+			circles.GET("/:id/articles", r.articleHandler.CircleArticles)
+			circles.POST("/:id/members", r.articleHandler.AddCircleMember)
+			circles.DELETE("/:id/members/:username", r.articleHandler.RemoveCircleMember)
+		}
+
 		// Feed routes
 		feeds := v1.Group("/feeds")
 		{
 			// Public feed routes
-			feeds.GET("", r.feedHandler.List)
-			feeds.GET("/:name", r.feedHandler.Get)
-			feeds.GET("/:name/articles", r.feedHandler.GetArticles)
+			feeds.GET("", middleware.ETag(middleware.CacheRevalidate), r.feedHandler.List)
+			feeds.GET("/resolve", r.feedHandler.ResolveRemote)
+			feeds.GET("/:name", middleware.ETag(middleware.CacheRevalidate), r.feedHandler.Get)
+			feeds.GET("/:name/articles", middleware.ETag(middleware.CacheRevalidate), r.feedHandler.GetArticles)
 
 			// Protected feed routes
 			feedsProtected := feeds.Group("")
@@ -202,15 +317,245 @@ func (r *Router) Setup() *gin.Engine {
 			{
 				feedsProtected.POST("/:name/sync", r.feedHandler.TriggerSync)
 			}
+
+			// Feeds publish under the node's IPNS keys and seeding pins content, so only admins manage them
+			feedsAdmin := feeds.Group("")
+			feedsAdmin.Use(middleware.AuthMiddleware(r.jwtManager), middleware.AdminMiddleware(r.cfg.Auth.AdminUsers))
+			{
+				feedsAdmin.POST("", r.feedHandler.Create)
+				feedsAdmin.POST("/seed", r.feedHandler.Seed)
+				feedsAdmin.PUT("/:name", r.feedHandler.Update)
+				feedsAdmin.DELETE("/:name", r.feedHandler.Delete)
+			}
+		}
+
+		// Archive routes
+		if r.archiveHandler != nil {
+			archive := v1.Group("/archive")
+			archive.Use(middleware.AuthMiddleware(r.jwtManager))
+			{
+				archive.GET("/export", r.archiveHandler.Export)
+			}
+		}
+
+		// Real-time event stream
+		if r.eventsHandler != nil {
+			v1.GET("/ws", middleware.AuthMiddleware(r.jwtManager), r.eventsHandler.Stream)
 		}
 
 		// Search routes (public)
 		v1.GET("/search", r.searchHandler.Search)
+		v1.GET("/search/suggest", r.searchHandler.Suggest)
+
+		// Reputation routes (public)
+		if r.reputationHandler != nil {
+			v1.GET("/reputation/top", r.reputationHandler.Top)
+			v1.GET("/reputation/peers", r.reputationHandler.Peers)
+			v1.GET("/reputation/:did", r.reputationHandler.Get)
+			v1.GET("/reputation/:did/endorsements", r.reputationHandler.Endorsements)
+
+			endorse := v1.Group("/reputation/:did/endorse")
+			endorse.Use(middleware.AuthMiddleware(r.jwtManager))
+			{
+				endorse.POST("", r.reputationHandler.Endorse)
+				endorse.DELETE("", r.reputationHandler.RevokeEndorsement)
+			}
+
+			// Each user's own trust graph
+			trust := v1.Group("/trust")
+			trust.Use(middleware.AuthMiddleware(r.jwtManager))
+			{
+				trust.GET("/edges", r.reputationHandler.TrustEdges)
+				trust.PUT("/edges/:did", r.reputationHandler.SetTrustEdge)
+				trust.DELETE("/edges/:did", r.reputationHandler.RemoveTrustEdge)
+				trust.GET("/network", r.reputationHandler.TrustNetwork)
+				trust.GET("/network/:did", r.reputationHandler.TrustScore)
+				trust.GET("/feed", r.reputationHandler.TrustFeed)
+			}
+		}
+
+		// Comment routes; moderators may delete any comment
+		if r.commentHandler != nil {
+			comments := v1.Group("/comments")
+			comments.GET("/:id", r.commentHandler.Get)
+
+			commentsProtected := comments.Group("")
+			commentsProtected.Use(middleware.AuthMiddleware(r.jwtManager))
+			{
+				commentsProtected.PUT("/:id", r.commentHandler.Update)
+				commentsProtected.DELETE("/:id",
+					middleware.IdentifyModerators(r.cfg.Auth.AdminUsers, r.cfg.Auth.Moderators),
+					r.commentHandler.Delete)
+			}
+		}
+
+		// Moderation queue (moderators and admins)
+		if r.moderationHandler != nil {
+			moderation := v1.Group("/moderation")
+			moderation.Use(middleware.AuthMiddleware(r.jwtManager))
+			moderation.Use(middleware.ModeratorMiddleware(r.cfg.Auth.AdminUsers, r.cfg.Auth.Moderators))
+			{
+				moderation.GET("/reports", r.moderationHandler.List)
+				moderation.GET("/reports/:id", r.moderationHandler.Get)
+				moderation.POST("/reports/:id/resolve", r.moderationHandler.Resolve)
+				moderation.POST("/reports/:id/dismiss", r.moderationHandler.Dismiss)
+				moderation.GET("/cases", r.moderationHandler.ListCases)
+				moderation.GET("/cases/:id", r.moderationHandler.GetCase)
+				moderation.POST("/cases/:id/assign", r.moderationHandler.AssignCase)
+				moderation.POST("/cases/:id/action", r.moderationHandler.ActionCase)
+				moderation.POST("/cases/:id/dismiss", r.moderationHandler.DismissCase)
+				moderation.GET("/appeals", r.moderationHandler.ListAppeals)
+				moderation.GET("/appeals/:id", r.moderationHandler.GetAppeal)
+				moderation.POST("/appeals/:id/uphold", r.moderationHandler.UpholdAppeal)
+				moderation.POST("/appeals/:id/reverse", r.moderationHandler.ReverseAppeal)
+			}
+
+			// Appeals signed by authors whose accounts live on other nodes
+			v1.POST("/appeals", r.moderationHandler.SubmitAppeal)
+		}
+
+		// Admin routes (node operators only)
+		admin := v1.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(r.jwtManager))
+		admin.Use(middleware.AdminMiddleware(r.cfg.Auth.AdminUsers))
+		{
+			admin.POST("/reindex", r.searchHandler.Reindex)
+			admin.GET("/reindex", r.searchHandler.ReindexStatus)
+			admin.GET("/ipfs/metrics", r.healthHandler.IPFSMetrics)
+
+			// Runtime profiles, unless they have a listener of their own
+			if r.cfg.Pprof.Enabled && r.cfg.Pprof.ListenAddr == "" {
+				admin.GET("/debug/pprof/*profile", handlers.Pprof)
+				admin.POST("/debug/pprof/*profile", handlers.Pprof) // symbol lookups
+			}
+
+			if r.adminHandler != nil {
+				admin.GET("/users", r.adminHandler.ListUsers)
+				admin.POST("/users/:id/activate", r.adminHandler.ActivateUser) // :id also accepts a username
+				admin.POST("/users/:id/deactivate", r.adminHandler.DeactivateUser)
+				admin.GET("/backup", r.adminHandler.Backup)
+				admin.GET("/audit", r.adminHandler.ListAudit)
+				admin.GET("/audit/verify", r.adminHandler.VerifyAudit)
+				admin.GET("/log-levels", r.adminHandler.LogLevels)
+				admin.PUT("/log-levels", r.adminHandler.SetLogLevels)
+				admin.GET("/storage", r.adminHandler.Storage)
+				admin.POST("/storage/sweep", r.adminHandler.Sweep)
+				admin.GET("/filters", r.adminHandler.ListFilters)
+				admin.POST("/filters/check", r.adminHandler.CheckFilters)
+				admin.GET("/filters/:name", r.adminHandler.GetFilter)
+				admin.PUT("/filters/:name", r.adminHandler.PutFilter)
+				admin.DELETE("/filters/:name", r.adminHandler.DeleteFilter)
+				admin.GET("/blocklists", r.adminHandler.ListBlocklists)
+				admin.GET("/blocklists/:name", r.adminHandler.GetBlocklist)
+				admin.PUT("/blocklists/:name", r.adminHandler.PutBlocklist)
+				admin.DELETE("/blocklists/:name", r.adminHandler.DeleteBlocklist)
+				admin.POST("/blocklists/:name/refresh", r.adminHandler.RefreshBlocklist)
+				admin.GET("/acceptance", r.adminHandler.Acceptance)
+			}
+
+			if r.integrityHandler != nil {
+				admin.POST("/verify", r.integrityHandler.Verify)
+				admin.GET("/verify", r.integrityHandler.LastReport)
+			}
+
+			if r.indexHandler != nil {
+				admin.GET("/search/stats", r.indexHandler.Stats)
+				admin.POST("/search/optimize", r.indexHandler.Optimize)
+			}
+
+			if r.archiveHandler != nil {
+				admin.POST("/archive/import", r.archiveHandler.Import)
+			}
+
+			if r.reputationHandler != nil {
+				admin.GET("/reputation/export", r.reputationHandler.Export)
+				admin.POST("/reputation/import", r.reputationHandler.Import)
+			}
+
+			if r.pinHandler != nil {
+				admin.GET("/pins", r.pinHandler.List)
+				admin.POST("/pins/reconcile", r.pinHandler.Reconcile)
+				admin.POST("/gc", r.pinHandler.CollectGarbage)
+				admin.GET("/gc", r.pinHandler.LastGarbageCollection)
+			}
+		}
 	}
 
+	// API v2 routes: cursor pagination and problem details, served
+	// alongside v1 while clients migrate
+	if r.v2Handler != nil {
+		v2 := r.engine.Group("/api/v2")
+		v2.Use(response.ProblemDetails())
+		v2.Use(rateLimit)
+		{
+			v2.GET("/articles", middleware.ETag(middleware.CacheRevalidate), r.v2Handler.ListArticles)
+			v2.GET("/articles/:cid", middleware.ETag(middleware.CacheRevalidate), r.v2Handler.GetArticle)
+			v2.POST("/articles", middleware.AuthMiddleware(r.jwtManager), r.v2Handler.CreateArticle)
+			v2.GET("/search", r.v2Handler.Search)
+		}
+
+		r.engine.NoRoute(func(c *gin.Context) {
+			if strings.HasPrefix(c.Request.URL.Path, "/api/v2/") {
+				response.WriteProblem(c, http.StatusNotFound, "No route matches "+c.Request.URL.Path)
+			}
+		})
+	}
+
+	// API documentation, generated from the routes registered above. JSON
+	// is valid YAML, so the same document is served under both names.
+	routes := r.engine.Routes()
+	if missing := openapi.Missing(routes, routeDocs); len(missing) > 0 {
+		r.logger.Warn("Routes missing from the API documentation", "routes", missing)
+	}
+	spec, err := json.Marshal(openapi.Build(apiInfo, routes, routeDocs))
+	if err != nil {
+		r.logger.Error("Failed to generate OpenAPI document", "error", err)
+	}
+	r.engine.GET("/docs/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", spec)
+	})
+	r.engine.GET("/docs/openapi.yaml", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/yaml", spec)
+	})
+	r.engine.GET("/docs", func(c *gin.Context) {
+		c.Header("Content-Type", "text/html")
+		c.String(200, `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <meta name="description" content="SwaggerUI" />
+  <title>Liberation News API Docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui.css" />
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-bundle.js" crossorigin></script>
+<script>
+  window.onload = () => {
+    window.ui = SwaggerUIBundle({
+      url: '/docs/openapi.json',
+      dom_id: '#swagger-ui',
+    });
+  };
+</script>
+</body>
+</html>`)
+	})
+
 	return r.engine
 }
 
+// PprofEngine serves only the runtime profiles, at /debug/pprof/, for the
+// loopback listener set by pprof.listen_addr
+func PprofEngine() *gin.Engine {
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	engine.GET("/debug/pprof/*profile", handlers.Pprof)
+	engine.POST("/debug/pprof/*profile", handlers.Pprof)
+	return engine
+}
+
 // GetEngine returns the Gin engine
 func (r *Router) GetEngine() *gin.Engine {
 	if r.engine == nil {
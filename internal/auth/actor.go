@@ -0,0 +1,24 @@
+package auth
+
+import "context"
+
+// actorKey carries the authenticated user through the request context
+type actorKey struct{}
+
+// Actor is the user on whose behalf a request runs
+type Actor struct {
+	ID       string
+	Username string
+}
+
+// WithActor returns ctx carrying the authenticated user, so services can
+// attribute what they do without every call taking a user
+func WithActor(ctx context.Context, id, username string) context.Context {
+	return context.WithValue(ctx, actorKey{}, Actor{ID: id, Username: username})
+}
+
+// ActorFrom returns the user carried by ctx, if any
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
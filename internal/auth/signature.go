@@ -60,3 +60,248 @@ func (s *ArticleSigner) VerifyArticle(article *domain.Article) error {
 
 	return nil
 }
+
+// SignVote signs a vote with the voter's private key
+func (s *ArticleSigner) SignVote(vote *domain.Vote, privateKey ed25519.PrivateKey) error {
+	content, err := vote.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	signature, err := crypto.Sign(content, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign vote: %w", err)
+	}
+
+	vote.Signature = signature
+	return nil
+}
+
+// VerifyVote verifies a vote's signature against the voter's public key
+func (s *ArticleSigner) VerifyVote(vote *domain.Vote) error {
+	publicKey, err := crypto.PublicKeyFromString(vote.VoterDID)
+	if err != nil {
+		return fmt.Errorf("failed to parse voter key: %w", err)
+	}
+
+	content, err := vote.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	valid, err := crypto.Verify(content, vote.Signature, publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	if !valid {
+		return domain.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// SignEndorsement signs an endorsement with the endorser's private key
+func (s *ArticleSigner) SignEndorsement(endorsement *domain.Endorsement, privateKey ed25519.PrivateKey) error {
+	content, err := endorsement.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	signature, err := crypto.Sign(content, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign endorsement: %w", err)
+	}
+
+	endorsement.Signature = signature
+	return nil
+}
+
+// VerifyEndorsement verifies an endorsement's signature against the
+// endorser's public key
+func (s *ArticleSigner) VerifyEndorsement(endorsement *domain.Endorsement) error {
+	publicKey, err := crypto.PublicKeyFromString(endorsement.EndorserDID)
+	if err != nil {
+		return fmt.Errorf("failed to parse endorser key: %w", err)
+	}
+
+	content, err := endorsement.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	valid, err := crypto.Verify(content, endorsement.Signature, publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	if !valid {
+		return domain.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// SignArticleDeletion signs an author's request to delete their article
+func (s *ArticleSigner) SignArticleDeletion(deletion *domain.ArticleDeletion, privateKey ed25519.PrivateKey) error {
+	content, err := deletion.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	signature, err := crypto.Sign(content, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign deletion: %w", err)
+	}
+
+	deletion.Signature = signature
+	return nil
+}
+
+// VerifyArticleDeletion verifies a deletion's signature against the
+// author key it names
+func (s *ArticleSigner) VerifyArticleDeletion(deletion *domain.ArticleDeletion) error {
+	publicKey, err := crypto.PublicKeyFromString(deletion.AuthorPubKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	content, err := deletion.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	valid, err := crypto.Verify(content, deletion.Signature, publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	if !valid {
+		return domain.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// SignAppeal signs an appeal with the author's private key
+func (s *ArticleSigner) SignAppeal(appeal *domain.Appeal, privateKey ed25519.PrivateKey) error {
+	content, err := appeal.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	signature, err := crypto.Sign(content, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign appeal: %w", err)
+	}
+
+	appeal.Signature = signature
+	return nil
+}
+
+// VerifyAppeal verifies an appeal's signature against the public key the
+// appealed article is signed with
+func (s *ArticleSigner) VerifyAppeal(appeal *domain.Appeal, authorPubKey string) error {
+	publicKey, err := crypto.PublicKeyFromString(authorPubKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse author key: %w", err)
+	}
+
+	content, err := appeal.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	valid, err := crypto.Verify(content, appeal.Signature, publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	if !valid {
+		return domain.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// SignCircleKeyCard signs a circle key card with its user's private key
+func (s *ArticleSigner) SignCircleKeyCard(card *domain.CircleKeyCard, privateKey ed25519.PrivateKey) error {
+	content, err := card.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	signature, err := crypto.Sign(content, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign key card: %w", err)
+	}
+
+	card.Signature = signature
+	return nil
+}
+
+// VerifyCircleKeyCard verifies a circle key card's signature against the
+// public key it names
+func (s *ArticleSigner) VerifyCircleKeyCard(card *domain.CircleKeyCard) error {
+	publicKey, err := crypto.PublicKeyFromString(card.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	content, err := card.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	valid, err := crypto.Verify(content, card.Signature, publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	if !valid {
+		return domain.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// SignCircleKeys signs a circle key message with the owner's private key
+func (s *ArticleSigner) SignCircleKeys(msg *domain.CircleKeyMessage, privateKey ed25519.PrivateKey) error {
+	content, err := msg.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	signature, err := crypto.Sign(content, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign circle keys: %w", err)
+	}
+
+	msg.Signature = signature
+	return nil
+}
+
+// VerifyCircleKeys verifies a circle key message's signature against the
+// owner's public key
+func (s *ArticleSigner) VerifyCircleKeys(msg *domain.CircleKeyMessage) error {
+	publicKey, err := crypto.PublicKeyFromString(msg.OwnerKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse owner key: %w", err)
+	}
+
+	content, err := msg.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to get signable content: %w", err)
+	}
+
+	valid, err := crypto.Verify(content, msg.Signature, publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	if !valid {
+		return domain.ErrInvalidSignature
+	}
+
+	return nil
+}
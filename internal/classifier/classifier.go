@@ -0,0 +1,38 @@
+// Package classifier connects the node to an operator's content models,
+// such as nudity, toxicity or disinformation classifiers, over HTTP with
+// JSON or over gRPC.
+package classifier
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+)
+
+// defaultTimeout bounds each call when no timeout is configured
+const defaultTimeout = 10 * time.Second
+
+// New returns a classifier for endpoint. http:// and https:// URLs are sent
+// JSON; grpc:// and grpcs:// addresses are called through the
+// ClassifierService in proto/newsp2p/v1/newsp2p.proto.
+func New(endpoint string, timeout time.Duration) (service.ContentClassifier, error) {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid classifier endpoint %q", endpoint)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return NewHTTPClassifier(endpoint, timeout), nil
+	case "grpc":
+		return NewGRPCClassifier(u.Host, false, timeout)
+	case "grpcs":
+		return NewGRPCClassifier(u.Host, true, timeout)
+	}
+	return nil, fmt.Errorf("classifier endpoint must be http(s):// or grpc(s)://, got %q", endpoint)
+}
@@ -0,0 +1,65 @@
+package classifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	newsp2pv1 "github.com/amiyamandal-dev/newsp2p/proto/newsp2p/v1"
+)
+
+// GRPCClassifier calls the Classify RPC of a ClassifierService, in plain
+// text or with TLS
+type GRPCClassifier struct {
+	client  newsp2pv1.ClassifierServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCClassifier creates a classifier calling the service at address
+// (host:port). The connection is made on the first call.
+func NewGRPCClassifier(address string, useTLS bool, timeout time.Duration) (*GRPCClassifier, error) {
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("invalid classifier address %q: %w", address, err)
+	}
+	return &GRPCClassifier{
+		client:  newsp2pv1.NewClassifierServiceClient(conn),
+		timeout: timeout,
+	}, nil
+}
+
+// Classify sends article to the classifier. Failed calls return the
+// classifier's gRPC status.
+func (c *GRPCClassifier) Classify(ctx context.Context, article *domain.Article) ([]domain.Classification, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result, err := c.client.Classify(ctx, &newsp2pv1.ClassifyRequest{
+		Id:       article.ID,
+		Cid:      article.CID,
+		Title:    article.Title,
+		Body:     article.Body,
+		Author:   article.Author,
+		Tags:     article.Tags,
+		Category: article.Category,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	classifications := make([]domain.Classification, len(result.Classifications))
+	for i, c := range result.Classifications {
+		classifications[i] = domain.Classification{Label: c.Label, Score: c.Score}
+	}
+	return classifications, nil
+}
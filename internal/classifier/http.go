@@ -0,0 +1,84 @@
+package classifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// maxResponseSize caps what is read of a classifier's response
+const maxResponseSize = 1 << 20
+
+// Request is the JSON body posted to an HTTP classifier
+type Request struct {
+	ID       string   `json:"id"`
+	CID      string   `json:"cid"`
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Author   string   `json:"author"`
+	Tags     []string `json:"tags"`
+	Category string   `json:"category"`
+}
+
+// Response is the JSON an HTTP classifier answers with
+type Response struct {
+	Classifications []domain.Classification `json:"classifications"`
+}
+
+// HTTPClassifier POSTs each article to a URL as a Request and reads back
+// a Response. Any status other than 200 is a failure.
+type HTTPClassifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPClassifier creates a classifier posting to url
+func NewHTTPClassifier(url string, timeout time.Duration) *HTTPClassifier {
+	return &HTTPClassifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Classify sends article to the classifier
+func (c *HTTPClassifier) Classify(ctx context.Context, article *domain.Article) ([]domain.Classification, error) {
+	body, err := json.Marshal(Request{
+		ID:       article.ID,
+		CID:      article.CID,
+		Title:    article.Title,
+		Body:     article.Body,
+		Author:   article.Author,
+		Tags:     article.Tags,
+		Category: article.Category,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("classifier returned %s", resp.Status)
+	}
+	var result Response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid classifier response: %w", err)
+	}
+	return result.Classifications, nil
+}
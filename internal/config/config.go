@@ -1,7 +1,16 @@
 package config
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -10,15 +19,46 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	IPFS      IPFSConfig      `mapstructure:"ipfs"`
-	Auth      AuthConfig      `mapstructure:"auth"`
-	Search    SearchConfig    `mapstructure:"search"`
-	Logging   LoggingConfig   `mapstructure:"logging"`
-	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
-	CORS      CORSConfig      `mapstructure:"cors"`
-	P2P       P2PConfig       `mapstructure:"p2p"`
+	Server     ServerConfig     `mapstructure:"server"`
+	GRPC       GRPCConfig       `mapstructure:"grpc"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	IPFS       IPFSConfig       `mapstructure:"ipfs"`
+	Auth       AuthConfig       `mapstructure:"auth"`
+	Search     SearchConfig     `mapstructure:"search"`
+	Upload     UploadConfig     `mapstructure:"upload"`
+	Logging    LoggingConfig    `mapstructure:"logging"`
+	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
+	CORS       CORSConfig       `mapstructure:"cors"`
+	P2P        P2PConfig        `mapstructure:"p2p"`
+	Data       DataConfig       `mapstructure:"data"`
+	Metrics    MetricsConfig    `mapstructure:"metrics"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
+	Pprof      PprofConfig      `mapstructure:"pprof"`
+	Events     EventsConfig     `mapstructure:"events"`
+	Alerts     AlertsConfig     `mapstructure:"alerts"`
+	Retention  RetentionConfig  `mapstructure:"retention"`
+	Network    NetworkConfig    `mapstructure:"network"`
+	Classifier ClassifierConfig `mapstructure:"classifier"`
+	Blocklists BlocklistsConfig `mapstructure:"blocklists"`
+	Trust      TrustConfig      `mapstructure:"trust"`
+	Acceptance AcceptanceConfig `mapstructure:"acceptance"`
+}
+
+// DataConfig controls where node-local state lives on disk. Setting a
+// profile namespaces every store (Badger, Bleve, node key, bootstrap
+// cache) under <root>/profiles/<profile> so several nodes can share a
+// machine without stepping on each other.
+type DataConfig struct {
+	Root    string `mapstructure:"root"`
+	Profile string `mapstructure:"profile"`
+}
+
+// Dir returns the directory holding state for the active profile
+func (d DataConfig) Dir() string {
+	if d.Profile == "" {
+		return d.Root
+	}
+	return filepath.Join(d.Root, "profiles", d.Profile)
 }
 
 // ServerConfig contains HTTP server configuration
@@ -29,6 +69,13 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	MaxBatchSize    int           `mapstructure:"max_batch_size"` // articles per POST /articles/batch
+}
+
+// GRPCConfig controls the gRPC API, served on its own port on server.host
+type GRPCConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Port    int  `mapstructure:"port"`
 }
 
 // DatabaseConfig contains database configuration
@@ -37,6 +84,26 @@ type DatabaseConfig struct {
 	Path         string `mapstructure:"path"`
 	MaxOpenConns int    `mapstructure:"max_open_conns"`
 	MaxIdleConns int    `mapstructure:"max_idle_conns"`
+	CacheSize    int    `mapstructure:"cache_size"` // articles held in the in-memory LRU, 0 disables
+	// EncryptionKey encrypts the store at rest: a hex-encoded AES key of
+	// 16, 24 or 32 bytes. Empty leaves it unencrypted. Best mounted as a
+	// file through NEWS_DATABASE_ENCRYPTION_KEY_FILE.
+	EncryptionKey string `mapstructure:"encryption_key"`
+}
+
+// EncryptionKeyBytes decodes EncryptionKey; nil when it is empty
+func (d DatabaseConfig) EncryptionKeyBytes() ([]byte, error) {
+	if d.EncryptionKey == "" {
+		return nil, nil
+	}
+	key, err := hex.DecodeString(d.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("database.encryption_key must be hex: %w", err)
+	}
+	if n := len(key); n != 16 && n != 24 && n != 32 {
+		return nil, fmt.Errorf("database.encryption_key must be 16, 24 or 32 bytes, got %d", n)
+	}
+	return key, nil
 }
 
 // IPFSConfig contains IPFS client configuration
@@ -44,6 +111,105 @@ type IPFSConfig struct {
 	APIEndpoint string        `mapstructure:"api_endpoint"`
 	Timeout     time.Duration `mapstructure:"timeout"`
 	PinArticles bool          `mapstructure:"pin_articles"`
+	Cluster     ClusterConfig `mapstructure:"cluster"`
+	IPNS        IPNSConfig    `mapstructure:"ipns"`
+	GC          GCConfig      `mapstructure:"gc"`
+	MFSFeedRoot string        `mapstructure:"mfs_feed_root"` // MFS directory feeds are mirrored under; empty disables
+	Add         AddConfig     `mapstructure:"add"`
+	Timeouts    TimeoutConfig `mapstructure:"timeouts"` // per operation; Timeout applies to everything else
+
+	// Pin ledger: failed pins are retried with backoff and the ledger is
+	// checked against the node's pinset every PinReconcileInterval
+	PinRetryInterval     time.Duration `mapstructure:"pin_retry_interval"`
+	PinReconcileInterval time.Duration `mapstructure:"pin_reconcile_interval"`
+	PinMaxBackoff        time.Duration `mapstructure:"pin_max_backoff"`
+}
+
+// TimeoutConfig bounds each kind of IPFS operation separately
+type TimeoutConfig struct {
+	Add  time.Duration `mapstructure:"add"`
+	Cat  time.Duration `mapstructure:"cat"`  // whole verified read of one file
+	Pin  time.Duration `mapstructure:"pin"`  // pin/unpin; pinning may fetch the whole DAG
+	IPNS time.Duration `mapstructure:"ipns"` // publishing; resolves use ipns.resolve_timeout
+}
+
+// AddConfig chooses the CID format of content added to IPFS. The hash
+// name is checked against the multihash table when the client is built.
+type AddConfig struct {
+	CIDVersion int    `mapstructure:"cid_version"` // 0 or 1 (base32)
+	RawLeaves  bool   `mapstructure:"raw_leaves"`  // CIDv1 only
+	Hash       string `mapstructure:"hash"`        // e.g. sha2-256, blake3
+}
+
+// GCConfig is the retention policy for pinned content. It only ever
+// unpins CIDs recorded in the pin ledger.
+type GCConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	Interval          time.Duration `mapstructure:"interval"`
+	RevisionRetention time.Duration `mapstructure:"revision_retention"` // 0 keeps old revisions forever
+	ArticleMaxAge     time.Duration `mapstructure:"article_max_age"`    // 0 keeps articles forever
+	RepoGC            bool          `mapstructure:"repo_gc"`            // also run `ipfs repo gc`
+}
+
+// RetentionConfig caps how much content synced from other nodes is kept.
+// The sweeper removes synced articles, oldest first; articles written by
+// this node's own users are never removed.
+type RetentionConfig struct {
+	Enabled           bool                     `mapstructure:"enabled"`
+	Interval          time.Duration            `mapstructure:"interval"`
+	MaxDiskUsage      int64                    `mapstructure:"max_disk_usage"`      // bytes used by the database and search index; 0 is unlimited
+	MaxSyncedArticles int                      `mapstructure:"max_synced_articles"` // 0 is unlimited
+	Categories        map[string]time.Duration `mapstructure:"categories"`          // how long synced articles are kept, by category
+}
+
+// IPNSConfig controls how feed names are published and resolved
+type IPNSConfig struct {
+	Pubsub         bool          `mapstructure:"pubsub"`          // resolve and publish over IPNS-over-PubSub
+	ResolveTimeout time.Duration `mapstructure:"resolve_timeout"` // DHT fallback bound
+	Follow         []string      `mapstructure:"follow"`          // other nodes' feed names to subscribe to at startup
+}
+
+// ClusterConfig points pin operations at an IPFS Cluster REST API. Leaving
+// the endpoint empty keeps pins on the local IPFS node only.
+type ClusterConfig struct {
+	Endpoint       string `mapstructure:"endpoint"`        // e.g. http://localhost:9094
+	ReplicationMin int    `mapstructure:"replication_min"` // 0 = cluster default, -1 = every peer
+	ReplicationMax int    `mapstructure:"replication_max"`
+	Username       string `mapstructure:"username"`
+	Password       string `mapstructure:"password"`
+	Token          string `mapstructure:"token"` // bearer token, for clusters using JWT auth instead of a password
+}
+
+// UploadConfig controls media uploads to IPFS
+type UploadConfig struct {
+	ChunkSize int64            `mapstructure:"chunk_size"` // bytes per IPFS chunk
+	MaxSizes  map[string]int64 `mapstructure:"max_sizes"`  // bytes, keyed by "type/subtype" or "type/*"
+	TempDir   string           `mapstructure:"temp_dir"`   // spool for audio/video; system temp dir when empty
+	Transcode TranscodeConfig  `mapstructure:"transcode"`
+}
+
+// TranscodeConfig runs an external command on every new audio and video
+// upload. {input}, {output_dir} and {mime} in the arguments are replaced
+// with the uploaded file, the directory to write renditions to and its
+// MIME type.
+type TranscodeConfig struct {
+	Command []string      `mapstructure:"command"` // program and arguments; empty disables transcoding
+	Timeout time.Duration `mapstructure:"timeout"` // per upload
+}
+
+// MaxSize returns the size limit for a MIME type. An exact match wins over
+// a family wildcard; types matching neither are not accepted.
+func (c UploadConfig) MaxSize(mimeType string) (int64, bool) {
+	mimeType = strings.ToLower(mimeType)
+	if limit, ok := c.MaxSizes[mimeType]; ok {
+		return limit, true
+	}
+	if family, _, ok := strings.Cut(mimeType, "/"); ok {
+		if limit, ok := c.MaxSizes[family+"/*"]; ok {
+			return limit, true
+		}
+	}
+	return 0, false
 }
 
 // AuthConfig contains authentication configuration
@@ -52,25 +218,101 @@ type AuthConfig struct {
 	JWTExpiry          time.Duration `mapstructure:"jwt_expiry"`
 	RefreshTokenExpiry time.Duration `mapstructure:"refresh_token_expiry"`
 	BcryptCost         int           `mapstructure:"bcrypt_cost"`
+	AdminUsers         []string      `mapstructure:"admin_users"` // usernames or user IDs allowed on admin routes
+	Moderators         []string      `mapstructure:"moderators"`  // usernames or user IDs that work the moderation queue, besides admins
 }
 
 // SearchConfig contains search index configuration
 type SearchConfig struct {
-	IndexPath string `mapstructure:"index_path"`
+	IndexPath    string `mapstructure:"index_path"`
+	MaxFuzziness int    `mapstructure:"max_fuzziness"` // cap on edit distance for fuzzy queries
+
+	// scope=network searches
+	FederatedPeers   int           `mapstructure:"federated_peers"`   // peers queried per search
+	FederatedTimeout time.Duration `mapstructure:"federated_timeout"` // overall wait for peer results
+
+	// Result cache for repeated queries; 0 disables either
+	CacheSize int           `mapstructure:"cache_size"`
+	CacheTTL  time.Duration `mapstructure:"cache_ttl"`
+
+	Optimize SearchOptimizeConfig `mapstructure:"optimize"`
+}
+
+// SearchOptimizeConfig schedules index compaction during quiet hours
+type SearchOptimizeConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`
+	WindowStart      string  `mapstructure:"window_start"`      // local time, "HH:MM"
+	WindowEnd        string  `mapstructure:"window_end"`        // may be earlier than start to span midnight
+	MinSegments      int     `mapstructure:"min_segments"`      // compact when more file segments than this...
+	MinFragmentation float64 `mapstructure:"min_fragmentation"` // ...or when this share of bytes is reclaimable
+}
+
+// Window returns the quiet-hours window as offsets from midnight
+func (c SearchOptimizeConfig) Window() (start, end time.Duration, err error) {
+	if start, err = parseClock(c.WindowStart); err != nil {
+		return 0, 0, fmt.Errorf("search.optimize.window_start: %w", err)
+	}
+	if end, err = parseClock(c.WindowEnd); err != nil {
+		return 0, 0, fmt.Errorf("search.optimize.window_end: %w", err)
+	}
+	return start, end, nil
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("must be HH:MM, got %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
 }
 
 // LoggingConfig contains logging configuration
 type LoggingConfig struct {
-	Level  string `mapstructure:"level"`  // debug, info, warn, error
-	Format string `mapstructure:"format"` // json, text
+	Level  string        `mapstructure:"level"`  // debug, info, warn, error
+	Format string        `mapstructure:"format"` // json, text
+	File   LogFileConfig `mapstructure:"file"`
+	// Components overrides Level for named components ("p2p-sync": debug).
+	// Reloaded on SIGHUP along with Level.
+	Components map[string]string `mapstructure:"components"`
+}
+
+// LogFileConfig writes logs to a file as well as the console. The file is
+// rotated at max_size_mb; max_backups and max_age_days bound how many
+// rotated files are kept (0 keeps them all).
+type LogFileConfig struct {
+	Path       string `mapstructure:"path"` // empty disables the file
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	MaxAgeDays int    `mapstructure:"max_age_days"`
+	Compress   bool   `mapstructure:"compress"`
 }
 
-// RateLimitConfig contains rate limiting configuration
+// RateLimitConfig contains rate limiting configuration. Reads, writes and
+// searches are metered separately per client; a group whose
+// requests_per_minute is 0 falls back to the top-level budget.
 type RateLimitConfig struct {
+	RequestsPerMinute int             `mapstructure:"requests_per_minute"`
+	Burst             int             `mapstructure:"burst"`
+	Reads             RateLimitBudget `mapstructure:"reads"`
+	Writes            RateLimitBudget `mapstructure:"writes"`
+	Search            RateLimitBudget `mapstructure:"search"`
+}
+
+// RateLimitBudget is the allowance of one route group, per client
+type RateLimitBudget struct {
 	RequestsPerMinute int `mapstructure:"requests_per_minute"`
 	Burst             int `mapstructure:"burst"`
 }
 
+// Budget resolves a group's budget, applying the top-level fallback
+func (c RateLimitConfig) Budget(group RateLimitBudget) RateLimitBudget {
+	if group.RequestsPerMinute == 0 {
+		return RateLimitBudget{RequestsPerMinute: c.RequestsPerMinute, Burst: c.Burst}
+	}
+	return group
+}
+
 // CORSConfig contains CORS configuration
 type CORSConfig struct {
 	AllowedOrigins []string `mapstructure:"allowed_origins"`
@@ -82,16 +324,162 @@ type P2PConfig struct {
 	ListenAddrs    []string `mapstructure:"listen_addrs"`
 	BootstrapPeers []string `mapstructure:"bootstrap_peers"`
 	Rendezvous     string   `mapstructure:"rendezvous"`
+
+	Reputation ReputationConfig `mapstructure:"reputation"`
+}
+
+// ReputationConfig controls how reputation scores are kept. They live in
+// memory and changed ones are saved to the database every snapshot
+// interval, and on shutdown. Summaries pulled from trusted peers are
+// blended in, so a new node doesn't start with a blank view.
+type ReputationConfig struct {
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"`
+	TrustedPeers     []string      `mapstructure:"trusted_peers"` // peer IDs whose summaries are merged; empty disables the exchange
+	SyncInterval     time.Duration `mapstructure:"sync_interval"`
+	LocalWeight      float64       `mapstructure:"local_weight"`     // share of a score from local observations, 0-1
+	ProofDifficulty  int           `mapstructure:"proof_difficulty"` // proof-of-work bits asked of unestablished authors; 0 disables
+}
+
+// MetricsConfig controls the Prometheus endpoint on the main HTTP server
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+}
+
+// TracingConfig exports OpenTelemetry traces over OTLP/HTTP
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Endpoint    string  `mapstructure:"endpoint"`     // collector host:port, e.g. localhost:4318
+	Insecure    bool    `mapstructure:"insecure"`     // plain HTTP to the collector
+	SampleRatio float64 `mapstructure:"sample_ratio"` // share of new traces recorded, 0-1
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+// PprofConfig exposes the Go runtime profiles. With no listen address they
+// are served to admins under /api/v1/admin/debug/pprof; with one, on a
+// separate loopback-only listener instead.
+type PprofConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	ListenAddr string `mapstructure:"listen_addr"` // e.g. 127.0.0.1:6060
+}
+
+// EventsConfig sends node events (article.created, peer.connected,
+// moderation.applied, ...) somewhere besides the WebSocket and SSE streams
+type EventsConfig struct {
+	Webhooks []WebhookConfig `mapstructure:"webhooks"`
+	// Audit lists event types to record in the audit log
+	Audit []string `mapstructure:"audit"`
+}
+
+// WebhookConfig posts events to a URL as JSON
+type WebhookConfig struct {
+	URL     string        `mapstructure:"url"`
+	Types   []string      `mapstructure:"types"`   // empty means every type
+	Secret  string        `mapstructure:"secret"`  // signs each body with HMAC-SHA256
+	Timeout time.Duration `mapstructure:"timeout"` // per attempt; default 10s
 }
 
+// ClassifierConfig sends every article received from peers to an
+// operator's content classifier. An empty endpoint disables it.
+type ClassifierConfig struct {
+	Endpoint string        `mapstructure:"endpoint"` // http(s):// URL taking JSON, or grpc(s)://host:port
+	Timeout  time.Duration `mapstructure:"timeout"`  // per article
+	Required bool          `mapstructure:"required"` // refuse articles that can't be classified instead of storing them unclassified
+}
+
+// BlocklistsConfig sets how often subscribed blocklists are fetched again.
+// Subscriptions themselves are managed through the admin API.
+type BlocklistsConfig struct {
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// TrustConfig bounds how far trust flows through the users' trust graph
+type TrustConfig struct {
+	MaxDepth int     `mapstructure:"max_depth"` // hops from a user; their own marks are one hop
+	Decay    float64 `mapstructure:"decay"`     // share of trust passed on with each further hop
+}
+
+// AcceptanceConfig is the node's acceptance policy for articles received
+// from peers. Empty lists and zero values accept everything.
+type AcceptanceConfig struct {
+	Categories               []string `mapstructure:"categories"`                 // accepted categories
+	Languages                []string `mapstructure:"languages"`                  // accepted BCP 47 tags; "und" accepts articles without one
+	MaxArticleSize           int      `mapstructure:"max_article_size"`           // bytes of signed content
+	MinAuthorReputation      float64  `mapstructure:"min_author_reputation"`      // 0-100; needs P2P reputation
+	RequireAttachmentsPinned bool     `mapstructure:"require_attachments_pinned"` // pin every attachment before accepting
+}
+
+// AlertsConfig checks the node's health on an interval and notifies each
+// URL in Notify when a rule starts or stops firing
+type AlertsConfig struct {
+	Enabled  bool              `mapstructure:"enabled"`
+	Interval time.Duration     `mapstructure:"interval"`
+	Rules    []AlertRuleConfig `mapstructure:"rules"`
+	// Notify lists webhook (http/https), telegram://, matrix:// and smtp://
+	// URLs. They carry credentials, so they are reported as a digest.
+	Notify []string `mapstructure:"notify"`
+	Secret string   `mapstructure:"secret"` // signs webhook bodies with HMAC-SHA256
+}
+
+// Alert rule kinds
+const (
+	AlertPeersBelow    = "peers_below"    // threshold: minimum connected peers
+	AlertIPFSUnhealthy = "ipfs_unhealthy" // IPFS daemon unreachable
+	AlertDiskAbove     = "disk_above"     // threshold: percent of the disk used
+	AlertSyncStalled   = "sync_stalled"   // article sync missed two rounds
+)
+
+// AlertRuleConfig is one alert rule
+type AlertRuleConfig struct {
+	Kind      string        `mapstructure:"kind"`
+	Name      string        `mapstructure:"name"` // defaults to the kind
+	Threshold float64       `mapstructure:"threshold"`
+	For       time.Duration `mapstructure:"for"`  // how long the problem must last before alerting
+	Path      string        `mapstructure:"path"` // disk_above: defaults to data.root
+}
+
+// RuleName returns the name alerts for the rule are sent under
+func (r AlertRuleConfig) RuleName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Kind
+}
+
+// Overrides holds values supplied on the command line. Non-empty fields
+// take precedence over environment variables and the config file.
+type Overrides struct {
+	ConfigFile string // read this file instead of searching ./configs and .
+	DataRoot   string
+	Profile    string
+	Port       int
+	LogLevel   string
+	P2PEnabled *bool // nil keeps the configured setting
+}
+
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// languageTagPattern matches BCP 47 language tags, e.g. "en" or "pt-BR"
+var languageTagPattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
 // Load loads configuration from file and environment variables
 // Priority: ENV vars > config.yaml > defaults
 func Load() (*Config, error) {
+	return LoadWithOverrides(Overrides{})
+}
+
+// LoadWithOverrides loads configuration and applies command line overrides
+// Priority: flags > ENV vars > config.yaml > defaults
+func LoadWithOverrides(overrides Overrides) (*Config, error) {
 	// Set config file details
-	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./configs")
-	viper.AddConfigPath(".")
+	if overrides.ConfigFile != "" {
+		viper.SetConfigFile(overrides.ConfigFile)
+	} else {
+		viper.SetConfigName("config")
+		viper.AddConfigPath("./configs")
+		viper.AddConfigPath(".")
+	}
 
 	// Set defaults
 	setDefaults()
@@ -108,20 +496,164 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// Layer the profile's settings over the file
+	profile := overrides.Profile
+	if profile == "" {
+		profile = viper.GetString("data.profile")
+	}
+	profileFile = ""
+	if profile != "" {
+		if !profileNamePattern.MatchString(profile) {
+			return nil, fmt.Errorf("invalid configuration: data.profile may only contain letters, digits, '-' and '_', got: %s", profile)
+		}
+		if err := mergeProfileConfig(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	// Secrets mounted as files (Docker and Kubernetes secrets)
+	if err := applySecretFiles(); err != nil {
+		return nil, err
+	}
+
+	// Apply command line overrides
+	previousRoot := viper.GetString("data.root")
+	if overrides.DataRoot != "" {
+		viper.Set("data.root", overrides.DataRoot)
+	}
+	if overrides.Profile != "" {
+		viper.Set("data.profile", overrides.Profile)
+	}
+	if overrides.Port != 0 {
+		viper.Set("server.port", overrides.Port)
+	}
+	if overrides.LogLevel != "" {
+		viper.Set("logging.level", overrides.LogLevel)
+	}
+	if overrides.P2PEnabled != nil {
+		viper.Set("p2p.enabled", *overrides.P2PEnabled)
+	}
+
 	// Unmarshal into config struct
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("unable to decode config: %w", err)
 	}
+	applyNetwork(&cfg)
 
 	// Validate configuration
 	if err := validate(&cfg); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// Stores kept under the configured root follow an overridden one
+	if overrides.DataRoot != "" {
+		cfg.Database.Path = rebasePath(cfg.Database.Path, previousRoot, cfg.Data.Root)
+		cfg.Search.IndexPath = rebasePath(cfg.Search.IndexPath, previousRoot, cfg.Data.Root)
+	}
+	applyProfile(&cfg)
+
 	return &cfg, nil
 }
 
+// secretFileSettings can be read from files. Only these are, so a stray
+// NEWS_<KEY>_FILE variable can't point some other setting at a file.
+var secretFileSettings = []string{
+	"auth.jwt_secret",
+	"database.encryption_key",
+	"ipfs.cluster.password",
+	"ipfs.cluster.token",
+	"alerts.secret",
+}
+
+// applySecretFiles reads secrets from the files named by NEWS_<KEY>_FILE
+// environment variables, e.g. NEWS_AUTH_JWT_SECRET_FILE=/run/secrets/jwt,
+// so they needn't pass through the environment themselves. Trailing
+// newlines are trimmed.
+func applySecretFiles() error {
+	for _, key := range secretFileSettings {
+		env := "NEWS_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		path := os.Getenv(env + "_FILE")
+		if path == "" {
+			continue
+		}
+		if _, ok := os.LookupEnv(env); ok {
+			return fmt.Errorf("both %s and %s_FILE are set", env, env)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE: %w", env, err)
+		}
+		viper.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return nil
+}
+
+// profileFile is the overlay the last load merged, if any
+var profileFile string
+
+// mergeProfileConfig merges the overlay for profile over the config file:
+// config.<profile>.yaml next to config.yaml, or node.<profile>.yaml next to
+// a file given as node.yaml. Without a config file it is looked for in
+// ./configs and the working directory. A profile needn't have one.
+func mergeProfileConfig(profile string) error {
+	dirs, stem := []string{"./configs", "."}, "config"
+	if used := viper.ConfigFileUsed(); used != "" {
+		dirs = []string{filepath.Dir(used)}
+		stem = strings.TrimSuffix(filepath.Base(used), filepath.Ext(used))
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, stem+"."+profile+".yaml")
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading profile config: %w", err)
+		}
+		defer f.Close()
+		if err := viper.MergeConfig(f); err != nil {
+			return fmt.Errorf("error reading profile config %s: %w", path, err)
+		}
+		profileFile = path
+		return nil
+	}
+	return nil
+}
+
+// ProfileFileUsed returns the profile overlay the last load merged over
+// the config file, or "" when there was none
+func ProfileFileUsed() string {
+	return profileFile
+}
+
+// FileUsed returns the config file the last load read, or "" when none
+// was found and only defaults and the environment applied
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// applyProfile moves store paths that live under the data root into the
+// active profile directory. Paths outside the root are left untouched so
+// operators can still place individual stores elsewhere.
+func applyProfile(cfg *Config) {
+	if cfg.Data.Profile == "" {
+		return
+	}
+	cfg.Database.Path = rebasePath(cfg.Database.Path, cfg.Data.Root, cfg.Data.Dir())
+	cfg.Search.IndexPath = rebasePath(cfg.Search.IndexPath, cfg.Data.Root, cfg.Data.Dir())
+}
+
+// rebasePath re-roots path from oldRoot to newRoot when path is inside oldRoot
+func rebasePath(path, oldRoot, newRoot string) string {
+	rel, err := filepath.Rel(filepath.Clean(oldRoot), filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.Join(newRoot, rel)
+}
+
 // setDefaults sets default values for configuration
 func setDefaults() {
 	// Server defaults
@@ -131,33 +663,100 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
 	viper.SetDefault("server.shutdown_timeout", "10s")
+	viper.SetDefault("server.max_batch_size", 100)
+
+	// gRPC defaults
+	viper.SetDefault("grpc.enabled", false)
+	viper.SetDefault("grpc.port", 50051)
 
 	// Database defaults
 	viper.SetDefault("database.mode", "sqlite") // sqlite or distributed
 	viper.SetDefault("database.path", "./data/news.db")
 	viper.SetDefault("database.max_open_conns", 10)
 	viper.SetDefault("database.max_idle_conns", 5)
+	viper.SetDefault("database.cache_size", 1000)
+	viper.SetDefault("database.encryption_key", "")
 
 	// IPFS defaults
 	viper.SetDefault("ipfs.api_endpoint", "http://localhost:5001")
 	viper.SetDefault("ipfs.timeout", "60s")
 	viper.SetDefault("ipfs.pin_articles", true)
+	viper.SetDefault("ipfs.mfs_feed_root", "/newsp2p/feeds")
+	viper.SetDefault("ipfs.timeouts.add", "60s")
+	viper.SetDefault("ipfs.timeouts.cat", "2m")
+	viper.SetDefault("ipfs.timeouts.pin", "5m")
+	viper.SetDefault("ipfs.timeouts.ipns", "2m")
+	viper.SetDefault("ipfs.add.cid_version", 0)
+	viper.SetDefault("ipfs.add.raw_leaves", false)
+	viper.SetDefault("ipfs.add.hash", "sha2-256")
+	viper.SetDefault("ipfs.ipns.pubsub", true)
+	viper.SetDefault("ipfs.ipns.resolve_timeout", "10s")
+	viper.SetDefault("ipfs.ipns.follow", []string{})
+	viper.SetDefault("ipfs.gc.enabled", false)
+	viper.SetDefault("ipfs.gc.interval", "24h")
+	viper.SetDefault("ipfs.gc.revision_retention", "720h") // 30 days
+	viper.SetDefault("ipfs.gc.article_max_age", "0s")
+	viper.SetDefault("ipfs.gc.repo_gc", false)
+	viper.SetDefault("ipfs.pin_retry_interval", "30s")
+	viper.SetDefault("ipfs.pin_reconcile_interval", "1h")
+	viper.SetDefault("ipfs.pin_max_backoff", "1h")
+	viper.SetDefault("ipfs.cluster.endpoint", "")
+	viper.SetDefault("ipfs.cluster.replication_min", 0)
+	viper.SetDefault("ipfs.cluster.replication_max", 0)
+	viper.SetDefault("ipfs.cluster.username", "")
+	viper.SetDefault("ipfs.cluster.password", "")
+	viper.SetDefault("ipfs.cluster.token", "")
 
 	// Auth defaults
+	viper.SetDefault("auth.jwt_secret", "") // required; listed so the environment can set it without a config file
 	viper.SetDefault("auth.jwt_expiry", "24h")
 	viper.SetDefault("auth.refresh_token_expiry", "168h") // 7 days
 	viper.SetDefault("auth.bcrypt_cost", 12)
 
 	// Search defaults
 	viper.SetDefault("search.index_path", "./data/search.bleve")
+	viper.SetDefault("search.max_fuzziness", 2)
+	viper.SetDefault("search.federated_peers", 5)
+	viper.SetDefault("search.federated_timeout", "3s")
+	viper.SetDefault("search.cache_size", 500)
+	viper.SetDefault("search.cache_ttl", "30s")
+	viper.SetDefault("search.optimize.enabled", true)
+	viper.SetDefault("search.optimize.window_start", "03:00")
+	viper.SetDefault("search.optimize.window_end", "05:00")
+	viper.SetDefault("search.optimize.min_segments", 5)
+	viper.SetDefault("search.optimize.min_fragmentation", 0.2)
+
+	// Upload defaults
+	viper.SetDefault("upload.chunk_size", 256*1024)
+	viper.SetDefault("upload.max_sizes", map[string]int64{
+		"image/*":         10 << 20,
+		"audio/*":         100 << 20,
+		"video/*":         500 << 20,
+		"application/pdf": 25 << 20,
+	})
+	viper.SetDefault("upload.temp_dir", "")
+	viper.SetDefault("upload.transcode.command", []string{})
+	viper.SetDefault("upload.transcode.timeout", "30m")
 
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
+	viper.SetDefault("logging.components", map[string]string{})
+	viper.SetDefault("logging.file.path", "")
+	viper.SetDefault("logging.file.max_size_mb", 100)
+	viper.SetDefault("logging.file.max_backups", 5)
+	viper.SetDefault("logging.file.max_age_days", 30)
+	viper.SetDefault("logging.file.compress", true)
 
 	// Rate limit defaults
 	viper.SetDefault("rate_limit.requests_per_minute", 1000)
 	viper.SetDefault("rate_limit.burst", 100)
+	viper.SetDefault("rate_limit.reads.requests_per_minute", 1000)
+	viper.SetDefault("rate_limit.reads.burst", 100)
+	viper.SetDefault("rate_limit.writes.requests_per_minute", 60)
+	viper.SetDefault("rate_limit.writes.burst", 10)
+	viper.SetDefault("rate_limit.search.requests_per_minute", 120)
+	viper.SetDefault("rate_limit.search.burst", 20)
 
 	// CORS defaults
 	viper.SetDefault("cors.allowed_origins", []string{"http://localhost:3000"})
@@ -168,11 +767,115 @@ func setDefaults() {
 		"/ip4/0.0.0.0/tcp/0",
 		"/ip4/0.0.0.0/udp/0/quic-v1",
 	})
-	viper.SetDefault("p2p.bootstrap_peers", []string{
-		"/dnsaddr/bootstrap.libp2p.io/p2p/QmNnooDu7bfjPFoTZYxMNLWUQJyrVwtbZg5gBMjTezGAJN",
-		"/dnsaddr/bootstrap.libp2p.io/p2p/QmQCU2EcMqAqQPR2i9bChDtGNJchTbq5TbXJJ16u19uLTa",
-	})
-	viper.SetDefault("p2p.rendezvous", "newsp2p-network")
+	viper.SetDefault("p2p.bootstrap_peers", []string{}) // empty takes the network's
+	viper.SetDefault("p2p.rendezvous", "")
+	viper.SetDefault("p2p.reputation.snapshot_interval", "5m")
+	viper.SetDefault("p2p.reputation.trusted_peers", []string{})
+	viper.SetDefault("p2p.reputation.sync_interval", "30m")
+	viper.SetDefault("p2p.reputation.local_weight", 0.7)
+	viper.SetDefault("p2p.reputation.proof_difficulty", 20)
+	viper.SetDefault("network.name", NetworkMainnet)
+	viper.SetDefault("network.policy.name", "")
+	viper.SetDefault("network.policy.publisher_key", "")
+	viper.SetDefault("network.policy.interval", "1h")
+
+	// Metrics defaults
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.path", "/metrics")
+
+	// Profiling defaults
+	viper.SetDefault("pprof.enabled", false)
+	viper.SetDefault("pprof.listen_addr", "")
+
+	// Event sink defaults
+	viper.SetDefault("events.webhooks", []map[string]interface{}{})
+	viper.SetDefault("events.audit", []string{})
+
+	// Classifier defaults
+	viper.SetDefault("classifier.endpoint", "")
+	viper.SetDefault("classifier.timeout", "10s")
+	viper.SetDefault("classifier.required", false)
+
+	// Blocklist defaults
+	viper.SetDefault("blocklists.interval", "1h")
+
+	// Trust graph defaults
+	viper.SetDefault("trust.max_depth", 3)
+	viper.SetDefault("trust.decay", 0.5)
+
+	// Acceptance policy defaults: accept everything
+	viper.SetDefault("acceptance.categories", []string{})
+	viper.SetDefault("acceptance.languages", []string{})
+	viper.SetDefault("acceptance.max_article_size", 0)
+	viper.SetDefault("acceptance.min_author_reputation", 0)
+	viper.SetDefault("acceptance.require_attachments_pinned", false)
+
+	// Alerting defaults
+	viper.SetDefault("alerts.enabled", false)
+	viper.SetDefault("alerts.interval", "30s")
+	viper.SetDefault("alerts.rules", []map[string]interface{}{})
+	viper.SetDefault("alerts.notify", []string{})
+	viper.SetDefault("alerts.secret", "")
+
+	// Retention defaults
+	viper.SetDefault("retention.enabled", false)
+	viper.SetDefault("retention.interval", "1h")
+	viper.SetDefault("retention.max_disk_usage", 0)
+	viper.SetDefault("retention.max_synced_articles", 0)
+	viper.SetDefault("retention.categories", map[string]string{})
+
+	// Tracing defaults
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.service_name", "newsp2p")
+
+	// Data directory defaults
+	viper.SetDefault("data.root", "./data")
+	viper.SetDefault("data.profile", "")
+}
+
+func validateAlerts(cfg *AlertsConfig) error {
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("alerts.interval must be positive, got: %s", cfg.Interval)
+	}
+	names := make(map[string]bool)
+	for i, rule := range cfg.Rules {
+		switch rule.Kind {
+		case AlertPeersBelow:
+			if rule.Threshold < 1 {
+				return fmt.Errorf("alerts.rules[%d].threshold must be at least 1 peer, got: %v", i, rule.Threshold)
+			}
+		case AlertDiskAbove:
+			if rule.Threshold <= 0 || rule.Threshold >= 100 {
+				return fmt.Errorf("alerts.rules[%d].threshold must be a percentage between 0 and 100, got: %v", i, rule.Threshold)
+			}
+		case AlertIPFSUnhealthy, AlertSyncStalled:
+		default:
+			return fmt.Errorf("alerts.rules[%d].kind must be one of %s, %s, %s, %s, got: %s",
+				i, AlertPeersBelow, AlertIPFSUnhealthy, AlertDiskAbove, AlertSyncStalled, rule.Kind)
+		}
+		if rule.For < 0 {
+			return fmt.Errorf("alerts.rules[%d].for must be >= 0", i)
+		}
+		if names[rule.RuleName()] {
+			return fmt.Errorf("alerts.rules[%d]: duplicate rule name %q", i, rule.RuleName())
+		}
+		names[rule.RuleName()] = true
+	}
+	for i, target := range cfg.Notify {
+		u, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("alerts.notify[%d] is not a valid URL", i)
+		}
+		switch u.Scheme {
+		case "http", "https", "telegram", "matrix", "smtp":
+		default:
+			return fmt.Errorf("alerts.notify[%d] must be an http(s), telegram, matrix or smtp URL, got scheme: %q", i, u.Scheme)
+		}
+	}
+	return nil
 }
 
 // validate validates the configuration
@@ -186,6 +889,49 @@ func validate(cfg *Config) error {
 	if cfg.Server.Port < 1 || cfg.Server.Port > 65535 {
 		return fmt.Errorf("server.port must be between 1 and 65535, got: %d", cfg.Server.Port)
 	}
+	if cfg.Server.MaxBatchSize < 1 || cfg.Server.MaxBatchSize > 1000 {
+		return fmt.Errorf("server.max_batch_size must be between 1 and 1000, got: %d", cfg.Server.MaxBatchSize)
+	}
+	if cfg.GRPC.Enabled {
+		if cfg.GRPC.Port < 1 || cfg.GRPC.Port > 65535 {
+			return fmt.Errorf("grpc.port must be between 1 and 65535, got: %d", cfg.GRPC.Port)
+		}
+		if cfg.GRPC.Port == cfg.Server.Port {
+			return fmt.Errorf("grpc.port must differ from server.port")
+		}
+	}
+
+	// Validate the network; private ones bring their own rendezvous
+	if !profileNamePattern.MatchString(cfg.Network.Name) {
+		return fmt.Errorf("network.name may only contain letters, digits, '-' and '_', got: %q", cfg.Network.Name)
+	}
+	if cfg.P2P.Enabled && cfg.P2P.Rendezvous == "" {
+		return fmt.Errorf("p2p.rendezvous is required on private network %q", cfg.Network.Name)
+	}
+	if cfg.P2P.Reputation.SnapshotInterval <= 0 {
+		return fmt.Errorf("p2p.reputation.snapshot_interval must be positive, got: %s", cfg.P2P.Reputation.SnapshotInterval)
+	}
+	if cfg.P2P.Reputation.SyncInterval <= 0 {
+		return fmt.Errorf("p2p.reputation.sync_interval must be positive, got: %s", cfg.P2P.Reputation.SyncInterval)
+	}
+	if w := cfg.P2P.Reputation.LocalWeight; w < 0 || w > 1 {
+		return fmt.Errorf("p2p.reputation.local_weight must be between 0 and 1, got: %g", w)
+	}
+	if d := cfg.P2P.Reputation.ProofDifficulty; d < 0 || d > 32 {
+		return fmt.Errorf("p2p.reputation.proof_difficulty must be between 0 and 32, got: %d", d)
+	}
+	if policy := cfg.Network.Policy; policy.Name != "" {
+		if policy.PublisherKey == "" {
+			return fmt.Errorf("network.policy.publisher_key is required to follow a network policy")
+		}
+		if policy.Interval <= 0 {
+			return fmt.Errorf("network.policy.interval must be positive, got: %s", policy.Interval)
+		}
+	}
+
+	if _, err := cfg.Database.EncryptionKeyBytes(); err != nil {
+		return err
+	}
 
 	// Validate JWT secret
 	if cfg.Auth.JWTSecret == "" {
@@ -205,11 +951,24 @@ func validate(cfg *Config) error {
 	if !validLevels[cfg.Logging.Level] {
 		return fmt.Errorf("logging.level must be one of: debug, info, warn, error, got: %s", cfg.Logging.Level)
 	}
+	for component, level := range cfg.Logging.Components {
+		if !validLevels[level] {
+			return fmt.Errorf("logging.components.%s must be one of: debug, info, warn, error, got: %s", component, level)
+		}
+	}
 
 	// Validate logging format
 	if cfg.Logging.Format != "json" && cfg.Logging.Format != "text" {
 		return fmt.Errorf("logging.format must be 'json' or 'text', got: %s", cfg.Logging.Format)
 	}
+	if cfg.Logging.File.Path != "" {
+		if cfg.Logging.File.MaxSizeMB <= 0 {
+			return fmt.Errorf("logging.file.max_size_mb must be positive, got: %d", cfg.Logging.File.MaxSizeMB)
+		}
+		if cfg.Logging.File.MaxBackups < 0 || cfg.Logging.File.MaxAgeDays < 0 {
+			return fmt.Errorf("logging.file.max_backups and max_age_days must not be negative")
+		}
+	}
 
 	// Validate database mode
 	if cfg.Database.Mode != "sqlite" && cfg.Database.Mode != "distributed" {
@@ -221,15 +980,240 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("database.path is required")
 	}
 
+	// Validate cache size
+	if cfg.Database.CacheSize < 0 {
+		return fmt.Errorf("database.cache_size must not be negative, got: %d", cfg.Database.CacheSize)
+	}
+
 	// Validate IPFS endpoint
 	if cfg.IPFS.APIEndpoint == "" {
 		return fmt.Errorf("ipfs.api_endpoint is required")
 	}
 
+	// Validate per-operation timeouts
+	if t := cfg.IPFS.Timeouts; t.Add < 0 || t.Cat < 0 || t.Pin < 0 || t.IPNS < 0 {
+		return fmt.Errorf("ipfs.timeouts must not be negative")
+	}
+
+	// Validate add options
+	if add := cfg.IPFS.Add; add.CIDVersion != 0 && add.CIDVersion != 1 {
+		return fmt.Errorf("ipfs.add.cid_version must be 0 or 1, got: %d", add.CIDVersion)
+	} else if add.CIDVersion == 0 && (add.RawLeaves || add.Hash != "sha2-256") {
+		return fmt.Errorf("ipfs.add.cid_version 0 requires hash sha2-256 without raw_leaves")
+	}
+
+	// Validate the MFS feed mirror
+	if root := cfg.IPFS.MFSFeedRoot; root != "" && (!strings.HasPrefix(root, "/") || path.Clean(root) == "/") {
+		return fmt.Errorf("ipfs.mfs_feed_root must be an absolute MFS path below /, got: %s", root)
+	}
+
+	// Validate IPNS resolution
+	if cfg.IPFS.IPNS.ResolveTimeout <= 0 {
+		return fmt.Errorf("ipfs.ipns.resolve_timeout must be positive")
+	}
+
+	// Validate pin ledger timing
+	if cfg.IPFS.PinArticles {
+		if cfg.IPFS.PinRetryInterval <= 0 || cfg.IPFS.PinReconcileInterval <= 0 {
+			return fmt.Errorf("ipfs.pin_retry_interval and ipfs.pin_reconcile_interval must be positive")
+		}
+		if cfg.IPFS.PinMaxBackoff < cfg.IPFS.PinRetryInterval {
+			return fmt.Errorf("ipfs.pin_max_backoff must be at least ipfs.pin_retry_interval")
+		}
+	}
+
+	// Validate garbage collection policy (it works from the pin ledger)
+	if cfg.IPFS.GC.Enabled {
+		if !cfg.IPFS.PinArticles {
+			return fmt.Errorf("ipfs.gc requires ipfs.pin_articles")
+		}
+		if cfg.IPFS.GC.Interval <= 0 {
+			return fmt.Errorf("ipfs.gc.interval must be positive")
+		}
+	}
+	if cfg.IPFS.GC.RevisionRetention < 0 || cfg.IPFS.GC.ArticleMaxAge < 0 {
+		return fmt.Errorf("ipfs.gc retention periods must not be negative")
+	}
+
+	// Validate cluster replication factor (-1 means every peer)
+	if cluster := cfg.IPFS.Cluster; cluster.Endpoint != "" {
+		if cluster.ReplicationMin < -1 || cluster.ReplicationMax < -1 {
+			return fmt.Errorf("ipfs.cluster replication factors must be -1 or greater")
+		}
+		if cluster.ReplicationMin > 0 && cluster.ReplicationMax > 0 && cluster.ReplicationMax < cluster.ReplicationMin {
+			return fmt.Errorf("ipfs.cluster.replication_max (%d) must be >= replication_min (%d)", cluster.ReplicationMax, cluster.ReplicationMin)
+		}
+	}
+
 	// Validate search index path
 	if cfg.Search.IndexPath == "" {
 		return fmt.Errorf("search.index_path is required")
 	}
 
+	// Validate fuzziness cap (Bleve supports edit distances up to 2)
+	if cfg.Search.MaxFuzziness < 0 || cfg.Search.MaxFuzziness > 2 {
+		return fmt.Errorf("search.max_fuzziness must be between 0 and 2, got: %d", cfg.Search.MaxFuzziness)
+	}
+
+	// Validate federated search settings
+	if cfg.Search.FederatedPeers < 0 {
+		return fmt.Errorf("search.federated_peers must be >= 0, got: %d", cfg.Search.FederatedPeers)
+	}
+
+	if cfg.Search.FederatedPeers > 0 && cfg.Search.FederatedTimeout <= 0 {
+		return fmt.Errorf("search.federated_timeout must be positive")
+	}
+
+	if cfg.Search.CacheSize < 0 {
+		return fmt.Errorf("search.cache_size must be >= 0, got: %d", cfg.Search.CacheSize)
+	}
+
+	// Validate index optimization schedule
+	if cfg.Search.Optimize.Enabled {
+		if _, _, err := cfg.Search.Optimize.Window(); err != nil {
+			return err
+		}
+		if cfg.Search.Optimize.MinFragmentation < 0 || cfg.Search.Optimize.MinFragmentation > 1 {
+			return fmt.Errorf("search.optimize.min_fragmentation must be between 0 and 1, got: %v", cfg.Search.Optimize.MinFragmentation)
+		}
+	}
+
+	// Validate upload limits (kubo rejects chunks above 1MiB)
+	if cfg.Upload.ChunkSize < 1024 || cfg.Upload.ChunkSize > 1<<20 {
+		return fmt.Errorf("upload.chunk_size must be between 1024 and 1048576 bytes, got: %d", cfg.Upload.ChunkSize)
+	}
+	for mimeType, limit := range cfg.Upload.MaxSizes {
+		if !strings.Contains(mimeType, "/") {
+			return fmt.Errorf("upload.max_sizes key must be a MIME type like image/png or image/*, got: %s", mimeType)
+		}
+		if limit <= 0 {
+			return fmt.Errorf("upload.max_sizes[%s] must be positive, got: %d", mimeType, limit)
+		}
+	}
+	if len(cfg.Upload.Transcode.Command) > 0 && cfg.Upload.Transcode.Timeout <= 0 {
+		return fmt.Errorf("upload.transcode.timeout must be positive when a transcode command is set, got: %s", cfg.Upload.Transcode.Timeout)
+	}
+
+	// Validate data directory
+	if cfg.Data.Root == "" {
+		return fmt.Errorf("data.root is required")
+	}
+	if cfg.Data.Profile != "" && !profileNamePattern.MatchString(cfg.Data.Profile) {
+		return fmt.Errorf("data.profile may only contain letters, digits, '-' and '_', got: %s", cfg.Data.Profile)
+	}
+
+	// Validate rate limit budgets
+	if cfg.RateLimit.RequestsPerMinute < 1 {
+		return fmt.Errorf("rate_limit.requests_per_minute must be positive, got: %d", cfg.RateLimit.RequestsPerMinute)
+	}
+	for name, budget := range map[string]RateLimitBudget{
+		"":        {RequestsPerMinute: cfg.RateLimit.RequestsPerMinute, Burst: cfg.RateLimit.Burst},
+		"reads.":  cfg.RateLimit.Reads,
+		"writes.": cfg.RateLimit.Writes,
+		"search.": cfg.RateLimit.Search,
+	} {
+		if budget.RequestsPerMinute < 0 || budget.Burst < 0 {
+			return fmt.Errorf("rate_limit.%srequests_per_minute and burst must be >= 0", name)
+		}
+	}
+
+	// Validate the metrics endpoint
+	if cfg.Metrics.Enabled {
+		if p := cfg.Metrics.Path; !strings.HasPrefix(p, "/") || path.Clean(p) == "/" || strings.HasPrefix(path.Clean(p), "/api/") {
+			return fmt.Errorf("metrics.path must be an absolute path outside /api, got: %s", p)
+		}
+	}
+
+	// The separate profiling listener has no auth, so keep it off the network
+	if cfg.Pprof.Enabled && cfg.Pprof.ListenAddr != "" {
+		host, _, err := net.SplitHostPort(cfg.Pprof.ListenAddr)
+		if err != nil {
+			return fmt.Errorf("pprof.listen_addr: %w", err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("pprof.listen_addr must be a loopback address, got: %s", cfg.Pprof.ListenAddr)
+		}
+	}
+
+	// Validate event webhooks
+	for i, hook := range cfg.Events.Webhooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("events.webhooks[%d].url must be an http(s) URL, got: %s", i, hook.URL)
+		}
+	}
+
+	// Validate the classifier endpoint
+	if endpoint := cfg.Classifier.Endpoint; endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("classifier.endpoint must be an http(s):// or grpc(s):// address, got: %s", endpoint)
+		}
+		switch u.Scheme {
+		case "http", "https", "grpc", "grpcs":
+		default:
+			return fmt.Errorf("classifier.endpoint must be an http(s):// or grpc(s):// address, got: %s", endpoint)
+		}
+		if cfg.Classifier.Timeout <= 0 {
+			return fmt.Errorf("classifier.timeout must be positive, got: %s", cfg.Classifier.Timeout)
+		}
+	}
+
+	// Validate the blocklist refresh interval
+	if cfg.Blocklists.Interval <= 0 {
+		return fmt.Errorf("blocklists.interval must be positive, got: %s", cfg.Blocklists.Interval)
+	}
+
+	// Validate the trust graph bounds; every hop is a database scan per identity reached
+	if cfg.Trust.MaxDepth < 1 || cfg.Trust.MaxDepth > 6 {
+		return fmt.Errorf("trust.max_depth must be between 1 and 6, got: %d", cfg.Trust.MaxDepth)
+	}
+	if cfg.Trust.Decay <= 0 || cfg.Trust.Decay > 1 {
+		return fmt.Errorf("trust.decay must be above 0 and at most 1, got: %g", cfg.Trust.Decay)
+	}
+
+	// Validate the acceptance policy
+	if cfg.Acceptance.MaxArticleSize < 0 {
+		return fmt.Errorf("acceptance.max_article_size must not be negative, got: %d", cfg.Acceptance.MaxArticleSize)
+	}
+	if r := cfg.Acceptance.MinAuthorReputation; r < 0 || r > 100 {
+		return fmt.Errorf("acceptance.min_author_reputation must be between 0 and 100, got: %g", r)
+	}
+	for _, language := range cfg.Acceptance.Languages {
+		if !languageTagPattern.MatchString(language) {
+			return fmt.Errorf("acceptance.languages must hold BCP 47 tags such as en or pt-BR, got: %q", language)
+		}
+	}
+
+	// Validate alert rules and where they are sent
+	if cfg.Alerts.Enabled {
+		if err := validateAlerts(&cfg.Alerts); err != nil {
+			return err
+		}
+	}
+
+	// Validate storage quota and retention
+	if cfg.Retention.Enabled && cfg.Retention.Interval <= 0 {
+		return fmt.Errorf("retention.interval must be positive, got: %s", cfg.Retention.Interval)
+	}
+	if cfg.Retention.MaxDiskUsage < 0 || cfg.Retention.MaxSyncedArticles < 0 {
+		return fmt.Errorf("retention limits must not be negative")
+	}
+	for category, maxAge := range cfg.Retention.Categories {
+		if maxAge <= 0 {
+			return fmt.Errorf("retention.categories.%s must be positive, got: %s", category, maxAge)
+		}
+	}
+
+	// Validate tracing export
+	if cfg.Tracing.Enabled {
+		if cfg.Tracing.Endpoint == "" {
+			return fmt.Errorf("tracing.endpoint is required when tracing is enabled")
+		}
+		if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing.sample_ratio must be between 0 and 1, got: %v", cfg.Tracing.SampleRatio)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,68 @@
+package config
+
+import "time"
+
+// Network is a public newsp2p network a node can join by name
+type Network struct {
+	Rendezvous string
+	// BootstrapPeers are the first peers a new node dials. Each pins its
+	// peer ID, so whoever controls the DNS names can't substitute nodes of
+	// their own. The project runs no bootstrap nodes of its own yet, so
+	// these are the public libp2p ones; /liberation peers are then found
+	// through the rendezvous, the bootstrap cache and the network policy.
+	BootstrapPeers []string
+}
+
+// libp2pBootstrapPeers are the public libp2p bootstrap nodes
+var libp2pBootstrapPeers = []string{
+	"/dnsaddr/bootstrap.libp2p.io/p2p/QmNnooDu7bfjPFoTZYxMNLWUQJyrVwtbZg5gBMjTezGAJN",
+	"/dnsaddr/bootstrap.libp2p.io/p2p/QmQCU2EcMqAqQPR2i9bChDtGNJchTbq5TbXJJ16u19uLTa",
+}
+
+// Built-in network names
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+)
+
+// Networks are the built-in networks. Any other network.name is a private
+// network, which needs p2p.bootstrap_peers and p2p.rendezvous of its own.
+var Networks = map[string]Network{
+	NetworkMainnet: {
+		Rendezvous:     "liberation-news-network",
+		BootstrapPeers: libp2pBootstrapPeers,
+	},
+	NetworkTestnet: {
+		Rendezvous:     "liberation-news-testnet",
+		BootstrapPeers: libp2pBootstrapPeers,
+	},
+}
+
+// NetworkConfig selects the network the node joins
+type NetworkConfig struct {
+	Name   string       `mapstructure:"name"` // mainnet, testnet, or a private network's name
+	Policy PolicyConfig `mapstructure:"policy"`
+}
+
+// PolicyConfig subscribes the node to a network policy document published
+// over IPNS. Documents not signed by PublisherKey are ignored.
+type PolicyConfig struct {
+	Name         string        `mapstructure:"name"`          // IPNS name the policy is published under; empty disables
+	PublisherKey string        `mapstructure:"publisher_key"` // Ed25519 public key, base64 or did:key
+	Interval     time.Duration `mapstructure:"interval"`      // how often the name is resolved again
+}
+
+// applyNetwork fills in the bootstrap peers and rendezvous of a built-in
+// network where the config leaves them empty
+func applyNetwork(cfg *Config) {
+	network, ok := Networks[cfg.Network.Name]
+	if !ok {
+		return
+	}
+	if len(cfg.P2P.BootstrapPeers) == 0 {
+		cfg.P2P.BootstrapPeers = append([]string(nil), network.BootstrapPeers...)
+	}
+	if cfg.P2P.Rendezvous == "" {
+		cfg.P2P.Rendezvous = network.Rendezvous
+	}
+}
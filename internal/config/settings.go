@@ -0,0 +1,103 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// secretSettings are never reported in the clear. Settings records a
+// digest instead, so a changed secret still shows up as a change. So are
+// settings named "secret" wherever they appear, like webhook secrets, and
+// webhook URLs, which often carry their token in the path.
+var secretSettings = map[string]bool{
+	"auth.jwt_secret":         true,
+	"database.encryption_key": true,
+	"ipfs.cluster.password":   true,
+	"ipfs.cluster.token":      true,
+	"alerts.notify":           true,
+}
+
+func isSecret(key string) bool {
+	return secretSettings[key] || strings.HasSuffix(key, ".secret") ||
+		strings.HasPrefix(key, "events.webhooks.") && strings.HasSuffix(key, ".url")
+}
+
+// redactEndpoint drops the credentials an endpoint URL may carry in its
+// userinfo, query or fragment, keeping where it points. host:port
+// endpoints are returned as they are.
+func redactEndpoint(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return value
+	}
+	u.User, u.RawQuery, u.Fragment = nil, "", ""
+	return u.String()
+}
+
+// Settings flattens the configuration into dotted keys as they appear in
+// the config file ("server.port"), with values formatted as text. Lists
+// are comma-separated and maps are sorted key=value pairs. Lists of
+// sections are numbered ("events.webhooks.0.url"). Endpoints are
+// reported without userinfo or query.
+func (c *Config) Settings() map[string]string {
+	settings := make(map[string]string)
+	flatten(settings, "", reflect.ValueOf(*c))
+	for key, value := range settings {
+		switch {
+		case value == "":
+		case isSecret(key):
+			sum := sha256.Sum256([]byte(value))
+			settings[key] = "sha256:" + hex.EncodeToString(sum[:6])
+		case strings.HasSuffix(key, "endpoint"):
+			settings[key] = redactEndpoint(value)
+		}
+	}
+	return settings
+}
+
+func flatten(settings map[string]string, prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			flatten(settings, key, field)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() == reflect.Struct {
+				for j := 0; j < field.Len(); j++ {
+					flatten(settings, fmt.Sprintf("%s.%d", key, j), field.Index(j))
+				}
+				continue
+			}
+			items := make([]string, field.Len())
+			for j := range items {
+				items[j] = fmt.Sprint(field.Index(j).Interface())
+			}
+			settings[key] = strings.Join(items, ",")
+		case reflect.Map:
+			pairs := make([]string, 0, field.Len())
+			iter := field.MapRange()
+			for iter.Next() {
+				pairs = append(pairs, fmt.Sprintf("%v=%v", iter.Key().Interface(), iter.Value().Interface()))
+			}
+			sort.Strings(pairs)
+			settings[key] = strings.Join(pairs, ",")
+		default:
+			settings[key] = fmt.Sprint(field.Interface())
+		}
+	}
+}
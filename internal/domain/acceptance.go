@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrArticleNotAccepted is returned for articles from peers the node's
+// acceptance policy refuses
+var ErrArticleNotAccepted = errors.New("article is refused by the node's acceptance policy")
+
+// Acceptance policy rules
+const (
+	AcceptCategory    = "category"    // the article's category isn't accepted
+	AcceptLanguage    = "language"    // the article's language isn't accepted
+	AcceptSize        = "size"        // the signed article is too large
+	AcceptReputation  = "reputation"  // the author's reputation is too low
+	AcceptAttachments = "attachments" // an attachment couldn't be pinned
+)
+
+// LanguageUndetermined is the BCP 47 tag for an unknown language. Listing
+// it in the accepted languages accepts articles that don't name one.
+const LanguageUndetermined = "und"
+
+// AcceptancePolicy is the node's rules for articles received from peers.
+// A zero field accepts everything for its rule.
+type AcceptancePolicy struct {
+	Categories               []string `json:"categories"`
+	Languages                []string `json:"languages"`
+	MaxArticleSize           int      `json:"max_article_size"` // bytes of signed content
+	MinAuthorReputation      float64  `json:"min_author_reputation"`
+	RequireAttachmentsPinned bool     `json:"require_attachments_pinned"`
+}
+
+// PolicyRefusal explains why the acceptance policy refused an article
+type PolicyRefusal struct {
+	Rule   string
+	Reason string
+}
+
+func (r *PolicyRefusal) Error() string {
+	return fmt.Sprintf("%s: %s", ErrArticleNotAccepted, r.Reason)
+}
+
+// Is makes errors.Is(err, ErrArticleNotAccepted) match any refusal
+func (r *PolicyRefusal) Is(target error) bool {
+	return target == ErrArticleNotAccepted
+}
+
+// AcceptanceStatus is the policy in force and the articles it refused
+// since the node started, by rule
+type AcceptanceStatus struct {
+	Policy  AcceptancePolicy `json:"policy"`
+	Refused map[string]int64 `json:"refused"`
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Appeal states
+const (
+	AppealOpen     = "open"     // waiting for a moderator
+	AppealUpheld   = "upheld"   // the moderation decision stands
+	AppealReversed = "reversed" // the moderation decision was undone
+)
+
+// MaxAppealReasonLength is the maximum appeal reason length in characters
+const MaxAppealReasonLength = 2000
+
+// Appeal is an author's signed request to undo the moderation of one of
+// their articles. The author signs the article ID, their username, the
+// reason and a timestamp with the key the article is signed with, so an
+// author whose account lives on another node can appeal too. Each
+// moderation case can be appealed once.
+type Appeal struct {
+	ID         string          `json:"id"`
+	CaseID     string          `json:"case_id"`
+	ArticleID  string          `json:"article_id"`
+	ArticleCID string          `json:"article_cid"`
+	Author     string          `json:"author"`
+	Reason     string          `json:"reason"`
+	Timestamp  int64           `json:"timestamp"` // when the author signed, Unix seconds
+	Signature  string          `json:"signature"`
+	Status     string          `json:"status"`
+	Decision   *AppealDecision `json:"decision,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// AppealDecision records how an appeal was closed
+type AppealDecision struct {
+	Note      string    `json:"note,omitempty"`
+	Moderator string    `json:"moderator"`
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// Validate validates the appeal fields the author signs
+func (a *Appeal) Validate() error {
+	if a.ArticleID == "" {
+		return NewValidationError("article_id", "article_id is required")
+	}
+	if a.Author == "" {
+		return NewValidationError("author", "author is required")
+	}
+	if strings.TrimSpace(a.Reason) == "" {
+		return NewValidationError("reason", "reason is required")
+	}
+	if len([]rune(a.Reason)) > MaxAppealReasonLength {
+		return NewValidationError("reason", "reason must be at most 2000 characters")
+	}
+	if a.Timestamp <= 0 {
+		return NewValidationError("timestamp", "timestamp is required")
+	}
+	return nil
+}
+
+// GetSignableContent returns the canonical encoding of the fields the
+// author signs
+func (a *Appeal) GetSignableContent() ([]byte, error) {
+	return json.Marshal(struct {
+		ArticleID string `json:"article_id"`
+		Author    string `json:"author"`
+		Reason    string `json:"reason"`
+		Timestamp int64  `json:"timestamp"`
+	}{a.ArticleID, a.Author, a.Reason, a.Timestamp})
+}
+
+// AppealCreateRequest is the body of an appeal by a signed-in author; the
+// node signs it with their key
+type AppealCreateRequest struct {
+	Reason string `json:"reason" binding:"required,max=2000"`
+}
+
+// SignedAppealRequest is the body of an appeal the author signed
+// elsewhere, verified against the key the article is signed with
+type SignedAppealRequest struct {
+	ArticleID string `json:"article_id" binding:"required"`
+	Author    string `json:"author" binding:"required"`
+	Reason    string `json:"reason" binding:"required,max=2000"`
+	Timestamp int64  `json:"timestamp" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// AppealDecisionRequest is the body of an appeal decision
+type AppealDecisionRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// AppealListFilter selects appeals
+type AppealListFilter struct {
+	Status string // empty lists every status
+	Page   int
+	Limit  int
+}
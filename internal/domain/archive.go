@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// ArchiveManifestVersion is the manifest layout written by this node
+const ArchiveManifestVersion = 1
+
+// Archive layout paths, relative to the archive root
+const (
+	ArchiveManifestPath   = "manifest.json"
+	ArchiveArticlesDir    = "articles"
+	ArchiveAttachmentsDir = "attachments"
+)
+
+// MaxArchiveArticles caps how many articles a single export may contain
+const MaxArchiveArticles = 500
+
+// ArchiveManifest describes the contents of a CAR archive
+type ArchiveManifest struct {
+	Version   int                   `json:"version"`
+	CreatedAt time.Time             `json:"created_at"`
+	Articles  []ArchiveArticleEntry `json:"articles"`
+}
+
+// ArchiveArticleEntry records one article and its attachments within an archive
+type ArchiveArticleEntry struct {
+	ID          string   `json:"id"`
+	CID         string   `json:"cid"`
+	Title       string   `json:"title"`
+	Author      string   `json:"author"`
+	Path        string   `json:"path"`
+	Attachments []string `json:"attachments,omitempty"`
+}
+
+// ArchiveImportReport summarises a CAR import
+type ArchiveImportReport struct {
+	Roots    []string `json:"roots"`
+	Imported []string `json:"imported"`
+	Skipped  []string `json:"skipped"`          // already stored locally
+	Failed   []string `json:"failed,omitempty"` // unreadable or badly signed
+	Warnings []string `json:"warnings,omitempty"`
+}
@@ -2,35 +2,83 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 )
 
+// languagePattern matches BCP 47 language tags: a primary language and
+// optional subtags, e.g. "en", "pt-BR" or "zh-Hant-TW"
+var languagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
 // Article represents a news article
 type Article struct {
-	ID           string    `json:"id" db:"id"`
-	CID          string    `json:"cid" db:"cid"` // IPFS content ID
-	Title        string    `json:"title" db:"title" binding:"required,min=1,max=200"`
-	Body         string    `json:"body" db:"body" binding:"required,min=1"`
-	Author       string    `json:"author" db:"author" binding:"required"`
-	AuthorPubKey string    `json:"author_pubkey" db:"author_pubkey"` // For verification
-	OriginIP     string    `json:"origin_ip" db:"origin_ip"`         // Public IP of the author
-	Signature    string    `json:"signature" db:"signature"`         // Article signature
-	Timestamp    time.Time `json:"timestamp" db:"timestamp"`
-	Tags         []string  `json:"tags" db:"tags"` // JSON array in SQLite
-	Category     string    `json:"category" db:"category"`
-	Version      int       `json:"version" db:"version"` // For updates
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID              string            `json:"id" db:"id"`
+	CID             string            `json:"cid" db:"cid"`                     // IPFS content ID
+	NodeCID         string            `json:"node_cid,omitempty" db:"node_cid"` // Latest dag-cbor revision node
+	Title           string            `json:"title" db:"title" binding:"required,min=1,max=200"`
+	Body            string            `json:"body" db:"body" binding:"required,min=1"`
+	Author          string            `json:"author" db:"author" binding:"required"`
+	AuthorPubKey    string            `json:"author_pubkey" db:"author_pubkey"` // For verification
+	OriginIP        string            `json:"origin_ip" db:"origin_ip"`         // Public IP of the author
+	Signature       string            `json:"signature" db:"signature"`         // Article signature
+	Timestamp       time.Time         `json:"timestamp" db:"timestamp"`
+	Tags            []string          `json:"tags" db:"tags"` // JSON array in SQLite
+	Category        string            `json:"category" db:"category"`
+	Media           []MediaAttachment `json:"media,omitempty" db:"media"` // Attached audio/video
+	Version         int               `json:"version" db:"version"`       // For updates
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+	Language        string            `json:"language,omitempty" db:"language"`               // BCP 47 tag, e.g. "en" or "pt-BR"; signed, empty when unknown
+	Circle          string            `json:"circle,omitempty" db:"circle"`                   // ID of the circle the article is encrypted for; signed
+	Visibility      string            `json:"visibility,omitempty" db:"visibility"`           // set by local moderation; ignored on copies from peers
+	Labels          []string          `json:"labels,omitempty" db:"labels"`                   // set by local filter lists; ignored on copies from peers
+	Classifications []Classification  `json:"classifications,omitempty" db:"classifications"` // set by the local content classifier; ignored on copies from peers
+}
+
+// Hidden reports whether moderation hid or a filter list quarantined the
+// article on this node
+func (a *Article) Hidden() bool {
+	return a.Visibility == VisibilityHidden || a.Visibility == VisibilityQuarantined
+}
+
+// Listed reports whether the article appears in listings and search.
+// Circle articles are only listed to their circle's members.
+func (a *Article) Listed() bool {
+	return a.Visibility == "" && a.Circle == ""
+}
+
+// InCircle reports whether the article is published to a circle, so it
+// is only served to members and never in plaintext to peers
+func (a *Article) InCircle() bool {
+	return a.Circle != ""
+}
+
+// Clone returns a copy that shares no slices with a, so either can be
+// changed without affecting the other
+func (a *Article) Clone() *Article {
+	copied := *a
+	copied.Tags = slices.Clone(a.Tags)
+	copied.Media = slices.Clone(a.Media)
+	copied.Labels = slices.Clone(a.Labels)
+	copied.Classifications = slices.Clone(a.Classifications)
+	return &copied
 }
 
 // SignableContent represents the content to be signed
 type SignableContent struct {
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
-	Author    string    `json:"author"`
-	Timestamp time.Time `json:"timestamp"`
-	Tags      []string  `json:"tags"`
-	Category  string    `json:"category"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Author    string            `json:"author"`
+	Timestamp time.Time         `json:"timestamp"`
+	Tags      []string          `json:"tags"`
+	Category  string            `json:"category"`
+	Language  string            `json:"language,omitempty"`
+	Circle    string            `json:"circle,omitempty"`
+	Media     []MediaAttachment `json:"media,omitempty"` // omitted when empty, like language, so older signatures still verify
 }
 
 // GetSignableContent returns the canonical content for signing
@@ -42,6 +90,9 @@ func (a *Article) GetSignableContent() ([]byte, error) {
 		Timestamp: a.Timestamp,
 		Tags:      a.Tags,
 		Category:  a.Category,
+		Language:  a.Language,
+		Circle:    a.Circle,
+		Media:     a.Media,
 	}
 	return json.Marshal(content)
 }
@@ -98,12 +149,24 @@ func (a *Article) Validate() error {
 		return NewValidationError("category", "invalid category")
 	}
 
+	if a.Language != "" && !languagePattern.MatchString(a.Language) {
+		return NewValidationError("language", "language must be a BCP 47 tag such as en or pt-BR")
+	}
+
+	if len(a.Media) > MaxArticleMedia {
+		return NewValidationError("media", fmt.Sprintf("maximum %d media attachments allowed", MaxArticleMedia))
+	}
+
 	return nil
 }
 
-// ToJSON converts article to JSON
+// ToJSON converts article to the JSON published to IPFS. Visibility,
+// labels and classifications are this node's own state, so they are left
+// out and don't change the CID.
 func (a *Article) ToJSON() ([]byte, error) {
-	return json.Marshal(a)
+	published := *a
+	published.Visibility, published.Labels, published.Classifications = "", nil, nil
+	return json.Marshal(&published)
 }
 
 // FromJSON parses JSON into article
@@ -115,12 +178,82 @@ func FromJSON(data []byte) (*Article, error) {
 	return &article, nil
 }
 
+// maxSlugLength bounds slugs so long titles still make usable file names
+const maxSlugLength = 80
+
+// Slug derives a lowercase, hyphen-separated file name from the title.
+// Titles with no usable characters fall back to the article ID.
+func (a *Article) Slug() string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(a.Title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sep := hyphen && b.Len() > 0
+			if n := b.Len() + len(string(r)); n > maxSlugLength || (sep && n+1 > maxSlugLength) {
+				return b.String()
+			}
+			if sep {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+	if b.Len() == 0 {
+		return a.ID
+	}
+	return b.String()
+}
+
 // ArticleCreateRequest represents a request to create an article
 type ArticleCreateRequest struct {
 	Title    string   `json:"title" binding:"required,min=1,max=200"`
 	Body     string   `json:"body" binding:"required,min=1"`
 	Tags     []string `json:"tags"`
 	Category string   `json:"category"`
+	Language string   `json:"language"` // BCP 47 tag, e.g. "en"
+	Circle   string   `json:"circle"`   // ID of a circle to publish to, encrypted for its members
+	Media    []string `json:"media"`    // CIDs of audio/video uploaded through /upload/media
+}
+
+// ArticleBatchRequest represents a request to create several articles.
+// Items are validated one by one, so a bad item does not reject the batch.
+type ArticleBatchRequest struct {
+	Articles []ArticleCreateRequest `json:"articles" binding:"required,min=1"`
+}
+
+// ArticleBatchResult is the outcome of one item of a batch, in request order
+type ArticleBatchResult struct {
+	Index   int      `json:"index"`
+	Article *Article `json:"article,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// ArticleFetchRequest asks the node to find an article it may not have yet
+type ArticleFetchRequest struct {
+	CID string `json:"cid" binding:"required"`
+}
+
+// Where a fetched article was found
+const (
+	FetchSourceLocal = "local" // already stored on this node
+	FetchSourceIPFS  = "ipfs"
+	FetchSourceDHT   = "dht"  // a peer the DHT lists as a provider
+	FetchSourcePeer  = "peer" // a connected peer asked directly
+)
+
+// ArticleFetchResult is a fetched article and where it came from. Cached
+// is set when the fetch stored the article locally; Pinned lists the CIDs
+// a seed handed to the pin ledger.
+type ArticleFetchResult struct {
+	Article *Article `json:"article"`
+	Source  string   `json:"source"`
+	Peer    string   `json:"peer,omitempty"`
+	Cached  bool     `json:"cached"`
+	Pinned  []string `json:"pinned,omitempty"`
 }
 
 // ArticleUpdateRequest represents a request to update an article
@@ -129,15 +262,35 @@ type ArticleUpdateRequest struct {
 	Body     string   `json:"body" binding:"omitempty,min=1"`
 	Tags     []string `json:"tags"`
 	Category string   `json:"category"`
+	Language string   `json:"language"`
+	Media    []string `json:"media"` // replaces the attachments; [] removes them all
 }
 
 // ArticleListFilter represents filters for listing articles
 type ArticleListFilter struct {
 	Author   string
+	Authors  []string // any of these authors, e.g. a reader's trust network
 	Category string
 	Tags     []string
+	Circle   string // only this circle's articles, which are otherwise left out
 	FromDate time.Time
 	ToDate   time.Time
 	Page     int
 	Limit    int
+
+	// After switches to keyset pagination: only articles that come after
+	// this one in newest-first order are listed, and Page is ignored
+	After *ArticleCursor
+}
+
+// TagCount is a tag and the number of articles carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// ArticleCursor is a position in the newest-first article order
+type ArticleCursor struct {
+	Timestamp time.Time
+	ID        string
 }
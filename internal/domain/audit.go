@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditModerationResolve = "moderation.resolve" // a moderator upheld a report
+	AuditModerationDismiss = "moderation.dismiss" // a moderator dismissed a report or case
+	AuditModerationAction  = "moderation.action"  // a moderator hid, unlisted or unpinned an article
+	AuditModerationReverse = "moderation.reverse" // a moderator undid an action on appeal
+	AuditAppealFile        = "appeal.file"        // an author appealed a moderation decision
+	AuditAppealUphold      = "appeal.uphold"      // a moderator let the decision stand
+	AuditAppealReverse     = "appeal.reverse"     // a moderator undid the decision
+	AuditUserDeactivate    = "user.deactivate"    // a user was banned from logging in and posting
+	AuditUserActivate      = "user.activate"      // a ban was lifted
+	AuditRoleGrant         = "role.grant"         // a user became an admin or moderator
+	AuditRoleRevoke        = "role.revoke"        // a user stopped being one
+	AuditConfigChange      = "config.change"      // a setting differs from the last start
+	AuditFilterChange      = "filter.change"      // a content filter list was saved or deleted
+	AuditBlocklistChange   = "blocklist.change"   // a blocklist subscription was saved or removed
+)
+
+// AuditActorSystem is the actor of entries the node records itself, such
+// as configuration changes found at startup
+const AuditActorSystem = "system"
+
+// AuditEntry is one record in the append-only audit log. Each entry's hash
+// covers its content and the previous entry's hash, so editing or deleting
+// an entry breaks the chain from that point on.
+type AuditEntry struct {
+	Seq       uint64            `json:"seq"`
+	Time      time.Time         `json:"time"`
+	Actor     string            `json:"actor"` // username, or AuditActorSystem
+	ActorID   string            `json:"actor_id,omitempty"`
+	Action    string            `json:"action"`
+	Target    string            `json:"target,omitempty"` // what was acted on: article, user or setting
+	Details   map[string]string `json:"details,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+}
+
+// ComputeHash returns the hex SHA-256 of the entry's JSON without its own
+// hash. Map keys marshal in sorted order, so the result is stable.
+func (e *AuditEntry) ComputeHash() string {
+	unhashed := *e
+	unhashed.Hash = ""
+	data, _ := json.Marshal(&unhashed)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditListFilter selects audit entries; empty fields match everything
+type AuditListFilter struct {
+	Action string
+	Actor  string // username or user ID
+	Target string
+	Page   int
+	Limit  int
+}
+
+// AuditVerifyReport is the result of walking the hash chain
+type AuditVerifyReport struct {
+	Entries  uint64 `json:"entries"`
+	Valid    bool   `json:"valid"`
+	BrokenAt uint64 `json:"broken_at,omitempty"` // first entry that fails to verify
+	Problem  string `json:"problem,omitempty"`
+	Head     string `json:"head,omitempty"` // hash of the last entry
+}
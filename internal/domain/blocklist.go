@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Blocklist is a list of authors, nodes and articles a curator publishes
+// on IPFS or IPNS, signed with the curator's Ed25519 key. Nodes that
+// subscribe refuse connections from the listed peers and articles by the
+// listed authors or with the listed CIDs.
+type Blocklist struct {
+	Version   int       `json:"version"` // increases with every edition; older ones are refused
+	IssuedAt  time.Time `json:"issued_at"`
+	DIDs      []string  `json:"dids,omitempty"`     // authors, as did:key or base64 Ed25519 keys
+	PeerIDs   []string  `json:"peer_ids,omitempty"` // libp2p nodes
+	CIDs      []string  `json:"cids,omitempty"`     // articles
+	Signature string    `json:"signature"`
+}
+
+// SignableContent returns the canonical bytes the signature covers: the
+// document without its signature
+func (b *Blocklist) SignableContent() ([]byte, error) {
+	unsigned := *b
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// BlocklistSubscription is a curator's blocklist the operator follows,
+// with the last edition that verified
+type BlocklistSubscription struct {
+	Name        string     `json:"name"`
+	Source      string     `json:"source"`  // /ipns/<name>, or /ipfs/<cid> for a fixed edition
+	Curator     string     `json:"curator"` // Ed25519 public key, base64 or did:key
+	Description string     `json:"description,omitempty"`
+	List        *Blocklist `json:"list,omitempty"` // the edition in force
+	FetchedAt   *time.Time `json:"fetched_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Validate validates the subscription's name and source
+func (s *BlocklistSubscription) Validate() error {
+	if !listName.MatchString(s.Name) {
+		return NewValidationError("name", "name must be 1-64 lowercase letters, digits, '-' or '_'")
+	}
+	if source := strings.TrimSuffix(s.Source, "/"); source == "" || source == "/ipfs" || source == "/ipns" {
+		return NewValidationError("source", "source must be an IPNS name or an /ipfs/ path")
+	}
+	return nil
+}
+
+// BlocklistRequest is the body that subscribes to a blocklist or changes
+// a subscription
+type BlocklistRequest struct {
+	Source      string `json:"source" binding:"required"`
+	Curator     string `json:"curator" binding:"required"`
+	Description string `json:"description"`
+}
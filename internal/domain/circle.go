@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Circle limits
+const (
+	MaxCircleNameLength = 100
+	MaxCircleMembers    = 256
+)
+
+// Circle is a group whose articles are encrypted for its members. Every
+// membership change starts a new epoch with a fresh key, so removed
+// members can't read what is published after they leave and new members
+// can't read what was published before they joined. Only the owner changes
+// membership; other nodes learn the circle from its signed key messages.
+type Circle struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Owner     string         `json:"owner"`
+	OwnerKey  string         `json:"owner_key"` // owner's Ed25519 public key, which signs key messages
+	Members   []CircleMember `json:"members"`   // the owner included
+	Epoch     int            `json:"epoch"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// CircleMember is a member and the keys they are known by
+type CircleMember struct {
+	Username      string `json:"username"`
+	PublicKey     string `json:"public_key"`     // Ed25519, as on their articles
+	EncryptionKey string `json:"encryption_key"` // X25519, derived from the Ed25519 key
+}
+
+// Member returns the member username is known as, if any
+func (c *Circle) Member(username string) (CircleMember, bool) {
+	for _, member := range c.Members {
+		if member.Username == username {
+			return member, true
+		}
+	}
+	return CircleMember{}, false
+}
+
+// IsMember reports whether the user with username and publicKey belongs
+// to the circle
+func (c *Circle) IsMember(username, publicKey string) bool {
+	member, ok := c.Member(username)
+	return ok && member.PublicKey == publicKey
+}
+
+// CircleKeyCard is the key a user receives circle keys with, signed with
+// their Ed25519 key. Users share their card with circle owners on other
+// nodes.
+type CircleKeyCard struct {
+	Username      string `json:"username" binding:"required"`
+	PublicKey     string `json:"public_key" binding:"required"`
+	EncryptionKey string `json:"encryption_key" binding:"required"`
+	Signature     string `json:"signature" binding:"required"`
+}
+
+// GetSignableContent returns the canonical card encoding without the
+// signature
+func (c *CircleKeyCard) GetSignableContent() ([]byte, error) {
+	unsigned := *c
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// Member returns the circle member the card describes
+func (c *CircleKeyCard) Member() CircleMember {
+	return CircleMember{Username: c.Username, PublicKey: c.PublicKey, EncryptionKey: c.EncryptionKey}
+}
+
+// CircleKeyGrant is a circle key encrypted for one member with an
+// ephemeral X25519 key
+type CircleKeyGrant struct {
+	Member    string `json:"member"` // the member's Ed25519 public key
+	Ephemeral string `json:"ephemeral"`
+	Key       string `json:"key"`
+}
+
+// CircleKeyMessage distributes a circle's key for one epoch, with the
+// membership it was made for. It is signed by the owner and is the record
+// every node keeps of the circle.
+type CircleKeyMessage struct {
+	CircleID  string           `json:"circle_id"`
+	Name      string           `json:"name"`
+	Owner     string           `json:"owner"`
+	OwnerKey  string           `json:"owner_key"`
+	Epoch     int              `json:"epoch"`
+	Members   []CircleMember   `json:"members"`
+	Grants    []CircleKeyGrant `json:"grants"`
+	Timestamp int64            `json:"timestamp"`
+	Signature string           `json:"signature"`
+}
+
+// Validate validates the key message fields
+func (m *CircleKeyMessage) Validate() error {
+	if m.CircleID == "" {
+		return NewValidationError("circle_id", "circle_id is required")
+	}
+	if m.Owner == "" || m.OwnerKey == "" {
+		return NewValidationError("owner", "owner and owner_key are required")
+	}
+	if m.Epoch < 1 {
+		return NewValidationError("epoch", "epoch must be positive")
+	}
+	if len(m.Members) > MaxCircleMembers {
+		return NewValidationError("members", fmt.Sprintf("maximum %d members allowed", MaxCircleMembers))
+	}
+	return nil
+}
+
+// GetSignableContent returns the canonical key message encoding without
+// the signature
+func (m *CircleKeyMessage) GetSignableContent() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// Grant returns the key encrypted for the member with publicKey, if any
+func (m *CircleKeyMessage) Grant(publicKey string) (CircleKeyGrant, bool) {
+	for _, grant := range m.Grants {
+		if grant.Member == publicKey {
+			return grant, true
+		}
+	}
+	return CircleKeyGrant{}, false
+}
+
+// Circle returns the circle as of this message
+func (m *CircleKeyMessage) Circle() *Circle {
+	return &Circle{
+		ID:       m.CircleID,
+		Name:     m.Name,
+		Owner:    m.Owner,
+		OwnerKey: m.OwnerKey,
+		Members:  m.Members,
+		Epoch:    m.Epoch,
+	}
+}
+
+// CircleKeyAAD binds a wrapped circle key to its circle, epoch and member
+func CircleKeyAAD(circleID string, epoch int, member string) []byte {
+	return fmt.Appendf(nil, "newsp2p circle key %s %d %s", circleID, epoch, member)
+}
+
+// SealedArticle is a circle article encrypted with its circle's key for
+// an epoch. It is what is uploaded to IPFS and gossiped in place of the
+// article, whose signed JSON is the plaintext.
+type SealedArticle struct {
+	CircleID   string `json:"circle_id"`
+	Epoch      int    `json:"epoch"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// AAD binds the ciphertext to its circle and epoch
+func (s *SealedArticle) AAD() []byte {
+	return fmt.Appendf(nil, "newsp2p circle article %s %d", s.CircleID, s.Epoch)
+}
+
+// CircleMemberRequest adds a member: a user on this node by username, or
+// anyone by the key card they shared
+type CircleMemberRequest struct {
+	Username string         `json:"username"`
+	Card     *CircleKeyCard `json:"card"`
+}
+
+// CircleCreateRequest represents a request to create a circle. The owner
+// is always a member.
+type CircleCreateRequest struct {
+	Name    string                `json:"name" binding:"required,max=100"`
+	Members []CircleMemberRequest `json:"members"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+// DefaultClassificationScore is the score from which a classification
+// counts: it is searchable as a label, and matches filter lists that don't
+// set their own minimum
+const DefaultClassificationScore = 0.5
+
+// Classification is a label a content classifier gave an article, such as
+// nsfw or toxic, with the model's confidence from 0 to 1
+type Classification struct {
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+// ClassificationScore returns the article's score for label, and whether
+// the classifier gave one
+func (a *Article) ClassificationScore(label string) (float64, bool) {
+	for _, c := range a.Classifications {
+		if c.Label == label {
+			return c.Score, true
+		}
+	}
+	return 0, false
+}
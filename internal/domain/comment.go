@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Comment represents a reader comment on an article
+type Comment struct {
+	ID        string    `json:"id"`
+	ArticleID string    `json:"article_id"`
+	ParentID  string    `json:"parent_id,omitempty"` // Set for replies
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MaxCommentLength is the maximum comment body length in characters
+const MaxCommentLength = 5000
+
+// Validate validates the comment fields
+func (c *Comment) Validate() error {
+	if c.ArticleID == "" {
+		return NewValidationError("article_id", "article_id is required")
+	}
+	if c.Body == "" {
+		return NewValidationError("body", "body is required")
+	}
+	if len([]rune(c.Body)) > MaxCommentLength {
+		return NewValidationError("body", "body must be at most 5000 characters")
+	}
+	if c.Author == "" {
+		return NewValidationError("author", "author is required")
+	}
+	return nil
+}
+
+// CommentCreateRequest is the body of a new comment or reply
+type CommentCreateRequest struct {
+	Body     string `json:"body" binding:"required,max=5000"`
+	ParentID string `json:"parent_id"`
+}
+
+// CommentUpdateRequest is the body of a comment edit
+type CommentUpdateRequest struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "encoding/json"
+
+// MaxEndorsementNoteLength is the maximum endorsement note length in characters
+const MaxEndorsementNoteLength = 280
+
+// Endorsement is a signed statement that one user vouches for another.
+// EndorserDID is the endorser's Ed25519 public key, so any node can verify
+// the signature; Endorser and Subject are usernames, the DIDs reputation
+// is kept under. A newer record from the same endorser for the same
+// subject replaces the older one, and a Revoked record withdraws it.
+type Endorsement struct {
+	Endorser    string `json:"endorser"`
+	EndorserDID string `json:"endorser_did"`
+	Subject     string `json:"subject"`
+	Note        string `json:"note,omitempty"`
+	Revoked     bool   `json:"revoked,omitempty"`
+	Timestamp   int64  `json:"timestamp"`
+	Signature   string `json:"signature"`
+}
+
+// Validate validates the endorsement fields
+func (e *Endorsement) Validate() error {
+	if e.Endorser == "" {
+		return NewValidationError("endorser", "endorser is required")
+	}
+	if e.EndorserDID == "" {
+		return NewValidationError("endorser_did", "endorser_did is required")
+	}
+	if e.Subject == "" {
+		return NewValidationError("subject", "subject is required")
+	}
+	if e.Subject == e.Endorser {
+		return NewValidationError("subject", "users can't endorse themselves")
+	}
+	if len([]rune(e.Note)) > MaxEndorsementNoteLength {
+		return NewValidationError("note", "note must be at most 280 characters")
+	}
+	return nil
+}
+
+// GetSignableContent returns the canonical endorsement encoding without the
+// signature
+func (e *Endorsement) GetSignableContent() ([]byte, error) {
+	unsigned := *e
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// EndorsementRequest is the body of an endorsement
+type EndorsementRequest struct {
+	Note string `json:"note" binding:"max=280"`
+}
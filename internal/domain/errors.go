@@ -26,6 +26,7 @@ var (
 	ErrArticleAlreadyExists = errors.New("article already exists")
 	ErrInvalidArticle       = errors.New("invalid article")
 	ErrInvalidSignature     = errors.New("invalid article signature")
+	ErrArticleDeleted       = errors.New("article was deleted by its author")
 
 	// User errors
 	ErrUserNotFound       = errors.New("user not found")
@@ -49,7 +50,57 @@ var (
 	ErrIPFSUnavailable   = errors.New("IPFS service unavailable")
 	ErrIPFSUploadFailed  = errors.New("IPFS upload failed")
 	ErrIPNSPublishFailed = errors.New("IPNS publish failed")
+	ErrIPNSResolveFailed = errors.New("IPNS resolve failed")
+	ErrIPNSKeyFailed     = errors.New("IPNS key operation failed")
+	ErrInvalidIPNSName   = errors.New("invalid IPNS name")
 	ErrInvalidCID        = errors.New("invalid CID")
+	ErrCIDMismatch       = errors.New("content does not match its CID")
+	ErrPinNotFound       = errors.New("pin not found in ledger")
+
+	// Upload errors
+	ErrUploadTooLarge   = errors.New("upload exceeds the size limit for its type")
+	ErrUnsupportedMedia = errors.New("unsupported media type")
+	ErrUploadNotFound   = errors.New("upload not found")
+	ErrMediaNotFound    = errors.New("media not found")
+
+	// Comment errors
+	ErrCommentNotFound = errors.New("comment not found")
+
+	// Moderation errors
+	ErrReportNotFound  = errors.New("report not found")
+	ErrReportClosed    = errors.New("report has already been resolved or dismissed")
+	ErrAlreadyReported = errors.New("article already reported by this user")
+	ErrCaseNotFound    = errors.New("moderation case not found")
+	ErrCaseClosed      = errors.New("moderation case has already been decided")
+
+	// Appeal errors
+	ErrAppealNotFound = errors.New("appeal not found")
+	ErrAppealClosed   = errors.New("appeal has already been decided")
+	ErrAppealExists   = errors.New("moderation case has already been appealed")
+	ErrNotAppealable  = errors.New("article has no moderation decision to appeal")
+
+	// Content filter errors
+	ErrFilterListNotFound = errors.New("filter list not found")
+
+	// Blocklist errors
+	ErrBlocklistNotFound  = errors.New("blocklist subscription not found")
+	ErrBlocklistSignature = errors.New("blocklist is not signed by the subscription's curator")
+	ErrBlocklistOutdated  = errors.New("blocklist is older than the one in force")
+	ErrArticleBlocked     = errors.New("article is on a subscribed blocklist")
+
+	// Endorsement errors
+	ErrEndorsementNotFound = errors.New("endorsement not found")
+	ErrNotEstablished      = errors.New("only users with an established reputation can endorse")
+
+	// Trust graph errors
+	ErrTrustEdgeNotFound = errors.New("trust edge not found")
+
+	// Circle errors
+	ErrCircleNotFound       = errors.New("circle not found")
+	ErrNotCircleMember      = errors.New("user is not a member of the circle")
+	ErrNotCircleOwner       = errors.New("only the circle's owner can change its members")
+	ErrCircleMemberExists   = errors.New("user is already a member of the circle")
+	ErrCircleKeyUnavailable = errors.New("no key for the circle's epoch is held on this node")
 
 	// Validation errors
 	ErrValidationFailed = errors.New("validation failed")
@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+// Event types pushed to real-time clients
+const (
+	EventArticleCreated    = "article.created"  // published on this node
+	EventArticleReceived   = "article.received" // arrived from a peer and passed verification
+	EventVoteTally         = "vote.tally"
+	EventPeerConnected     = "peer.connected"
+	EventPeerDisconnected  = "peer.disconnected"
+	EventSyncProgress      = "sync.progress"
+	EventModerationApplied = "moderation.applied" // a moderator closed a report or case
+)
+
+// Event types for network input that has not been verified. They are only
+// delivered to sinks that ask for them.
+const (
+	EventArticleGossip = "p2p.article" // *Article from the articles topic
+)
+
+// Event is one notification on the node's event bus. IDs increase
+// monotonically for the lifetime of the process.
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// VoteTally is the running vote count for one article
+type VoteTally struct {
+	ArticleID string  `json:"article_id"`
+	Up        int     `json:"up"`
+	Down      int     `json:"down"`
+	Score     int     `json:"score"`
+	Weighted  float64 `json:"weighted"` // Score with each vote weighted by the voter's reputation
+}
+
+// PeerEvent reports a peer joining or leaving
+type PeerEvent struct {
+	PeerID string `json:"peer_id"`
+	Peers  int    `json:"peers"` // connected peers after the change
+}
+
+// ModerationEvent reports a moderation decision taken on this node
+type ModerationEvent struct {
+	ReportID  string `json:"report_id,omitempty"` // set for a single report
+	CaseID    string `json:"case_id,omitempty"`   // set for a case
+	ArticleID string `json:"article_id"`
+	Status    string `json:"status"`           // resolved or dismissed for a report; actioned, dismissed or reversed for a case
+	Action    string `json:"action,omitempty"` // hide, unlist or unpin for an actioned or reversed case
+	Moderator string `json:"moderator"`
+	Broadcast bool   `json:"broadcast"`
+}
+
+// SyncProgress reports the outcome of pulling articles from one peer
+type SyncProgress struct {
+	PeerID   string `json:"peer_id"`
+	Received int    `json:"received"`
+	New      int    `json:"new"`
+	Error    string `json:"error,omitempty"`
+}
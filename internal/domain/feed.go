@@ -1,9 +1,24 @@
 package domain
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 )
 
+// feedNamePattern keeps feed names usable as IPNS key names and MFS
+// directory names
+var feedNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,49}$`)
+
+// reservedFeedNames can't name a feed: "self" is the IPFS node's own key
+// and "resolve" and "seed" are routes under /feeds
+var reservedFeedNames = map[string]bool{"self": true, "resolve": true, "seed": true}
+
+// MaxFeedSyncInterval is the longest sync interval in minutes. Feeds are
+// published with a 24h record lifetime, so a feed republished less often
+// than this would stop resolving in between.
+const MaxFeedSyncInterval = 12 * 60
+
 // Feed represents a collection of articles published to IPNS
 type Feed struct {
 	ID           string    `json:"id" db:"id"`
@@ -11,6 +26,7 @@ type Feed struct {
 	IPNSKey      string    `json:"ipns_key" db:"ipns_key"`                         // IPNS key name
 	IPNSAddress  string    `json:"ipns_address" db:"ipns_address"`                 // /ipns/...
 	LastCID      string    `json:"last_cid" db:"last_cid"`                         // Latest feed CID
+	DirectoryCID string    `json:"directory_cid,omitempty" db:"directory_cid"`     // Browsable MFS mirror of the feed
 	LastSync     time.Time `json:"last_sync" db:"last_sync"`
 	SyncInterval int       `json:"sync_interval" db:"sync_interval"` // Minutes
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
@@ -19,11 +35,14 @@ type Feed struct {
 
 // Validate validates the feed fields
 func (f *Feed) Validate() error {
-	if f.Name == "" || len(f.Name) > 50 {
-		return ErrInvalidFeed
+	if !feedNamePattern.MatchString(f.Name) {
+		return fmt.Errorf("%w: name must be 1-50 letters, digits, '-' or '_', starting with a letter or digit", ErrInvalidFeed)
 	}
-	if f.SyncInterval < 1 {
-		return ErrInvalidFeed
+	if reservedFeedNames[f.Name] {
+		return fmt.Errorf("%w: name %q is reserved", ErrInvalidFeed, f.Name)
+	}
+	if f.SyncInterval < 1 || f.SyncInterval > MaxFeedSyncInterval {
+		return fmt.Errorf("%w: sync_interval must be between 1 and %d minutes", ErrInvalidFeed, MaxFeedSyncInterval)
 	}
 	return nil
 }
@@ -37,6 +56,39 @@ type FeedManifest struct {
 	Signature   string    `json:"signature"` // Feed signature
 }
 
+// RemoteFeed is another node's feed, resolved through its IPNS name
+type RemoteFeed struct {
+	Name       string        `json:"name"` // /ipns/...
+	CID        string        `json:"cid"`  // manifest CID the name currently points at
+	Manifest   *FeedManifest `json:"manifest"`
+	ResolvedAt time.Time     `json:"resolved_at"`
+	Pubsub     bool          `json:"pubsub"` // resolved with IPNS-over-PubSub active
+}
+
+// FeedSeedRequest asks the node to mirror another node's feed
+type FeedSeedRequest struct {
+	Name string `json:"name" binding:"required"` // IPNS name of the feed
+}
+
+// FeedSeedReport is the outcome of mirroring a remote feed: every article
+// its manifest lists is stored locally and pinned
+type FeedSeedReport struct {
+	Name        string            `json:"name"`         // /ipns/...
+	ManifestCID string            `json:"manifest_cid"` // manifest the name pointed at
+	Articles    int               `json:"articles"`     // CIDs the manifest lists
+	Fetched     []string          `json:"fetched"`      // stored by this run
+	Present     int               `json:"present"`      // already stored
+	Pinned      int               `json:"pinned"`       // content and attachment CIDs handed to the pin ledger
+	Failed      []FeedSeedFailure `json:"failed,omitempty"`
+	Pubsub      bool              `json:"pubsub"` // updates to the name are pushed to this node
+}
+
+// FeedSeedFailure is a manifest entry that could not be mirrored
+type FeedSeedFailure struct {
+	CID   string `json:"cid"`
+	Error string `json:"error"`
+}
+
 // FeedCreateRequest represents a request to create a feed
 type FeedCreateRequest struct {
 	Name         string `json:"name" binding:"required,min=1,max=50"`
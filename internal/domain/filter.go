@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Content filter actions, weakest first
+const (
+	FilterTag        = "tag"        // the article is accepted and labelled
+	FilterQuarantine = "quarantine" // the article is stored out of sight and queued for moderators
+	FilterReject     = "reject"     // the article is refused
+)
+
+// VisibilityQuarantined marks an article a filter list held back for
+// moderators; like a hidden one it is neither served, listed nor searched
+const VisibilityQuarantined = "quarantined"
+
+// listName restricts filter list and blocklist subscription names to what
+// reads well in a URL and a label
+var listName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// FilterList is a named set of phrases and regular expressions screened
+// against articles published here or received from peers. Phrases match
+// whole words case-insensitively; patterns are Go regular expressions.
+// Classifier labels match articles the content classifier scored at
+// MinScore or above.
+type FilterList struct {
+	Name            string    `json:"name"`
+	Description     string    `json:"description,omitempty"`
+	Categories      []string  `json:"categories,omitempty"` // article categories it applies to; empty applies to all
+	Phrases         []string  `json:"phrases,omitempty"`
+	Patterns        []string  `json:"patterns,omitempty"`
+	Classifications []string  `json:"classifications,omitempty"`
+	MinScore        float64   `json:"min_score,omitempty"` // 0 uses DefaultClassificationScore
+	Action          string    `json:"action"`
+	Label           string    `json:"label,omitempty"` // set by tag; defaults to the list name
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Validate validates the list, compiling every pattern
+func (l *FilterList) Validate() error {
+	if !listName.MatchString(l.Name) {
+		return NewValidationError("name", "name must be 1-64 lowercase letters, digits, '-' or '_'")
+	}
+	switch l.Action {
+	case FilterReject, FilterQuarantine, FilterTag:
+	default:
+		return NewValidationError("action", "action must be one of reject, quarantine, tag")
+	}
+	if len(l.Phrases) == 0 && len(l.Patterns) == 0 && len(l.Classifications) == 0 {
+		return NewValidationError("phrases", "a list needs at least one phrase, pattern or classification")
+	}
+	for _, phrase := range l.Phrases {
+		if phrase == "" {
+			return NewValidationError("phrases", "phrases can't be empty")
+		}
+	}
+	for _, pattern := range l.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return NewValidationError("patterns", fmt.Sprintf("invalid pattern %q: %v", pattern, err))
+		}
+	}
+	for _, label := range l.Classifications {
+		if label == "" {
+			return NewValidationError("classifications", "classifications can't be empty")
+		}
+	}
+	if l.MinScore < 0 || l.MinScore > 1 {
+		return NewValidationError("min_score", "min_score must be between 0 and 1")
+	}
+	for _, category := range l.Categories {
+		if category == "" || !AllowedCategories[category] {
+			return NewValidationError("categories", fmt.Sprintf("unknown category %q", category))
+		}
+	}
+	return nil
+}
+
+// FilterListRequest is the body that creates or replaces a filter list
+type FilterListRequest struct {
+	Description     string   `json:"description"`
+	Categories      []string `json:"categories"`
+	Phrases         []string `json:"phrases"`
+	Patterns        []string `json:"patterns"`
+	Classifications []string `json:"classifications"`
+	MinScore        float64  `json:"min_score"`
+	Action          string   `json:"action" binding:"required"`
+	Label           string   `json:"label"`
+}
+
+// FilterMatch is one list that matched an article
+type FilterMatch struct {
+	List   string `json:"list"`
+	Action string `json:"action"`
+	Match  string `json:"match"` // the phrase, pattern or classification that matched
+}
+
+// FilterVerdict is the outcome of screening an article: the strongest
+// action among the matching lists and the labels of those that tag
+type FilterVerdict struct {
+	Action  string        `json:"action"`
+	Labels  []string      `json:"labels,omitempty"`
+	Matches []FilterMatch `json:"matches"`
+}
+
+// FilterCheckRequest is an article draft screened without publishing it,
+// with the classifications a classifier might give it
+type FilterCheckRequest struct {
+	Title           string           `json:"title"`
+	Body            string           `json:"body"`
+	Tags            []string         `json:"tags"`
+	Category        string           `json:"category"`
+	Classifications []Classification `json:"classifications"`
+}
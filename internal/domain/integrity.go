@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// Integrity issue kinds reported by the verification job
+const (
+	IssueInvalidSignature = "invalid_signature"
+	IssueCIDMismatch      = "cid_mismatch"
+	IssueMissingIndex     = "missing_index"
+	IssueDanglingIndex    = "dangling_index"
+	IssueCorruptRecord    = "corrupt_record"
+)
+
+// IntegrityIssue describes a single inconsistency found in stored data
+type IntegrityIssue struct {
+	Kind      string `json:"kind"`
+	ArticleID string `json:"article_id,omitempty"`
+	CID       string `json:"cid,omitempty"`
+	Key       string `json:"key,omitempty"` // Badger key, for index issues
+	Detail    string `json:"detail"`
+	Repaired  bool   `json:"repaired"`
+}
+
+// IntegrityReport summarises a verification run
+type IntegrityReport struct {
+	StartedAt       time.Time        `json:"started_at"`
+	FinishedAt      time.Time        `json:"finished_at"`
+	Repair          bool             `json:"repair"`
+	ArticlesChecked int              `json:"articles_checked"`
+	CIDsChecked     int              `json:"cids_checked"`
+	Issues          []IntegrityIssue `json:"issues"`
+	Repaired        int              `json:"repaired"`
+	Warnings        []string         `json:"warnings,omitempty"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Media processing states
+const (
+	MediaProcessing = "processing" // stored; the transcoder is still running
+	MediaReady      = "ready"
+	MediaFailed     = "failed" // transcoding failed; the original is still usable
+)
+
+// MaxArticleMedia bounds how many audio/video files one article may attach
+const MaxArticleMedia = 10
+
+// Media is an audio or video file uploaded to IPFS, with what was learned
+// about it while it streamed in
+type Media struct {
+	CID        string           `json:"cid"`
+	Owner      string           `json:"owner"` // uploading user ID
+	MimeType   string           `json:"mime_type"`
+	Size       int64            `json:"size"`
+	Duration   float64          `json:"duration,omitempty"` // seconds; 0 when the container wasn't recognised
+	State      string           `json:"state"`
+	Error      string           `json:"error,omitempty"`
+	Renditions []MediaRendition `json:"renditions,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// MediaRendition is a transcoded copy of an uploaded file
+type MediaRendition struct {
+	Name     string `json:"name"` // file name the transcoder chose
+	CID      string `json:"cid"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+}
+
+// MediaAttachment is the signed reference an article carries to an uploaded
+// audio or video file
+type MediaAttachment struct {
+	CID      string  `json:"cid"`
+	MimeType string  `json:"mime_type"`
+	Size     int64   `json:"size"`
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// Attachment returns the reference an article stores for m
+func (m *Media) Attachment() MediaAttachment {
+	return MediaAttachment{CID: m.CID, MimeType: m.MimeType, Size: m.Size, Duration: m.Duration}
+}
+
+// IsTimedMedia reports whether a MIME type is audio or video, the uploads
+// that are probed for a duration and handed to the transcoder
+func IsTimedMedia(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "audio/") || strings.HasPrefix(mimeType, "video/")
+}
@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+// Moderation case states
+const (
+	CaseOpen      = "open"      // reports are waiting for a moderator
+	CaseReviewing = "reviewing" // a moderator has taken the case
+	CaseActioned  = "actioned"  // a moderator acted on the article
+	CaseDismissed = "dismissed" // a moderator found nothing to act on
+	CaseReversed  = "reversed"  // the action was undone on appeal
+)
+
+// Moderation case decisions, applied to the article on this node
+const (
+	CaseActionHide   = "hide"   // the article is no longer served, listed or searchable
+	CaseActionUnlist = "unlist" // the article is served by ID or CID but not listed or searchable
+	CaseActionUnpin  = "unpin"  // the article's content is unpinned from IPFS
+)
+
+// Article visibility set by moderation. Empty means public.
+const (
+	VisibilityHidden   = "hidden"
+	VisibilityUnlisted = "unlisted"
+)
+
+// ModerationCase aggregates the reports against one article until a
+// moderator closes it. A report on an article whose last case was closed
+// opens a new case.
+type ModerationCase struct {
+	ID         string        `json:"id"`
+	ArticleID  string        `json:"article_id"`
+	ArticleCID string        `json:"article_cid"`
+	Author     string        `json:"author"`
+	Status     string        `json:"status"`
+	ReportIDs  []string      `json:"report_ids"`
+	Reasons    []string      `json:"reasons"` // distinct report reasons, first seen first
+	Assignee   string        `json:"assignee,omitempty"`
+	AssignedAt *time.Time    `json:"assigned_at,omitempty"`
+	Decision   *CaseDecision `json:"decision,omitempty"`
+	Reversal   *CaseDecision `json:"reversal,omitempty"` // how the action was undone on appeal
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// CaseDecision records how a case was closed
+type CaseDecision struct {
+	Action    string    `json:"action,omitempty"` // hide, unlist or unpin; empty when dismissed
+	Note      string    `json:"note,omitempty"`
+	Moderator string    `json:"moderator"`
+	Broadcast bool      `json:"broadcast,omitempty"` // the outcome was shared with the network
+	Penalized bool      `json:"penalized,omitempty"` // the author's reputation took the report penalty
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// Closed reports whether a moderator has decided the case
+func (c *ModerationCase) Closed() bool {
+	return c.Status == CaseActioned || c.Status == CaseDismissed || c.Status == CaseReversed
+}
+
+// IsCaseAction reports whether action is a decision a case can apply
+func IsCaseAction(action string) bool {
+	switch action {
+	case CaseActionHide, CaseActionUnlist, CaseActionUnpin:
+		return true
+	}
+	return false
+}
+
+// CaseAssignRequest is the body of a case assignment. An empty moderator
+// assigns the case to the caller.
+type CaseAssignRequest struct {
+	Moderator string `json:"moderator"`
+}
+
+// CaseDecisionRequest is the body of a case decision
+type CaseDecisionRequest struct {
+	Action    string `json:"action" binding:"omitempty,oneof=hide unlist unpin"` // required to action, ignored to dismiss
+	Note      string `json:"note" binding:"max=1000"`
+	Broadcast bool   `json:"broadcast"` // publish the outcome to peers
+}
+
+// CaseListFilter selects moderation cases
+type CaseListFilter struct {
+	Statuses  []string // empty lists every status
+	Assignee  string
+	ArticleID string
+	Page      int
+	Limit     int
+}
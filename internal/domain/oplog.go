@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Operation types replicated through the article op log
+const (
+	OpPut    = "put"
+	OpDelete = "delete"
+)
+
+// ArticleOp is a single replicated write against the shared article store.
+// Clock and NodeID are chosen by the relaying node, so ops are ordered by
+// what the author signed instead: an author's deletion buries every
+// version of the article signed no later than it.
+type ArticleOp struct {
+	Type      string           `json:"type"` // "put" or "delete"
+	ArticleID string           `json:"article_id"`
+	Article   *Article         `json:"article,omitempty"`
+	Deletion  *ArticleDeletion `json:"deletion,omitempty"` // author-signed; required on delete ops
+	Clock     uint64           `json:"clock"`              // Lamport clock of the issuing node; not used for ordering
+	NodeID    string           `json:"node_id"`            // Peer ID of the issuing node
+	Timestamp time.Time        `json:"timestamp"`
+	Signature string           `json:"signature"` // Node signature over GetSignableContent
+}
+
+// GetSignableContent returns the canonical op encoding without the signature
+func (op *ArticleOp) GetSignableContent() ([]byte, error) {
+	unsigned := *op
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// ArticleDeletion is an author's signed request to delete their article,
+// carried by delete ops so that peers only apply deletes the author made
+type ArticleDeletion struct {
+	ArticleID    string `json:"article_id"`
+	AuthorPubKey string `json:"author_pubkey"`
+	Timestamp    int64  `json:"timestamp"`
+	Signature    string `json:"signature"`
+}
+
+// GetSignableContent returns the canonical deletion encoding without the
+// signature
+func (d *ArticleDeletion) GetSignableContent() ([]byte, error) {
+	unsigned := *d
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// deletionKey carries an author's signed deletion through the context
+type deletionKey struct{}
+
+// WithArticleDeletion returns ctx carrying the author's signed deletion, so
+// a replicated store can share the delete without the repository
+// interface taking signatures
+func WithArticleDeletion(ctx context.Context, deletion *ArticleDeletion) context.Context {
+	return context.WithValue(ctx, deletionKey{}, deletion)
+}
+
+// ArticleDeletionFrom returns the signed deletion carried by ctx, if any
+func ArticleDeletionFrom(ctx context.Context) (*ArticleDeletion, bool) {
+	deletion, ok := ctx.Value(deletionKey{}).(*ArticleDeletion)
+	return deletion, ok && deletion != nil
+}
+
+// AuthorPubKey returns the key of the author who made the op: the
+// article's signer on puts, the deletion's on deletes
+func (op *ArticleOp) AuthorPubKey() string {
+	switch {
+	case op.Article != nil:
+		return op.Article.AuthorPubKey
+	case op.Deletion != nil:
+		return op.Deletion.AuthorPubKey
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// Pin ledger states
+const (
+	PinPending = "pending" // recorded, not yet confirmed pinned
+	PinPinned  = "pinned"
+	PinFailed  = "failed" // last attempt failed; retried with backoff
+)
+
+// PinRecord is one CID this node intends to keep pinned
+type PinRecord struct {
+	CID         string    `json:"cid"`
+	Ref         string    `json:"ref"` // owning article ID
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"` // consecutive failed attempts
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// PinReconcileReport summarises a comparison of the ledger with the pinset
+type PinReconcileReport struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Checked    int       `json:"checked"`
+	Missing    []string  `json:"missing,omitempty"` // recorded as pinned but absent from the pinset
+	Confirmed  int       `json:"confirmed"`         // pending/failed entries found already pinned
+}
+
+// GC unpin reasons
+const (
+	GCReasonSuperseded = "superseded_revision" // older revision past the retention window
+	GCReasonExpired    = "article_expired"     // article older than the maximum age
+	GCReasonOrphaned   = "orphaned"            // ledger entry for an article that no longer exists
+)
+
+// GCUnpin is one CID removed (or, in a dry run, that would be removed)
+type GCUnpin struct {
+	CID    string `json:"cid"`
+	Ref    string `json:"ref"`
+	Reason string `json:"reason"`
+}
+
+// GCReport summarises a garbage collection run
+type GCReport struct {
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	DryRun         bool      `json:"dry_run"`
+	Unpinned       []GCUnpin `json:"unpinned"`
+	Failed         int       `json:"failed"`
+	RepoGC         bool      `json:"repo_gc"`
+	RemovedBlocks  int       `json:"removed_blocks"`
+	RepoSizeBefore uint64    `json:"repo_size_before,omitempty"`
+	RepoSizeAfter  uint64    `json:"repo_size_after,omitempty"`
+	Reclaimed      uint64    `json:"reclaimed_bytes"`
+	Warnings       []string  `json:"warnings,omitempty"`
+}
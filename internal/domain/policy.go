@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Network policy errors
+var (
+	ErrPolicySignature = errors.New("network policy is not signed by the configured publisher")
+	ErrPolicyOutdated  = errors.New("network policy is older than the one in force")
+)
+
+// NetworkPolicy is a network-wide policy its operators publish over IPNS,
+// signed with the publisher's Ed25519 key. Nodes that subscribe merge it
+// into their runtime configuration.
+type NetworkPolicy struct {
+	Version            int       `json:"version"` // increases with every edition; older ones are refused
+	IssuedAt           time.Time `json:"issued_at"`
+	BootstrapPeers     []string  `json:"bootstrap_peers,omitempty"`      // dialled alongside the configured ones
+	BannedCategories   []string  `json:"banned_categories,omitempty"`    // articles in these are neither accepted nor published
+	MinProtocolVersion string    `json:"min_protocol_version,omitempty"` // e.g. "1.0.0"
+	Signature          string    `json:"signature"`
+}
+
+// SignableContent returns the canonical bytes the signature covers: the
+// document without its signature
+func (p *NetworkPolicy) SignableContent() ([]byte, error) {
+	unsigned := *p
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// CategoryBanned reports whether the policy bans category
+func (p *NetworkPolicy) CategoryBanned(category string) bool {
+	for _, banned := range p.BannedCategories {
+		if banned == category {
+			return true
+		}
+	}
+	return false
+}
+
+// NetworkPolicyStatus reports the policy subscription and the policy in force
+type NetworkPolicyStatus struct {
+	Name            string         `json:"name"`      // IPNS name the policy is fetched from
+	Publisher       string         `json:"publisher"` // key it must be signed with
+	Policy          *NetworkPolicy `json:"policy,omitempty"`
+	FetchedAt       *time.Time     `json:"fetched_at,omitempty"`
+	LastError       string         `json:"last_error,omitempty"`
+	ProtocolVersion string         `json:"protocol_version"` // this node's
+	Compatible      bool           `json:"compatible"`       // meets min_protocol_version
+}
@@ -0,0 +1,77 @@
+package domain
+
+import "time"
+
+// Report states
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"  // a moderator agreed the content is a problem
+	ReportDismissed = "dismissed" // a moderator found nothing to act on
+)
+
+// Report sources
+const (
+	ReportSourceLocal = "local" // filed by a user of this node
+	ReportSourcePeer  = "peer"  // received from another node on the moderation topic
+)
+
+// Moderation actions carried on the P2P moderation topic
+const (
+	ModerationReport  = "report"  // a peer's user reported the article
+	ModerationFlag    = "flag"    // a peer's moderator upheld a report
+	ModerationDismiss = "dismiss" // a peer's moderator found nothing to act on
+)
+
+// MaxReportReasonLength is the maximum report reason length in characters
+const MaxReportReasonLength = 1000
+
+// Report is one entry in the moderation queue
+type Report struct {
+	ID         string     `json:"id"`
+	ArticleID  string     `json:"article_id"`
+	ArticleCID string     `json:"article_cid"`
+	Reporter   string     `json:"reporter"` // user ID for local reports, peer ID for peer reports
+	Reason     string     `json:"reason"`
+	Source     string     `json:"source"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	Broadcast  bool       `json:"broadcast,omitempty"` // the decision was shared with the network
+}
+
+// Validate validates the report fields
+func (r *Report) Validate() error {
+	if r.ArticleID == "" {
+		return NewValidationError("article_id", "article_id is required")
+	}
+	if r.Reason == "" {
+		return NewValidationError("reason", "reason is required")
+	}
+	if len([]rune(r.Reason)) > MaxReportReasonLength {
+		return NewValidationError("reason", "reason must be at most 1000 characters")
+	}
+	if r.Reporter == "" {
+		return NewValidationError("reporter", "reporter is required")
+	}
+	return nil
+}
+
+// ReportCreateRequest is the body of a content report
+type ReportCreateRequest struct {
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
+
+// ReportDecisionRequest is the body of a resolve or dismiss action
+type ReportDecisionRequest struct {
+	Note      string `json:"note" binding:"max=1000"`
+	Broadcast bool   `json:"broadcast"` // emit a moderation message to peers
+}
+
+// ReportListFilter selects reports from the moderation queue
+type ReportListFilter struct {
+	Status string // empty lists every status
+	Page   int
+	Limit  int
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// ReputationScore represents a user's reputation
+type ReputationScore struct {
+	DID          string    `json:"did"`
+	Score        float64   `json:"score"` // 0-100 scale
+	ArticleCount int       `json:"article_count"`
+	UpVotes      int       `json:"up_votes"`
+	DownVotes    int       `json:"down_votes"`
+	ReportCount  int       `json:"report_count"` // Reports against this user
+	LastUpdated  time.Time `json:"last_updated"`
+	Peers        int       `json:"peers,omitempty"`        // trusted peers whose summaries are blended into Score
+	Endorsements int       `json:"endorsements,omitempty"` // established users vouching for this one, counted in Score
+}
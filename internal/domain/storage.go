@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Retention sweep removal reasons
+const (
+	RetentionReasonCategory = "category_expired" // synced article older than its category's retention
+	RetentionReasonCount    = "synced_limit"     // beyond the maximum number of synced articles
+	RetentionReasonDisk     = "disk_quota"       // removed to bring disk usage under the quota
+)
+
+// RetentionRemoval is one synced article removed (or, in a dry run, that
+// would be removed)
+type RetentionRemoval struct {
+	ArticleID string    `json:"article_id"`
+	Category  string    `json:"category"`
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason"`
+}
+
+// RetentionReport summarises a retention sweep
+type RetentionReport struct {
+	StartedAt       time.Time          `json:"started_at"`
+	FinishedAt      time.Time          `json:"finished_at"`
+	DryRun          bool               `json:"dry_run"`
+	Removed         []RetentionRemoval `json:"removed"`
+	Failed          int                `json:"failed"`
+	DiskUsageBefore int64              `json:"disk_usage_before"`
+}
+
+// StorageStats reports how much the node stores against its quotas.
+// Synced articles are those authored by users of other nodes; only they
+// are ever removed by the retention sweeper.
+type StorageStats struct {
+	DiskUsage         int64             `json:"disk_usage"`     // bytes used by the database and search index
+	MaxDiskUsage      int64             `json:"max_disk_usage"` // 0 means unlimited
+	Articles          int               `json:"articles"`
+	SyncedArticles    int               `json:"synced_articles"`
+	MaxSyncedArticles int               `json:"max_synced_articles"` // 0 means unlimited
+	SyncedByCategory  map[string]int    `json:"synced_by_category"`
+	CategoryRetention map[string]string `json:"category_retention"`
+	LastSweep         *RetentionReport  `json:"last_sweep,omitempty"`
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// Trust edge kinds
+const (
+	TrustTrusted = "trusted" // the user vouches for the identity; trust flows through it
+	TrustBlocked = "blocked" // the user wants nothing from the identity
+)
+
+// MaxTrustNoteLength is the maximum trust edge note length in characters
+const MaxTrustNoteLength = 280
+
+// TrustEdge is a user's mark on another identity in this node's trust
+// graph. Truster and Subject are usernames, the identities reputation and
+// endorsements are kept under. A user has at most one edge per subject.
+type TrustEdge struct {
+	Truster   string    `json:"truster"`
+	Subject   string    `json:"subject"`
+	Kind      string    `json:"kind"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate validates the edge fields
+func (e *TrustEdge) Validate() error {
+	if e.Truster == "" {
+		return NewValidationError("truster", "truster is required")
+	}
+	if e.Subject == "" {
+		return NewValidationError("subject", "subject is required")
+	}
+	if e.Subject == e.Truster {
+		return NewValidationError("subject", "users can't mark themselves")
+	}
+	if e.Kind != TrustTrusted && e.Kind != TrustBlocked {
+		return NewValidationError("kind", "kind must be trusted or blocked")
+	}
+	if len([]rune(e.Note)) > MaxTrustNoteLength {
+		return NewValidationError("note", "note must be at most 280 characters")
+	}
+	return nil
+}
+
+// TrustEdgeRequest is the body that marks an identity
+type TrustEdgeRequest struct {
+	Kind string `json:"kind" binding:"required,oneof=trusted blocked"`
+	Note string `json:"note" binding:"max=280"`
+}
+
+// TrustScore is how much a user trusts an identity through the trust
+// graph. Trust is 1 for the user's own edges and decays with every hop
+// through identities they trust, up to the graph's depth. An identity is
+// blocked when a block reaches it with at least as much weight as its
+// trust.
+type TrustScore struct {
+	Subject string  `json:"subject"`
+	Trust   float64 `json:"trust"`
+	Depth   int     `json:"depth,omitempty"` // hops from the user on the strongest path; 0 when out of reach
+	Via     string  `json:"via,omitempty"`   // the user's own contact the strongest path starts with
+	Blocked bool    `json:"blocked,omitempty"`
+}
+
+// TrustFeedItem is an article in a user's personalized feed, with the
+// user's trust in its author
+type TrustFeedItem struct {
+	*Article
+	AuthorTrust TrustScore `json:"author_trust"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+// Upload progress states
+const (
+	UploadPending   = "pending"
+	UploadUploading = "uploading"
+	UploadDone      = "done"
+	UploadFailed    = "failed"
+)
+
+// UploadProgress is a snapshot of a streaming upload, pushed to listeners
+// as bytes reach IPFS
+type UploadProgress struct {
+	ID       string  `json:"id"`
+	State    string  `json:"state"`
+	Sent     int64   `json:"sent"`
+	Total    int64   `json:"total,omitempty"` // 0 when the client didn't declare a size
+	Percent  float64 `json:"percent,omitempty"`
+	MimeType string  `json:"mime_type,omitempty"`
+	CID      string  `json:"cid,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	Media    *Media  `json:"media,omitempty"` // catalog record of a finished audio/video upload
+}
+
+// Finished reports whether no further updates will follow
+func (p UploadProgress) Finished() bool {
+	return p.State == UploadDone || p.State == UploadFailed
+}
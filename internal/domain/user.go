@@ -17,6 +17,7 @@ type User struct {
 	PublicKey    string    `json:"public_key" db:"public_key"` // Ed25519 public key
 	PrivateKey   string    `json:"-" db:"private_key"`         // Encrypted, never expose
 	IsActive     bool      `json:"is_active" db:"is_active"`
+	Language     string    `json:"language,omitempty" db:"language"` // Web UI language, e.g. "en"
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -65,6 +66,7 @@ type UserResponse struct {
 	Email     string    `json:"email"`
 	PublicKey string    `json:"public_key"`
 	IsActive  bool      `json:"is_active"`
+	Language  string    `json:"language,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -76,6 +78,7 @@ func (u *User) ToResponse() *UserResponse {
 		Email:     u.Email,
 		PublicKey: u.PublicKey,
 		IsActive:  u.IsActive,
+		Language:  u.Language,
 		CreatedAt: u.CreatedAt,
 	}
 }
@@ -0,0 +1,44 @@
+package domain
+
+// Revision chain states
+const (
+	ChainVerified    = "verified"    // every revision back to the first verifies under the same key
+	ChainKeyChanged  = "key_changed" // revisions verify, but not all under the current key
+	ChainBroken      = "broken"      // a revision failed verification or doesn't follow on from the next
+	ChainTruncated   = "truncated"   // verified as far as the walk got; older revisions weren't checked
+	ChainUnavailable = "unavailable" // no revision graph, or it couldn't be fetched
+)
+
+// ArticleVerification explains whether an article can be trusted: who
+// signed it, what exactly was signed and whether its history holds up
+type ArticleVerification struct {
+	CID         string      `json:"cid"`
+	ArticleID   string      `json:"article_id"`
+	Valid       bool        `json:"valid"` // signature over the current content
+	Error       string      `json:"error,omitempty"`
+	ContentHash string      `json:"content_hash"` // hex SHA-256 of the signed content
+	Signer      Signer      `json:"signer"`
+	Chain       ChainStatus `json:"chain"`
+}
+
+// Signer identifies the key an article was signed with
+type Signer struct {
+	PublicKey   string `json:"public_key"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	DID         string `json:"did,omitempty"`
+	PeerID      string `json:"peer_id,omitempty"`
+	KnownUser   bool   `json:"known_user"`         // the key belongs to a user registered on this node
+	Username    string `json:"username,omitempty"` // that user, when known
+	// AuthorMatches reports whether the known user is the article's byline
+	AuthorMatches bool `json:"author_matches"`
+}
+
+// ChainStatus summarises a walk of an article's revision graph
+type ChainStatus struct {
+	Status    string `json:"status"`
+	Head      string `json:"head,omitempty"`      // latest revision node
+	Revisions int    `json:"revisions"`           // nodes walked
+	Verified  int    `json:"verified"`            // of which verified
+	BrokenAt  string `json:"broken_at,omitempty"` // first node that didn't hold up
+	Error     string `json:"error,omitempty"`
+}
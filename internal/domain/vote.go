@@ -0,0 +1,47 @@
+package domain
+
+import "encoding/json"
+
+// MaxVoteReasonLength is the maximum vote reason length in characters
+const MaxVoteReasonLength = 280
+
+// Vote is a signed up or down vote on an article. VoterDID is the voter's
+// Ed25519 public key, so any node can verify the signature.
+type Vote struct {
+	ArticleID string `json:"article_id"`
+	VoterDID  string `json:"voter_did"`
+	Value     int    `json:"vote"` // +1 or -1
+	Reason    string `json:"reason,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+}
+
+// Validate validates the vote fields
+func (v *Vote) Validate() error {
+	if v.ArticleID == "" {
+		return NewValidationError("article_id", "article_id is required")
+	}
+	if v.VoterDID == "" {
+		return NewValidationError("voter_did", "voter_did is required")
+	}
+	if v.Value != 1 && v.Value != -1 {
+		return NewValidationError("vote", "vote must be 1 or -1")
+	}
+	if len([]rune(v.Reason)) > MaxVoteReasonLength {
+		return NewValidationError("reason", "reason must be at most 280 characters")
+	}
+	return nil
+}
+
+// GetSignableContent returns the canonical vote encoding without the signature
+func (v *Vote) GetSignableContent() ([]byte, error) {
+	unsigned := *v
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// VoteRequest is the body of a vote
+type VoteRequest struct {
+	Vote   int    `json:"vote" binding:"required,oneof=1 -1"`
+	Reason string `json:"reason" binding:"max=280"`
+}
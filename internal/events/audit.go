@@ -0,0 +1,55 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// AuditRecorder appends to the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, action, target string, details map[string]string) error
+}
+
+// AuditSink records events in the audit log under their own type as the
+// action, attributed to the node itself. It is for events nobody is
+// otherwise accountable for, such as peers connecting; moderation
+// decisions are recorded by the moderation service with the moderator
+// who made them.
+type AuditSink struct {
+	audit AuditRecorder
+}
+
+// NewAuditSink creates a sink recording into audit
+func NewAuditSink(audit AuditRecorder) *AuditSink {
+	return &AuditSink{audit: audit}
+}
+
+// HandleEvent records one event. Its data's top-level fields become the
+// entry's details; article_id or peer_id, if present, becomes its target.
+func (s *AuditSink) HandleEvent(ctx context.Context, event domain.Event) error {
+	details := map[string]string{"event_id": fmt.Sprint(event.ID)}
+
+	var fields map[string]interface{}
+	if data, err := json.Marshal(event.Data); err == nil {
+		json.Unmarshal(data, &fields)
+	}
+	for key, value := range fields {
+		switch v := value.(type) {
+		case string:
+			details[key] = v
+		case map[string]interface{}, []interface{}:
+			// Nested values such as whole articles are left out
+		default:
+			details[key] = fmt.Sprint(v)
+		}
+	}
+
+	target := details["article_id"]
+	if target == "" {
+		target = details["peer_id"]
+	}
+	return s.audit.Record(ctx, event.Type, target, details)
+}
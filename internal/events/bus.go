@@ -0,0 +1,233 @@
+// Package events fans node events out to the parties interested in them:
+// WebSocket and SSE clients subscribe for as long as they are connected,
+// and sinks (webhooks, metrics, the audit log, services consuming network
+// input) are attached for the life of the node.
+package events
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// eventBuffer is how many events a subscriber may fall behind by before
+// further events are dropped for it
+const eventBuffer = 64
+
+// sinkBuffer is eventBuffer for sinks, which may block on the network
+const sinkBuffer = 256
+
+// eventHistory is how many recent events are kept for clients resuming a
+// stream after a reconnect
+const eventHistory = 256
+
+// Publisher accepts events. Services depend on this rather than on Bus.
+type Publisher interface {
+	Publish(eventType string, data interface{})
+}
+
+// Sink handles events delivered by a Bus, one at a time and in order.
+// Errors are logged; the event is not redelivered.
+type Sink interface {
+	HandleEvent(ctx context.Context, event domain.Event) error
+}
+
+// SinkFunc adapts a function to Sink
+type SinkFunc func(ctx context.Context, event domain.Event) error
+
+// HandleEvent calls f
+func (f SinkFunc) HandleEvent(ctx context.Context, event domain.Event) error {
+	return f(ctx, event)
+}
+
+// Bus fans node events out to subscribers and sinks. Publishing never
+// blocks: a subscriber or sink that falls behind loses events rather than
+// stalling P2P handlers.
+//
+// Event types under "p2p." carry network input that has not been verified
+// yet. They are only delivered to subscribers and sinks that ask for them
+// by name and are not kept in the history.
+type Bus struct {
+	mu      sync.Mutex
+	nextID  uint64
+	subs    map[*eventSub]struct{}
+	history []domain.Event // oldest first, at most eventHistory long
+	logger  *logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	sinks  sync.WaitGroup
+}
+
+// eventSub is one subscriber and the event types it wants
+type eventSub struct {
+	ch      chan domain.Event
+	types   map[string]bool // empty means every public type
+	sink    string          // name of the sink reading ch, if any
+	dropped int
+}
+
+func (s *eventSub) wants(eventType string) bool {
+	if len(s.types) == 0 {
+		return !IsPrivate(eventType)
+	}
+	return s.types[eventType]
+}
+
+// IsPrivate reports whether events of this type carry unverified network
+// input, which is never streamed to clients unasked
+func IsPrivate(eventType string) bool {
+	return strings.HasPrefix(eventType, "p2p.")
+}
+
+// NewBus creates an event bus with no subscribers
+func NewBus(logger *logger.Logger) *Bus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Bus{
+		subs:   make(map[*eventSub]struct{}),
+		logger: logger.WithComponent("event-bus"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Publish assigns the next event ID and delivers the event to every
+// subscriber and sink interested in its type
+func (b *Bus) Publish(eventType string, data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := domain.Event{ID: b.nextID, Type: eventType, Time: time.Now(), Data: data}
+
+	if !IsPrivate(eventType) {
+		if len(b.history) == eventHistory {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, event)
+	}
+
+	for sub := range b.subs {
+		if !sub.wants(eventType) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped++
+			if sub.dropped == 1 {
+				if sub.sink != "" {
+					b.logger.Warn("Event sink is falling behind; dropping events", "sink", sub.sink, "type", eventType)
+				} else {
+					b.logger.Warn("Event subscriber is falling behind; dropping events", "type", eventType)
+				}
+			}
+		}
+	}
+}
+
+// Subscribe returns a channel of events of the given types (all public
+// types when none are given). Call the returned function to stop
+// listening; it closes the channel.
+func (b *Bus) Subscribe(types ...string) (<-chan domain.Event, func()) {
+	_, events, unsubscribe := b.SubscribeAfter(0, types...)
+	return events, unsubscribe
+}
+
+// SubscribeAfter is Subscribe for a client that has already seen events up
+// to afterID. It also returns the retained events of the given types
+// published since then, with no gap or overlap between them and the
+// channel. Events older than the retained history are not replayed, and an
+// afterID of zero replays nothing.
+func (b *Bus) SubscribeAfter(afterID uint64, types ...string) ([]domain.Event, <-chan domain.Event, func()) {
+	sub := newSub(eventBuffer, types)
+
+	var missed []domain.Event
+	b.mu.Lock()
+	if afterID > 0 {
+		for _, event := range b.history {
+			if event.ID > afterID && sub.wants(event.Type) {
+				missed = append(missed, event)
+			}
+		}
+	}
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return missed, sub.ch, func() { b.remove(sub) }
+}
+
+// AddSink delivers events of the given types (all public types when none
+// are given) to sink on a goroutine of its own until Close
+func (b *Bus) AddSink(name string, sink Sink, types ...string) {
+	sub := newSub(sinkBuffer, types)
+	sub.sink = name
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	b.sinks.Add(1)
+	go func() {
+		defer b.sinks.Done()
+		for event := range sub.ch {
+			if err := sink.HandleEvent(b.ctx, event); err != nil {
+				b.logger.Warn("Event sink failed", "sink", name, "type", event.Type, "event_id", event.ID, "error", err)
+			}
+		}
+	}()
+	b.logger.Debug("Event sink added", "sink", name, "types", types)
+}
+
+// Close detaches every sink, cancelling the context of any event being
+// handled, and waits for them to return. Subscribers are unaffected.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	for sub := range b.subs {
+		if sub.sink != "" {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+	b.mu.Unlock()
+
+	b.cancel()
+	b.sinks.Wait()
+}
+
+// Subscribers returns the number of active subscribers, not counting sinks
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for sub := range b.subs {
+		if sub.sink == "" {
+			n++
+		}
+	}
+	return n
+}
+
+func newSub(buffer int, types []string) *eventSub {
+	sub := &eventSub{
+		ch:    make(chan domain.Event, buffer),
+		types: make(map[string]bool, len(types)),
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	return sub
+}
+
+func (b *Bus) remove(sub *eventSub) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
@@ -0,0 +1,115 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const (
+	// webhookAttempts is how many times a delivery is tried before the
+	// event is given up on
+	webhookAttempts = 3
+	// webhookBackoff is the wait before the first retry, doubling after
+	webhookBackoff = time.Second
+	// webhookTimeout bounds each attempt when no timeout is configured
+	webhookTimeout = 10 * time.Second
+)
+
+// Webhook delivery headers
+const (
+	HeaderEventType = "X-Newsp2p-Event"
+	HeaderEventID   = "X-Newsp2p-Event-Id"
+	// HeaderSignature is "sha256=" and the hex HMAC-SHA256 of the body,
+	// keyed with the webhook's secret
+	HeaderSignature = "X-Newsp2p-Signature"
+)
+
+// WebhookSink POSTs each event to a URL as JSON. Deliveries that fail with
+// a network error or a 5xx are retried with backoff; 4xx responses are not.
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink posting to url. When secret is set, each
+// request is signed in the X-Newsp2p-Signature header.
+func NewWebhookSink(url, secret string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = webhookTimeout
+	}
+	return &WebhookSink{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// HandleEvent delivers one event
+func (s *WebhookSink) HandleEvent(ctx context.Context, event domain.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(ctx, event, body)
+		if err == nil || !retry || attempt == webhookAttempts {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// post makes one delivery attempt, reporting whether a failure is worth
+// retrying
+func (s *WebhookSink) post(ctx context.Context, event domain.Event, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventType, event.Type)
+	req.Header.Set(HeaderEventID, strconv.FormatUint(event.ID, 10))
+	if len(s.secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return false, nil
+}
+
+// Sign returns the X-Newsp2p-Signature value for body, for receivers
+// checking deliveries
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
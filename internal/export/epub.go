@@ -0,0 +1,110 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"text/template"
+)
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var epubTemplates = template.Must(template.New("epub").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`
+{{define "content.opf"}}<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="cid" prefix="newsp2p: https://github.com/amiyamandal-dev/newsp2p#">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="cid">{{xml .CID}}</dc:identifier>
+    <dc:title>{{xml .Title}}</dc:title>
+    <dc:creator>{{xml .Author}}</dc:creator>
+    <dc:language>en</dc:language>
+    <dc:date>{{.Published.UTC.Format "2006-01-02T15:04:05Z"}}</dc:date>
+    <dc:source>ipfs://{{xml .CID}}</dc:source>
+{{- range .Tags}}
+    <dc:subject>{{xml .}}</dc:subject>
+{{- end}}
+{{- if .KeyFingerprint}}
+    <meta property="newsp2p:signature-fingerprint">{{xml .KeyFingerprint}}</meta>
+{{- end}}
+    <meta property="dcterms:modified">{{.Published.UTC.Format "2006-01-02T15:04:05Z"}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="article" href="article.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="article"/>
+  </spine>
+</package>
+{{end}}
+{{define "nav.xhtml"}}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>{{xml .Title}}</title></head>
+<body>
+<nav epub:type="toc"><ol><li><a href="article.xhtml">{{xml .Title}}</a></li></ol></nav>
+</body>
+</html>
+{{end}}`))
+
+// renderEPUB packages doc as a single-chapter EPUB 3 book
+func renderEPUB(d *Document) ([]byte, error) {
+	chapter, err := renderArticlePage(d, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: d.Published})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := mimetype.Write([]byte("application/epub+zip")); err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name     string
+		template string
+		data     []byte
+	}{
+		{name: "META-INF/container.xml", data: []byte(containerXML)},
+		{name: "OEBPS/content.opf", template: "content.opf"},
+		{name: "OEBPS/nav.xhtml", template: "nav.xhtml"},
+		{name: "OEBPS/article.xhtml", data: chapter},
+	}
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: d.Published})
+		if err != nil {
+			return nil, err
+		}
+		if f.template != "" {
+			err = epubTemplates.ExecuteTemplate(w, f.template, d)
+		} else {
+			_, err = w.Write(f.data)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
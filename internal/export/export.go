@@ -0,0 +1,151 @@
+// Package export renders articles as downloadable files. Every format
+// goes through the same goldmark pipeline the web UI uses and carries the
+// author, the fingerprint of the signing key and the CID as metadata, so
+// a reader can check an exported copy against the network.
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+)
+
+// Format is an export file format
+type Format string
+
+// Supported export formats
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatEPUB     Format = "epub"
+	FormatPDF      Format = "pdf"
+)
+
+// ParseFormat parses a format name, defaulting to markdown when empty
+func ParseFormat(s string) (Format, bool) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "", FormatMarkdown:
+		return FormatMarkdown, true
+	case FormatHTML, FormatEPUB, FormatPDF:
+		return f, true
+	}
+	return "", false
+}
+
+// Document is an article prepared for export
+type Document struct {
+	Title          string
+	Author         string
+	CID            string
+	KeyFingerprint string // of the key that signed the article; empty if unknown
+	Published      time.Time
+	Tags           []string
+	Category       string
+	Markdown       string
+	slug           string
+}
+
+// NewDocument prepares an article for export
+func NewDocument(article *domain.Article) *Document {
+	doc := &Document{
+		Title:     article.Title,
+		Author:    article.Author,
+		CID:       article.CID,
+		Published: article.Timestamp.UTC(),
+		Tags:      article.Tags,
+		Category:  article.Category,
+		Markdown:  article.Body,
+		slug:      article.Slug(),
+	}
+	if key, err := crypto.PublicKeyFromString(article.AuthorPubKey); err == nil {
+		doc.KeyFingerprint = crypto.Fingerprint(key)
+	}
+	return doc
+}
+
+// File is a rendered export
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Render renders doc in the given format
+func Render(doc *Document, format Format) (*File, error) {
+	var (
+		data        []byte
+		contentType string
+		ext         string
+		err         error
+	)
+	switch format {
+	case FormatMarkdown:
+		data, contentType, ext = renderMarkdown(doc), "text/markdown; charset=utf-8", "md"
+	case FormatHTML:
+		data, err = renderArticlePage(doc, false)
+		contentType, ext = "text/html; charset=utf-8", "html"
+	case FormatEPUB:
+		data, err = renderEPUB(doc)
+		contentType, ext = "application/epub+zip", "epub"
+	case FormatPDF:
+		data, err = renderPDF(doc)
+		contentType, ext = "application/pdf", "pdf"
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", format, err)
+	}
+	return &File{Name: doc.slug + "." + ext, ContentType: contentType, Data: data}, nil
+}
+
+// metadata lists the provenance fields shown in every format, in order
+func (d *Document) metadata() [][2]string {
+	fields := [][2]string{
+		{"Author", d.Author},
+		{"Published", d.Published.Format(time.RFC3339)},
+		{"CID", d.CID},
+	}
+	if d.KeyFingerprint != "" {
+		fields = append(fields, [2]string{"Signing key", d.KeyFingerprint})
+	}
+	if d.Category != "" {
+		fields = append(fields, [2]string{"Category", d.Category})
+	}
+	if len(d.Tags) > 0 {
+		fields = append(fields, [2]string{"Tags", strings.Join(d.Tags, ", ")})
+	}
+	return fields
+}
+
+// renderMarkdown returns the article body under a YAML front matter block
+func renderMarkdown(d *Document) []byte {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %q\n", d.Title)
+	fmt.Fprintf(&b, "author: %q\n", d.Author)
+	fmt.Fprintf(&b, "date: %s\n", d.Published.Format(time.RFC3339))
+	fmt.Fprintf(&b, "cid: %q\n", d.CID)
+	if d.KeyFingerprint != "" {
+		fmt.Fprintf(&b, "signature_fingerprint: %q\n", d.KeyFingerprint)
+	}
+	if d.Category != "" {
+		fmt.Fprintf(&b, "category: %q\n", d.Category)
+	}
+	if len(d.Tags) > 0 {
+		quoted := make([]string, len(d.Tags))
+		for i, tag := range d.Tags {
+			quoted[i] = fmt.Sprintf("%q", tag)
+		}
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(quoted, ", "))
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(d.Markdown)
+	if !strings.HasSuffix(d.Markdown, "\n") {
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var (
+	// markdown renders XHTML-compatible output so the same HTML can go
+	// into web pages and EPUB chapters
+	markdown  = goldmark.New(goldmark.WithRendererOptions(html.WithXHTML()))
+	sanitizer = bluemonday.UGCPolicy()
+)
+
+// RenderHTML converts article markdown to sanitized HTML. Raw HTML in the
+// source is dropped by goldmark and anything unsafe by the sanitizer.
+func RenderHTML(source string) template.HTML {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return template.HTML(template.HTMLEscapeString(source))
+	}
+	return template.HTML(sanitizer.Sanitize(buf.String()))
+}
+
+// articleTemplate is shared by the HTML export and the EPUB chapter; the
+// EPUB variant adds the XHTML namespace and XML declaration
+var articleTemplate = template.Must(template.New("article").Parse(`{{if .XHTML}}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+{{- else}}<!DOCTYPE html>
+<html lang="en">
+{{- end}}
+<head>
+<meta charset="utf-8"{{if .XHTML}} /{{end}}>
+<title>{{.Doc.Title}}</title>
+<meta name="author" content="{{.Doc.Author}}"{{if .XHTML}} /{{end}}>
+<meta name="newsp2p:cid" content="{{.Doc.CID}}"{{if .XHTML}} /{{end}}>
+{{- if .Doc.KeyFingerprint}}
+<meta name="newsp2p:signature-fingerprint" content="{{.Doc.KeyFingerprint}}"{{if .XHTML}} /{{end}}>
+{{- end}}
+<style>
+body { max-width: 42em; margin: 2em auto; padding: 0 1em; font-family: Georgia, serif; line-height: 1.6; }
+.provenance { font-family: sans-serif; font-size: 0.85em; color: #555; border-top: 1px solid #ddd; border-bottom: 1px solid #ddd; padding: 0.5em 0; }
+.provenance dt { float: left; clear: left; width: 8em; font-weight: bold; }
+.provenance dd { margin-left: 8em; word-break: break-all; }
+pre { overflow-x: auto; background: #f5f5f5; padding: 0.75em; }
+</style>
+</head>
+<body>
+<article>
+<h1>{{.Doc.Title}}</h1>
+<dl class="provenance">
+{{- range .Meta}}
+<dt>{{index . 0}}</dt><dd>{{index . 1}}</dd>
+{{- end}}
+</dl>
+{{.Body}}
+</article>
+</body>
+</html>
+`))
+
+// renderArticlePage renders doc as a standalone page
+func renderArticlePage(d *Document, xhtml bool) ([]byte, error) {
+	var buf bytes.Buffer
+	err := articleTemplate.Execute(&buf, struct {
+		Doc   *Document
+		Meta  [][2]string
+		Body  template.HTML
+		XHTML bool
+	}{d, d.metadata(), RenderHTML(d.Markdown), xhtml})
+	return buf.Bytes(), err
+}
@@ -0,0 +1,395 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Page geometry in points (A4)
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	pageMargin   = 56.0
+	contentWidth = pageWidth - 2*pageMargin
+	listIndent   = 18.0
+)
+
+// pdfFont is one of the standard Type 1 fonts every PDF reader has, so
+// nothing needs embedding
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota
+	fontBold
+	fontItalic
+	fontMono
+)
+
+var baseFonts = []string{"Helvetica", "Helvetica-Bold", "Helvetica-Oblique", "Courier"}
+
+// Advance widths in 1/1000 em for ASCII 32-126
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// width returns the advance width of WinAnsi-encoded s at size
+func (f pdfFont) width(s []byte, size float64) float64 {
+	if f == fontMono {
+		return float64(len(s)) * 600 * size / 1000
+	}
+	table := &helveticaWidths
+	if f == fontBold {
+		table = &helveticaBoldWidths
+	}
+	total := 0
+	for _, c := range s {
+		if c >= 32 && c <= 126 {
+			total += table[c-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// winAnsi maps the non-Latin-1 characters of Windows-1252
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encodeWinAnsi converts s to the encoding of the standard fonts.
+// Characters they cannot show become '?'.
+func encodeWinAnsi(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			out = append(out, ' ', ' ', ' ', ' ')
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		case winAnsi[r] != 0:
+			out = append(out, winAnsi[r])
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// pdfLayout flows text into pages top to bottom
+type pdfLayout struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func (l *pdfLayout) page() *bytes.Buffer {
+	if len(l.pages) == 0 {
+		l.newPage()
+	}
+	return l.pages[len(l.pages)-1]
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, new(bytes.Buffer))
+	l.y = pageHeight - pageMargin
+}
+
+// reserve moves down by height, starting a new page if it does not fit
+func (l *pdfLayout) reserve(height float64) {
+	if len(l.pages) == 0 || l.y-height < pageMargin {
+		l.newPage()
+	}
+	l.y -= height
+}
+
+func (l *pdfLayout) space(height float64) {
+	if len(l.pages) > 0 && l.y-height >= pageMargin {
+		l.y -= height
+	}
+}
+
+// line draws one line of already-encoded text
+func (l *pdfLayout) line(font pdfFont, size, x float64, s []byte, gray float64) {
+	l.reserve(size * 1.4)
+	fmt.Fprintf(l.page(), "BT %.3g g /F%d %.3g Tf %.2f %.2f Td (%s) Tj ET\n",
+		gray, int(font)+1, size, x, l.y, escapePDFString(s))
+}
+
+// text wraps s to the content width after indent. prefix, such as a list
+// bullet, is hung in the indent of the first line.
+func (l *pdfLayout) text(font pdfFont, size, indent float64, prefix, s string, gray float64) {
+	x := pageMargin + indent
+	width := contentWidth - indent
+	space := font.width([]byte(" "), size)
+
+	var current []byte
+	lineWidth := 0.0
+	first := true
+	flush := func() {
+		l.line(font, size, x, current, gray)
+		if first && prefix != "" {
+			fmt.Fprintf(l.page(), "BT %.3g g /F%d %.3g Tf %.2f %.2f Td (%s) Tj ET\n",
+				gray, int(font)+1, size, x-listIndent, l.y, escapePDFString(encodeWinAnsi(prefix)))
+		}
+		first = false
+		current, lineWidth = current[:0], 0
+	}
+
+	for _, word := range strings.Fields(s) {
+		encoded := encodeWinAnsi(word)
+		w := font.width(encoded, size)
+		// Words longer than a line are split wherever they overflow
+		for w > width && len(encoded) > 1 {
+			if len(current) > 0 {
+				flush()
+			}
+			n := len(encoded)
+			for n > 1 && font.width(encoded[:n], size) > width {
+				n--
+			}
+			current = append(current, encoded[:n]...)
+			flush()
+			encoded = encoded[n:]
+			w = font.width(encoded, size)
+		}
+		if len(current) > 0 && lineWidth+space+w > width {
+			flush()
+		}
+		if len(current) > 0 {
+			current = append(current, ' ')
+			lineWidth += space
+		}
+		current = append(current, encoded...)
+		lineWidth += w
+	}
+	if len(current) > 0 || first {
+		flush()
+	}
+}
+
+// rule draws a horizontal line across the content width
+func (l *pdfLayout) rule() {
+	l.reserve(8)
+	fmt.Fprintf(l.page(), "0.7 G 0.5 w %.2f %.2f m %.2f %.2f l S\n",
+		pageMargin, l.y+4, pageWidth-pageMargin, l.y+4)
+}
+
+// renderPDF lays doc out as a text PDF: title, provenance block, then the
+// article with headings, lists, quotes and code kept distinct
+func renderPDF(d *Document) ([]byte, error) {
+	l := &pdfLayout{}
+	l.text(fontBold, 20, 0, "", d.Title, 0)
+	l.space(4)
+	for _, field := range d.metadata() {
+		l.text(fontRegular, 9, 0, "", field[0]+": "+field[1], 0.35)
+	}
+	l.rule()
+	l.space(6)
+
+	source := []byte(d.Markdown)
+	root := markdown.Parser().Parse(text.NewReader(source))
+	for n := root.FirstChild(); n != nil; n = n.NextSibling() {
+		layoutBlock(l, n, source, 0, "", fontRegular)
+	}
+
+	return assemblePDF(d, l.pages), nil
+}
+
+// layoutBlock renders one block node and its children
+func layoutBlock(l *pdfLayout, n ast.Node, source []byte, indent float64, prefix string, font pdfFont) {
+	switch n := n.(type) {
+	case *ast.Heading:
+		sizes := map[int]float64{1: 17, 2: 15}
+		size, ok := sizes[n.Level]
+		if !ok {
+			size = 13
+		}
+		l.space(6)
+		l.text(fontBold, size, indent, prefix, inlineText(n, source), 0)
+		l.space(2)
+	case *ast.Paragraph, *ast.TextBlock:
+		l.text(font, 11, indent, prefix, inlineText(n, source), 0)
+		if _, tight := n.(*ast.TextBlock); !tight {
+			l.space(6)
+		}
+	case *ast.List:
+		number := n.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "•"
+			if n.IsOrdered() {
+				marker = strconv.Itoa(number) + "."
+				number++
+			}
+			first := true
+			for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+				itemPrefix := ""
+				if first {
+					itemPrefix = marker
+				}
+				layoutBlock(l, child, source, indent+listIndent, itemPrefix, font)
+				first = false
+			}
+		}
+		l.space(4)
+	case *ast.Blockquote:
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			layoutBlock(l, child, source, indent+listIndent, "", fontItalic)
+		}
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			segment := lines.At(i)
+			line := strings.TrimRight(string(segment.Value(source)), "\r\n")
+			encoded := encodeWinAnsi(line)
+			// Code keeps its line breaks; overlong lines are cut to fit
+			maxChars := int((contentWidth - indent) / (600 * 9.0 / 1000))
+			for len(encoded) > maxChars {
+				l.line(fontMono, 9, pageMargin+indent, encoded[:maxChars], 0.2)
+				encoded = encoded[maxChars:]
+			}
+			l.line(fontMono, 9, pageMargin+indent, encoded, 0.2)
+		}
+		l.space(6)
+	case *ast.ThematicBreak:
+		l.rule()
+	}
+}
+
+// inlineText flattens a block's inline content to plain text. Link
+// targets follow their text in parentheses so they survive printing.
+func inlineText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	var walk func(ast.Node)
+	walk = func(n ast.Node) {
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			switch c := c.(type) {
+			case *ast.Text:
+				b.Write(c.Value(source))
+				if c.SoftLineBreak() || c.HardLineBreak() {
+					b.WriteByte(' ')
+				}
+			case *ast.String:
+				b.Write(c.Value)
+			case *ast.RawHTML:
+			case *ast.AutoLink:
+				b.Write(c.URL(source))
+			case *ast.Link:
+				walk(c)
+				fmt.Fprintf(&b, " (%s)", c.Destination)
+			case *ast.Image:
+				b.WriteString("[image: ")
+				walk(c)
+				b.WriteString("]")
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// assemblePDF writes the page streams into a PDF file with the document
+// metadata in its Info dictionary
+func assemblePDF(d *Document, pages []*bytes.Buffer) []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objects 1-2 are the catalog and page tree, 3-6 the fonts, 7 the
+	// Info dictionary; each page then takes a page and a content object
+	const firstPage = 8
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	for _, name := range baseFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+
+	info := []string{
+		"/Title " + pdfTextString(d.Title),
+		"/Author " + pdfTextString(d.Author),
+		"/Subject " + pdfTextString("CID "+d.CID),
+		"/Creator (newsp2p)",
+		"/CreationDate (D:" + d.Published.UTC().Format("20060102150405") + "Z)",
+		"/CID " + pdfTextString(d.CID),
+	}
+	if d.KeyFingerprint != "" {
+		info = append(info, "/SignatureFingerprint "+pdfTextString(d.KeyFingerprint))
+	}
+	if len(d.Tags) > 0 {
+		info = append(info, "/Keywords "+pdfTextString(strings.Join(d.Tags, ", ")))
+	}
+	object("<< " + strings.Join(info, " ") + " >>")
+
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R /F4 6 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 7 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// escapePDFString escapes a literal string's delimiters
+func escapePDFString(s []byte) []byte {
+	var b bytes.Buffer
+	for _, c := range s {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.Bytes()
+}
+
+// pdfTextString encodes s as a UTF-16BE hex string, which PDF readers
+// show correctly in document properties whatever the script
+func pdfTextString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package grpcapi
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	newsp2pv1 "github.com/amiyamandal-dev/newsp2p/proto/newsp2p/v1"
+)
+
+// newArticle converts a domain article to the message sent to clients
+func newArticle(a *domain.Article) *newsp2pv1.Article {
+	return &newsp2pv1.Article{
+		Id:           a.ID,
+		Cid:          a.CID,
+		NodeCid:      a.NodeCID,
+		Title:        a.Title,
+		Body:         a.Body,
+		Author:       a.Author,
+		AuthorPubkey: a.AuthorPubKey,
+		Signature:    a.Signature,
+		Timestamp:    timestamp(a.Timestamp),
+		Tags:         a.Tags,
+		Category:     a.Category,
+		Version:      int32(a.Version),
+		CreatedAt:    timestamp(a.CreatedAt),
+		UpdatedAt:    timestamp(a.UpdatedAt),
+	}
+}
+
+// timestamp converts t, leaving zero times unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,435 @@
+// Package grpcapi serves the gRPC API described in
+// proto/newsp2p/v1/newsp2p.proto on its own port.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	newsp2pv1 "github.com/amiyamandal-dev/newsp2p/proto/newsp2p/v1"
+)
+
+// streamPageSize is how many articles StreamArticles reads at a time
+const streamPageSize = 100
+
+// Server implements the ArticleService, SearchService and NetworkService
+// gRPC services
+type Server struct {
+	newsp2pv1.UnimplementedArticleServiceServer
+	newsp2pv1.UnimplementedSearchServiceServer
+	newsp2pv1.UnimplementedNetworkServiceServer
+
+	articles   *service.ArticleService
+	search     *service.SearchService
+	jwtManager *auth.JWTManager
+	node       *p2p.P2PNode     // optional; nil when P2P is disabled
+	sync       *p2p.SyncService // optional
+	events     *events.Bus      // optional; enables StreamArticles follow
+	grpcServer *grpc.Server
+	stopChan   chan struct{} // closed on Shutdown to end following streams
+	logger     *logger.Logger
+}
+
+// NewServer creates a gRPC server
+func NewServer(articles *service.ArticleService, search *service.SearchService, jwtManager *auth.JWTManager, logger *logger.Logger) *Server {
+	s := &Server{
+		articles:   articles,
+		search:     search,
+		jwtManager: jwtManager,
+		stopChan:   make(chan struct{}),
+		logger:     logger.WithComponent("grpc"),
+	}
+	s.grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryStatus),
+		grpc.ChainStreamInterceptor(s.streamStatus),
+	)
+	newsp2pv1.RegisterArticleServiceServer(s.grpcServer, s)
+	newsp2pv1.RegisterSearchServiceServer(s.grpcServer, s)
+	newsp2pv1.RegisterNetworkServiceServer(s.grpcServer, s)
+	return s
+}
+
+// SetNetwork enables the NetworkService calls that need a running P2P node
+func (s *Server) SetNetwork(node *p2p.P2PNode, sync *p2p.SyncService) {
+	s.node = node
+	s.sync = sync
+}
+
+// SetEventBus lets StreamArticles follow new articles
+func (s *Server) SetEventBus(bus *events.Bus) {
+	s.events = bus
+}
+
+// Serve accepts gRPC connections (HTTP/2 without TLS) on l until Shutdown
+func (s *Server) Serve(l net.Listener) error {
+	s.logger.Info("gRPC server listening", "address", l.Addr().String())
+	if err := s.grpcServer.Serve(l); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting calls, ends streams that follow new articles
+// and waits for the remaining calls until ctx ends, then cancels them
+func (s *Server) Shutdown(ctx context.Context) error {
+	close(s.stopChan)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// unaryStatus reports handler errors as gRPC statuses
+func (s *Server) unaryStatus(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, s.status(info.FullMethod, err)
+	}
+	return resp, nil
+}
+
+// streamStatus reports streaming handler errors as gRPC statuses
+func (s *Server) streamStatus(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := handler(srv, stream); err != nil {
+		return s.status(info.FullMethod, err)
+	}
+	return nil
+}
+
+// status converts a handler error into the status sent to the client.
+// Unexpected errors are logged and reported without their details.
+func (s *Server) status(method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "canceled")
+	}
+	s.logger.Error("gRPC call failed", "method", method, "error", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// caller returns the user behind the call's bearer token
+func (s *Server) caller(ctx context.Context) (*auth.Claims, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var authorization string
+	if values := md.Get("authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization")
+	}
+	claims, err := s.jwtManager.ValidateToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	return claims, nil
+}
+
+// remoteIP returns the address the call came from
+func remoteIP(ctx context.Context) string {
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, _ := net.SplitHostPort(p.Addr.String())
+	return host
+}
+
+// domainStatus maps service errors onto gRPC statuses
+func domainStatus(err error) error {
+	var validation *domain.ValidationError
+	switch {
+	case errors.As(err, &validation):
+		return status.Error(codes.InvalidArgument, validation.Message)
+	case errors.Is(err, domain.ErrArticleNotFound), errors.Is(err, domain.ErrNotFound):
+		return status.Error(codes.NotFound, "article not found")
+	case errors.Is(err, domain.ErrInvalidCID):
+		return status.Error(codes.InvalidArgument, "invalid CID")
+	case errors.Is(err, domain.ErrCIDMismatch):
+		return status.Error(codes.Unavailable, "content retrieved from IPFS does not match its CID")
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrUserNotActive):
+		return status.Error(codes.PermissionDenied, "account is not active")
+	}
+	return err
+}
+
+// GetArticle looks an article up by CID or ID
+func (s *Server) GetArticle(ctx context.Context, req *newsp2pv1.GetArticleRequest) (*newsp2pv1.Article, error) {
+	var (
+		article *domain.Article
+		err     error
+	)
+	switch {
+	case req.Cid != "" && req.Id != "":
+		return nil, status.Error(codes.InvalidArgument, "set either cid or id, not both")
+	case req.Cid != "":
+		article, err = s.articles.GetByCID(ctx, req.Cid)
+	case req.Id != "":
+		article, err = s.articles.GetByID(ctx, req.Id)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "cid or id is required")
+	}
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+	return newArticle(article), nil
+}
+
+// ListArticles returns one page of articles
+func (s *Server) ListArticles(ctx context.Context, req *newsp2pv1.ListArticlesRequest) (*newsp2pv1.ListArticlesResponse, error) {
+	filter := &domain.ArticleListFilter{
+		Author:   req.Author,
+		Category: req.Category,
+		Page:     int(req.Page),
+		Limit:    int(req.Limit),
+	}
+	articles, total, err := s.articles.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &newsp2pv1.ListArticlesResponse{
+		Articles: make([]*newsp2pv1.Article, len(articles)),
+		Total:    int32(total),
+		Page:     int32(filter.Page),
+		Limit:    int32(filter.Limit),
+	}
+	for i, article := range articles {
+		resp.Articles[i] = newArticle(article)
+	}
+	return resp, nil
+}
+
+// StreamArticles sends every matching article and, with follow set, the
+// ones created or received afterwards
+func (s *Server) StreamArticles(req *newsp2pv1.StreamArticlesRequest, stream grpc.ServerStreamingServer[newsp2pv1.Article]) error {
+	ctx := stream.Context()
+
+	// Subscribe before reading stored articles so none fall between the two
+	var live <-chan domain.Event
+	if req.Follow {
+		if s.events == nil {
+			return status.Error(codes.FailedPrecondition, "following new articles is not available on this node")
+		}
+		var unsubscribe func()
+		live, unsubscribe = s.events.Subscribe(domain.EventArticleCreated, domain.EventArticleReceived)
+		defer unsubscribe()
+	}
+
+	sent := make(map[string]bool)
+	for page := 1; ; page++ {
+		articles, total, err := s.articles.List(ctx, &domain.ArticleListFilter{
+			Author:   req.Author,
+			Category: req.Category,
+			Page:     page,
+			Limit:    streamPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, article := range articles {
+			if err := stream.Send(newArticle(article)); err != nil {
+				return err
+			}
+			sent[article.ID] = true
+		}
+		if len(articles) == 0 || page*streamPageSize >= total {
+			break
+		}
+	}
+
+	if !req.Follow {
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.stopChan:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case event, ok := <-live:
+			if !ok {
+				return nil
+			}
+			article, ok := event.Data.(*domain.Article)
+			if !ok || sent[article.ID] {
+				continue
+			}
+			if req.Author != "" && !strings.EqualFold(article.Author, req.Author) {
+				continue
+			}
+			if req.Category != "" && !strings.EqualFold(article.Category, req.Category) {
+				continue
+			}
+			if err := stream.Send(newArticle(article)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// CreateArticle publishes an article as the calling user
+func (s *Server) CreateArticle(ctx context.Context, req *newsp2pv1.CreateArticleRequest) (*newsp2pv1.Article, error) {
+	claims, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := s.articles.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    req.Title,
+		Body:     req.Body,
+		Tags:     req.Tags,
+		Category: req.Category,
+	}, claims.UserID, remoteIP(ctx))
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+	return newArticle(article), nil
+}
+
+// Search runs a full-text article search
+func (s *Server) Search(ctx context.Context, req *newsp2pv1.SearchRequest) (*newsp2pv1.SearchResponse, error) {
+	if s.search == nil {
+		return nil, status.Error(codes.Unavailable, "search is not available on this node")
+	}
+	if req.Scope != "" && req.Scope != search.ScopeLocal && req.Scope != search.ScopeNetwork {
+		return nil, status.Errorf(codes.InvalidArgument, "scope must be %q or %q", search.ScopeLocal, search.ScopeNetwork)
+	}
+
+	result, err := s.search.Search(ctx, &search.SearchQuery{
+		Query:    req.Query,
+		Author:   req.Author,
+		Category: req.Category,
+		Tags:     req.Tags,
+		Page:     int(req.Page),
+		Limit:    int(req.Limit),
+		Scope:    req.Scope,
+	})
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	resp := &newsp2pv1.SearchResponse{
+		Articles:    make([]*newsp2pv1.Article, len(result.Articles)),
+		Total:       int32(result.Total),
+		Page:        int32(result.Page),
+		Limit:       int32(result.Limit),
+		TotalPages:  int32(result.TotalPages),
+		QueryTimeMs: result.QueryTime,
+	}
+	for i, article := range result.Articles {
+		resp.Articles[i] = newArticle(article)
+	}
+	return resp, nil
+}
+
+// GetStats describes this node's place in the P2P network
+func (s *Server) GetStats(ctx context.Context, _ *newsp2pv1.Empty) (*newsp2pv1.NetworkStats, error) {
+	if s.node == nil {
+		return &newsp2pv1.NetworkStats{Status: "disabled"}, nil
+	}
+
+	peerID := s.node.GetPeerID().String()
+	stats := &newsp2pv1.NetworkStats{
+		Status:    "active",
+		PeerId:    peerID,
+		PeerCount: int32(s.node.GetPeerCount()),
+	}
+	for _, addr := range s.node.GetHost().Addrs() {
+		stats.Addresses = append(stats.Addresses, fmt.Sprintf("%s/p2p/%s", addr, peerID))
+	}
+	if s.sync != nil {
+		stats.LastSync = timestamp(s.sync.GetLastSyncTime())
+	}
+	return stats, nil
+}
+
+// ListPeers lists connected peers and their known addresses
+func (s *Server) ListPeers(ctx context.Context, _ *newsp2pv1.Empty) (*newsp2pv1.ListPeersResponse, error) {
+	if s.node == nil {
+		return nil, status.Error(codes.Unavailable, "P2P node not running")
+	}
+
+	peerstore := s.node.GetHost().Peerstore()
+	resp := &newsp2pv1.ListPeersResponse{}
+	for _, id := range s.node.GetConnectedPeers() {
+		p := &newsp2pv1.Peer{Id: id.String()}
+		for _, addr := range peerstore.Addrs(id) {
+			p.Addresses = append(p.Addresses, addr.String())
+		}
+		resp.Peers = append(resp.Peers, p)
+	}
+	return resp, nil
+}
+
+// ConnectPeer dials a peer by multiaddr
+func (s *Server) ConnectPeer(ctx context.Context, req *newsp2pv1.ConnectPeerRequest) (*newsp2pv1.Peer, error) {
+	if _, err := s.caller(ctx); err != nil {
+		return nil, err
+	}
+	if s.node == nil {
+		return nil, status.Error(codes.Unavailable, "P2P node not running")
+	}
+
+	addr, err := multiaddr.NewMultiaddr(req.Address)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid multiaddr: %v", err)
+	}
+	info, err := peer.AddrInfoFromP2pAddr(addr)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid peer address: %v", err)
+	}
+	if err := s.node.GetHost().Connect(ctx, *info); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to connect: %v", err)
+	}
+
+	p := &newsp2pv1.Peer{Id: info.ID.String()}
+	for _, a := range info.Addrs {
+		p.Addresses = append(p.Addresses, a.String())
+	}
+	return p, nil
+}
+
+// TriggerSync starts a sync with peers
+func (s *Server) TriggerSync(ctx context.Context, _ *newsp2pv1.Empty) (*newsp2pv1.Empty, error) {
+	if _, err := s.caller(ctx); err != nil {
+		return nil, err
+	}
+	if s.sync == nil {
+		return nil, status.Error(codes.Unavailable, "P2P sync not running")
+	}
+	s.sync.TriggerSync()
+	return &newsp2pv1.Empty{}, nil
+}
@@ -0,0 +1,145 @@
+// Package i18n loads the web UI's message catalogs and picks the language
+// a request is served in.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// NameKey is the catalog key holding a language's name in that language,
+// as shown by the language switcher
+const NameKey = "language.name"
+
+// Bundle holds a catalog of messages for each language the UI ships in.
+// Catalogs are flat JSON objects named after their language tag, such as
+// en.json. Messages missing from a catalog fall back to the default
+// language's catalog.
+type Bundle struct {
+	fallback string
+	codes    []string // fallback first, the rest sorted
+	catalogs map[string]map[string]string
+	matcher  language.Matcher
+}
+
+// Language is one language the UI ships in
+type Language struct {
+	Code string
+	Name string
+}
+
+// Load reads every catalog in dir. The fallback language's catalog must be
+// among them.
+func Load(dir, fallback string) (*Bundle, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		fallback: fallback,
+		catalogs: make(map[string]map[string]string),
+	}
+	for _, path := range paths {
+		code := strings.TrimSuffix(filepath.Base(path), ".json")
+		if _, err := language.Parse(code); err != nil {
+			return nil, fmt.Errorf("catalog %s: invalid language tag: %w", path, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", path, err)
+		}
+		b.catalogs[code] = messages
+		if code != fallback {
+			b.codes = append(b.codes, code)
+		}
+	}
+	if _, ok := b.catalogs[fallback]; !ok {
+		return nil, fmt.Errorf("no catalog for the default language %q in %s", fallback, dir)
+	}
+
+	// The matcher answers with the first tag when nothing matches
+	sort.Strings(b.codes)
+	b.codes = append([]string{fallback}, b.codes...)
+	tags := make([]language.Tag, len(b.codes))
+	for i, code := range b.codes {
+		tags[i] = language.MustParse(code)
+	}
+	b.matcher = language.NewMatcher(tags)
+
+	return b, nil
+}
+
+// Default returns the fallback language
+func (b *Bundle) Default() string {
+	return b.fallback
+}
+
+// Has reports whether the bundle has a catalog for code
+func (b *Bundle) Has(code string) bool {
+	_, ok := b.catalogs[code]
+	return ok
+}
+
+// Languages lists the languages with a catalog, default first
+func (b *Bundle) Languages() []Language {
+	languages := make([]Language, len(b.codes))
+	for i, code := range b.codes {
+		languages[i] = Language{Code: code, Name: b.Translate(code, NameKey)}
+	}
+	return languages
+}
+
+// Match picks the best language for an Accept-Language header, or the
+// default when none of the requested languages has a catalog
+func (b *Bundle) Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return b.fallback
+	}
+	_, index, confidence := b.matcher.Match(tags...)
+	if confidence == language.No {
+		return b.fallback
+	}
+	return b.codes[index]
+}
+
+// Translate looks up key in code's catalog. With args the message is used
+// as a fmt format. Unknown keys are returned as they are, so a missing
+// message shows up on the page rather than as a blank.
+func (b *Bundle) Translate(code, key string, args ...interface{}) string {
+	message, ok := b.catalogs[code][key]
+	if !ok {
+		message, ok = b.catalogs[b.fallback][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Missing lists the keys of the default catalog that code's catalog lacks
+func (b *Bundle) Missing(code string) []string {
+	var missing []string
+	for key := range b.catalogs[b.fallback] {
+		if _, ok := b.catalogs[code][key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
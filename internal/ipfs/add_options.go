@@ -0,0 +1,56 @@
+package ipfs
+
+import (
+	"fmt"
+
+	shell "github.com/ipfs/go-ipfs-api"
+	"github.com/multiformats/go-multihash"
+)
+
+// AddOptions chooses the CID format of content added to IPFS. The zero
+// value leaves every choice to the daemon (CIDv0, dag-pb leaves, sha2-256).
+type AddOptions struct {
+	CIDVersion int    // 0 or 1; the daemon prints CIDv1 in base32
+	RawLeaves  bool   // store file leaves as raw blocks instead of dag-pb
+	Hash       string // multihash function name, e.g. "sha2-256" or "blake3"
+}
+
+// Validate reports option combinations the daemon would reject or
+// silently upgrade
+func (o AddOptions) Validate() error {
+	if o.CIDVersion != 0 && o.CIDVersion != 1 {
+		return fmt.Errorf("unsupported CID version %d", o.CIDVersion)
+	}
+	if o.Hash != "" {
+		if _, ok := multihash.Names[o.Hash]; !ok {
+			return fmt.Errorf("unknown hash function %q", o.Hash)
+		}
+	}
+	if o.CIDVersion == 0 && (o.RawLeaves || (o.Hash != "" && o.Hash != "sha2-256")) {
+		return fmt.Errorf("CIDv0 only supports sha2-256 without raw leaves")
+	}
+	return nil
+}
+
+// SetAddOptions applies opts to every Add, Hash, AddStream and DagPut.
+// Hash must match Add, or content checks against stored CIDs would fail.
+func (c *Client) SetAddOptions(opts AddOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	c.addOpts = opts
+	return nil
+}
+
+// addOptions turns the configured AddOptions into shell add options
+func (c *Client) addOptions() []shell.AddOpts {
+	var opts []shell.AddOpts
+	if c.addOpts.CIDVersion == 1 {
+		// CIDv1 implies raw leaves on the daemon unless told otherwise
+		opts = append(opts, shell.CidVersion(1), shell.RawLeaves(c.addOpts.RawLeaves))
+	}
+	if c.addOpts.Hash != "" {
+		opts = append(opts, shell.Hash(c.addOpts.Hash))
+	}
+	return opts
+}
@@ -0,0 +1,104 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	shell "github.com/ipfs/go-ipfs-api"
+	"github.com/ipfs/go-ipfs-api/options"
+)
+
+// archiveStagingDir is the MFS directory archives are assembled under
+const archiveStagingDir = "/newsp2p/archives"
+
+// DirectoryEntry places existing IPFS content at a relative path in a directory
+type DirectoryEntry struct {
+	Path string // relative, e.g. "articles/<id>.json"
+	CID  string
+}
+
+// BuildDirectory links entries into a fresh UnixFS directory and returns its
+// root CID. Content is copied by reference, so nothing is re-uploaded.
+func (c *Client) BuildDirectory(ctx context.Context, entries []DirectoryEntry) (string, error) {
+	staging := fmt.Sprintf("%s/%d", archiveStagingDir, time.Now().UnixNano())
+	if err := c.shell.FilesMkdir(ctx, staging, shell.FilesMkdir.Parents(true)); err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() {
+		if err := c.shell.FilesRm(context.Background(), staging, true); err != nil {
+			c.logger.Warn("Failed to remove archive staging directory", "path", staging, "error", err)
+		}
+	}()
+
+	if err := c.linkEntries(ctx, staging, entries); err != nil {
+		return "", err
+	}
+
+	stat, err := c.shell.FilesStat(ctx, staging)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat staging directory: %w", err)
+	}
+
+	c.logger.Debug("Built IPFS directory", "root", stat.Hash, "entries", len(entries))
+	return stat.Hash, nil
+}
+
+// linkEntries copies each entry by reference to its path below the MFS
+// directory dir, creating intermediate directories as needed
+func (c *Client) linkEntries(ctx context.Context, dir string, entries []DirectoryEntry) error {
+	for _, entry := range entries {
+		dest := path.Join(dir, entry.Path)
+		if parent := path.Dir(dest); parent != dir {
+			if err := c.shell.FilesMkdir(ctx, parent, shell.FilesMkdir.Parents(true)); err != nil {
+				return fmt.Errorf("failed to create %s: %w", parent, err)
+			}
+		}
+		if err := c.shell.FilesCp(ctx, "/ipfs/"+entry.CID, dest); err != nil {
+			return fmt.Errorf("failed to link %s: %w", entry.CID, err)
+		}
+	}
+	return nil
+}
+
+// ExportCAR streams the DAG under root to w as a CARv1 archive
+func (c *Client) ExportCAR(ctx context.Context, root string, w io.Writer) error {
+	if root == "" {
+		return fmt.Errorf("export requires a root CID")
+	}
+
+	resp, err := c.shell.Request("dag/export", root).Send(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", root, err)
+	}
+	defer resp.Close()
+	if resp.Error != nil {
+		return fmt.Errorf("failed to export %s: %w", root, resp.Error)
+	}
+
+	n, err := io.Copy(w, resp.Output)
+	if err != nil {
+		return fmt.Errorf("failed to stream CAR for %s: %w", root, err)
+	}
+
+	c.logger.Debug("Exported CAR", "root", root, "bytes", n)
+	return nil
+}
+
+// ImportCAR loads a CARv1 archive into the node, pins its roots and returns them
+func (c *Client) ImportCAR(ctx context.Context, r io.Reader) ([]string, error) {
+	out, err := c.shell.DagImportWithOpts(r, options.Dag.PinRoots(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to import CAR: %w", err)
+	}
+
+	roots := make([]string, 0, len(out.Roots))
+	for _, root := range out.Roots {
+		roots = append(roots, root.Root.Cid.Value)
+	}
+
+	c.logger.Debug("Imported CAR", "roots", roots)
+	return roots, nil
+}
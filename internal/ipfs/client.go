@@ -3,20 +3,27 @@ package ipfs
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	files "github.com/ipfs/boxo/files"
 	shell "github.com/ipfs/go-ipfs-api"
 )
 
 // Client wraps the IPFS HTTP API client
 type Client struct {
 	shell      *shell.Shell
+	stream     *shell.Shell // no overall timeout; requests are bounded by their context
 	timeout    time.Duration
+	timeouts   Timeouts
 	pinContent bool
+	cluster    *ClusterClient // optional; pins are replicated through it when set
+	addOpts    AddOptions
+	metrics    *Metrics
 	logger     *logger.Logger
 }
 
@@ -27,16 +34,49 @@ func NewClient(apiEndpoint string, timeout time.Duration, pinContent bool, logge
 
 	return &Client{
 		shell:      sh,
+		stream:     shell.NewShell(apiEndpoint),
 		timeout:    timeout,
 		pinContent: pinContent,
+		metrics:    NewMetrics(),
 		logger:     logger.WithComponent("ipfs-client"),
 	}
 }
 
+// SetTimeouts bounds add, cat, pin and IPNS operations separately, so a
+// large read isn't cut short by the limit meant for a quick pin
+func (c *Client) SetTimeouts(timeouts Timeouts) {
+	c.timeouts = timeouts
+}
+
+// Metrics returns the latency and error counters of this client's operations
+func (c *Client) Metrics() *Metrics {
+	return c.metrics
+}
+
+// opTimeout returns timeout, or the client default when it is unset
+func (c *Client) opTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	return c.timeout
+}
+
+// SetCluster routes pin operations through an IPFS Cluster so content is
+// replicated to the cluster's configured number of peers
+func (c *Client) SetCluster(cluster *ClusterClient) {
+	c.cluster = cluster
+}
+
+// Cluster returns the configured cluster client, or nil
+func (c *Client) Cluster() *ClusterClient {
+	return c.cluster
+}
+
 // Add uploads data to IPFS and returns the CID
 func (c *Client) Add(ctx context.Context, data []byte) (string, error) {
-	reader := bytes.NewReader(data)
-	cid, err := c.AddWithRetry(ctx, reader, 3)
+	addCtx, done := c.metrics.track(ctx, OpAdd, c.opTimeout(c.timeouts.Add))
+	cid, err := c.AddWithRetry(addCtx, bytes.NewReader(data), 3)
+	done(err)
 	if err != nil {
 		c.logger.Error("Failed to add to IPFS", "error", err)
 		return "", domain.ErrIPFSUploadFailed
@@ -55,12 +95,17 @@ func (c *Client) Add(ctx context.Context, data []byte) (string, error) {
 	return cid, nil
 }
 
-// AddWithRetry uploads data to IPFS with retry logic
+// AddWithRetry uploads data to IPFS with retry logic. The reader is
+// buffered so every attempt sends the full content.
 func (c *Client) AddWithRetry(ctx context.Context, reader io.Reader, retries int) (string, error) {
-	var lastErr error
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read content: %w", err)
+	}
 
+	var lastErr error
 	for i := 0; i < retries; i++ {
-		cid, err := c.shell.Add(reader)
+		cid, err := c.add(ctx, bytes.NewReader(data), c.addOptions()...)
 		if err == nil {
 			return cid, nil
 		}
@@ -70,40 +115,88 @@ func (c *Client) AddWithRetry(ctx context.Context, reader io.Reader, retries int
 
 		// Wait before retry with exponential backoff
 		if i < retries-1 {
-			time.Sleep(time.Duration(i+1) * time.Second)
+			select {
+			case <-time.After(time.Duration(i+1) * time.Second):
+			case <-ctx.Done():
+				return "", fmt.Errorf("failed after %d attempts: %w", i+1, errors.Join(lastErr, ctx.Err()))
+			}
 		}
 	}
 
 	return "", fmt.Errorf("failed after %d retries: %w", retries, lastErr)
 }
 
+// Hash computes the CID data would get on this node without storing it
+func (c *Client) Hash(ctx context.Context, data []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opTimeout(c.timeouts.Add))
+	defer cancel()
+
+	opts := append(c.addOptions(), shell.OnlyHash(true), shell.Pin(false))
+	cid, err := c.add(ctx, bytes.NewReader(data), opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash content: %w", err)
+	}
+	return cid, nil
+}
+
+// add is shell.Add bounded by ctx instead of the shell-wide timeout
+func (c *Client) add(ctx context.Context, r io.Reader, opts ...shell.AddOpts) (string, error) {
+	dir := files.NewSliceDirectory([]files.DirEntry{files.FileEntry("", files.NewReaderFile(r))})
+
+	rb := c.stream.Request("add")
+	for _, opt := range opts {
+		if err := opt(rb); err != nil {
+			return "", err
+		}
+	}
+
+	var out struct {
+		Hash string
+	}
+	if err := rb.Body(files.NewMultiFileReader(dir, true, false)).Exec(ctx, &out); err != nil {
+		return "", err
+	}
+	return out.Hash, nil
+}
+
 // Cat retrieves data from IPFS by CID
 func (c *Client) Cat(ctx context.Context, cid string) ([]byte, error) {
 	if cid == "" {
 		return nil, domain.ErrInvalidCID
 	}
 
-	reader, err := c.shell.Cat(cid)
+	catCtx, done := c.metrics.track(ctx, OpCat, c.opTimeout(c.timeouts.Cat))
+	data, err := c.catVerified(catCtx, cid)
+	done(err)
 	if err != nil {
 		c.logger.Error("Failed to cat from IPFS", "cid", cid, "error", err)
+		if errors.Is(err, domain.ErrCIDMismatch) || errors.Is(err, domain.ErrInvalidCID) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to retrieve from IPFS: %w", err)
 	}
-	defer reader.Close()
-
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		c.logger.Error("Failed to read IPFS content", "cid", cid, "error", err)
-		return nil, fmt.Errorf("failed to read IPFS content: %w", err)
-	}
 
 	c.logger.Debug("Retrieved content from IPFS", "cid", cid, "size", len(data))
 
 	return data, nil
 }
 
-// Pin pins content to prevent garbage collection
-func (c *Client) Pin(ctx context.Context, cid string) error {
-	if err := c.shell.Pin(cid); err != nil {
+// Pin pins content to prevent garbage collection. With a cluster configured
+// the pin is handed to the cluster, which places it on enough peers to meet
+// the replication factor.
+func (c *Client) Pin(ctx context.Context, cid string) (err error) {
+	ctx, done := c.metrics.track(ctx, OpPin, c.opTimeout(c.timeouts.Pin))
+	defer func() { done(err) }()
+
+	if c.cluster != nil {
+		if err := c.cluster.Pin(ctx, cid, ""); err != nil {
+			c.logger.Error("Failed to pin content on cluster", "cid", cid, "error", err)
+			return err
+		}
+		return nil
+	}
+
+	if err := c.pinRequest(ctx, "pin/add", cid); err != nil {
 		c.logger.Error("Failed to pin content", "cid", cid, "error", err)
 		return fmt.Errorf("failed to pin %s: %w", cid, err)
 	}
@@ -112,13 +205,52 @@ func (c *Client) Pin(ctx context.Context, cid string) error {
 	return nil
 }
 
+// pinRequest runs a recursive pin/add or pin/rm bounded by ctx
+func (c *Client) pinRequest(ctx context.Context, cmd, cid string) error {
+	return c.stream.Request(cmd, cid).Option("recursive", true).Exec(ctx, nil)
+}
+
+// PinnedCIDs returns the CIDs currently pinned recursively, taken from the
+// cluster pinset when a cluster is configured
+func (c *Client) PinnedCIDs(ctx context.Context) (map[string]bool, error) {
+	if c.cluster != nil {
+		return c.cluster.Pinset(ctx)
+	}
+
+	pins, err := c.shell.PinsOfType(ctx, shell.RecursivePin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pins: %w", err)
+	}
+
+	pinned := make(map[string]bool, len(pins))
+	for cid := range pins {
+		pinned[cid] = true
+	}
+	return pinned, nil
+}
+
 // Unpin unpins content to allow garbage collection
-func (c *Client) Unpin(ctx context.Context, cid string) error {
+func (c *Client) Unpin(ctx context.Context, cid string) (err error) {
 	if cid == "" {
 		return nil // Nothing to unpin
 	}
 
-	if err := c.shell.Unpin(cid); err != nil {
+	ctx, done := c.metrics.track(ctx, OpUnpin, c.opTimeout(c.timeouts.Pin))
+	defer func() { done(err) }()
+
+	if c.cluster != nil {
+		if err := c.cluster.Unpin(ctx, cid); err != nil {
+			c.logger.Warn("Failed to unpin content on cluster", "cid", cid, "error", err)
+			return err
+		}
+		// Content added through this node is also pinned locally
+		if err := c.pinRequest(ctx, "pin/rm", cid); err != nil {
+			c.logger.Debug("No local pin to remove", "cid", cid, "error", err)
+		}
+		return nil
+	}
+
+	if err := c.pinRequest(ctx, "pin/rm", cid); err != nil {
 		c.logger.Warn("Failed to unpin content", "cid", cid, "error", err)
 		return fmt.Errorf("failed to unpin %s: %w", cid, err)
 	}
@@ -127,6 +259,17 @@ func (c *Client) Unpin(ctx context.Context, cid string) error {
 	return nil
 }
 
+// replicate hands content that was pinned locally during add to the
+// cluster as well; failures are logged since the content is already stored
+func (c *Client) replicate(ctx context.Context, cid string) {
+	if c.cluster == nil || !c.pinContent {
+		return
+	}
+	if err := c.cluster.Pin(ctx, cid, ""); err != nil {
+		c.logger.Warn("Failed to replicate content on cluster", "cid", cid, "error", err)
+	}
+}
+
 // IsHealthy checks if the IPFS daemon is reachable
 func (c *Client) IsHealthy(ctx context.Context) bool {
 	_, err := c.shell.ID()
@@ -160,5 +303,15 @@ func (c *Client) Stats(ctx context.Context) (map[string]interface{}, error) {
 		"addresses":        id.Addresses,
 	}
 
+	stats["operations"] = c.metrics.Snapshot()
+
+	if c.cluster != nil {
+		clusterID, err := c.cluster.ID(ctx)
+		stats["cluster"] = map[string]interface{}{
+			"id":        clusterID,
+			"reachable": err == nil,
+		}
+	}
+
 	return stats, nil
 }
@@ -0,0 +1,246 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// ClusterClient talks to an IPFS Cluster REST API so pins are replicated
+// across the cooperative's peers instead of living on this node alone
+type ClusterClient struct {
+	endpoint       string
+	username       string
+	password       string
+	token          string
+	replicationMin int
+	replicationMax int
+	http           *http.Client
+	logger         *logger.Logger
+}
+
+// ClusterPinStatus summarizes where a CID is pinned across the cluster
+type ClusterPinStatus struct {
+	CID      string            `json:"cid"`
+	Pinned   int               `json:"pinned"`  // peers holding a complete pin
+	Pending  int               `json:"pending"` // peers still queued or pinning
+	Failed   int               `json:"failed"`
+	PeerErrs map[string]string `json:"peer_errors,omitempty"`
+}
+
+// clusterPinInfo is the per-peer entry of GET /pins/{cid}
+type clusterPinInfo struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// NewClusterClient creates a cluster client. replicationMin and
+// replicationMax are sent with every pin; 0 leaves the cluster's own
+// defaults in place and -1 pins on every peer.
+func NewClusterClient(endpoint string, replicationMin, replicationMax int, timeout time.Duration, logger *logger.Logger) *ClusterClient {
+	return &ClusterClient{
+		endpoint:       strings.TrimRight(endpoint, "/"),
+		replicationMin: replicationMin,
+		replicationMax: replicationMax,
+		http:           &http.Client{Timeout: timeout},
+		logger:         logger.WithComponent("ipfs-cluster"),
+	}
+}
+
+// SetBasicAuth sets credentials for clusters that protect their REST API
+func (cc *ClusterClient) SetBasicAuth(username, password string) {
+	cc.username = username
+	cc.password = password
+}
+
+// SetToken sets a bearer token for clusters using JWT auth; it takes the
+// place of basic auth
+func (cc *ClusterClient) SetToken(token string) {
+	cc.token = token
+}
+
+// Pin asks the cluster to pin cid with the configured replication factor
+func (cc *ClusterClient) Pin(ctx context.Context, cid, name string) error {
+	query := url.Values{}
+	if cc.replicationMin != 0 {
+		query.Set("replication-min", strconv.Itoa(cc.replicationMin))
+	}
+	if cc.replicationMax != 0 {
+		query.Set("replication-max", strconv.Itoa(cc.replicationMax))
+	}
+	if name != "" {
+		query.Set("name", name)
+	}
+
+	if err := cc.do(ctx, http.MethodPost, "/pins/"+url.PathEscape(cid), query, nil); err != nil {
+		return fmt.Errorf("cluster pin %s: %w", cid, err)
+	}
+	cc.logger.Debug("Pinned content on cluster", "cid", cid, "replication_min", cc.replicationMin, "replication_max", cc.replicationMax)
+	return nil
+}
+
+// Unpin removes cid from the cluster pinset on all peers
+func (cc *ClusterClient) Unpin(ctx context.Context, cid string) error {
+	if err := cc.do(ctx, http.MethodDelete, "/pins/"+url.PathEscape(cid), nil, nil); err != nil {
+		return fmt.Errorf("cluster unpin %s: %w", cid, err)
+	}
+	cc.logger.Debug("Unpinned content on cluster", "cid", cid)
+	return nil
+}
+
+// Status reports how many cluster peers hold cid
+func (cc *ClusterClient) Status(ctx context.Context, cid string) (*ClusterPinStatus, error) {
+	var info struct {
+		PeerMap map[string]clusterPinInfo `json:"peer_map"`
+	}
+	if err := cc.do(ctx, http.MethodGet, "/pins/"+url.PathEscape(cid), nil, &info); err != nil {
+		return nil, fmt.Errorf("cluster status %s: %w", cid, err)
+	}
+
+	status := &ClusterPinStatus{CID: cid}
+	for peer, pin := range info.PeerMap {
+		switch pin.Status {
+		case "pinned":
+			status.Pinned++
+		case "pinning", "pin_queued":
+			status.Pending++
+		case "pin_error", "cluster_error":
+			status.Failed++
+			if status.PeerErrs == nil {
+				status.PeerErrs = make(map[string]string)
+			}
+			status.PeerErrs[peer] = pin.Error
+		}
+	}
+	return status, nil
+}
+
+// Pinset returns every CID in the cluster's shared pinset
+func (cc *ClusterClient) Pinset(ctx context.Context) (map[string]bool, error) {
+	resp, err := cc.send(ctx, http.MethodGet, "/allocations", url.Values{"filter": {"pin"}})
+	if err != nil {
+		return nil, fmt.Errorf("cluster allocations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cluster allocations: %w", err)
+	}
+
+	type clusterPin struct {
+		CID json.RawMessage `json:"cid"`
+	}
+	var list []clusterPin
+
+	// Older clusters answer with a JSON array, newer ones stream NDJSON
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("[")) {
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("cluster allocations: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		for dec.More() {
+			var pin clusterPin
+			if err := dec.Decode(&pin); err != nil {
+				return nil, fmt.Errorf("cluster allocations: %w", err)
+			}
+			list = append(list, pin)
+		}
+	}
+
+	pins := make(map[string]bool, len(list))
+	for _, pin := range list {
+		if cid := decodeClusterCID(pin.CID); cid != "" {
+			pins[cid] = true
+		}
+	}
+	return pins, nil
+}
+
+// decodeClusterCID accepts both the plain string and {"/": cid} encodings
+func decodeClusterCID(raw json.RawMessage) string {
+	var cid string
+	if json.Unmarshal(raw, &cid) == nil {
+		return cid
+	}
+	var link struct {
+		CID string `json:"/"`
+	}
+	if json.Unmarshal(raw, &link) == nil {
+		return link.CID
+	}
+	return ""
+}
+
+// ID returns the peer ID of the cluster node behind the endpoint
+func (cc *ClusterClient) ID(ctx context.Context) (string, error) {
+	var id struct {
+		ID string `json:"id"`
+	}
+	if err := cc.do(ctx, http.MethodGet, "/id", nil, &id); err != nil {
+		return "", fmt.Errorf("cluster id: %w", err)
+	}
+	return id.ID, nil
+}
+
+// do sends a request to the cluster API and decodes a JSON reply into out
+func (cc *ClusterClient) do(ctx context.Context, method, path string, query url.Values, out interface{}) error {
+	resp, err := cc.send(ctx, method, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// send issues a request and turns non-2xx replies into errors; the caller
+// closes the body on success
+func (cc *ClusterClient) send(ctx context.Context, method, path string, query url.Values) (*http.Response, error) {
+	target := cc.endpoint + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cc.token != "" {
+		req.Header.Set("Authorization", "Bearer "+cc.token)
+	} else if cc.username != "" {
+		req.SetBasicAuth(cc.username, cc.password)
+	}
+
+	resp, err := cc.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("status %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/ipfs/go-ipfs-api/options"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// DagPut stores node as dag-cbor and returns its CID. node is encoded as
+// dag-json first, so link fields must marshal to {"/": "<cid>"}.
+func (c *Client) DagPut(ctx context.Context, node interface{}) (string, error) {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode DAG node: %w", err)
+	}
+
+	opts := []options.DagPutOption{
+		options.Dag.InputCodec("dag-json"),
+		options.Dag.StoreCodec("dag-cbor"),
+		options.Dag.Pin(strconv.FormatBool(c.pinContent)),
+	}
+	if c.addOpts.Hash != "" {
+		opts = append(opts, options.Dag.Hash(c.addOpts.Hash))
+	}
+
+	cid, err := c.shell.DagPutWithOpts(bytes.NewReader(data), opts...)
+	if err != nil {
+		c.logger.Error("Failed to put DAG node", "error", err)
+		return "", fmt.Errorf("failed to put DAG node: %w", err)
+	}
+
+	c.logger.Debug("Stored DAG node", "cid", cid, "size", len(data))
+	c.replicate(ctx, cid)
+	return cid, nil
+}
+
+// DagGet resolves ref, a CID optionally followed by a path such as
+// "<cid>/title" or "<cid>/previous", and decodes the result into out
+func (c *Client) DagGet(ctx context.Context, ref string, out interface{}) error {
+	if ref == "" {
+		return domain.ErrInvalidCID
+	}
+
+	if err := c.shell.Request("dag/get", ref).Exec(ctx, out); err != nil {
+		return fmt.Errorf("failed to get DAG node %s: %w", ref, err)
+	}
+	return nil
+}
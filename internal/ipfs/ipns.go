@@ -3,6 +3,9 @@ package ipfs
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
@@ -10,20 +13,134 @@ import (
 	shell "github.com/ipfs/go-ipfs-api"
 )
 
-// IPNSManager handles IPNS key management and publishing
+// ipnsNamePattern accepts peer/key IDs and DNSLink domains, with or
+// without the /ipns/ prefix
+var ipnsNamePattern = regexp.MustCompile(`^(/ipns/)?[A-Za-z0-9][A-Za-z0-9.-]{0,252}$`)
+
+// defaultResolveTimeout bounds DHT lookups when no timeout is configured
+const defaultResolveTimeout = 30 * time.Second
+
+// defaultPublishTimeout bounds a publish when no timeout is configured
+const defaultPublishTimeout = 2 * time.Minute
+
+// IPNSManager handles IPNS key management, publishing and resolution.
+// With IPNS-over-PubSub enabled on the daemon, records are pushed to
+// subscribers as soon as they are published, so resolving a followed name
+// returns the latest record in seconds rather than after a DHT walk.
 type IPNSManager struct {
-	shell  *shell.Shell
-	logger *logger.Logger
+	shell          *shell.Shell
+	resolveTimeout time.Duration
+	publishTimeout time.Duration
+	metrics        *Metrics
+	logger         *logger.Logger
+
+	mu     sync.RWMutex
+	pubsub bool // daemon reported IPNS pubsub as enabled
 }
 
 // NewIPNSManager creates a new IPNS manager
 func NewIPNSManager(sh *shell.Shell, logger *logger.Logger) *IPNSManager {
 	return &IPNSManager{
-		shell:  sh,
-		logger: logger.WithComponent("ipns-manager"),
+		shell:          sh,
+		resolveTimeout: defaultResolveTimeout,
+		publishTimeout: defaultPublishTimeout,
+		metrics:        NewMetrics(),
+		logger:         logger.WithComponent("ipns-manager"),
+	}
+}
+
+// SetResolveTimeout bounds how long a resolve may spend in the DHT
+func (m *IPNSManager) SetResolveTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		m.resolveTimeout = timeout
 	}
 }
 
+// SetPublishTimeout bounds how long a publish may take
+func (m *IPNSManager) SetPublishTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		m.publishTimeout = timeout
+	}
+}
+
+// SetMetrics records publish and resolve latencies in metrics, typically
+// the IPFS client's, so all daemon operations are reported together
+func (m *IPNSManager) SetMetrics(metrics *Metrics) {
+	m.metrics = metrics
+}
+
+// EnablePubsub checks whether the daemon resolves IPNS over pubsub and,
+// if not, turns it on in the daemon's config. Kubo only reads that
+// setting at startup, so a false result means the daemon needs a restart
+// (or `ipfs daemon --enable-namesys-pubsub`) before it takes effect.
+func (m *IPNSManager) EnablePubsub(ctx context.Context) (bool, error) {
+	enabled, err := m.PubsubEnabled(ctx)
+	if err != nil {
+		return false, err
+	}
+	if enabled {
+		return true, nil
+	}
+
+	if err := m.shell.Request("config", "Ipns.UsePubsub", "true").Option("bool", true).Exec(ctx, nil); err != nil {
+		return false, fmt.Errorf("failed to enable IPNS pubsub: %w", err)
+	}
+	m.logger.Warn("Enabled IPNS pubsub in the IPFS config; restart the daemon for it to take effect")
+	return false, nil
+}
+
+// PubsubEnabled asks the daemon whether IPNS-over-PubSub is active
+func (m *IPNSManager) PubsubEnabled(ctx context.Context) (bool, error) {
+	var state struct {
+		Enabled bool
+	}
+	if err := m.shell.Request("name/pubsub/state").Exec(ctx, &state); err != nil {
+		return false, fmt.Errorf("failed to read IPNS pubsub state: %w", err)
+	}
+
+	m.mu.Lock()
+	m.pubsub = state.Enabled
+	m.mu.Unlock()
+	return state.Enabled, nil
+}
+
+// UsingPubsub reports the pubsub state seen by the last check
+func (m *IPNSManager) UsingPubsub() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pubsub
+}
+
+// Subscriptions lists the IPNS names the daemon follows over pubsub
+func (m *IPNSManager) Subscriptions(ctx context.Context) ([]string, error) {
+	var subs struct {
+		Strings []string
+	}
+	if err := m.shell.Request("name/pubsub/subs").Exec(ctx, &subs); err != nil {
+		return nil, fmt.Errorf("failed to list IPNS subscriptions: %w", err)
+	}
+	return subs.Strings, nil
+}
+
+// Follow subscribes to updates for another node's IPNS name. Kubo joins a
+// name's pubsub topic on its first resolve, so this simply resolves it.
+func (m *IPNSManager) Follow(ctx context.Context, name string) error {
+	_, err := m.Resolve(ctx, name)
+	return err
+}
+
+// NormalizeIPNSName returns name as an /ipns/ path, or ErrInvalidIPNSName
+func NormalizeIPNSName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if !ipnsNamePattern.MatchString(name) {
+		return "", domain.ErrInvalidIPNSName
+	}
+	if !strings.HasPrefix(name, "/ipns/") {
+		name = "/ipns/" + name
+	}
+	return name, nil
+}
+
 // KeyInfo represents IPNS key information
 type KeyInfo struct {
 	Name string
@@ -64,14 +181,20 @@ func (m *IPNSManager) Publish(ctx context.Context, cid, keyName string) (string,
 		return "", domain.ErrInvalidCID
 	}
 
-	// Publish with options
-	response, err := m.shell.PublishWithDetails(
-		cid,
-		keyName,
-		24*time.Hour,   // Lifetime
-		30*time.Second, // TTL
-		true,           // Resolve
-	)
+	ctx, done := m.metrics.track(ctx, OpIPNSPublish, m.publishTimeout)
+	var response struct {
+		Name  string
+		Value string
+	}
+	req := m.shell.Request("name/publish", cid).
+		Option("lifetime", "24h").
+		Option("ttl", "30s").
+		Option("resolve", true)
+	if keyName != "" {
+		req.Option("key", keyName)
+	}
+	err := req.Exec(ctx, &response)
+	done(err)
 
 	if err != nil {
 		m.logger.Error("Failed to publish to IPNS",
@@ -92,16 +215,32 @@ func (m *IPNSManager) Publish(ctx context.Context, cid, keyName string) (string,
 	return ipnsPath, nil
 }
 
-// Resolve resolves an IPNS name to a CID
-func (m *IPNSManager) Resolve(ctx context.Context, ipnsPath string) (string, error) {
-	cid, err := m.shell.Resolve(ipnsPath)
+// Resolve resolves an IPNS name (ours or another node's) to an /ipfs/
+// path. With pubsub enabled the daemon answers from records pushed over
+// the name's topic; otherwise the DHT lookup is bounded by the resolve
+// timeout.
+func (m *IPNSManager) Resolve(ctx context.Context, name string) (string, error) {
+	ipnsPath, err := NormalizeIPNSName(name)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, done := m.metrics.track(ctx, OpIPNSResolve, m.resolveTimeout+5*time.Second)
+	var out struct {
+		Path string
+	}
+	err = m.shell.Request("name/resolve", ipnsPath).
+		Option("recursive", true).
+		Option("dht-timeout", m.resolveTimeout.String()).
+		Exec(ctx, &out)
+	done(err)
 	if err != nil {
-		m.logger.Error("Failed to resolve IPNS", "ipns_path", ipnsPath, "error", err)
-		return "", fmt.Errorf("failed to resolve IPNS: %w", err)
+		m.logger.Warn("Failed to resolve IPNS", "ipns_path", ipnsPath, "pubsub", m.UsingPubsub(), "error", err)
+		return "", fmt.Errorf("%w: %v", domain.ErrIPNSResolveFailed, err)
 	}
 
-	m.logger.Debug("Resolved IPNS", "ipns_path", ipnsPath, "cid", cid)
-	return cid, nil
+	m.logger.Debug("Resolved IPNS", "ipns_path", ipnsPath, "path", out.Path)
+	return out.Path, nil
 }
 
 // ListKeys lists all IPNS keys
@@ -139,6 +278,23 @@ func (m *IPNSManager) GetKeyID(ctx context.Context, keyName string) (string, err
 	return "", fmt.Errorf("key not found: %s", keyName)
 }
 
+// RemoveKey deletes a named key from the daemon's keystore. Records
+// already published under it stay resolvable until they expire. The node's
+// own "self" key is never removed.
+func (m *IPNSManager) RemoveKey(ctx context.Context, keyName string) error {
+	if keyName == "" || keyName == "self" {
+		return fmt.Errorf("%w: refusing to remove key %q", domain.ErrIPNSKeyFailed, keyName)
+	}
+
+	if _, err := m.shell.KeyRm(ctx, keyName); err != nil {
+		m.logger.Error("Failed to remove key", "key_name", keyName, "error", err)
+		return fmt.Errorf("%w: %v", domain.ErrIPNSKeyFailed, err)
+	}
+
+	m.logger.Info("Removed IPNS key", "key_name", keyName)
+	return nil
+}
+
 // EnsureKey ensures a key exists, creating it if necessary
 func (m *IPNSManager) EnsureKey(ctx context.Context, keyName string) (*KeyInfo, error) {
 	// Try to get existing key
@@ -0,0 +1,132 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/tracing"
+)
+
+// Operation names recorded in Metrics
+const (
+	OpAdd         = "add"
+	OpCat         = "cat"
+	OpPin         = "pin"
+	OpUnpin       = "unpin"
+	OpIPNSPublish = "ipns_publish"
+	OpIPNSResolve = "ipns_resolve"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histogram
+var latencyBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+// Timeouts bounds each kind of IPFS operation. A zero value falls back to
+// the client's default timeout.
+type Timeouts struct {
+	Add  time.Duration
+	Cat  time.Duration // the whole verified read, not each block
+	Pin  time.Duration // pin and unpin; pinning may fetch the entire DAG
+	IPNS time.Duration // publishing; resolves are bounded by the resolve timeout
+}
+
+// LatencyBucket counts operations that finished within Le seconds
+type LatencyBucket struct {
+	Le    float64 `json:"le"`
+	Count int64   `json:"count"`
+}
+
+// OpStats summarises one kind of operation. Buckets are cumulative, so the
+// last one holds every operation that finished within the largest bound.
+type OpStats struct {
+	Count      int64           `json:"count"`
+	Errors     int64           `json:"errors"`
+	Timeouts   int64           `json:"timeouts"`
+	LatencySum float64         `json:"latency_seconds_sum"`
+	LatencyMax float64         `json:"latency_seconds_max"`
+	Buckets    []LatencyBucket `json:"latency_buckets"`
+}
+
+// Metrics records the latency and outcome of IPFS operations. It is safe
+// for concurrent use.
+type Metrics struct {
+	mu  sync.Mutex
+	ops map[string]*OpStats
+}
+
+// NewMetrics creates an empty metrics recorder
+func NewMetrics() *Metrics {
+	return &Metrics{ops: make(map[string]*OpStats)}
+}
+
+// Observe records one operation that took d and ended with err
+func (m *Metrics) Observe(op string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.ops[op]
+	if !ok {
+		stats = &OpStats{Buckets: make([]LatencyBucket, len(latencyBuckets))}
+		for i, le := range latencyBuckets {
+			stats.Buckets[i].Le = le
+		}
+		m.ops[op] = stats
+	}
+
+	seconds := d.Seconds()
+	stats.Count++
+	stats.LatencySum += seconds
+	stats.LatencyMax = max(stats.LatencyMax, seconds)
+	for i := range stats.Buckets {
+		if seconds <= stats.Buckets[i].Le {
+			stats.Buckets[i].Count++
+		}
+	}
+
+	if err != nil {
+		stats.Errors++
+		if errors.Is(err, context.DeadlineExceeded) {
+			stats.Timeouts++
+		}
+	}
+}
+
+// Snapshot returns a copy of the stats of every operation seen so far
+func (m *Metrics) Snapshot() map[string]OpStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]OpStats, len(m.ops))
+	for op, stats := range m.ops {
+		snap := *stats
+		snap.Buckets = append([]LatencyBucket(nil), stats.Buckets...)
+		out[op] = snap
+	}
+	return out
+}
+
+// track bounds ctx by timeout (when positive), opens a span for the
+// operation and returns a function that records its outcome and releases
+// the context
+func (m *Metrics) track(ctx context.Context, op string, timeout time.Duration) (context.Context, func(error)) {
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	ctx, span := tracing.StartKind(ctx, trace.SpanKindClient, "ipfs."+op)
+	start := time.Now()
+	return ctx, func(err error) {
+		// An error caused by our own deadline is a timeout even when the
+		// daemon client reports it as something else
+		if err != nil && ctx.Err() == context.DeadlineExceeded && !errors.Is(err, context.DeadlineExceeded) {
+			err = errors.Join(err, context.DeadlineExceeded)
+		}
+		m.Observe(op, time.Since(start), err)
+		tracing.End(span, err)
+		cancel()
+	}
+}
@@ -0,0 +1,61 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	shell "github.com/ipfs/go-ipfs-api"
+)
+
+// MirrorDirectory replaces the MFS directory dir with one holding entries
+// and returns its CID. The new tree is assembled next to the old one and
+// moved into place, so gateways browsing dir never see a half-built copy.
+func (c *Client) MirrorDirectory(ctx context.Context, dir string, entries []DirectoryEntry) (string, error) {
+	dir = path.Clean("/" + dir)
+	if dir == "/" {
+		return "", fmt.Errorf("refusing to mirror onto the MFS root")
+	}
+
+	staging := fmt.Sprintf("%s.staging-%d", dir, time.Now().UnixNano())
+	if err := c.shell.FilesMkdir(ctx, staging, shell.FilesMkdir.Parents(true)); err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := c.shell.FilesRm(context.Background(), staging, true); err != nil {
+			c.logger.Warn("Failed to remove MFS staging directory", "path", staging, "error", err)
+		}
+	}()
+
+	if err := c.linkEntries(ctx, staging, entries); err != nil {
+		return "", err
+	}
+
+	if err := c.shell.FilesRm(ctx, dir, true); err != nil && !isMFSNotExist(err) {
+		return "", fmt.Errorf("failed to remove previous %s: %w", dir, err)
+	}
+	if err := c.shell.FilesMv(ctx, staging, dir); err != nil {
+		return "", fmt.Errorf("failed to move %s into place: %w", dir, err)
+	}
+	committed = true
+
+	stat, err := c.shell.FilesStat(ctx, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", dir, err)
+	}
+
+	c.logger.Debug("Mirrored directory into MFS", "path", dir, "root", stat.Hash, "entries", len(entries))
+	return stat.Hash, nil
+}
+
+// isMFSNotExist reports whether err is the daemon saying an MFS path is absent
+func isMFSNotExist(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "file does not exist") || strings.Contains(msg, "no such file")
+}
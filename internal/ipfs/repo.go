@@ -0,0 +1,56 @@
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// RepoSize returns the bytes used by the daemon's block store
+func (c *Client) RepoSize(ctx context.Context) (uint64, error) {
+	var stat struct {
+		RepoSize uint64
+	}
+	if err := c.shell.Request("repo/stat").Option("size-only", true).Exec(ctx, &stat); err != nil {
+		return 0, fmt.Errorf("failed to read repo size: %w", err)
+	}
+	return stat.RepoSize, nil
+}
+
+// RepoGC runs garbage collection on the daemon and returns how many blocks
+// it removed. It can take minutes on a large repo, so it uses the client
+// without the request timeout and relies on ctx instead.
+func (c *Client) RepoGC(ctx context.Context) (int, error) {
+	resp, err := c.stream.Request("repo/gc").Send(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run repo gc: %w", err)
+	}
+	defer resp.Close()
+	if resp.Error != nil {
+		return 0, fmt.Errorf("failed to run repo gc: %w", resp.Error)
+	}
+
+	removed := 0
+	dec := json.NewDecoder(resp.Output)
+	for {
+		var event struct {
+			Key   map[string]string
+			Error string
+		}
+		if err := dec.Decode(&event); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return removed, fmt.Errorf("failed to read repo gc output: %w", err)
+		}
+		if event.Error != "" {
+			c.logger.Warn("Repo GC reported an error", "error", event.Error)
+			continue
+		}
+		removed++
+	}
+
+	c.logger.Info("Repo GC finished", "removed_blocks", removed)
+	return removed, nil
+}
@@ -0,0 +1,77 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	shell "github.com/ipfs/go-ipfs-api"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ProgressFunc is called as data is sent to IPFS with the running byte count
+type ProgressFunc func(sent int64)
+
+// AddStream uploads r to IPFS without buffering it in memory, splitting it
+// into chunkSize blocks. progress, if set, is called after every read; the
+// upload is aborted when ctx is cancelled.
+func (c *Client) AddStream(ctx context.Context, r io.Reader, chunkSize int64, progress ProgressFunc) (string, error) {
+	reader := &progressReader{ctx: ctx, r: r, progress: progress}
+
+	// Media always uses raw leaves so players can range-request blocks
+	opts := []shell.AddOpts{shell.Pin(c.pinContent), shell.RawLeaves(true)}
+	if c.addOpts.CIDVersion == 1 {
+		opts = append(opts, shell.CidVersion(1))
+	}
+	if c.addOpts.Hash != "" {
+		opts = append(opts, shell.Hash(c.addOpts.Hash))
+	}
+	if chunkSize > 0 {
+		opts = append(opts, chunker(chunkSize))
+	}
+
+	cid, err := c.stream.Add(reader, opts...)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		c.logger.Error("Failed to stream to IPFS", "sent", reader.sent, "error", err)
+		return "", fmt.Errorf("%w: %v", domain.ErrIPFSUploadFailed, err)
+	}
+
+	c.logger.Debug("Streamed content to IPFS", "cid", cid, "size", reader.sent)
+	c.replicate(ctx, cid)
+	return cid, nil
+}
+
+// chunker sets a fixed-size chunker on an add request
+func chunker(size int64) shell.AddOpts {
+	return func(rb *shell.RequestBuilder) error {
+		rb.Option("chunker", fmt.Sprintf("size-%d", size))
+		return nil
+	}
+}
+
+// progressReader counts bytes read and stops once ctx is done
+type progressReader struct {
+	ctx      context.Context
+	r        io.Reader
+	sent     int64
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := p.r.Read(buf)
+	if n > 0 {
+		p.sent += int64(n)
+		if p.progress != nil {
+			p.progress(p.sent)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,319 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// UnixFS node types (unixfs.proto Data.DataType)
+const (
+	unixfsRaw       = 0
+	unixfsDirectory = 1
+	unixfsFile      = 2
+	unixfsHAMTShard = 5
+)
+
+// pbLink is a link in a dag-pb node
+type pbLink struct {
+	CID  cid.Cid
+	Name string
+}
+
+// blockSource is where verified reads get their blocks from: the daemon
+// or a trustless gateway
+type blockSource interface {
+	// rawBlock returns a block as the source has it, unverified
+	rawBlock(ctx context.Context, id cid.Cid) ([]byte, error)
+	// resolvePath resolves path below a sharded directory, which isn't
+	// walked block by block
+	resolvePath(ctx context.Context, id cid.Cid, path string) (cid.Cid, error)
+}
+
+// catVerified reads a file from the daemon, verifying every block
+func (c *Client) catVerified(ctx context.Context, ref string) ([]byte, error) {
+	data, err := catVerified(ctx, c, ref)
+	if errors.Is(err, domain.ErrCIDMismatch) {
+		c.logger.Error("Block failed CID verification", "ref", ref, "error", err)
+	}
+	return data, err
+}
+
+// rawBlock gets one block from the daemon
+func (c *Client) rawBlock(ctx context.Context, id cid.Cid) ([]byte, error) {
+	resp, err := c.stream.Request("block/get", id.String()).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return io.ReadAll(resp.Output)
+}
+
+// catVerified reads a UnixFS file block by block, checking every block's
+// multihash against the CID that referenced it. Nothing the daemon or a
+// gateway returns is trusted until it hashes to what was asked for. ref
+// is a CID, optionally followed by a path through directories.
+func catVerified(ctx context.Context, src blockSource, ref string) ([]byte, error) {
+	root, rest, _ := strings.Cut(strings.TrimPrefix(ref, "/ipfs/"), "/")
+	id, err := cid.Decode(root)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCID, root)
+	}
+
+	if rest != "" {
+		if id, err = resolveVerified(ctx, src, id, rest); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := readFile(ctx, src, id, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Resolve returns the CID a path below a root CID leads to, verifying
+// every directory block on the way
+func (c *Client) Resolve(ctx context.Context, ref string) (string, error) {
+	root, rest, _ := strings.Cut(strings.TrimPrefix(ref, "/ipfs/"), "/")
+	id, err := cid.Decode(root)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", domain.ErrInvalidCID, root)
+	}
+	if rest != "" {
+		if id, err = resolveVerified(ctx, c, id, rest); err != nil {
+			return "", err
+		}
+	}
+	return id.String(), nil
+}
+
+// fetchBlock gets one raw block and checks it against id
+func fetchBlock(ctx context.Context, src blockSource, id cid.Cid) ([]byte, error) {
+	data, err := src.rawBlock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := id.Prefix().Sum(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash block %s: %w", id, err)
+	}
+	if !sum.Equals(id) {
+		return nil, fmt.Errorf("%w: block %s hashed to %s", domain.ErrCIDMismatch, id, sum)
+	}
+	return data, nil
+}
+
+// readFile writes the file rooted at id to w in order
+func readFile(ctx context.Context, src blockSource, id cid.Cid, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := fetchBlock(ctx, src, id)
+	if err != nil {
+		return err
+	}
+
+	switch id.Type() {
+	case cid.Raw:
+		_, err := w.Write(data)
+		return err
+	case cid.DagProtobuf:
+	default:
+		return fmt.Errorf("cannot read %s as a file: unsupported codec 0x%x", id, id.Type())
+	}
+
+	links, fsData, err := decodePBNode(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", id, err)
+	}
+	fsType, content, err := decodeUnixFS(fsData)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", id, err)
+	}
+	if fsType != unixfsFile && fsType != unixfsRaw {
+		return fmt.Errorf("%s is not a file (unixfs type %d)", id, fsType)
+	}
+
+	if _, err := w.Write(content); err != nil {
+		return err
+	}
+	for _, link := range links {
+		if err := readFile(ctx, src, link.CID, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveVerified follows path through verified UnixFS directories.
+// Sharded directories are resolved by the source from that point on; the
+// file they lead to is still verified.
+func resolveVerified(ctx context.Context, src blockSource, id cid.Cid, path string) (cid.Cid, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, name := range segments {
+		data, err := fetchBlock(ctx, src, id)
+		if err != nil {
+			return cid.Undef, err
+		}
+		if id.Type() != cid.DagProtobuf {
+			return cid.Undef, fmt.Errorf("cannot resolve %q in %s: not a directory", name, id)
+		}
+
+		links, fsData, err := decodePBNode(data)
+		if err != nil {
+			return cid.Undef, fmt.Errorf("failed to decode %s: %w", id, err)
+		}
+		fsType, _, err := decodeUnixFS(fsData)
+		if err != nil {
+			return cid.Undef, fmt.Errorf("failed to decode %s: %w", id, err)
+		}
+
+		switch fsType {
+		case unixfsDirectory:
+		case unixfsHAMTShard:
+			return src.resolvePath(ctx, id, strings.Join(segments[i:], "/"))
+		default:
+			return cid.Undef, fmt.Errorf("cannot resolve %q in %s: not a directory", name, id)
+		}
+
+		found := false
+		for _, link := range links {
+			if link.Name == name {
+				id, found = link.CID, true
+				break
+			}
+		}
+		if !found {
+			return cid.Undef, fmt.Errorf("no link named %q under %s", name, id)
+		}
+	}
+	return id, nil
+}
+
+// resolvePath asks the daemon to resolve path below id
+func (c *Client) resolvePath(ctx context.Context, id cid.Cid, path string) (cid.Cid, error) {
+	var out struct {
+		Cid domain.Link
+	}
+	if err := c.stream.Request("dag/resolve", "/ipfs/"+id.String()+"/"+path).Exec(ctx, &out); err != nil {
+		return cid.Undef, fmt.Errorf("failed to resolve %s/%s: %w", id, path, err)
+	}
+	c.logger.Debug("Resolved sharded directory through daemon", "root", id.String(), "path", path)
+	return cid.Decode(out.Cid.CID)
+}
+
+// decodePBNode splits a dag-pb block into its links and data field
+func decodePBNode(b []byte) ([]pbLink, []byte, error) {
+	var links []pbLink
+	var data []byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			data, n = protowire.ConsumeBytes(b)
+		case num == 2 && typ == protowire.BytesType:
+			var raw []byte
+			raw, n = protowire.ConsumeBytes(b)
+			if n >= 0 {
+				link, err := decodePBLink(raw)
+				if err != nil {
+					return nil, nil, err
+				}
+				links = append(links, link)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return links, data, nil
+}
+
+// decodePBLink decodes a dag-pb PBLink message
+func decodePBLink(b []byte) (pbLink, error) {
+	var link pbLink
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return link, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			var hash []byte
+			hash, n = protowire.ConsumeBytes(b)
+			if n >= 0 {
+				id, err := cid.Cast(hash)
+				if err != nil {
+					return link, fmt.Errorf("invalid link CID: %w", err)
+				}
+				link.CID = id
+			}
+		case num == 2 && typ == protowire.BytesType:
+			var name []byte
+			name, n = protowire.ConsumeBytes(b)
+			link.Name = string(name)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return link, protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	if !link.CID.Defined() {
+		return link, fmt.Errorf("link without a CID")
+	}
+	return link, nil
+}
+
+// decodeUnixFS returns the node type and inline data of a UnixFS Data message
+func decodeUnixFS(b []byte) (uint64, []byte, error) {
+	var fsType uint64
+	var data []byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return 0, nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			fsType, n = protowire.ConsumeVarint(b)
+		case num == 2 && typ == protowire.BytesType:
+			data, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return 0, nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return fsType, data, nil
+}
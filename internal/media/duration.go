@@ -0,0 +1,204 @@
+// Package media inspects and converts uploaded audio and video. Durations
+// are read from container headers without decoding anything, and
+// transcoding is delegated to an external command so the node doesn't
+// link against a codec library.
+package media
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Duration returns the playing time in seconds of an MP4/MOV/M4A, WAV or
+// MP3 file, read from its headers. Other formats, and files whose headers
+// don't give a duration, report 0.
+func Duration(r io.ReaderAt, size int64) float64 {
+	head := make([]byte, 12)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return 0
+	}
+	switch {
+	case isMP4Box(string(head[4:8])):
+		return mp4Duration(r, size)
+	case string(head[0:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return wavDuration(r, size)
+	default:
+		return mp3Duration(r, size)
+	}
+}
+
+// isMP4Box reports whether a file starting with this box type is an
+// ISO base media file. Old QuickTime files may not start with ftyp.
+func isMP4Box(name string) bool {
+	switch name {
+	case "ftyp", "moov", "mdat", "wide", "free", "skip":
+		return true
+	}
+	return false
+}
+
+// mp4Duration reads the movie header: moov/mvhd holds the duration in
+// units of its own timescale
+func mp4Duration(r io.ReaderAt, size int64) float64 {
+	moov, moovEnd, ok := findBox(r, 0, size, "moov")
+	if !ok {
+		return 0
+	}
+	mvhd, mvhdEnd, ok := findBox(r, moov, moovEnd, "mvhd")
+	if !ok {
+		return 0
+	}
+
+	buf := make([]byte, min(32, mvhdEnd-mvhd))
+	if _, err := r.ReadAt(buf, mvhd); err != nil || len(buf) < 20 {
+		return 0
+	}
+	var timescale, duration uint64
+	if buf[0] == 1 {
+		if len(buf) < 32 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(buf[20:24]))
+		duration = binary.BigEndian.Uint64(buf[24:32])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(buf[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(buf[16:20]))
+	}
+	if timescale == 0 {
+		return 0
+	}
+	return float64(duration) / float64(timescale)
+}
+
+// findBox returns the payload bounds of the first box named name between
+// start and end
+func findBox(r io.ReaderAt, start, end int64, name string) (int64, int64, bool) {
+	hdr := make([]byte, 16)
+	for off := start; off+8 <= end; {
+		if _, err := r.ReadAt(hdr[:8], off); err != nil {
+			return 0, 0, false
+		}
+		boxSize, header := int64(binary.BigEndian.Uint32(hdr[0:4])), int64(8)
+		switch boxSize {
+		case 0: // extends to the end of its parent
+			boxSize = end - off
+		case 1: // 64-bit size follows the type
+			if _, err := r.ReadAt(hdr[8:16], off+8); err != nil {
+				return 0, 0, false
+			}
+			boxSize, header = int64(binary.BigEndian.Uint64(hdr[8:16])), 16
+		}
+		if boxSize < header || boxSize > end-off {
+			return 0, 0, false
+		}
+		if string(hdr[4:8]) == name {
+			return off + header, off + boxSize, true
+		}
+		off += boxSize
+	}
+	return 0, 0, false
+}
+
+// wavDuration divides the size of the data chunk by the byte rate from
+// the fmt chunk
+func wavDuration(r io.ReaderAt, size int64) float64 {
+	var byteRate uint32
+	hdr := make([]byte, 8)
+	for off := int64(12); off+8 <= size; {
+		if _, err := r.ReadAt(hdr, off); err != nil {
+			return 0
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(hdr[4:8]))
+		switch string(hdr[0:4]) {
+		case "fmt ":
+			fmtChunk := make([]byte, 12)
+			if _, err := r.ReadAt(fmtChunk, off+8); err != nil {
+				return 0
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtChunk[8:12])
+		case "data":
+			if byteRate == 0 {
+				return 0
+			}
+			// Streamed recordings leave the size at its maximum
+			if chunkSize == 0xFFFFFFFF || chunkSize > size-off-8 {
+				chunkSize = size - off - 8
+			}
+			return float64(chunkSize) / float64(byteRate)
+		}
+		off += 8 + chunkSize + chunkSize%2 // chunks are word aligned
+	}
+	return 0
+}
+
+// MPEG audio Layer III tables, indexed by the header fields
+var (
+	mp3Bitrates = [2][15]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}, // MPEG-1
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},     // MPEG-2 and 2.5
+	}
+	mp3SampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// mp3Duration reads the first Layer III frame after any ID3v2 tag. A
+// Xing/Info header gives the exact frame count of VBR files; otherwise
+// the file is assumed to be constant bitrate.
+func mp3Duration(r io.ReaderAt, size int64) float64 {
+	var start int64
+	tag := make([]byte, 10)
+	if _, err := r.ReadAt(tag, 0); err != nil {
+		return 0
+	}
+	if string(tag[0:3]) == "ID3" {
+		// Synchsafe size: 7 bits per byte
+		start = 10 + (int64(tag[6])<<21 | int64(tag[7])<<14 | int64(tag[8])<<7 | int64(tag[9]))
+		if tag[5]&0x10 != 0 {
+			start += 10 // footer
+		}
+	}
+
+	frame := make([]byte, 4+32+12)
+	if _, err := r.ReadAt(frame[:4], start); err != nil {
+		return 0
+	}
+	version, layer := (frame[1]>>3)&3, (frame[1]>>1)&3
+	if frame[0] != 0xFF || frame[1]&0xE0 != 0xE0 || layer != 1 || version == 1 {
+		return 0
+	}
+	rates, ok := mp3SampleRates[version]
+	bitrateIndex, rateIndex := frame[2]>>4, (frame[2]>>2)&3
+	if !ok || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return 0
+	}
+	sampleRate := rates[rateIndex]
+	mono := frame[3]>>6 == 3
+
+	// The Xing header follows the side information, whose size depends on
+	// the version and channel count
+	samplesPerFrame, sideInfo, table := 576, 17, 1
+	if mono {
+		sideInfo = 9
+	}
+	if version == 3 {
+		samplesPerFrame, sideInfo, table = 1152, 32, 0
+		if mono {
+			sideInfo = 17
+		}
+	}
+	bitrate := mp3Bitrates[table][bitrateIndex] * 1000
+
+	if n, _ := r.ReadAt(frame[4:], start+4); n >= sideInfo+12 {
+		xing := frame[4+sideInfo:]
+		if tag := string(xing[0:4]); tag == "Xing" || tag == "Info" {
+			if flags := binary.BigEndian.Uint32(xing[4:8]); flags&1 != 0 {
+				frames := binary.BigEndian.Uint32(xing[8:12])
+				return float64(frames) * float64(samplesPerFrame) / float64(sampleRate)
+			}
+		}
+	}
+	return float64(size-start) * 8 / float64(bitrate)
+}
@@ -0,0 +1,81 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// maxTranscoderOutput bounds how much of a failed command's output ends
+// up in the error
+const maxTranscoderOutput = 512
+
+// renditionTypes covers the files transcoders usually write, so their type
+// doesn't depend on the host having a MIME database
+var renditionTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".webm": "video/webm",
+	".ts":   "video/mp2t",
+	".m4a":  "audio/mp4",
+	".mp3":  "audio/mpeg",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".opus": "audio/ogg",
+	".wav":  "audio/wav",
+	".m3u8": "application/vnd.apple.mpegurl",
+	".vtt":  "text/vtt",
+}
+
+// TypeByExtension returns the MIME type of a rendition from its file name
+func TypeByExtension(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if mimeType, ok := renditionTypes[ext]; ok {
+		return mimeType
+	}
+	if parsed, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+		return parsed
+	}
+	return "application/octet-stream"
+}
+
+// CommandTranscoder runs an external program, typically ffmpeg or a
+// script wrapping it, once per upload. The placeholders {input},
+// {output_dir} and {mime} in its arguments are replaced with the uploaded
+// file, the directory renditions must be written to and the upload's MIME
+// type.
+type CommandTranscoder struct {
+	command []string
+}
+
+// NewCommandTranscoder creates a transcoder for command, the program
+// followed by its arguments
+func NewCommandTranscoder(command []string) (*CommandTranscoder, error) {
+	if len(command) == 0 || command[0] == "" {
+		return nil, fmt.Errorf("transcoder command is empty")
+	}
+	return &CommandTranscoder{command: command}, nil
+}
+
+// Transcode runs the command for one file. It is killed when ctx ends.
+func (t *CommandTranscoder) Transcode(ctx context.Context, src, mimeType, outDir string) error {
+	placeholders := strings.NewReplacer("{input}", src, "{output_dir}", outDir, "{mime}", mimeType)
+	args := make([]string, len(t.command))
+	for i, arg := range t.command {
+		args[i] = placeholders.Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = outDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		output := strings.TrimSpace(string(out))
+		if len(output) > maxTranscoderOutput {
+			output = "..." + output[len(output)-maxTranscoderOutput:]
+		}
+		return fmt.Errorf("transcoder failed: %w: %s", err, output)
+	}
+	return nil
+}
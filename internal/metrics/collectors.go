@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+)
+
+var (
+	ipfsOpsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ipfs", "operations_total"),
+		"IPFS operations, by operation and result (ok, error or timeout).",
+		[]string{"op", "result"}, nil,
+	)
+	ipfsLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ipfs", "operation_duration_seconds"),
+		"Latency of IPFS operations.",
+		[]string{"op"}, nil,
+	)
+	pubsubDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pubsub", "messages_total"),
+		"Pubsub messages, by topic and what happened to them.",
+		[]string{"topic", "event"}, nil,
+	)
+	topicPeersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pubsub", "topic_peers"),
+		"Peers subscribed to each topic this node has joined.",
+		[]string{"topic"}, nil,
+	)
+	peersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "p2p", "peers"),
+		"Peers currently connected.",
+		nil, nil,
+	)
+	routingTableDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "p2p", "dht_routing_table_peers"),
+		"Peers in the DHT routing table.",
+		nil, nil,
+	)
+	bandwidthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "p2p", "bandwidth_bytes_total"),
+		"Bytes exchanged with peers, by direction (in or out).",
+		[]string{"direction"}, nil,
+	)
+	pinsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pins", "ledger_entries"),
+		"Pin ledger entries, by status (pending, pinned or failed).",
+		[]string{"status"}, nil,
+	)
+	indexDocsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "search", "index_documents"),
+		"Documents in the search index.",
+		nil, nil,
+	)
+)
+
+// scrapeTimeout bounds collectors that read from a store
+const scrapeTimeout = 5 * time.Second
+
+// ipfsCollector reads ipfs.Metrics at scrape time
+type ipfsCollector struct {
+	source *ipfs.Metrics
+}
+
+func (c *ipfsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ipfsOpsDesc
+	ch <- ipfsLatencyDesc
+}
+
+func (c *ipfsCollector) Collect(ch chan<- prometheus.Metric) {
+	for op, stats := range c.source.Snapshot() {
+		// Timeouts are a subset of errors; report each outcome once
+		ok := stats.Count - stats.Errors
+		ch <- prometheus.MustNewConstMetric(ipfsOpsDesc, prometheus.CounterValue, float64(ok), op, "ok")
+		ch <- prometheus.MustNewConstMetric(ipfsOpsDesc, prometheus.CounterValue, float64(stats.Errors-stats.Timeouts), op, "error")
+		ch <- prometheus.MustNewConstMetric(ipfsOpsDesc, prometheus.CounterValue, float64(stats.Timeouts), op, "timeout")
+
+		buckets := make(map[float64]uint64, len(stats.Buckets))
+		for _, bucket := range stats.Buckets {
+			buckets[bucket.Le] = uint64(bucket.Count)
+		}
+		ch <- prometheus.MustNewConstHistogram(ipfsLatencyDesc, uint64(stats.Count), stats.LatencySum, buckets, op)
+	}
+}
+
+// pubsubCollector reads the node's pubsub counters at scrape time
+type pubsubCollector struct {
+	node *p2p.P2PNode
+}
+
+func (c *pubsubCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pubsubDesc
+}
+
+func (c *pubsubCollector) Collect(ch chan<- prometheus.Metric) {
+	for topic, stats := range c.node.PubsubStats() {
+		for event, count := range map[string]int64{
+			"published":     stats.Published,
+			"received":      stats.Received,
+			"rejected":      stats.Rejected,
+			"duplicate":     stats.Duplicate,
+			"undeliverable": stats.Undeliverable,
+		} {
+			ch <- prometheus.MustNewConstMetric(pubsubDesc, prometheus.CounterValue, float64(count), topic, event)
+		}
+	}
+}
+
+// networkCollector reads the node's peer counts and traffic at scrape time
+type networkCollector struct {
+	node *p2p.P2PNode
+}
+
+func (c *networkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- peersDesc
+	ch <- routingTableDesc
+	ch <- bandwidthDesc
+	ch <- topicPeersDesc
+}
+
+func (c *networkCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(peersDesc, prometheus.GaugeValue, float64(c.node.GetPeerCount()))
+	ch <- prometheus.MustNewConstMetric(routingTableDesc, prometheus.GaugeValue, float64(c.node.RoutingTableSize()))
+
+	bandwidth := c.node.Bandwidth()
+	ch <- prometheus.MustNewConstMetric(bandwidthDesc, prometheus.CounterValue, float64(bandwidth.TotalIn), "in")
+	ch <- prometheus.MustNewConstMetric(bandwidthDesc, prometheus.CounterValue, float64(bandwidth.TotalOut), "out")
+
+	for topic, peers := range c.node.TopicPeers() {
+		ch <- prometheus.MustNewConstMetric(topicPeersDesc, prometheus.GaugeValue, float64(peers), topic)
+	}
+}
+
+// PinSummarizer counts pin ledger entries by status
+type PinSummarizer interface {
+	Summary(ctx context.Context) (*service.PinLedgerSummary, error)
+}
+
+// pinCollector reads the pin ledger at scrape time. A failed read leaves
+// the series out of the scrape rather than reporting zeros.
+type pinCollector struct {
+	ledger PinSummarizer
+}
+
+func (c *pinCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pinsDesc
+}
+
+func (c *pinCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+	summary, err := c.ledger.Summary(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(pinsDesc, err)
+		return
+	}
+	for status, count := range summary.ByStatus {
+		ch <- prometheus.MustNewConstMetric(pinsDesc, prometheus.GaugeValue, float64(count), status)
+	}
+}
+
+// DocumentCounter reports the size of the search index
+type DocumentCounter interface {
+	Count() (uint64, error)
+}
+
+// indexCollector reads the search index size at scrape time
+type indexCollector struct {
+	index DocumentCounter
+}
+
+func (c *indexCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- indexDocsDesc
+}
+
+func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
+	count, err := c.index.Count()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(indexDocsDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(indexDocsDesc, prometheus.GaugeValue, float64(count))
+}
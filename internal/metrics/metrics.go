@@ -0,0 +1,187 @@
+// Package metrics exposes the node's operational metrics in the Prometheus
+// text format. Subsystems that already keep their own counters, like the
+// IPFS client and pubsub, are read at scrape time; everything else is
+// observed as it happens.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+)
+
+const namespace = "newsp2p"
+
+// Metrics holds the node's collectors in a registry of its own, so /metrics
+// shows only what this process registered
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	repoDuration *prometheus.HistogramVec
+	searchTime   *prometheus.HistogramVec
+	syncs        *prometheus.CounterVec
+	syncArticles *prometheus.CounterVec
+	syncLastSeen prometheus.Gauge
+	events       *prometheus.CounterVec
+}
+
+// New creates the collectors, along with the standard Go runtime and
+// process metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to serve HTTP requests, by route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_operation_duration_seconds",
+			Help:      "Latency of repository calls, by repository, operation and result.",
+			Buckets:   []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		}, []string{"repo", "op", "result"}),
+		searchTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "search_duration_seconds",
+			Help:      "Search latency, by scope (local or network) or suggest, and result.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"kind", "result"}),
+		syncs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sync_peer_syncs_total",
+			Help:      "Article syncs with individual peers, by result.",
+		}, []string{"result"}),
+		syncArticles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sync_articles_total",
+			Help:      "Articles received from peers while syncing (received), and how many of them were new here (new).",
+		}, []string{"kind"}),
+		syncLastSeen: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "sync_last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful sync with a peer.",
+		}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Events published on the node's event bus, by type.",
+		}, []string{"type"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.repoDuration,
+		m.searchTime,
+		m.syncs,
+		m.syncArticles,
+		m.syncLastSeen,
+		m.events,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTP records a served request. route is the matched pattern, not
+// the raw path, so IDs don't explode the label set.
+func (m *Metrics) ObserveHTTP(method, route string, status int, d time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+// ObserveRepoOp records a repository call
+func (m *Metrics) ObserveRepoOp(repo, op string, d time.Duration, err error) {
+	m.repoDuration.WithLabelValues(repo, op, result(err)).Observe(d.Seconds())
+}
+
+// ObserveSearch records a search or suggest call
+func (m *Metrics) ObserveSearch(kind string, d time.Duration, err error) {
+	m.searchTime.WithLabelValues(kind, result(err)).Observe(d.Seconds())
+}
+
+// ObserveSync records the outcome of syncing with one peer
+func (m *Metrics) ObserveSync(progress domain.SyncProgress) {
+	if progress.Error != "" {
+		m.syncs.WithLabelValues("error").Inc()
+		return
+	}
+	m.syncs.WithLabelValues("ok").Inc()
+	m.syncArticles.WithLabelValues("received").Add(float64(progress.Received))
+	m.syncArticles.WithLabelValues("new").Add(float64(progress.New))
+	m.syncLastSeen.SetToCurrentTime()
+}
+
+// HandleEvent makes Metrics an event sink: every event is counted, and
+// sync progress feeds the sync metrics
+func (m *Metrics) HandleEvent(ctx context.Context, event domain.Event) error {
+	m.events.WithLabelValues(event.Type).Inc()
+	if progress, ok := event.Data.(domain.SyncProgress); ok {
+		m.ObserveSync(progress)
+	}
+	return nil
+}
+
+// RegisterIPFS exports the IPFS client's operation counters and latency
+// histograms
+func (m *Metrics) RegisterIPFS(source *ipfs.Metrics) {
+	m.registry.MustRegister(&ipfsCollector{source: source})
+}
+
+// RegisterPubsub exports the node's per-topic pubsub message counts
+func (m *Metrics) RegisterPubsub(node *p2p.P2PNode) {
+	m.registry.MustRegister(&pubsubCollector{node: node})
+}
+
+// RegisterNetwork exports the node's connected peers, DHT routing table
+// size, bandwidth and subscribers per topic
+func (m *Metrics) RegisterNetwork(node *p2p.P2PNode) {
+	m.registry.MustRegister(&networkCollector{node: node})
+}
+
+// RegisterPinLedger exports the pin ledger's entries by status
+func (m *Metrics) RegisterPinLedger(ledger PinSummarizer) {
+	m.registry.MustRegister(&pinCollector{ledger: ledger})
+}
+
+// RegisterSearchIndex exports the number of indexed documents
+func (m *Metrics) RegisterSearchIndex(index DocumentCounter) {
+	m.registry.MustRegister(&indexCollector{index: index})
+}
+
+// result labels an outcome. Lookups of missing records are expected, so
+// they are told apart from failures.
+func result(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, domain.ErrArticleNotFound), errors.Is(err, domain.ErrUserNotFound):
+		return "not_found"
+	default:
+		return "error"
+	}
+}
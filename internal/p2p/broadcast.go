@@ -2,13 +2,18 @@ package p2p
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
 
@@ -17,6 +22,10 @@ const (
 	TopicFeeds     = "newsp2p/feeds/v1"
 	TopicVotes     = "newsp2p/votes/v1"
 	TopicModerator = "newsp2p/moderation/v1"
+	TopicOpLog     = "newsp2p/oplog/v1"
+
+	TopicEndorsements = "newsp2p/endorsements/v1"
+	TopicCircles      = "newsp2p/circles/v1"
 )
 
 // Ensure pubsub is imported
@@ -30,6 +39,7 @@ type ArticleMessage struct {
 	Timestamp int64           `json:"timestamp"`
 	Signature string          `json:"signature"`
 	PeerID    string          `json:"peer_id"`
+	Proof     *ArticleProof   `json:"proof,omitempty"` // required for authors without an established reputation
 }
 
 // FeedMessage represents a feed update message
@@ -41,7 +51,7 @@ type FeedMessage struct {
 	PeerID    string        `json:"peer_id"`
 }
 
-// VoteMessage represents a content vote/rating
+// VoteMessage represents a content vote/rating, signed by the voter's key
 type VoteMessage struct {
 	ArticleID string `json:"article_id"`
 	VoterDID  string `json:"voter_did"`
@@ -51,16 +61,52 @@ type VoteMessage struct {
 	Signature string `json:"signature"`
 }
 
-// ModerationMessage represents a moderation action
+// ToDomain converts the message to the vote it carries
+func (m *VoteMessage) ToDomain() *domain.Vote {
+	return &domain.Vote{
+		ArticleID: m.ArticleID,
+		VoterDID:  m.VoterDID,
+		Value:     m.Vote,
+		Reason:    m.Reason,
+		Timestamp: m.Timestamp,
+		Signature: m.Signature,
+	}
+}
+
+// ModerationMessage represents a moderation action. ReporterDID is the
+// peer ID of the node that signed it.
 type ModerationMessage struct {
 	ArticleID   string `json:"article_id"`
-	Action      string `json:"action"` // "report", "flag", "vote_remove"
+	Action      string `json:"action"` // "report", "flag", "dismiss", "vote_remove"
 	Reason      string `json:"reason"`
 	ReporterDID string `json:"reporter_did"`
 	Timestamp   int64  `json:"timestamp"`
 	Signature   string `json:"signature"`
 }
 
+// signableContent returns the bytes covered by the message signature
+func (m *ModerationMessage) signableContent() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// Circle message types
+const (
+	CircleMessageKeys    = "keys"    // a circle's key distribution for an epoch
+	CircleMessageArticle = "article" // an article sealed for a circle
+)
+
+// CircleMessage carries a circle's signed key message or an article
+// sealed with its key, stored on IPFS under CID. Only nodes with a member
+// of the circle can read either.
+type CircleMessage struct {
+	Type    string                   `json:"type"`
+	Keys    *domain.CircleKeyMessage `json:"keys,omitempty"`
+	Article *domain.SealedArticle    `json:"article,omitempty"`
+	CID     string                   `json:"cid,omitempty"`
+}
+
 // Broadcaster handles P2P content broadcasting
 type Broadcaster struct {
 	node   *P2PNode
@@ -69,7 +115,12 @@ type Broadcaster struct {
 	articleHandlers     []ArticleHandler
 	feedHandlers        []FeedHandler
 	voteHandlers        []VoteHandler
+	endorsementHandlers []EndorsementHandler
+	circleHandlers      []CircleHandler
 	moderationHandlers  []ModerationHandler
+	opHandlers          []OpHandler
+	events              events.Publisher // optional; receives gossiped articles
+	proofs              *proofPolicy     // optional; set with RequireProofOfWork
 	mu                  sync.RWMutex
 
 	ctx    context.Context
@@ -77,6 +128,25 @@ type Broadcaster struct {
 	wg     sync.WaitGroup
 }
 
+// proofPolicy requires proof of work on articles from authors whose
+// reputation isn't established
+type proofPolicy struct {
+	reputation *ReputationSystem
+	signer     *auth.ArticleSigner
+	difficulty int
+}
+
+// exempt reports whether article is validly signed by a key whose did:key
+// has an established reputation. The username is the author's claim, not
+// an identity, so it is never consulted.
+func (p *proofPolicy) exempt(article *domain.Article) bool {
+	did := authorDID(article.AuthorPubKey)
+	if did == "" || !p.reputation.IsEstablished(did) {
+		return false
+	}
+	return p.signer.VerifyArticle(article) == nil
+}
+
 // ArticleHandler handles incoming article messages
 type ArticleHandler func(*ArticleMessage) error
 
@@ -86,9 +156,18 @@ type FeedHandler func(*FeedMessage) error
 // VoteHandler handles incoming vote messages
 type VoteHandler func(*VoteMessage) error
 
+// EndorsementHandler handles incoming endorsements, not yet verified
+type EndorsementHandler func(*domain.Endorsement) error
+
+// CircleHandler handles incoming circle messages, not yet verified
+type CircleHandler func(*CircleMessage) error
+
 // ModerationHandler handles incoming moderation messages
 type ModerationHandler func(*ModerationMessage) error
 
+// OpHandler handles incoming, signature-verified article store operations
+type OpHandler func(*domain.ArticleOp) error
+
 // NewBroadcaster creates a new broadcaster
 func NewBroadcaster(node *P2PNode, log *logger.Logger) *Broadcaster {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -99,7 +178,10 @@ func NewBroadcaster(node *P2PNode, log *logger.Logger) *Broadcaster {
 		articleHandlers:     make([]ArticleHandler, 0),
 		feedHandlers:        make([]FeedHandler, 0),
 		voteHandlers:        make([]VoteHandler, 0),
+		endorsementHandlers: make([]EndorsementHandler, 0),
+		circleHandlers:      make([]CircleHandler, 0),
 		moderationHandlers:  make([]ModerationHandler, 0),
+		opHandlers:          make([]OpHandler, 0),
 		ctx:                 ctx,
 		cancel:              cancel,
 	}
@@ -108,7 +190,7 @@ func NewBroadcaster(node *P2PNode, log *logger.Logger) *Broadcaster {
 // Start starts the broadcaster
 func (b *Broadcaster) Start() error {
 	// Join topics
-	topics := []string{TopicArticles, TopicFeeds, TopicVotes, TopicModerator}
+	topics := []string{TopicArticles, TopicFeeds, TopicVotes, TopicModerator, TopicOpLog, TopicEndorsements, TopicCircles}
 	for _, topic := range topics {
 		if _, err := b.node.JoinTopic(topic); err != nil {
 			return fmt.Errorf("failed to join topic %s: %w", topic, err)
@@ -116,11 +198,14 @@ func (b *Broadcaster) Start() error {
 	}
 
 	// Start subscribers
-	b.wg.Add(4)
+	b.wg.Add(7)
 	go b.subscribeArticles()
 	go b.subscribeFeeds()
 	go b.subscribeVotes()
+	go b.subscribeEndorsements()
+	go b.subscribeCircles()
 	go b.subscribeModeration()
+	go b.subscribeOps()
 
 	b.logger.Info("Broadcaster started")
 	return nil
@@ -146,6 +231,14 @@ func (b *Broadcaster) BroadcastArticle(msgType string, article *domain.Article)
 		msg.ArticleID = article.ID
 	}
 
+	if policy := b.proofPolicy(); policy != nil && needsProof(msg) && !policy.exempt(article) {
+		proof, err := SolveArticleProof(b.ctx, article, policy.difficulty)
+		if err != nil {
+			return fmt.Errorf("failed to solve article proof of work: %w", err)
+		}
+		msg.Proof = proof
+	}
+
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal article message: %w", err)
@@ -181,9 +274,16 @@ func (b *Broadcaster) BroadcastFeed(msgType string, feed *domain.Feed) error {
 	return nil
 }
 
-// BroadcastVote broadcasts a vote
-func (b *Broadcaster) BroadcastVote(vote *VoteMessage) error {
-	data, err := json.Marshal(vote)
+// BroadcastVote broadcasts a vote already signed by the voter
+func (b *Broadcaster) BroadcastVote(vote *domain.Vote) error {
+	data, err := json.Marshal(&VoteMessage{
+		ArticleID: vote.ArticleID,
+		VoterDID:  vote.VoterDID,
+		Vote:      vote.Value,
+		Reason:    vote.Reason,
+		Timestamp: vote.Timestamp,
+		Signature: vote.Signature,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to marshal vote: %w", err)
 	}
@@ -192,12 +292,76 @@ func (b *Broadcaster) BroadcastVote(vote *VoteMessage) error {
 		return fmt.Errorf("failed to broadcast vote: %w", err)
 	}
 
-	b.logger.Debug("Broadcast vote", "article_id", vote.ArticleID, "vote", vote.Vote)
+	b.logger.Debug("Broadcast vote", "article_id", vote.ArticleID, "vote", vote.Value)
 	return nil
 }
 
-// BroadcastModeration broadcasts a moderation action
+// BroadcastEndorsement broadcasts an endorsement already signed by the
+// endorser
+func (b *Broadcaster) BroadcastEndorsement(endorsement *domain.Endorsement) error {
+	data, err := json.Marshal(endorsement)
+	if err != nil {
+		return fmt.Errorf("failed to marshal endorsement: %w", err)
+	}
+
+	if err := b.node.Publish(TopicEndorsements, data); err != nil {
+		return fmt.Errorf("failed to broadcast endorsement: %w", err)
+	}
+
+	b.logger.Debug("Broadcast endorsement", "endorser", endorsement.Endorser, "subject", endorsement.Subject, "revoked", endorsement.Revoked)
+	return nil
+}
+
+// BroadcastCircleKeys broadcasts a circle key message already signed by
+// the circle's owner
+func (b *Broadcaster) BroadcastCircleKeys(msg *domain.CircleKeyMessage) error {
+	return b.broadcastCircle(&CircleMessage{Type: CircleMessageKeys, Keys: msg})
+}
+
+// BroadcastSealedArticle broadcasts an article sealed for its circle,
+// stored on IPFS under cid
+func (b *Broadcaster) BroadcastSealedArticle(cid string, sealed *domain.SealedArticle) error {
+	return b.broadcastCircle(&CircleMessage{Type: CircleMessageArticle, Article: sealed, CID: cid})
+}
+
+func (b *Broadcaster) broadcastCircle(msg *CircleMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal circle message: %w", err)
+	}
+
+	if err := b.node.Publish(TopicCircles, data); err != nil {
+		return fmt.Errorf("failed to broadcast circle message: %w", err)
+	}
+
+	b.logger.Debug("Broadcast circle message", "type", msg.Type, "cid", msg.CID)
+	return nil
+}
+
+// BroadcastModerationAction signs a moderation action as this node and
+// broadcasts it
+func (b *Broadcaster) BroadcastModerationAction(articleID, action, reason string) error {
+	return b.BroadcastModeration(&ModerationMessage{
+		ArticleID: articleID,
+		Action:    action,
+		Reason:    reason,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// BroadcastModeration signs a moderation message with the node key and
+// broadcasts it
 func (b *Broadcaster) BroadcastModeration(msg *ModerationMessage) error {
+	msg.ReporterDID = b.node.GetPeerID().String()
+
+	content, err := msg.signableContent()
+	if err != nil {
+		return fmt.Errorf("failed to encode moderation message: %w", err)
+	}
+	if msg.Signature, err = b.sign(content); err != nil {
+		return fmt.Errorf("failed to sign moderation message: %w", err)
+	}
+
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal moderation message: %w", err)
@@ -211,6 +375,81 @@ func (b *Broadcaster) BroadcastModeration(msg *ModerationMessage) error {
 	return nil
 }
 
+// BroadcastOp signs an article store operation with the node key and publishes it
+func (b *Broadcaster) BroadcastOp(op *domain.ArticleOp) error {
+	op.NodeID = b.node.GetPeerID().String()
+
+	content, err := op.GetSignableContent()
+	if err != nil {
+		return fmt.Errorf("failed to encode op: %w", err)
+	}
+	if op.Signature, err = b.sign(content); err != nil {
+		return fmt.Errorf("failed to sign op: %w", err)
+	}
+
+	data, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to marshal op: %w", err)
+	}
+
+	if err := b.node.Publish(TopicOpLog, data); err != nil {
+		return fmt.Errorf("failed to broadcast op: %w", err)
+	}
+
+	b.logger.Debug("Broadcast article op", "type", op.Type, "article_id", op.ArticleID, "clock", op.Clock)
+	return nil
+}
+
+// RequireProofOfWork makes articles not signed by a key with an established
+// reputation carry a proof of difficulty bits, checked by a topic
+// validator before they are delivered or forwarded. Articles this node
+// broadcasts for such authors get one attached.
+func (b *Broadcaster) RequireProofOfWork(reputation *ReputationSystem, difficulty int) error {
+	if err := b.node.pubsub.RegisterTopicValidator(TopicArticles, b.validateArticle); err != nil {
+		return fmt.Errorf("failed to register article validator: %w", err)
+	}
+
+	b.mu.Lock()
+	b.proofs = &proofPolicy{reputation: reputation, signer: auth.NewArticleSigner(), difficulty: difficulty}
+	b.mu.Unlock()
+
+	b.logger.Info("Requiring proof of work from unestablished authors", "difficulty", difficulty)
+	return nil
+}
+
+func (b *Broadcaster) proofPolicy() *proofPolicy {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.proofs
+}
+
+// needsProof reports whether msg carries an article a proof can cover
+func needsProof(msg *ArticleMessage) bool {
+	return msg.Article != nil && msg.Type != "delete"
+}
+
+// validateArticle rejects articles from unestablished authors without a
+// valid proof of work
+func (b *Broadcaster) validateArticle(ctx context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+	policy := b.proofPolicy()
+	if policy == nil {
+		return pubsub.ValidationAccept
+	}
+
+	var articleMsg ArticleMessage
+	if err := json.Unmarshal(msg.Data, &articleMsg); err != nil {
+		return pubsub.ValidationReject
+	}
+	if !needsProof(&articleMsg) || policy.exempt(articleMsg.Article) {
+		return pubsub.ValidationAccept
+	}
+	if err := VerifyArticleProof(articleMsg.Article, articleMsg.Proof, policy.difficulty); err != nil {
+		b.logger.Debug("Rejected article without proof of work", "article_id", articleMsg.ArticleID, "author", articleMsg.Article.Author, "from", from.String(), "error", err)
+		return pubsub.ValidationReject
+	}
+	return pubsub.ValidationAccept
+}
+
 // OnArticle registers an article handler
 func (b *Broadcaster) OnArticle(handler ArticleHandler) {
 	b.mu.Lock()
@@ -218,6 +457,14 @@ func (b *Broadcaster) OnArticle(handler ArticleHandler) {
 	b.articleHandlers = append(b.articleHandlers, handler)
 }
 
+// SetEventPublisher publishes each article gossiped on the articles topic
+// as a p2p.article event, for whichever sinks consume network input
+func (b *Broadcaster) SetEventPublisher(publisher events.Publisher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = publisher
+}
+
 // OnFeed registers a feed handler
 func (b *Broadcaster) OnFeed(handler FeedHandler) {
 	b.mu.Lock()
@@ -232,6 +479,20 @@ func (b *Broadcaster) OnVote(handler VoteHandler) {
 	b.voteHandlers = append(b.voteHandlers, handler)
 }
 
+// OnEndorsement registers an endorsement handler
+func (b *Broadcaster) OnEndorsement(handler EndorsementHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.endorsementHandlers = append(b.endorsementHandlers, handler)
+}
+
+// OnCircle registers a circle message handler
+func (b *Broadcaster) OnCircle(handler CircleHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.circleHandlers = append(b.circleHandlers, handler)
+}
+
 // OnModeration registers a moderation handler
 func (b *Broadcaster) OnModeration(handler ModerationHandler) {
 	b.mu.Lock()
@@ -239,6 +500,13 @@ func (b *Broadcaster) OnModeration(handler ModerationHandler) {
 	b.moderationHandlers = append(b.moderationHandlers, handler)
 }
 
+// OnOp registers an article store operation handler
+func (b *Broadcaster) OnOp(handler OpHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opHandlers = append(b.opHandlers, handler)
+}
+
 // subscribeArticles subscribes to article messages
 func (b *Broadcaster) subscribeArticles() {
 	defer b.wg.Done()
@@ -281,8 +549,13 @@ func (b *Broadcaster) handleArticleMessage(msg *ArticleMessage) {
 	b.mu.RLock()
 	handlers := make([]ArticleHandler, len(b.articleHandlers))
 	copy(handlers, b.articleHandlers)
+	publisher := b.events
 	b.mu.RUnlock()
 
+	if publisher != nil && msg.Article != nil {
+		publisher.Publish(domain.EventArticleGossip, msg.Article)
+	}
+
 	for _, handler := range handlers {
 		if err := handler(msg); err != nil {
 			b.logger.Warn("Article handler error", "error", err)
@@ -390,6 +663,106 @@ func (b *Broadcaster) handleVoteMessage(msg *VoteMessage) {
 	}
 }
 
+// subscribeEndorsements subscribes to endorsement messages
+func (b *Broadcaster) subscribeEndorsements() {
+	defer b.wg.Done()
+
+	sub, err := b.node.Subscribe(TopicEndorsements)
+	if err != nil {
+		b.logger.Error("Failed to subscribe to endorsements", "error", err)
+		return
+	}
+
+	b.logger.Info("Subscribed to endorsements topic")
+
+	for {
+		msg, err := sub.Next(b.ctx)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			b.logger.Warn("Error reading endorsement message", "error", err)
+			continue
+		}
+
+		if msg.ReceivedFrom == b.node.GetPeerID() {
+			continue
+		}
+
+		var endorsement domain.Endorsement
+		if err := json.Unmarshal(msg.Data, &endorsement); err != nil {
+			b.logger.Warn("Failed to unmarshal endorsement message", "error", err)
+			continue
+		}
+
+		b.handleEndorsementMessage(&endorsement)
+	}
+}
+
+// handleEndorsementMessage handles an endorsement message
+func (b *Broadcaster) handleEndorsementMessage(endorsement *domain.Endorsement) {
+	b.mu.RLock()
+	handlers := make([]EndorsementHandler, len(b.endorsementHandlers))
+	copy(handlers, b.endorsementHandlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(endorsement); err != nil {
+			b.logger.Warn("Endorsement handler error", "error", err)
+		}
+	}
+}
+
+// subscribeCircles subscribes to circle messages
+func (b *Broadcaster) subscribeCircles() {
+	defer b.wg.Done()
+
+	sub, err := b.node.Subscribe(TopicCircles)
+	if err != nil {
+		b.logger.Error("Failed to subscribe to circles", "error", err)
+		return
+	}
+
+	b.logger.Info("Subscribed to circles topic")
+
+	for {
+		msg, err := sub.Next(b.ctx)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			b.logger.Warn("Error reading circle message", "error", err)
+			continue
+		}
+
+		if msg.ReceivedFrom == b.node.GetPeerID() {
+			continue
+		}
+
+		var circleMsg CircleMessage
+		if err := json.Unmarshal(msg.Data, &circleMsg); err != nil {
+			b.logger.Warn("Failed to unmarshal circle message", "error", err)
+			continue
+		}
+
+		b.handleCircleMessage(&circleMsg)
+	}
+}
+
+// handleCircleMessage handles a circle message
+func (b *Broadcaster) handleCircleMessage(msg *CircleMessage) {
+	b.mu.RLock()
+	handlers := make([]CircleHandler, len(b.circleHandlers))
+	copy(handlers, b.circleHandlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(msg); err != nil {
+			b.logger.Warn("Circle handler error", "type", msg.Type, "error", err)
+		}
+	}
+}
+
 // subscribeModeration subscribes to moderation messages
 func (b *Broadcaster) subscribeModeration() {
 	defer b.wg.Done()
@@ -422,6 +795,15 @@ func (b *Broadcaster) subscribeModeration() {
 			continue
 		}
 
+		content, err := moderationMsg.signableContent()
+		if err == nil {
+			err = verifyNodeSignature(moderationMsg.ReporterDID, moderationMsg.Signature, content, msg.GetFrom())
+		}
+		if err != nil {
+			b.logger.Warn("Rejected moderation message", "article_id", moderationMsg.ArticleID, "reporter_did", moderationMsg.ReporterDID, "error", err)
+			continue
+		}
+
 		b.handleModerationMessage(&moderationMsg)
 	}
 }
@@ -439,3 +821,106 @@ func (b *Broadcaster) handleModerationMessage(msg *ModerationMessage) {
 		}
 	}
 }
+
+// subscribeOps subscribes to the article store op log
+func (b *Broadcaster) subscribeOps() {
+	defer b.wg.Done()
+
+	sub, err := b.node.Subscribe(TopicOpLog)
+	if err != nil {
+		b.logger.Error("Failed to subscribe to op log", "error", err)
+		return
+	}
+
+	b.logger.Info("Subscribed to op log topic")
+
+	for {
+		msg, err := sub.Next(b.ctx)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			b.logger.Warn("Error reading op log message", "error", err)
+			continue
+		}
+
+		if msg.ReceivedFrom == b.node.GetPeerID() {
+			continue
+		}
+
+		var op domain.ArticleOp
+		if err := json.Unmarshal(msg.Data, &op); err != nil {
+			b.logger.Warn("Failed to unmarshal op log message", "error", err)
+			continue
+		}
+
+		if err := verifyOp(&op, msg.GetFrom()); err != nil {
+			b.logger.Warn("Rejected article op", "article_id", op.ArticleID, "node_id", op.NodeID, "error", err)
+			continue
+		}
+
+		b.handleOpMessage(&op)
+	}
+}
+
+// verifyOp checks that op was signed by the node that published it
+func verifyOp(op *domain.ArticleOp, from peer.ID) error {
+	content, err := op.GetSignableContent()
+	if err != nil {
+		return err
+	}
+	return verifyNodeSignature(op.NodeID, op.Signature, content, from)
+}
+
+// sign signs content with the node key
+func (b *Broadcaster) sign(content []byte) (string, error) {
+	sig, err := b.node.privKey.Sign(content)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// verifyNodeSignature checks that signature is nodeID's signature over
+// content and that nodeID is the peer that published the message
+func verifyNodeSignature(nodeID, signature string, content []byte, from peer.ID) error {
+	id, err := peer.Decode(nodeID)
+	if err != nil {
+		return fmt.Errorf("invalid node id: %w", err)
+	}
+	if id != from {
+		return fmt.Errorf("node %s does not match publisher %s", id, from)
+	}
+
+	pubKey, err := id.ExtractPublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to extract public key: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	valid, err := pubKey.Verify(content, sig)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return domain.ErrInvalidSignature
+	}
+	return nil
+}
+
+// handleOpMessage handles an article store operation
+func (b *Broadcaster) handleOpMessage(op *domain.ArticleOp) {
+	b.mu.RLock()
+	handlers := make([]OpHandler, len(b.opHandlers))
+	copy(handlers, b.opHandlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(op); err != nil {
+			b.logger.Warn("Op handler error", "error", err)
+		}
+	}
+}
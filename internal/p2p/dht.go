@@ -0,0 +1,61 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// DHTPeer is a peer as the DHT reports it, with whether this node is
+// currently connected to it
+type DHTPeer struct {
+	ID            string   `json:"id"`
+	Addresses     []string `json:"addresses"`
+	Connectedness string   `json:"connectedness"`
+}
+
+// FindPeer looks a peer's addresses up in the DHT. A peer that can't be
+// found returns routing.ErrNotFound.
+func (n *P2PNode) FindPeer(ctx context.Context, id peer.ID) (*DHTPeer, error) {
+	info, err := n.dht.FindPeer(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find peer %s: %w", id, err)
+	}
+	found := n.dhtPeer(info)
+	return &found, nil
+}
+
+// FindProviders asks the DHT for up to limit peers providing c. Finding
+// none is not an error.
+func (n *P2PNode) FindProviders(ctx context.Context, c cid.Cid, limit int) ([]DHTPeer, error) {
+	providers := []DHTPeer{}
+	for info := range n.dht.FindProvidersAsync(ctx, c, limit) {
+		providers = append(providers, n.dhtPeer(info))
+	}
+	// The channel closes early when ctx ends; report that rather than a
+	// partial list passing for a complete one
+	if err := ctx.Err(); err != nil && len(providers) < limit {
+		return providers, fmt.Errorf("provider lookup for %s ended early: %w", c, err)
+	}
+	return providers, nil
+}
+
+// RoutingTableSize is how many peers the DHT routing table holds. Lookups
+// can't succeed while it is empty.
+func (n *P2PNode) RoutingTableSize() int {
+	return n.dht.RoutingTable().Size()
+}
+
+func (n *P2PNode) dhtPeer(info peer.AddrInfo) DHTPeer {
+	found := DHTPeer{
+		ID:            info.ID.String(),
+		Addresses:     make([]string, len(info.Addrs)),
+		Connectedness: n.host.Network().Connectedness(info.ID).String(),
+	}
+	for i, addr := range info.Addrs {
+		found.Addresses[i] = addr.String()
+	}
+	return found
+}
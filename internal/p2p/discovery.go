@@ -14,6 +14,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
 
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
@@ -73,7 +74,7 @@ func NewAutoDiscovery(h host.Host, dataDir string, log *logger.Logger) *AutoDisc
 		cancel:          cancel,
 		host:            h,
 		logger:          log.WithComponent("auto-discovery"),
-		bootstrapURLs:   getDefaultBootstrapURLs(),
+		bootstrapURLs:   DefaultBootstrapURLs(),
 		knownBootstraps: make(map[string]*BootstrapInfo),
 		dataDir:         dataDir,
 	}
@@ -84,8 +85,9 @@ func NewAutoDiscovery(h host.Host, dataDir string, log *logger.Logger) *AutoDisc
 	return ad
 }
 
-// getDefaultBootstrapURLs returns default bootstrap server URLs to check
-func getDefaultBootstrapURLs() []string {
+// DefaultBootstrapURLs returns the bootstrap server URLs auto-discovery
+// checks: BOOTSTRAP_URL when set, then a bootstrap server on this machine
+func DefaultBootstrapURLs() []string {
 	urls := []string{
 		// Local bootstrap server (for development/same network)
 		"http://localhost:8081/bootstrap",
@@ -109,13 +111,22 @@ func (ad *AutoDiscovery) AddBootstrapURL(url string) {
 	ad.bootstrapURLs = append([]string{url}, ad.bootstrapURLs...)
 }
 
-// AddBootstrapPeer adds a known bootstrap peer address
+// AddBootstrapPeer adds a known bootstrap peer address. A /dnsaddr name
+// without a peer ID stands for every peer in its TXT records; they are
+// looked up in the background and dialled once found.
 func (ad *AutoDiscovery) AddBootstrapPeer(addrStr string) error {
 	addr, err := multiaddr.NewMultiaddr(addrStr)
 	if err != nil {
 		return fmt.Errorf("invalid multiaddr: %w", err)
 	}
 
+	if _, err := addr.ValueForProtocol(multiaddr.P_P2P); err != nil {
+		if _, dnsErr := addr.ValueForProtocol(multiaddr.P_DNSADDR); dnsErr == nil {
+			go ad.resolveBootstrapPeers(addr)
+			return nil
+		}
+	}
+
 	peerInfo, err := peer.AddrInfoFromP2pAddr(addr)
 	if err != nil {
 		return fmt.Errorf("invalid peer address: %w", err)
@@ -132,6 +143,31 @@ func (ad *AutoDiscovery) AddBootstrapPeer(addrStr string) error {
 	return nil
 }
 
+// resolveBootstrapPeers adds the peers a /dnsaddr name lists
+func (ad *AutoDiscovery) resolveBootstrapPeers(addr multiaddr.Multiaddr) {
+	ctx, cancel := context.WithTimeout(ad.ctx, 30*time.Second)
+	defer cancel()
+
+	resolved, err := madns.DefaultResolver.Resolve(ctx, addr)
+	if err != nil {
+		ad.logger.Warn("Failed to resolve bootstrap peers", "addr", addr.String(), "error", err)
+		return
+	}
+
+	added := 0
+	for _, r := range resolved {
+		if err := ad.AddBootstrapPeer(r.String()); err != nil {
+			ad.logger.Debug("Skipping resolved bootstrap peer", "addr", r.String(), "error", err)
+			continue
+		}
+		added++
+	}
+	ad.logger.Info("Resolved bootstrap peers", "addr", addr.String(), "peers", added)
+	if added > 0 {
+		ad.connectToBootstraps()
+	}
+}
+
 // Start starts the auto-discovery service
 func (ad *AutoDiscovery) Start() {
 	ad.logger.Info("Starting auto-discovery service")
@@ -386,6 +422,22 @@ func (ad *AutoDiscovery) GetConnectedBootstraps() int {
 	return count
 }
 
+// GetKnownBootstraps returns the number of bootstrap servers this node
+// knows of, connected or not
+func (ad *AutoDiscovery) GetKnownBootstraps() int {
+	ad.mu.RLock()
+	defer ad.mu.RUnlock()
+	return len(ad.knownBootstraps)
+}
+
+// IsBootstrap reports whether a peer is a known bootstrap server
+func (ad *AutoDiscovery) IsBootstrap(id peer.ID) bool {
+	ad.mu.RLock()
+	defer ad.mu.RUnlock()
+	_, ok := ad.knownBootstraps[id.String()]
+	return ok
+}
+
 // OnPeerConnected sets a callback for peer connection events
 func (ad *AutoDiscovery) OnPeerConnected(fn func(peer.ID)) {
 	ad.onPeerConnected = fn
@@ -0,0 +1,253 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+const (
+	// Protocol ID for fetching a single article from a peer
+	ProtocolFetch = "/newsp2p/fetch/1.0.0"
+
+	// Max providers or connected peers asked for one article
+	MaxFetchPeers = 10
+
+	// How long announcing an article in the DHT may take
+	provideTimeout = time.Minute
+)
+
+// FetchRequest asks a peer for the article stored under a CID
+type FetchRequest struct {
+	CID string `json:"cid"`
+}
+
+// FetchResponse carries the article, or none if the peer doesn't have it
+type FetchResponse struct {
+	Article *domain.Article `json:"article,omitempty"`
+}
+
+// LocalArticleLookup finds articles this node stores itself
+type LocalArticleLookup interface {
+	GetLocalByCID(ctx context.Context, cid string) (*domain.Article, error)
+}
+
+// ArticleFetcher retrieves individual articles from other nodes, either
+// those the DHT lists as providers of a CID or connected peers, and serves
+// such requests from the local store
+type ArticleFetcher struct {
+	node   *P2PNode
+	lookup LocalArticleLookup
+	logger *logger.Logger
+}
+
+// NewArticleFetcher creates an article fetcher and registers the fetch protocol
+func NewArticleFetcher(node *P2PNode, lookup LocalArticleLookup, log *logger.Logger) *ArticleFetcher {
+	f := &ArticleFetcher{
+		node:   node,
+		lookup: lookup,
+		logger: log.WithComponent("p2p-fetch"),
+	}
+
+	node.host.SetStreamHandler(protocol.ID(ProtocolFetch), f.handleFetchRequest)
+
+	return f
+}
+
+// Provide announces in the DHT that this node holds the article stored
+// under c, so other nodes find it with FetchFromProviders
+func (f *ArticleFetcher) Provide(ctx context.Context, c string) error {
+	id, err := cid.Decode(c)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidCID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, provideTimeout)
+	defer cancel()
+
+	if err := f.node.dht.Provide(ctx, id, true); err != nil {
+		return fmt.Errorf("failed to provide %s: %w", c, err)
+	}
+	return nil
+}
+
+// FetchFromProviders looks c up in the DHT and asks the providers it finds
+// for the article. The first copy accept approves is returned along with
+// the peer it came from.
+func (f *ArticleFetcher) FetchFromProviders(ctx context.Context, c string, accept func(*domain.Article) error) (*domain.Article, string, error) {
+	id, err := cid.Decode(c)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", domain.ErrInvalidCID, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	peers := make(chan peer.ID)
+	go func() {
+		defer close(peers)
+		for info := range f.node.dht.FindProvidersAsync(ctx, id, MaxFetchPeers) {
+			if info.ID == f.node.host.ID() {
+				continue
+			}
+			// Providers we aren't connected to are dialled on the addresses
+			// the DHT returned
+			f.node.host.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.TempAddrTTL)
+			select {
+			case peers <- info.ID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return f.firstAccepted(ctx, cancel, peers, c, accept)
+}
+
+// FetchFromPeers asks up to MaxFetchPeers connected peers for the article
+// stored under c and returns the first copy accept approves
+func (f *ArticleFetcher) FetchFromPeers(ctx context.Context, c string, accept func(*domain.Article) error) (*domain.Article, string, error) {
+	connected := f.node.host.Network().Peers()
+	rand.Shuffle(len(connected), func(i, j int) { connected[i], connected[j] = connected[j], connected[i] })
+
+	peers := make(chan peer.ID, MaxFetchPeers)
+	for _, p := range connected {
+		if len(peers) == MaxFetchPeers {
+			break
+		}
+		if p != f.node.host.ID() {
+			peers <- p
+		}
+	}
+	close(peers)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	return f.firstAccepted(ctx, cancel, peers, c, accept)
+}
+
+// firstAccepted asks every peer from peers at once, stopping the rest as
+// soon as one returns a copy accept approves. If none does, the error is
+// the reason the last copy was rejected, or ErrArticleNotFound if no peer
+// had one.
+func (f *ArticleFetcher) firstAccepted(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	peers <-chan peer.ID,
+	c string,
+	accept func(*domain.Article) error,
+) (*domain.Article, string, error) {
+	type answer struct {
+		article *domain.Article
+		from    peer.ID
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		rejected error
+		found    = make(chan answer, 1)
+	)
+	for pid := range peers {
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			article, err := f.fetchFrom(ctx, pid, c)
+			if err != nil {
+				f.logger.Debug("Peer fetch failed", "peer", pid.String()[:16], "cid", c, "error", err)
+				return
+			}
+			if err := accept(article); err != nil {
+				f.logger.Warn("Rejected article from peer", "peer", pid.String()[:16], "cid", c, "error", err)
+				mu.Lock()
+				rejected = err
+				mu.Unlock()
+				return
+			}
+			select {
+			case found <- answer{article: article, from: pid}:
+				cancel()
+			default:
+			}
+		}(pid)
+	}
+	wg.Wait()
+
+	select {
+	case a := <-found:
+		return a.article, a.from.String(), nil
+	default:
+	}
+	if rejected != nil {
+		return nil, "", rejected
+	}
+	return nil, "", domain.ErrArticleNotFound
+}
+
+// fetchFrom asks one peer for the article stored under c
+func (f *ArticleFetcher) fetchFrom(ctx context.Context, peerID peer.ID, c string) (*domain.Article, error) {
+	stream, err := f.node.host.NewStream(ctx, peerID, protocol.ID(ProtocolFetch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(stream).Encode(&FetchRequest{CID: c}); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp FetchResponse
+	if err := json.NewDecoder(stream).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Article == nil {
+		return nil, domain.ErrArticleNotFound
+	}
+	return resp.Article, nil
+}
+
+// handleFetchRequest answers a fetch from the local store only, so
+// requests never fan out further
+func (f *ArticleFetcher) handleFetchRequest(stream network.Stream) {
+	defer stream.Close()
+
+	var req FetchRequest
+	if err := json.NewDecoder(bufio.NewReader(stream)).Decode(&req); err != nil {
+		f.logger.Warn("Failed to decode fetch request", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var resp FetchResponse
+	article, err := f.lookup.GetLocalByCID(ctx, req.CID)
+	switch {
+	case err == nil:
+		resp.Article = article
+	case !errors.Is(err, domain.ErrArticleNotFound):
+		f.logger.Warn("Failed to look up fetched article", "cid", req.CID, "error", err)
+	}
+
+	if err := json.NewEncoder(stream).Encode(&resp); err != nil {
+		f.logger.Warn("Failed to send fetch response", "error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package p2p
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/control"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// PeerBlocker decides which peers the node refuses to connect to, e.g.
+// the peers named by subscribed blocklists
+type PeerBlocker interface {
+	PeerBlocked(id string) bool
+}
+
+// Gater is the host's connection gater. It refuses dials to and
+// connections from blocked peers, and allows everything until a blocker
+// is set.
+type Gater struct {
+	mu      sync.RWMutex
+	blocker PeerBlocker
+}
+
+// NewGater creates a gater that allows every peer
+func NewGater() *Gater {
+	return &Gater{}
+}
+
+// SetBlocker starts refusing the peers blocker names
+func (g *Gater) SetBlocker(blocker PeerBlocker) {
+	g.mu.Lock()
+	g.blocker = blocker
+	g.mu.Unlock()
+}
+
+func (g *Gater) blocked(id peer.ID) bool {
+	g.mu.RLock()
+	blocker := g.blocker
+	g.mu.RUnlock()
+	return blocker != nil && blocker.PeerBlocked(id.String())
+}
+
+// InterceptPeerDial refuses to dial blocked peers
+func (g *Gater) InterceptPeerDial(id peer.ID) bool {
+	return !g.blocked(id)
+}
+
+// InterceptAddrDial refuses to dial blocked peers at any address
+func (g *Gater) InterceptAddrDial(id peer.ID, _ multiaddr.Multiaddr) bool {
+	return !g.blocked(id)
+}
+
+// InterceptAccept allows every inbound connection; the peer isn't known
+// until the security handshake
+func (g *Gater) InterceptAccept(network.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptSecured refuses connections once the peer proves to be blocked
+func (g *Gater) InterceptSecured(_ network.Direction, id peer.ID, _ network.ConnMultiaddrs) bool {
+	return !g.blocked(id)
+}
+
+// InterceptUpgraded allows every connection that got this far
+func (g *Gater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
@@ -13,6 +13,7 @@ import (
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/metrics"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
@@ -38,19 +39,37 @@ type P2PNode struct {
 	discovery     *drouting.RoutingDiscovery
 	autoDiscovery *AutoDiscovery
 
-	topics map[string]*pubsub.Topic
-	subs   map[string]*pubsub.Subscription
-	mu     sync.RWMutex
+	topics      map[string]*pubsub.Topic
+	subs        map[string]*pubsub.Subscription
+	pubsubStats *pubsubStats
+	bandwidth   *metrics.BandwidthCounter
+	gater       *Gater
+	mu          sync.RWMutex
 
 	logger *logger.Logger
 }
 
+// DHTProtocolPrefix keeps the node's DHT apart from the public IPFS DHT;
+// only peers running newsp2p answer on it
+const DHTProtocolPrefix = "/liberation"
+
+// ProtocolVersion is the version of the /newsp2p stream protocols this
+// node speaks, checked against a network policy's minimum
+const ProtocolVersion = "1.0.0"
+
 // Config holds P2P node configuration
 type Config struct {
-	ListenAddrs   []string
+	ListenAddrs    []string
 	BootstrapPeers []string
-	ProtocolID    protocol.ID
-	Rendezvous    string
+	ProtocolID     protocol.ID
+	Rendezvous     string
+	DataDir        string // holds node_key and the bootstrap cache
+
+	// Host replaces the libp2p host the node would build, e.g. with one on
+	// an in-memory network for benchmarks. Its peerstore must hold the
+	// host's private key; ListenAddrs and the node_key file are ignored,
+	// and blocked peers are only disconnected, not gated.
+	Host host.Host
 }
 
 // DefaultConfig returns default P2P configuration
@@ -67,6 +86,7 @@ func DefaultConfig() *Config {
 		},
 		ProtocolID: "/liberation/1.0.0",
 		Rendezvous: "liberation-news-network",
+		DataDir:    "data",
 	}
 }
 
@@ -74,37 +94,16 @@ func DefaultConfig() *Config {
 func NewP2PNode(ctx context.Context, cfg *Config, log *logger.Logger) (*P2PNode, error) {
 	ctx, cancel := context.WithCancel(ctx)
 
-	// Load or generate identity
-	privKey, err := loadOrGenerateKey("data/node_key")
-	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("failed to load or generate key: %w", err)
+	dataDir := cfg.DataDir
+	if dataDir == "" {
+		dataDir = "data"
 	}
 
-	// Parse listen addresses
-	var listenAddrs []multiaddr.Multiaddr
-	for _, addrStr := range cfg.ListenAddrs {
-		addr, err := multiaddr.NewMultiaddr(addrStr)
-		if err != nil {
-			cancel()
-			return nil, fmt.Errorf("invalid listen address %s: %w", addrStr, err)
-		}
-		listenAddrs = append(listenAddrs, addr)
-	}
-
-	// Create libp2p host
-	h, err := libp2p.New(
-		libp2p.Identity(privKey),
-		libp2p.ListenAddrs(listenAddrs...),
-		libp2p.DefaultSecurity,
-		libp2p.DefaultTransports,
-		libp2p.NATPortMap(),
-		libp2p.EnableNATService(),
-		libp2p.EnableRelay(),
-	)
+	gater := NewGater()
+	h, privKey, bandwidth, err := newHost(cfg, dataDir, gater)
 	if err != nil {
 		cancel()
-		return nil, fmt.Errorf("failed to create host: %w", err)
+		return nil, err
 	}
 
 	peerID := h.ID()
@@ -116,7 +115,7 @@ func NewP2PNode(ctx context.Context, cfg *Config, log *logger.Logger) (*P2PNode,
 	// Setup DHT for peer discovery with Liberation News protocol prefix
 	kdht, err := dht.New(ctx, h,
 		dht.Mode(dht.ModeServer),
-		dht.ProtocolPrefix("/liberation"),
+		dht.ProtocolPrefix(DHTProtocolPrefix),
 	)
 	if err != nil {
 		h.Close()
@@ -132,9 +131,11 @@ func NewP2PNode(ctx context.Context, cfg *Config, log *logger.Logger) (*P2PNode,
 	}
 
 	// Setup PubSub with Gossip
+	stats := newPubsubStats()
 	ps, err := pubsub.NewGossipSub(ctx, h,
 		pubsub.WithMessageSignaturePolicy(pubsub.StrictSign),
 		pubsub.WithFloodPublish(true),
+		pubsub.WithRawTracer(stats),
 	)
 	if err != nil {
 		h.Close()
@@ -146,21 +147,24 @@ func NewP2PNode(ctx context.Context, cfg *Config, log *logger.Logger) (*P2PNode,
 	discovery := drouting.NewRoutingDiscovery(kdht)
 
 	node := &P2PNode{
-		ctx:       ctx,
-		cancel:    cancel,
-		host:      h,
-		dht:       kdht,
-		pubsub:    ps,
-		privKey:   privKey,
-		peerID:    peerID,
-		discovery: discovery,
-		topics:    make(map[string]*pubsub.Topic),
-		subs:      make(map[string]*pubsub.Subscription),
-		logger:    log.WithComponent("p2p-node"),
+		ctx:         ctx,
+		cancel:      cancel,
+		host:        h,
+		dht:         kdht,
+		pubsub:      ps,
+		privKey:     privKey,
+		peerID:      peerID,
+		discovery:   discovery,
+		topics:      make(map[string]*pubsub.Topic),
+		subs:        make(map[string]*pubsub.Subscription),
+		pubsubStats: stats,
+		bandwidth:   bandwidth,
+		gater:       gater,
+		logger:      log.WithComponent("p2p-node"),
 	}
 
 	// Initialize auto-discovery service
-	node.autoDiscovery = NewAutoDiscovery(h, "data", log)
+	node.autoDiscovery = NewAutoDiscovery(h, dataDir, log)
 
 	// Add configured bootstrap peers to auto-discovery
 	for _, addr := range cfg.BootstrapPeers {
@@ -181,24 +185,61 @@ func NewP2PNode(ctx context.Context, cfg *Config, log *logger.Logger) (*P2PNode,
 	return node, nil
 }
 
-// loadOrGenerateKey loads a private key from file or generates a new one
-func loadOrGenerateKey(path string) (crypto.PrivKey, error) {
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
-		return nil, fmt.Errorf("failed to create directory: %w", err)
+// newHost builds the node's libp2p host from its stored identity, unless
+// the config supplies one. Supplied hosts get no bandwidth counter or
+// connection gater.
+func newHost(cfg *Config, dataDir string, gater *Gater) (host.Host, crypto.PrivKey, *metrics.BandwidthCounter, error) {
+	bandwidth := metrics.NewBandwidthCounter()
+	if cfg.Host != nil {
+		privKey := cfg.Host.Peerstore().PrivKey(cfg.Host.ID())
+		if privKey == nil {
+			return nil, nil, nil, fmt.Errorf("host %s has no private key in its peerstore", cfg.Host.ID())
+		}
+		return cfg.Host, privKey, bandwidth, nil
 	}
 
-	// Try to read key from file
-	if _, err := os.Stat(path); err == nil {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read key file: %w", err)
-		}
-		privKey, err := crypto.UnmarshalPrivateKey(data)
+	// Load or generate identity
+	privKey, err := loadOrGenerateKey(filepath.Join(dataDir, NodeKeyFile))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load or generate key: %w", err)
+	}
+
+	// Parse listen addresses
+	var listenAddrs []multiaddr.Multiaddr
+	for _, addrStr := range cfg.ListenAddrs {
+		addr, err := multiaddr.NewMultiaddr(addrStr)
 		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal private key: %w", err)
+			return nil, nil, nil, fmt.Errorf("invalid listen address %s: %w", addrStr, err)
 		}
-		return privKey, nil
+		listenAddrs = append(listenAddrs, addr)
+	}
+
+	// Create libp2p host
+	h, err := libp2p.New(
+		libp2p.Identity(privKey),
+		libp2p.ListenAddrs(listenAddrs...),
+		libp2p.DefaultSecurity,
+		libp2p.DefaultTransports,
+		libp2p.NATPortMap(),
+		libp2p.EnableNATService(),
+		libp2p.EnableRelay(),
+		libp2p.BandwidthReporter(bandwidth),
+		libp2p.ConnectionGater(gater),
+	)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create host: %w", err)
+	}
+	return h, privKey, bandwidth, nil
+}
+
+// NodeKeyFile is the name of the node's identity key in its data directory
+const NodeKeyFile = "node_key"
+
+// loadOrGenerateKey loads a private key from file or generates a new one
+func loadOrGenerateKey(path string) (crypto.PrivKey, error) {
+	// Try to read key from file
+	if _, err := os.Stat(path); err == nil {
+		return LoadNodeKey(path)
 	}
 
 	// Generate new key
@@ -207,20 +248,43 @@ func loadOrGenerateKey(path string) (crypto.PrivKey, error) {
 		return nil, fmt.Errorf("failed to generate key pair: %w", err)
 	}
 
-	// Save key to file
+	if err := SaveNodeKey(path, privKey); err != nil {
+		return nil, err
+	}
+	return privKey, nil
+}
+
+// LoadNodeKey reads a node key file
+func LoadNodeKey(path string) (crypto.PrivKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	privKey, err := crypto.UnmarshalPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private key: %w", err)
+	}
+	return privKey, nil
+}
+
+// SaveNodeKey writes a node key file, readable only by its owner
+func SaveNodeKey(path string, privKey crypto.PrivKey) error {
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
 	data, err := crypto.MarshalPrivateKey(privKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+		return fmt.Errorf("failed to marshal private key: %w", err)
 	}
 
 	if err := os.WriteFile(path, data, 0600); err != nil {
-		return nil, fmt.Errorf("failed to write key file: %w", err)
+		return fmt.Errorf("failed to write key file: %w", err)
 	}
-
-	return privKey, nil
+	return nil
 }
 
-
 // advertise advertises this node on the network
 func (n *P2PNode) advertise(rendezvous string) {
 	dutil.Advertise(n.ctx, n.discovery, rendezvous)
@@ -331,6 +395,7 @@ func (n *P2PNode) Publish(topicName string, data []byte) error {
 	if err := topic.Publish(n.ctx, data); err != nil {
 		return fmt.Errorf("failed to publish to topic %s: %w", topicName, err)
 	}
+	n.pubsubStats.published(topicName)
 
 	n.logger.Debug("Published to topic", "topic", topicName, "size", len(data))
 	return nil
@@ -402,6 +467,51 @@ func (n *P2PNode) AddBootstrapURL(url string) {
 	}
 }
 
+// AddBootstrapPeer adds a bootstrap peer for auto-discovery to dial, such
+// as one recommended by the network policy
+func (n *P2PNode) AddBootstrapPeer(addr string) error {
+	if n.autoDiscovery == nil {
+		return nil
+	}
+	return n.autoDiscovery.AddBootstrapPeer(addr)
+}
+
+// SetPeerBlocker refuses connections to and from the peers blocker names
+func (n *P2PNode) SetPeerBlocker(blocker PeerBlocker) {
+	n.gater.SetBlocker(blocker)
+}
+
+// ClosePeer disconnects from a peer, e.g. one a blocklist just named.
+// Closing a peer that isn't connected does nothing.
+func (n *P2PNode) ClosePeer(id string) error {
+	pid, err := peer.Decode(id)
+	if err != nil {
+		return fmt.Errorf("invalid peer ID %q: %w", id, err)
+	}
+	return n.host.Network().ClosePeer(pid)
+}
+
+// PeerChangeHandler is told when a peer connects or disconnects, along with
+// the number of peers connected after the change
+type PeerChangeHandler func(id peer.ID, connected bool, peers int)
+
+// OnPeerChange registers a handler for peers joining and leaving. Extra
+// connections to an already connected peer are not reported.
+func (n *P2PNode) OnPeerChange(handler PeerChangeHandler) {
+	n.host.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(net network.Network, conn network.Conn) {
+			if len(net.ConnsToPeer(conn.RemotePeer())) == 1 {
+				handler(conn.RemotePeer(), true, len(net.Peers()))
+			}
+		},
+		DisconnectedF: func(net network.Network, conn network.Conn) {
+			if net.Connectedness(conn.RemotePeer()) != network.Connected {
+				handler(conn.RemotePeer(), false, len(net.Peers()))
+			}
+		},
+	})
+}
+
 // GetAutoDiscovery returns the auto-discovery service
 func (n *P2PNode) GetAutoDiscovery() *AutoDiscovery {
 	return n.autoDiscovery
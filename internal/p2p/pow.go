@@ -0,0 +1,116 @@
+package p2p
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+)
+
+const (
+	// DefaultProofDifficulty is the leading zero bits required of an
+	// article proof; about a million hashes, well under a second to solve
+	DefaultProofDifficulty = 20
+
+	// MaxProofDifficulty bounds the work a publisher will do for one article
+	MaxProofDifficulty = 32
+
+	// EstablishedScore is the reputation at which an author's articles no
+	// longer need a proof of work
+	EstablishedScore = 55.0
+)
+
+var (
+	// ErrProofRequired is returned for an article from an unestablished
+	// author that carries no proof of work
+	ErrProofRequired = errors.New("article from an unestablished author needs a proof of work")
+	// ErrProofInvalid is returned for a proof that doesn't meet the difficulty
+	ErrProofInvalid = errors.New("article proof of work is invalid")
+)
+
+// ArticleProof is a hashcash stamp: SHA-256 over the article's ID, CID and
+// author key and Nonce starts with Difficulty zero bits. It binds to one
+// version of one article, so it can't be reused for another.
+type ArticleProof struct {
+	Difficulty int    `json:"difficulty"`
+	Nonce      uint64 `json:"nonce"`
+}
+
+// proofDigest hashes the article identity with nonce
+func proofDigest(article *domain.Article, nonce uint64) [sha256.Size]byte {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], nonce)
+
+	h := sha256.New()
+	for _, field := range []string{article.ID, article.CID, article.AuthorPubKey} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	h.Write(n[:])
+
+	var digest [sha256.Size]byte
+	h.Sum(digest[:0])
+	return digest
+}
+
+// leadingZeroBits counts the zero bits at the start of digest
+func leadingZeroBits(digest [sha256.Size]byte) int {
+	zeros := 0
+	for _, b := range digest {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
+
+// SolveArticleProof searches for a nonce giving difficulty leading zero bits
+func SolveArticleProof(ctx context.Context, article *domain.Article, difficulty int) (*ArticleProof, error) {
+	if difficulty < 0 || difficulty > MaxProofDifficulty {
+		return nil, fmt.Errorf("proof difficulty must be between 0 and %d, got %d", MaxProofDifficulty, difficulty)
+	}
+
+	for nonce := uint64(0); ; nonce++ {
+		if nonce%(1<<16) == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if leadingZeroBits(proofDigest(article, nonce)) >= difficulty {
+			return &ArticleProof{Difficulty: difficulty, Nonce: nonce}, nil
+		}
+	}
+}
+
+// VerifyArticleProof checks proof is for article and at least difficulty
+// bits of work
+func VerifyArticleProof(article *domain.Article, proof *ArticleProof, difficulty int) error {
+	if proof == nil {
+		return ErrProofRequired
+	}
+	if proof.Difficulty < difficulty {
+		return fmt.Errorf("%w: %d bits, %d required", ErrProofInvalid, proof.Difficulty, difficulty)
+	}
+	if leadingZeroBits(proofDigest(article, proof.Nonce)) < proof.Difficulty {
+		return ErrProofInvalid
+	}
+	return nil
+}
+
+// authorDID returns the did:key for an article's signing key, or "" if
+// key isn't a valid public key
+func authorDID(key string) string {
+	if strings.HasPrefix(key, "did:key:") {
+		return key
+	}
+	pub, err := crypto.PublicKeyFromString(key)
+	if err != nil {
+		return ""
+	}
+	return crypto.DIDKey(pub)
+}
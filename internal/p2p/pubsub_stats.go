@@ -0,0 +1,150 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// TopicStats counts pubsub messages on one topic
+type TopicStats struct {
+	Published     int64 `json:"published"`     // by this node
+	Received      int64 `json:"received"`      // delivered from peers
+	Rejected      int64 `json:"rejected"`      // failed validation or ignored
+	Invalid       int64 `json:"invalid"`       // of those rejected, failed validation or had a bad signature
+	Duplicate     int64 `json:"duplicate"`     // already seen, dropped
+	Undeliverable int64 `json:"undeliverable"` // dropped because a subscriber fell behind
+
+	LastPublished *time.Time `json:"last_published,omitempty"`
+	LastReceived  *time.Time `json:"last_received,omitempty"`
+
+	// Joined is whether this node is in the topic; Peers counts the
+	// subscribed peers it knows of and is 0 when not joined
+	Joined bool `json:"joined"`
+	Peers  int  `json:"peers"`
+}
+
+// invalidReasons are the rejections that mean a peer sent a bad message,
+// as opposed to this node being too busy to validate it or ignoring it
+var invalidReasons = map[string]bool{
+	pubsub.RejectMissingSignature:    true,
+	pubsub.RejectUnexpectedSignature: true,
+	pubsub.RejectUnexpectedAuthInfo:  true,
+	pubsub.RejectInvalidSignature:    true,
+	pubsub.RejectValidationFailed:    true,
+}
+
+// pubsubStats is a pubsub.RawTracer that counts messages per topic. Raw
+// tracers never see this node's own messages, so P2PNode.Publish counts
+// those itself.
+type pubsubStats struct {
+	mu     sync.Mutex
+	topics map[string]*TopicStats
+}
+
+func newPubsubStats() *pubsubStats {
+	return &pubsubStats{topics: make(map[string]*TopicStats)}
+}
+
+// count applies fn to the stats of topic
+func (s *pubsubStats) count(topic string, fn func(*TopicStats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, ok := s.topics[topic]
+	if !ok {
+		stats = &TopicStats{}
+		s.topics[topic] = stats
+	}
+	fn(stats)
+}
+
+// published counts a message this node published on topic
+func (s *pubsubStats) published(topic string) {
+	now := time.Now()
+	s.count(topic, func(stats *TopicStats) {
+		stats.Published++
+		stats.LastPublished = &now
+	})
+}
+
+// snapshot copies the stats of every topic seen so far
+func (s *pubsubStats) snapshot() map[string]TopicStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]TopicStats, len(s.topics))
+	for topic, stats := range s.topics {
+		out[topic] = *stats
+	}
+	return out
+}
+
+func (s *pubsubStats) DeliverMessage(msg *pubsub.Message) {
+	now := time.Now()
+	s.count(msg.GetTopic(), func(stats *TopicStats) {
+		stats.Received++
+		stats.LastReceived = &now
+	})
+}
+
+func (s *pubsubStats) RejectMessage(msg *pubsub.Message, reason string) {
+	s.count(msg.GetTopic(), func(stats *TopicStats) {
+		stats.Rejected++
+		if invalidReasons[reason] {
+			stats.Invalid++
+		}
+	})
+}
+
+func (s *pubsubStats) DuplicateMessage(msg *pubsub.Message) {
+	s.count(msg.GetTopic(), func(stats *TopicStats) { stats.Duplicate++ })
+}
+
+func (s *pubsubStats) UndeliverableMessage(msg *pubsub.Message) {
+	s.count(msg.GetTopic(), func(stats *TopicStats) { stats.Undeliverable++ })
+}
+
+// The remaining RawTracer events are not counted
+func (s *pubsubStats) AddPeer(p peer.ID, proto protocol.ID) {}
+func (s *pubsubStats) RemovePeer(p peer.ID)                 {}
+func (s *pubsubStats) Join(topic string)                    {}
+func (s *pubsubStats) Leave(topic string)                   {}
+func (s *pubsubStats) Graft(p peer.ID, topic string)        {}
+func (s *pubsubStats) Prune(p peer.ID, topic string)        {}
+func (s *pubsubStats) ValidateMessage(msg *pubsub.Message)  {}
+func (s *pubsubStats) ThrottlePeer(p peer.ID)               {}
+func (s *pubsubStats) RecvRPC(rpc *pubsub.RPC)              {}
+func (s *pubsubStats) SendRPC(rpc *pubsub.RPC, p peer.ID)   {}
+func (s *pubsubStats) DropRPC(rpc *pubsub.RPC, p peer.ID)   {}
+
+// PubsubStats returns message counts for every topic seen so far and
+// every topic this node has joined, with the peers subscribed to each
+func (n *P2PNode) PubsubStats() map[string]TopicStats {
+	out := n.pubsubStats.snapshot()
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for name, topic := range n.topics {
+		stats := out[name]
+		stats.Joined = true
+		stats.Peers = len(topic.ListPeers())
+		out[name] = stats
+	}
+	return out
+}
+
+// TopicPeers counts the peers subscribed to each topic this node has joined
+func (n *P2PNode) TopicPeers() map[string]int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	out := make(map[string]int, len(n.topics))
+	for name, topic := range n.topics {
+		out[name] = len(topic.ListPeers())
+	}
+	return out
+}
@@ -1,23 +1,25 @@
 package p2p
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
 
 // ReputationScore represents a user's reputation
-type ReputationScore struct {
-	DID          string    `json:"did"`
-	Score        float64   `json:"score"`        // 0-100 scale
-	ArticleCount int       `json:"article_count"`
-	UpVotes      int       `json:"up_votes"`
-	DownVotes    int       `json:"down_votes"`
-	ReportCount  int       `json:"report_count"`  // Reports against this user
-	LastUpdated  time.Time `json:"last_updated"`
+type ReputationScore = domain.ReputationScore
+
+// ReputationStore persists scores across restarts
+type ReputationStore interface {
+	List(ctx context.Context) ([]*ReputationScore, error)
+	Save(ctx context.Context, scores []*ReputationScore) error
+	Replace(ctx context.Context, scores []*ReputationScore) error
 }
 
 // ReputationEvent represents a reputation-affecting event
@@ -28,11 +30,26 @@ type ReputationEvent struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
-// ReputationSystem manages user reputation
+// ReputationSystem manages user reputation. Scores are kept in memory;
+// with a store set, changed scores are written to it at every snapshot.
 type ReputationSystem struct {
 	scores map[string]*ReputationScore
 	mu     sync.RWMutex
 	logger *logger.Logger
+
+	// remote holds the latest summary from each trusted peer, issuer to
+	// DID to score, blended with local scores by localWeight
+	remote      map[string]map[string]float64
+	localWeight float64
+
+	// endorsements maps a subject to the users vouching for it
+	endorsements map[string]map[string]bool
+
+	store      ReputationStore // optional; set with SetStore
+	dirty      map[string]bool // changed since the last snapshot
+	replaced   bool            // an import replaced every score
+	snapshotMu sync.Mutex      // one snapshot at a time
+	stopChan   chan struct{}
 }
 
 const (
@@ -52,29 +69,260 @@ const (
 	SpamPenalty       = -10.0 // Heavy penalty for spam
 )
 
+// DefaultLocalWeight is the share of a blended score that comes from this
+// node's own observations
+const DefaultLocalWeight = 0.7
+
+const (
+	EndorsementBonus    = 5.0  // Points per endorsement from an established user
+	MaxEndorsementBonus = 15.0 // Most points endorsements can add
+)
+
 // NewReputationSystem creates a new reputation system
 func NewReputationSystem(log *logger.Logger) *ReputationSystem {
 	return &ReputationSystem{
-		scores: make(map[string]*ReputationScore),
-		logger: log.WithComponent("reputation"),
+		scores:      make(map[string]*ReputationScore),
+		remote:       make(map[string]map[string]float64),
+		localWeight:  DefaultLocalWeight,
+		endorsements: make(map[string]map[string]bool),
+		dirty:        make(map[string]bool),
+		logger:       log.WithComponent("reputation"),
+		stopChan:     make(chan struct{}),
 	}
 }
 
-// GetScore retrieves a user's reputation score
-func (rs *ReputationSystem) GetScore(did string) *ReputationScore {
+// SetLocalWeight sets how much this node's own observations count against
+// the summaries of trusted peers, from 0 (peers only) to 1 (local only)
+func (rs *ReputationSystem) SetLocalWeight(weight float64) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.localWeight = max(0, min(1, weight))
+}
+
+// MergeRemote replaces the evidence from issuer with its latest summary.
+// Remote scores are kept apart from local observations: they are never
+// saved, exported or passed on to other peers.
+func (rs *ReputationSystem) MergeRemote(issuer string, scores []*ReputationScore) {
+	evidence := make(map[string]float64, len(scores))
+	for _, score := range scores {
+		if score == nil || score.DID == "" || score.Score != score.Score { // NaN
+			continue
+		}
+		evidence[score.DID] = max(0, min(100, score.Score))
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.remote[issuer] = evidence
+
+	rs.logger.Debug("Merged remote reputation", "issuer", issuer, "scores", len(evidence))
+}
+
+// ForgetRemote drops the evidence from issuer
+func (rs *ReputationSystem) ForgetRemote(issuer string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	delete(rs.remote, issuer)
+}
+
+// SetEndorsement records that endorser vouches for subject, or with active
+// false that it no longer does. Endorsements count toward subject's score
+// while the endorser's own reputation is established.
+func (rs *ReputationSystem) SetEndorsement(endorser, subject string, active bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	endorsers := rs.endorsements[subject]
+	if !active {
+		delete(endorsers, endorser)
+		if len(endorsers) == 0 {
+			delete(rs.endorsements, subject)
+		}
+		return
+	}
+	if endorsers == nil {
+		endorsers = make(map[string]bool)
+		rs.endorsements[subject] = endorsers
+	}
+	endorsers[endorser] = true
+}
+
+// LocalScores returns copies of up to limit of this node's own scores,
+// most recently updated first; these are what it shares with peers
+func (rs *ReputationSystem) LocalScores(limit int) []*ReputationScore {
 	rs.mu.RLock()
-	defer rs.mu.RUnlock()
+	scores := make([]*ReputationScore, 0, len(rs.scores))
+	for _, score := range rs.scores {
+		copied := *score
+		scores = append(scores, &copied)
+	}
+	rs.mu.RUnlock()
 
-	score, exists := rs.scores[did]
-	if !exists {
-		return &ReputationScore{
-			DID:         did,
-			Score:       InitialScore,
-			LastUpdated: time.Now(),
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].LastUpdated.After(scores[j].LastUpdated)
+	})
+	if limit > 0 && len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores
+}
+
+// blend is did's evidence-based score plus a bonus for each established
+// user endorsing it. Callers hold rs.mu.
+func (rs *ReputationSystem) blend(did string) *ReputationScore {
+	blended := rs.evidence(did)
+	for endorser := range rs.endorsements[did] {
+		if rs.evidence(endorser).Score >= EstablishedScore {
+			blended.Endorsements++
+		}
+	}
+	if blended.Endorsements > 0 {
+		bonus := min(MaxEndorsementBonus, EndorsementBonus*float64(blended.Endorsements))
+		blended.Score = min(100, blended.Score+bonus)
+	}
+	return blended
+}
+
+// evidence combines the local score for did, if any, with the average of
+// the trusted peers' scores for it. Callers hold rs.mu.
+func (rs *ReputationSystem) evidence(did string) *ReputationScore {
+	var blended ReputationScore
+	local, exists := rs.scores[did]
+	if exists {
+		blended = *local
+	} else {
+		blended = ReputationScore{DID: did, Score: InitialScore, LastUpdated: time.Now()}
+	}
+
+	var sum float64
+	for _, evidence := range rs.remote {
+		if score, ok := evidence[did]; ok {
+			sum += score
+			blended.Peers++
 		}
 	}
+	if blended.Peers == 0 {
+		return &blended
+	}
+
+	remote := sum / float64(blended.Peers)
+	if exists {
+		blended.Score = rs.localWeight*local.Score + (1-rs.localWeight)*remote
+	} else {
+		// Nothing seen here yet, so the peers' view stands
+		blended.Score = remote
+	}
+	return &blended
+}
+
+// SetStore persists scores to store. Call Load to restore what it holds.
+func (rs *ReputationSystem) SetStore(store ReputationStore) {
+	rs.store = store
+}
+
+// Load replaces the scores in memory with those in the store
+func (rs *ReputationSystem) Load(ctx context.Context) error {
+	if rs.store == nil {
+		return nil
+	}
+	stored, err := rs.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load reputation: %w", err)
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.scores = make(map[string]*ReputationScore, len(stored))
+	for _, score := range stored {
+		rs.scores[score.DID] = score
+	}
+	rs.dirty = make(map[string]bool)
+	rs.replaced = false
+	rs.logger.Info("Loaded reputation", "user_count", len(stored))
 
-	return score
+	return nil
+}
+
+// Snapshot writes the scores changed since the last snapshot to the store
+func (rs *ReputationSystem) Snapshot(ctx context.Context) error {
+	if rs.store == nil {
+		return nil
+	}
+	rs.snapshotMu.Lock()
+	defer rs.snapshotMu.Unlock()
+
+	rs.mu.Lock()
+	replaced, dirty := rs.replaced, rs.dirty
+	changed := make([]*ReputationScore, 0, len(dirty))
+	for did, score := range rs.scores {
+		if replaced || dirty[did] {
+			copied := *score
+			changed = append(changed, &copied)
+		}
+	}
+	rs.replaced, rs.dirty = false, make(map[string]bool)
+	rs.mu.Unlock()
+
+	if !replaced && len(changed) == 0 {
+		return nil
+	}
+
+	var err error
+	if replaced {
+		err = rs.store.Replace(ctx, changed)
+	} else {
+		err = rs.store.Save(ctx, changed)
+	}
+	if err != nil {
+		// Try again at the next snapshot
+		rs.mu.Lock()
+		rs.replaced = rs.replaced || replaced
+		for did := range dirty {
+			rs.dirty[did] = true
+		}
+		rs.mu.Unlock()
+		return fmt.Errorf("failed to save reputation: %w", err)
+	}
+
+	rs.logger.Debug("Saved reputation snapshot", "scores", len(changed), "replaced", replaced)
+	return nil
+}
+
+// Start snapshots changed scores every interval until Stop is called
+func (rs *ReputationSystem) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := rs.Snapshot(ctx); err != nil {
+				rs.logger.Warn("Reputation snapshot failed", "error", err)
+			}
+		case <-rs.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop ends the snapshot loop and saves what changed since the last one
+func (rs *ReputationSystem) Stop() {
+	close(rs.stopChan)
+	if err := rs.Snapshot(context.Background()); err != nil {
+		rs.logger.Warn("Final reputation snapshot failed", "error", err)
+	}
+}
+
+// GetScore retrieves a copy of a user's reputation score, blended with
+// what trusted peers report
+func (rs *ReputationSystem) GetScore(did string) *ReputationScore {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return rs.blend(did)
 }
 
 // RecordEvent records a reputation event
@@ -129,6 +377,7 @@ func (rs *ReputationSystem) RecordEvent(event *ReputationEvent) error {
 	}
 
 	score.LastUpdated = time.Now()
+	rs.dirty[event.DID] = true
 
 	rs.logger.Debug("Reputation updated",
 		"did", event.DID,
@@ -139,26 +388,129 @@ func (rs *ReputationSystem) RecordEvent(event *ReputationEvent) error {
 	return nil
 }
 
+// RecordVote applies a vote on one of did's articles. previous is the
+// voter's earlier vote on that article (0 if none), whose effect is undone
+// first so a changed or repeated vote is only counted once.
+func (rs *ReputationSystem) RecordVote(did string, previous, vote int) {
+	if previous == vote {
+		return
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	score, exists := rs.scores[did]
+	if !exists {
+		score = &ReputationScore{
+			DID:   did,
+			Score: InitialScore,
+		}
+		rs.scores[did] = score
+	}
+
+	switch {
+	case previous > 0:
+		score.UpVotes--
+		score.Score -= UpVoteScore
+	case previous < 0:
+		score.DownVotes--
+		score.Score -= DownVoteScore
+	}
+	switch {
+	case vote > 0:
+		score.UpVotes++
+		score.Score += UpVoteScore
+	case vote < 0:
+		score.DownVotes++
+		score.Score += DownVoteScore
+	}
+
+	score.Score = max(0, min(100, score.Score))
+	score.LastUpdated = time.Now()
+	rs.dirty[did] = true
+
+	rs.logger.Debug("Reputation updated by vote",
+		"did", did,
+		"previous", previous,
+		"vote", vote,
+		"new_score", score.Score,
+	)
+}
+
+// RecordModeration charges did the report penalty for an article a
+// moderator acted on, or with penalized false refunds it when the action
+// is reversed on appeal
+func (rs *ReputationSystem) RecordModeration(did string, penalized bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	score, exists := rs.scores[did]
+	if !exists {
+		score = &ReputationScore{
+			DID:   did,
+			Score: InitialScore,
+		}
+		rs.scores[did] = score
+	}
+
+	if penalized {
+		score.ReportCount++
+		score.Score += ReportPenalty
+	} else {
+		score.ReportCount = max(0, score.ReportCount-1)
+		score.Score -= ReportPenalty
+	}
+
+	score.Score = max(0, min(100, score.Score))
+	score.LastUpdated = time.Now()
+	rs.dirty[did] = true
+
+	rs.logger.Debug("Reputation updated by moderation",
+		"did", did,
+		"penalized", penalized,
+		"new_score", score.Score,
+	)
+}
+
 // IsTrusted checks if a DID is trusted (reputation > 60)
 func (rs *ReputationSystem) IsTrusted(did string) bool {
 	score := rs.GetScore(did)
 	return score.Score >= 60.0
 }
 
+// IsEstablished checks if a DID's reputation, including what trusted peers
+// report, has risen far enough that its articles need no proof of work
+func (rs *ReputationSystem) IsEstablished(did string) bool {
+	return rs.GetScore(did).Score >= EstablishedScore
+}
+
 // IsLowReputation checks if a DID has low reputation (< 30)
 func (rs *ReputationSystem) IsLowReputation(did string) bool {
 	score := rs.GetScore(did)
 	return score.Score < 30.0
 }
 
-// GetTopUsers returns users with highest reputation
+// GetTopUsers returns copies of the scores of the users with the highest
+// reputation
 func (rs *ReputationSystem) GetTopUsers(limit int) []*ReputationScore {
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
 
-	scores := make([]*ReputationScore, 0, len(rs.scores))
-	for _, score := range rs.scores {
-		scores = append(scores, score)
+	dids := make(map[string]bool, len(rs.scores))
+	for did := range rs.scores {
+		dids[did] = true
+	}
+	for _, evidence := range rs.remote {
+		for did := range evidence {
+			dids[did] = true
+		}
+	}
+	for did := range rs.endorsements {
+		dids[did] = true
+	}
+	scores := make([]*ReputationScore, 0, len(dids))
+	for did := range dids {
+		scores = append(scores, rs.blend(did))
 	}
 
 	// Simple bubble sort for top users
@@ -177,6 +529,13 @@ func (rs *ReputationSystem) GetTopUsers(limit int) []*ReputationScore {
 	return scores
 }
 
+// Count returns the number of DIDs with a score
+func (rs *ReputationSystem) Count() int {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return len(rs.scores)
+}
+
 // Export exports all reputation data
 func (rs *ReputationSystem) Export() ([]byte, error) {
 	rs.mu.RLock()
@@ -185,7 +544,8 @@ func (rs *ReputationSystem) Export() ([]byte, error) {
 	return json.Marshal(rs.scores)
 }
 
-// Import imports reputation data
+// Import imports reputation data in the Export format, replacing every
+// score. With a store set, the next snapshot replaces the stored scores.
 func (rs *ReputationSystem) Import(data []byte) error {
 	var scores map[string]*ReputationScore
 	if err := json.Unmarshal(data, &scores); err != nil {
@@ -195,7 +555,18 @@ func (rs *ReputationSystem) Import(data []byte) error {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 
+	if scores == nil {
+		scores = make(map[string]*ReputationScore)
+	}
+	for did, score := range scores {
+		if score == nil {
+			delete(scores, did)
+			continue
+		}
+		score.DID = did
+	}
 	rs.scores = scores
+	rs.replaced = true
 	rs.logger.Info("Imported reputation data", "user_count", len(scores))
 
 	return nil
@@ -218,6 +589,7 @@ func (rs *ReputationSystem) DecayScores(decayRate float64) {
 				score.Score = InitialScore / 2 // Don't go below half of initial
 			}
 			score.LastUpdated = now
+			rs.dirty[did] = true
 			rs.logger.Debug("Applied reputation decay", "did", did, "new_score", score.Score)
 		}
 	}
@@ -241,3 +613,9 @@ func (rs *ReputationSystem) CalculateContentTrust(authorDID string, upvotes, dow
 
 	return trustScore
 }
+
+// ArticleTrust scores an article from its author's reputation. Votes are
+// not tracked per article yet, so only the author component counts.
+func (rs *ReputationSystem) ArticleTrust(article *domain.Article) float64 {
+	return rs.CalculateContentTrust(article.Author, 0, 0)
+}
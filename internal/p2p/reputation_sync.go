@@ -0,0 +1,278 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+const (
+	// Protocol ID for reputation summary exchange
+	ProtocolReputation = "/newsp2p/reputation/1.0.0"
+
+	// Max scores in one summary, sent or accepted
+	MaxReputationSummary = 5000
+
+	// reputationSyncTimeout bounds one exchange with a peer
+	reputationSyncTimeout = 30 * time.Second
+	// maxSummaryBytes bounds the summary read from a peer
+	maxSummaryBytes = 8 << 20
+)
+
+// ErrSummarySignature is returned for a summary not signed by its issuer
+var ErrSummarySignature = errors.New("reputation summary is not signed by its issuer")
+
+// ReputationSummary is a node's own reputation observations, signed with
+// its node key so they can be checked against the issuer's peer ID
+type ReputationSummary struct {
+	Issuer    string             `json:"issuer"` // peer ID
+	IssuedAt  time.Time          `json:"issued_at"`
+	Scores    []*ReputationScore `json:"scores"`
+	Signature string             `json:"signature"`
+}
+
+// signableContent is the summary without its signature
+func (s *ReputationSummary) signableContent() ([]byte, error) {
+	unsigned := *s
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// Verify checks the summary was signed by the key behind its issuer
+func (s *ReputationSummary) Verify() error {
+	issuer, err := peer.Decode(s.Issuer)
+	if err != nil {
+		return fmt.Errorf("invalid issuer: %w", err)
+	}
+	publicKey, err := issuer.ExtractPublicKey()
+	if err != nil {
+		return fmt.Errorf("issuer has no embedded public key: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return ErrSummarySignature
+	}
+	content, err := s.signableContent()
+	if err != nil {
+		return err
+	}
+	if ok, err := publicKey.Verify(content, signature); err != nil || !ok {
+		return ErrSummarySignature
+	}
+	return nil
+}
+
+// ReputationPeerStatus is the exchange history with one trusted peer
+type ReputationPeerStatus struct {
+	PeerID      string     `json:"peer_id"`
+	LastSync    *time.Time `json:"last_sync,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Scores      int        `json:"scores"` // in the last summary accepted
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// ReputationSync exchanges signed reputation summaries with trusted peers.
+// Every node answers summary requests; only summaries pulled from the
+// trusted peers are merged, weighted against local observations by the
+// reputation system.
+type ReputationSync struct {
+	node       *P2PNode
+	reputation *ReputationSystem
+	trusted    []peer.ID
+	logger     *logger.Logger
+
+	mu     sync.Mutex
+	status map[peer.ID]*ReputationPeerStatus
+
+	stopChan chan struct{}
+}
+
+// NewReputationSync creates the exchange and registers the reputation
+// protocol
+func NewReputationSync(node *P2PNode, reputation *ReputationSystem, trusted []peer.ID, log *logger.Logger) *ReputationSync {
+	s := &ReputationSync{
+		node:       node,
+		reputation: reputation,
+		trusted:    trusted,
+		logger:     log.WithComponent("reputation-sync"),
+		status:     make(map[peer.ID]*ReputationPeerStatus),
+		stopChan:   make(chan struct{}),
+	}
+	for _, id := range trusted {
+		s.status[id] = &ReputationPeerStatus{PeerID: id.String()}
+	}
+
+	node.host.SetStreamHandler(protocol.ID(ProtocolReputation), s.handleSummaryRequest)
+
+	return s
+}
+
+// Start pulls summaries from the trusted peers now and then every interval
+// until Stop is called
+func (s *ReputationSync) Start(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Starting reputation sync", "trusted_peers", len(s.trusted), "interval", interval.String())
+
+	s.SyncAll(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.SyncAll(ctx)
+		case <-s.stopChan:
+			s.logger.Info("Stopping reputation sync")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the sync loop
+func (s *ReputationSync) Stop() {
+	close(s.stopChan)
+}
+
+// SyncAll pulls a summary from every trusted peer in parallel
+func (s *ReputationSync) SyncAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, id := range s.trusted {
+		wg.Add(1)
+		go func(id peer.ID) {
+			defer wg.Done()
+			if _, err := s.SyncPeer(ctx, id); err != nil {
+				s.logger.Debug("Reputation sync failed", "peer", id.String(), "error", err)
+			}
+		}(id)
+	}
+	wg.Wait()
+}
+
+// SyncPeer pulls and verifies a trusted peer's summary and merges it,
+// returning the number of scores merged
+func (s *ReputationSync) SyncPeer(ctx context.Context, id peer.ID) (int, error) {
+	s.mu.Lock()
+	_, ok := s.status[id]
+	s.mu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("peer %s is not trusted for reputation", id)
+	}
+
+	summary, err := s.requestSummary(ctx, id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status[id]
+	now := time.Now()
+	status.LastSync = &now
+	if err != nil {
+		status.LastError = err.Error()
+		return 0, err
+	}
+
+	s.reputation.MergeRemote(id.String(), summary.Scores)
+	status.LastSuccess = &now
+	status.Scores = len(summary.Scores)
+	status.LastError = ""
+
+	s.logger.Debug("Merged reputation summary", "peer", id.String(), "scores", len(summary.Scores))
+	return len(summary.Scores), nil
+}
+
+// Status returns the exchange history with each trusted peer
+func (s *ReputationSync) Status() []ReputationPeerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]ReputationPeerStatus, 0, len(s.status))
+	for _, status := range s.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].PeerID < statuses[j].PeerID })
+	return statuses
+}
+
+// Summary signs this node's own observations
+func (s *ReputationSync) Summary() (*ReputationSummary, error) {
+	summary := &ReputationSummary{
+		Issuer:   s.node.GetPeerID().String(),
+		IssuedAt: time.Now().UTC(),
+		Scores:   s.reputation.LocalScores(MaxReputationSummary),
+	}
+	content, err := summary.signableContent()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := s.node.privKey.Sign(content)
+	if err != nil {
+		return nil, err
+	}
+	summary.Signature = base64.StdEncoding.EncodeToString(signature)
+	return summary, nil
+}
+
+// requestSummary asks a peer for its summary and checks it came from that
+// peer
+func (s *ReputationSync) requestSummary(ctx context.Context, id peer.ID) (*ReputationSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, reputationSyncTimeout)
+	defer cancel()
+
+	stream, err := s.node.host.NewStream(ctx, id, protocol.ID(ProtocolReputation))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	}
+
+	var summary ReputationSummary
+	if err := json.NewDecoder(bufio.NewReader(io.LimitReader(stream, maxSummaryBytes))).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("failed to read summary: %w", err)
+	}
+	if summary.Issuer != id.String() {
+		return nil, fmt.Errorf("summary issued by %s, not the peer asked", summary.Issuer)
+	}
+	if err := summary.Verify(); err != nil {
+		return nil, err
+	}
+	if len(summary.Scores) > MaxReputationSummary {
+		summary.Scores = summary.Scores[:MaxReputationSummary]
+	}
+	return &summary, nil
+}
+
+// handleSummaryRequest sends this node's signed summary to any peer that
+// asks; the scores are public through the API anyway
+func (s *ReputationSync) handleSummaryRequest(stream network.Stream) {
+	defer stream.Close()
+	stream.SetDeadline(time.Now().Add(reputationSyncTimeout))
+
+	summary, err := s.Summary()
+	if err != nil {
+		s.logger.Warn("Failed to sign reputation summary", "error", err)
+		return
+	}
+	if err := json.NewEncoder(stream).Encode(summary); err != nil {
+		s.logger.Warn("Failed to send reputation summary", "error", err)
+		return
+	}
+
+	s.logger.Debug("Sent reputation summary", "to", stream.Conn().RemotePeer().String(), "scores", len(summary.Scores))
+}
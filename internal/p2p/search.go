@@ -0,0 +1,272 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+const (
+	// Protocol ID for forwarded searches
+	ProtocolSearch = "/newsp2p/search/1.0.0"
+
+	// Max articles a peer returns for one search
+	MaxRemoteSearchResults = 50
+
+	// maxSearchRequestBytes and maxSearchResponseBytes bound what is read
+	// from a peer's search stream
+	maxSearchRequestBytes  = 64 << 10
+	maxSearchResponseBytes = 16 << 20
+)
+
+// SearchRequest is a search forwarded to a peer
+type SearchRequest struct {
+	Query     string    `json:"query"`
+	Author    string    `json:"author,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	From      time.Time `json:"from,omitempty"`
+	To        time.Time `json:"to,omitempty"`
+	Fuzziness int       `json:"fuzziness,omitempty"`
+	Prefix    bool      `json:"prefix,omitempty"`
+	SortBy    string    `json:"sort_by,omitempty"`
+	Limit     int       `json:"limit"`
+}
+
+// SearchResponse carries a peer's local results
+type SearchResponse struct {
+	Articles []*domain.Article `json:"articles"`
+	Total    int               `json:"total"`
+}
+
+// SearchProvider runs searches against the local index
+type SearchProvider interface {
+	Search(ctx context.Context, query *search.SearchQuery) (*search.SearchResult, error)
+}
+
+// FederatedSearch answers searches from peers and forwards local
+// searches to a bounded number of connected peers
+type FederatedSearch struct {
+	host     host.Host
+	provider SearchProvider
+	maxPeers int
+	timeout  time.Duration
+	logger   *logger.Logger
+}
+
+// NewFederatedSearch creates a federated search service and registers the search protocol
+func NewFederatedSearch(
+	h host.Host,
+	provider SearchProvider,
+	maxPeers int,
+	timeout time.Duration,
+	log *logger.Logger,
+) *FederatedSearch {
+	f := &FederatedSearch{
+		host:     h,
+		provider: provider,
+		maxPeers: maxPeers,
+		timeout:  timeout,
+		logger:   log.WithComponent("p2p-search"),
+	}
+
+	h.SetStreamHandler(protocol.ID(ProtocolSearch), f.handleSearchRequest)
+
+	return f
+}
+
+// SearchPeers sends query to up to maxPeers random connected peers and
+// merges their results, dropping duplicates by CID. Peers that fail or
+// miss the timeout are skipped.
+func (f *FederatedSearch) SearchPeers(ctx context.Context, query *search.SearchQuery) ([]*domain.Article, int, error) {
+	peers := f.pickPeers()
+	if len(peers) == 0 {
+		return nil, 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	req := &SearchRequest{
+		Query:     query.Query,
+		Author:    query.Author,
+		Category:  query.Category,
+		Tags:      query.Tags,
+		From:      query.FromDate,
+		To:        query.ToDate,
+		Fuzziness: query.Fuzziness,
+		Prefix:    query.Prefix,
+		SortBy:    string(query.SortBy),
+		Limit:     query.Limit,
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		responses = make([][]*domain.Article, 0, len(peers))
+	)
+	for _, peerID := range peers {
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			articles, err := f.searchPeer(ctx, pid, req)
+			if err != nil {
+				f.logger.Debug("Peer search failed", "peer", pid.String()[:16], "error", err)
+				return
+			}
+			mu.Lock()
+			responses = append(responses, articles)
+			mu.Unlock()
+		}(peerID)
+	}
+	wg.Wait()
+
+	// Interleave peer results so one peer can't fill the page
+	var merged []*domain.Article
+	seen := make(map[string]bool)
+	for i := 0; ; i++ {
+		added := false
+		for _, articles := range responses {
+			if i >= len(articles) {
+				continue
+			}
+			added = true
+			article := articles[i]
+			if article == nil || article.ID == "" {
+				continue
+			}
+			key := search.ArticleKey(article)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, article)
+		}
+		if !added {
+			break
+		}
+	}
+
+	f.logger.Debug("Federated search completed",
+		"query", query.Query,
+		"peers_asked", len(peers),
+		"peers_responded", len(responses),
+		"results", len(merged),
+	)
+
+	return merged, len(responses), nil
+}
+
+// pickPeers returns up to maxPeers connected peers in random order
+func (f *FederatedSearch) pickPeers() []peer.ID {
+	var peers []peer.ID
+	for _, p := range f.host.Network().Peers() {
+		if p != f.host.ID() {
+			peers = append(peers, p)
+		}
+	}
+
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	if len(peers) > f.maxPeers {
+		peers = peers[:f.maxPeers]
+	}
+	return peers
+}
+
+// searchPeer runs one forwarded search against a peer
+func (f *FederatedSearch) searchPeer(ctx context.Context, peerID peer.ID, req *SearchRequest) ([]*domain.Article, error) {
+	stream, err := f.host.NewStream(ctx, peerID, protocol.ID(ProtocolSearch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(stream).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp SearchResponse
+	if err := json.NewDecoder(io.LimitReader(stream, maxSearchResponseBytes)).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if len(resp.Articles) > MaxRemoteSearchResults {
+		resp.Articles = resp.Articles[:MaxRemoteSearchResults]
+	}
+	return resp.Articles, nil
+}
+
+// handleSearchRequest answers a forwarded search from the local index only
+func (f *FederatedSearch) handleSearchRequest(stream network.Stream) {
+	defer stream.Close()
+
+	peerID := stream.Conn().RemotePeer()
+
+	var req SearchRequest
+	if err := json.NewDecoder(bufio.NewReader(io.LimitReader(stream, maxSearchRequestBytes))).Decode(&req); err != nil {
+		f.logger.Warn("Failed to decode search request", "error", err)
+		return
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > MaxRemoteSearchResults {
+		limit = MaxRemoteSearchResults
+	}
+
+	sortBy, ok := search.ParseSortOrder(req.SortBy)
+	if !ok {
+		sortBy = search.SortRelevance
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Scope stays local so forwarded searches never fan out further
+	result, err := f.provider.Search(ctx, &search.SearchQuery{
+		Query:     req.Query,
+		Author:    req.Author,
+		Category:  req.Category,
+		Tags:      req.Tags,
+		FromDate:  req.From,
+		ToDate:    req.To,
+		Page:      1,
+		Limit:     limit,
+		Fuzziness: req.Fuzziness,
+		Prefix:    req.Prefix,
+		SortBy:    sortBy,
+		Scope:     search.ScopeLocal,
+	})
+	if err != nil {
+		f.logger.Warn("Failed to run forwarded search", "error", err)
+		return
+	}
+
+	resp := &SearchResponse{
+		Articles: result.Articles,
+		Total:    result.Total,
+	}
+	if err := json.NewEncoder(stream).Encode(resp); err != nil {
+		f.logger.Warn("Failed to send search response", "error", err)
+		return
+	}
+
+	f.logger.Debug("Answered forwarded search", "from", peerID.String()[:16], "results", len(result.Articles))
+}
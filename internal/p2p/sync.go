@@ -13,8 +13,11 @@ import (
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/tracing"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
 
@@ -35,6 +38,9 @@ type SyncRequest struct {
 	Since     int64    `json:"since"`      // Unix timestamp - get articles after this time
 	Limit     int      `json:"limit"`      // Max articles to return
 	ExcludeIDs []string `json:"exclude_ids"` // Article IDs we already have
+	// W3C trace context of the requesting node's sync, so both ends of the
+	// stream land in one trace. Ignored by peers that don't trace.
+	Trace map[string]string `json:"trace,omitempty"`
 }
 
 // SyncResponse represents a response with articles
@@ -64,6 +70,10 @@ type SyncService struct {
 
 	syncInterval time.Duration
 	lastSync     time.Time
+	onProgress   func(domain.SyncProgress)
+	peerSyncs    map[peer.ID]PeerSync
+	stats        map[peer.ID]*PeerSyncStats
+	rounds       int
 	mu           sync.RWMutex
 
 	ctx    context.Context
@@ -86,6 +96,8 @@ func NewSyncService(
 		receiver:     receiver,
 		logger:       log.WithComponent("p2p-sync"),
 		syncInterval: DefaultSyncInterval,
+		peerSyncs:    make(map[peer.ID]PeerSync),
+		stats:        make(map[peer.ID]*PeerSyncStats),
 		ctx:          ctx,
 		cancel:       cancel,
 	}
@@ -117,6 +129,50 @@ func (s *SyncService) SetSyncInterval(d time.Duration) {
 	s.mu.Unlock()
 }
 
+// OnProgress registers a handler told the outcome of each sync with a peer
+func (s *SyncService) OnProgress(handler func(domain.SyncProgress)) {
+	s.mu.Lock()
+	s.onProgress = handler
+	s.mu.Unlock()
+}
+
+// PeerSync is the outcome of the most recent sync with one peer
+type PeerSync struct {
+	domain.SyncProgress
+	At time.Time `json:"at"`
+}
+
+// reportProgress records a peer's sync outcome and passes it to the
+// progress handler
+func (s *SyncService) reportProgress(peerID peer.ID, received, newCount int, err error) {
+	progress := domain.SyncProgress{PeerID: peerID.String(), Received: received, New: newCount}
+	if err != nil {
+		progress.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.peerSyncs[peerID] = PeerSync{SyncProgress: progress, At: time.Now()}
+	handler := s.onProgress
+	s.mu.Unlock()
+	if handler == nil {
+		return
+	}
+	handler(progress)
+}
+
+// PeerSyncs returns the most recent sync outcome for each peer this node
+// has synced with, keyed by peer ID
+func (s *SyncService) PeerSyncs() map[string]PeerSync {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	syncs := make(map[string]PeerSync, len(s.peerSyncs))
+	for id, ps := range s.peerSyncs {
+		syncs[id.String()] = ps
+	}
+	return syncs
+}
+
 // syncLoop runs the periodic sync
 func (s *SyncService) syncLoop() {
 	defer s.wg.Done()
@@ -157,9 +213,13 @@ func (s *SyncService) syncWithPeers() {
 		wg.Add(1)
 		go func(pid peer.ID) {
 			defer wg.Done()
-			if err := s.syncWithPeer(pid); err != nil {
+			start := time.Now()
+			outcome, err := s.syncWithPeer(pid)
+			s.recordPull(pid, outcome, time.Since(start), err)
+			if err != nil {
 				s.logger.Debug("Failed to sync with peer", "peer", pid.String()[:16], "error", err)
 			}
+			s.reportProgress(pid, outcome.offered, outcome.accepted, err)
 		}(peerID)
 	}
 
@@ -167,20 +227,31 @@ func (s *SyncService) syncWithPeers() {
 
 	s.mu.Lock()
 	s.lastSync = time.Now()
+	s.rounds++
+	s.pruneStats()
+	// Forget peers that have since disconnected
+	for id := range s.peerSyncs {
+		if s.host.Network().Connectedness(id) != network.Connected {
+			delete(s.peerSyncs, id)
+		}
+	}
 	s.mu.Unlock()
 
 	s.logger.Debug("Article sync completed")
 }
 
-// syncWithPeer syncs articles with a specific peer
-func (s *SyncService) syncWithPeer(peerID peer.ID) error {
+// syncWithPeer pulls recent articles from a specific peer
+func (s *SyncService) syncWithPeer(peerID peer.ID) (outcome syncOutcome, err error) {
 	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
 	defer cancel()
 
+	ctx, span := tracing.StartKind(ctx, trace.SpanKindClient, "p2p.sync.pull", attribute.String("peer.id", peerID.String()))
+	defer func() { tracing.End(span, err) }()
+
 	// Open stream to peer
 	stream, err := s.host.NewStream(ctx, peerID, protocol.ID(ProtocolSyncRequest))
 	if err != nil {
-		return fmt.Errorf("failed to open stream: %w", err)
+		return outcome, fmt.Errorf("failed to open stream: %w", err)
 	}
 	defer stream.Close()
 
@@ -197,11 +268,13 @@ func (s *SyncService) syncWithPeer(peerID peer.ID) error {
 	req := &SyncRequest{
 		Since: since.Unix(),
 		Limit: MaxArticlesPerSync,
+		Trace: make(map[string]string),
 	}
+	tracing.Inject(ctx, req.Trace)
 
 	encoder := json.NewEncoder(stream)
 	if err := encoder.Encode(req); err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return outcome, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	// Read response
@@ -209,40 +282,48 @@ func (s *SyncService) syncWithPeer(peerID peer.ID) error {
 	var resp SyncResponse
 	if err := decoder.Decode(&resp); err != nil {
 		if err == io.EOF {
-			return nil
+			return outcome, nil
 		}
-		return fmt.Errorf("failed to read response: %w", err)
+		return outcome, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Process received articles
-	newCount := 0
+	outcome.offered = len(resp.Articles)
 	for _, article := range resp.Articles {
 		if article == nil {
+			outcome.rejected++
 			continue
 		}
 
 		// Check if we already have this article
 		if s.provider.HasArticle(ctx, article.ID) {
+			outcome.duplicates++
 			continue
 		}
 
 		// Handle the incoming article
 		if err := s.receiver.HandleIncomingArticle(article); err != nil {
 			s.logger.Warn("Failed to handle synced article", "article_id", article.ID, "error", err)
+			outcome.rejected++
 			continue
 		}
-		newCount++
+		outcome.accepted++
 	}
 
-	if newCount > 0 {
+	if outcome.accepted > 0 {
 		s.logger.Info("Synced articles from peer",
 			"peer", peerID.String()[:16],
-			"received", len(resp.Articles),
-			"new", newCount,
+			"received", outcome.offered,
+			"new", outcome.accepted,
 		)
 	}
+	span.SetAttributes(
+		attribute.Int("sync.received", outcome.offered),
+		attribute.Int("sync.new", outcome.accepted),
+		attribute.Int("sync.rejected", outcome.rejected),
+	)
 
-	return nil
+	return outcome, nil
 }
 
 // handleSyncRequest handles incoming sync requests from peers
@@ -270,11 +351,18 @@ func (s *SyncService) handleSyncRequest(stream network.Stream) {
 	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
 	defer cancel()
 
+	ctx, span := tracing.StartKind(tracing.Extract(ctx, req.Trace), trace.SpanKindServer, "p2p.sync.serve",
+		attribute.String("peer.id", peerID.String()),
+	)
+	var err error
+	defer func() { tracing.End(span, err) }()
+
 	articles, err := s.provider.GetRecent(ctx, limit, since)
 	if err != nil {
 		s.logger.Warn("Failed to get articles for sync", "error", err)
 		return
 	}
+	span.SetAttributes(attribute.Int("sync.sent", len(articles)))
 
 	// Send response
 	resp := &SyncResponse{
@@ -283,14 +371,22 @@ func (s *SyncService) handleSyncRequest(stream network.Stream) {
 	}
 
 	encoder := json.NewEncoder(stream)
-	if err := encoder.Encode(resp); err != nil {
+	if err = encoder.Encode(resp); err != nil {
 		s.logger.Warn("Failed to send sync response", "error", err)
 		return
 	}
 
+	s.recordServe(peerID, len(articles))
 	s.logger.Debug("Sent sync response", "to", peerID.String()[:16], "articles", len(articles))
 }
 
+// SyncInterval returns the time between periodic syncs
+func (s *SyncService) SyncInterval() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.syncInterval
+}
+
 // TriggerSync manually triggers a sync
 func (s *SyncService) TriggerSync() {
 	go s.syncWithPeers()
@@ -0,0 +1,172 @@
+package p2p
+
+import (
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// syncStatsTTL is how long a peer's sync statistics are kept after the
+// last pull from or request by it
+const syncStatsTTL = 24 * time.Hour
+
+// SyncCounts accumulates sync outcomes. Pulls are syncs this node started;
+// serves are sync requests it answered.
+type SyncCounts struct {
+	Pulls         int     `json:"pulls"`
+	Failures      int     `json:"failures"`   // pulls that failed outright
+	Offered       int     `json:"offered"`    // articles received in pulls
+	Duplicates    int     `json:"duplicates"` // offered articles already held here
+	Accepted      int     `json:"accepted"`   // offered articles stored
+	Rejected      int     `json:"rejected"`   // offered articles that failed verification or storage
+	Serves        int     `json:"serves"`
+	Sent          int     `json:"sent"` // articles sent in serves
+	AvgDurationMs float64 `json:"avg_duration_ms"`
+	MaxDurationMs int64   `json:"max_duration_ms"`
+
+	totalDuration time.Duration
+}
+
+func (c *SyncCounts) add(o *SyncCounts) {
+	c.Pulls += o.Pulls
+	c.Failures += o.Failures
+	c.Offered += o.Offered
+	c.Duplicates += o.Duplicates
+	c.Accepted += o.Accepted
+	c.Rejected += o.Rejected
+	c.Serves += o.Serves
+	c.Sent += o.Sent
+	c.totalDuration += o.totalDuration
+	c.MaxDurationMs = max(c.MaxDurationMs, o.MaxDurationMs)
+}
+
+func (c *SyncCounts) finish() {
+	if c.Pulls > 0 {
+		c.AvgDurationMs = float64(c.totalDuration.Microseconds()) / 1000 / float64(c.Pulls)
+	}
+}
+
+// PeerSyncStats is the sync history with one peer since this node started
+type PeerSyncStats struct {
+	PeerID string `json:"peer_id"`
+	SyncCounts
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastPull       *time.Time `json:"last_pull,omitempty"`
+	LastSuccess    *time.Time `json:"last_success,omitempty"`
+	LastServe      *time.Time `json:"last_serve,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	Connected      bool       `json:"connected"`
+}
+
+// lastActive is when the peer last pulled from or was pulled by this node
+func (p *PeerSyncStats) lastActive() time.Time {
+	var t time.Time
+	for _, at := range []*time.Time{p.LastPull, p.LastServe} {
+		if at != nil && at.After(t) {
+			t = *at
+		}
+	}
+	return t
+}
+
+// SyncStats summarises sync activity since this node started
+type SyncStats struct {
+	Rounds    int             `json:"rounds"`
+	LastRound *time.Time      `json:"last_round,omitempty"`
+	Totals    SyncCounts      `json:"totals"`
+	Peers     []PeerSyncStats `json:"peers"` // most recently active first
+}
+
+// syncOutcome is what came of one pull
+type syncOutcome struct {
+	offered, duplicates, accepted, rejected int
+}
+
+// peerStats returns the stats for a peer, creating them. Callers hold s.mu.
+func (s *SyncService) peerStats(id peer.ID) *PeerSyncStats {
+	stats, ok := s.stats[id]
+	if !ok {
+		stats = &PeerSyncStats{PeerID: id.String()}
+		s.stats[id] = stats
+	}
+	return stats
+}
+
+// recordPull adds the outcome of a pull to the peer's stats
+func (s *SyncService) recordPull(id peer.ID, outcome syncOutcome, d time.Duration, err error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.peerStats(id)
+	stats.Pulls++
+	stats.Offered += outcome.offered
+	stats.Duplicates += outcome.duplicates
+	stats.Accepted += outcome.accepted
+	stats.Rejected += outcome.rejected
+	stats.totalDuration += d
+	stats.LastDurationMs = d.Milliseconds()
+	stats.MaxDurationMs = max(stats.MaxDurationMs, stats.LastDurationMs)
+	stats.LastPull = &now
+	if err != nil {
+		stats.Failures++
+		stats.LastError = err.Error()
+	} else {
+		stats.LastSuccess = &now
+		stats.LastError = ""
+	}
+}
+
+// recordServe counts a sync request answered for a peer
+func (s *SyncService) recordServe(id peer.ID, sent int) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.peerStats(id)
+	stats.Serves++
+	stats.Sent += sent
+	stats.LastServe = &now
+}
+
+// pruneStats forgets peers inactive for longer than syncStatsTTL. Callers
+// hold s.mu.
+func (s *SyncService) pruneStats() {
+	cutoff := time.Now().Add(-syncStatsTTL)
+	for id, stats := range s.stats {
+		if stats.lastActive().Before(cutoff) {
+			delete(s.stats, id)
+		}
+	}
+}
+
+// SyncStats returns per-peer sync statistics and their totals
+func (s *SyncService) SyncStats() *SyncStats {
+	s.mu.RLock()
+	result := &SyncStats{Rounds: s.rounds, Peers: make([]PeerSyncStats, 0, len(s.stats))}
+	if !s.lastSync.IsZero() {
+		last := s.lastSync
+		result.LastRound = &last
+	}
+	for _, stats := range s.stats {
+		result.Peers = append(result.Peers, *stats)
+	}
+	s.mu.RUnlock()
+
+	for i := range result.Peers {
+		p := &result.Peers[i]
+		result.Totals.add(&p.SyncCounts)
+		p.finish()
+		if id, err := peer.Decode(p.PeerID); err == nil {
+			p.Connected = s.host.Network().Connectedness(id) == network.Connected
+		}
+	}
+	result.Totals.finish()
+
+	sort.Slice(result.Peers, func(i, j int) bool {
+		return result.Peers[i].lastActive().After(result.Peers[j].lastActive())
+	})
+	return result
+}
@@ -0,0 +1,182 @@
+package p2p
+
+import (
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// Topology is this node's view of the overlay: itself, the peers it is
+// connected to and one link to each of them
+type Topology struct {
+	Self  TopologyPeer   `json:"self"`
+	Peers []TopologyPeer `json:"peers"`
+	Links []TopologyLink `json:"links"`
+}
+
+// TopologyPeer describes a node in the topology graph
+type TopologyPeer struct {
+	ID           string   `json:"id"`
+	AgentVersion string   `json:"agent_version,omitempty"`
+	Addresses    []string `json:"addresses"`
+	Protocols    []string `json:"protocols"` // as advertised over identify
+	Topics       []string `json:"topics"`    // topics the peer subscribes to, of those this node joined
+}
+
+// TopologyLink is the connection between this node and a peer. Peers with
+// several connections are described by the best one, direct before relayed.
+type TopologyLink struct {
+	Source      string    `json:"source"`
+	Target      string    `json:"target"`
+	Transport   string    `json:"transport"` // tcp, quic-v1, ws, webtransport, relay, ...
+	Direction   string    `json:"direction"` // inbound or outbound
+	LatencyMS   float64   `json:"latency_ms"`
+	Connections int       `json:"connections"`
+	Opened      time.Time `json:"opened"`
+	Bandwidth   Bandwidth `json:"bandwidth"`
+}
+
+// Bandwidth is the traffic exchanged with a peer, or with all of them:
+// totals since the node started and current rates in bytes per second
+type Bandwidth struct {
+	TotalIn  int64   `json:"total_in"`
+	TotalOut int64   `json:"total_out"`
+	RateIn   float64 `json:"rate_in"`
+	RateOut  float64 `json:"rate_out"`
+}
+
+func bandwidthFrom(stats metrics.Stats) Bandwidth {
+	return Bandwidth{
+		TotalIn:  stats.TotalIn,
+		TotalOut: stats.TotalOut,
+		RateIn:   stats.RateIn,
+		RateOut:  stats.RateOut,
+	}
+}
+
+// Bandwidth returns this node's traffic across all peers
+func (n *P2PNode) Bandwidth() Bandwidth {
+	return bandwidthFrom(n.bandwidth.GetBandwidthTotals())
+}
+
+// Topology snapshots the peers this node is connected to
+func (n *P2PNode) Topology() *Topology {
+	store := n.host.Peerstore()
+
+	// Which peers subscribe to each topic this node has joined
+	topicsByPeer := make(map[peer.ID][]string)
+	n.mu.RLock()
+	for name, topic := range n.topics {
+		for _, id := range topic.ListPeers() {
+			topicsByPeer[id] = append(topicsByPeer[id], name)
+		}
+	}
+	selfTopics := make([]string, 0, len(n.topics))
+	for name := range n.topics {
+		selfTopics = append(selfTopics, name)
+	}
+	n.mu.RUnlock()
+
+	describe := func(id peer.ID, addrs []multiaddr.Multiaddr, topics []string) TopologyPeer {
+		p := TopologyPeer{
+			ID:        id.String(),
+			Addresses: make([]string, len(addrs)),
+			Protocols: []string{},
+			Topics:    append([]string{}, topics...),
+		}
+		for i, addr := range addrs {
+			p.Addresses[i] = addr.String()
+		}
+		if agent, err := store.Get(id, "AgentVersion"); err == nil {
+			p.AgentVersion, _ = agent.(string)
+		}
+		if protocols, err := store.GetProtocols(id); err == nil {
+			p.Protocols = protocolNames(protocols)
+		}
+		sort.Strings(p.Topics)
+		return p
+	}
+
+	topology := &Topology{
+		Self:  describe(n.peerID, n.host.Addrs(), selfTopics),
+		Peers: []TopologyPeer{},
+		Links: []TopologyLink{},
+	}
+	// Our own protocols come from the mux rather than the peerstore
+	topology.Self.Protocols = protocolNames(n.host.Mux().Protocols())
+
+	peers := n.host.Network().Peers()
+	sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+	for _, id := range peers {
+		conns := n.host.Network().ConnsToPeer(id)
+		if len(conns) == 0 {
+			continue
+		}
+
+		addrs := make([]multiaddr.Multiaddr, 0, len(conns))
+		best := conns[0]
+		for _, conn := range conns {
+			addrs = append(addrs, conn.RemoteMultiaddr())
+			if best.Stat().Limited && !conn.Stat().Limited {
+				best = conn
+			}
+		}
+		topology.Peers = append(topology.Peers, describe(id, addrs, topicsByPeer[id]))
+
+		stat := best.Stat()
+		topology.Links = append(topology.Links, TopologyLink{
+			Source:      n.peerID.String(),
+			Target:      id.String(),
+			Transport:   transportName(best.RemoteMultiaddr()),
+			Direction:   directionName(stat.Direction),
+			LatencyMS:   float64(store.LatencyEWMA(id)) / float64(time.Millisecond),
+			Connections: len(conns),
+			Opened:      stat.Opened,
+			Bandwidth:   bandwidthFrom(n.bandwidth.GetBandwidthForPeer(id)),
+		})
+	}
+
+	return topology
+}
+
+// transportName names the transport a connection address uses. Protocols
+// are listed from the network layer up, so later matches win: ws over tcp
+// is "ws" and anything through a relay is "relay".
+func transportName(addr multiaddr.Multiaddr) string {
+	name := "unknown"
+	for _, proto := range addr.Protocols() {
+		switch proto.Code {
+		case multiaddr.P_TCP, multiaddr.P_QUIC, multiaddr.P_QUIC_V1, multiaddr.P_WS, multiaddr.P_WSS,
+			multiaddr.P_WEBTRANSPORT, multiaddr.P_WEBRTC, multiaddr.P_WEBRTC_DIRECT:
+			name = proto.Name
+		case multiaddr.P_CIRCUIT:
+			name = "relay"
+		}
+	}
+	return name
+}
+
+func protocolNames(protocols []protocol.ID) []string {
+	names := make([]string, len(protocols))
+	for i, proto := range protocols {
+		names[i] = string(proto)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func directionName(dir network.Direction) string {
+	switch dir {
+	case network.DirInbound:
+		return "inbound"
+	case network.DirOutbound:
+		return "outbound"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// AppealRepository persists authors' appeals of moderation decisions. At
+// most one appeal per moderation case is kept.
+type AppealRepository interface {
+	// Create adds an appeal, failing with ErrAppealExists if its case was
+	// already appealed
+	Create(ctx context.Context, appeal *domain.Appeal) error
+
+	// GetByID retrieves an appeal by ID
+	GetByID(ctx context.Context, id string) (*domain.Appeal, error)
+
+	// GetByCase retrieves the appeal of a moderation case
+	GetByCase(ctx context.Context, caseID string) (*domain.Appeal, error)
+
+	// Update updates an existing appeal
+	Update(ctx context.Context, appeal *domain.Appeal) error
+
+	// List retrieves appeals newest first with pagination and filtering
+	List(ctx context.Context, filter *domain.AppealListFilter) ([]*domain.Appeal, int, error)
+}
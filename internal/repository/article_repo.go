@@ -11,6 +11,9 @@ type ArticleRepository interface {
 	// Create creates a new article
 	Create(ctx context.Context, article *domain.Article) error
 
+	// CreateBatch creates several articles atomically
+	CreateBatch(ctx context.Context, articles []*domain.Article) error
+
 	// GetByID retrieves an article by ID
 	GetByID(ctx context.Context, id string) (*domain.Article, error)
 
@@ -32,6 +35,9 @@ type ArticleRepository interface {
 	// ListByAuthor retrieves articles by author with pagination
 	ListByAuthor(ctx context.Context, author string, page, limit int) ([]*domain.Article, int, error)
 
+	// ListTags counts the articles under each tag, most used first
+	ListTags(ctx context.Context, limit int) ([]domain.TagCount, error)
+
 	// GetByIDs retrieves articles by a list of IDs (for search results)
 	GetByIDs(ctx context.Context, ids []string) ([]*domain.Article, error)
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// AuditRepository persists the append-only audit log. There is no update
+// or delete.
+type AuditRepository interface {
+	// Append chains an entry onto the log, filling in its sequence number
+	// and hashes
+	Append(ctx context.Context, entry *domain.AuditEntry) error
+
+	// List retrieves entries newest first with pagination and filtering
+	List(ctx context.Context, filter *domain.AuditListFilter) ([]*domain.AuditEntry, int, error)
+
+	// Verify walks the whole chain, checking every hash and link
+	Verify(ctx context.Context) (*domain.AuditVerifyReport, error)
+
+	// Settings returns the configuration recorded at the last start, or
+	// nil before the first
+	Settings(ctx context.Context) (map[string]string, error)
+
+	// SaveSettings records the configuration the node started with
+	SaveSettings(ctx context.Context, settings map[string]string) error
+}
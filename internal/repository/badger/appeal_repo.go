@@ -0,0 +1,164 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// AppealRepo implements AppealRepository using BadgerDB
+type AppealRepo struct {
+	db *DB
+}
+
+// NewAppealRepo creates a new BadgerDB-based appeal repository
+func NewAppealRepo(db *DB) *AppealRepo {
+	return &AppealRepo{db: db}
+}
+
+func appealKey(id string) []byte {
+	return []byte(fmt.Sprintf("appeal:id:%s", id))
+}
+
+func appealTimeKey(a *domain.Appeal) []byte {
+	return []byte(fmt.Sprintf("appeal:time:%d:%s", a.CreatedAt.UnixNano(), a.ID))
+}
+
+// appealCaseKey points at the appeal of a moderation case
+func appealCaseKey(caseID string) []byte {
+	return []byte(fmt.Sprintf("appeal:case:%s", caseID))
+}
+
+// Create adds an appeal, failing if its case was already appealed
+func (r *AppealRepo) Create(ctx context.Context, appeal *domain.Appeal) error {
+	data, err := json.Marshal(appeal)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get(appealCaseKey(appeal.CaseID)); err == nil {
+			return domain.ErrAppealExists
+		} else if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		if err := txn.Set(appealCaseKey(appeal.CaseID), []byte(appeal.ID)); err != nil {
+			return err
+		}
+		if err := txn.Set(appealKey(appeal.ID), data); err != nil {
+			return err
+		}
+		return txn.Set(appealTimeKey(appeal), []byte(appeal.ID))
+	})
+}
+
+// GetByID retrieves an appeal by ID
+func (r *AppealRepo) GetByID(ctx context.Context, id string) (*domain.Appeal, error) {
+	var appeal *domain.Appeal
+	err := r.db.View(func(txn *badger.Txn) error {
+		var err error
+		appeal, err = getAppeal(txn, id)
+		return err
+	})
+	return appeal, err
+}
+
+// GetByCase retrieves the appeal of a moderation case
+func (r *AppealRepo) GetByCase(ctx context.Context, caseID string) (*domain.Appeal, error) {
+	var appeal *domain.Appeal
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(appealCaseKey(caseID))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrAppealNotFound
+			}
+			return err
+		}
+		id, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		appeal, err = getAppeal(txn, string(id))
+		return err
+	})
+	return appeal, err
+}
+
+// Update updates an existing appeal
+func (r *AppealRepo) Update(ctx context.Context, appeal *domain.Appeal) error {
+	data, err := json.Marshal(appeal)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if _, err := getAppeal(txn, appeal.ID); err != nil {
+			return err
+		}
+		return txn.Set(appealKey(appeal.ID), data)
+	})
+}
+
+// List retrieves appeals newest first with pagination and filtering
+func (r *AppealRepo) List(ctx context.Context, filter *domain.AppealListFilter) ([]*domain.Appeal, int, error) {
+	var appeals []*domain.Appeal
+	err := r.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true // Newest first
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte("appeal:time:")
+		for it.Seek(append(prefix, 0xFF)); it.ValidForPrefix(prefix); it.Next() {
+			id, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				continue
+			}
+			appeal, err := getAppeal(txn, string(id))
+			if err != nil {
+				continue
+			}
+			if filter.Status != "" && appeal.Status != filter.Status {
+				continue
+			}
+			appeals = append(appeals, appeal)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(appeals)
+	if filter.Limit > 0 {
+		start := (filter.Page - 1) * filter.Limit
+		if start < 0 {
+			start = 0
+		}
+		if start >= total {
+			return []*domain.Appeal{}, total, nil
+		}
+		appeals = appeals[start:min(start+filter.Limit, total)]
+	}
+	return appeals, total, nil
+}
+
+func getAppeal(txn *badger.Txn, id string) (*domain.Appeal, error) {
+	item, err := txn.Get(appealKey(id))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, domain.ErrAppealNotFound
+		}
+		return nil, err
+	}
+	var appeal domain.Appeal
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &appeal)
+	}); err != nil {
+		return nil, err
+	}
+	return &appeal, nil
+}
@@ -1,10 +1,13 @@
 package badger
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/dgraph-io/badger/v4"
@@ -24,38 +27,95 @@ func NewArticleRepo(db *DB) *ArticleRepo {
 // Create creates a new article
 func (r *ArticleRepo) Create(ctx context.Context, article *domain.Article) error {
 	return r.db.Update(func(txn *badger.Txn) error {
-		// Save article data
-		data, err := json.Marshal(article)
-		if err != nil {
-			return err
-		}
+		return putArticle(txn, article)
+	})
+}
 
-		idKey := []byte(fmt.Sprintf("article:id:%s", article.ID))
-		if err := txn.Set(idKey, data); err != nil {
-			return err
+// CreateBatch creates several articles in one transaction, so either all
+// of them are stored or none are
+func (r *ArticleRepo) CreateBatch(ctx context.Context, articles []*domain.Article) error {
+	return r.db.Update(func(txn *badger.Txn) error {
+		for _, article := range articles {
+			if err := putArticle(txn, article); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+}
 
-		// Indexes
-		cidKey := []byte(fmt.Sprintf("article:cid:%s", article.CID))
-		if err := txn.Set(cidKey, []byte(article.ID)); err != nil {
-			return err
-		}
+// putArticle writes an article and its index entries
+func putArticle(txn *badger.Txn, article *domain.Article) error {
+	// Save article data
+	data, err := json.Marshal(article)
+	if err != nil {
+		return err
+	}
 
-		// Time index for sorting (descending scan needs careful key design, or use reverse iterator)
-		// Format: article:time:<timestamp_unix_nano>:<id>
-		timeKey := []byte(fmt.Sprintf("article:time:%d:%s", article.Timestamp.UnixNano(), article.ID))
-		if err := txn.Set(timeKey, []byte(article.ID)); err != nil {
+	idKey := []byte(fmt.Sprintf("article:id:%s", article.ID))
+	if err := txn.Set(idKey, data); err != nil {
+		return err
+	}
+
+	// Indexes
+	cidKey := []byte(fmt.Sprintf("article:cid:%s", article.CID))
+	if err := txn.Set(cidKey, []byte(article.ID)); err != nil {
+		return err
+	}
+
+	// Time index for sorting (descending scan needs careful key design, or use reverse iterator)
+	// Format: article:time:<timestamp_unix_nano>:<id>
+	timeKey := []byte(fmt.Sprintf("article:time:%d:%s", article.Timestamp.UnixNano(), article.ID))
+	if err := txn.Set(timeKey, []byte(article.ID)); err != nil {
+		return err
+	}
+
+	// Author index
+	authorKey := []byte(fmt.Sprintf("article:author:%s:%d:%s", strings.ToLower(article.Author), article.Timestamp.UnixNano(), article.ID))
+	if err := txn.Set(authorKey, []byte(article.ID)); err != nil {
+		return err
+	}
+
+	// Tag index
+	for _, key := range tagKeys(article) {
+		if err := txn.Set([]byte(key), []byte(article.ID)); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// Author index
-		authorKey := []byte(fmt.Sprintf("article:author:%s:%d:%s", strings.ToLower(article.Author), article.Timestamp.UnixNano(), article.ID))
-		if err := txn.Set(authorKey, []byte(article.ID)); err != nil {
-			return err
+// tagKeys returns an article's tag index keys, one per distinct tag.
+// Format: article:tag:<lowercase tag>:<timestamp_unix_nano>:<id>
+func tagKeys(article *domain.Article) []string {
+	seen := make(map[string]bool, len(article.Tags))
+	keys := make([]string, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		tag = strings.ToLower(tag)
+		if seen[tag] {
+			continue
 		}
+		seen[tag] = true
+		keys = append(keys, fmt.Sprintf("article:tag:%s:%d:%s", tag, article.Timestamp.UnixNano(), article.ID))
+	}
+	return keys
+}
 
-		return nil
-	})
+// hasTags reports whether an article has every one of tags, ignoring case
+func hasTags(article *domain.Article, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range article.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 // GetByID retrieves an article by ID
@@ -105,38 +165,82 @@ func (r *ArticleRepo) GetByCID(ctx context.Context, cid string) (*domain.Article
 func (r *ArticleRepo) Update(ctx context.Context, article *domain.Article) error {
 	return r.db.Update(func(txn *badger.Txn) error {
 		// In a real implementation, we should cleanup old indexes if sort keys (time/author) change.
-		// Assuming immutable metadata for now except content body.
+		// Assuming immutable metadata for now except content body and tags.
+		if item, err := txn.Get([]byte(fmt.Sprintf("article:id:%s", article.ID))); err == nil {
+			var old domain.Article
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &old)
+			}); err == nil {
+				for _, key := range tagKeys(&old) {
+					if err := txn.Delete([]byte(key)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
 		data, err := json.Marshal(article)
 		if err != nil {
 			return err
 		}
-		return txn.Set([]byte(fmt.Sprintf("article:id:%s", article.ID)), data)
+		if err := txn.Set([]byte(fmt.Sprintf("article:id:%s", article.ID)), data); err != nil {
+			return err
+		}
+		// Revisions are published under new CIDs; earlier ones keep resolving
+		if err := txn.Set([]byte(fmt.Sprintf("article:cid:%s", article.CID)), []byte(article.ID)); err != nil {
+			return err
+		}
+		for _, key := range tagKeys(article) {
+			if err := txn.Set([]byte(key), []byte(article.ID)); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
 // Delete deletes an article by ID
 func (r *ArticleRepo) Delete(ctx context.Context, id string) error {
 	return r.db.Update(func(txn *badger.Txn) error {
-		// Load article to find index keys
-		item, err := txn.Get([]byte(fmt.Sprintf("article:id:%s", id)))
-		if err != nil {
-			return err
-		}
-		var article domain.Article
-		if err := item.Value(func(val []byte) error {
-			return json.Unmarshal(val, &article)
-		}); err != nil {
+		return deleteArticle(txn, id)
+	})
+}
+
+// Replace stores an article in place of any copy with the same ID,
+// rebuilding its index entries, in one transaction
+func (r *ArticleRepo) Replace(ctx context.Context, article *domain.Article) error {
+	return r.db.Update(func(txn *badger.Txn) error {
+		if err := deleteArticle(txn, article.ID); err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
 			return err
 		}
+		return putArticle(txn, article)
+	})
+}
 
-		// Delete indexes
-		txn.Delete([]byte(fmt.Sprintf("article:cid:%s", article.CID)))
-		txn.Delete([]byte(fmt.Sprintf("article:time:%d:%s", article.Timestamp.UnixNano(), article.ID)))
-		txn.Delete([]byte(fmt.Sprintf("article:author:%s:%d:%s", strings.ToLower(article.Author), article.Timestamp.UnixNano(), article.ID)))
+// deleteArticle removes an article and its index entries
+func deleteArticle(txn *badger.Txn, id string) error {
+	// Load article to find index keys
+	item, err := txn.Get([]byte(fmt.Sprintf("article:id:%s", id)))
+	if err != nil {
+		return err
+	}
+	var article domain.Article
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &article)
+	}); err != nil {
+		return err
+	}
 
-		// Delete data
-		return txn.Delete([]byte(fmt.Sprintf("article:id:%s", id)))
-	})
+	// Delete indexes
+	txn.Delete([]byte(fmt.Sprintf("article:cid:%s", article.CID)))
+	txn.Delete([]byte(fmt.Sprintf("article:time:%d:%s", article.Timestamp.UnixNano(), article.ID)))
+	txn.Delete([]byte(fmt.Sprintf("article:author:%s:%d:%s", strings.ToLower(article.Author), article.Timestamp.UnixNano(), article.ID)))
+	for _, key := range tagKeys(&article) {
+		txn.Delete([]byte(key))
+	}
+
+	// Delete data
+	return txn.Delete([]byte(fmt.Sprintf("article:id:%s", id)))
 }
 
 // List retrieves articles with pagination and filtering
@@ -152,10 +256,23 @@ func (r *ArticleRepo) List(ctx context.Context, filter *domain.ArticleListFilter
 		it := txn.NewIterator(opts)
 		defer it.Close()
 
+		// Tag filters walk the first tag's index instead of every article
 		prefix := []byte("article:time:")
-		
-		for it.Seek(append(prefix, 0xFF)); it.ValidForPrefix(prefix); it.Next() {
+		if len(filter.Tags) > 0 {
+			prefix = []byte(fmt.Sprintf("article:tag:%s:", strings.ToLower(filter.Tags[0])))
+		}
+		seek := append(prefix, 0xFF)
+		var cursorKey []byte
+		if filter.After != nil {
+			cursorKey = []byte(fmt.Sprintf("%s%d:%s", prefix, filter.After.Timestamp.UnixNano(), filter.After.ID))
+			seek = cursorKey
+		}
+
+		for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
 			item := it.Item()
+			if cursorKey != nil && bytes.Equal(item.Key(), cursorKey) {
+				continue
+			}
 			var id string
 			err := item.Value(func(val []byte) error {
 				id = string(val)
@@ -178,13 +295,28 @@ func (r *ArticleRepo) List(ctx context.Context, filter *domain.ArticleListFilter
 				continue
 			}
 
-			// Apply filters
+			// Apply filters; moderation keeps hidden and unlisted articles out,
+			// and circle articles only list for their circle
+			if filter.Circle != "" {
+				if art.Circle != filter.Circle || art.Visibility != "" {
+					continue
+				}
+			} else if !art.Listed() {
+				continue
+			}
 			if filter.Author != "" && !strings.EqualFold(art.Author, filter.Author) {
 				continue
 			}
+			if len(filter.Authors) > 0 && !slices.ContainsFunc(filter.Authors, func(a string) bool { return strings.EqualFold(art.Author, a) }) {
+				continue
+			}
 			if filter.Category != "" && !strings.EqualFold(art.Category, filter.Category) {
 				continue
 			}
+			// Also guards against tags containing ':' sharing a key prefix
+			if len(filter.Tags) > 0 && !hasTags(&art, filter.Tags) {
+				continue
+			}
 			if !filter.FromDate.IsZero() && art.Timestamp.Before(filter.FromDate) {
 				continue
 			}
@@ -205,6 +337,9 @@ func (r *ArticleRepo) List(ctx context.Context, filter *domain.ArticleListFilter
 	
 	// Pagination
 	start := (filter.Page - 1) * filter.Limit
+	if filter.After != nil {
+		start = 0
+	}
 	if start > total {
 		start = total
 	}
@@ -236,6 +371,53 @@ func (r *ArticleRepo) ListByAuthor(ctx context.Context, author string, page, lim
 	return r.List(ctx, filter)
 }
 
+// ListTags counts the articles under each tag, most used first. A limit
+// of 0 or less returns every tag.
+func (r *ArticleRepo) ListTags(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	counts := make(map[string]int)
+
+	err := r.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte("article:tag:")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			// Tags may contain ':', so trim the timestamp and ID from the end
+			key := strings.TrimPrefix(string(it.Item().Key()), "article:tag:")
+			end := strings.LastIndexByte(key, ':')
+			if end < 0 {
+				continue
+			}
+			end = strings.LastIndexByte(key[:end], ':')
+			if end < 0 {
+				continue
+			}
+			counts[key[:end]]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]domain.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, domain.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	if limit > 0 && len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
 // GetByIDs retrieves articles by IDs
 func (r *ArticleRepo) GetByIDs(ctx context.Context, ids []string) ([]*domain.Article, error) {
 	var articles []*domain.Article
@@ -247,3 +429,122 @@ func (r *ArticleRepo) GetByIDs(ctx context.Context, ids []string) ([]*domain.Art
 	}
 	return articles, nil
 }
+
+// CheckIndexes walks the article records and their cid/time/author/tag
+// index keys and reports inconsistencies. With repair set, missing index keys
+// are rebuilt and dangling ones removed. Corrupt records are only reported.
+func (r *ArticleRepo) CheckIndexes(ctx context.Context, repair bool) ([]domain.IntegrityIssue, error) {
+	var issues []domain.IntegrityIssue
+
+	err := r.db.Update(func(txn *badger.Txn) error {
+		articles := make(map[string]*domain.Article)
+
+		// Pass 1: every record must decode and have all of its indexes
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		prefix := []byte("article:id:")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			key := string(it.Item().KeyCopy(nil))
+			id := strings.TrimPrefix(key, "article:id:")
+
+			var art domain.Article
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &art)
+			}); err != nil || art.ID != id {
+				issues = append(issues, domain.IntegrityIssue{
+					Kind:      domain.IssueCorruptRecord,
+					ArticleID: id,
+					Key:       key,
+					Detail:    "article record does not decode or has mismatched id",
+				})
+				continue
+			}
+			articles[id] = &art
+		}
+		it.Close()
+
+		for id, art := range articles {
+			ts := art.Timestamp.UnixNano()
+			expected := []string{
+				fmt.Sprintf("article:cid:%s", art.CID),
+				fmt.Sprintf("article:time:%d:%s", ts, id),
+				fmt.Sprintf("article:author:%s:%d:%s", strings.ToLower(art.Author), ts, id),
+			}
+			expected = append(expected, tagKeys(art)...)
+			for _, key := range expected {
+				item, err := txn.Get([]byte(key))
+				if err == nil {
+					var got string
+					if err := item.Value(func(v []byte) error {
+						got = string(v)
+						return nil
+					}); err == nil && got == id {
+						continue
+					}
+				} else if !errors.Is(err, badger.ErrKeyNotFound) {
+					return err
+				}
+
+				issue := domain.IntegrityIssue{
+					Kind:      domain.IssueMissingIndex,
+					ArticleID: id,
+					CID:       art.CID,
+					Key:       key,
+					Detail:    "index key missing or pointing at another article",
+				}
+				if repair {
+					if err := txn.Set([]byte(key), []byte(id)); err != nil {
+						return err
+					}
+					issue.Repaired = true
+				}
+				issues = append(issues, issue)
+			}
+		}
+
+		// Pass 2: every index key must point at an existing record
+		for _, indexPrefix := range []string{"article:cid:", "article:time:", "article:author:", "article:tag:"} {
+			var dangling []domain.IntegrityIssue
+			it := txn.NewIterator(badger.DefaultIteratorOptions)
+			p := []byte(indexPrefix)
+			for it.Seek(p); it.ValidForPrefix(p); it.Next() {
+				key := string(it.Item().KeyCopy(nil))
+				var id string
+				if err := it.Item().Value(func(v []byte) error {
+					id = string(v)
+					return nil
+				}); err != nil {
+					continue
+				}
+				detail := "index key points at a missing article"
+				if art, ok := articles[id]; ok {
+					// Tag keys also go stale when an edit drops the tag
+					if indexPrefix != "article:tag:" || slices.Contains(tagKeys(art), key) {
+						continue
+					}
+					detail = "tag index key for a tag the article no longer has"
+				}
+				dangling = append(dangling, domain.IntegrityIssue{
+					Kind:      domain.IssueDanglingIndex,
+					ArticleID: id,
+					Key:       key,
+					Detail:    detail,
+				})
+			}
+			it.Close()
+
+			for i := range dangling {
+				if repair {
+					if err := txn.Delete([]byte(dangling[i].Key)); err != nil {
+						return err
+					}
+					dangling[i].Repaired = true
+				}
+			}
+			issues = append(issues, dangling...)
+		}
+
+		return nil
+	})
+
+	return issues, err
+}
@@ -0,0 +1,232 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const (
+	auditEntryPrefix = "audit:seq:"
+	// auditHeadKey holds the sequence number and hash of the last entry
+	auditHeadKey = "audit:head"
+	// auditSettingsKey holds the configuration seen at the last start
+	auditSettingsKey = "audit:settings"
+)
+
+// auditHead is the tip of the hash chain
+type auditHead struct {
+	Seq  uint64 `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// AuditRepo implements AuditRepository using BadgerDB
+type AuditRepo struct {
+	db *DB
+	mu sync.Mutex // serialises appends so each reads the head it extends
+}
+
+// NewAuditRepo creates a new BadgerDB-based audit log
+func NewAuditRepo(db *DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+// Sequence numbers are zero-padded so keys sort in order
+func auditEntryKey(seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d", auditEntryPrefix, seq))
+}
+
+// Append chains an entry onto the log
+func (r *AuditRepo) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for {
+		err := r.db.Update(func(txn *badger.Txn) error {
+			head, err := getAuditHead(txn)
+			if err != nil {
+				return err
+			}
+			entry.Seq = head.Seq + 1
+			entry.PrevHash = head.Hash
+			entry.Hash = entry.ComputeHash()
+
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			headData, err := json.Marshal(auditHead{Seq: entry.Seq, Hash: entry.Hash})
+			if err != nil {
+				return err
+			}
+			if err := txn.Set(auditEntryKey(entry.Seq), data); err != nil {
+				return err
+			}
+			return txn.Set([]byte(auditHeadKey), headData)
+		})
+		// Another repo on the same store appended first; chain onto it
+		if errors.Is(err, badger.ErrConflict) {
+			continue
+		}
+		return err
+	}
+}
+
+// List retrieves entries newest first with pagination and filtering
+func (r *AuditRepo) List(ctx context.Context, filter *domain.AuditListFilter) ([]*domain.AuditEntry, int, error) {
+	var entries []*domain.AuditEntry
+	err := r.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true // Newest first
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(auditEntryPrefix)
+		for it.Seek(append(prefix, 0xFF)); it.ValidForPrefix(prefix); it.Next() {
+			var entry domain.AuditEntry
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &entry)
+			}); err != nil {
+				return err
+			}
+			if filter.Action != "" && entry.Action != filter.Action {
+				continue
+			}
+			if filter.Actor != "" && entry.Actor != filter.Actor && entry.ActorID != filter.Actor {
+				continue
+			}
+			if filter.Target != "" && entry.Target != filter.Target {
+				continue
+			}
+			entries = append(entries, &entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(entries)
+	if filter.Limit > 0 {
+		start := (filter.Page - 1) * filter.Limit
+		if start < 0 {
+			start = 0
+		}
+		if start >= total {
+			return []*domain.AuditEntry{}, total, nil
+		}
+		entries = entries[start:min(start+filter.Limit, total)]
+	}
+	return entries, total, nil
+}
+
+// Verify walks the chain from the first entry, checking that sequence
+// numbers are contiguous, every hash matches its entry and every entry
+// links to the one before. The head must match the last entry, so
+// trimming entries off the end is caught too.
+func (r *AuditRepo) Verify(ctx context.Context) (*domain.AuditVerifyReport, error) {
+	report := &domain.AuditVerifyReport{Valid: true}
+	fail := func(seq uint64, problem string) {
+		report.Valid = false
+		report.BrokenAt = seq
+		report.Problem = problem
+	}
+
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prev := auditHead{}
+		prefix := []byte(auditEntryPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var entry domain.AuditEntry
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &entry)
+			}); err != nil {
+				fail(prev.Seq+1, "entry is not valid JSON")
+				return nil
+			}
+			switch {
+			case entry.Seq != prev.Seq+1:
+				fail(prev.Seq+1, fmt.Sprintf("entry missing; next is %d", entry.Seq))
+			case entry.PrevHash != prev.Hash:
+				fail(entry.Seq, "does not link to the previous entry")
+			case entry.ComputeHash() != entry.Hash:
+				fail(entry.Seq, "content does not match its hash")
+			}
+			if !report.Valid {
+				return nil
+			}
+			report.Entries++
+			prev = auditHead{Seq: entry.Seq, Hash: entry.Hash}
+		}
+
+		head, err := getAuditHead(txn)
+		if err != nil {
+			return err
+		}
+		if head != prev {
+			fail(prev.Seq+1, fmt.Sprintf("log ends at %d but the head records %d", prev.Seq, head.Seq))
+			return nil
+		}
+		report.Head = head.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Settings returns the configuration recorded at the last start
+func (r *AuditRepo) Settings(ctx context.Context) (map[string]string, error) {
+	var settings map[string]string
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(auditSettingsKey))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return nil
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &settings)
+		})
+	})
+	return settings, err
+}
+
+// SaveSettings records the configuration the node started with
+func (r *AuditRepo) SaveSettings(ctx context.Context, settings map[string]string) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(auditSettingsKey), data)
+	})
+}
+
+func getAuditHead(txn *badger.Txn) (auditHead, error) {
+	var head auditHead
+	item, err := txn.Get([]byte(auditHeadKey))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return head, nil
+		}
+		return head, err
+	}
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &head)
+	})
+	return head, err
+}
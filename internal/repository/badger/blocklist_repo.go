@@ -0,0 +1,94 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const blocklistPrefix = "blocklist:"
+
+// BlocklistRepo implements BlocklistRepository using BadgerDB
+type BlocklistRepo struct {
+	db *DB
+}
+
+// NewBlocklistRepo creates a new BadgerDB-based blocklist subscription repository
+func NewBlocklistRepo(db *DB) *BlocklistRepo {
+	return &BlocklistRepo{db: db}
+}
+
+func blocklistKey(name string) []byte {
+	return []byte(blocklistPrefix + name)
+}
+
+// Save creates or replaces a subscription
+func (r *BlocklistRepo) Save(ctx context.Context, sub *domain.BlocklistSubscription) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(blocklistKey(sub.Name), data)
+	})
+}
+
+// Get retrieves a subscription by name
+func (r *BlocklistRepo) Get(ctx context.Context, name string) (*domain.BlocklistSubscription, error) {
+	var sub domain.BlocklistSubscription
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(blocklistKey(name))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrBlocklistNotFound
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &sub)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Delete removes a subscription
+func (r *BlocklistRepo) Delete(ctx context.Context, name string) error {
+	return r.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get(blocklistKey(name)); err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrBlocklistNotFound
+			}
+			return err
+		}
+		return txn.Delete(blocklistKey(name))
+	})
+}
+
+// List retrieves every subscription, ordered by name
+func (r *BlocklistRepo) List(ctx context.Context) ([]*domain.BlocklistSubscription, error) {
+	var subs []*domain.BlocklistSubscription
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(blocklistPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var sub domain.BlocklistSubscription
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &sub)
+			}); err != nil {
+				return err
+			}
+			subs = append(subs, &sub)
+		}
+		return nil
+	})
+	return subs, err
+}
@@ -0,0 +1,111 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const (
+	circlePrefix     = "circle:id:"
+	circleKeysPrefix = "circle:keys:"
+)
+
+// CircleRepo implements CircleRepository using BadgerDB
+type CircleRepo struct {
+	db *DB
+}
+
+// NewCircleRepo creates a new BadgerDB-based circle repository
+func NewCircleRepo(db *DB) *CircleRepo {
+	return &CircleRepo{db: db}
+}
+
+// circleKeysKey orders a circle's key messages by epoch
+func circleKeysKey(circleID string, epoch int) []byte {
+	return fmt.Appendf(nil, "%s%s:%010d", circleKeysPrefix, circleID, epoch)
+}
+
+// Save creates or replaces a circle
+func (r *CircleRepo) Save(ctx context.Context, circle *domain.Circle) error {
+	data, err := json.Marshal(circle)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(circlePrefix+circle.ID), data)
+	})
+}
+
+// Get retrieves a circle by ID
+func (r *CircleRepo) Get(ctx context.Context, id string) (*domain.Circle, error) {
+	var circle domain.Circle
+	if err := r.get([]byte(circlePrefix+id), &circle, domain.ErrCircleNotFound); err != nil {
+		return nil, err
+	}
+	return &circle, nil
+}
+
+// List retrieves every circle, ordered by ID
+func (r *CircleRepo) List(ctx context.Context) ([]*domain.Circle, error) {
+	var circles []*domain.Circle
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(circlePrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var circle domain.Circle
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &circle)
+			}); err != nil {
+				return err
+			}
+			circles = append(circles, &circle)
+		}
+		return nil
+	})
+	return circles, err
+}
+
+// SaveKeys stores the key message for its circle's epoch
+func (r *CircleRepo) SaveKeys(ctx context.Context, msg *domain.CircleKeyMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(circleKeysKey(msg.CircleID, msg.Epoch), data)
+	})
+}
+
+// GetKeys retrieves the key message for a circle's epoch
+func (r *CircleRepo) GetKeys(ctx context.Context, circleID string, epoch int) (*domain.CircleKeyMessage, error) {
+	var msg domain.CircleKeyMessage
+	if err := r.get(circleKeysKey(circleID, epoch), &msg, domain.ErrCircleKeyUnavailable); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// get decodes the value under key into out, returning notFound if the key
+// is missing
+func (r *CircleRepo) get(key []byte, out any, notFound error) error {
+	return r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return notFound
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, out)
+		})
+	})
+}
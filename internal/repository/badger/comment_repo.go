@@ -0,0 +1,137 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// CommentRepo implements CommentRepository using BadgerDB
+type CommentRepo struct {
+	db *DB
+}
+
+// NewCommentRepo creates a new BadgerDB-based comment repository
+func NewCommentRepo(db *DB) *CommentRepo {
+	return &CommentRepo{db: db}
+}
+
+func commentKey(id string) []byte {
+	return []byte(fmt.Sprintf("comment:id:%s", id))
+}
+
+// commentArticleKey orders an article's comments by creation time
+func commentArticleKey(comment *domain.Comment) []byte {
+	return []byte(fmt.Sprintf("comment:article:%s:%d:%s", comment.ArticleID, comment.CreatedAt.UnixNano(), comment.ID))
+}
+
+// Create creates a new comment
+func (r *CommentRepo) Create(ctx context.Context, comment *domain.Comment) error {
+	data, err := json.Marshal(comment)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set(commentKey(comment.ID), data); err != nil {
+			return err
+		}
+		return txn.Set(commentArticleKey(comment), []byte(comment.ID))
+	})
+}
+
+// GetByID retrieves a comment by ID
+func (r *CommentRepo) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	var comment *domain.Comment
+	err := r.db.View(func(txn *badger.Txn) error {
+		var err error
+		comment, err = getComment(txn, id)
+		return err
+	})
+	return comment, err
+}
+
+// Update updates an existing comment
+func (r *CommentRepo) Update(ctx context.Context, comment *domain.Comment) error {
+	data, err := json.Marshal(comment)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if _, err := getComment(txn, comment.ID); err != nil {
+			return err
+		}
+		return txn.Set(commentKey(comment.ID), data)
+	})
+}
+
+// Delete deletes a comment by ID
+func (r *CommentRepo) Delete(ctx context.Context, id string) error {
+	return r.db.Update(func(txn *badger.Txn) error {
+		comment, err := getComment(txn, id)
+		if err != nil {
+			return err
+		}
+		if err := txn.Delete(commentArticleKey(comment)); err != nil {
+			return err
+		}
+		return txn.Delete(commentKey(id))
+	})
+}
+
+// ListByArticle retrieves an article's comments oldest first with pagination
+func (r *CommentRepo) ListByArticle(ctx context.Context, articleID string, page, limit int) ([]*domain.Comment, int, error) {
+	var comments []*domain.Comment
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(fmt.Sprintf("comment:article:%s:", articleID))
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			id, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				continue
+			}
+			comment, err := getComment(txn, string(id))
+			if err != nil {
+				continue
+			}
+			comments = append(comments, comment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(comments)
+	if limit > 0 {
+		start := max(0, (page-1)*limit)
+		if start >= total {
+			return []*domain.Comment{}, total, nil
+		}
+		comments = comments[start:min(start+limit, total)]
+	}
+	return comments, total, nil
+}
+
+func getComment(txn *badger.Txn, id string) (*domain.Comment, error) {
+	item, err := txn.Get(commentKey(id))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, domain.ErrCommentNotFound
+		}
+		return nil, err
+	}
+	var comment domain.Comment
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &comment)
+	}); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
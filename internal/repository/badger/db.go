@@ -17,8 +17,20 @@ type DB struct {
 
 // New creates a new BadgerDB instance and starts GC
 func New(dbPath string) (*DB, error) {
+	return NewEncrypted(dbPath, nil)
+}
+
+// NewEncrypted is New with the store encrypted at rest under an AES key
+// of 16, 24 or 32 bytes. A nil key leaves it unencrypted. A store created
+// with a key can only be opened with it.
+func NewEncrypted(dbPath string, encryptionKey []byte) (*DB, error) {
 	opts := badger.DefaultOptions(dbPath)
 	opts.Logger = nil // Disable badger's logger
+	if len(encryptionKey) > 0 {
+		// Encrypted tables are decrypted into the index cache instead of
+		// being held in memory whole
+		opts = opts.WithEncryptionKey(encryptionKey).WithIndexCacheSize(100 << 20)
+	}
 
 	db, err := badger.Open(opts)
 	if err != nil {
@@ -51,6 +63,21 @@ func (db *DB) HealthCheck() error {
 	})
 }
 
+// Empty reports whether the database holds no keys
+func (db *DB) Empty() (bool, error) {
+	empty := true
+	err := db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		it.Rewind()
+		empty = !it.Valid()
+		return nil
+	})
+	return empty, err
+}
+
 // runGC runs value log garbage collection periodically
 func (db *DB) runGC() {
 	ticker := time.NewTicker(5 * time.Minute)
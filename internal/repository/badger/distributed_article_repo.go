@@ -0,0 +1,479 @@
+package badger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// OpPublisher replicates article operations to the rest of the network
+type OpPublisher interface {
+	BroadcastOp(op *domain.ArticleOp) error
+}
+
+// ArticleVerifier checks authors' signatures on replicated articles and
+// deletions, e.g. auth.ArticleSigner
+type ArticleVerifier interface {
+	VerifyArticle(article *domain.Article) error
+	VerifyArticleDeletion(deletion *domain.ArticleDeletion) error
+}
+
+// ArticleIngester takes articles from remote puts through the node's
+// checks for articles from peers, storing and indexing the ones it
+// accepts, and drops remotely deleted articles from the search index,
+// e.g. the article service
+type ArticleIngester interface {
+	IngestArticle(ctx context.Context, article *domain.Article) error
+	ArticleRemoved(ctx context.Context, article *domain.Article)
+}
+
+// remoteOpKey marks the context of writes made while applying a remote op,
+// which are neither stamped nor replicated again
+type remoteOpKey struct{}
+
+// maxClockJump bounds how far a remote op may move the Lamport clock
+// ahead of this node's, so a peer can't push it to overflow and win every
+// later write. Honest clocks only grow by the number of ops in the network.
+const maxClockJump = 1 << 32
+
+// opHead is the tombstone an author's deletion leaves for an article. It
+// holds the signed deletion time, so replayed or re-gossiped puts of the
+// article can't bring it back.
+type opHead struct {
+	Clock        uint64 `json:"clock"`
+	NodeID       string `json:"node_id"`
+	Deleted      bool   `json:"deleted"`
+	DeletedAt    int64  `json:"deleted_at,omitempty"`    // ArticleDeletion.Timestamp
+	AuthorPubKey string `json:"author_pubkey,omitempty"` // key that signed the deletion
+}
+
+// buries reports whether the tombstone h deletes article: the same author
+// signed a deletion no earlier than the article. Tombstones written
+// before deletion times were kept bury every version.
+func (h *opHead) buries(article *domain.Article) bool {
+	if h == nil || !h.Deleted {
+		return false
+	}
+	if h.AuthorPubKey != "" && h.AuthorPubKey != article.AuthorPubKey {
+		return false
+	}
+	return h.DeletedAt == 0 || article.Timestamp.Unix() <= h.DeletedAt
+}
+
+// tombstone is the head recorded for an author's deletion
+func tombstone(clock uint64, nodeID string, deletion *domain.ArticleDeletion) *opHead {
+	return &opHead{
+		Clock:        clock,
+		NodeID:       nodeID,
+		Deleted:      true,
+		DeletedAt:    deletion.Timestamp,
+		AuthorPubKey: deletion.AuthorPubKey,
+	}
+}
+
+// newerThan orders two versions of one article by the author's edits:
+// the higher version wins, then the later update. Signatures break ties
+// so every node picks the same winner.
+func newerThan(incoming, existing *domain.Article) bool {
+	if incoming.Version != existing.Version {
+		return incoming.Version > existing.Version
+	}
+	if !incoming.UpdatedAt.Equal(existing.UpdatedAt) {
+		return incoming.UpdatedAt.After(existing.UpdatedAt)
+	}
+	return incoming.Signature > existing.Signature
+}
+
+// DistributedArticleRepo implements the "distributed" database mode. Every
+// local write is applied to Badger and emitted as a signed op; ops from
+// other nodes are merged per article by the author's version and edit
+// time, so all nodes converge on one logical article store. Only the
+// author can change an article: puts must carry the author's signature
+// and deletes the author's signed deletion, which sticks against every
+// version signed before it. Circle articles are only
+// published sealed, so their writes stay local and are never replicated.
+type DistributedArticleRepo struct {
+	*ArticleRepo
+	db        *DB
+	publisher OpPublisher
+	verifier  ArticleVerifier
+	ingester  ArticleIngester // optional; without it remote puts are stored as they are
+	nodeID    string
+	logger    *logger.Logger
+
+	mu        sync.Mutex // serialises clock bumps and merges
+	listeners []func(id, cid string)
+}
+
+// NewDistributedArticleRepo creates an op-log replicated article repository
+func NewDistributedArticleRepo(db *DB, publisher OpPublisher, verifier ArticleVerifier, nodeID string, log *logger.Logger) *DistributedArticleRepo {
+	return &DistributedArticleRepo{
+		ArticleRepo: NewArticleRepo(db),
+		db:          db,
+		publisher:   publisher,
+		verifier:    verifier,
+		nodeID:      nodeID,
+		logger:      log.WithComponent("distributed-article-repo"),
+	}
+}
+
+// SetIngester routes remote puts through ingester, which screens, stores
+// and indexes them like gossiped articles
+func (r *DistributedArticleRepo) SetIngester(ingester ArticleIngester) {
+	r.ingester = ingester
+}
+
+// OnRemoteChange registers a callback invoked after a remote op changes an article
+func (r *DistributedArticleRepo) OnRemoteChange(fn func(id, cid string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Create creates an article locally and replicates it
+func (r *DistributedArticleRepo) Create(ctx context.Context, article *domain.Article) error {
+	return r.write(ctx, domain.OpPut, article.ID, article, func() error {
+		return r.ArticleRepo.Create(ctx, article)
+	}, nil)
+}
+
+// Update updates an article locally and replicates it
+func (r *DistributedArticleRepo) Update(ctx context.Context, article *domain.Article) error {
+	return r.write(ctx, domain.OpPut, article.ID, article, func() error {
+		return r.ArticleRepo.Update(ctx, article)
+	}, nil)
+}
+
+// Delete deletes an article locally and replicates a tombstone carrying
+// the author's signed deletion from ctx. Without one, e.g. when storage
+// limits remove a synced article, or for a circle article, the delete
+// stays local.
+func (r *DistributedArticleRepo) Delete(ctx context.Context, id string) error {
+	if isRemoteOp(ctx) {
+		return r.ArticleRepo.Delete(ctx, id)
+	}
+	deletion, ok := domain.ArticleDeletionFrom(ctx)
+	if !ok || r.inCircle(ctx, id) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.ArticleRepo.Delete(ctx, id)
+	}
+
+	return r.write(ctx, domain.OpDelete, id, nil, func() error {
+		return r.ArticleRepo.Delete(ctx, id)
+	}, deletion)
+}
+
+// CreateBatch creates several articles locally in one transaction and
+// replicates each of them but circle articles
+func (r *DistributedArticleRepo) CreateBatch(ctx context.Context, articles []*domain.Article) error {
+	r.mu.Lock()
+	for _, article := range articles {
+		if err := r.checkTombstone(article); err != nil {
+			r.mu.Unlock()
+			return err
+		}
+	}
+	if err := r.ArticleRepo.CreateBatch(ctx, articles); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+
+	ops := make([]*domain.ArticleOp, 0, len(articles))
+	for _, article := range articles {
+		if article.InCircle() {
+			continue
+		}
+		op, err := r.stamp(domain.OpPut, article.ID, article, nil)
+		if err != nil {
+			r.mu.Unlock()
+			return err
+		}
+		ops = append(ops, op)
+	}
+	r.mu.Unlock()
+
+	for _, op := range ops {
+		r.publish(op)
+	}
+	return nil
+}
+
+// write applies a local mutation, stamps it with the next clock value and
+// publishes it. Circle articles are applied without either.
+func (r *DistributedArticleRepo) write(ctx context.Context, opType, id string, article *domain.Article, apply func() error, deletion *domain.ArticleDeletion) error {
+	// ApplyRemote holds r.mu and records the head itself
+	if isRemoteOp(ctx) {
+		return apply()
+	}
+
+	r.mu.Lock()
+	if article != nil {
+		if err := r.checkTombstone(article); err != nil {
+			r.mu.Unlock()
+			return err
+		}
+	}
+	if err := apply(); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	if article != nil && article.InCircle() {
+		r.mu.Unlock()
+		return nil
+	}
+
+	op, err := r.stamp(opType, id, article, deletion)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	r.publish(op)
+	return nil
+}
+
+// stamp assigns an applied local write the next clock value and records
+// a delete's tombstone. The caller must hold r.mu.
+func (r *DistributedArticleRepo) stamp(opType, id string, article *domain.Article, deletion *domain.ArticleDeletion) (*domain.ArticleOp, error) {
+	clock, err := r.tick(0)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &domain.ArticleOp{
+		Type:      opType,
+		ArticleID: id,
+		Article:   article,
+		Deletion:  deletion,
+		Clock:     clock,
+		NodeID:    r.nodeID,
+		Timestamp: time.Now().UTC(),
+	}
+	if opType == domain.OpDelete {
+		if err := r.saveHead(id, tombstone(clock, r.nodeID, deletion)); err != nil {
+			return nil, err
+		}
+	}
+	return op, nil
+}
+
+// checkTombstone refuses a local put of an article its author has
+// deleted, e.g. one gossiped or synced again. The caller must hold r.mu.
+func (r *DistributedArticleRepo) checkTombstone(article *domain.Article) error {
+	head, err := r.loadHead(article.ID)
+	if err != nil {
+		return err
+	}
+	if head.buries(article) {
+		return domain.ErrArticleDeleted
+	}
+	return nil
+}
+
+// publish replicates a local op. The local write already succeeded; peers
+// will catch up via sync if this fails.
+func (r *DistributedArticleRepo) publish(op *domain.ArticleOp) {
+	if err := r.publisher.BroadcastOp(op); err != nil {
+		r.logger.Warn("Failed to replicate article op", "op", op.Type, "article_id", op.ArticleID, "error", err)
+	}
+}
+
+// inCircle reports whether the stored article id is a circle article
+func (r *DistributedArticleRepo) inCircle(ctx context.Context, id string) bool {
+	article, err := r.ArticleRepo.GetByID(ctx, id)
+	return err == nil && article.InCircle()
+}
+
+// ApplyRemote merges an op received from another node. Puts of a version
+// no newer than the stored one are ignored, as are puts an author's
+// deletion buries and deletions older than the stored article. Deletes of
+// articles this node doesn't have still leave a tombstone. Ops the
+// article's author didn't sign are rejected.
+func (r *DistributedArticleRepo) ApplyRemote(ctx context.Context, op *domain.ArticleOp) error {
+	if op.ArticleID == "" {
+		return fmt.Errorf("%w: op without article id", domain.ErrInvalidArticle)
+	}
+	switch op.Type {
+	case domain.OpPut:
+		if op.Article == nil || op.Article.ID != op.ArticleID {
+			return fmt.Errorf("%w: put op without matching article", domain.ErrInvalidArticle)
+		}
+		if err := r.verifier.VerifyArticle(op.Article); err != nil {
+			return err
+		}
+	case domain.OpDelete:
+		if op.Deletion == nil || op.Deletion.ArticleID != op.ArticleID {
+			return fmt.Errorf("%w: delete op without the author's deletion", domain.ErrInvalidArticle)
+		}
+		if err := r.verifier.VerifyArticleDeletion(op.Deletion); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown op type: %s", op.Type)
+	}
+
+	r.mu.Lock()
+	if _, err := r.tick(op.Clock); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+
+	head, err := r.loadHead(op.ArticleID)
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	existing, err := r.ArticleRepo.GetByID(ctx, op.ArticleID)
+	if err != nil && !errors.Is(err, domain.ErrArticleNotFound) {
+		r.mu.Unlock()
+		return err
+	}
+	if existing != nil && op.AuthorPubKey() != existing.AuthorPubKey {
+		r.mu.Unlock()
+		r.logger.Warn("Rejecting article op signed by another author", "article_id", op.ArticleID, "node_id", op.NodeID)
+		return fmt.Errorf("%w: article belongs to another author", domain.ErrForbidden)
+	}
+
+	var stale bool
+	switch op.Type {
+	case domain.OpPut:
+		stale = head.buries(op.Article) || (existing != nil && !newerThan(op.Article, existing))
+	case domain.OpDelete:
+		if existing == nil {
+			// Remember the deletion so the put, if it arrives later, stays
+			// buried, but don't let another author replace a tombstone
+			if head == nil || !head.Deleted || head.AuthorPubKey == op.Deletion.AuthorPubKey {
+				err = r.saveHead(op.ArticleID, tombstone(op.Clock, op.NodeID, op.Deletion))
+			}
+			r.mu.Unlock()
+			return err
+		}
+		stale = existing.Timestamp.Unix() > op.Deletion.Timestamp
+	}
+	if stale {
+		r.mu.Unlock()
+		r.logger.Debug("Ignoring stale article op", "type", op.Type, "article_id", op.ArticleID, "node_id", op.NodeID)
+		return nil
+	}
+
+	var cid string
+	switch op.Type {
+	case domain.OpPut:
+		cid = op.Article.CID
+		err = r.applyPut(ctx, op.Article)
+	case domain.OpDelete:
+		cid = existing.CID
+		err = r.ArticleRepo.Delete(ctx, op.ArticleID)
+		if err == nil && r.ingester != nil {
+			r.ingester.ArticleRemoved(ctx, existing)
+		}
+	}
+	if err == nil && op.Type == domain.OpDelete {
+		err = r.saveHead(op.ArticleID, tombstone(op.Clock, op.NodeID, op.Deletion))
+	}
+	listeners := make([]func(id, cid string), len(r.listeners))
+	copy(listeners, r.listeners)
+	r.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range listeners {
+		fn(op.ArticleID, cid)
+	}
+	return nil
+}
+
+// applyPut stores a remote article. The ingester checks, stores and
+// indexes it like a gossiped article; without one it replaces the local
+// copy in one transaction.
+func (r *DistributedArticleRepo) applyPut(ctx context.Context, article *domain.Article) error {
+	if r.ingester != nil {
+		return r.ingester.IngestArticle(context.WithValue(ctx, remoteOpKey{}, true), article)
+	}
+	return r.ArticleRepo.Replace(ctx, article)
+}
+
+// isRemoteOp reports whether ctx belongs to applying a remote op
+func isRemoteOp(ctx context.Context) bool {
+	remote, _ := ctx.Value(remoteOpKey{}).(bool)
+	return remote
+}
+
+// tick advances the persisted Lamport clock past seen and returns the new
+// value. A seen clock more than maxClockJump ahead is refused.
+func (r *DistributedArticleRepo) tick(seen uint64) (uint64, error) {
+	var next uint64
+	err := r.db.Update(func(txn *badger.Txn) error {
+		var current uint64
+		item, err := txn.Get([]byte("oplog:clock"))
+		if err == nil {
+			if err := item.Value(func(val []byte) error {
+				current = binary.BigEndian.Uint64(val)
+				return nil
+			}); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		if seen > current {
+			if seen-current > maxClockJump {
+				return fmt.Errorf("%w: clock %d jumps too far past %d", domain.ErrInvalidArticle, seen, current)
+			}
+			current = seen
+		}
+		if current == math.MaxUint64 {
+			return fmt.Errorf("%w: clock exhausted", domain.ErrInvalidArticle)
+		}
+		next = current + 1
+
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, next)
+		return txn.Set([]byte("oplog:clock"), buf)
+	})
+	return next, err
+}
+
+// loadHead returns an article's tombstone, or nil if none
+func (r *DistributedArticleRepo) loadHead(id string) (*opHead, error) {
+	var head *opHead
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(fmt.Sprintf("oplog:head:%s", id)))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return nil
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			head = &opHead{}
+			return json.Unmarshal(val, head)
+		})
+	})
+	return head, err
+}
+
+// saveHead records an article's tombstone
+func (r *DistributedArticleRepo) saveHead(id string, head *opHead) error {
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(fmt.Sprintf("oplog:head:%s", id)), data)
+	})
+}
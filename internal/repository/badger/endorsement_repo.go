@@ -0,0 +1,95 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const endorsementPrefix = "endorsement:"
+
+// EndorsementRepo implements EndorsementRepository using BadgerDB
+type EndorsementRepo struct {
+	db *DB
+}
+
+// NewEndorsementRepo creates a new BadgerDB-based endorsement repository
+func NewEndorsementRepo(db *DB) *EndorsementRepo {
+	return &EndorsementRepo{db: db}
+}
+
+// endorsementSubjectPrefix groups a subject's records. Usernames may hold
+// colons, so a NUL ends the subject.
+func endorsementSubjectPrefix(subject string) []byte {
+	return []byte(endorsementPrefix + subject + "\x00")
+}
+
+func endorsementKey(subject, endorser string) []byte {
+	return append(endorsementSubjectPrefix(subject), endorser...)
+}
+
+// Save creates or replaces the endorser's record for its subject
+func (r *EndorsementRepo) Save(ctx context.Context, endorsement *domain.Endorsement) error {
+	data, err := json.Marshal(endorsement)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(endorsementKey(endorsement.Subject, endorsement.Endorser), data)
+	})
+}
+
+// Get retrieves the endorser's record for subject
+func (r *EndorsementRepo) Get(ctx context.Context, subject, endorser string) (*domain.Endorsement, error) {
+	var endorsement domain.Endorsement
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(endorsementKey(subject, endorser))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrEndorsementNotFound
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &endorsement)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &endorsement, nil
+}
+
+// ListBySubject retrieves every record for subject, revoked ones included
+func (r *EndorsementRepo) ListBySubject(ctx context.Context, subject string) ([]*domain.Endorsement, error) {
+	return r.list(endorsementSubjectPrefix(subject))
+}
+
+// List retrieves every record
+func (r *EndorsementRepo) List(ctx context.Context) ([]*domain.Endorsement, error) {
+	return r.list([]byte(endorsementPrefix))
+}
+
+func (r *EndorsementRepo) list(prefix []byte) ([]*domain.Endorsement, error) {
+	var endorsements []*domain.Endorsement
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var endorsement domain.Endorsement
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &endorsement)
+			}); err != nil {
+				return err
+			}
+			endorsements = append(endorsements, &endorsement)
+		}
+		return nil
+	})
+	return endorsements, err
+}
@@ -0,0 +1,94 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const filterPrefix = "filter:"
+
+// FilterListRepo implements FilterListRepository using BadgerDB
+type FilterListRepo struct {
+	db *DB
+}
+
+// NewFilterListRepo creates a new BadgerDB-based filter list repository
+func NewFilterListRepo(db *DB) *FilterListRepo {
+	return &FilterListRepo{db: db}
+}
+
+func filterKey(name string) []byte {
+	return []byte(filterPrefix + name)
+}
+
+// Save creates or replaces a list
+func (r *FilterListRepo) Save(ctx context.Context, list *domain.FilterList) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(filterKey(list.Name), data)
+	})
+}
+
+// Get retrieves a list by name
+func (r *FilterListRepo) Get(ctx context.Context, name string) (*domain.FilterList, error) {
+	var list domain.FilterList
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(filterKey(name))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrFilterListNotFound
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &list)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// Delete removes a list
+func (r *FilterListRepo) Delete(ctx context.Context, name string) error {
+	return r.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get(filterKey(name)); err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrFilterListNotFound
+			}
+			return err
+		}
+		return txn.Delete(filterKey(name))
+	})
+}
+
+// List retrieves every list, ordered by name
+func (r *FilterListRepo) List(ctx context.Context) ([]*domain.FilterList, error) {
+	var lists []*domain.FilterList
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(filterPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var list domain.FilterList
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &list)
+			}); err != nil {
+				return err
+			}
+			lists = append(lists, &list)
+		}
+		return nil
+	})
+	return lists, err
+}
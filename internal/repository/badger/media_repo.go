@@ -0,0 +1,58 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// MediaRepo implements MediaRepository using BadgerDB
+type MediaRepo struct {
+	db *DB
+}
+
+// NewMediaRepo creates a new BadgerDB-based media catalog
+func NewMediaRepo(db *DB) *MediaRepo {
+	return &MediaRepo{db: db}
+}
+
+func mediaKey(cid string) []byte {
+	return []byte(fmt.Sprintf("media:cid:%s", cid))
+}
+
+// Save creates or replaces the record for a CID
+func (r *MediaRepo) Save(ctx context.Context, media *domain.Media) error {
+	data, err := json.Marshal(media)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(mediaKey(media.CID), data)
+	})
+}
+
+// Get retrieves the record for a CID
+func (r *MediaRepo) Get(ctx context.Context, cid string) (*domain.Media, error) {
+	var media domain.Media
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(mediaKey(cid))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrMediaNotFound
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &media)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
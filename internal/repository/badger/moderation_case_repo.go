@@ -0,0 +1,190 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ModerationCaseRepo implements ModerationCaseRepository using BadgerDB
+type ModerationCaseRepo struct {
+	db *DB
+}
+
+// NewModerationCaseRepo creates a new BadgerDB-based case repository
+func NewModerationCaseRepo(db *DB) *ModerationCaseRepo {
+	return &ModerationCaseRepo{db: db}
+}
+
+func caseKey(id string) []byte {
+	return []byte(fmt.Sprintf("case:id:%s", id))
+}
+
+func caseTimeKey(c *domain.ModerationCase) []byte {
+	return []byte(fmt.Sprintf("case:time:%d:%s", c.CreatedAt.UnixNano(), c.ID))
+}
+
+// caseActiveKey points at the article's open or reviewing case
+func caseActiveKey(articleID string) []byte {
+	return []byte(fmt.Sprintf("case:active:%s", articleID))
+}
+
+// Create adds a case, making it the article's active case
+func (r *ModerationCaseRepo) Create(ctx context.Context, c *domain.ModerationCase) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if !c.Closed() {
+			if err := txn.Set(caseActiveKey(c.ArticleID), []byte(c.ID)); err != nil {
+				return err
+			}
+		}
+		if err := txn.Set(caseKey(c.ID), data); err != nil {
+			return err
+		}
+		return txn.Set(caseTimeKey(c), []byte(c.ID))
+	})
+}
+
+// GetByID retrieves a case by ID
+func (r *ModerationCaseRepo) GetByID(ctx context.Context, id string) (*domain.ModerationCase, error) {
+	var c *domain.ModerationCase
+	err := r.db.View(func(txn *badger.Txn) error {
+		var err error
+		c, err = getCase(txn, id)
+		return err
+	})
+	return c, err
+}
+
+// GetActive retrieves the article's open or reviewing case
+func (r *ModerationCaseRepo) GetActive(ctx context.Context, articleID string) (*domain.ModerationCase, error) {
+	var c *domain.ModerationCase
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(caseActiveKey(articleID))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrCaseNotFound
+			}
+			return err
+		}
+		id, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		c, err = getCase(txn, string(id))
+		return err
+	})
+	return c, err
+}
+
+// Update updates an existing case, retiring it as the article's active
+// case once it is closed. A later case on the article stays active.
+func (r *ModerationCaseRepo) Update(ctx context.Context, c *domain.ModerationCase) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if _, err := getCase(txn, c.ID); err != nil {
+			return err
+		}
+		if c.Closed() {
+			if err := retireActive(txn, c); err != nil {
+				return err
+			}
+		}
+		return txn.Set(caseKey(c.ID), data)
+	})
+}
+
+// retireActive drops the article's active case pointer if it points at c
+func retireActive(txn *badger.Txn, c *domain.ModerationCase) error {
+	item, err := txn.Get(caseActiveKey(c.ArticleID))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		return err
+	}
+	id, err := item.ValueCopy(nil)
+	if err != nil || string(id) != c.ID {
+		return err
+	}
+	return txn.Delete(caseActiveKey(c.ArticleID))
+}
+
+// List retrieves cases newest first with pagination and filtering
+func (r *ModerationCaseRepo) List(ctx context.Context, filter *domain.CaseListFilter) ([]*domain.ModerationCase, int, error) {
+	var cases []*domain.ModerationCase
+	err := r.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true // Newest first
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte("case:time:")
+		for it.Seek(append(prefix, 0xFF)); it.ValidForPrefix(prefix); it.Next() {
+			id, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				continue
+			}
+			c, err := getCase(txn, string(id))
+			if err != nil {
+				continue
+			}
+			if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, c.Status) {
+				continue
+			}
+			if filter.Assignee != "" && c.Assignee != filter.Assignee {
+				continue
+			}
+			if filter.ArticleID != "" && c.ArticleID != filter.ArticleID {
+				continue
+			}
+			cases = append(cases, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(cases)
+	if filter.Limit > 0 {
+		start := (filter.Page - 1) * filter.Limit
+		if start < 0 {
+			start = 0
+		}
+		if start >= total {
+			return []*domain.ModerationCase{}, total, nil
+		}
+		cases = cases[start:min(start+filter.Limit, total)]
+	}
+	return cases, total, nil
+}
+
+func getCase(txn *badger.Txn, id string) (*domain.ModerationCase, error) {
+	item, err := txn.Get(caseKey(id))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, domain.ErrCaseNotFound
+		}
+		return nil, err
+	}
+	var c domain.ModerationCase
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &c)
+	}); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,159 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// PinRepo implements PinRepository using BadgerDB
+type PinRepo struct {
+	db *DB
+}
+
+// NewPinRepo creates a new BadgerDB-based pin ledger
+func NewPinRepo(db *DB) *PinRepo {
+	return &PinRepo{db: db}
+}
+
+func pinKey(cid string) []byte {
+	return []byte(fmt.Sprintf("pin:cid:%s", cid))
+}
+
+func pinRefKey(ref, cid string) []byte {
+	return []byte(fmt.Sprintf("pin:ref:%s:%s", ref, cid))
+}
+
+// Save creates or replaces the record for a CID
+func (r *PinRepo) Save(ctx context.Context, pin *domain.PinRecord) error {
+	data, err := json.Marshal(pin)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set(pinKey(pin.CID), data); err != nil {
+			return err
+		}
+		if pin.Ref == "" {
+			return nil
+		}
+		return txn.Set(pinRefKey(pin.Ref, pin.CID), []byte(pin.CID))
+	})
+}
+
+// Get retrieves the record for a CID
+func (r *PinRepo) Get(ctx context.Context, cid string) (*domain.PinRecord, error) {
+	var pin domain.PinRecord
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(pinKey(cid))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrPinNotFound
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &pin)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pin, nil
+}
+
+// Delete removes the record for a CID
+func (r *PinRepo) Delete(ctx context.Context, cid string) error {
+	return r.db.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get(pinKey(cid))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrPinNotFound
+			}
+			return err
+		}
+		var pin domain.PinRecord
+		if err := item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &pin)
+		}); err != nil {
+			return err
+		}
+
+		if pin.Ref != "" {
+			if err := txn.Delete(pinRefKey(pin.Ref, cid)); err != nil {
+				return err
+			}
+		}
+		return txn.Delete(pinKey(cid))
+	})
+}
+
+// DeleteByRef removes every record owned by ref and returns their CIDs
+func (r *PinRepo) DeleteByRef(ctx context.Context, ref string) ([]string, error) {
+	var cids []string
+	err := r.db.Update(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(fmt.Sprintf("pin:ref:%s:", ref))
+		var keys [][]byte
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			keys = append(keys, it.Item().KeyCopy(nil))
+			cids = append(cids, string(keys[len(keys)-1][len(prefix):]))
+		}
+		for i, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+			if err := txn.Delete(pinKey(cids[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return cids, err
+}
+
+// List retrieves all records, optionally filtered by status
+func (r *PinRepo) List(ctx context.Context, status string) ([]*domain.PinRecord, error) {
+	var pins []*domain.PinRecord
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte("pin:cid:")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var pin domain.PinRecord
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &pin)
+			})
+			if err == nil && (status == "" || pin.Status == status) {
+				pins = append(pins, &pin)
+			}
+		}
+		return nil
+	})
+	return pins, err
+}
+
+// ListDue retrieves unconfirmed records whose next attempt is at or before now
+func (r *PinRepo) ListDue(ctx context.Context, now time.Time) ([]*domain.PinRecord, error) {
+	all, err := r.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var due []*domain.PinRecord
+	for _, pin := range all {
+		if pin.Status != domain.PinPinned && !pin.NextAttempt.After(now) {
+			due = append(due, pin)
+		}
+	}
+	return due, nil
+}
@@ -0,0 +1,172 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ReportRepo implements ReportRepository using BadgerDB
+type ReportRepo struct {
+	db *DB
+}
+
+// NewReportRepo creates a new BadgerDB-based moderation queue
+func NewReportRepo(db *DB) *ReportRepo {
+	return &ReportRepo{db: db}
+}
+
+func reportKey(id string) []byte {
+	return []byte(fmt.Sprintf("report:id:%s", id))
+}
+
+func reportTimeKey(report *domain.Report) []byte {
+	return []byte(fmt.Sprintf("report:time:%d:%s", report.CreatedAt.UnixNano(), report.ID))
+}
+
+// reportOpenKey indexes open reports so a reporter can't queue the same
+// article twice
+func reportOpenKey(articleID, reporter string) []byte {
+	return []byte(fmt.Sprintf("report:open:%s:%s", articleID, reporter))
+}
+
+// Create adds a report to the queue
+func (r *ReportRepo) Create(ctx context.Context, report *domain.Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		openKey := reportOpenKey(report.ArticleID, report.Reporter)
+		if report.Status == domain.ReportOpen {
+			if _, err := txn.Get(openKey); err == nil {
+				return domain.ErrAlreadyReported
+			}
+			if err := txn.Set(openKey, []byte(report.ID)); err != nil {
+				return err
+			}
+		}
+		if err := txn.Set(reportKey(report.ID), data); err != nil {
+			return err
+		}
+		return txn.Set(reportTimeKey(report), []byte(report.ID))
+	})
+}
+
+// GetByID retrieves a report by ID
+func (r *ReportRepo) GetByID(ctx context.Context, id string) (*domain.Report, error) {
+	var report *domain.Report
+	err := r.db.View(func(txn *badger.Txn) error {
+		var err error
+		report, err = getReport(txn, id)
+		return err
+	})
+	return report, err
+}
+
+// GetOpen retrieves the open report a reporter filed against an article
+func (r *ReportRepo) GetOpen(ctx context.Context, articleID, reporter string) (*domain.Report, error) {
+	var report *domain.Report
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(reportOpenKey(articleID, reporter))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrReportNotFound
+			}
+			return err
+		}
+		id, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		report, err = getReport(txn, string(id))
+		return err
+	})
+	return report, err
+}
+
+// Update updates an existing report, dropping it from the open index once
+// it is closed
+func (r *ReportRepo) Update(ctx context.Context, report *domain.Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if _, err := getReport(txn, report.ID); err != nil {
+			return err
+		}
+		if report.Status != domain.ReportOpen {
+			if err := txn.Delete(reportOpenKey(report.ArticleID, report.Reporter)); err != nil {
+				return err
+			}
+		}
+		return txn.Set(reportKey(report.ID), data)
+	})
+}
+
+// List retrieves reports newest first with pagination and filtering
+func (r *ReportRepo) List(ctx context.Context, filter *domain.ReportListFilter) ([]*domain.Report, int, error) {
+	var reports []*domain.Report
+	err := r.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true // Newest first
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte("report:time:")
+		for it.Seek(append(prefix, 0xFF)); it.ValidForPrefix(prefix); it.Next() {
+			id, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				continue
+			}
+			report, err := getReport(txn, string(id))
+			if err != nil {
+				continue
+			}
+			if filter.Status != "" && report.Status != filter.Status {
+				continue
+			}
+			reports = append(reports, report)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(reports)
+	if filter.Limit > 0 {
+		start := (filter.Page - 1) * filter.Limit
+		if start < 0 {
+			start = 0
+		}
+		if start >= total {
+			return []*domain.Report{}, total, nil
+		}
+		reports = reports[start:min(start+filter.Limit, total)]
+	}
+	return reports, total, nil
+}
+
+func getReport(txn *badger.Txn, id string) (*domain.Report, error) {
+	item, err := txn.Get(reportKey(id))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, domain.ErrReportNotFound
+		}
+		return nil, err
+	}
+	var report domain.Report
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &report)
+	}); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
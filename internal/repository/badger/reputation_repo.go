@@ -0,0 +1,74 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+const reputationPrefix = "reputation:did:"
+
+// ReputationRepo implements ReputationRepository using BadgerDB
+type ReputationRepo struct {
+	db *DB
+}
+
+// NewReputationRepo creates a new BadgerDB-based reputation store
+func NewReputationRepo(db *DB) *ReputationRepo {
+	return &ReputationRepo{db: db}
+}
+
+func reputationKey(did string) []byte {
+	return []byte(reputationPrefix + did)
+}
+
+// List retrieves every stored score
+func (r *ReputationRepo) List(ctx context.Context) ([]*domain.ReputationScore, error) {
+	var scores []*domain.ReputationScore
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(reputationPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var score domain.ReputationScore
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &score)
+			}); err != nil {
+				return err
+			}
+			scores = append(scores, &score)
+		}
+		return nil
+	})
+	return scores, err
+}
+
+// Save creates or replaces the given scores. A write batch is used so a
+// snapshot of many scores isn't limited by the transaction size.
+func (r *ReputationRepo) Save(ctx context.Context, scores []*domain.ReputationScore) error {
+	batch := r.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	for _, score := range scores {
+		data, err := json.Marshal(score)
+		if err != nil {
+			return err
+		}
+		if err := batch.Set(reputationKey(score.DID), data); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
+// Replace drops every stored score and saves scores in their place
+func (r *ReputationRepo) Replace(ctx context.Context, scores []*domain.ReputationScore) error {
+	if err := r.db.DropPrefix([]byte(reputationPrefix)); err != nil {
+		return err
+	}
+	return r.Save(ctx, scores)
+}
@@ -0,0 +1,124 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// Every edge is stored twice, under its truster and under its subject, so
+// both directions of the graph can be walked by prefix
+const (
+	trustOutPrefix = "trust:out:"
+	trustInPrefix  = "trust:in:"
+)
+
+// TrustEdgeRepo implements TrustEdgeRepository using BadgerDB
+type TrustEdgeRepo struct {
+	db *DB
+}
+
+// NewTrustEdgeRepo creates a new BadgerDB-based trust edge repository
+func NewTrustEdgeRepo(db *DB) *TrustEdgeRepo {
+	return &TrustEdgeRepo{db: db}
+}
+
+// trustPrefix groups the edges of one identity in one direction.
+// Usernames may hold colons, so a NUL ends the identity.
+func trustPrefix(direction, identity string) []byte {
+	return []byte(direction + identity + "\x00")
+}
+
+func trustOutKey(truster, subject string) []byte {
+	return append(trustPrefix(trustOutPrefix, truster), subject...)
+}
+
+func trustInKey(truster, subject string) []byte {
+	return append(trustPrefix(trustInPrefix, subject), truster...)
+}
+
+// Save creates or replaces the truster's edge to its subject
+func (r *TrustEdgeRepo) Save(ctx context.Context, edge *domain.TrustEdge) error {
+	data, err := json.Marshal(edge)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set(trustOutKey(edge.Truster, edge.Subject), data); err != nil {
+			return err
+		}
+		return txn.Set(trustInKey(edge.Truster, edge.Subject), data)
+	})
+}
+
+// Get retrieves the truster's edge to subject
+func (r *TrustEdgeRepo) Get(ctx context.Context, truster, subject string) (*domain.TrustEdge, error) {
+	var edge domain.TrustEdge
+	err := r.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(trustOutKey(truster, subject))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrTrustEdgeNotFound
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &edge)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &edge, nil
+}
+
+// Delete removes the truster's edge to subject
+func (r *TrustEdgeRepo) Delete(ctx context.Context, truster, subject string) error {
+	return r.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get(trustOutKey(truster, subject)); err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return domain.ErrTrustEdgeNotFound
+			}
+			return err
+		}
+		if err := txn.Delete(trustOutKey(truster, subject)); err != nil {
+			return err
+		}
+		return txn.Delete(trustInKey(truster, subject))
+	})
+}
+
+// ListByTruster retrieves the edges a user made, ordered by subject
+func (r *TrustEdgeRepo) ListByTruster(ctx context.Context, truster string) ([]*domain.TrustEdge, error) {
+	return r.list(trustPrefix(trustOutPrefix, truster))
+}
+
+// ListBySubject retrieves the edges other users made to subject, ordered
+// by truster
+func (r *TrustEdgeRepo) ListBySubject(ctx context.Context, subject string) ([]*domain.TrustEdge, error) {
+	return r.list(trustPrefix(trustInPrefix, subject))
+}
+
+func (r *TrustEdgeRepo) list(prefix []byte) ([]*domain.TrustEdge, error) {
+	var edges []*domain.TrustEdge
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var edge domain.TrustEdge
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &edge)
+			}); err != nil {
+				return err
+			}
+			edges = append(edges, &edge)
+		}
+		return nil
+	})
+	return edges, err
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -27,6 +28,7 @@ type storageUser struct {
 	PublicKey    string    `json:"public_key"`
 	PrivateKey   string    `json:"private_key"`
 	IsActive     bool      `json:"is_active"`
+	Language     string    `json:"language,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -40,6 +42,7 @@ func toStorageUser(u *domain.User) *storageUser {
 		PublicKey:    u.PublicKey,
 		PrivateKey:   u.PrivateKey,
 		IsActive:     u.IsActive,
+		Language:     u.Language,
 		CreatedAt:    u.CreatedAt,
 		UpdatedAt:    u.UpdatedAt,
 	}
@@ -54,6 +57,7 @@ func toDomainUser(s *storageUser) *domain.User {
 		PublicKey:    s.PublicKey,
 		PrivateKey:   s.PrivateKey,
 		IsActive:     s.IsActive,
+		Language:     s.Language,
 		CreatedAt:    s.CreatedAt,
 		UpdatedAt:    s.UpdatedAt,
 	}
@@ -220,6 +224,35 @@ func (r *UserRepo) Delete(ctx context.Context, id string) error {
 	})
 }
 
+// List returns every user, ordered by username
+func (r *UserRepo) List(ctx context.Context) ([]*domain.User, error) {
+	var users []*domain.User
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte("user:id:")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var sUser storageUser
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &sUser)
+			}); err != nil {
+				return err
+			}
+			users = append(users, toDomainUser(&sUser))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return strings.ToLower(users[i].Username) < strings.ToLower(users[j].Username)
+	})
+	return users, nil
+}
+
 // ExistsByUsername checks if a user exists by username
 func (r *UserRepo) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	err := r.db.View(func(txn *badger.Txn) error {
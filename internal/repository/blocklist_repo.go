@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// BlocklistRepository persists the node's blocklist subscriptions
+type BlocklistRepository interface {
+	// Save creates or replaces a subscription
+	Save(ctx context.Context, sub *domain.BlocklistSubscription) error
+
+	// Get retrieves a subscription by name
+	Get(ctx context.Context, name string) (*domain.BlocklistSubscription, error)
+
+	// Delete removes a subscription
+	Delete(ctx context.Context, name string) error
+
+	// List retrieves every subscription, ordered by name
+	List(ctx context.Context) ([]*domain.BlocklistSubscription, error)
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// CachedArticleRepo decorates an ArticleRepository with a size-bounded LRU
+// for single-article lookups and recent lists. Every write that passes
+// through the decorator invalidates the affected entries, so incoming P2P
+// articles (which are persisted via Create/Update) never serve stale data.
+type CachedArticleRepo struct {
+	ArticleRepository
+
+	articles *lru.Cache[string, *domain.Article] // keyed by "id:<id>" and "cid:<cid>"
+
+	recentMu sync.RWMutex
+	recent   map[int][]*domain.Article // keyed by limit
+}
+
+// NewCachedArticleRepo wraps repo with an LRU holding up to size articles
+func NewCachedArticleRepo(repo ArticleRepository, size int) (*CachedArticleRepo, error) {
+	cache, err := lru.New[string, *domain.Article](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CachedArticleRepo{
+		ArticleRepository: repo,
+		articles:          cache,
+		recent:            make(map[int][]*domain.Article),
+	}, nil
+}
+
+// Create creates a new article and drops cached recent lists
+func (r *CachedArticleRepo) Create(ctx context.Context, article *domain.Article) error {
+	if err := r.ArticleRepository.Create(ctx, article); err != nil {
+		return err
+	}
+	r.Invalidate(article.ID, article.CID)
+	return nil
+}
+
+// CreateBatch creates several articles and drops cached recent lists
+func (r *CachedArticleRepo) CreateBatch(ctx context.Context, articles []*domain.Article) error {
+	if err := r.ArticleRepository.CreateBatch(ctx, articles); err != nil {
+		return err
+	}
+	for _, article := range articles {
+		r.Invalidate(article.ID, article.CID)
+	}
+	return nil
+}
+
+// GetByID retrieves an article by ID, serving from cache when possible
+func (r *CachedArticleRepo) GetByID(ctx context.Context, id string) (*domain.Article, error) {
+	if article, ok := r.articles.Get("id:" + id); ok {
+		return article.Clone(), nil
+	}
+
+	article, err := r.ArticleRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.store(article)
+	return article.Clone(), nil
+}
+
+// GetByCID retrieves an article by CID, serving from cache when possible
+func (r *CachedArticleRepo) GetByCID(ctx context.Context, cid string) (*domain.Article, error) {
+	if article, ok := r.articles.Get("cid:" + cid); ok && article.CID == cid {
+		return article.Clone(), nil
+	}
+
+	article, err := r.ArticleRepository.GetByCID(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	r.store(article)
+	return article.Clone(), nil
+}
+
+// Update updates an existing article and invalidates its cache entries
+func (r *CachedArticleRepo) Update(ctx context.Context, article *domain.Article) error {
+	if err := r.ArticleRepository.Update(ctx, article); err != nil {
+		return err
+	}
+	r.Invalidate(article.ID, article.CID)
+	return nil
+}
+
+// Delete deletes an article and invalidates its cache entries
+func (r *CachedArticleRepo) Delete(ctx context.Context, id string) error {
+	if err := r.ArticleRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.Invalidate(id, "")
+	return nil
+}
+
+// ListRecent retrieves recent articles, caching the result per limit
+func (r *CachedArticleRepo) ListRecent(ctx context.Context, limit int) ([]*domain.Article, error) {
+	r.recentMu.RLock()
+	cached, ok := r.recent[limit]
+	r.recentMu.RUnlock()
+	if ok {
+		return cloneArticles(cached), nil
+	}
+
+	articles, err := r.ArticleRepository.ListRecent(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	r.recentMu.Lock()
+	r.recent[limit] = cloneArticles(articles)
+	r.recentMu.Unlock()
+
+	return articles, nil
+}
+
+// Invalidate drops cached entries for an article and all recent lists.
+// Either argument may be empty. Call it when the underlying store is
+// modified without going through this decorator.
+func (r *CachedArticleRepo) Invalidate(id, cid string) {
+	if id != "" {
+		if old, ok := r.articles.Peek("id:" + id); ok {
+			r.articles.Remove("cid:" + old.CID)
+		}
+		r.articles.Remove("id:" + id)
+	}
+	if cid != "" {
+		r.articles.Remove("cid:" + cid)
+	}
+
+	r.recentMu.Lock()
+	r.recent = make(map[int][]*domain.Article)
+	r.recentMu.Unlock()
+}
+
+// Purge empties the cache
+func (r *CachedArticleRepo) Purge() {
+	r.articles.Purge()
+
+	r.recentMu.Lock()
+	r.recent = make(map[int][]*domain.Article)
+	r.recentMu.Unlock()
+}
+
+// store caches a private copy of article under both of its keys
+func (r *CachedArticleRepo) store(article *domain.Article) {
+	copied := article.Clone()
+	r.articles.Add("id:"+copied.ID, copied)
+	if copied.CID != "" {
+		r.articles.Add("cid:"+copied.CID, copied)
+	}
+}
+
+// cloneArticles copies every article in a list
+func cloneArticles(articles []*domain.Article) []*domain.Article {
+	out := make([]*domain.Article, len(articles))
+	for i, article := range articles {
+		out[i] = article.Clone()
+	}
+	return out
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// CircleRepository persists the circles this node knows, owned here or
+// with members here, and their key messages for every epoch, so members
+// can still read articles sealed before the latest rotation
+type CircleRepository interface {
+	// Save creates or replaces a circle
+	Save(ctx context.Context, circle *domain.Circle) error
+
+	// Get retrieves a circle by ID
+	Get(ctx context.Context, id string) (*domain.Circle, error)
+
+	// List retrieves every circle, ordered by ID
+	List(ctx context.Context) ([]*domain.Circle, error)
+
+	// SaveKeys stores the key message for its circle's epoch
+	SaveKeys(ctx context.Context, msg *domain.CircleKeyMessage) error
+
+	// GetKeys retrieves the key message for a circle's epoch
+	GetKeys(ctx context.Context, circleID string, epoch int) (*domain.CircleKeyMessage, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// CommentRepository persists article comments
+type CommentRepository interface {
+	// Create creates a new comment
+	Create(ctx context.Context, comment *domain.Comment) error
+
+	// GetByID retrieves a comment by ID
+	GetByID(ctx context.Context, id string) (*domain.Comment, error)
+
+	// Update updates an existing comment
+	Update(ctx context.Context, comment *domain.Comment) error
+
+	// Delete deletes a comment by ID
+	Delete(ctx context.Context, id string) error
+
+	// ListByArticle retrieves an article's comments oldest first with
+	// pagination; a limit of 0 returns every comment
+	ListByArticle(ctx context.Context, articleID string, page, limit int) ([]*domain.Comment, int, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// EndorsementRepository persists the latest endorsement record from each
+// endorser for each subject, revocations included, so an older record
+// replayed later can't undo a newer one
+type EndorsementRepository interface {
+	// Save creates or replaces the endorser's record for its subject
+	Save(ctx context.Context, endorsement *domain.Endorsement) error
+
+	// Get retrieves the endorser's record for subject
+	Get(ctx context.Context, subject, endorser string) (*domain.Endorsement, error)
+
+	// ListBySubject retrieves every record for subject, revoked ones included
+	ListBySubject(ctx context.Context, subject string) ([]*domain.Endorsement, error)
+
+	// List retrieves every record
+	List(ctx context.Context) ([]*domain.Endorsement, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// FilterListRepository persists the node's content filter lists
+type FilterListRepository interface {
+	// Save creates or replaces a list
+	Save(ctx context.Context, list *domain.FilterList) error
+
+	// Get retrieves a list by name
+	Get(ctx context.Context, name string) (*domain.FilterList, error)
+
+	// Delete removes a list
+	Delete(ctx context.Context, name string) error
+
+	// List retrieves every list, ordered by name
+	List(ctx context.Context) ([]*domain.FilterList, error)
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/tracing"
+)
+
+// OpObserver is told how long each repository call took and how it ended
+type OpObserver interface {
+	ObserveRepoOp(repo, op string, d time.Duration, err error)
+}
+
+// startOp opens a span for one repository call and returns the function
+// that ends it and reports the call to observer, when there is one
+func startOp(ctx context.Context, observer OpObserver, repo, op string) (context.Context, func(error)) {
+	ctx, span := tracing.Start(ctx, "repo."+repo+"."+op)
+	start := time.Now()
+	return ctx, func(err error) {
+		if observer != nil {
+			observer.ObserveRepoOp(repo, op, time.Since(start), err)
+		}
+		tracing.End(span, err)
+	}
+}
+
+// InstrumentedArticleRepo decorates an ArticleRepository, timing and tracing every call
+type InstrumentedArticleRepo struct {
+	repo     ArticleRepository
+	observer OpObserver
+}
+
+// NewInstrumentedArticleRepo reports the latency of repo's calls to
+// observer, which may be nil when only spans are wanted
+func NewInstrumentedArticleRepo(repo ArticleRepository, observer OpObserver) *InstrumentedArticleRepo {
+	return &InstrumentedArticleRepo{repo: repo, observer: observer}
+}
+
+func (r *InstrumentedArticleRepo) start(ctx context.Context, op string) (context.Context, func(error)) {
+	return startOp(ctx, r.observer, "article", op)
+}
+
+// Create creates a new article
+func (r *InstrumentedArticleRepo) Create(ctx context.Context, article *domain.Article) error {
+	ctx, done := r.start(ctx, "create")
+	err := r.repo.Create(ctx, article)
+	done(err)
+	return err
+}
+
+// CreateBatch creates several articles atomically
+func (r *InstrumentedArticleRepo) CreateBatch(ctx context.Context, articles []*domain.Article) error {
+	ctx, done := r.start(ctx, "create_batch")
+	err := r.repo.CreateBatch(ctx, articles)
+	done(err)
+	return err
+}
+
+// GetByID retrieves an article by ID
+func (r *InstrumentedArticleRepo) GetByID(ctx context.Context, id string) (*domain.Article, error) {
+	ctx, done := r.start(ctx, "get_by_id")
+	result, err := r.repo.GetByID(ctx, id)
+	done(err)
+	return result, err
+}
+
+// GetByCID retrieves an article by CID
+func (r *InstrumentedArticleRepo) GetByCID(ctx context.Context, cid string) (*domain.Article, error) {
+	ctx, done := r.start(ctx, "get_by_cid")
+	result, err := r.repo.GetByCID(ctx, cid)
+	done(err)
+	return result, err
+}
+
+// Update updates an existing article
+func (r *InstrumentedArticleRepo) Update(ctx context.Context, article *domain.Article) error {
+	ctx, done := r.start(ctx, "update")
+	err := r.repo.Update(ctx, article)
+	done(err)
+	return err
+}
+
+// Delete deletes an article by ID
+func (r *InstrumentedArticleRepo) Delete(ctx context.Context, id string) error {
+	ctx, done := r.start(ctx, "delete")
+	err := r.repo.Delete(ctx, id)
+	done(err)
+	return err
+}
+
+// List retrieves articles with pagination and filtering
+func (r *InstrumentedArticleRepo) List(ctx context.Context, filter *domain.ArticleListFilter) ([]*domain.Article, int, error) {
+	ctx, done := r.start(ctx, "list")
+	result, total, err := r.repo.List(ctx, filter)
+	done(err)
+	return result, total, err
+}
+
+// ListRecent retrieves recent articles for a feed
+func (r *InstrumentedArticleRepo) ListRecent(ctx context.Context, limit int) ([]*domain.Article, error) {
+	ctx, done := r.start(ctx, "list_recent")
+	result, err := r.repo.ListRecent(ctx, limit)
+	done(err)
+	return result, err
+}
+
+// ListByAuthor retrieves articles by author with pagination
+func (r *InstrumentedArticleRepo) ListByAuthor(ctx context.Context, author string, page, limit int) ([]*domain.Article, int, error) {
+	ctx, done := r.start(ctx, "list_by_author")
+	result, total, err := r.repo.ListByAuthor(ctx, author, page, limit)
+	done(err)
+	return result, total, err
+}
+
+// ListTags counts the articles under each tag
+func (r *InstrumentedArticleRepo) ListTags(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	ctx, done := r.start(ctx, "list_tags")
+	result, err := r.repo.ListTags(ctx, limit)
+	done(err)
+	return result, err
+}
+
+// GetByIDs retrieves articles by a list of IDs
+func (r *InstrumentedArticleRepo) GetByIDs(ctx context.Context, ids []string) ([]*domain.Article, error) {
+	ctx, done := r.start(ctx, "get_by_ids")
+	result, err := r.repo.GetByIDs(ctx, ids)
+	done(err)
+	return result, err
+}
+
+// InstrumentedUserRepo decorates a UserRepository, timing and tracing every call
+type InstrumentedUserRepo struct {
+	repo     UserRepository
+	observer OpObserver
+}
+
+// NewInstrumentedUserRepo reports the latency of repo's calls to
+// observer, which may be nil when only spans are wanted
+func NewInstrumentedUserRepo(repo UserRepository, observer OpObserver) *InstrumentedUserRepo {
+	return &InstrumentedUserRepo{repo: repo, observer: observer}
+}
+
+func (r *InstrumentedUserRepo) start(ctx context.Context, op string) (context.Context, func(error)) {
+	return startOp(ctx, r.observer, "user", op)
+}
+
+// Create creates a new user
+func (r *InstrumentedUserRepo) Create(ctx context.Context, user *domain.User) error {
+	ctx, done := r.start(ctx, "create")
+	err := r.repo.Create(ctx, user)
+	done(err)
+	return err
+}
+
+// GetByID retrieves a user by ID
+func (r *InstrumentedUserRepo) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	ctx, done := r.start(ctx, "get_by_id")
+	result, err := r.repo.GetByID(ctx, id)
+	done(err)
+	return result, err
+}
+
+// GetByUsername retrieves a user by username
+func (r *InstrumentedUserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	ctx, done := r.start(ctx, "get_by_username")
+	result, err := r.repo.GetByUsername(ctx, username)
+	done(err)
+	return result, err
+}
+
+// GetByEmail retrieves a user by email
+func (r *InstrumentedUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ctx, done := r.start(ctx, "get_by_email")
+	result, err := r.repo.GetByEmail(ctx, email)
+	done(err)
+	return result, err
+}
+
+// Update updates an existing user
+func (r *InstrumentedUserRepo) Update(ctx context.Context, user *domain.User) error {
+	ctx, done := r.start(ctx, "update")
+	err := r.repo.Update(ctx, user)
+	done(err)
+	return err
+}
+
+// Delete deletes a user by ID
+func (r *InstrumentedUserRepo) Delete(ctx context.Context, id string) error {
+	ctx, done := r.start(ctx, "delete")
+	err := r.repo.Delete(ctx, id)
+	done(err)
+	return err
+}
+
+// List returns every user
+func (r *InstrumentedUserRepo) List(ctx context.Context) ([]*domain.User, error) {
+	ctx, done := r.start(ctx, "list")
+	result, err := r.repo.List(ctx)
+	done(err)
+	return result, err
+}
+
+// ExistsByUsername checks if a user exists by username
+func (r *InstrumentedUserRepo) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	ctx, done := r.start(ctx, "exists_by_username")
+	result, err := r.repo.ExistsByUsername(ctx, username)
+	done(err)
+	return result, err
+}
+
+// ExistsByEmail checks if a user exists by email
+func (r *InstrumentedUserRepo) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	ctx, done := r.start(ctx, "exists_by_email")
+	result, err := r.repo.ExistsByEmail(ctx, email)
+	done(err)
+	return result, err
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// MediaRepository persists metadata about uploaded audio and video
+type MediaRepository interface {
+	// Save creates or replaces the record for a CID
+	Save(ctx context.Context, media *domain.Media) error
+
+	// Get retrieves the record for a CID
+	Get(ctx context.Context, cid string) (*domain.Media, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ModerationCaseRepository persists moderation cases. At most one case per
+// article is active (open or reviewing) at a time.
+type ModerationCaseRepository interface {
+	// Create adds a case, making it the article's active case
+	Create(ctx context.Context, c *domain.ModerationCase) error
+
+	// GetByID retrieves a case by ID
+	GetByID(ctx context.Context, id string) (*domain.ModerationCase, error)
+
+	// GetActive retrieves the article's open or reviewing case
+	GetActive(ctx context.Context, articleID string) (*domain.ModerationCase, error)
+
+	// Update updates an existing case, retiring it as the article's active
+	// case once it is closed
+	Update(ctx context.Context, c *domain.ModerationCase) error
+
+	// List retrieves cases newest first with pagination and filtering
+	List(ctx context.Context, filter *domain.CaseListFilter) ([]*domain.ModerationCase, int, error)
+}
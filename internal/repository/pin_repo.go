@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// PinRepository persists the pin ledger
+type PinRepository interface {
+	// Save creates or replaces the record for a CID
+	Save(ctx context.Context, pin *domain.PinRecord) error
+
+	// Get retrieves the record for a CID
+	Get(ctx context.Context, cid string) (*domain.PinRecord, error)
+
+	// Delete removes the record for a CID
+	Delete(ctx context.Context, cid string) error
+
+	// DeleteByRef removes every record owned by ref and returns their CIDs
+	DeleteByRef(ctx context.Context, ref string) ([]string, error)
+
+	// List retrieves all records, optionally filtered by status
+	List(ctx context.Context, status string) ([]*domain.PinRecord, error)
+
+	// ListDue retrieves unconfirmed records whose next attempt is at or before now
+	ListDue(ctx context.Context, now time.Time) ([]*domain.PinRecord, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ReportRepository persists the moderation queue
+type ReportRepository interface {
+	// Create adds a report to the queue
+	Create(ctx context.Context, report *domain.Report) error
+
+	// GetByID retrieves a report by ID
+	GetByID(ctx context.Context, id string) (*domain.Report, error)
+
+	// GetOpen retrieves the open report a reporter filed against an article
+	GetOpen(ctx context.Context, articleID, reporter string) (*domain.Report, error)
+
+	// Update updates an existing report
+	Update(ctx context.Context, report *domain.Report) error
+
+	// List retrieves reports newest first with pagination and filtering
+	List(ctx context.Context, filter *domain.ReportListFilter) ([]*domain.Report, int, error)
+}
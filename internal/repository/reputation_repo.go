@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ReputationRepository persists reputation scores so trust accumulates
+// across restarts
+type ReputationRepository interface {
+	// List retrieves every stored score
+	List(ctx context.Context) ([]*domain.ReputationScore, error)
+
+	// Save creates or replaces the given scores in one transaction
+	Save(ctx context.Context, scores []*domain.ReputationScore) error
+
+	// Replace drops every stored score and saves scores in their place
+	Replace(ctx context.Context, scores []*domain.ReputationScore) error
+}
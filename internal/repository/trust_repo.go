@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// TrustEdgeRepository persists the trust graph: each user's trusted and
+// blocked marks on other identities
+type TrustEdgeRepository interface {
+	// Save creates or replaces the truster's edge to its subject
+	Save(ctx context.Context, edge *domain.TrustEdge) error
+
+	// Get retrieves the truster's edge to subject
+	Get(ctx context.Context, truster, subject string) (*domain.TrustEdge, error)
+
+	// Delete removes the truster's edge to subject
+	Delete(ctx context.Context, truster, subject string) error
+
+	// ListByTruster retrieves the edges a user made, ordered by subject
+	ListByTruster(ctx context.Context, truster string) ([]*domain.TrustEdge, error)
+
+	// ListBySubject retrieves the edges other users made to subject,
+	// ordered by truster
+	ListBySubject(ctx context.Context, subject string) ([]*domain.TrustEdge, error)
+}
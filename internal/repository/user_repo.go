@@ -26,6 +26,9 @@ type UserRepository interface {
 	// Delete deletes a user by ID
 	Delete(ctx context.Context, id string) error
 
+	// List returns every user, ordered by username
+	List(ctx context.Context) ([]*domain.User, error)
+
 	// ExistsByUsername checks if a user exists by username
 	ExistsByUsername(ctx context.Context, username string) (bool, error)
 
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/mapping"
+	bleveSearch "github.com/blevesearch/bleve/v2/search"
 	"github.com/blevesearch/bleve/v2/search/query"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
@@ -18,11 +21,33 @@ import (
 
 // BleveIndex implements the Index interface using Bleve
 type BleveIndex struct {
-	index  bleve.Index
-	mu     sync.RWMutex // Protects concurrent access to the index
-	logger *logger.Logger
+	index   bleve.Index
+	path    string
+	mu      sync.RWMutex // Protects concurrent access to the index
+	signals SignalProvider
+	logger  *logger.Logger
+
+	// Set while Rebuild runs: live writes also go to the new index and
+	// their IDs are recorded so the repository snapshot can't overwrite them
+	rebuilding bleve.Index
+	touched    map[string]bool
+
+	// Set when the index on disk was built with an older mapping
+	outdated bool
+
+	// Held by Optimize for the whole merge, which runs without mu so writes
+	// can continue; Rebuild takes it before swapping the index out
+	merging sync.Mutex
 }
 
+// mappingVersion is bumped whenever buildIndexMapping changes in a way that
+// existing indexes must be rebuilt for. Indexes created before versions were
+// recorded have none and are treated as outdated.
+const mappingVersion = "2"
+
+// mappingVersionKey stores an index's mapping version in its internal storage
+var mappingVersionKey = []byte("mapping_version")
+
 // NewBleveIndex creates a new Bleve search index
 func NewBleveIndex(logger *logger.Logger) *BleveIndex {
 	return &BleveIndex{
@@ -30,6 +55,14 @@ func NewBleveIndex(logger *logger.Logger) *BleveIndex {
 	}
 }
 
+// SetSignalProvider sets the source of vote and trust signals stored with
+// each document. Documents indexed without a provider sort last.
+func (b *BleveIndex) SetSignalProvider(provider SignalProvider) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.signals = provider
+}
+
 // Open opens or creates the search index
 func (b *BleveIndex) Open(indexPath string) error {
 	// Ensure directory exists
@@ -39,17 +72,26 @@ func (b *BleveIndex) Open(indexPath string) error {
 	}
 
 	var err error
+	b.path = indexPath
 
 	// Try to open existing index
 	b.index, err = bleve.Open(indexPath)
 	if err == nil {
+		version, err := b.index.GetInternal(mappingVersionKey)
+		if err != nil {
+			return fmt.Errorf("failed to read search index mapping version: %w", err)
+		}
+		if string(version) != mappingVersion {
+			b.outdated = true
+			b.logger.Warn("Search index was built with an older mapping and needs a rebuild",
+				"path", indexPath, "version", string(version), "current", mappingVersion)
+		}
 		b.logger.Info("Opened existing search index", "path", indexPath)
 		return nil
 	}
 
 	// Index doesn't exist, create new one
-	indexMapping := b.buildIndexMapping()
-	b.index, err = bleve.New(indexPath, indexMapping)
+	b.index, err = b.newIndex(indexPath)
 	if err != nil {
 		return fmt.Errorf("failed to create search index: %w", err)
 	}
@@ -58,6 +100,27 @@ func (b *BleveIndex) Open(indexPath string) error {
 	return nil
 }
 
+// newIndex creates an index at path with the current mapping
+func (b *BleveIndex) newIndex(path string) (bleve.Index, error) {
+	index, err := bleve.New(path, b.buildIndexMapping())
+	if err != nil {
+		return nil, err
+	}
+	if err := index.SetInternal(mappingVersionKey, []byte(mappingVersion)); err != nil {
+		index.Close()
+		return nil, err
+	}
+	return index, nil
+}
+
+// Outdated reports whether the index was built with an older mapping. Until
+// it is rebuilt, fuzzy, prefix and wildcard searches miss its documents.
+func (b *BleveIndex) Outdated() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.outdated
+}
+
 // buildIndexMapping builds the index mapping for articles
 func (b *BleveIndex) buildIndexMapping() mapping.IndexMapping {
 	// Create a document mapping
@@ -68,14 +131,14 @@ func (b *BleveIndex) buildIndexMapping() mapping.IndexMapping {
 	titleFieldMapping.Analyzer = "en"
 	titleFieldMapping.Store = true
 	titleFieldMapping.Index = true
-	articleMapping.AddFieldMappingsAt("title", titleFieldMapping)
+	articleMapping.AddFieldMappingsAt("title", titleFieldMapping, termsFieldMapping("title"))
 
-	// Body field - analyzed
+	// Body field - analyzed, stored for highlighting
 	bodyFieldMapping := bleve.NewTextFieldMapping()
 	bodyFieldMapping.Analyzer = "en"
-	bodyFieldMapping.Store = false
+	bodyFieldMapping.Store = true
 	bodyFieldMapping.Index = true
-	articleMapping.AddFieldMappingsAt("body", bodyFieldMapping)
+	articleMapping.AddFieldMappingsAt("body", bodyFieldMapping, termsFieldMapping("body"))
 
 	// Author field - keyword (not analyzed)
 	authorFieldMapping := bleve.NewKeywordFieldMapping()
@@ -94,7 +157,14 @@ func (b *BleveIndex) buildIndexMapping() mapping.IndexMapping {
 	tagsFieldMapping.Analyzer = "en"
 	tagsFieldMapping.Store = true
 	tagsFieldMapping.Index = true
-	articleMapping.AddFieldMappingsAt("tags", tagsFieldMapping)
+	articleMapping.AddFieldMappingsAt("tags", tagsFieldMapping, termsFieldMapping("tags"))
+
+	// Labels field - keyword, from filter lists and the classifier
+	labelsFieldMapping := bleve.NewKeywordFieldMapping()
+	labelsFieldMapping.Store = true
+	labelsFieldMapping.Index = true
+	labelsFieldMapping.IncludeInAll = false
+	articleMapping.AddFieldMappingsAt("labels", labelsFieldMapping)
 
 	// Timestamp field - datetime
 	timestampFieldMapping := bleve.NewDateTimeFieldMapping()
@@ -102,14 +172,77 @@ func (b *BleveIndex) buildIndexMapping() mapping.IndexMapping {
 	timestampFieldMapping.Index = true
 	articleMapping.AddFieldMappingsAt("timestamp", timestampFieldMapping)
 
+	// Ranking signals - numeric, used for sorting
+	votesFieldMapping := bleve.NewNumericFieldMapping()
+	votesFieldMapping.IncludeInAll = false
+	articleMapping.AddFieldMappingsAt("votes", votesFieldMapping)
+
+	trustFieldMapping := bleve.NewNumericFieldMapping()
+	trustFieldMapping.IncludeInAll = false
+	articleMapping.AddFieldMappingsAt("trust_score", trustFieldMapping)
+
+	// Document type - keyword, used to filter articles from comments
+	articleMapping.AddFieldMappingsAt("type", typeFieldMapping())
+
 	// Create index mapping
 	indexMapping := bleve.NewIndexMapping()
-	indexMapping.AddDocumentMapping("article", articleMapping)
+	indexMapping.TypeField = "type"
+	indexMapping.AddDocumentMapping(DocTypeArticle, articleMapping)
+	indexMapping.AddDocumentMapping(DocTypeComment, buildCommentMapping())
 	indexMapping.DefaultMapping = articleMapping
 
 	return indexMapping
 }
 
+// buildCommentMapping builds the document mapping for comments. Every
+// field is stored so hits can be returned without a repository lookup.
+func buildCommentMapping() *mapping.DocumentMapping {
+	commentMapping := bleve.NewDocumentMapping()
+	commentMapping.AddFieldMappingsAt("type", typeFieldMapping())
+
+	for _, field := range []string{"article_id", "parent_id", "author"} {
+		keywordMapping := bleve.NewKeywordFieldMapping()
+		keywordMapping.Store = true
+		keywordMapping.Index = true
+		commentMapping.AddFieldMappingsAt(field, keywordMapping)
+	}
+
+	bodyFieldMapping := bleve.NewTextFieldMapping()
+	bodyFieldMapping.Analyzer = "en"
+	bodyFieldMapping.Store = true
+	bodyFieldMapping.Index = true
+	commentMapping.AddFieldMappingsAt("body", bodyFieldMapping, termsFieldMapping("body"))
+
+	timestampFieldMapping := bleve.NewDateTimeFieldMapping()
+	timestampFieldMapping.Store = true
+	timestampFieldMapping.Index = true
+	commentMapping.AddFieldMappingsAt("timestamp", timestampFieldMapping)
+
+	return commentMapping
+}
+
+// typeFieldMapping indexes the document type as a single keyword
+func typeFieldMapping() *mapping.FieldMapping {
+	typeMapping := bleve.NewKeywordFieldMapping()
+	typeMapping.Store = true
+	typeMapping.Index = true
+	typeMapping.IncludeInAll = false
+	return typeMapping
+}
+
+// termsFieldMapping indexes a text field a second time without stemming
+// (as "<field>_terms") so fuzzy, prefix and wildcard queries can match the
+// words users actually type.
+func termsFieldMapping(field string) *mapping.FieldMapping {
+	termsMapping := bleve.NewTextFieldMapping()
+	termsMapping.Name = field + "_terms"
+	termsMapping.Analyzer = "standard"
+	termsMapping.Store = false
+	termsMapping.Index = true
+	termsMapping.IncludeInAll = false
+	return termsMapping
+}
+
 // Close closes the search index
 func (b *BleveIndex) Close() error {
 	if b.index != nil {
@@ -126,17 +259,110 @@ func (b *BleveIndex) IndexArticle(ctx context.Context, article *domain.Article)
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	doc := ArticleToDocument(article)
+	doc := b.document(article)
 
 	if err := b.index.Index(article.ID, doc); err != nil {
 		b.logger.Error("Failed to index article", "article_id", article.ID, "error", err)
 		return fmt.Errorf("failed to index article: %w", err)
 	}
 
+	if b.rebuilding != nil {
+		b.touched[article.ID] = true
+		if err := b.rebuilding.Index(article.ID, doc); err != nil {
+			b.logger.Warn("Failed to index article into rebuild", "article_id", article.ID, "error", err)
+		}
+	}
+
 	b.logger.Debug("Indexed article", "article_id", article.ID)
 	return nil
 }
 
+// IndexArticles indexes several articles in one Bleve batch
+func (b *BleveIndex) IndexArticles(ctx context.Context, articles []*domain.Article) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch := b.index.NewBatch()
+	for _, article := range articles {
+		if err := batch.Index(article.ID, b.document(article)); err != nil {
+			return fmt.Errorf("failed to index article %s: %w", article.ID, err)
+		}
+	}
+	if err := b.index.Batch(batch); err != nil {
+		b.logger.Error("Failed to index article batch", "articles", len(articles), "error", err)
+		return fmt.Errorf("failed to index articles: %w", err)
+	}
+
+	if b.rebuilding != nil {
+		for _, article := range articles {
+			b.touched[article.ID] = true
+			if err := b.rebuilding.Index(article.ID, b.document(article)); err != nil {
+				b.logger.Warn("Failed to index article into rebuild", "article_id", article.ID, "error", err)
+			}
+		}
+	}
+
+	b.logger.Debug("Indexed article batch", "articles", len(articles))
+	return nil
+}
+
+// IndexComment indexes or replaces a comment
+func (b *BleveIndex) IndexComment(ctx context.Context, comment *domain.Comment) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	docID := commentIDPrefix + comment.ID
+	doc := CommentToDocument(comment)
+
+	if err := b.index.Index(docID, doc); err != nil {
+		b.logger.Error("Failed to index comment", "comment_id", comment.ID, "error", err)
+		return fmt.Errorf("failed to index comment: %w", err)
+	}
+
+	if b.rebuilding != nil {
+		b.touched[docID] = true
+		if err := b.rebuilding.Index(docID, doc); err != nil {
+			b.logger.Warn("Failed to index comment into rebuild", "comment_id", comment.ID, "error", err)
+		}
+	}
+
+	b.logger.Debug("Indexed comment", "comment_id", comment.ID, "article_id", comment.ArticleID)
+	return nil
+}
+
+// DeleteComment removes a comment from the index
+func (b *BleveIndex) DeleteComment(ctx context.Context, commentID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	docID := commentIDPrefix + commentID
+	if err := b.index.Delete(docID); err != nil {
+		b.logger.Error("Failed to delete comment from index", "comment_id", commentID, "error", err)
+		return fmt.Errorf("failed to delete comment from index: %w", err)
+	}
+
+	if b.rebuilding != nil {
+		b.touched[docID] = true
+		if err := b.rebuilding.Delete(docID); err != nil {
+			b.logger.Warn("Failed to delete comment from rebuild", "comment_id", commentID, "error", err)
+		}
+	}
+
+	b.logger.Debug("Deleted comment from index", "comment_id", commentID)
+	return nil
+}
+
+// document converts an article and attaches ranking signals. Callers hold b.mu.
+func (b *BleveIndex) document(article *domain.Article) *SearchDocument {
+	doc := ArticleToDocument(article)
+	if b.signals != nil {
+		signals := b.signals.ArticleSignals(article)
+		doc.Votes = signals.Votes
+		doc.TrustScore = signals.TrustScore
+	}
+	return doc
+}
+
 // UpdateArticle updates an indexed article
 func (b *BleveIndex) UpdateArticle(ctx context.Context, article *domain.Article) error {
 	// In Bleve, update is the same as index (it overwrites)
@@ -153,6 +379,13 @@ func (b *BleveIndex) DeleteArticle(ctx context.Context, articleID string) error
 		return fmt.Errorf("failed to delete from index: %w", err)
 	}
 
+	if b.rebuilding != nil {
+		b.touched[articleID] = true
+		if err := b.rebuilding.Delete(articleID); err != nil {
+			b.logger.Warn("Failed to delete article from rebuild", "article_id", articleID, "error", err)
+		}
+	}
+
 	b.logger.Debug("Deleted article from index", "article_id", articleID)
 	return nil
 }
@@ -183,6 +416,19 @@ func (b *BleveIndex) Search(ctx context.Context, query *SearchQuery) (*SearchRes
 
 	searchRequest.From = (query.Page - 1) * query.Limit
 	searchRequest.Size = query.Limit
+	searchRequest.SortBy(sortFields(query.SortBy))
+
+	// Comment hits are built from stored fields
+	if query.DocType == DocTypeComment || query.DocType == DocTypeAll {
+		searchRequest.Fields = []string{"article_id", "parent_id", "author", "body", "timestamp"}
+	}
+
+	// Highlight matched terms in title and body for free-text queries
+	if query.Query != "" {
+		searchRequest.Highlight = bleve.NewHighlightWithStyle("html")
+		searchRequest.Highlight.AddField("title")
+		searchRequest.Highlight.AddField("body")
+	}
 
 	// Execute search
 	searchResults, err := b.index.Search(searchRequest)
@@ -193,10 +439,22 @@ func (b *BleveIndex) Search(ctx context.Context, query *SearchQuery) (*SearchRes
 
 	queryTime := time.Since(startTime).Milliseconds()
 
-	// Extract document IDs from search results
+	// Extract document IDs and highlighted fragments from search results
 	ids := make([]string, 0, len(searchResults.Hits))
+	highlights := make(map[string]*HitHighlight)
+	var comments []*CommentHit
 	for _, hit := range searchResults.Hits {
+		if strings.HasPrefix(hit.ID, commentIDPrefix) {
+			comments = append(comments, commentHit(hit))
+			continue
+		}
 		ids = append(ids, hit.ID)
+		if len(hit.Fragments) > 0 {
+			highlights[hit.ID] = &HitHighlight{
+				Title: hit.Fragments["title"],
+				Body:  hit.Fragments["body"],
+			}
+		}
 	}
 
 	totalPages := int(searchResults.Total) / query.Limit
@@ -207,6 +465,8 @@ func (b *BleveIndex) Search(ctx context.Context, query *SearchQuery) (*SearchRes
 	result := &SearchResult{
 		Articles:   make([]*domain.Article, 0),
 		IDs:        ids, // Populate IDs for the search service to fetch full articles
+		Highlights: highlights,
+		Comments:   comments,
 		Total:      int(searchResults.Total),
 		Page:       query.Page,
 		Limit:      query.Limit,
@@ -230,8 +490,7 @@ func (b *BleveIndex) buildSearchQuery(searchQuery *SearchQuery) query.Query {
 
 	// Full-text query on title and body
 	if searchQuery.Query != "" {
-		matchQuery := bleve.NewMatchQuery(searchQuery.Query)
-		queries = append(queries, matchQuery)
+		queries = append(queries, b.buildTextQuery(searchQuery))
 	}
 
 	// Author filter
@@ -262,7 +521,43 @@ func (b *BleveIndex) buildSearchQuery(searchQuery *SearchQuery) query.Query {
 		queries = append(queries, dateQuery)
 	}
 
-	// Combine queries
+	// Label filters
+	for _, label := range searchQuery.Labels {
+		labelQuery := bleve.NewTermQuery(label)
+		labelQuery.SetField("labels")
+		queries = append(queries, labelQuery)
+	}
+	var excluded []query.Query
+	for _, label := range searchQuery.ExcludeLabels {
+		labelQuery := bleve.NewTermQuery(label)
+		labelQuery.SetField("labels")
+		excluded = append(excluded, labelQuery)
+	}
+
+	// Document type filter
+	commentType := bleve.NewTermQuery(DocTypeComment)
+	commentType.SetField("type")
+	switch searchQuery.DocType {
+	case DocTypeAll:
+	case DocTypeComment:
+		queries = append(queries, commentType)
+	default:
+		// Articles only; older documents have no type field, so exclude
+		// comments rather than requiring type=article
+		excluded = append(excluded, commentType)
+	}
+
+	if len(excluded) == 0 {
+		return combineQueries(queries)
+	}
+	boolQuery := bleve.NewBooleanQuery()
+	boolQuery.AddMust(combineQueries(queries))
+	boolQuery.AddMustNot(excluded...)
+	return boolQuery
+}
+
+// combineQueries ANDs queries together, matching everything when empty
+func combineQueries(queries []query.Query) query.Query {
 	if len(queries) == 0 {
 		// No filters, match all
 		return bleve.NewMatchAllQuery()
@@ -275,6 +570,186 @@ func (b *BleveIndex) buildSearchQuery(searchQuery *SearchQuery) query.Query {
 	}
 }
 
+// commentHit builds a comment result from a hit's stored fields
+func commentHit(hit *bleveSearch.DocumentMatch) *CommentHit {
+	field := func(name string) string {
+		value, _ := hit.Fields[name].(string)
+		return value
+	}
+
+	result := &CommentHit{
+		ID:         strings.TrimPrefix(hit.ID, commentIDPrefix),
+		ArticleID:  field("article_id"),
+		ParentID:   field("parent_id"),
+		Author:     field("author"),
+		Body:       field("body"),
+		Highlights: hit.Fragments["body"],
+	}
+	if ts, err := time.Parse(time.RFC3339, field("timestamp")); err == nil {
+		result.Timestamp = ts
+	}
+	return result
+}
+
+// sortFields maps a sort order to Bleve sort fields. Signal and date
+// orders fall back to relevance, then newest, to break ties.
+func sortFields(order SortOrder) []string {
+	switch order {
+	case SortNewest:
+		return []string{"-timestamp", "-_score"}
+	case SortOldest:
+		return []string{"timestamp", "-_score"}
+	case SortMostVoted:
+		return []string{"-votes", "-_score", "-timestamp"}
+	case SortTrust:
+		return []string{"-trust_score", "-_score", "-timestamp"}
+	default:
+		return []string{"-_score", "-timestamp"}
+	}
+}
+
+// termFields are the unstemmed fields searched by fuzzy, prefix and wildcard queries
+var termFields = []string{"title_terms", "body_terms", "tags_terms"}
+
+// buildTextQuery builds the free-text part of a search. Words containing
+// wildcards become wildcard queries; otherwise the stemmed match query is
+// OR'ed with fuzzy and/or per-word prefix queries on the unstemmed fields.
+func (b *BleveIndex) buildTextQuery(searchQuery *SearchQuery) query.Query {
+	words := strings.Fields(strings.ToLower(searchQuery.Query))
+
+	if strings.ContainsAny(searchQuery.Query, "*?") {
+		perWord := make([]query.Query, 0, len(words))
+		for _, word := range words {
+			perWord = append(perWord, anyField(func(field string) query.Query {
+				if strings.ContainsAny(word, "*?") {
+					q := bleve.NewWildcardQuery(word)
+					q.SetField(field)
+					return q
+				}
+				q := bleve.NewMatchQuery(word)
+				q.SetField(field)
+				return q
+			}))
+		}
+		return bleve.NewConjunctionQuery(perWord...)
+	}
+
+	matchQuery := bleve.NewMatchQuery(searchQuery.Query)
+	if searchQuery.Fuzziness == 0 && !searchQuery.Prefix {
+		return matchQuery
+	}
+
+	alternatives := []query.Query{matchQuery}
+	if searchQuery.Fuzziness > 0 {
+		alternatives = append(alternatives, anyField(func(field string) query.Query {
+			q := bleve.NewMatchQuery(searchQuery.Query)
+			q.SetField(field)
+			q.SetFuzziness(searchQuery.Fuzziness)
+			return q
+		}))
+	}
+	if searchQuery.Prefix {
+		perWord := make([]query.Query, 0, len(words))
+		for _, word := range words {
+			perWord = append(perWord, anyField(func(field string) query.Query {
+				q := bleve.NewPrefixQuery(word)
+				q.SetField(field)
+				return q
+			}))
+		}
+		alternatives = append(alternatives, bleve.NewConjunctionQuery(perWord...))
+	}
+	return bleve.NewDisjunctionQuery(alternatives...)
+}
+
+// anyField ORs the query built by build across the unstemmed text fields
+func anyField(build func(field string) query.Query) query.Query {
+	fieldQueries := make([]query.Query, 0, len(termFields))
+	for _, field := range termFields {
+		fieldQueries = append(fieldQueries, build(field))
+	}
+	return bleve.NewDisjunctionQuery(fieldQueries...)
+}
+
+// Suggest returns the most common title/tag terms starting with prefix,
+// followed by titles of articles containing a word with that prefix
+func (b *BleveIndex) Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || limit < 1 {
+		return []Suggestion{}, nil
+	}
+
+	// Term completions from the unstemmed title and tag dictionaries
+	counts := make(map[string]*Suggestion)
+	for _, source := range []struct{ field, kind string }{
+		{"title_terms", SuggestionTerm},
+		{"tags_terms", SuggestionTag},
+	} {
+		dict, err := b.index.FieldDictPrefix(source.field, []byte(prefix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read term dictionary: %w", err)
+		}
+		for {
+			entry, err := dict.Next()
+			if err != nil {
+				dict.Close()
+				return nil, fmt.Errorf("failed to read term dictionary: %w", err)
+			}
+			if entry == nil {
+				break
+			}
+			if existing, ok := counts[entry.Term]; ok {
+				existing.Count += int(entry.Count)
+				if source.kind == SuggestionTag {
+					existing.Kind = SuggestionTag
+				}
+				continue
+			}
+			counts[entry.Term] = &Suggestion{Text: entry.Term, Kind: source.kind, Count: int(entry.Count)}
+		}
+		dict.Close()
+	}
+
+	terms := make([]Suggestion, 0, len(counts))
+	for _, suggestion := range counts {
+		terms = append(terms, *suggestion)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Count != terms[j].Count {
+			return terms[i].Count > terms[j].Count
+		}
+		return terms[i].Text < terms[j].Text
+	})
+	if len(terms) > limit {
+		terms = terms[:limit]
+	}
+
+	// Title completions
+	titleQuery := bleve.NewPrefixQuery(prefix)
+	titleQuery.SetField("title_terms")
+	request := bleve.NewSearchRequestOptions(titleQuery, limit, 0, false)
+	request.Fields = []string{"title"}
+
+	results, err := b.index.SearchInContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("suggest search failed: %w", err)
+	}
+
+	suggestions := terms
+	for _, hit := range results.Hits {
+		title, ok := hit.Fields["title"].(string)
+		if !ok {
+			continue
+		}
+		suggestions = append(suggestions, Suggestion{Text: title, Kind: SuggestionTitle, ID: hit.ID})
+	}
+
+	return suggestions, nil
+}
+
 // Count returns the number of documents in the index
 func (b *BleveIndex) Count() (uint64, error) {
 	count, err := b.index.DocCount()
@@ -2,13 +2,27 @@ package search
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
 )
 
+// Document types stored in the index
+const (
+	DocTypeArticle = "article"
+	DocTypeComment = "comment"
+	DocTypeAll     = "all" // query-only: match every type
+)
+
+// commentIDPrefix namespaces comment document IDs so they can't collide with article IDs
+const commentIDPrefix = "comment:"
+
 // SearchDocument represents a document in the search index
 type SearchDocument struct {
+	Type      string    `json:"type"`
 	ID        string    `json:"id"`
 	Title     string    `json:"title"`
 	Body      string    `json:"body"`
@@ -17,6 +31,65 @@ type SearchDocument struct {
 	Category  string    `json:"category"`
 	Timestamp time.Time `json:"timestamp"`
 	CID       string    `json:"cid"`
+	Labels    []string  `json:"labels"` // filter list labels and confident classifications
+
+	// Ranking signals, filled by the index's SignalProvider
+	Votes      float64 `json:"votes"` // reputation-weighted vote score
+	TrustScore float64 `json:"trust_score"`
+}
+
+// CommentDocument represents a comment in the search index
+type CommentDocument struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	ArticleID string    `json:"article_id"`
+	ParentID  string    `json:"parent_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SortOrder selects how search results are ordered
+type SortOrder string
+
+// Supported sort orders
+const (
+	SortRelevance SortOrder = "relevance"
+	SortNewest    SortOrder = "newest"
+	SortOldest    SortOrder = "oldest"
+	SortMostVoted SortOrder = "most_voted"
+	SortTrust     SortOrder = "trust"
+)
+
+// ParseSortOrder validates a sort parameter. An empty value means relevance.
+func ParseSortOrder(s string) (SortOrder, bool) {
+	switch order := SortOrder(s); order {
+	case "":
+		return SortRelevance, true
+	case SortRelevance, SortNewest, SortOldest, SortMostVoted, SortTrust:
+		return order, true
+	default:
+		return "", false
+	}
+}
+
+// Signals are ranking inputs that are not part of the article itself
+type Signals struct {
+	Votes      float64 // reputation-weighted vote score
+	TrustScore float64
+}
+
+// SignalProvider supplies ranking signals for an article at index time
+type SignalProvider interface {
+	ArticleSignals(article *domain.Article) Signals
+}
+
+// SignalFunc adapts a function to the SignalProvider interface
+type SignalFunc func(article *domain.Article) Signals
+
+// ArticleSignals calls f(article)
+func (f SignalFunc) ArticleSignals(article *domain.Article) Signals {
+	return f(article)
 }
 
 // SearchQuery represents a search query
@@ -29,17 +102,100 @@ type SearchQuery struct {
 	ToDate   time.Time
 	Page     int
 	Limit    int
+
+	// Fuzziness is the maximum edit distance for matching query terms
+	// (0 = exact). Callers should clamp it to the configured cap.
+	Fuzziness int
+	// Prefix also matches terms that start with each query word.
+	// Query words containing * or ? are always treated as wildcards.
+	Prefix bool
+	// SortBy orders the results; empty means relevance
+	SortBy SortOrder
+	// Scope is ScopeLocal (default) or ScopeNetwork to also ask peers
+	Scope string
+	// DocType restricts results to DocTypeArticle (default), DocTypeComment or DocTypeAll
+	DocType string
+	// MinTrust drops articles whose current trust score (0-100) is lower
+	MinTrust float64
+	// Labels keeps articles carrying every label; ExcludeLabels drops those
+	// carrying any. Labels come from filter lists and the classifier.
+	Labels        []string
+	ExcludeLabels []string
+}
+
+// MinWildcardPrefix is the number of literal characters a wildcard word
+// must start with. Leading wildcards would scan every term in the index.
+const MinWildcardPrefix = 2
+
+// Validate rejects queries too expensive to run
+func (q *SearchQuery) Validate() error {
+	for _, word := range strings.Fields(q.Query) {
+		if i := strings.IndexAny(word, "*?"); i >= 0 && i < MinWildcardPrefix {
+			return domain.NewValidationError("q",
+				fmt.Sprintf("wildcards must follow at least %d characters", MinWildcardPrefix))
+		}
+	}
+	return nil
+}
+
+// Search scopes
+const (
+	ScopeLocal   = "local"
+	ScopeNetwork = "network"
+)
+
+// HitHighlight holds HTML fragments for one hit with matched terms wrapped
+// in <mark>. Fragment text is HTML-escaped by the highlighter.
+type HitHighlight struct {
+	Title []string `json:"title,omitempty"`
+	Body  []string `json:"body,omitempty"`
+}
+
+// CommentHit is a comment matched by a search, built from stored fields
+type CommentHit struct {
+	ID         string    `json:"id"`
+	ArticleID  string    `json:"article_id"`
+	ParentID   string    `json:"parent_id,omitempty"`
+	Author     string    `json:"author"`
+	Body       string    `json:"body"`
+	Timestamp  time.Time `json:"timestamp"`
+	Highlights []string  `json:"highlights,omitempty"`
 }
 
 // SearchResult represents a search result
 type SearchResult struct {
 	Articles   []*domain.Article
-	IDs        []string // Document IDs from search (for fetching full articles)
+	Comments   []*CommentHit            // Only for comment or all searches
+	IDs        []string                 // Document IDs from search (for fetching full articles)
+	Highlights map[string]*HitHighlight // Keyed by article ID, only for full-text queries
 	Total      int
 	Page       int
 	Limit      int
 	TotalPages int
 	QueryTime  int64 // milliseconds
+
+	// Set for network-scoped searches
+	PeersResponded int
+	RemoteResults  int // articles on this page that came from peers
+
+	// Articles removed by MinTrust, out of the local hits considered and
+	// this page's peer results
+	FilteredLowTrust int
+}
+
+// Suggestion kinds
+const (
+	SuggestionTerm  = "term"
+	SuggestionTag   = "tag"
+	SuggestionTitle = "title"
+)
+
+// Suggestion is a single autocomplete entry for the search box
+type Suggestion struct {
+	Text  string `json:"text"`
+	Kind  string `json:"kind"`            // "term", "tag" or "title"
+	Count int    `json:"count,omitempty"` // documents containing the term
+	ID    string `json:"id,omitempty"`    // article ID for title suggestions
 }
 
 // Index defines the interface for search indexing
@@ -53,22 +209,44 @@ type Index interface {
 	// IndexArticle indexes an article
 	IndexArticle(ctx context.Context, article *domain.Article) error
 
+	// IndexArticles indexes several articles in one write
+	IndexArticles(ctx context.Context, articles []*domain.Article) error
+
 	// UpdateArticle updates an indexed article
 	UpdateArticle(ctx context.Context, article *domain.Article) error
 
 	// DeleteArticle removes an article from the index
 	DeleteArticle(ctx context.Context, articleID string) error
 
+	// IndexComment indexes or replaces a comment
+	IndexComment(ctx context.Context, comment *domain.Comment) error
+
+	// DeleteComment removes a comment from the index
+	DeleteComment(ctx context.Context, commentID string) error
+
 	// Search searches the index
 	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
 
+	// Suggest returns completions for a word prefix
+	Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error)
+
 	// Count returns the number of documents in the index
 	Count() (uint64, error)
 }
 
+// ArticleKey identifies an article across nodes: its CID, or its ID
+// when it hasn't been stored in IPFS
+func ArticleKey(article *domain.Article) string {
+	if article.CID != "" {
+		return "cid:" + article.CID
+	}
+	return "id:" + article.ID
+}
+
 // articleToDocument converts an article to a search document
 func ArticleToDocument(article *domain.Article) *SearchDocument {
 	return &SearchDocument{
+		Type:      DocTypeArticle,
 		ID:        article.ID,
 		Title:     article.Title,
 		Body:      article.Body,
@@ -77,5 +255,31 @@ func ArticleToDocument(article *domain.Article) *SearchDocument {
 		Category:  article.Category,
 		Timestamp: article.Timestamp,
 		CID:       article.CID,
+		Labels:    articleLabels(article),
+	}
+}
+
+// articleLabels combines an article's filter list labels with the
+// classifications scored at DefaultClassificationScore or above
+func articleLabels(article *domain.Article) []string {
+	labels := slices.Clone(article.Labels)
+	for _, c := range article.Classifications {
+		if c.Score >= domain.DefaultClassificationScore && !slices.Contains(labels, c.Label) {
+			labels = append(labels, c.Label)
+		}
+	}
+	return labels
+}
+
+// CommentToDocument converts a comment to a search document
+func CommentToDocument(comment *domain.Comment) *CommentDocument {
+	return &CommentDocument{
+		Type:      DocTypeComment,
+		ID:        comment.ID,
+		ArticleID: comment.ArticleID,
+		ParentID:  comment.ParentID,
+		Author:    comment.Author,
+		Body:      comment.Body,
+		Timestamp: comment.CreatedAt,
 	}
 }
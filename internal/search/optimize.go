@@ -0,0 +1,143 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blevesearch/bleve/v2/index/scorch"
+)
+
+// ErrOptimizeUnsupported is returned when the index backend can't be compacted
+var ErrOptimizeUnsupported = errors.New("index backend does not support compaction")
+
+// IndexStats describes the on-disk layout of the index
+type IndexStats struct {
+	Documents        uint64 `json:"documents"`
+	FileSegments     uint64 `json:"file_segments"`
+	MemorySegments   uint64 `json:"memory_segments"`
+	FilesOnDisk      uint64 `json:"files_on_disk"`
+	BytesOnDisk      uint64 `json:"bytes_on_disk"`
+	ReclaimableBytes uint64 `json:"reclaimable_bytes"` // approx. space held by deleted docs
+
+	// Fragmentation is the share of the current snapshot's bytes that a
+	// full compaction would reclaim (0-1)
+	Fragmentation float64 `json:"fragmentation"`
+}
+
+// OptimizeReport summarizes a compaction run
+type OptimizeReport struct {
+	StartedAt  time.Time   `json:"started_at"`
+	FinishedAt time.Time   `json:"finished_at"`
+	DurationMs int64       `json:"duration_ms"`
+	Before     *IndexStats `json:"before"`
+	After      *IndexStats `json:"after"`
+}
+
+// Stats reports segment counts, disk usage and fragmentation
+func (b *BleveIndex) Stats() (*IndexStats, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.stats()
+}
+
+// stats reads scorch statistics. Callers hold b.mu.
+func (b *BleveIndex) stats() (*IndexStats, error) {
+	s, err := b.scorch()
+	if err != nil {
+		return nil, err
+	}
+
+	m := s.StatsMap()
+	if m == nil {
+		return nil, errors.New("index snapshot unavailable")
+	}
+
+	count, err := b.index.DocCount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	stats := &IndexStats{
+		Documents:        count,
+		FileSegments:     statUint(m, "num_root_filesegments"),
+		MemorySegments:   statUint(m, "num_root_memorysegments"),
+		FilesOnDisk:      statUint(m, "num_files_on_disk"),
+		BytesOnDisk:      statUint(m, "num_bytes_used_disk"),
+		ReclaimableBytes: statUint(m, "num_bytes_used_disk_by_root_reclaimable"),
+	}
+	if byRoot := statUint(m, "num_bytes_used_disk_by_root"); byRoot > 0 {
+		stats.Fragmentation = float64(stats.ReclaimableBytes) / float64(byRoot)
+	}
+	return stats, nil
+}
+
+// Optimize merges the index down to a single segment, dropping deleted
+// documents. Searches and writes continue while the merge runs.
+func (b *BleveIndex) Optimize(ctx context.Context) (*OptimizeReport, error) {
+	b.merging.Lock()
+	defer b.merging.Unlock()
+
+	b.mu.RLock()
+	s, err := b.scorch()
+	report := &OptimizeReport{StartedAt: time.Now()}
+	if err == nil {
+		report.Before, err = b.stats()
+	}
+	b.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// nil options request a single-segment merge. The index can't be
+	// swapped out meanwhile, since Rebuild waits for b.merging.
+	if err := s.ForceMerge(ctx, nil); err != nil {
+		return nil, fmt.Errorf("force merge failed: %w", err)
+	}
+
+	if report.After, err = b.Stats(); err != nil {
+		return nil, err
+	}
+	report.FinishedAt = time.Now()
+	report.DurationMs = report.FinishedAt.Sub(report.StartedAt).Milliseconds()
+
+	b.logger.Info("Search index optimized",
+		"segments_before", report.Before.FileSegments,
+		"segments_after", report.After.FileSegments,
+		"bytes_before", report.Before.BytesOnDisk,
+		"bytes_after", report.After.BytesOnDisk,
+		"duration_ms", report.DurationMs,
+	)
+	return report, nil
+}
+
+// scorch returns the underlying scorch index. Callers hold b.mu.
+func (b *BleveIndex) scorch() (*scorch.Scorch, error) {
+	if b.index == nil {
+		return nil, errors.New("search index is not open")
+	}
+	advanced, err := b.index.Advanced()
+	if err != nil {
+		return nil, fmt.Errorf("failed to access index internals: %w", err)
+	}
+	s, ok := advanced.(*scorch.Scorch)
+	if !ok {
+		return nil, ErrOptimizeUnsupported
+	}
+	return s, nil
+}
+
+// statUint reads a numeric scorch stat, which may be any unsigned width
+func statUint(m map[string]interface{}, key string) uint64 {
+	switch v := m[key].(type) {
+	case uint64:
+		return v
+	case int64:
+		return uint64(v)
+	case int:
+		return uint64(v)
+	default:
+		return 0
+	}
+}
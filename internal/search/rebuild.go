@@ -0,0 +1,231 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ErrRebuildInProgress is returned when Rebuild is called while another rebuild runs
+var ErrRebuildInProgress = errors.New("index rebuild already in progress")
+
+// rebuildBatchSize is the number of documents written per Bleve batch
+const rebuildBatchSize = 500
+
+// ArticleSource feeds every article to add, stopping on the first error
+type ArticleSource func(add func(*domain.Article) error) error
+
+// Rebuild builds a fresh index with the current mapping from the articles
+// yielded by source, then swaps it in for the live index. Comments have no
+// other source of truth yet and are copied over from the live index. Searches keep
+// using the old index until the swap. Writes made while the rebuild runs
+// are applied to both indexes and win over the snapshot from source.
+// Returns the number of documents written.
+func (b *BleveIndex) Rebuild(ctx context.Context, source ArticleSource) (int, error) {
+	b.mu.Lock()
+	if b.index == nil {
+		b.mu.Unlock()
+		return 0, errors.New("search index is not open")
+	}
+	if b.rebuilding != nil {
+		b.mu.Unlock()
+		return 0, ErrRebuildInProgress
+	}
+
+	buildPath := fmt.Sprintf("%s.rebuild-%d", b.path, time.Now().UnixNano())
+	fresh, err := b.newIndex(buildPath)
+	if err != nil {
+		b.mu.Unlock()
+		return 0, fmt.Errorf("failed to create rebuild index: %w", err)
+	}
+	b.rebuilding = fresh
+	b.touched = make(map[string]bool)
+	b.mu.Unlock()
+
+	b.logger.Info("Rebuilding search index", "path", b.path, "build_path", buildPath)
+
+	count, err := b.fill(ctx, fresh, source)
+	if err == nil {
+		var comments int
+		comments, err = b.copyComments(ctx, fresh)
+		count += comments
+	}
+
+	// A running merge holds the live index; let it finish before the swap
+	b.merging.Lock()
+	defer b.merging.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rebuilding = nil
+	b.touched = nil
+
+	if err != nil {
+		fresh.Close()
+		os.RemoveAll(buildPath)
+		return 0, err
+	}
+
+	if err := b.swap(fresh, buildPath); err != nil {
+		return 0, err
+	}
+
+	b.logger.Info("Search index rebuilt", "path", b.path, "documents", count)
+	return count, nil
+}
+
+// fill writes articles from source into fresh in batches, skipping IDs
+// that were written live since the rebuild started
+func (b *BleveIndex) fill(ctx context.Context, fresh bleve.Index, source ArticleSource) (int, error) {
+	count := 0
+	pending := make([]*domain.Article, 0, rebuildBatchSize)
+
+	flush := func() error {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		batch := fresh.NewBatch()
+		for _, article := range pending {
+			if b.touched[article.ID] {
+				continue
+			}
+			if err := batch.Index(article.ID, b.document(article)); err != nil {
+				return fmt.Errorf("failed to index article %s: %w", article.ID, err)
+			}
+		}
+		if err := fresh.Batch(batch); err != nil {
+			return fmt.Errorf("failed to write rebuild batch: %w", err)
+		}
+		count += batch.Size()
+		pending = pending[:0]
+		return nil
+	}
+
+	err := source(func(article *domain.Article) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pending = append(pending, article)
+		if len(pending) >= rebuildBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) > 0 {
+		if err := flush(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// copyComments copies comment documents from the live index into fresh
+// using their stored fields, skipping comments written since the rebuild began
+func (b *BleveIndex) copyComments(ctx context.Context, fresh bleve.Index) (int, error) {
+	commentType := bleve.NewTermQuery(DocTypeComment)
+	commentType.SetField("type")
+
+	count := 0
+	for from := 0; ; from += rebuildBatchSize {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		request := bleve.NewSearchRequestOptions(commentType, rebuildBatchSize, from, false)
+		request.Fields = []string{"article_id", "parent_id", "author", "body", "timestamp"}
+		request.SortBy([]string{"_id"})
+
+		b.mu.RLock()
+		results, err := b.index.SearchInContext(ctx, request)
+		b.mu.RUnlock()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read comments for rebuild: %w", err)
+		}
+		if len(results.Hits) == 0 {
+			return count, nil
+		}
+
+		b.mu.Lock()
+		batch := fresh.NewBatch()
+		for _, hit := range results.Hits {
+			if b.touched[hit.ID] {
+				continue
+			}
+			c := commentHit(hit)
+			doc := CommentToDocument(&domain.Comment{
+				ID:        c.ID,
+				ArticleID: c.ArticleID,
+				ParentID:  c.ParentID,
+				Author:    c.Author,
+				Body:      c.Body,
+				CreatedAt: c.Timestamp,
+			})
+			if err := batch.Index(hit.ID, doc); err != nil {
+				b.mu.Unlock()
+				return 0, fmt.Errorf("failed to copy comment %s: %w", c.ID, err)
+			}
+		}
+		err = fresh.Batch(batch)
+		count += batch.Size()
+		b.mu.Unlock()
+		if err != nil {
+			return 0, fmt.Errorf("failed to write comment batch: %w", err)
+		}
+	}
+}
+
+// swap replaces the live index directory with the rebuilt one and reopens
+// it. On failure the previous index is restored. Callers hold b.mu.
+func (b *BleveIndex) swap(fresh bleve.Index, buildPath string) error {
+	oldPath := b.path + ".old"
+
+	if err := fresh.Close(); err != nil {
+		os.RemoveAll(buildPath)
+		return fmt.Errorf("failed to close rebuilt index: %w", err)
+	}
+	if err := b.index.Close(); err != nil {
+		b.logger.Warn("Failed to close index before swap", "error", err)
+	}
+
+	os.RemoveAll(oldPath)
+	if err := os.Rename(b.path, oldPath); err != nil {
+		os.RemoveAll(buildPath)
+		return b.reopen(fmt.Errorf("failed to move old index aside: %w", err))
+	}
+	if err := os.Rename(buildPath, b.path); err != nil {
+		os.RemoveAll(buildPath)
+		os.Rename(oldPath, b.path)
+		return b.reopen(fmt.Errorf("failed to move rebuilt index into place: %w", err))
+	}
+
+	index, err := bleve.Open(b.path)
+	if err != nil {
+		os.RemoveAll(b.path)
+		os.Rename(oldPath, b.path)
+		return b.reopen(fmt.Errorf("failed to open rebuilt index: %w", err))
+	}
+
+	b.index = index
+	b.outdated = false
+	os.RemoveAll(oldPath)
+	return nil
+}
+
+// reopen reopens the index at b.path after a failed swap and returns cause
+func (b *BleveIndex) reopen(cause error) error {
+	index, err := bleve.Open(b.path)
+	if err != nil {
+		b.logger.Error("Failed to reopen search index after failed rebuild", "error", err)
+		return fmt.Errorf("%w (reopen failed: %v)", cause, err)
+	}
+	b.index = index
+	return cause
+}
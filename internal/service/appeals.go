@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// AppealService lets authors appeal the moderation of their articles.
+// An article can be appealed while it is hidden, unlisted or unpinned by
+// a moderator, or quarantined by a filter list. Appeals wait in a queue
+// until a moderator upholds the decision or reverses it, which restores
+// the article and the author's reputation.
+type AppealService struct {
+	repo       repository.AppealRepository
+	articles   repository.ArticleRepository
+	users      repository.UserRepository
+	signer     *auth.ArticleSigner
+	moderation *ModerationService
+	audit      AuditRecorder // optional; records every appeal and decision
+	mu         sync.Mutex    // serialises deciding an appeal
+	logger     *logger.Logger
+}
+
+// NewAppealService creates a new appeal service
+func NewAppealService(
+	repo repository.AppealRepository,
+	articles repository.ArticleRepository,
+	users repository.UserRepository,
+	signer *auth.ArticleSigner,
+	moderation *ModerationService,
+	logger *logger.Logger,
+) *AppealService {
+	return &AppealService{
+		repo:       repo,
+		articles:   articles,
+		users:      users,
+		signer:     signer,
+		moderation: moderation,
+		logger:     logger.WithComponent("appeal-service"),
+	}
+}
+
+// SetAuditLog records every appeal and its outcome in the audit log
+func (s *AppealService) SetAuditLog(audit AuditRecorder) {
+	s.audit = audit
+}
+
+// File appeals the moderation of the article with the given ID or CID on
+// behalf of its author, signing the appeal with their key
+func (s *AppealService) File(ctx context.Context, ref, userID string, req *domain.AppealCreateRequest) (*domain.Appeal, error) {
+	article, err := findArticle(ctx, s.articles, ref)
+	if err != nil {
+		return nil, err
+	}
+	user, privateKey, err := loadSigningKey(ctx, s.users, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username != article.Author {
+		return nil, domain.ErrForbidden
+	}
+
+	appeal := &domain.Appeal{
+		ArticleID: article.ID,
+		Author:    user.Username,
+		Reason:    strings.TrimSpace(req.Reason),
+		Timestamp: time.Now().Unix(),
+	}
+	if err := appeal.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.signer.SignAppeal(appeal, privateKey); err != nil {
+		return nil, err
+	}
+	return s.open(ctx, article, appeal)
+}
+
+// Submit accepts an appeal the author signed elsewhere, e.g. on the node
+// their account lives on. The signature must verify against the key the
+// article is signed with.
+func (s *AppealService) Submit(ctx context.Context, req *domain.SignedAppealRequest) (*domain.Appeal, error) {
+	appeal := &domain.Appeal{
+		ArticleID: req.ArticleID,
+		Author:    req.Author,
+		Reason:    req.Reason,
+		Timestamp: req.Timestamp,
+		Signature: req.Signature,
+	}
+	if err := appeal.Validate(); err != nil {
+		return nil, err
+	}
+
+	article, err := s.articles.GetByID(ctx, req.ArticleID)
+	if err != nil {
+		return nil, err
+	}
+	if appeal.Author != article.Author {
+		return nil, domain.ErrInvalidSignature
+	}
+	if err := s.signer.VerifyAppeal(appeal, article.AuthorPubKey); err != nil {
+		s.logger.Ctx(ctx).Warn("Invalid signature on appeal", "article_id", article.ID, "author", appeal.Author, "error", err)
+		return nil, domain.ErrInvalidSignature
+	}
+	return s.open(ctx, article, appeal)
+}
+
+// open queues a signed appeal against the article's latest moderation
+// case, if that case hid, unlisted, unpinned or quarantined it
+func (s *AppealService) open(ctx context.Context, article *domain.Article, appeal *domain.Appeal) (*domain.Appeal, error) {
+	c, err := s.moderation.LatestCase(ctx, article.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrCaseNotFound) {
+			return nil, domain.ErrNotAppealable
+		}
+		return nil, err
+	}
+	quarantined := !c.Closed() && article.Visibility == domain.VisibilityQuarantined
+	if c.Status != domain.CaseActioned && !quarantined {
+		return nil, domain.ErrNotAppealable
+	}
+
+	now := time.Now().UTC()
+	appeal.ID = uuid.New().String()
+	appeal.CaseID = c.ID
+	appeal.ArticleCID = article.CID
+	appeal.Status = domain.AppealOpen
+	appeal.CreatedAt = now
+	appeal.UpdatedAt = now
+	if err := s.repo.Create(ctx, appeal); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Moderation appealed", "appeal_id", appeal.ID, "case_id", c.ID, "article_id", article.ID, "author", appeal.Author)
+	if _, ok := auth.ActorFrom(ctx); !ok {
+		ctx = auth.WithActor(ctx, "", appeal.Author) // a signed appeal from another node
+	}
+	s.record(ctx, domain.AuditAppealFile, appeal, appeal.Reason)
+	return appeal, nil
+}
+
+// Get retrieves an appeal by ID
+func (s *AppealService) Get(ctx context.Context, id string) (*domain.Appeal, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List retrieves appeals, newest first
+func (s *AppealService) List(ctx context.Context, filter *domain.AppealListFilter) ([]*domain.Appeal, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Uphold closes an appeal leaving the moderation decision in place
+func (s *AppealService) Uphold(ctx context.Context, id, moderatorID string, req *domain.AppealDecisionRequest) (*domain.Appeal, error) {
+	return s.decide(ctx, id, moderatorID, domain.AppealUpheld, req)
+}
+
+// Reverse closes an appeal by undoing the moderation decision: the
+// article is restored and the author's reputation penalty lifted
+func (s *AppealService) Reverse(ctx context.Context, id, moderatorID string, req *domain.AppealDecisionRequest) (*domain.Appeal, error) {
+	return s.decide(ctx, id, moderatorID, domain.AppealReversed, req)
+}
+
+func (s *AppealService) decide(ctx context.Context, id, moderatorID, status string, req *domain.AppealDecisionRequest) (*domain.Appeal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	appeal, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if appeal.Status != domain.AppealOpen {
+		return nil, domain.ErrAppealClosed
+	}
+
+	// The case is reversed first, so a failure leaves the appeal open
+	if status == domain.AppealReversed {
+		if _, err := s.moderation.ReverseCase(ctx, appeal.CaseID, moderatorID, req.Note); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	appeal.Status = status
+	appeal.UpdatedAt = now
+	appeal.Decision = &domain.AppealDecision{
+		Note:      req.Note,
+		Moderator: moderatorID,
+		DecidedAt: now,
+	}
+	if err := s.repo.Update(ctx, appeal); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Appeal decided", "appeal_id", id, "case_id", appeal.CaseID, "status", status, "moderator", moderatorID)
+	if _, ok := auth.ActorFrom(ctx); !ok {
+		ctx = auth.WithActor(ctx, moderatorID, "")
+	}
+	action := domain.AuditAppealUphold
+	if status == domain.AppealReversed {
+		action = domain.AuditAppealReverse
+	}
+	s.record(ctx, action, appeal, req.Note)
+	return appeal, nil
+}
+
+// record adds an appeal event to the audit log
+func (s *AppealService) record(ctx context.Context, action string, appeal *domain.Appeal, note string) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, action, appeal.ArticleID, map[string]string{
+		"appeal_id":   appeal.ID,
+		"case_id":     appeal.CaseID,
+		"article_cid": appeal.ArticleCID,
+		"author":      appeal.Author,
+		"note":        note,
+	})
+}
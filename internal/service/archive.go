@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+var (
+	// ErrArchiveEmpty is returned when an export selection matches no articles
+	ErrArchiveEmpty = errors.New("no articles matched the archive selection")
+	// ErrArchiveTooLarge is returned when an export selection exceeds MaxArchiveArticles
+	ErrArchiveTooLarge = fmt.Errorf("archive selection exceeds %d articles", domain.MaxArchiveArticles)
+	// ErrArchiveManifest is returned when an imported CAR has no readable manifest
+	ErrArchiveManifest = errors.New("archive has no readable manifest")
+)
+
+// ArchiveStore is the IPFS surface needed to build and load CAR archives
+type ArchiveStore interface {
+	Add(ctx context.Context, data []byte) (string, error)
+	Cat(ctx context.Context, cid string) ([]byte, error)
+	Resolve(ctx context.Context, ref string) (string, error)
+	BuildDirectory(ctx context.Context, entries []ipfs.DirectoryEntry) (string, error)
+	ExportCAR(ctx context.Context, root string, w io.Writer) error
+	ImportCAR(ctx context.Context, r io.Reader) ([]string, error)
+}
+
+// ArticleReceiver verifies and stores articles that arrive from outside this node
+type ArticleReceiver interface {
+	HasArticle(ctx context.Context, id string) bool
+	HandleIncomingArticle(article *domain.Article) error
+}
+
+// ArchiveSelection chooses the articles to export. IDs take precedence;
+// otherwise the filter fields select from the local store.
+type ArchiveSelection struct {
+	IDs    []string
+	Author string
+	Tags   []string
+	Since  time.Time
+	Until  time.Time
+}
+
+// ArchiveService exports articles as CAR archives and imports them back
+type ArchiveService struct {
+	articleRepo repository.ArticleRepository
+	store       ArchiveStore
+	receiver    ArticleReceiver
+	logger      *logger.Logger
+}
+
+// NewArchiveService creates a new archive service
+func NewArchiveService(
+	articleRepo repository.ArticleRepository,
+	store ArchiveStore,
+	receiver ArticleReceiver,
+	logger *logger.Logger,
+) *ArchiveService {
+	return &ArchiveService{
+		articleRepo: articleRepo,
+		store:       store,
+		receiver:    receiver,
+		logger:      logger.WithComponent("archive-service"),
+	}
+}
+
+// Prepare assembles the selected articles, their attachments and a manifest
+// into one IPFS directory and returns its root CID. Stream it with WriteCAR.
+func (s *ArchiveService) Prepare(ctx context.Context, sel ArchiveSelection) (string, *domain.ArchiveManifest, error) {
+	articles, err := s.selectArticles(ctx, sel)
+	if err != nil {
+		return "", nil, err
+	}
+
+	manifest := &domain.ArchiveManifest{
+		Version:   domain.ArchiveManifestVersion,
+		CreatedAt: time.Now().UTC(),
+		Articles:  make([]domain.ArchiveArticleEntry, 0, len(articles)),
+	}
+
+	var entries []ipfs.DirectoryEntry
+	seen := make(map[string]bool)
+	for _, article := range articles {
+		cid, err := s.contentCID(ctx, article)
+		if err != nil {
+			return "", nil, err
+		}
+
+		entry := domain.ArchiveArticleEntry{
+			ID:          article.ID,
+			CID:         cid,
+			Title:       article.Title,
+			Author:      article.Author,
+			Path:        path.Join(domain.ArchiveArticlesDir, article.ID+".json"),
+			Attachments: article.AttachmentCIDs(),
+		}
+		manifest.Articles = append(manifest.Articles, entry)
+		entries = append(entries, ipfs.DirectoryEntry{Path: entry.Path, CID: cid})
+
+		for _, attachment := range entry.Attachments {
+			if seen[attachment] {
+				continue
+			}
+			seen[attachment] = true
+			entries = append(entries, ipfs.DirectoryEntry{
+				Path: path.Join(domain.ArchiveAttachmentsDir, attachment),
+				CID:  attachment,
+			})
+		}
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	manifestCID, err := s.store.Add(ctx, manifestJSON)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to store manifest: %w", err)
+	}
+	entries = append(entries, ipfs.DirectoryEntry{Path: domain.ArchiveManifestPath, CID: manifestCID})
+
+	root, err := s.store.BuildDirectory(ctx, entries)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to build archive: %w", err)
+	}
+
+	s.logger.Ctx(ctx).Info("Prepared archive", "root", root, "articles", len(manifest.Articles), "attachments", len(seen))
+	return root, manifest, nil
+}
+
+// WriteCAR streams a prepared archive to w as CARv1
+func (s *ArchiveService) WriteCAR(ctx context.Context, root string, w io.Writer) error {
+	return s.store.ExportCAR(ctx, root, w)
+}
+
+// Import loads a CAR archive and stores every article listed in its
+// manifest. Articles go through the same signature check as articles
+// received from peers, so a tampered archive cannot inject content.
+func (s *ArchiveService) Import(ctx context.Context, r io.Reader) (*domain.ArchiveImportReport, error) {
+	roots, err := s.store.ImportCAR(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ArchiveImportReport{
+		Roots:    roots,
+		Imported: []string{},
+		Skipped:  []string{},
+	}
+
+	manifests := 0
+	for _, root := range roots {
+		manifest, err := s.readManifest(ctx, root)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("root %s: %v", root, err))
+			continue
+		}
+		manifests++
+
+		for _, entry := range manifest.Articles {
+			s.importArticle(ctx, root, entry, report)
+		}
+	}
+
+	if manifests == 0 {
+		return nil, ErrArchiveManifest
+	}
+
+	s.logger.Ctx(ctx).Info("Imported archive",
+		"roots", len(roots),
+		"imported", len(report.Imported),
+		"skipped", len(report.Skipped),
+		"failed", len(report.Failed),
+	)
+	return report, nil
+}
+
+// importArticle reads one manifest entry from the archive and hands it to the receiver
+func (s *ArchiveService) importArticle(ctx context.Context, root string, entry domain.ArchiveArticleEntry, report *domain.ArchiveImportReport) {
+	if s.receiver.HasArticle(ctx, entry.ID) {
+		report.Skipped = append(report.Skipped, entry.ID)
+		return
+	}
+
+	// The manifest is only a claim. The article is read by the CID the
+	// archive's own directory links to, whose blocks are hashed as they are
+	// read, and that CID must be the one the manifest lists.
+	target, err := s.store.Resolve(ctx, path.Join(root, entry.Path))
+	if err == nil && target != entry.CID {
+		err = fmt.Errorf("%w: manifest lists %s, archive holds %s", domain.ErrCIDMismatch, entry.CID, target)
+		report.Warnings = append(report.Warnings, fmt.Sprintf("article %s: %v", entry.ID, err))
+	}
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Archived article rejected", "article_id", entry.ID, "error", err)
+		report.Failed = append(report.Failed, entry.ID)
+		return
+	}
+
+	data, err := s.store.Cat(ctx, target)
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Archived article unreadable", "article_id", entry.ID, "error", err)
+		report.Failed = append(report.Failed, entry.ID)
+		return
+	}
+
+	article, err := domain.FromJSON(data)
+	if err != nil || article.ID != entry.ID {
+		s.logger.Ctx(ctx).Warn("Archived article malformed", "article_id", entry.ID, "error", err)
+		report.Failed = append(report.Failed, entry.ID)
+		return
+	}
+	article.CID = entry.CID
+
+	if err := s.receiver.HandleIncomingArticle(article); err != nil {
+		report.Failed = append(report.Failed, entry.ID)
+		return
+	}
+	report.Imported = append(report.Imported, entry.ID)
+}
+
+// readManifest loads and decodes manifest.json below root
+func (s *ArchiveService) readManifest(ctx context.Context, root string) (*domain.ArchiveManifest, error) {
+	data, err := s.store.Cat(ctx, path.Join(root, domain.ArchiveManifestPath))
+	if err != nil {
+		return nil, ErrArchiveManifest
+	}
+
+	var manifest domain.ArchiveManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, ErrArchiveManifest
+	}
+	if manifest.Version > domain.ArchiveManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// selectArticles resolves an export selection against the local store
+func (s *ArchiveService) selectArticles(ctx context.Context, sel ArchiveSelection) ([]*domain.Article, error) {
+	var articles []*domain.Article
+	if len(sel.IDs) > 0 {
+		if len(sel.IDs) > domain.MaxArchiveArticles {
+			return nil, ErrArchiveTooLarge
+		}
+		found, err := s.articleRepo.GetByIDs(ctx, sel.IDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load articles: %w", err)
+		}
+		// Only what listings show is archived: moderation keeps hidden and
+		// unlisted articles out, and circle articles are only published sealed
+		for _, article := range found {
+			if article.Listed() {
+				articles = append(articles, article)
+			}
+		}
+	} else {
+		found, total, err := s.articleRepo.List(ctx, &domain.ArticleListFilter{
+			Author:   sel.Author,
+			Tags:     sel.Tags,
+			FromDate: sel.Since,
+			ToDate:   sel.Until,
+			Page:     1,
+			Limit:    domain.MaxArchiveArticles,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list articles: %w", err)
+		}
+		if total > domain.MaxArchiveArticles {
+			return nil, ErrArchiveTooLarge
+		}
+		articles = found
+	}
+
+	if len(articles) == 0 {
+		return nil, ErrArchiveEmpty
+	}
+	return articles, nil
+}
+
+// contentCID returns the IPFS CID holding the article's signed JSON.
+// Articles stored while IPFS was down only have a local ID, so their
+// JSON is uploaded now.
+func (s *ArchiveService) contentCID(ctx context.Context, article *domain.Article) (string, error) {
+	if article.CID != "" && !domain.IsLocalCID(article.CID) {
+		return article.CID, nil
+	}
+
+	stored := *article
+	stored.CID = ""
+	stored.NodeCID = ""
+	data, err := stored.ToJSON()
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize article %s: %w", article.ID, err)
+	}
+
+	cid, err := s.store.Add(ctx, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload local article %s: %w", article.ID, err)
+	}
+	return cid, nil
+}
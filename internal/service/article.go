@@ -2,16 +2,22 @@ package service
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/auth"
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
 	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/internal/tracing"
 	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
@@ -19,6 +25,7 @@ import (
 // SearchIndexer defines the interface for search indexing
 type SearchIndexer interface {
 	IndexArticle(ctx context.Context, article *domain.Article) error
+	IndexArticles(ctx context.Context, articles []*domain.Article) error
 	UpdateArticle(ctx context.Context, article *domain.Article) error
 	DeleteArticle(ctx context.Context, articleID string) error
 }
@@ -30,11 +37,70 @@ type IPFSClient interface {
 	Unpin(ctx context.Context, cid string) error
 }
 
+// DAGStore stores and resolves IPLD nodes
+type DAGStore interface {
+	DagPut(ctx context.Context, node interface{}) (string, error)
+	DagGet(ctx context.Context, ref string, out interface{}) error
+}
+
+// PinTracker records CIDs that should stay pinned, keyed by article ID
+type PinTracker interface {
+	Track(ctx context.Context, cid, ref string)
+	Release(ctx context.Context, ref string)
+}
+
+// CategoryPolicy bans categories network-wide, e.g. the network policy
+type CategoryPolicy interface {
+	CategoryBanned(category string) bool
+}
+
+// ContentFilter screens articles against the node's filter lists
+type ContentFilter interface {
+	Screen(article *domain.Article) *domain.FilterVerdict
+}
+
+// QuarantineQueue puts quarantined articles in front of moderators, e.g.
+// the moderation service
+type QuarantineQueue interface {
+	Report(ctx context.Context, ref, reporterID, reason string) (*domain.Report, error)
+}
+
+// IngestBlocklist names the articles subscribed blocklists refuse, e.g.
+// the blocklist service
+type IngestBlocklist interface {
+	ArticleBlocked(article *domain.Article) (string, bool)
+}
+
+// IngestPolicy decides whether the node accepts an article from peers,
+// e.g. the policy engine
+type IngestPolicy interface {
+	Evaluate(ctx context.Context, article *domain.Article) error
+}
+
+// CircleSealer encrypts articles published to circles and shares the
+// sealed copies with members' nodes, e.g. the circle service
+type CircleSealer interface {
+	Seal(ctx context.Context, article *domain.Article, user *domain.User, privateKey ed25519.PrivateKey) (*domain.SealedArticle, error)
+	Share(ctx context.Context, cid string, sealed *domain.SealedArticle)
+}
+
 // ArticleBroadcaster defines the interface for broadcasting articles to the P2P network
 type ArticleBroadcaster interface {
 	BroadcastArticle(msgType string, article *domain.Article) error
 }
 
+// PeerFetcher retrieves single articles from other nodes. accept vets each
+// copy a peer returns; rejected copies are skipped. The peer that supplied
+// the article is returned with it.
+type PeerFetcher interface {
+	// FetchFromProviders asks peers the DHT lists as providers of cid
+	FetchFromProviders(ctx context.Context, cid string, accept func(*domain.Article) error) (*domain.Article, string, error)
+	// FetchFromPeers asks connected peers
+	FetchFromPeers(ctx context.Context, cid string, accept func(*domain.Article) error) (*domain.Article, string, error)
+	// Provide announces that this node holds the article stored under cid
+	Provide(ctx context.Context, cid string) error
+}
+
 // ArticleService handles article-related business logic
 type ArticleService struct {
 	articleRepo repository.ArticleRepository
@@ -43,6 +109,19 @@ type ArticleService struct {
 	broadcaster ArticleBroadcaster
 	signer      *auth.ArticleSigner
 	indexer     SearchIndexer
+	dag         DAGStore                   // optional; enables dag-cbor revision nodes
+	pins        PinTracker                 // optional; retries and reconciles pins
+	events      events.Publisher           // optional; notifies real-time clients
+	peers       PeerFetcher                // optional; fetches missing articles from peers
+	media       repository.MediaRepository // optional; resolves attached audio/video
+	policy      CategoryPolicy             // optional; refuses banned categories
+	filter      ContentFilter              // optional; rejects, quarantines or labels articles
+	quarantined QuarantineQueue            // optional; reports quarantined articles to moderators
+	classifier  ContentClassifier          // optional; scores articles received from peers
+	classifyAll bool                       // refuse articles the classifier can't score
+	blocklist   IngestBlocklist            // optional; refuses blocked authors and CIDs from peers
+	acceptance  IngestPolicy               // optional; the node's acceptance policy for articles from peers
+	circles     CircleSealer               // optional; encrypts articles published to circles
 	logger      *logger.Logger
 }
 
@@ -67,17 +146,255 @@ func NewArticleService(
 	}
 }
 
+// SetDAGStore enables publishing each article revision as a dag-cbor node
+func (s *ArticleService) SetDAGStore(dag DAGStore) {
+	s.dag = dag
+}
+
+// SetPinTracker records every published CID in the pin ledger
+func (s *ArticleService) SetPinTracker(pins PinTracker) {
+	s.pins = pins
+}
+
+// SetEventPublisher announces articles created here or received from
+// peers to real-time clients
+func (s *ArticleService) SetEventPublisher(publisher events.Publisher) {
+	s.events = publisher
+}
+
+// SetPeerFetcher lets Fetch ask other nodes for articles, and announces
+// the articles stored here in the DHT
+func (s *ArticleService) SetPeerFetcher(peers PeerFetcher) {
+	s.peers = peers
+}
+
+// SetMediaCatalog lets articles attach audio and video uploaded through
+// the upload service, looked up by CID
+func (s *ArticleService) SetMediaCatalog(media repository.MediaRepository) {
+	s.media = media
+}
+
+// SetCategoryPolicy refuses articles in banned categories, whether written
+// here or received from peers
+func (s *ArticleService) SetCategoryPolicy(policy CategoryPolicy) {
+	s.policy = policy
+}
+
+// SetContentFilter screens every article published here or received from
+// peers against the node's filter lists. Quarantined articles are reported
+// to queue, which may be nil.
+func (s *ArticleService) SetContentFilter(filter ContentFilter, queue QuarantineQueue) {
+	s.filter = filter
+	s.quarantined = queue
+}
+
+// SetBlocklist refuses articles from peers whose author or CID a
+// subscribed blocklist names
+func (s *ArticleService) SetBlocklist(blocklist IngestBlocklist) {
+	s.blocklist = blocklist
+}
+
+// SetAcceptancePolicy refuses articles from peers the node's acceptance
+// policy doesn't accept
+func (s *ArticleService) SetAcceptancePolicy(policy IngestPolicy) {
+	s.acceptance = policy
+}
+
+// SetCircles lets users publish articles to circles, encrypted for the
+// circle's members
+func (s *ArticleService) SetCircles(circles CircleSealer) {
+	s.circles = circles
+}
+
+// checkBlocklist rejects articles a subscribed blocklist names
+func (s *ArticleService) checkBlocklist(ctx context.Context, article *domain.Article) error {
+	if s.blocklist == nil {
+		return nil
+	}
+	if name, blocked := s.blocklist.ArticleBlocked(article); blocked {
+		s.logger.Ctx(ctx).Info("Refusing article on a blocklist", "article_id", article.ID, "cid", article.CID, "blocklist", name)
+		return domain.ErrArticleBlocked
+	}
+	return nil
+}
+
+// checkAcceptance rejects articles the acceptance policy refuses
+func (s *ArticleService) checkAcceptance(ctx context.Context, article *domain.Article) error {
+	if s.acceptance == nil {
+		return nil
+	}
+	return s.acceptance.Evaluate(ctx, article)
+}
+
+// checkCategory rejects articles in a category the policy bans
+func (s *ArticleService) checkCategory(article *domain.Article) error {
+	if s.policy != nil && s.policy.CategoryBanned(article.Category) {
+		return domain.NewValidationError("category", "category is banned by the network policy")
+	}
+	return nil
+}
+
+// checkRemoteCategory refuses an article from elsewhere in a category the
+// network policy bans
+func (s *ArticleService) checkRemoteCategory(ctx context.Context, article *domain.Article) error {
+	if err := s.checkCategory(article); err != nil {
+		s.logger.Ctx(ctx).Info("Refusing article in a banned category", "article_id", article.ID, "category", article.Category)
+		return err
+	}
+	return nil
+}
+
+// screen applies the content filter to an article about to be stored. A
+// reject match refuses it, a quarantine match holds it out of sight unless
+// moderation already has, and tag matches replace its labels.
+func (s *ArticleService) screen(article *domain.Article) (*domain.FilterVerdict, error) {
+	article.Labels = nil
+	if s.filter == nil {
+		return nil, nil
+	}
+	verdict := s.filter.Screen(article)
+	if verdict == nil {
+		return nil, nil
+	}
+
+	article.Labels = verdict.Labels
+	switch verdict.Action {
+	case domain.FilterReject:
+		for _, match := range verdict.Matches {
+			if match.Action == domain.FilterReject {
+				return verdict, domain.NewValidationError("content", fmt.Sprintf("content is refused by the filter list %q", match.List))
+			}
+		}
+	case domain.FilterQuarantine:
+		if article.Visibility == "" {
+			article.Visibility = domain.VisibilityQuarantined
+		}
+	}
+	return verdict, nil
+}
+
+// quarantine reports an article a filter list held back to moderators,
+// once per list that quarantined it
+func (s *ArticleService) quarantine(ctx context.Context, article *domain.Article, verdict *domain.FilterVerdict) {
+	s.logger.Ctx(ctx).Info("Article quarantined by a filter list", "article_id", article.ID, "author", article.Author)
+	if s.quarantined == nil {
+		return
+	}
+	for _, match := range verdict.Matches {
+		if match.Action != domain.FilterQuarantine {
+			continue
+		}
+		reason := fmt.Sprintf("Quarantined by filter list %s: matched %q", match.List, match.Match)
+		if _, err := s.quarantined.Report(ctx, article.ID, "filter:"+match.List, reason); err != nil && !errors.Is(err, domain.ErrAlreadyReported) {
+			s.logger.Ctx(ctx).Warn("Failed to queue quarantined article", "article_id", article.ID, "list", match.List, "error", err)
+		}
+	}
+}
+
+// resolveMedia turns the CIDs of uploaded audio/video into the attachments
+// an article signs. Each CID must be in the media catalog.
+func (s *ArticleService) resolveMedia(ctx context.Context, cids []string) ([]domain.MediaAttachment, error) {
+	if len(cids) == 0 {
+		return nil, nil
+	}
+	if s.media == nil {
+		return nil, domain.NewValidationError("media", "media attachments are not supported on this node")
+	}
+	if len(cids) > domain.MaxArticleMedia {
+		return nil, domain.NewValidationError("media", fmt.Sprintf("maximum %d media attachments allowed", domain.MaxArticleMedia))
+	}
+
+	attachments := make([]domain.MediaAttachment, 0, len(cids))
+	seen := make(map[string]bool)
+	for _, cid := range cids {
+		if seen[cid] {
+			continue
+		}
+		seen[cid] = true
+		record, err := s.media.Get(ctx, cid)
+		if err != nil {
+			if errors.Is(err, domain.ErrMediaNotFound) {
+				return nil, domain.NewValidationError("media", fmt.Sprintf("unknown media CID: %s", cid))
+			}
+			return nil, fmt.Errorf("failed to look up media: %w", err)
+		}
+		attachments = append(attachments, record.Attachment())
+	}
+	return attachments, nil
+}
+
 // Create creates a new article
-func (s *ArticleService) Create(ctx context.Context, req *domain.ArticleCreateRequest, userID string, originIP string) (*domain.Article, error) {
-	// Get user with private key for signing
-	user, err := s.userRepo.GetByID(ctx, userID)
+func (s *ArticleService) Create(ctx context.Context, req *domain.ArticleCreateRequest, userID string, originIP string) (_ *domain.Article, err error) {
+	ctx, span := tracing.Start(ctx, "article.create", attribute.String("user.id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	user, privateKey, err := s.signingUser(ctx, userID)
 	if err != nil {
-		s.logger.Error("Failed to get user", "user_id", userID, "error", err)
 		return nil, err
 	}
+	if req.Circle != "" {
+		return s.createInCircle(ctx, req, user, privateKey, originIP)
+	}
+
+	article, verdict, err := s.newArticle(ctx, req, user, privateKey, originIP)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store in database
+	if err := s.articleRepo.Create(ctx, article); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to store article", "article_id", article.ID, "error", err)
+		return nil, fmt.Errorf("failed to store article: %w", err)
+	}
+	s.trackPins(ctx, article)
+	span.SetAttributes(attribute.String("article.id", article.ID), attribute.String("article.cid", article.CID))
+
+	// Quarantined articles wait for moderators before anyone sees them
+	if article.Hidden() {
+		s.quarantine(ctx, article, verdict)
+		return article, nil
+	}
+	s.broadcast(ctx, "new", article)
+
+	// Index for search
+	if s.indexer != nil {
+		if err := s.indexer.IndexArticle(ctx, article); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to index article", "article_id", article.ID, "error", err)
+			// Don't fail on indexing error
+		}
+	}
+
+	s.logger.Ctx(ctx).Info("Article created successfully",
+		"article_id", article.ID,
+		"cid", article.CID,
+		"author", user.Username,
+	)
+
+	if s.events != nil {
+		s.events.Publish(domain.EventArticleCreated, article)
+	}
+
+	return article, nil
+}
+
+// signingUser loads an active user along with their decrypted signing key
+func (s *ArticleService) signingUser(ctx context.Context, userID string) (*domain.User, ed25519.PrivateKey, error) {
+	user, privateKey, err := loadSigningKey(ctx, s.userRepo, userID)
+	if err != nil && !errors.Is(err, domain.ErrUserNotActive) {
+		s.logger.Ctx(ctx).Error("Failed to load signing key", "user_id", userID, "error", err)
+	}
+	return user, privateKey, err
+}
+
+// loadSigningKey loads an active user and decrypts their signing key
+func loadSigningKey(ctx context.Context, users repository.UserRepository, userID string) (*domain.User, ed25519.PrivateKey, error) {
+	user, err := users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if !user.IsActive {
-		return nil, domain.ErrUserNotActive
+		return nil, nil, domain.ErrUserNotActive
 	}
 
 	// Decrypt private key using password hash as key derivation material
@@ -85,8 +402,40 @@ func (s *ArticleService) Create(ctx context.Context, req *domain.ArticleCreateRe
 	// We use the password hash as a secure key since we don't have the original password
 	privateKey, err := crypto.DecryptPrivateKey(user.PrivateKey, user.PasswordHash)
 	if err != nil {
-		s.logger.Error("Failed to decrypt private key", "user_id", userID, "error", err)
-		return nil, fmt.Errorf("failed to decrypt private key: %w", err)
+		return nil, nil, fmt.Errorf("failed to decrypt private key: %w", err)
+	}
+
+	return user, privateKey, nil
+}
+
+// newArticle builds, validates, screens and signs an article and uploads
+// it to IPFS, without storing it. The filter verdict is returned with it.
+func (s *ArticleService) newArticle(ctx context.Context, req *domain.ArticleCreateRequest, user *domain.User, privateKey ed25519.PrivateKey, originIP string) (*domain.Article, *domain.FilterVerdict, error) {
+	if req.Circle != "" {
+		return nil, nil, domain.NewValidationError("circle", "circle articles must be published one at a time")
+	}
+	article, verdict, err := s.signedArticle(ctx, req, user, privateKey, originIP)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Serialize article to JSON
+	articleJSON, err := article.ToJSON()
+	if err != nil {
+		s.logger.Ctx(ctx).Error("Failed to serialize article", "article_id", article.ID, "error", err)
+		return nil, nil, fmt.Errorf("failed to serialize article: %w", err)
+	}
+
+	article.CID = s.upload(ctx, articleJSON)
+	s.publishRevision(ctx, article, "")
+	return article, verdict, nil
+}
+
+// signedArticle builds, validates, screens and signs an article
+func (s *ArticleService) signedArticle(ctx context.Context, req *domain.ArticleCreateRequest, user *domain.User, privateKey ed25519.PrivateKey, originIP string) (*domain.Article, *domain.FilterVerdict, error) {
+	media, err := s.resolveMedia(ctx, req.Media)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Create article
@@ -100,6 +449,9 @@ func (s *ArticleService) Create(ctx context.Context, req *domain.ArticleCreateRe
 		Timestamp:    time.Now(),
 		Tags:         req.Tags,
 		Category:     req.Category,
+		Language:     req.Language,
+		Circle:       req.Circle,
+		Media:        media,
 		Version:      1,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
@@ -107,63 +459,87 @@ func (s *ArticleService) Create(ctx context.Context, req *domain.ArticleCreateRe
 
 	// Validate article
 	if err := article.Validate(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-
-	// Sign article
-	if err := s.signer.SignArticle(article, privateKey); err != nil {
-		s.logger.Error("Failed to sign article", "article_id", article.ID, "error", err)
-		return nil, fmt.Errorf("failed to sign article: %w", err)
+	if err := s.checkCategory(article); err != nil {
+		return nil, nil, err
+	}
+	verdict, err := s.screen(article)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Serialize article to JSON
-	articleJSON, err := article.ToJSON()
+	// Sign article
+	_, signSpan := tracing.Start(ctx, "article.sign")
+	err = s.signer.SignArticle(article, privateKey)
+	tracing.End(signSpan, err)
 	if err != nil {
-		s.logger.Error("Failed to serialize article", "article_id", article.ID, "error", err)
-		return nil, fmt.Errorf("failed to serialize article: %w", err)
+		s.logger.Ctx(ctx).Error("Failed to sign article", "article_id", article.ID, "error", err)
+		return nil, nil, fmt.Errorf("failed to sign article: %w", err)
 	}
+	return article, verdict, nil
+}
 
-	// Upload to IPFS
-	cid, err := s.ipfsClient.Add(ctx, articleJSON)
+// upload adds published article data to IPFS and returns its CID. When
+// IPFS is unavailable the data gets a local CID instead.
+func (s *ArticleService) upload(ctx context.Context, data []byte) string {
+	cid, err := s.ipfsClient.Add(ctx, data)
 	if err != nil {
-		s.logger.Warn("Failed to upload to IPFS - falling back to local storage", "error", err)
+		s.logger.Ctx(ctx).Warn("Failed to upload to IPFS - falling back to local storage", "error", err)
 		// Generate a local deterministic content ID (sha256)
-		hash := sha256.Sum256(articleJSON)
+		hash := sha256.Sum256(data)
 		cid = "local-" + hex.EncodeToString(hash[:])
 	}
+	return cid
+}
 
-	article.CID = cid
-
-	// Store in database
-	if err := s.articleRepo.Create(ctx, article); err != nil {
-		s.logger.Error("Failed to store article", "article_id", article.ID, "error", err)
-		return nil, fmt.Errorf("failed to store article: %w", err)
-	}
-
-	// Broadcast to P2P network
-	if s.broadcaster != nil {
-		go func() {
-			if err := s.broadcaster.BroadcastArticle("new", article); err != nil {
-				s.logger.Warn("Failed to broadcast article", "article_id", article.ID, "error", err)
-			}
-		}()
+// republish uploads a revision's JSON as newArticle does the first
+// revision's, so the revision's CID and revision node point at its own
+// content. Earlier CIDs keep resolving to the article.
+func (s *ArticleService) republish(ctx context.Context, article *domain.Article) error {
+	published := *article
+	published.CID, published.NodeCID = "", ""
+	articleJSON, err := published.ToJSON()
+	if err != nil {
+		s.logger.Ctx(ctx).Error("Failed to serialize article", "article_id", article.ID, "error", err)
+		return fmt.Errorf("failed to serialize article: %w", err)
 	}
+	article.CID = s.upload(ctx, articleJSON)
+	return nil
+}
 
-	// Index for search
-	if s.indexer != nil {
-		if err := s.indexer.IndexArticle(ctx, article); err != nil {
-			s.logger.Warn("Failed to index article", "article_id", article.ID, "error", err)
-			// Don't fail on indexing error
-		}
+// broadcast announces a new or updated article to the P2P network in the
+// background. The announcement is traced under ctx's span but outlives the
+// request.
+func (s *ArticleService) broadcast(ctx context.Context, msgType string, article *domain.Article) {
+	s.provide(article)
+	if s.broadcaster == nil {
+		return
 	}
-
-	s.logger.Info("Article created successfully",
-		"article_id", article.ID,
-		"cid", cid,
-		"author", user.Username,
+	_, span := tracing.StartKind(context.WithoutCancel(ctx), trace.SpanKindProducer, "article.broadcast",
+		attribute.String("article.id", article.ID),
+		attribute.String("broadcast.type", msgType),
 	)
+	go func() {
+		err := s.broadcaster.BroadcastArticle(msgType, article)
+		if err != nil {
+			s.logger.Warn("Failed to broadcast article", "article_id", article.ID, "error", err)
+		}
+		tracing.End(span, err)
+	}()
+}
 
-	return article, nil
+// provide lists this node as a DHT provider of the article in the
+// background. Local-only CIDs can't be looked up, so they are skipped.
+func (s *ArticleService) provide(article *domain.Article) {
+	if s.peers == nil || domain.IsLocalCID(article.CID) {
+		return
+	}
+	go func() {
+		if err := s.peers.Provide(context.Background(), article.CID); err != nil {
+			s.logger.Debug("Failed to provide article", "cid", article.CID, "error", err)
+		}
+	}()
 }
 
 // GetByCID retrieves an article by CID (from DB or IPFS)
@@ -171,55 +547,85 @@ func (s *ArticleService) GetByCID(ctx context.Context, cid string) (*domain.Arti
 	// Try to get from database first
 	article, err := s.articleRepo.GetByCID(ctx, cid)
 	if err == nil {
-		s.logger.Debug("Retrieved article from database", "cid", cid)
+		if article.Hidden() || article.InCircle() {
+			return nil, domain.ErrArticleNotFound
+		}
+		s.logger.Ctx(ctx).Debug("Retrieved article from database", "cid", cid)
 		return article, nil
 	}
 
 	if err != domain.ErrArticleNotFound {
-		s.logger.Error("Database error", "cid", cid, "error", err)
+		s.logger.Ctx(ctx).Error("Database error", "cid", cid, "error", err)
 		return nil, err
 	}
 
 	// Not in database, fetch from IPFS
 	// If it's a local-only CID, we can't fetch it from IPFS
-	if len(cid) > 6 && cid[:6] == "local-" {
+	if domain.IsLocalCID(cid) {
 		return nil, domain.ErrArticleNotFound
 	}
 
-	s.logger.Debug("Article not in database, fetching from IPFS", "cid", cid)
+	s.logger.Ctx(ctx).Debug("Article not in database, fetching from IPFS", "cid", cid)
+	return s.catArticle(ctx, cid)
+}
+
+// catArticle retrieves an article from IPFS and verifies its signature.
+// Caching it is left to Fetch.
+func (s *ArticleService) catArticle(ctx context.Context, cid string) (*domain.Article, error) {
 	data, err := s.ipfsClient.Cat(ctx, cid)
 	if err != nil {
-		s.logger.Error("Failed to fetch from IPFS", "cid", cid, "error", err)
+		s.logger.Ctx(ctx).Error("Failed to fetch from IPFS", "cid", cid, "error", err)
+		if errors.Is(err, domain.ErrCIDMismatch) {
+			return nil, domain.ErrCIDMismatch
+		}
 		return nil, domain.ErrArticleNotFound
 	}
 
 	// Parse article
-	article, err = domain.FromJSON(data)
+	article, err := domain.FromJSON(data)
 	if err != nil {
-		s.logger.Error("Failed to parse article JSON", "cid", cid, "error", err)
+		s.logger.Ctx(ctx).Error("Failed to parse article JSON", "cid", cid, "error", err)
 		return nil, fmt.Errorf("failed to parse article: %w", err)
 	}
 
 	// Verify signature
 	if err := s.signer.VerifyArticle(article); err != nil {
-		s.logger.Warn("Article signature verification failed", "cid", cid, "error", err)
+		s.logger.Ctx(ctx).Warn("Article signature verification failed", "cid", cid, "error", err)
 		return nil, domain.ErrInvalidSignature
 	}
 
-	s.logger.Info("Retrieved and verified article from IPFS", "cid", cid)
+	// The CID is assigned after the content is added, so the stored JSON
+	// doesn't carry it. Visibility is only ever set by local moderation.
+	article.CID = cid
+	article.Visibility, article.Labels, article.Classifications = "", nil, nil
 
-	// Optionally cache in database
-	// (we'll skip this for now to keep it simple)
+	s.logger.Ctx(ctx).Info("Retrieved and verified article from IPFS", "cid", cid)
+	return article, nil
+}
 
+// GetLocalByCID retrieves an article by CID from the database only.
+// Articles hidden by moderation or published to a circle are not found.
+func (s *ArticleService) GetLocalByCID(ctx context.Context, cid string) (*domain.Article, error) {
+	article, err := s.articleRepo.GetByCID(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	if article.Hidden() || article.InCircle() {
+		return nil, domain.ErrArticleNotFound
+	}
 	return article, nil
 }
 
-// GetByID retrieves an article by ID
+// GetByID retrieves an article by ID. Articles hidden by moderation or
+// published to a circle are not found.
 func (s *ArticleService) GetByID(ctx context.Context, id string) (*domain.Article, error) {
 	article, err := s.articleRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if article.Hidden() || article.InCircle() {
+		return nil, domain.ErrArticleNotFound
+	}
 
 	return article, nil
 }
@@ -239,13 +645,23 @@ func (s *ArticleService) List(ctx context.Context, filter *domain.ArticleListFil
 
 	articles, total, err := s.articleRepo.List(ctx, filter)
 	if err != nil {
-		s.logger.Error("Failed to list articles", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to list articles", "error", err)
 		return nil, 0, err
 	}
 
 	return articles, total, nil
 }
 
+// ListTags returns the most used tags with their article counts
+func (s *ArticleService) ListTags(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	tags, err := s.articleRepo.ListTags(ctx, limit)
+	if err != nil {
+		s.logger.Ctx(ctx).Error("Failed to list tags", "error", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
 // Update updates an existing article
 func (s *ArticleService) Update(ctx context.Context, id string, req *domain.ArticleUpdateRequest, userID string) (*domain.Article, error) {
 	// Get existing article
@@ -253,6 +669,9 @@ func (s *ArticleService) Update(ctx context.Context, id string, req *domain.Arti
 	if err != nil {
 		return nil, err
 	}
+	if article.Hidden() {
+		return nil, domain.ErrArticleNotFound
+	}
 
 	// Get user
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -278,27 +697,87 @@ func (s *ArticleService) Update(ctx context.Context, id string, req *domain.Arti
 	if req.Category != "" {
 		article.Category = req.Category
 	}
+	if req.Language != "" {
+		article.Language = req.Language
+	}
+	if req.Media != nil {
+		if article.InCircle() && len(req.Media) > 0 {
+			return nil, domain.NewValidationError("media", "media can't be attached to circle articles")
+		}
+		if article.Media, err = s.resolveMedia(ctx, req.Media); err != nil {
+			return nil, err
+		}
+	}
 	article.UpdatedAt = time.Now()
+	article.Version++
 
 	// Validate
 	if err := article.Validate(); err != nil {
 		return nil, err
 	}
+	if err := s.checkCategory(article); err != nil {
+		return nil, err
+	}
+	verdict, err := s.screen(article)
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-sign so every revision in the graph verifies on its own
+	privateKey, err := crypto.DecryptPrivateKey(user.PrivateKey, user.PasswordHash)
+	if err != nil {
+		s.logger.Ctx(ctx).Error("Failed to decrypt private key", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to decrypt private key: %w", err)
+	}
+	if err := s.signer.SignArticle(article, privateKey); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to sign article", "article_id", article.ID, "error", err)
+		return nil, fmt.Errorf("failed to sign article: %w", err)
+	}
+	// Circle revisions are sealed again; they have no public revision graph
+	var sealed *domain.SealedArticle
+	if article.InCircle() {
+		if sealed, err = s.seal(ctx, article, user, privateKey); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.republish(ctx, article); err != nil {
+			return nil, err
+		}
+		s.publishRevision(ctx, article, article.NodeCID)
+	}
 
 	// Update in database
 	if err := s.articleRepo.Update(ctx, article); err != nil {
-		s.logger.Error("Failed to update article", "article_id", id, "error", err)
+		s.logger.Ctx(ctx).Error("Failed to update article", "article_id", id, "error", err)
 		return nil, fmt.Errorf("failed to update article: %w", err)
 	}
+	s.trackPins(ctx, article)
 
-	// Update search index
-	if s.indexer != nil {
+	// A revision a filter list quarantined leaves search and waits for moderators
+	if article.Hidden() {
+		if s.indexer != nil {
+			if err := s.indexer.DeleteArticle(ctx, article.ID); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to remove quarantined article from search", "article_id", id, "error", err)
+			}
+		}
+		s.quarantine(ctx, article, verdict)
+		return article, nil
+	}
+	if sealed != nil {
+		s.circles.Share(ctx, article.CID, sealed)
+		s.logger.Ctx(ctx).Info("Circle article updated successfully", "article_id", id, "circle", article.Circle)
+		return article, nil
+	}
+	s.broadcast(ctx, "update", article)
+
+	// Update search index; unlisted articles stay out of it
+	if s.indexer != nil && article.Listed() {
 		if err := s.indexer.UpdateArticle(ctx, article); err != nil {
-			s.logger.Warn("Failed to update article index", "article_id", id, "error", err)
+			s.logger.Ctx(ctx).Warn("Failed to update article index", "article_id", id, "error", err)
 		}
 	}
 
-	s.logger.Info("Article updated successfully", "article_id", id)
+	s.logger.Ctx(ctx).Info("Article updated successfully", "article_id", id)
 
 	return article, nil
 }
@@ -312,7 +791,7 @@ func (s *ArticleService) Delete(ctx context.Context, id string, userID string) e
 	}
 
 	// Get user
-	user, err := s.userRepo.GetByID(ctx, userID)
+	user, privateKey, err := loadSigningKey(ctx, s.userRepo, userID)
 	if err != nil {
 		return err
 	}
@@ -322,31 +801,54 @@ func (s *ArticleService) Delete(ctx context.Context, id string, userID string) e
 		return domain.ErrForbidden
 	}
 
+	// Sign the deletion, so a replicated store can prove it to peers
+	deletion := &domain.ArticleDeletion{ArticleID: id, AuthorPubKey: user.PublicKey, Timestamp: time.Now().Unix()}
+	if err := s.signer.SignArticleDeletion(deletion, privateKey); err != nil {
+		return err
+	}
+	ctx = domain.WithArticleDeletion(ctx, deletion)
+
 	// Delete from database
 	if err := s.articleRepo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete article", "article_id", id, "error", err)
+		s.logger.Ctx(ctx).Error("Failed to delete article", "article_id", id, "error", err)
 		return fmt.Errorf("failed to delete article: %w", err)
 	}
 
 	// Delete from search index
 	if s.indexer != nil {
 		if err := s.indexer.DeleteArticle(ctx, id); err != nil {
-			s.logger.Warn("Failed to delete article from index", "article_id", id, "error", err)
+			s.logger.Ctx(ctx).Warn("Failed to delete article from index", "article_id", id, "error", err)
 		}
 	}
 
+	if s.pins != nil {
+		s.pins.Release(ctx, id)
+	}
+
 	// Optionally unpin from IPFS
 	if article.CID != "" {
 		if err := s.ipfsClient.Unpin(ctx, article.CID); err != nil {
-			s.logger.Warn("Failed to unpin article from IPFS", "cid", article.CID, "error", err)
+			s.logger.Ctx(ctx).Warn("Failed to unpin article from IPFS", "cid", article.CID, "error", err)
 		}
 	}
 
-	s.logger.Info("Article deleted successfully", "article_id", id)
+	s.logger.Ctx(ctx).Info("Article deleted successfully", "article_id", id)
 
 	return nil
 }
 
+// trackPins records the article's content and latest revision node in the
+// pin ledger
+func (s *ArticleService) trackPins(ctx context.Context, article *domain.Article) {
+	if s.pins == nil {
+		return
+	}
+	s.pins.Track(ctx, article.CID, article.ID)
+	if article.NodeCID != "" {
+		s.pins.Track(ctx, article.NodeCID, article.ID)
+	}
+}
+
 // VerifySignature verifies an article's signature
 func (s *ArticleService) VerifySignature(ctx context.Context, cid string) (bool, error) {
 	article, err := s.GetByCID(ctx, cid)
@@ -364,40 +866,177 @@ func (s *ArticleService) VerifySignature(ctx context.Context, cid string) (bool,
 // HandleIncomingArticle processes an article received from the P2P network
 // It verifies the signature and persists it if it's new.
 func (s *ArticleService) HandleIncomingArticle(article *domain.Article) error {
-	s.logger.Info("Received article from P2P network", "article_id", article.ID, "cid", article.CID)
+	// We use a background context because this is event-driven
+	return s.IngestArticle(context.Background(), article)
+}
+
+// IngestArticle verifies, checks and stores an article received from a
+// peer, or a newer revision of one held here, and indexes it
+func (s *ArticleService) IngestArticle(ctx context.Context, article *domain.Article) error {
+	s.logger.Ctx(ctx).Info("Received article from P2P network", "article_id", article.ID, "cid", article.CID)
 
 	// 1. Check if we already have it
-	_, err := s.articleRepo.GetByID(context.Background(), article.ID)
+	existing, err := s.articleRepo.GetByID(ctx, article.ID)
 	if err == nil {
-		// Already exists, ignore
-		return nil
+		// Only a newer revision signed by the same author replaces it
+		if article.Version <= existing.Version || article.AuthorPubKey != existing.AuthorPubKey {
+			return nil
+		}
+		if err := s.signer.VerifyArticle(article); err != nil {
+			s.logger.Ctx(ctx).Warn("Invalid signature on incoming revision", "article_id", article.ID, "error", err)
+			return err
+		}
+		return s.updateRemote(ctx, article, existing)
 	}
 
 	// 2. Verify Signature
 	if err := s.signer.VerifyArticle(article); err != nil {
-		s.logger.Warn("Invalid signature on incoming article", "article_id", article.ID, "error", err)
+		s.logger.Ctx(ctx).Warn("Invalid signature on incoming article", "article_id", article.ID, "error", err)
+		return err
+	}
+
+	// 3. Persist and index
+	return s.saveRemote(ctx, article)
+}
+
+// CheckRemoteArticle applies the checks saveRemote makes to an article a
+// peer returned in search results, without storing it
+func (s *ArticleService) CheckRemoteArticle(ctx context.Context, article *domain.Article) error {
+	if err := s.signer.VerifyArticle(article); err != nil {
+		return err
+	}
+	if err := s.checkCategory(article); err != nil {
+		return err
+	}
+	if err := s.checkBlocklist(ctx, article); err != nil {
+		return err
+	}
+	return s.checkAcceptance(ctx, article)
+}
+
+// HandleEvent makes the service an event sink for articles gossiped on
+// the articles topic
+func (s *ArticleService) HandleEvent(ctx context.Context, event domain.Event) error {
+	article, ok := event.Data.(*domain.Article)
+	if event.Type != domain.EventArticleGossip || !ok || article == nil {
+		return nil
+	}
+	return s.HandleIncomingArticle(article)
+}
+
+// saveRemote checks the category and blocklists, then classifies, screens,
+// stores and indexes a verified article that was published elsewhere, and
+// tells real-time clients about it. Visibility, labels and classifications
+// are local state, so the copy's are never kept.
+func (s *ArticleService) saveRemote(ctx context.Context, article *domain.Article) error {
+	if err := s.checkRemoteCategory(ctx, article); err != nil {
+		return err
+	}
+	if err := s.checkBlocklist(ctx, article); err != nil {
+		return err
+	}
+	if err := s.checkAcceptance(ctx, article); err != nil {
+		return err
+	}
+	article.Visibility = ""
+	if err := s.classify(ctx, article); err != nil {
+		return err
+	}
+	verdict, err := s.screen(article)
+	if err != nil {
+		s.logger.Ctx(ctx).Info("Refusing article matching a filter list", "article_id", article.ID, "error", err)
 		return err
 	}
 
-	// 3. Persist to local DB
-	// We use a background context because this is event-driven
-	ctx := context.Background()
 	if err := s.articleRepo.Create(ctx, article); err != nil {
-		s.logger.Error("Failed to save incoming article", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to save incoming article", "error", err)
 		return err
 	}
+	if article.Hidden() {
+		s.quarantine(ctx, article, verdict)
+		return nil
+	}
+	// Circle articles stay out of search, streams and the DHT
+	if article.InCircle() {
+		s.logger.Ctx(ctx).Info("Saved new circle article from peer", "article_id", article.ID, "circle", article.Circle)
+		return nil
+	}
 
-	// 4. Index for search
 	if s.indexer != nil {
 		if err := s.indexer.IndexArticle(ctx, article); err != nil {
-			s.logger.Warn("Failed to index incoming article", "error", err)
+			s.logger.Ctx(ctx).Warn("Failed to index incoming article", "error", err)
 		}
 	}
 
-	s.logger.Info("Saved new article from peer", "title", article.Title)
+	s.logger.Ctx(ctx).Info("Saved new article from peer", "title", article.Title)
+
+	if s.events != nil {
+		s.events.Publish(domain.EventArticleReceived, article)
+	}
+	s.provide(article)
 	return nil
 }
 
+// updateRemote checks the category and blocklists, then classifies,
+// screens, stores and reindexes a verified revision of an article this
+// node already has, keeping the visibility held for it
+func (s *ArticleService) updateRemote(ctx context.Context, article, existing *domain.Article) error {
+	if err := s.checkRemoteCategory(ctx, article); err != nil {
+		return err
+	}
+	if err := s.checkBlocklist(ctx, article); err != nil {
+		return err
+	}
+	if err := s.checkAcceptance(ctx, article); err != nil {
+		return err
+	}
+	article.Visibility = existing.Visibility
+	if err := s.classify(ctx, article); err != nil {
+		return err
+	}
+	verdict, err := s.screen(article)
+	if err != nil {
+		s.logger.Ctx(ctx).Info("Refusing revision matching a filter list", "article_id", article.ID, "error", err)
+		return err
+	}
+
+	if err := s.articleRepo.Update(ctx, article); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to save incoming revision", "article_id", article.ID, "error", err)
+		return err
+	}
+
+	if s.indexer != nil {
+		var err error
+		if article.Listed() {
+			err = s.indexer.UpdateArticle(ctx, article)
+		} else {
+			err = s.indexer.DeleteArticle(ctx, article.ID)
+		}
+		if err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to reindex incoming revision", "article_id", article.ID, "error", err)
+		}
+	}
+	if article.Hidden() && !existing.Hidden() {
+		s.quarantine(ctx, article, verdict)
+	}
+
+	s.logger.Ctx(ctx).Info("Updated article from peer", "article_id", article.ID, "version", article.Version)
+	return nil
+}
+
+// ArticleRemoved drops an article a peer's replicated delete removed from
+// the store out of the search index and the pin ledger
+func (s *ArticleService) ArticleRemoved(ctx context.Context, article *domain.Article) {
+	if s.indexer != nil {
+		if err := s.indexer.DeleteArticle(ctx, article.ID); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to delete article from index", "article_id", article.ID, "error", err)
+		}
+	}
+	if s.pins != nil {
+		s.pins.Release(ctx, article.ID)
+	}
+}
+
 // HasArticle checks if an article exists in the local database
 func (s *ArticleService) HasArticle(ctx context.Context, id string) bool {
 	_, err := s.articleRepo.GetByID(ctx, id)
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// CreateBatch creates several articles for one user. Each item is
+// validated, signed and uploaded on its own and reported in its result;
+// the items that pass are stored in one repository transaction and
+// indexed in one search write. Only failures affecting every item, such
+// as an unknown user or a failed store, are returned as an error.
+func (s *ArticleService) CreateBatch(ctx context.Context, reqs []domain.ArticleCreateRequest, userID string, originIP string) ([]domain.ArticleBatchResult, error) {
+	user, privateKey, err := s.signingUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.ArticleBatchResult, len(reqs))
+	articles := make([]*domain.Article, 0, len(reqs))
+	verdicts := make(map[string]*domain.FilterVerdict)
+	for i := range reqs {
+		results[i].Index = i
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		article, verdict, err := s.newArticle(ctx, &reqs[i], user, privateKey, originIP)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if article.Hidden() {
+			verdicts[article.ID] = verdict
+		}
+		results[i].Article = article
+		articles = append(articles, article)
+	}
+
+	if len(articles) == 0 {
+		return results, nil
+	}
+
+	if err := s.articleRepo.CreateBatch(ctx, articles); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to store article batch", "articles", len(articles), "error", err)
+		return nil, fmt.Errorf("failed to store articles: %w", err)
+	}
+
+	// Quarantined articles wait for moderators before anyone sees them
+	visible := make([]*domain.Article, 0, len(articles))
+	for _, article := range articles {
+		s.trackPins(ctx, article)
+		if article.Hidden() {
+			s.quarantine(ctx, article, verdicts[article.ID])
+			continue
+		}
+		visible = append(visible, article)
+	}
+
+	if s.indexer != nil && len(visible) > 0 {
+		if err := s.indexer.IndexArticles(ctx, visible); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to index article batch", "articles", len(visible), "error", err)
+		}
+	}
+
+	for _, article := range visible {
+		s.broadcast(ctx, "new", article)
+		if s.events != nil {
+			s.events.Publish(domain.EventArticleCreated, article)
+		}
+	}
+
+	s.logger.Ctx(ctx).Info("Article batch created",
+		"requested", len(reqs),
+		"created", len(articles),
+		"author", user.Username,
+	)
+
+	return results, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// createInCircle publishes an article to a circle. It is signed like any
+// other article, but only a copy sealed with the circle's key is uploaded
+// to IPFS and sent to peers, and it stays out of listings, search and
+// real-time streams.
+func (s *ArticleService) createInCircle(ctx context.Context, req *domain.ArticleCreateRequest, user *domain.User, privateKey ed25519.PrivateKey, originIP string) (*domain.Article, error) {
+	if s.circles == nil {
+		return nil, domain.NewValidationError("circle", "circles are not enabled on this node")
+	}
+	// Attachments are uploaded in the clear, so they can't go to a circle
+	if len(req.Media) > 0 {
+		return nil, domain.NewValidationError("media", "media can't be attached to circle articles")
+	}
+
+	article, verdict, err := s.signedArticle(ctx, req, user, privateKey, originIP)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.seal(ctx, article, user, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.articleRepo.Create(ctx, article); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to store article", "article_id", article.ID, "error", err)
+		return nil, fmt.Errorf("failed to store article: %w", err)
+	}
+	s.trackPins(ctx, article)
+
+	if article.Hidden() {
+		s.quarantine(ctx, article, verdict)
+		return article, nil
+	}
+	s.circles.Share(ctx, article.CID, sealed)
+
+	s.logger.Ctx(ctx).Info("Circle article created successfully",
+		"article_id", article.ID,
+		"cid", article.CID,
+		"circle", article.Circle,
+		"author", user.Username,
+	)
+	return article, nil
+}
+
+// seal encrypts a signed circle article for its circle and uploads the
+// sealed copy, whose CID becomes the article's
+func (s *ArticleService) seal(ctx context.Context, article *domain.Article, user *domain.User, privateKey ed25519.PrivateKey) (*domain.SealedArticle, error) {
+	if s.circles == nil {
+		return nil, domain.NewValidationError("circle", "circles are not enabled on this node")
+	}
+	sealed, err := s.circles.Seal(ctx, article, user, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize sealed article: %w", err)
+	}
+	article.CID = s.upload(ctx, data)
+	return sealed, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ContentClassifier scores an article with the operator's own models, e.g.
+// for nudity, toxicity or disinformation. Scores run from 0 to 1.
+type ContentClassifier interface {
+	Classify(ctx context.Context, article *domain.Article) ([]domain.Classification, error)
+}
+
+// SetClassifier classifies every article received from peers before the
+// filter lists screen it, so lists and search can use the results. With
+// required, an article the classifier fails on is refused and can arrive
+// again later; otherwise it is stored unclassified.
+func (s *ArticleService) SetClassifier(classifier ContentClassifier, required bool) {
+	s.classifier = classifier
+	s.classifyAll = required
+}
+
+// classify replaces the article's classifications with the classifier's.
+// Results without a label or with a score outside 0-1 are dropped.
+func (s *ArticleService) classify(ctx context.Context, article *domain.Article) error {
+	article.Classifications = nil
+	if s.classifier == nil {
+		return nil
+	}
+
+	results, err := s.classifier.Classify(ctx, article)
+	if err != nil {
+		if s.classifyAll {
+			s.logger.Ctx(ctx).Warn("Refusing article the classifier failed on", "article_id", article.ID, "error", err)
+			return fmt.Errorf("failed to classify article: %w", err)
+		}
+		s.logger.Ctx(ctx).Warn("Failed to classify article, storing it unclassified", "article_id", article.ID, "error", err)
+		return nil
+	}
+
+	for _, result := range results {
+		if result.Label == "" || result.Score < 0 || result.Score > 1 {
+			continue
+		}
+		article.Classifications = append(article.Classifications, result)
+	}
+	s.logger.Ctx(ctx).Debug("Classified article", "article_id", article.ID, "classifications", len(article.Classifications))
+	return nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// Each network stage of Fetch gets its own budget, so a slow IPFS lookup
+// doesn't leave nothing for the peers
+const (
+	fetchIPFSTimeout = 20 * time.Second
+	fetchDHTTimeout  = 20 * time.Second
+	fetchPeerTimeout = 10 * time.Second
+)
+
+// Fetch finds an article this node may not have synced yet. It tries the
+// local database, then IPFS, then peers the DHT lists as providers, then
+// connected peers asked directly. Copies from the network must carry a
+// valid signature and the requested CID; the first one found is stored
+// locally before it is returned.
+func (s *ArticleService) Fetch(ctx context.Context, cid string) (*domain.ArticleFetchResult, error) {
+	article, err := s.articleRepo.GetByCID(ctx, cid)
+	if err == nil {
+		if article.Hidden() || article.InCircle() {
+			return nil, domain.ErrArticleNotFound
+		}
+		return &domain.ArticleFetchResult{Article: article, Source: domain.FetchSourceLocal}, nil
+	}
+	if !errors.Is(err, domain.ErrArticleNotFound) {
+		return nil, err
+	}
+
+	// rejected remembers why a copy was turned down, which says more than
+	// not found if nothing better turns up
+	var rejected error
+
+	// Local-only CIDs aren't content addresses, so only peers can have them
+	if !domain.IsLocalCID(cid) {
+		ipfsCtx, cancel := context.WithTimeout(ctx, fetchIPFSTimeout)
+		article, err = s.catArticle(ipfsCtx, cid)
+		cancel()
+		if err == nil {
+			return s.cacheFetched(ctx, &domain.ArticleFetchResult{Article: article, Source: domain.FetchSourceIPFS})
+		}
+		if !errors.Is(err, domain.ErrArticleNotFound) {
+			rejected = err
+		}
+	}
+
+	if s.peers == nil {
+		if rejected != nil {
+			return nil, rejected
+		}
+		return nil, domain.ErrArticleNotFound
+	}
+
+	accept := func(article *domain.Article) error {
+		if article.CID != cid {
+			return domain.ErrCIDMismatch
+		}
+		if err := s.signer.VerifyArticle(article); err != nil {
+			return domain.ErrInvalidSignature
+		}
+		return nil
+	}
+
+	type stage struct {
+		source  string
+		timeout time.Duration
+		fetch   func(context.Context, string, func(*domain.Article) error) (*domain.Article, string, error)
+	}
+	var stages []stage
+	if !domain.IsLocalCID(cid) {
+		stages = append(stages, stage{domain.FetchSourceDHT, fetchDHTTimeout, s.peers.FetchFromProviders})
+	}
+	stages = append(stages, stage{domain.FetchSourcePeer, fetchPeerTimeout, s.peers.FetchFromPeers})
+
+	for _, stage := range stages {
+		stageCtx, cancel := context.WithTimeout(ctx, stage.timeout)
+		article, peerID, err := stage.fetch(stageCtx, cid, accept)
+		cancel()
+		if err == nil {
+			s.logger.Ctx(ctx).Info("Fetched article from peer", "cid", cid, "source", stage.source, "peer", peerID)
+			return s.cacheFetched(ctx, &domain.ArticleFetchResult{Article: article, Source: stage.source, Peer: peerID})
+		}
+		if errors.Is(err, domain.ErrInvalidSignature) || errors.Is(err, domain.ErrCIDMismatch) {
+			rejected = err
+		}
+		s.logger.Ctx(ctx).Debug("Fetch stage found nothing", "cid", cid, "source", stage.source, "error", err)
+	}
+
+	if rejected != nil {
+		return nil, rejected
+	}
+	return nil, domain.ErrArticleNotFound
+}
+
+// cacheFetched stores a verified article unless this node already holds
+// another revision of it, which must not be overwritten by an older one
+func (s *ArticleService) cacheFetched(ctx context.Context, result *domain.ArticleFetchResult) (*domain.ArticleFetchResult, error) {
+	if s.HasArticle(ctx, result.Article.ID) {
+		return result, nil
+	}
+	if err := s.saveRemote(ctx, result.Article); err != nil {
+		return nil, err
+	}
+	result.Cached = true
+	return result, nil
+}
+
+// Seed fetches an article as Fetch does and pins its content and
+// attachments through the pin ledger, so this node keeps serving them.
+// Without a pin ledger the article is stored but nothing is pinned.
+func (s *ArticleService) Seed(ctx context.Context, cid string) (*domain.ArticleFetchResult, error) {
+	result, err := s.Fetch(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	if s.pins == nil {
+		return result, nil
+	}
+
+	article := result.Article
+	pins := []string{article.CID, article.NodeCID}
+	for _, pin := range append(pins, article.AttachmentCIDs()...) {
+		if pin == "" || domain.IsLocalCID(pin) {
+			continue
+		}
+		s.pins.Track(ctx, pin, article.ID)
+		result.Pinned = append(result.Pinned, pin)
+	}
+	return result, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// AuditRecorder appends to the audit log. The actor is taken from ctx
+// (see auth.WithActor); without one the node itself is the actor.
+type AuditRecorder interface {
+	Record(ctx context.Context, action, target string, details map[string]string) error
+}
+
+// roleSettings maps the settings that grant privileges to the role named
+// in role.grant and role.revoke entries
+var roleSettings = map[string]string{
+	"auth.admin_users": "admin",
+	"auth.moderators":  "moderator",
+}
+
+// AuditService keeps the tamper-evident record of what admins and
+// moderators did to the node
+type AuditService struct {
+	repo   repository.AuditRepository
+	logger *logger.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repository.AuditRepository, logger *logger.Logger) *AuditService {
+	return &AuditService{
+		repo:   repo,
+		logger: logger.WithComponent("audit-service"),
+	}
+}
+
+// Record appends an entry attributed to ctx's actor
+func (s *AuditService) Record(ctx context.Context, action, target string, details map[string]string) error {
+	entry := &domain.AuditEntry{
+		Time:      time.Now().UTC(),
+		Actor:     domain.AuditActorSystem,
+		Action:    action,
+		Target:    target,
+		Details:   details,
+		RequestID: logger.RequestID(ctx),
+	}
+	if actor, ok := auth.ActorFrom(ctx); ok {
+		entry.ActorID = actor.ID
+		entry.Actor = actor.Username
+		if entry.Actor == "" {
+			entry.Actor = actor.ID
+		}
+	}
+
+	if err := s.repo.Append(ctx, entry); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to record audit entry", "action", action, "target", target, "error", err)
+		return err
+	}
+	s.logger.Ctx(ctx).Info("Audit entry recorded", "seq", entry.Seq, "action", action, "actor", entry.Actor, "target", target)
+	return nil
+}
+
+// RecordSettings compares the configuration the node is starting with to
+// the one it last started with, recording each changed setting and each
+// admin or moderator added or removed. On the first start only the initial
+// roles are recorded.
+func (s *AuditService) RecordSettings(ctx context.Context, settings map[string]string) error {
+	previous, err := s.repo.Settings(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	for key := range previous {
+		if _, ok := settings[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		was, now := previous[key], settings[key]
+		if was == now {
+			continue
+		}
+		if role, ok := roleSettings[key]; ok {
+			if err := s.recordRoles(ctx, role, splitSetting(was), splitSetting(now)); err != nil {
+				return err
+			}
+			continue
+		}
+		if previous == nil {
+			continue
+		}
+		if err := s.Record(ctx, domain.AuditConfigChange, key, map[string]string{"old": was, "new": now}); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.SaveSettings(ctx, settings)
+}
+
+func (s *AuditService) recordRoles(ctx context.Context, role string, was, now []string) error {
+	for _, user := range now {
+		if !slices.Contains(was, user) {
+			if err := s.Record(ctx, domain.AuditRoleGrant, user, map[string]string{"role": role}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, user := range was {
+		if !slices.Contains(now, user) {
+			if err := s.Record(ctx, domain.AuditRoleRevoke, user, map[string]string{"role": role}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// List retrieves entries newest first
+func (s *AuditService) List(ctx context.Context, filter *domain.AuditListFilter) ([]*domain.AuditEntry, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Verify checks the hash chain from the first entry to the last
+func (s *AuditService) Verify(ctx context.Context) (*domain.AuditVerifyReport, error) {
+	report, err := s.repo.Verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !report.Valid {
+		s.logger.Ctx(ctx).Error("Audit log failed verification", "broken_at", report.BrokenAt, "problem", report.Problem)
+	}
+	return report, nil
+}
+
+// splitSetting undoes the comma-joining of list settings
+func splitSetting(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// PeerDisconnector drops the connections to a peer, e.g. the P2P node
+type PeerDisconnector interface {
+	ClosePeer(id string) error
+}
+
+// blockSet is the lookup built from a subscription's edition in force
+type blockSet struct {
+	name  string
+	dids  map[string]bool
+	peers map[string]bool
+	cids  map[string]bool
+}
+
+// BlocklistService follows blocklists that curators the operator trusts
+// publish on IPFS or IPNS. An edition is applied only when it is signed by
+// the subscription's curator and newer than the one in force. Editions in
+// force are stored, so they apply from startup, before any source is
+// fetched again.
+type BlocklistService struct {
+	repo         repository.BlocklistRepository
+	resolver     NameResolver
+	reader       ContentReader
+	audit        AuditRecorder    // optional; records subscription changes
+	disconnector PeerDisconnector // optional; drops newly blocked peers
+	logger       *logger.Logger
+
+	refreshMu sync.Mutex // serializes fetches and subscription changes
+	mu        sync.RWMutex
+	sets      []*blockSet // ordered by subscription name
+
+	now      func() time.Time
+	stopChan chan struct{}
+}
+
+// NewBlocklistService creates a new blocklist service
+func NewBlocklistService(repo repository.BlocklistRepository, resolver NameResolver, reader ContentReader, logger *logger.Logger) *BlocklistService {
+	return &BlocklistService{
+		repo:     repo,
+		resolver: resolver,
+		reader:   reader,
+		logger:   logger.WithComponent("blocklists"),
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SetAuditLog records every subscription change in the audit log
+func (s *BlocklistService) SetAuditLog(audit AuditRecorder) {
+	s.audit = audit
+}
+
+// SetDisconnector drops connections to peers as soon as a blocklist names
+// them; the connection gater only refuses new ones
+func (s *BlocklistService) SetDisconnector(disconnector PeerDisconnector) {
+	s.disconnector = disconnector
+}
+
+// Load applies the stored edition of every subscription
+func (s *BlocklistService) Load(ctx context.Context) error {
+	subs, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	sets := make([]*blockSet, 0, len(subs))
+	for _, sub := range subs {
+		if sub.List != nil {
+			sets = append(sets, newBlockSet(sub))
+		}
+	}
+
+	s.mu.Lock()
+	s.sets = sets
+	s.mu.Unlock()
+
+	s.logger.Info("Loaded blocklists", "subscriptions", len(subs), "applied", len(sets))
+	return nil
+}
+
+// newBlockSet indexes the edition in force of sub. Authors listed by key
+// are matched by their did:key.
+func newBlockSet(sub *domain.BlocklistSubscription) *blockSet {
+	set := &blockSet{
+		name:  sub.Name,
+		dids:  make(map[string]bool, len(sub.List.DIDs)),
+		peers: make(map[string]bool, len(sub.List.PeerIDs)),
+		cids:  make(map[string]bool, len(sub.List.CIDs)),
+	}
+	for _, key := range sub.List.DIDs {
+		if did := authorDID(key); did != "" {
+			set.dids[did] = true
+		}
+	}
+	for _, id := range sub.List.PeerIDs {
+		set.peers[id] = true
+	}
+	for _, cid := range sub.List.CIDs {
+		set.cids[cid] = true
+	}
+	return set
+}
+
+// authorDID returns the did:key for a did:key or base64 Ed25519 public
+// key, or "" when it is neither
+func authorDID(key string) string {
+	if strings.HasPrefix(key, "did:key:") {
+		return key
+	}
+	pub, err := crypto.PublicKeyFromString(key)
+	if err != nil {
+		return ""
+	}
+	return crypto.DIDKey(pub)
+}
+
+// Start fetches every blocklist now and then every interval until Stop is
+// called
+func (s *BlocklistService) Start(ctx context.Context, interval time.Duration) {
+	s.logger.Ctx(ctx).Info("Following blocklists", "interval", interval.String())
+
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to fetch blocklists", "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to fetch blocklists", "error", err)
+			}
+		case <-s.stopChan:
+			s.logger.Ctx(ctx).Info("Stopping blocklist subscriptions")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the refresh loop
+func (s *BlocklistService) Stop() {
+	close(s.stopChan)
+}
+
+// List returns every subscription, ordered by name
+func (s *BlocklistService) List(ctx context.Context) ([]*domain.BlocklistSubscription, error) {
+	return s.repo.List(ctx)
+}
+
+// Get returns a subscription by name
+func (s *BlocklistService) Get(ctx context.Context, name string) (*domain.BlocklistSubscription, error) {
+	return s.repo.Get(ctx, name)
+}
+
+// Subscribe creates or changes the named subscription and fetches its
+// blocklist. A failed fetch is recorded on the subscription and retried
+// on the next refresh. Changing the source or curator drops the edition
+// in force.
+func (s *BlocklistService) Subscribe(ctx context.Context, name string, req *domain.BlocklistRequest) (*domain.BlocklistSubscription, error) {
+	if _, err := parsePublisherKey(req.Curator); err != nil {
+		return nil, domain.NewValidationError("curator", "curator must be an Ed25519 public key, base64 or did:key")
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	now := s.now().UTC()
+	sub, err := s.repo.Get(ctx, name)
+	switch {
+	case errors.Is(err, domain.ErrBlocklistNotFound):
+		sub = &domain.BlocklistSubscription{Name: name, CreatedAt: now}
+	case err != nil:
+		return nil, err
+	case sub.Source != req.Source || sub.Curator != req.Curator:
+		sub.List, sub.FetchedAt, sub.LastError = nil, nil, ""
+		s.remove(name)
+	}
+	sub.Source = req.Source
+	sub.Curator = req.Curator
+	sub.Description = req.Description
+	sub.UpdatedAt = now
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Blocklist subscription saved", "name", name, "source", sub.Source, "curator", sub.Curator)
+	s.record(ctx, name, map[string]string{"change": "subscribe", "source": sub.Source, "curator": sub.Curator})
+
+	if err := s.refresh(ctx, sub); err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to fetch new blocklist", "name", name, "error", err)
+	}
+	return sub, nil
+}
+
+// Unsubscribe removes the named subscription and stops applying its
+// blocklist
+func (s *BlocklistService) Unsubscribe(ctx context.Context, name string) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	if err := s.repo.Delete(ctx, name); err != nil {
+		return err
+	}
+	s.remove(name)
+
+	s.logger.Ctx(ctx).Info("Blocklist subscription removed", "name", name)
+	s.record(ctx, name, map[string]string{"change": "unsubscribe"})
+	return nil
+}
+
+// record adds a subscription change to the audit log
+func (s *BlocklistService) record(ctx context.Context, name string, details map[string]string) {
+	if s.audit != nil {
+		s.audit.Record(ctx, domain.AuditBlocklistChange, name, details)
+	}
+}
+
+// Refresh fetches every subscribed blocklist, returning the failures
+// joined. A failure leaves that subscription's edition in force.
+func (s *BlocklistService) Refresh(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	subs, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, sub := range subs {
+		if err := s.refresh(ctx, sub); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RefreshOne fetches the named blocklist now. The outcome is recorded on
+// the returned subscription.
+func (s *BlocklistService) RefreshOne(ctx context.Context, name string) (*domain.BlocklistSubscription, error) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	sub, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refresh(ctx, sub); err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to fetch blocklist", "name", name, "error", err)
+	}
+	return sub, nil
+}
+
+// refresh fetches sub's blocklist and applies it if it is a newer
+// edition, recording the outcome on sub. Callers hold refreshMu.
+func (s *BlocklistService) refresh(ctx context.Context, sub *domain.BlocklistSubscription) error {
+	list, err := s.fetch(ctx, sub)
+	if err == nil && sub.List != nil && list.Version <= sub.List.Version {
+		if list.Version < sub.List.Version {
+			err = domain.ErrBlocklistOutdated
+		}
+		list = nil
+	}
+
+	if err != nil {
+		sub.LastError = err.Error()
+	} else {
+		now := s.now().UTC()
+		sub.FetchedAt = &now
+		sub.LastError = ""
+		if list != nil {
+			sub.List = list
+		}
+	}
+	if saveErr := s.repo.Save(ctx, sub); saveErr != nil {
+		s.logger.Ctx(ctx).Error("Failed to save blocklist subscription", "name", sub.Name, "error", saveErr)
+	}
+
+	if list != nil {
+		s.apply(ctx, sub)
+	}
+	return err
+}
+
+// fetch reads the document sub's source points to and checks the
+// curator's signature
+func (s *BlocklistService) fetch(ctx context.Context, sub *domain.BlocklistSubscription) (*domain.Blocklist, error) {
+	curator, err := parsePublisherKey(sub.Curator)
+	if err != nil {
+		return nil, err
+	}
+
+	cid, fixed := strings.CutPrefix(sub.Source, "/ipfs/")
+	if !fixed {
+		path, err := s.resolver.Resolve(ctx, sub.Source)
+		if err != nil {
+			return nil, err
+		}
+		cid = strings.TrimPrefix(path, "/ipfs/")
+	}
+	data, err := s.reader.Cat(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	var list domain.Blocklist
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("invalid blocklist: %w", err)
+	}
+	content, err := list.SignableContent()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := crypto.Verify(content, list.Signature, curator); err != nil || !ok {
+		return nil, domain.ErrBlocklistSignature
+	}
+	return &list, nil
+}
+
+// apply puts sub's edition in force and drops connections to the peers
+// it lists
+func (s *BlocklistService) apply(ctx context.Context, sub *domain.BlocklistSubscription) {
+	set := newBlockSet(sub)
+
+	s.mu.Lock()
+	sets := slices.DeleteFunc(slices.Clone(s.sets), func(b *blockSet) bool { return b.name == sub.Name })
+	sets = append(sets, set)
+	slices.SortFunc(sets, func(a, b *blockSet) int { return strings.Compare(a.name, b.name) })
+	s.sets = sets
+	s.mu.Unlock()
+
+	s.logger.Ctx(ctx).Info("Applied blocklist",
+		"name", sub.Name,
+		"version", sub.List.Version,
+		"dids", len(set.dids),
+		"peers", len(set.peers),
+		"cids", len(set.cids),
+	)
+
+	if s.disconnector == nil {
+		return
+	}
+	for id := range set.peers {
+		if err := s.disconnector.ClosePeer(id); err != nil {
+			s.logger.Ctx(ctx).Debug("Failed to disconnect blocked peer", "peer_id", id, "error", err)
+		}
+	}
+}
+
+// remove stops applying the named subscription's blocklist
+func (s *BlocklistService) remove(name string) {
+	s.mu.Lock()
+	s.sets = slices.DeleteFunc(slices.Clone(s.sets), func(b *blockSet) bool { return b.name == name })
+	s.mu.Unlock()
+}
+
+// PeerBlocked reports whether any blocklist in force names the peer
+func (s *BlocklistService) PeerBlocked(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, set := range s.sets {
+		if set.peers[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// ArticleBlocked returns the first subscription whose blocklist names the
+// article's author or CID
+func (s *BlocklistService) ArticleBlocked(article *domain.Article) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.sets) == 0 {
+		return "", false
+	}
+
+	did := authorDID(article.AuthorPubKey)
+	for _, set := range s.sets {
+		if set.cids[article.CID] || did != "" && set.dids[did] {
+			return set.name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,557 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// CircleBroadcaster shares circle key messages and sealed articles with
+// peers
+type CircleBroadcaster interface {
+	BroadcastCircleKeys(msg *domain.CircleKeyMessage) error
+	BroadcastSealedArticle(cid string, sealed *domain.SealedArticle) error
+}
+
+// CircleArticleReceiver stores circle articles opened from sealed copies
+// received from peers, e.g. the article service
+type CircleArticleReceiver interface {
+	HandleIncomingArticle(article *domain.Article) error
+}
+
+// CircleService manages circles: groups whose articles are encrypted for
+// their members. Each epoch's key is random and reaches members wrapped
+// for their X25519 key in a key message the owner signs; membership
+// changes rotate it. Key messages and sealed articles travel on the
+// circles topic, and only nodes with a member can open them.
+type CircleService struct {
+	repo        repository.CircleRepository
+	userRepo    repository.UserRepository
+	articleRepo repository.ArticleRepository
+	signer      *auth.ArticleSigner
+	broadcaster CircleBroadcaster     // optional; shares keys and sealed articles with peers
+	receiver    CircleArticleReceiver // optional; stores sealed articles from peers
+	mu          sync.Mutex            // serialises membership changes and incoming key messages
+	logger      *logger.Logger
+}
+
+// NewCircleService creates a new circle service
+func NewCircleService(
+	repo repository.CircleRepository,
+	userRepo repository.UserRepository,
+	articleRepo repository.ArticleRepository,
+	signer *auth.ArticleSigner,
+	logger *logger.Logger,
+) *CircleService {
+	return &CircleService{
+		repo:        repo,
+		userRepo:    userRepo,
+		articleRepo: articleRepo,
+		signer:      signer,
+		logger:      logger.WithComponent("circle-service"),
+	}
+}
+
+// SetBroadcaster publishes key messages and sealed articles made on this
+// node to peers
+func (s *CircleService) SetBroadcaster(broadcaster CircleBroadcaster) {
+	s.broadcaster = broadcaster
+}
+
+// SetReceiver stores the circle articles opened from peers' sealed copies
+func (s *CircleService) SetReceiver(receiver CircleArticleReceiver) {
+	s.receiver = receiver
+}
+
+// KeyCard returns the user's signed key card, which they share with
+// circle owners on other nodes
+func (s *CircleService) KeyCard(ctx context.Context, userID string) (*domain.CircleKeyCard, error) {
+	user, privateKey, err := loadSigningKey(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := memberFor(user, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &domain.CircleKeyCard{Username: member.Username, PublicKey: member.PublicKey, EncryptionKey: member.EncryptionKey}
+	if err := s.signer.SignCircleKeyCard(card, privateKey); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// Create creates a circle owned by the user and distributes its first key
+func (s *CircleService) Create(ctx context.Context, userID string, req *domain.CircleCreateRequest) (*domain.Circle, error) {
+	user, privateKey, err := loadSigningKey(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > domain.MaxCircleNameLength {
+		return nil, domain.NewValidationError("name", "name is required and must be at most 100 characters")
+	}
+	if len(req.Members) >= domain.MaxCircleMembers {
+		return nil, domain.NewValidationError("members", fmt.Sprintf("maximum %d members allowed", domain.MaxCircleMembers))
+	}
+
+	owner, err := memberFor(user, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	circle := &domain.Circle{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Owner:     user.Username,
+		OwnerKey:  user.PublicKey,
+		Members:   []domain.CircleMember{owner},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for i := range req.Members {
+		member, err := s.resolveMember(ctx, &req.Members[i])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := circle.Member(member.Username); !ok {
+			circle.Members = append(circle.Members, member)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rotate(ctx, circle, privateKey); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Circle created", "circle_id", circle.ID, "owner", user.Username, "members", len(circle.Members))
+	return circle, nil
+}
+
+// AddMember adds a member to the user's circle and rotates its key
+func (s *CircleService) AddMember(ctx context.Context, id, userID string, req *domain.CircleMemberRequest) (*domain.Circle, error) {
+	member, err := s.resolveMember(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	circle, privateKey, err := s.ownedCircle(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := circle.Member(member.Username); ok {
+		return nil, domain.ErrCircleMemberExists
+	}
+	if len(circle.Members) >= domain.MaxCircleMembers {
+		return nil, domain.NewValidationError("members", fmt.Sprintf("maximum %d members allowed", domain.MaxCircleMembers))
+	}
+
+	circle.Members = append(circle.Members, member)
+	if err := s.rotate(ctx, circle, privateKey); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Circle member added", "circle_id", id, "member", member.Username, "epoch", circle.Epoch)
+	return circle, nil
+}
+
+// RemoveMember removes a member from the user's circle and rotates its
+// key, so they can't read articles published from now on
+func (s *CircleService) RemoveMember(ctx context.Context, id, userID, username string) (*domain.Circle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	circle, privateKey, err := s.ownedCircle(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if username == circle.Owner {
+		return nil, domain.NewValidationError("username", "the owner can't be removed from their circle")
+	}
+	i := slices.IndexFunc(circle.Members, func(m domain.CircleMember) bool { return m.Username == username })
+	if i < 0 {
+		return nil, domain.ErrNotCircleMember
+	}
+
+	circle.Members = slices.Delete(circle.Members, i, i+1)
+	if err := s.rotate(ctx, circle, privateKey); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Circle member removed", "circle_id", id, "member", username, "epoch", circle.Epoch)
+	return circle, nil
+}
+
+// Get returns a circle the user is a member of. Other users get
+// ErrCircleNotFound.
+func (s *CircleService) Get(ctx context.Context, id, userID string) (*domain.Circle, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	circle, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !circle.IsMember(user.Username, user.PublicKey) {
+		return nil, domain.ErrCircleNotFound
+	}
+	return circle, nil
+}
+
+// List returns the circles the user is a member of
+func (s *CircleService) List(ctx context.Context, userID string) ([]*domain.Circle, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	circles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mine := make([]*domain.Circle, 0, len(circles))
+	for _, circle := range circles {
+		if circle.IsMember(user.Username, user.PublicKey) {
+			mine = append(mine, circle)
+		}
+	}
+	return mine, nil
+}
+
+// Articles lists a circle's articles held here, newest first, for one of
+// its members
+func (s *CircleService) Articles(ctx context.Context, id, userID string, page, limit int) ([]*domain.Article, int, error) {
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return nil, 0, err
+	}
+	return s.articleRepo.List(ctx, &domain.ArticleListFilter{Circle: id, Page: page, Limit: limit})
+}
+
+// Seal encrypts a signed article for its circle with the current epoch's
+// key. The author must be a member.
+func (s *CircleService) Seal(ctx context.Context, article *domain.Article, user *domain.User, privateKey ed25519.PrivateKey) (*domain.SealedArticle, error) {
+	circle, err := s.repo.Get(ctx, article.Circle)
+	if err != nil {
+		if errors.Is(err, domain.ErrCircleNotFound) {
+			return nil, domain.NewValidationError("circle", "unknown circle")
+		}
+		return nil, err
+	}
+	if !circle.IsMember(user.Username, user.PublicKey) {
+		return nil, domain.ErrNotCircleMember
+	}
+	key, err := s.epochKey(ctx, circle.ID, circle.Epoch, user.PublicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := article.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize article: %w", err)
+	}
+	sealed := &domain.SealedArticle{CircleID: circle.ID, Epoch: circle.Epoch}
+	if sealed.Ciphertext, err = crypto.Seal(key, plaintext, sealed.AAD()); err != nil {
+		return nil, fmt.Errorf("failed to seal article: %w", err)
+	}
+	return sealed, nil
+}
+
+// Share sends a sealed article, stored under cid, to peers in the
+// background
+func (s *CircleService) Share(ctx context.Context, cid string, sealed *domain.SealedArticle) {
+	if s.broadcaster == nil {
+		return
+	}
+	go func() {
+		if err := s.broadcaster.BroadcastSealedArticle(cid, sealed); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to broadcast sealed article", "circle_id", sealed.CircleID, "cid", cid, "error", err)
+		}
+	}()
+}
+
+// HandleIncomingKeys verifies and records a key message received from a
+// peer. Messages for circles this node has no member in are ignored, as
+// are ones no newer than the epoch held. A circle's owner key never
+// changes.
+func (s *CircleService) HandleIncomingKeys(ctx context.Context, msg *domain.CircleKeyMessage) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	if err := s.signer.VerifyCircleKeys(msg); err != nil {
+		s.logger.Ctx(ctx).Warn("Invalid signature on incoming circle keys", "circle_id", msg.CircleID, "error", err)
+		return err
+	}
+	if !msg.Circle().IsMember(msg.Owner, msg.OwnerKey) {
+		return domain.NewValidationError("members", "the owner must be a member")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated := time.Unix(msg.Timestamp, 0)
+	created := updated
+	existing, err := s.repo.Get(ctx, msg.CircleID)
+	switch {
+	case err == nil:
+		if existing.OwnerKey != msg.OwnerKey {
+			s.logger.Ctx(ctx).Warn("Circle keys signed by another owner", "circle_id", msg.CircleID, "owner", msg.Owner)
+			return domain.ErrNotCircleOwner
+		}
+		if existing.Epoch >= msg.Epoch {
+			return nil
+		}
+		created = existing.CreatedAt
+	case errors.Is(err, domain.ErrCircleNotFound):
+		if !s.hasLocalMember(ctx, msg.Members) {
+			return nil
+		}
+	default:
+		return err
+	}
+
+	if err := s.repo.SaveKeys(ctx, msg); err != nil {
+		return err
+	}
+	circle := msg.Circle()
+	circle.CreatedAt, circle.UpdatedAt = created, updated
+	if err := s.repo.Save(ctx, circle); err != nil {
+		return err
+	}
+
+	s.logger.Ctx(ctx).Info("Recorded circle keys from peer", "circle_id", msg.CircleID, "epoch", msg.Epoch)
+	return nil
+}
+
+// HandleIncomingSealed opens a sealed article received from a peer with
+// the key of a member on this node and hands it to the receiver, which
+// verifies and stores it. Articles for circles unknown here are ignored.
+func (s *CircleService) HandleIncomingSealed(ctx context.Context, cid string, sealed *domain.SealedArticle) error {
+	if _, err := s.repo.Get(ctx, sealed.CircleID); err != nil {
+		if errors.Is(err, domain.ErrCircleNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	article, err := s.open(ctx, sealed)
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to open sealed article", "circle_id", sealed.CircleID, "epoch", sealed.Epoch, "error", err)
+		return err
+	}
+	if article.Circle != sealed.CircleID {
+		return domain.NewValidationError("circle", "sealed article is signed for another circle")
+	}
+	article.CID = cid
+
+	if s.receiver == nil {
+		return nil
+	}
+	return s.receiver.HandleIncomingArticle(article)
+}
+
+// open decrypts a sealed article with the key of any member on this node
+// who held the epoch's key
+func (s *CircleService) open(ctx context.Context, sealed *domain.SealedArticle) (*domain.Article, error) {
+	keys, err := s.repo.GetKeys(ctx, sealed.CircleID, sealed.Epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range keys.Members {
+		user, err := s.userRepo.GetByUsername(ctx, member.Username)
+		if err != nil || user.PublicKey != member.PublicKey {
+			continue
+		}
+		_, privateKey, err := loadSigningKey(ctx, s.userRepo, user.ID)
+		if err != nil {
+			continue
+		}
+		key, err := s.epochKey(ctx, sealed.CircleID, sealed.Epoch, user.PublicKey, privateKey)
+		if err != nil {
+			continue
+		}
+		plaintext, err := crypto.Open(key, sealed.Ciphertext, sealed.AAD())
+		if err != nil {
+			return nil, err
+		}
+		return domain.FromJSON(plaintext)
+	}
+	return nil, domain.ErrCircleKeyUnavailable
+}
+
+// epochKey unwraps the member's copy of a circle's key for epoch
+func (s *CircleService) epochKey(ctx context.Context, circleID string, epoch int, publicKey string, privateKey ed25519.PrivateKey) ([]byte, error) {
+	keys, err := s.repo.GetKeys(ctx, circleID, epoch)
+	if err != nil {
+		return nil, err
+	}
+	grant, ok := keys.Grant(publicKey)
+	if !ok {
+		return nil, domain.ErrNotCircleMember
+	}
+	encryptionKey, err := crypto.EncryptionKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.UnwrapKey(encryptionKey, grant.Ephemeral, grant.Key, domain.CircleKeyAAD(circleID, epoch, publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap circle key: %w", err)
+	}
+	return key, nil
+}
+
+// rotate starts a new epoch: a fresh key wrapped for every member, in a
+// key message signed by the owner. The message and circle are saved and
+// the message is broadcast. Callers hold s.mu.
+func (s *CircleService) rotate(ctx context.Context, circle *domain.Circle, ownerKey ed25519.PrivateKey) error {
+	key, err := crypto.NewSecretKey()
+	if err != nil {
+		return err
+	}
+
+	epoch := circle.Epoch + 1
+	msg := &domain.CircleKeyMessage{
+		CircleID:  circle.ID,
+		Name:      circle.Name,
+		Owner:     circle.Owner,
+		OwnerKey:  circle.OwnerKey,
+		Epoch:     epoch,
+		Members:   circle.Members,
+		Grants:    make([]domain.CircleKeyGrant, 0, len(circle.Members)),
+		Timestamp: time.Now().Unix(),
+	}
+	for _, member := range circle.Members {
+		recipient, err := crypto.EncryptionKeyFromString(member.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("member %s: %w", member.Username, err)
+		}
+		ephemeral, wrapped, err := crypto.WrapKey(recipient, key, domain.CircleKeyAAD(circle.ID, epoch, member.PublicKey))
+		if err != nil {
+			return err
+		}
+		msg.Grants = append(msg.Grants, domain.CircleKeyGrant{Member: member.PublicKey, Ephemeral: ephemeral, Key: wrapped})
+	}
+	if err := s.signer.SignCircleKeys(msg, ownerKey); err != nil {
+		return err
+	}
+
+	if err := s.repo.SaveKeys(ctx, msg); err != nil {
+		return fmt.Errorf("failed to save circle keys: %w", err)
+	}
+	circle.Epoch = epoch
+	circle.UpdatedAt = time.Now()
+	if err := s.repo.Save(ctx, circle); err != nil {
+		return fmt.Errorf("failed to save circle: %w", err)
+	}
+
+	if s.broadcaster != nil {
+		go func() {
+			if err := s.broadcaster.BroadcastCircleKeys(msg); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to broadcast circle keys", "circle_id", circle.ID, "error", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// ownedCircle loads a circle the user owns along with their signing key.
+// Circles they aren't in aren't found.
+func (s *CircleService) ownedCircle(ctx context.Context, id, userID string) (*domain.Circle, ed25519.PrivateKey, error) {
+	user, privateKey, err := loadSigningKey(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	circle, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !circle.IsMember(user.Username, user.PublicKey) {
+		return nil, nil, domain.ErrCircleNotFound
+	}
+	if circle.Owner != user.Username || circle.OwnerKey != user.PublicKey {
+		return nil, nil, domain.ErrNotCircleOwner
+	}
+	return circle, privateKey, nil
+}
+
+// resolveMember turns a member request into the member's keys: a local
+// user's derived from their signing key, anyone else's from their signed
+// key card. A card for a username with a local account must carry that
+// account's key.
+func (s *CircleService) resolveMember(ctx context.Context, req *domain.CircleMemberRequest) (domain.CircleMember, error) {
+	if req.Card != nil {
+		if req.Username != "" && req.Username != req.Card.Username {
+			return domain.CircleMember{}, domain.NewValidationError("card", "card is for another user")
+		}
+		if err := s.signer.VerifyCircleKeyCard(req.Card); err != nil {
+			return domain.CircleMember{}, err
+		}
+		if _, err := crypto.EncryptionKeyFromString(req.Card.EncryptionKey); err != nil {
+			return domain.CircleMember{}, domain.NewValidationError("card", err.Error())
+		}
+		user, err := s.userRepo.GetByUsername(ctx, req.Card.Username)
+		switch {
+		case err == nil && user.PublicKey != req.Card.PublicKey:
+			return domain.CircleMember{}, domain.ErrInvalidSignature
+		case err != nil && !errors.Is(err, domain.ErrUserNotFound):
+			return domain.CircleMember{}, err
+		}
+		return req.Card.Member(), nil
+	}
+
+	if req.Username == "" {
+		return domain.CircleMember{}, domain.NewValidationError("members", "each member needs a username or a key card")
+	}
+	user, err := s.userRepo.GetByUsername(ctx, req.Username)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.CircleMember{}, domain.NewValidationError("username", fmt.Sprintf("%s has no account on this node; add them by key card", req.Username))
+		}
+		return domain.CircleMember{}, err
+	}
+	_, privateKey, err := loadSigningKey(ctx, s.userRepo, user.ID)
+	if err != nil {
+		return domain.CircleMember{}, err
+	}
+	return memberFor(user, privateKey)
+}
+
+// hasLocalMember reports whether any of members has an account here
+func (s *CircleService) hasLocalMember(ctx context.Context, members []domain.CircleMember) bool {
+	for _, member := range members {
+		if user, err := s.userRepo.GetByUsername(ctx, member.Username); err == nil && user.PublicKey == member.PublicKey {
+			return true
+		}
+	}
+	return false
+}
+
+// memberFor describes a user as a circle member
+func memberFor(user *domain.User, privateKey ed25519.PrivateKey) (domain.CircleMember, error) {
+	encryptionKey, err := crypto.EncryptionKey(privateKey)
+	if err != nil {
+		return domain.CircleMember{}, err
+	}
+	return domain.CircleMember{
+		Username:      user.Username,
+		PublicKey:     user.PublicKey,
+		EncryptionKey: crypto.EncryptionKeyToString(encryptionKey.PublicKey()),
+	}, nil
+}
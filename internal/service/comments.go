@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// CommentIndexer keeps comments searchable
+type CommentIndexer interface {
+	IndexComment(ctx context.Context, comment *domain.Comment) error
+	DeleteComment(ctx context.Context, commentID string) error
+}
+
+// CommentService handles article comments and replies
+type CommentService struct {
+	commentRepo repository.CommentRepository
+	articleRepo repository.ArticleRepository
+	userRepo    repository.UserRepository
+	indexer     CommentIndexer // optional; makes comments searchable
+	logger      *logger.Logger
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService(
+	commentRepo repository.CommentRepository,
+	articleRepo repository.ArticleRepository,
+	userRepo repository.UserRepository,
+	logger *logger.Logger,
+) *CommentService {
+	return &CommentService{
+		commentRepo: commentRepo,
+		articleRepo: articleRepo,
+		userRepo:    userRepo,
+		logger:      logger.WithComponent("comment-service"),
+	}
+}
+
+// SetIndexer indexes comments for doc_type=comment searches
+func (s *CommentService) SetIndexer(indexer CommentIndexer) {
+	s.indexer = indexer
+}
+
+// Create adds a comment, or a reply when req.ParentID is set, to the
+// article with the given ID or CID
+func (s *CommentService) Create(ctx context.Context, ref string, req *domain.CommentCreateRequest, userID string) (*domain.Comment, error) {
+	article, err := findArticle(ctx, s.articleRepo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, domain.ErrUserNotActive
+	}
+
+	if req.ParentID != "" {
+		parent, err := s.commentRepo.GetByID(ctx, req.ParentID)
+		if err != nil || parent.ArticleID != article.ID {
+			return nil, domain.NewValidationError("parent_id", "parent comment not found on this article")
+		}
+	}
+
+	now := time.Now()
+	comment := &domain.Comment{
+		ID:        uuid.New().String(),
+		ArticleID: article.ID,
+		ParentID:  req.ParentID,
+		Author:    user.Username,
+		Body:      req.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := comment.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to save comment", "article_id", article.ID, "error", err)
+		return nil, err
+	}
+	s.index(ctx, comment)
+
+	s.logger.Ctx(ctx).Info("Comment created", "comment_id", comment.ID, "article_id", article.ID)
+	return comment, nil
+}
+
+// GetByID retrieves a comment by ID
+func (s *CommentService) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	return s.commentRepo.GetByID(ctx, id)
+}
+
+// List retrieves the comments on the article with the given ID or CID,
+// oldest first
+func (s *CommentService) List(ctx context.Context, ref string, page, limit int) ([]*domain.Comment, int, error) {
+	article, err := findArticle(ctx, s.articleRepo, ref)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.commentRepo.ListByArticle(ctx, article.ID, page, limit)
+}
+
+// Update edits a comment's body; only its author may edit it
+func (s *CommentService) Update(ctx context.Context, id string, req *domain.CommentUpdateRequest, userID string) (*domain.Comment, error) {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Author != user.Username {
+		return nil, domain.ErrForbidden
+	}
+
+	comment.Body = req.Body
+	comment.UpdatedAt = time.Now()
+	if err := comment.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.Update(ctx, comment); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to update comment", "comment_id", id, "error", err)
+		return nil, err
+	}
+	s.index(ctx, comment)
+
+	return comment, nil
+}
+
+// Delete removes a comment and every reply beneath it. Authors may delete
+// their own comments; moderators may delete any.
+func (s *CommentService) Delete(ctx context.Context, id, userID string, moderator bool) error {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !moderator {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if comment.Author != user.Username {
+			return domain.ErrForbidden
+		}
+	}
+
+	thread, _, err := s.commentRepo.ListByArticle(ctx, comment.ArticleID, 0, 0)
+	if err != nil {
+		return err
+	}
+	children := make(map[string][]string)
+	for _, c := range thread {
+		if c.ParentID != "" {
+			children[c.ParentID] = append(children[c.ParentID], c.ID)
+		}
+	}
+
+	// Remove the comment and its replies, deepest first
+	var remove func(id string) error
+	remove = func(id string) error {
+		for _, child := range children[id] {
+			if err := remove(child); err != nil {
+				return err
+			}
+		}
+		if err := s.commentRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if s.indexer != nil {
+			if err := s.indexer.DeleteComment(ctx, id); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to delete comment from index", "comment_id", id, "error", err)
+			}
+		}
+		return nil
+	}
+	if err := remove(id); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to delete comment", "comment_id", id, "error", err)
+		return err
+	}
+
+	s.logger.Ctx(ctx).Info("Comment deleted", "comment_id", id, "by_moderator", moderator)
+	return nil
+}
+
+func (s *CommentService) index(ctx context.Context, comment *domain.Comment) {
+	if s.indexer == nil {
+		return
+	}
+	if err := s.indexer.IndexComment(ctx, comment); err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to index comment", "comment_id", comment.ID, "error", err)
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// ErrUnknownEndorser is returned for a peer endorsement whose key doesn't
+// belong to the user it names, as far as this node knows
+var ErrUnknownEndorser = errors.New("endorser key doesn't match a known user or author")
+
+// EndorsementBroadcaster shares signed endorsements with peers
+type EndorsementBroadcaster interface {
+	BroadcastEndorsement(endorsement *domain.Endorsement) error
+}
+
+// EndorsementReputation counts endorsements toward reputation scores
+type EndorsementReputation interface {
+	IsEstablished(did string) bool
+	SetEndorsement(endorser, subject string, active bool)
+}
+
+// EndorsementService lets established users vouch for other identities.
+// Endorsements are signed by the endorser, shared on the endorsements
+// topic and count toward the subject's reputation until revoked.
+type EndorsementService struct {
+	repo        repository.EndorsementRepository
+	articleRepo repository.ArticleRepository
+	userRepo    repository.UserRepository
+	signer      *auth.ArticleSigner
+	reputation  EndorsementReputation
+	broadcaster EndorsementBroadcaster // optional; shares endorsements with peers
+	mu          sync.Mutex             // serialises read-compare-write of a record
+	logger      *logger.Logger
+}
+
+// NewEndorsementService creates a new endorsement service
+func NewEndorsementService(
+	repo repository.EndorsementRepository,
+	articleRepo repository.ArticleRepository,
+	userRepo repository.UserRepository,
+	signer *auth.ArticleSigner,
+	reputation EndorsementReputation,
+	logger *logger.Logger,
+) *EndorsementService {
+	return &EndorsementService{
+		repo:        repo,
+		articleRepo: articleRepo,
+		userRepo:    userRepo,
+		signer:      signer,
+		reputation:  reputation,
+		logger:      logger.WithComponent("endorsement-service"),
+	}
+}
+
+// SetBroadcaster publishes endorsements made on this node to peers
+func (s *EndorsementService) SetBroadcaster(broadcaster EndorsementBroadcaster) {
+	s.broadcaster = broadcaster
+}
+
+// Load applies every stored endorsement to reputation
+func (s *EndorsementService) Load(ctx context.Context) error {
+	endorsements, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	active := 0
+	for _, endorsement := range endorsements {
+		if !endorsement.Revoked {
+			s.reputation.SetEndorsement(endorsement.Endorser, endorsement.Subject, true)
+			active++
+		}
+	}
+
+	s.logger.Info("Loaded endorsements", "active", active)
+	return nil
+}
+
+// Endorse signs, records and broadcasts the user's endorsement of subject,
+// replacing any earlier one of theirs. Only established users may endorse.
+func (s *EndorsementService) Endorse(ctx context.Context, userID, subject string, req *domain.EndorsementRequest) (*domain.Endorsement, error) {
+	return s.publish(ctx, userID, subject, req.Note, false)
+}
+
+// Revoke withdraws the user's endorsement of subject
+func (s *EndorsementService) Revoke(ctx context.Context, userID, subject string) (*domain.Endorsement, error) {
+	return s.publish(ctx, userID, subject, "", true)
+}
+
+// publish signs a new record from the user for subject, records it and
+// broadcasts it
+func (s *EndorsementService) publish(ctx context.Context, userID, subject, note string, revoked bool) (*domain.Endorsement, error) {
+	user, privateKey, err := loadSigningKey(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Records carry second timestamps; keep each one newer than the last
+	timestamp := time.Now().Unix()
+	previous, err := s.repo.Get(ctx, subject, user.Username)
+	switch {
+	case err == nil:
+		timestamp = max(timestamp, previous.Timestamp+1)
+	case !errors.Is(err, domain.ErrEndorsementNotFound):
+		return nil, err
+	}
+	if revoked && (previous == nil || previous.Revoked) {
+		return nil, domain.ErrEndorsementNotFound
+	}
+	if !revoked && !s.reputation.IsEstablished(user.Username) {
+		return nil, domain.ErrNotEstablished
+	}
+
+	endorsement := &domain.Endorsement{
+		Endorser:    user.Username,
+		EndorserDID: user.PublicKey,
+		Subject:     subject,
+		Note:        note,
+		Revoked:     revoked,
+		Timestamp:   timestamp,
+	}
+	if err := endorsement.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.signer.SignEndorsement(endorsement, privateKey); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, endorsement); err != nil {
+		return nil, err
+	}
+
+	if s.broadcaster != nil {
+		go func() {
+			if err := s.broadcaster.BroadcastEndorsement(endorsement); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to broadcast endorsement", "subject", subject, "error", err)
+			}
+		}()
+	}
+
+	s.logger.Ctx(ctx).Info("Endorsement recorded", "endorser", user.Username, "subject", subject, "revoked", revoked)
+	return endorsement, nil
+}
+
+// HandleIncomingEndorsement verifies and records an endorsement received
+// from a peer. It must be signed by the key of the user it names, as known
+// from a local account or an article by that author. Records older than
+// the one held are ignored.
+func (s *EndorsementService) HandleIncomingEndorsement(ctx context.Context, endorsement *domain.Endorsement) error {
+	if err := endorsement.Validate(); err != nil {
+		return err
+	}
+	if err := s.signer.VerifyEndorsement(endorsement); err != nil {
+		s.logger.Ctx(ctx).Warn("Invalid signature on incoming endorsement", "endorser", endorsement.Endorser, "error", err)
+		return err
+	}
+	key, err := s.userKey(ctx, endorsement.Endorser)
+	if err != nil {
+		return err
+	}
+	if key != endorsement.EndorserDID {
+		return ErrUnknownEndorser
+	}
+
+	return s.record(ctx, endorsement)
+}
+
+// List returns the endorsements of subject in effect
+func (s *EndorsementService) List(ctx context.Context, subject string) ([]*domain.Endorsement, error) {
+	endorsements, err := s.repo.ListBySubject(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]*domain.Endorsement, 0, len(endorsements))
+	for _, endorsement := range endorsements {
+		if !endorsement.Revoked {
+			active = append(active, endorsement)
+		}
+	}
+	return active, nil
+}
+
+// record saves endorsement unless a newer record from the same endorser for
+// the same subject is held, and applies it to reputation
+func (s *EndorsementService) record(ctx context.Context, endorsement *domain.Endorsement) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.repo.Get(ctx, endorsement.Subject, endorsement.Endorser)
+	switch {
+	case err == nil && existing.Timestamp >= endorsement.Timestamp:
+		return nil
+	case err != nil && !errors.Is(err, domain.ErrEndorsementNotFound):
+		return err
+	}
+
+	if err := s.repo.Save(ctx, endorsement); err != nil {
+		return err
+	}
+	s.reputation.SetEndorsement(endorsement.Endorser, endorsement.Subject, !endorsement.Revoked)
+	return nil
+}
+
+// userKey finds the public key of username, from a local account or else
+// the newest article by that author held here
+func (s *EndorsementService) userKey(ctx context.Context, username string) (string, error) {
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err == nil {
+		return user.PublicKey, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return "", err
+	}
+
+	articles, _, err := s.articleRepo.ListByAuthor(ctx, username, 1, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(articles) == 0 {
+		return "", ErrUnknownEndorser
+	}
+	return articles[0].AuthorPubKey, nil
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,11 +16,25 @@ import (
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
 
+var (
+	// ErrInvalidManifest is returned when an IPNS name doesn't point at a feed manifest
+	ErrInvalidManifest = errors.New("IPNS name does not point at a feed manifest")
+	// ErrSeedingUnavailable is returned by Seed when no seeder is configured
+	ErrSeedingUnavailable = errors.New("feed seeding is not available on this node")
+)
+
+// ArticleSeeder stores an article by CID and pins it, for mirroring feeds
+type ArticleSeeder interface {
+	Seed(ctx context.Context, cid string) (*domain.ArticleFetchResult, error)
+}
+
 // FeedService handles feed-related business logic
 type FeedService struct {
 	feedRepo    repository.FeedRepository
 	articleRepo repository.ArticleRepository
+	ipfsClient  IPFSClient
 	ipnsManager *ipfs.IPNSManager
+	seeder      ArticleSeeder
 	logger      *logger.Logger
 }
 
@@ -25,18 +42,27 @@ type FeedService struct {
 func NewFeedService(
 	feedRepo repository.FeedRepository,
 	articleRepo repository.ArticleRepository,
+	ipfsClient IPFSClient,
 	ipnsManager *ipfs.IPNSManager,
 	logger *logger.Logger,
 ) *FeedService {
 	return &FeedService{
 		feedRepo:    feedRepo,
 		articleRepo: articleRepo,
+		ipfsClient:  ipfsClient,
 		ipnsManager: ipnsManager,
 		logger:      logger.WithComponent("feed-service"),
 	}
 }
 
-// Create creates a new feed
+// SetSeeder enables Seed, which mirrors remote feeds onto this node
+func (s *FeedService) SetSeeder(seeder ArticleSeeder) {
+	s.seeder = seeder
+}
+
+// Create creates a new feed along with the IPNS key it is published
+// under. A key left behind by an earlier feed of the same name is reused,
+// so the feed keeps its old IPNS address.
 func (s *FeedService) Create(ctx context.Context, req *domain.FeedCreateRequest) (*domain.Feed, error) {
 	// Check if feed name already exists
 	_, err := s.feedRepo.GetByName(ctx, req.Name)
@@ -47,34 +73,42 @@ func (s *FeedService) Create(ctx context.Context, req *domain.FeedCreateRequest)
 		return nil, err
 	}
 
-	// Generate or ensure IPNS key exists
-	keyInfo, err := s.ipnsManager.EnsureKey(ctx, req.Name)
-	if err != nil {
-		s.logger.Error("Failed to ensure IPNS key", "feed_name", req.Name, "error", err)
-		return nil, fmt.Errorf("failed to create IPNS key: %w", err)
-	}
-
-	// Create feed
 	feed := &domain.Feed{
 		ID:           uuid.New().String(),
 		Name:         req.Name,
-		IPNSKey:      keyInfo.Name,
-		IPNSAddress:  fmt.Sprintf("/ipns/%s", keyInfo.ID),
+		IPNSKey:      req.Name,
 		SyncInterval: req.SyncInterval,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
+	// Validate before touching the keystore, so bad names never become keys
 	if err := feed.Validate(); err != nil {
 		return nil, err
 	}
 
+	// Generate or ensure IPNS key exists
+	_, lookupErr := s.ipnsManager.GetKeyID(ctx, feed.IPNSKey)
+	keyInfo, err := s.ipnsManager.EnsureKey(ctx, feed.IPNSKey)
+	if err != nil {
+		s.logger.Ctx(ctx).Error("Failed to ensure IPNS key", "feed_name", req.Name, "error", err)
+		return nil, fmt.Errorf("%w: failed to create key: %v", domain.ErrIPNSKeyFailed, err)
+	}
+	feed.IPNSKey = keyInfo.Name
+	feed.IPNSAddress = fmt.Sprintf("/ipns/%s", keyInfo.ID)
+
 	if err := s.feedRepo.Create(ctx, feed); err != nil {
-		s.logger.Error("Failed to create feed", "feed_name", req.Name, "error", err)
+		s.logger.Ctx(ctx).Error("Failed to create feed", "feed_name", req.Name, "error", err)
+		// Don't leave a key behind that this call generated
+		if lookupErr != nil {
+			if err := s.ipnsManager.RemoveKey(ctx, keyInfo.Name); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to remove IPNS key of failed feed", "feed_name", req.Name, "error", err)
+			}
+		}
 		return nil, fmt.Errorf("failed to create feed: %w", err)
 	}
 
-	s.logger.Info("Feed created successfully", "feed_id", feed.ID, "feed_name", feed.Name)
+	s.logger.Ctx(ctx).Info("Feed created successfully", "feed_id", feed.ID, "feed_name", feed.Name)
 
 	return feed, nil
 }
@@ -99,34 +133,44 @@ func (s *FeedService) Update(ctx context.Context, name string, req *domain.FeedU
 	if req.SyncInterval > 0 {
 		feed.SyncInterval = req.SyncInterval
 	}
+	feed.UpdatedAt = time.Now()
 
 	if err := feed.Validate(); err != nil {
 		return nil, err
 	}
 
 	if err := s.feedRepo.Update(ctx, feed); err != nil {
-		s.logger.Error("Failed to update feed", "feed_name", name, "error", err)
+		s.logger.Ctx(ctx).Error("Failed to update feed", "feed_name", name, "error", err)
 		return nil, fmt.Errorf("failed to update feed: %w", err)
 	}
 
-	s.logger.Info("Feed updated successfully", "feed_name", name)
+	s.logger.Ctx(ctx).Info("Feed updated successfully", "feed_name", name)
 
 	return feed, nil
 }
 
-// Delete deletes a feed
-func (s *FeedService) Delete(ctx context.Context, name string) error {
+// Delete deletes a feed and, unless keepKey is set, its IPNS key. Keeping
+// the key lets a feed created later under the same name publish at the
+// same address. A key that can't be removed is logged, not returned: the
+// feed is gone and its last record expires on its own.
+func (s *FeedService) Delete(ctx context.Context, name string, keepKey bool) error {
 	feed, err := s.feedRepo.GetByName(ctx, name)
 	if err != nil {
 		return err
 	}
 
 	if err := s.feedRepo.Delete(ctx, feed.ID); err != nil {
-		s.logger.Error("Failed to delete feed", "feed_name", name, "error", err)
+		s.logger.Ctx(ctx).Error("Failed to delete feed", "feed_name", name, "error", err)
 		return fmt.Errorf("failed to delete feed: %w", err)
 	}
 
-	s.logger.Info("Feed deleted successfully", "feed_name", name)
+	if !keepKey && feed.IPNSKey != "" {
+		if err := s.ipnsManager.RemoveKey(ctx, feed.IPNSKey); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to remove IPNS key of deleted feed", "feed_name", name, "key_name", feed.IPNSKey, "error", err)
+		}
+	}
+
+	s.logger.Ctx(ctx).Info("Feed deleted successfully", "feed_name", name, "key_kept", keepKey)
 
 	return nil
 }
@@ -160,3 +204,85 @@ func (s *FeedService) GetArticles(ctx context.Context, name string, page, limit
 
 	return articles, total, nil
 }
+
+// ResolveRemote resolves another node's feed IPNS name and fetches the
+// manifest it points at
+func (s *FeedService) ResolveRemote(ctx context.Context, name string) (*domain.RemoteFeed, error) {
+	path, err := s.ipnsManager.Resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ipnsPath, _ := ipfs.NormalizeIPNSName(name)
+
+	cid := strings.TrimPrefix(path, "/ipfs/")
+	data, err := s.ipfsClient.Cat(ctx, cid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed manifest: %w", err)
+	}
+
+	var manifest domain.FeedManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if manifest.Version == "" {
+		return nil, ErrInvalidManifest
+	}
+
+	return &domain.RemoteFeed{
+		Name:       ipnsPath,
+		CID:        cid,
+		Manifest:   &manifest,
+		ResolvedAt: time.Now(),
+		Pubsub:     s.ipnsManager.UsingPubsub(),
+	}, nil
+}
+
+// Seed makes this node a mirror of another node's feed: the name is
+// resolved, and every article its manifest lists is stored and pinned.
+// Articles already stored are only pinned again, which is cheap, so
+// seeding the same feed repeatedly picks up only what changed. With IPNS
+// pubsub on, resolving the name also subscribes the node to its updates.
+func (s *FeedService) Seed(ctx context.Context, name string) (*domain.FeedSeedReport, error) {
+	if s.seeder == nil {
+		return nil, ErrSeedingUnavailable
+	}
+
+	remote, err := s.ResolveRemote(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.FeedSeedReport{
+		Name:        remote.Name,
+		ManifestCID: remote.CID,
+		Articles:    len(remote.Manifest.Articles),
+		Fetched:     []string{},
+		Pubsub:      remote.Pubsub,
+	}
+	for _, cid := range remote.Manifest.Articles {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err := s.seeder.Seed(ctx, cid)
+		if err != nil {
+			report.Failed = append(report.Failed, domain.FeedSeedFailure{CID: cid, Error: err.Error()})
+			continue
+		}
+		if result.Source == domain.FetchSourceLocal || !result.Cached {
+			report.Present++
+		} else {
+			report.Fetched = append(report.Fetched, cid)
+		}
+		report.Pinned += len(result.Pinned)
+	}
+
+	s.logger.Ctx(ctx).Info("Seeded remote feed",
+		"name", remote.Name,
+		"manifest_cid", remote.CID,
+		"articles", report.Articles,
+		"fetched", len(report.Fetched),
+		"failed", len(report.Failed),
+	)
+	return report, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// filterRank orders filter actions so the strongest match wins
+var filterRank = map[string]int{
+	domain.FilterTag:        1,
+	domain.FilterQuarantine: 2,
+	domain.FilterReject:     3,
+}
+
+// compiledFilter is a filter list with its phrases and patterns compiled
+type compiledFilter struct {
+	list     *domain.FilterList
+	matchers []*regexp.Regexp
+	sources  []string // the phrase or pattern behind each matcher
+}
+
+// FilterService screens articles against the operator's filter lists, so a
+// node can enforce local rules on what it publishes, stores and serves
+// without changing code. Lists are stored in the database and compiled in
+// memory; changes take effect for the next article screened.
+type FilterService struct {
+	repo   repository.FilterListRepository
+	audit  AuditRecorder // optional; records list changes
+	mu     sync.RWMutex
+	lists  []*compiledFilter // ordered by name
+	logger *logger.Logger
+}
+
+// NewFilterService creates a new filter service
+func NewFilterService(repo repository.FilterListRepository, logger *logger.Logger) *FilterService {
+	return &FilterService{
+		repo:   repo,
+		logger: logger.WithComponent("filter-service"),
+	}
+}
+
+// SetAuditLog records every list change in the audit log
+func (s *FilterService) SetAuditLog(audit AuditRecorder) {
+	s.audit = audit
+}
+
+// Load compiles every stored list
+func (s *FilterService) Load(ctx context.Context) error {
+	lists, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	compiled := make([]*compiledFilter, 0, len(lists))
+	for _, list := range lists {
+		filter, err := compileFilter(list)
+		if err != nil {
+			s.logger.Warn("Skipping invalid filter list", "list", list.Name, "error", err)
+			continue
+		}
+		compiled = append(compiled, filter)
+	}
+
+	s.mu.Lock()
+	s.lists = compiled
+	s.mu.Unlock()
+
+	s.logger.Info("Loaded filter lists", "lists", len(compiled))
+	return nil
+}
+
+// compileFilter validates list and compiles its matchers. Phrases match
+// whole words, ignoring case.
+func compileFilter(list *domain.FilterList) (*compiledFilter, error) {
+	if err := list.Validate(); err != nil {
+		return nil, err
+	}
+
+	filter := &compiledFilter{list: list}
+	for _, phrase := range list.Phrases {
+		re := regexp.MustCompile(`(?i)(?:^|[^\pL\pN_])` + regexp.QuoteMeta(phrase) + `(?:$|[^\pL\pN_])`)
+		filter.matchers = append(filter.matchers, re)
+		filter.sources = append(filter.sources, phrase)
+	}
+	for _, pattern := range list.Patterns {
+		filter.matchers = append(filter.matchers, regexp.MustCompile(pattern))
+		filter.sources = append(filter.sources, pattern)
+	}
+	return filter, nil
+}
+
+// List returns every filter list, ordered by name
+func (s *FilterService) List(ctx context.Context) ([]*domain.FilterList, error) {
+	return s.repo.List(ctx)
+}
+
+// Get returns a filter list by name
+func (s *FilterService) Get(ctx context.Context, name string) (*domain.FilterList, error) {
+	return s.repo.Get(ctx, name)
+}
+
+// Put creates or replaces the named list
+func (s *FilterService) Put(ctx context.Context, name string, req *domain.FilterListRequest) (*domain.FilterList, error) {
+	list := &domain.FilterList{
+		Name:            name,
+		Description:     req.Description,
+		Categories:      req.Categories,
+		Phrases:         req.Phrases,
+		Patterns:        req.Patterns,
+		Classifications: req.Classifications,
+		MinScore:        req.MinScore,
+		Action:          req.Action,
+		Label:           req.Label,
+		UpdatedAt:       time.Now().UTC(),
+	}
+	if list.Action == domain.FilterTag && list.Label == "" {
+		list.Label = list.Name
+	}
+	filter, err := compileFilter(list)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, list); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	lists := slices.DeleteFunc(slices.Clone(s.lists), func(f *compiledFilter) bool { return f.list.Name == name })
+	lists = append(lists, filter)
+	slices.SortFunc(lists, func(a, b *compiledFilter) int { return strings.Compare(a.list.Name, b.list.Name) })
+	s.lists = lists
+	s.mu.Unlock()
+
+	s.logger.Ctx(ctx).Info("Filter list saved", "list", name, "action", list.Action, "phrases", len(list.Phrases), "patterns", len(list.Patterns), "classifications", len(list.Classifications))
+	s.record(ctx, name, map[string]string{"change": "put", "action": list.Action})
+	return list, nil
+}
+
+// Delete removes the named list
+func (s *FilterService) Delete(ctx context.Context, name string) error {
+	if err := s.repo.Delete(ctx, name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.lists = slices.DeleteFunc(slices.Clone(s.lists), func(f *compiledFilter) bool { return f.list.Name == name })
+	s.mu.Unlock()
+
+	s.logger.Ctx(ctx).Info("Filter list deleted", "list", name)
+	s.record(ctx, name, map[string]string{"change": "delete"})
+	return nil
+}
+
+// record adds a list change to the audit log. The change is saved by now,
+// so a failure is only logged.
+func (s *FilterService) record(ctx context.Context, name string, details map[string]string) {
+	if s.audit != nil {
+		s.audit.Record(ctx, domain.AuditFilterChange, name, details)
+	}
+}
+
+// Screen matches article's title, body, tags and classifications against
+// every list that applies to its category. It returns nil when nothing
+// matches.
+func (s *FilterService) Screen(article *domain.Article) *domain.FilterVerdict {
+	s.mu.RLock()
+	lists := s.lists
+	s.mu.RUnlock()
+	if len(lists) == 0 {
+		return nil
+	}
+
+	text := article.Title + "\n" + article.Body + "\n" + strings.Join(article.Tags, "\n")
+	var verdict *domain.FilterVerdict
+	for _, filter := range lists {
+		if len(filter.list.Categories) > 0 && !slices.Contains(filter.list.Categories, article.Category) {
+			continue
+		}
+		match, ok := filter.match(text, article)
+		if !ok {
+			continue
+		}
+		if verdict == nil {
+			verdict = &domain.FilterVerdict{}
+		}
+		verdict.Matches = append(verdict.Matches, domain.FilterMatch{
+			List:   filter.list.Name,
+			Action: filter.list.Action,
+			Match:  match,
+		})
+		if filterRank[filter.list.Action] > filterRank[verdict.Action] {
+			verdict.Action = filter.list.Action
+		}
+		if filter.list.Action == domain.FilterTag && !slices.Contains(verdict.Labels, filter.list.Label) {
+			verdict.Labels = append(verdict.Labels, filter.list.Label)
+		}
+	}
+	return verdict
+}
+
+// match returns the first phrase, pattern or classification of the list
+// that matches; one is enough
+func (f *compiledFilter) match(text string, article *domain.Article) (string, bool) {
+	for i, re := range f.matchers {
+		if re.MatchString(text) {
+			return f.sources[i], true
+		}
+	}
+
+	minScore := f.list.MinScore
+	if minScore == 0 {
+		minScore = domain.DefaultClassificationScore
+	}
+	for _, label := range f.list.Classifications {
+		if score, ok := article.ClassificationScore(label); ok && score >= minScore {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// Check screens a draft without publishing it
+func (s *FilterService) Check(req *domain.FilterCheckRequest) *domain.FilterVerdict {
+	verdict := s.Screen(&domain.Article{
+		Title:           req.Title,
+		Body:            req.Body,
+		Tags:            req.Tags,
+		Category:        req.Category,
+		Classifications: req.Classifications,
+	})
+	if verdict == nil {
+		return &domain.FilterVerdict{Matches: []domain.FilterMatch{}}
+	}
+	return verdict
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// ErrGCRunning is returned when a garbage collection is already in progress
+var ErrGCRunning = errors.New("garbage collection already running")
+
+// GCStore unpins content and collects garbage on the IPFS daemon
+type GCStore interface {
+	Unpin(ctx context.Context, cid string) error
+	RepoGC(ctx context.Context) (int, error)
+	RepoSize(ctx context.Context) (uint64, error)
+}
+
+// RetentionPolicy decides which ledger entries may be unpinned
+type RetentionPolicy struct {
+	RevisionRetention time.Duration // keep superseded revision nodes this long; 0 keeps them forever
+	ArticleMaxAge     time.Duration // unpin articles older than this; 0 keeps them forever
+	RepoGC            bool          // run repo GC on the daemon after unpinning
+}
+
+// GCService unpins content the retention policy no longer requires and
+// optionally runs repo GC on the daemon. Only CIDs in the pin ledger are
+// ever unpinned, and an article's current content and revision node are
+// kept unless the article itself has expired.
+type GCService struct {
+	pins     repository.PinRepository
+	articles repository.ArticleRepository
+	store    GCStore
+	policy   RetentionPolicy
+	logger   *logger.Logger
+
+	mu         sync.Mutex
+	running    bool
+	lastReport *domain.GCReport
+
+	now      func() time.Time
+	stopChan chan struct{}
+}
+
+// NewGCService creates a new garbage collection service
+func NewGCService(pins repository.PinRepository, articles repository.ArticleRepository, store GCStore, policy RetentionPolicy, logger *logger.Logger) *GCService {
+	return &GCService{
+		pins:     pins,
+		articles: articles,
+		store:    store,
+		policy:   policy,
+		logger:   logger.WithComponent("ipfs-gc"),
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs a collection every interval until Stop is called
+func (s *GCService) Start(ctx context.Context, interval time.Duration) {
+	s.logger.Ctx(ctx).Info("Starting IPFS garbage collection",
+		"interval", interval.String(),
+		"revision_retention", s.policy.RevisionRetention.String(),
+		"article_max_age", s.policy.ArticleMaxAge.String(),
+		"repo_gc", s.policy.RepoGC,
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Run(ctx, false, s.policy.RepoGC); err != nil && err != ErrGCRunning {
+				s.logger.Ctx(ctx).Warn("Scheduled garbage collection failed", "error", err)
+			}
+		case <-s.stopChan:
+			s.logger.Ctx(ctx).Info("Stopping IPFS garbage collection")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the scheduled collection loop
+func (s *GCService) Stop() {
+	close(s.stopChan)
+}
+
+// LastReport returns the report of the most recent run, or nil
+func (s *GCService) LastReport() *domain.GCReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastReport
+}
+
+// Run unpins expired ledger entries and, if repoGC is set, collects the
+// daemon's garbage. A dry run only reports what would be unpinned.
+func (s *GCService) Run(ctx context.Context, dryRun, repoGC bool) (*domain.GCReport, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrGCRunning
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	report := &domain.GCReport{
+		StartedAt: s.now(),
+		DryRun:    dryRun,
+		Unpinned:  []domain.GCUnpin{},
+	}
+
+	candidates, err := s.candidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		if dryRun {
+			report.Unpinned = append(report.Unpinned, candidate)
+			continue
+		}
+		if err := s.store.Unpin(ctx, candidate.CID); err != nil {
+			report.Failed++
+			s.logger.Ctx(ctx).Warn("Failed to unpin expired content", "cid", candidate.CID, "reason", candidate.Reason, "error", err)
+			continue
+		}
+		if err := s.pins.Delete(ctx, candidate.CID); err != nil && err != domain.ErrPinNotFound {
+			s.logger.Ctx(ctx).Warn("Failed to remove pin record", "cid", candidate.CID, "error", err)
+		}
+		report.Unpinned = append(report.Unpinned, candidate)
+	}
+
+	if repoGC && !dryRun {
+		s.collect(ctx, report)
+	}
+
+	report.FinishedAt = s.now()
+	s.logger.Ctx(ctx).Info("Garbage collection complete",
+		"dry_run", dryRun,
+		"unpinned", len(report.Unpinned),
+		"failed", report.Failed,
+		"removed_blocks", report.RemovedBlocks,
+		"reclaimed_bytes", report.Reclaimed,
+	)
+
+	s.mu.Lock()
+	s.lastReport = report
+	s.mu.Unlock()
+	return report, nil
+}
+
+// candidates applies the retention policy to the pin ledger
+func (s *GCService) candidates(ctx context.Context) ([]domain.GCUnpin, error) {
+	records, err := s.pins.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	byRef := make(map[string][]*domain.PinRecord)
+	for _, pin := range records {
+		byRef[pin.Ref] = append(byRef[pin.Ref], pin)
+	}
+
+	now := s.now()
+	var out []domain.GCUnpin
+	for ref, pins := range byRef {
+		if ref == "" {
+			continue
+		}
+
+		article, err := s.articles.GetByID(ctx, ref)
+		if errors.Is(err, domain.ErrArticleNotFound) {
+			for _, pin := range pins {
+				out = append(out, domain.GCUnpin{CID: pin.CID, Ref: ref, Reason: domain.GCReasonOrphaned})
+			}
+			continue
+		}
+		if err != nil {
+			s.logger.Ctx(ctx).Warn("Skipping pins for unreadable article", "article_id", ref, "error", err)
+			continue
+		}
+
+		if s.policy.ArticleMaxAge > 0 && now.Sub(article.Timestamp) > s.policy.ArticleMaxAge {
+			for _, pin := range pins {
+				out = append(out, domain.GCUnpin{CID: pin.CID, Ref: ref, Reason: domain.GCReasonExpired})
+			}
+			continue
+		}
+
+		if s.policy.RevisionRetention <= 0 {
+			continue
+		}
+
+		// A revision is superseded when the next one is recorded; it
+		// expires RevisionRetention after that. Pinned attachments are
+		// not revisions and stay with the article.
+		attachments := make(map[string]bool)
+		for _, cid := range article.AttachmentCIDs() {
+			attachments[cid] = true
+		}
+		var revisions []*domain.PinRecord
+		for _, pin := range pins {
+			if pin.CID != article.CID && !attachments[pin.CID] {
+				revisions = append(revisions, pin)
+			}
+		}
+		sort.Slice(revisions, func(i, j int) bool { return revisions[i].CreatedAt.Before(revisions[j].CreatedAt) })
+		for i := 0; i < len(revisions)-1; i++ {
+			pin := revisions[i]
+			if pin.CID == article.NodeCID {
+				continue
+			}
+			if now.Sub(revisions[i+1].CreatedAt) > s.policy.RevisionRetention {
+				out = append(out, domain.GCUnpin{CID: pin.CID, Ref: ref, Reason: domain.GCReasonSuperseded})
+			}
+		}
+	}
+	return out, nil
+}
+
+// collect runs repo GC and records how much space it freed
+func (s *GCService) collect(ctx context.Context, report *domain.GCReport) {
+	report.RepoGC = true
+
+	before, err := s.store.RepoSize(ctx)
+	if err != nil {
+		report.Warnings = append(report.Warnings, "could not read repo size before GC: "+err.Error())
+	}
+
+	removed, err := s.store.RepoGC(ctx)
+	report.RemovedBlocks = removed
+	if err != nil {
+		report.Warnings = append(report.Warnings, "repo gc: "+err.Error())
+		return
+	}
+
+	after, err := s.store.RepoSize(ctx)
+	if err != nil {
+		report.Warnings = append(report.Warnings, "could not read repo size after GC: "+err.Error())
+		return
+	}
+
+	report.RepoSizeBefore = before
+	report.RepoSizeAfter = after
+	if before > after {
+		report.Reclaimed = before - after
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// ErrOptimizeRunning is returned when an optimization is already in progress
+var ErrOptimizeRunning = errors.New("index optimization already running")
+
+// IndexOptimizer is implemented by indexes that can report fragmentation and compact
+type IndexOptimizer interface {
+	Stats() (*search.IndexStats, error)
+	Optimize(ctx context.Context) (*search.OptimizeReport, error)
+}
+
+// IndexMaintenanceConfig controls when scheduled optimization runs
+type IndexMaintenanceConfig struct {
+	WindowStart      time.Duration // offset from local midnight
+	WindowEnd        time.Duration // before WindowStart means the window spans midnight
+	MinSegments      uint64
+	MinFragmentation float64
+}
+
+// IndexMaintenanceService compacts the search index once per day inside a
+// quiet-hours window, when it is fragmented enough to be worth it
+type IndexMaintenanceService struct {
+	index  IndexOptimizer
+	cfg    IndexMaintenanceConfig
+	logger *logger.Logger
+
+	mu         sync.Mutex
+	running    bool
+	lastRun    time.Time
+	lastReport *search.OptimizeReport
+
+	now      func() time.Time
+	stopChan chan struct{}
+}
+
+// NewIndexMaintenanceService creates a new index maintenance service
+func NewIndexMaintenanceService(index IndexOptimizer, cfg IndexMaintenanceConfig, logger *logger.Logger) *IndexMaintenanceService {
+	return &IndexMaintenanceService{
+		index:    index,
+		cfg:      cfg,
+		logger:   logger.WithComponent("index-maintenance"),
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start checks every interval whether a scheduled optimization is due
+func (s *IndexMaintenanceService) Start(ctx context.Context, interval time.Duration) {
+	s.logger.Ctx(ctx).Info("Starting index maintenance",
+		"window_start", s.cfg.WindowStart.String(),
+		"window_end", s.cfg.WindowEnd.String(),
+		"check_interval", interval.String(),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.RunScheduled(ctx)
+		case <-s.stopChan:
+			s.logger.Ctx(ctx).Info("Stopping index maintenance")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the maintenance loop
+func (s *IndexMaintenanceService) Stop() {
+	close(s.stopChan)
+}
+
+// RunScheduled optimizes the index if we're inside the window, haven't run
+// in the last day, and the index is fragmented. Returns the report, or nil
+// when nothing was done.
+func (s *IndexMaintenanceService) RunScheduled(ctx context.Context) *search.OptimizeReport {
+	now := s.now()
+	if !s.inWindow(now) {
+		return nil
+	}
+
+	s.mu.Lock()
+	recent := !s.lastRun.IsZero() && now.Sub(s.lastRun) < 20*time.Hour
+	s.mu.Unlock()
+	if recent {
+		return nil
+	}
+
+	stats, err := s.index.Stats()
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to read index stats", "error", err)
+		return nil
+	}
+
+	s.logger.Ctx(ctx).Info("Index fragmentation",
+		"file_segments", stats.FileSegments,
+		"fragmentation", stats.Fragmentation,
+		"bytes_on_disk", stats.BytesOnDisk,
+	)
+
+	if !s.needsOptimize(stats) {
+		s.mu.Lock()
+		s.lastRun = now
+		s.mu.Unlock()
+		return nil
+	}
+
+	report, err := s.Optimize(ctx)
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Scheduled index optimization failed", "error", err)
+		return nil
+	}
+	return report
+}
+
+// Optimize compacts the index now, regardless of schedule
+func (s *IndexMaintenanceService) Optimize(ctx context.Context) (*search.OptimizeReport, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrOptimizeRunning
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	report, err := s.index.Optimize(ctx)
+
+	s.mu.Lock()
+	s.running = false
+	s.lastRun = s.now()
+	if err == nil {
+		s.lastReport = report
+	}
+	s.mu.Unlock()
+
+	return report, err
+}
+
+// Stats returns current index fragmentation statistics
+func (s *IndexMaintenanceService) Stats() (*search.IndexStats, error) {
+	return s.index.Stats()
+}
+
+// LastReport returns the most recent optimization report
+func (s *IndexMaintenanceService) LastReport() *search.OptimizeReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastReport
+}
+
+// needsOptimize reports whether the index is fragmented past the thresholds
+func (s *IndexMaintenanceService) needsOptimize(stats *search.IndexStats) bool {
+	return stats.FileSegments > s.cfg.MinSegments ||
+		(s.cfg.MinFragmentation > 0 && stats.Fragmentation >= s.cfg.MinFragmentation)
+}
+
+// inWindow reports whether t's local time of day falls in the quiet window
+func (s *IndexMaintenanceService) inWindow(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+
+	start, end := s.cfg.WindowStart, s.cfg.WindowEnd
+	if start <= end {
+		return offset >= start && offset < end
+	}
+	return offset >= start || offset < end
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// ErrModerationOffline is returned when a decision asks to be broadcast
+// but the node has no P2P network
+var ErrModerationOffline = errors.New("P2P is disabled; moderation decisions can't be broadcast")
+
+// ModerationBroadcaster shares moderation actions with peers
+type ModerationBroadcaster interface {
+	BroadcastModerationAction(articleID, action, reason string) error
+}
+
+// ModerationService runs the moderation queue: users report articles,
+// peers forward their reports and flags, and moderators resolve or
+// dismiss each entry. Reports are also gathered into one case per article,
+// which a moderator takes on and closes by acting on the article.
+type ModerationService struct {
+	reports     repository.ReportRepository
+	cases       repository.ModerationCaseRepository
+	articles    repository.ArticleRepository
+	broadcaster ModerationBroadcaster // optional; shares decisions with peers
+	audit       AuditRecorder         // optional; records every decision
+	events      events.Publisher      // optional; announces every decision
+	indexer     SearchIndexer         // optional; drops hidden and unlisted articles from search
+	pins        PinTracker            // optional; forgets the pins of unpinned articles
+	unpinner    ContentUnpinner       // optional; unpins content from IPFS
+	reputation  ModerationReputation  // optional; penalizes authors of actioned articles
+	mu          sync.Mutex            // serialises read-modify-write of a case
+	logger      *logger.Logger
+}
+
+// NewModerationService creates a new moderation service
+func NewModerationService(reports repository.ReportRepository, cases repository.ModerationCaseRepository, articles repository.ArticleRepository, logger *logger.Logger) *ModerationService {
+	return &ModerationService{
+		reports:  reports,
+		cases:    cases,
+		articles: articles,
+		logger:   logger.WithComponent("moderation-service"),
+	}
+}
+
+// SetBroadcaster lets moderators publish their decisions on the P2P
+// moderation topic
+func (s *ModerationService) SetBroadcaster(broadcaster ModerationBroadcaster) {
+	s.broadcaster = broadcaster
+}
+
+// SetAuditLog records every resolve and dismiss in the audit log
+func (s *ModerationService) SetAuditLog(audit AuditRecorder) {
+	s.audit = audit
+}
+
+// SetEventPublisher publishes moderation.applied after every resolve and
+// dismiss
+func (s *ModerationService) SetEventPublisher(publisher events.Publisher) {
+	s.events = publisher
+}
+
+// Report queues a report against the article with the given ID or CID
+func (s *ModerationService) Report(ctx context.Context, ref, reporterID, reason string) (*domain.Report, error) {
+	article, err := findArticle(ctx, s.articles, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.Report{
+		ID:         uuid.New().String(),
+		ArticleID:  article.ID,
+		ArticleCID: article.CID,
+		Reporter:   reporterID,
+		Reason:     reason,
+		Source:     domain.ReportSourceLocal,
+		Status:     domain.ReportOpen,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := report.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.reports.Create(ctx, report); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Article reported", "report_id", report.ID, "article_id", article.ID, "reporter", reporterID)
+	s.fileCase(ctx, article, report)
+	return report, nil
+}
+
+// HandlePeerAction queues reports and flags received from another node.
+// A peer's hide, unlist or unpin decision is queued like a flag. Actions
+// about articles this node doesn't hold, and repeats from a peer that
+// already has an open report, are ignored.
+func (s *ModerationService) HandlePeerAction(ctx context.Context, articleID, action, reason, peerID string, at time.Time) error {
+	if action != domain.ModerationReport && action != domain.ModerationFlag && !domain.IsCaseAction(action) {
+		s.logger.Ctx(ctx).Debug("Ignoring peer moderation action", "action", action, "article_id", articleID, "peer_id", peerID)
+		return nil
+	}
+
+	article, err := s.articles.GetByID(ctx, articleID)
+	if err != nil {
+		if errors.Is(err, domain.ErrArticleNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if reason == "" {
+		reason = action
+	}
+	report := &domain.Report{
+		ID:         uuid.New().String(),
+		ArticleID:  article.ID,
+		ArticleCID: article.CID,
+		Reporter:   peerID,
+		Reason:     reason,
+		Source:     domain.ReportSourcePeer,
+		Status:     domain.ReportOpen,
+		CreatedAt:  at.UTC(),
+	}
+	if err := report.Validate(); err != nil {
+		return err
+	}
+	if err := s.reports.Create(ctx, report); err != nil {
+		if errors.Is(err, domain.ErrAlreadyReported) {
+			return nil
+		}
+		return err
+	}
+
+	s.logger.Ctx(ctx).Info("Queued peer moderation action", "report_id", report.ID, "action", action, "article_id", articleID, "peer_id", peerID)
+	s.fileCase(ctx, article, report)
+	return nil
+}
+
+// Get retrieves a report by ID
+func (s *ModerationService) Get(ctx context.Context, id string) (*domain.Report, error) {
+	return s.reports.GetByID(ctx, id)
+}
+
+// List retrieves the moderation queue, newest first
+func (s *ModerationService) List(ctx context.Context, filter *domain.ReportListFilter) ([]*domain.Report, int, error) {
+	return s.reports.List(ctx, filter)
+}
+
+// Resolve closes a report as upheld, flagging the article to peers when
+// the moderator asks for it
+func (s *ModerationService) Resolve(ctx context.Context, id, moderatorID string, req *domain.ReportDecisionRequest) (*domain.Report, error) {
+	return s.decide(ctx, id, moderatorID, domain.ReportResolved, domain.ModerationFlag, req)
+}
+
+// Dismiss closes a report without action
+func (s *ModerationService) Dismiss(ctx context.Context, id, moderatorID string, req *domain.ReportDecisionRequest) (*domain.Report, error) {
+	return s.decide(ctx, id, moderatorID, domain.ReportDismissed, domain.ModerationDismiss, req)
+}
+
+// ResolveArticle upholds every open report on an article. A broadcast
+// flags the article to peers once, not once per report.
+func (s *ModerationService) ResolveArticle(ctx context.Context, articleID, moderatorID string, req *domain.ReportDecisionRequest) ([]*domain.Report, error) {
+	return s.decideArticle(ctx, articleID, moderatorID, s.Resolve, req)
+}
+
+// DismissArticle closes every open report on an article without action
+func (s *ModerationService) DismissArticle(ctx context.Context, articleID, moderatorID string, req *domain.ReportDecisionRequest) ([]*domain.Report, error) {
+	return s.decideArticle(ctx, articleID, moderatorID, s.Dismiss, req)
+}
+
+func (s *ModerationService) decideArticle(ctx context.Context, articleID, moderatorID string, decide func(context.Context, string, string, *domain.ReportDecisionRequest) (*domain.Report, error), req *domain.ReportDecisionRequest) ([]*domain.Report, error) {
+	open, _, err := s.reports.List(ctx, &domain.ReportListFilter{Status: domain.ReportOpen})
+	if err != nil {
+		return nil, err
+	}
+
+	var closed []*domain.Report
+	next := *req
+	for _, report := range open {
+		if report.ArticleID != articleID {
+			continue
+		}
+		report, err := decide(ctx, report.ID, moderatorID, &next)
+		if err != nil {
+			return closed, err
+		}
+		closed = append(closed, report)
+		if report.Broadcast {
+			next.Broadcast = false
+		}
+	}
+	if len(closed) == 0 {
+		return nil, domain.ErrReportNotFound
+	}
+	return closed, nil
+}
+
+func (s *ModerationService) decide(ctx context.Context, id, moderatorID, status, action string, req *domain.ReportDecisionRequest) (*domain.Report, error) {
+	if req.Broadcast && s.broadcaster == nil {
+		return nil, ErrModerationOffline
+	}
+
+	report, err := s.reports.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != domain.ReportOpen {
+		return nil, domain.ErrReportClosed
+	}
+
+	now := time.Now().UTC()
+	report.Status = status
+	report.ResolvedAt = &now
+	report.ResolvedBy = moderatorID
+	report.Note = req.Note
+
+	// The decision stands even if peers can't be told about it
+	if req.Broadcast {
+		reason := req.Note
+		if reason == "" {
+			reason = report.Reason
+		}
+		if err := s.broadcaster.BroadcastModerationAction(report.ArticleID, action, reason); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to broadcast moderation decision", "report_id", id, "action", action, "error", err)
+		} else {
+			report.Broadcast = true
+		}
+	}
+
+	if err := s.reports.Update(ctx, report); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Report closed", "report_id", id, "status", status, "moderator", moderatorID, "broadcast", report.Broadcast)
+	s.recordDecision(ctx, report)
+	if s.events != nil {
+		s.events.Publish(domain.EventModerationApplied, domain.ModerationEvent{
+			ReportID:  report.ID,
+			ArticleID: report.ArticleID,
+			Status:    report.Status,
+			Moderator: report.ResolvedBy,
+			Broadcast: report.Broadcast,
+		})
+	}
+	return report, nil
+}
+
+// recordDecision adds a closed report to the audit log. The decision has
+// been saved by now, so a failure is only logged.
+func (s *ModerationService) recordDecision(ctx context.Context, report *domain.Report) {
+	if s.audit == nil {
+		return
+	}
+	if _, ok := auth.ActorFrom(ctx); !ok {
+		ctx = auth.WithActor(ctx, report.ResolvedBy, "")
+	}
+	action := domain.AuditModerationDismiss
+	if report.Status == domain.ReportResolved {
+		action = domain.AuditModerationResolve
+	}
+	s.audit.Record(ctx, action, report.ArticleID, map[string]string{
+		"report_id":   report.ID,
+		"article_cid": report.ArticleCID,
+		"reason":      report.Reason,
+		"note":        report.Note,
+		"broadcast":   strconv.FormatBool(report.Broadcast),
+	})
+}
+
+// findArticle looks an article up by ID, falling back to CID
+func findArticle(ctx context.Context, articles repository.ArticleRepository, ref string) (*domain.Article, error) {
+	article, err := articles.GetByID(ctx, ref)
+	if errors.Is(err, domain.ErrArticleNotFound) {
+		return articles.GetByCID(ctx, ref)
+	}
+	return article, err
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// ContentUnpinner removes content from the node's IPFS pinset
+type ContentUnpinner interface {
+	Unpin(ctx context.Context, cid string) error
+}
+
+// ModerationReputation charges the authors of actioned articles the
+// report penalty, and refunds it when the action is reversed
+type ModerationReputation interface {
+	RecordModeration(did string, penalized bool)
+}
+
+// SetIndexer removes hidden and unlisted articles from search
+func (s *ModerationService) SetIndexer(indexer SearchIndexer) {
+	s.indexer = indexer
+}
+
+// SetPinTracker forgets the pin ledger entries of unpinned articles, so
+// they aren't pinned again on the next retry
+func (s *ModerationService) SetPinTracker(pins PinTracker) {
+	s.pins = pins
+}
+
+// SetUnpinner lets the unpin decision remove content from IPFS
+func (s *ModerationService) SetUnpinner(unpinner ContentUnpinner) {
+	s.unpinner = unpinner
+}
+
+// SetReputation penalizes the author's reputation when a case is actioned
+// and lifts the penalty when the action is reversed on appeal
+func (s *ModerationService) SetReputation(reputation ModerationReputation) {
+	s.reputation = reputation
+}
+
+// fileCase adds report to the article's active case, opening one if there
+// is none. The report is queued either way, so a failure is only logged.
+func (s *ModerationService) fileCase(ctx context.Context, article *domain.Article, report *domain.Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.cases.GetActive(ctx, article.ID)
+	switch {
+	case errors.Is(err, domain.ErrCaseNotFound):
+		c = &domain.ModerationCase{
+			ID:         uuid.New().String(),
+			ArticleID:  article.ID,
+			ArticleCID: article.CID,
+			Author:     article.Author,
+			Status:     domain.CaseOpen,
+			ReportIDs:  []string{report.ID},
+			Reasons:    []string{report.Reason},
+			CreatedAt:  report.CreatedAt,
+			UpdatedAt:  report.CreatedAt,
+		}
+		err = s.cases.Create(ctx, c)
+	case err == nil:
+		c.ReportIDs = append(c.ReportIDs, report.ID)
+		if !slices.Contains(c.Reasons, report.Reason) {
+			c.Reasons = append(c.Reasons, report.Reason)
+		}
+		c.ArticleCID = article.CID
+		c.UpdatedAt = time.Now().UTC()
+		err = s.cases.Update(ctx, c)
+	}
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to file report in a moderation case", "report_id", report.ID, "article_id", article.ID, "error", err)
+	}
+}
+
+// GetCase retrieves a moderation case by ID
+func (s *ModerationService) GetCase(ctx context.Context, id string) (*domain.ModerationCase, error) {
+	return s.cases.GetByID(ctx, id)
+}
+
+// ListCases retrieves moderation cases, newest first
+func (s *ModerationService) ListCases(ctx context.Context, filter *domain.CaseListFilter) ([]*domain.ModerationCase, int, error) {
+	return s.cases.List(ctx, filter)
+}
+
+// AssignCase hands an undecided case to moderator and marks it under
+// review. Reassigning a case under review is allowed.
+func (s *ModerationService) AssignCase(ctx context.Context, id, moderator string) (*domain.ModerationCase, error) {
+	if strings.TrimSpace(moderator) == "" {
+		return nil, domain.NewValidationError("moderator", "moderator is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.cases.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Closed() {
+		return nil, domain.ErrCaseClosed
+	}
+
+	now := time.Now().UTC()
+	c.Assignee = moderator
+	c.AssignedAt = &now
+	c.Status = domain.CaseReviewing
+	c.UpdatedAt = now
+	if err := s.cases.Update(ctx, c); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Moderation case assigned", "case_id", id, "assignee", moderator)
+	return c, nil
+}
+
+// ActionCase closes a case by applying req.Action to the article: hide,
+// unlist or unpin. Its open reports are upheld, and with req.Broadcast the
+// action is published to peers.
+func (s *ModerationService) ActionCase(ctx context.Context, id, moderatorID string, req *domain.CaseDecisionRequest) (*domain.ModerationCase, error) {
+	if !domain.IsCaseAction(req.Action) {
+		return nil, domain.NewValidationError("action", "action must be one of hide, unlist, unpin")
+	}
+	return s.decideCase(ctx, id, moderatorID, domain.CaseActioned, req)
+}
+
+// DismissCase closes a case without acting on the article, releasing it if
+// a filter list quarantined it. Its open reports are dismissed, and with
+// req.Broadcast peers are told.
+func (s *ModerationService) DismissCase(ctx context.Context, id, moderatorID string, req *domain.CaseDecisionRequest) (*domain.ModerationCase, error) {
+	dismiss := *req
+	dismiss.Action = ""
+	return s.decideCase(ctx, id, moderatorID, domain.CaseDismissed, &dismiss)
+}
+
+func (s *ModerationService) decideCase(ctx context.Context, id, moderatorID, status string, req *domain.CaseDecisionRequest) (*domain.ModerationCase, error) {
+	if req.Broadcast && s.broadcaster == nil {
+		return nil, ErrModerationOffline
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.cases.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Closed() {
+		return nil, domain.ErrCaseClosed
+	}
+
+	// The article is changed first, so a failure leaves the case open
+	if err := s.applyAction(ctx, c, req.Action); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	c.Status = status
+	c.UpdatedAt = now
+	c.Decision = &domain.CaseDecision{
+		Action:    req.Action,
+		Note:      req.Note,
+		Moderator: moderatorID,
+		DecidedAt: now,
+	}
+
+	if status == domain.CaseActioned && s.reputation != nil {
+		s.reputation.RecordModeration(c.Author, true)
+		c.Decision.Penalized = true
+	}
+
+	// The decision stands even if peers can't be told about it
+	if req.Broadcast {
+		action, reason := req.Action, req.Note
+		if status == domain.CaseDismissed {
+			action = domain.ModerationDismiss
+		}
+		if reason == "" && len(c.Reasons) > 0 {
+			reason = c.Reasons[0]
+		}
+		if err := s.broadcaster.BroadcastModerationAction(c.ArticleID, action, reason); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to broadcast case outcome", "case_id", id, "action", action, "error", err)
+		} else {
+			c.Decision.Broadcast = true
+		}
+	}
+
+	if err := s.cases.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	s.closeCaseReports(ctx, c)
+
+	s.logger.Ctx(ctx).Info("Moderation case closed", "case_id", id, "status", status, "action", req.Action, "moderator", moderatorID, "broadcast", c.Decision.Broadcast)
+	s.recordCaseDecision(ctx, c)
+	if s.events != nil {
+		s.events.Publish(domain.EventModerationApplied, domain.ModerationEvent{
+			CaseID:    c.ID,
+			ArticleID: c.ArticleID,
+			Status:    c.Status,
+			Action:    c.Decision.Action,
+			Moderator: moderatorID,
+			Broadcast: c.Decision.Broadcast,
+		})
+	}
+	return c, nil
+}
+
+// LatestCase retrieves the article's newest moderation case
+func (s *ModerationService) LatestCase(ctx context.Context, articleID string) (*domain.ModerationCase, error) {
+	cases, _, err := s.cases.List(ctx, &domain.CaseListFilter{ArticleID: articleID, Page: 1, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, domain.ErrCaseNotFound
+	}
+	return cases[0], nil
+}
+
+// ReverseCase undoes the action of an actioned case: a hidden or unlisted
+// article is made public and searchable again, unpinned content is pinned
+// again, the author's reputation penalty is lifted, and peers told of the
+// action are told it was dismissed. An undecided case is dismissed
+// instead, which releases an article a filter list quarantined.
+func (s *ModerationService) ReverseCase(ctx context.Context, id, moderatorID, note string) (*domain.ModerationCase, error) {
+	c, err := s.cases.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Closed() {
+		return s.DismissCase(ctx, id, moderatorID, &domain.CaseDecisionRequest{Note: note})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, err = s.cases.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if c.Status != domain.CaseActioned {
+		return nil, domain.ErrCaseClosed
+	}
+
+	// The article is changed first, so a failure leaves the action in place
+	if err := s.undoAction(ctx, c); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	c.Status = domain.CaseReversed
+	c.UpdatedAt = now
+	c.Reversal = &domain.CaseDecision{
+		Note:      note,
+		Moderator: moderatorID,
+		DecidedAt: now,
+	}
+	if c.Decision.Penalized && s.reputation != nil {
+		s.reputation.RecordModeration(c.Author, false)
+	}
+
+	// Peers that heard of the action hear of its reversal, if they still can
+	if c.Decision.Broadcast && s.broadcaster != nil {
+		if err := s.broadcaster.BroadcastModerationAction(c.ArticleID, domain.ModerationDismiss, note); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to broadcast case reversal", "case_id", id, "error", err)
+		} else {
+			c.Reversal.Broadcast = true
+		}
+	}
+
+	if err := s.cases.Update(ctx, c); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Moderation case reversed", "case_id", id, "action", c.Decision.Action, "moderator", moderatorID)
+	if s.audit != nil {
+		actx := ctx
+		if _, ok := auth.ActorFrom(ctx); !ok {
+			actx = auth.WithActor(ctx, moderatorID, "")
+		}
+		s.audit.Record(actx, domain.AuditModerationReverse, c.ArticleID, map[string]string{
+			"case_id":     c.ID,
+			"article_cid": c.ArticleCID,
+			"decision":    c.Decision.Action,
+			"note":        note,
+			"penalized":   strconv.FormatBool(c.Decision.Penalized),
+			"broadcast":   strconv.FormatBool(c.Reversal.Broadcast),
+		})
+	}
+	if s.events != nil {
+		s.events.Publish(domain.EventModerationApplied, domain.ModerationEvent{
+			CaseID:    c.ID,
+			ArticleID: c.ArticleID,
+			Status:    c.Status,
+			Action:    c.Decision.Action,
+			Moderator: moderatorID,
+			Broadcast: c.Reversal.Broadcast,
+		})
+	}
+	return c, nil
+}
+
+// undoAction restores an article a case acted on. An article that is no
+// longer stored has nothing to restore.
+func (s *ModerationService) undoAction(ctx context.Context, c *domain.ModerationCase) error {
+	article, err := s.articles.GetByID(ctx, c.ArticleID)
+	if err != nil && !errors.Is(err, domain.ErrArticleNotFound) {
+		return err
+	}
+
+	switch c.Decision.Action {
+	case domain.CaseActionHide, domain.CaseActionUnlist:
+		if article == nil || (article.Visibility != domain.VisibilityHidden && article.Visibility != domain.VisibilityUnlisted) {
+			return nil
+		}
+		article.Visibility = ""
+		if err := s.articles.Update(ctx, article); err != nil {
+			return err
+		}
+		if s.indexer != nil && article.Listed() {
+			if err := s.indexer.IndexArticle(ctx, article); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to index restored article", "article_id", article.ID, "error", err)
+			}
+		}
+
+	case domain.CaseActionUnpin:
+		// The pin ledger pins the content again, retrying until it succeeds
+		if s.pins == nil {
+			return nil
+		}
+		s.pins.Track(ctx, c.ArticleCID, c.ArticleID)
+		if article != nil && article.NodeCID != "" {
+			s.pins.Track(ctx, article.NodeCID, c.ArticleID)
+		}
+	}
+	return nil
+}
+
+// applyAction carries out a case decision on this node; an empty action
+// dismisses the case, which releases an article a filter list quarantined.
+// Changing an article that is no longer stored has nothing to do.
+func (s *ModerationService) applyAction(ctx context.Context, c *domain.ModerationCase, action string) error {
+	article, err := s.articles.GetByID(ctx, c.ArticleID)
+	if err != nil && !errors.Is(err, domain.ErrArticleNotFound) {
+		return err
+	}
+
+	switch action {
+	case "":
+		if article == nil || article.Visibility != domain.VisibilityQuarantined {
+			return nil
+		}
+		article.Visibility = ""
+		if err := s.articles.Update(ctx, article); err != nil {
+			return err
+		}
+		if s.indexer != nil && article.Listed() {
+			if err := s.indexer.IndexArticle(ctx, article); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to index released article", "article_id", article.ID, "error", err)
+			}
+		}
+
+	case domain.CaseActionHide, domain.CaseActionUnlist:
+		if article == nil {
+			return nil
+		}
+		visibility := domain.VisibilityHidden
+		if action == domain.CaseActionUnlist {
+			if article.Visibility == domain.VisibilityHidden {
+				return nil // already further out of sight
+			}
+			visibility = domain.VisibilityUnlisted
+		}
+		article.Visibility = visibility
+		if err := s.articles.Update(ctx, article); err != nil {
+			return err
+		}
+		if s.indexer != nil {
+			if err := s.indexer.DeleteArticle(ctx, article.ID); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to remove moderated article from search", "article_id", article.ID, "error", err)
+			}
+		}
+
+	case domain.CaseActionUnpin:
+		cids := []string{c.ArticleCID}
+		if article != nil && article.NodeCID != "" {
+			cids = append(cids, article.NodeCID)
+		}
+		if s.pins != nil {
+			s.pins.Release(ctx, c.ArticleID)
+		}
+		if s.unpinner == nil {
+			return nil
+		}
+		for _, cid := range cids {
+			// Content this node never pinned fails here; that's no reason to keep the case open
+			if err := s.unpinner.Unpin(ctx, cid); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to unpin moderated content", "case_id", c.ID, "cid", cid, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// closeCaseReports upholds or dismisses the case's reports that are still
+// open. The case decision is already saved, so failures are only logged.
+func (s *ModerationService) closeCaseReports(ctx context.Context, c *domain.ModerationCase) {
+	status := domain.ReportResolved
+	if c.Status == domain.CaseDismissed {
+		status = domain.ReportDismissed
+	}
+
+	for _, id := range c.ReportIDs {
+		report, err := s.reports.GetByID(ctx, id)
+		if err != nil || report.Status != domain.ReportOpen {
+			continue
+		}
+		decidedAt := c.Decision.DecidedAt
+		report.Status = status
+		report.ResolvedAt = &decidedAt
+		report.ResolvedBy = c.Decision.Moderator
+		report.Note = c.Decision.Note
+		report.Broadcast = c.Decision.Broadcast
+		if err := s.reports.Update(ctx, report); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to close report with its case", "case_id", c.ID, "report_id", id, "error", err)
+		}
+	}
+}
+
+// recordCaseDecision adds a closed case to the audit log
+func (s *ModerationService) recordCaseDecision(ctx context.Context, c *domain.ModerationCase) {
+	if s.audit == nil {
+		return
+	}
+	if _, ok := auth.ActorFrom(ctx); !ok {
+		ctx = auth.WithActor(ctx, c.Decision.Moderator, "")
+	}
+	action := domain.AuditModerationDismiss
+	if c.Status == domain.CaseActioned {
+		action = domain.AuditModerationAction
+	}
+	s.audit.Record(ctx, action, c.ArticleID, map[string]string{
+		"case_id":     c.ID,
+		"article_cid": c.ArticleCID,
+		"decision":    c.Decision.Action,
+		"reports":     strconv.Itoa(len(c.ReportIDs)),
+		"note":        c.Decision.Note,
+		"broadcast":   strconv.FormatBool(c.Decision.Broadcast),
+	})
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// NameResolver resolves an IPNS name to an /ipfs/ path
+type NameResolver interface {
+	Resolve(ctx context.Context, name string) (string, error)
+}
+
+// ContentReader reads content from IPFS by CID
+type ContentReader interface {
+	Cat(ctx context.Context, cid string) ([]byte, error)
+}
+
+// Bootstrapper dials bootstrap peers a policy recommends
+type Bootstrapper interface {
+	AddBootstrapPeer(addr string) error
+}
+
+// NetworkPolicyService follows the network policy document published
+// under an IPNS name. Only documents signed by the configured publisher
+// key are applied, and never one older than the policy in force.
+type NetworkPolicyService struct {
+	name            string
+	publisher       ed25519.PublicKey
+	publisherString string
+	protocolVersion string
+	resolver        NameResolver
+	reader          ContentReader
+	bootstrapper    Bootstrapper // optional; set with SetBootstrapper
+	logger          *logger.Logger
+
+	mu        sync.RWMutex
+	policy    *domain.NetworkPolicy
+	fetchedAt *time.Time
+	lastError string
+	dialled   map[string]bool
+
+	now      func() time.Time
+	stopChan chan struct{}
+}
+
+// NewNetworkPolicyService creates a subscription to the policy published
+// under name. publisher is the Ed25519 public key, base64 or did:key, the
+// policy must be signed with; protocolVersion is this node's.
+func NewNetworkPolicyService(name, publisher, protocolVersion string, resolver NameResolver, reader ContentReader, logger *logger.Logger) (*NetworkPolicyService, error) {
+	key, err := parsePublisherKey(publisher)
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkPolicyService{
+		name:            name,
+		publisher:       key,
+		publisherString: publisher,
+		protocolVersion: protocolVersion,
+		resolver:        resolver,
+		reader:          reader,
+		logger:          logger.WithComponent("network-policy"),
+		dialled:         make(map[string]bool),
+		now:             time.Now,
+		stopChan:        make(chan struct{}),
+	}, nil
+}
+
+// parsePublisherKey accepts a did:key or a base64 Ed25519 public key
+func parsePublisherKey(s string) (ed25519.PublicKey, error) {
+	if strings.HasPrefix(s, "did:key:") {
+		return crypto.PublicKeyFromDID(s)
+	}
+	key, err := crypto.PublicKeyFromString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy publisher key: %w", err)
+	}
+	return key, nil
+}
+
+// SetBootstrapper dials the bootstrap peers of each applied policy
+func (s *NetworkPolicyService) SetBootstrapper(bootstrapper Bootstrapper) {
+	s.bootstrapper = bootstrapper
+}
+
+// Start fetches the policy now and then every interval until Stop is called
+func (s *NetworkPolicyService) Start(ctx context.Context, interval time.Duration) {
+	s.logger.Ctx(ctx).Info("Following network policy", "name", s.name, "interval", interval.String())
+
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to fetch network policy", "name", s.name, "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to fetch network policy", "name", s.name, "error", err)
+			}
+		case <-s.stopChan:
+			s.logger.Ctx(ctx).Info("Stopping network policy subscription")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the refresh loop
+func (s *NetworkPolicyService) Stop() {
+	close(s.stopChan)
+}
+
+// Refresh resolves the policy name, verifies the document it points to and
+// applies it. A document already in force is not applied again.
+func (s *NetworkPolicyService) Refresh(ctx context.Context) error {
+	policy, err := s.fetch(ctx)
+	if err == nil {
+		err = s.accept(ctx, policy)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.lastError = err.Error()
+		return err
+	}
+	now := s.now()
+	s.fetchedAt = &now
+	s.lastError = ""
+	return nil
+}
+
+// accept makes policy the one in force unless it is older than, or the
+// same edition as, the current one
+func (s *NetworkPolicyService) accept(ctx context.Context, policy *domain.NetworkPolicy) error {
+	s.mu.Lock()
+	current := s.policy
+	if current != nil && policy.Version <= current.Version {
+		s.mu.Unlock()
+		if policy.Version < current.Version {
+			return domain.ErrPolicyOutdated
+		}
+		return nil
+	}
+	s.policy = policy
+	s.mu.Unlock()
+
+	s.apply(ctx, policy)
+	return nil
+}
+
+// fetch reads the document the policy name points to and checks its
+// signature
+func (s *NetworkPolicyService) fetch(ctx context.Context) (*domain.NetworkPolicy, error) {
+	path, err := s.resolver.Resolve(ctx, s.name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.reader.Cat(ctx, strings.TrimPrefix(path, "/ipfs/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var policy domain.NetworkPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid network policy: %w", err)
+	}
+	content, err := policy.SignableContent()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := crypto.Verify(content, policy.Signature, s.publisher); err != nil || !ok {
+		return nil, domain.ErrPolicySignature
+	}
+	return &policy, nil
+}
+
+// apply merges a newly accepted policy into the running node
+func (s *NetworkPolicyService) apply(ctx context.Context, policy *domain.NetworkPolicy) {
+	s.logger.Ctx(ctx).Info("Applied network policy",
+		"version", policy.Version,
+		"bootstrap_peers", len(policy.BootstrapPeers),
+		"banned_categories", policy.BannedCategories,
+		"min_protocol_version", policy.MinProtocolVersion,
+	)
+
+	if !s.compatible(policy) {
+		s.logger.Ctx(ctx).Error("This node is older than the network policy's minimum protocol version; upgrade it",
+			"protocol_version", s.protocolVersion,
+			"min_protocol_version", policy.MinProtocolVersion,
+		)
+	}
+
+	if s.bootstrapper == nil {
+		return
+	}
+	for _, addr := range policy.BootstrapPeers {
+		s.mu.Lock()
+		seen := s.dialled[addr]
+		s.dialled[addr] = true
+		s.mu.Unlock()
+		if seen {
+			continue
+		}
+		if err := s.bootstrapper.AddBootstrapPeer(addr); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to add policy bootstrap peer", "addr", addr, "error", err)
+		}
+	}
+}
+
+// Policy returns the policy in force, or nil before one is fetched
+func (s *NetworkPolicyService) Policy() *domain.NetworkPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// CategoryBanned reports whether the policy in force bans category
+func (s *NetworkPolicyService) CategoryBanned(category string) bool {
+	policy := s.Policy()
+	return policy != nil && policy.CategoryBanned(category)
+}
+
+// Status reports the subscription and the policy in force
+func (s *NetworkPolicyService) Status() *domain.NetworkPolicyStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &domain.NetworkPolicyStatus{
+		Name:            s.name,
+		Publisher:       s.publisherString,
+		Policy:          s.policy,
+		FetchedAt:       s.fetchedAt,
+		LastError:       s.lastError,
+		ProtocolVersion: s.protocolVersion,
+		Compatible:      s.policy == nil || s.compatible(s.policy),
+	}
+}
+
+// compatible reports whether this node meets the policy's minimum
+// protocol version
+func (s *NetworkPolicyService) compatible(policy *domain.NetworkPolicy) bool {
+	if policy.MinProtocolVersion == "" {
+		return true
+	}
+	return compareVersions(s.protocolVersion, policy.MinProtocolVersion) >= 0
+}
+
+// compareVersions compares dotted numeric versions such as 1.2.0; missing
+// or non-numeric parts count as zero
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// ErrReconcileRunning is returned when a reconciliation is already in progress
+var ErrReconcileRunning = errors.New("pin reconciliation already running")
+
+// PinStore pins content and lists what is currently pinned
+type PinStore interface {
+	Pin(ctx context.Context, cid string) error
+	PinnedCIDs(ctx context.Context) (map[string]bool, error)
+}
+
+// PinLedgerConfig controls retry and reconciliation timing
+type PinLedgerConfig struct {
+	RetryInterval     time.Duration // how often due retries are attempted
+	ReconcileInterval time.Duration // how often the ledger is checked against pin ls
+	MaxBackoff        time.Duration // cap on the delay between retries of one CID
+}
+
+// PinLedgerSummary counts ledger entries by status
+type PinLedgerSummary struct {
+	Total         int                        `json:"total"`
+	ByStatus      map[string]int             `json:"by_status"`
+	LastReconcile *domain.PinReconcileReport `json:"last_reconcile,omitempty"`
+}
+
+// PinLedgerService records the CIDs this node means to keep pinned, retries
+// pins that failed, and periodically compares the ledger with the daemon's
+// pinset so an IPFS outage can't silently leave articles open to GC
+type PinLedgerService struct {
+	repo   repository.PinRepository
+	store  PinStore
+	cfg    PinLedgerConfig
+	logger *logger.Logger
+
+	mu            sync.Mutex
+	reconciling   bool
+	lastReconcile *domain.PinReconcileReport
+
+	now      func() time.Time
+	stopChan chan struct{}
+}
+
+// NewPinLedgerService creates a new pin ledger service
+func NewPinLedgerService(repo repository.PinRepository, store PinStore, cfg PinLedgerConfig, logger *logger.Logger) *PinLedgerService {
+	return &PinLedgerService{
+		repo:     repo,
+		store:    store,
+		cfg:      cfg,
+		logger:   logger.WithComponent("pin-ledger"),
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Track records that cid (owned by ref) should stay pinned and pins it.
+// A failed pin is left in the ledger for the retry loop.
+func (s *PinLedgerService) Track(ctx context.Context, cid, ref string) {
+	if cid == "" || domain.IsLocalCID(cid) {
+		return
+	}
+
+	now := s.now()
+	pin := &domain.PinRecord{
+		CID:         cid,
+		Ref:         ref,
+		Status:      domain.PinPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		NextAttempt: now,
+	}
+	if existing, err := s.repo.Get(ctx, cid); err == nil {
+		if existing.Status == domain.PinPinned {
+			return
+		}
+		pin = existing
+	}
+
+	s.attempt(ctx, pin)
+}
+
+// Release forgets every CID owned by ref. Content is not unpinned here;
+// the caller decides what to remove from IPFS.
+func (s *PinLedgerService) Release(ctx context.Context, ref string) {
+	cids, err := s.repo.DeleteByRef(ctx, ref)
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to release pins", "ref", ref, "error", err)
+		return
+	}
+	if len(cids) > 0 {
+		s.logger.Ctx(ctx).Debug("Released pins", "ref", ref, "count", len(cids))
+	}
+}
+
+// RetryDue attempts every pin whose backoff has elapsed and returns how many
+// are now pinned
+func (s *PinLedgerService) RetryDue(ctx context.Context) (int, error) {
+	due, err := s.repo.ListDue(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+
+	pinned := 0
+	for _, pin := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if s.attempt(ctx, pin) {
+			pinned++
+		}
+	}
+	if len(due) > 0 {
+		s.logger.Ctx(ctx).Info("Retried pending pins", "due", len(due), "pinned", pinned)
+	}
+	return pinned, nil
+}
+
+// Reconcile compares the ledger with the node's pinset. Entries recorded
+// as pinned but missing are queued for an immediate retry; unconfirmed
+// entries that turn out to be pinned are marked as such.
+func (s *PinLedgerService) Reconcile(ctx context.Context) (*domain.PinReconcileReport, error) {
+	s.mu.Lock()
+	if s.reconciling {
+		s.mu.Unlock()
+		return nil, ErrReconcileRunning
+	}
+	s.reconciling = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.reconciling = false
+		s.mu.Unlock()
+	}()
+
+	report := &domain.PinReconcileReport{StartedAt: s.now()}
+
+	pinset, err := s.store.PinnedCIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := s.repo.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pin := range pins {
+		report.Checked++
+		present := pinset[pin.CID]
+
+		switch {
+		case pin.Status == domain.PinPinned && !present:
+			report.Missing = append(report.Missing, pin.CID)
+			pin.Status = domain.PinPending
+			pin.NextAttempt = s.now()
+		case pin.Status != domain.PinPinned && present:
+			report.Confirmed++
+			pin.Status = domain.PinPinned
+			pin.Attempts = 0
+			pin.LastError = ""
+		default:
+			continue
+		}
+
+		pin.UpdatedAt = s.now()
+		if err := s.repo.Save(ctx, pin); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to update pin record", "cid", pin.CID, "error", err)
+		}
+	}
+
+	report.FinishedAt = s.now()
+	if len(report.Missing) > 0 {
+		s.logger.Ctx(ctx).Warn("Pins missing from IPFS; queued for re-pin", "count", len(report.Missing))
+	}
+	s.logger.Ctx(ctx).Info("Pin reconciliation complete",
+		"checked", report.Checked,
+		"missing", len(report.Missing),
+		"confirmed", report.Confirmed,
+	)
+
+	s.mu.Lock()
+	s.lastReconcile = report
+	s.mu.Unlock()
+	return report, nil
+}
+
+// Summary counts ledger entries by status
+func (s *PinLedgerService) Summary(ctx context.Context) (*PinLedgerSummary, error) {
+	pins, err := s.repo.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &PinLedgerSummary{
+		Total:    len(pins),
+		ByStatus: map[string]int{domain.PinPending: 0, domain.PinPinned: 0, domain.PinFailed: 0},
+	}
+	for _, pin := range pins {
+		summary.ByStatus[pin.Status]++
+	}
+
+	s.mu.Lock()
+	summary.LastReconcile = s.lastReconcile
+	s.mu.Unlock()
+	return summary, nil
+}
+
+// Get returns the ledger entry for a CID
+func (s *PinLedgerService) Get(ctx context.Context, cid string) (*domain.PinRecord, error) {
+	return s.repo.Get(ctx, cid)
+}
+
+// List returns ledger entries, optionally filtered by status
+func (s *PinLedgerService) List(ctx context.Context, status string) ([]*domain.PinRecord, error) {
+	return s.repo.List(ctx, status)
+}
+
+// Start runs the retry and reconciliation loops until Stop is called
+func (s *PinLedgerService) Start(ctx context.Context) {
+	s.logger.Ctx(ctx).Info("Starting pin ledger",
+		"retry_interval", s.cfg.RetryInterval.String(),
+		"reconcile_interval", s.cfg.ReconcileInterval.String(),
+	)
+
+	retry := time.NewTicker(s.cfg.RetryInterval)
+	defer retry.Stop()
+	reconcile := time.NewTicker(s.cfg.ReconcileInterval)
+	defer reconcile.Stop()
+
+	for {
+		select {
+		case <-retry.C:
+			if _, err := s.RetryDue(ctx); err != nil {
+				s.logger.Ctx(ctx).Warn("Pin retry failed", "error", err)
+			}
+		case <-reconcile.C:
+			if _, err := s.Reconcile(ctx); err != nil {
+				s.logger.Ctx(ctx).Warn("Pin reconciliation failed", "error", err)
+			}
+		case <-s.stopChan:
+			s.logger.Ctx(ctx).Info("Stopping pin ledger")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the background loops
+func (s *PinLedgerService) Stop() {
+	close(s.stopChan)
+}
+
+// attempt pins one CID and records the outcome, backing off exponentially
+// on failure. Reports whether the pin succeeded.
+func (s *PinLedgerService) attempt(ctx context.Context, pin *domain.PinRecord) bool {
+	err := s.store.Pin(ctx, pin.CID)
+
+	now := s.now()
+	pin.UpdatedAt = now
+	if err != nil {
+		pin.Status = domain.PinFailed
+		pin.Attempts++
+		pin.LastError = err.Error()
+		pin.NextAttempt = now.Add(s.backoff(pin.Attempts))
+		s.logger.Ctx(ctx).Warn("Pin failed; will retry", "cid", pin.CID, "attempts", pin.Attempts, "next_attempt", pin.NextAttempt, "error", err)
+	} else {
+		pin.Status = domain.PinPinned
+		pin.Attempts = 0
+		pin.LastError = ""
+	}
+
+	if err := s.repo.Save(ctx, pin); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to record pin", "cid", pin.CID, "error", err)
+	}
+	return err == nil
+}
+
+// backoff doubles the retry interval per failed attempt, up to MaxBackoff
+func (s *PinLedgerService) backoff(attempts int) time.Duration {
+	delay := s.cfg.RetryInterval
+	for i := 1; i < attempts && delay < s.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.cfg.MaxBackoff)
+}
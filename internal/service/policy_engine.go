@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// AuthorReputation looks up the reputation of an article's author
+type AuthorReputation interface {
+	GetScore(did string) *domain.ReputationScore
+}
+
+// AttachmentPinner pins an article's attachments on this node's IPFS
+type AttachmentPinner interface {
+	Pin(ctx context.Context, cid string) error
+}
+
+// PolicyEngine applies the node's acceptance policy to articles received
+// from peers, by gossip, sync, fetch or archive import, before they are
+// stored, indexed or served again. Cheap rules run first; pinning the
+// attachments runs last.
+type PolicyEngine struct {
+	policy     domain.AcceptancePolicy
+	categories map[string]bool
+	languages  map[string]bool
+	reputation AuthorReputation // optional; without it the reputation rule is skipped
+	pinner     AttachmentPinner // optional; without it the attachments rule refuses every article with attachments
+	mu         sync.Mutex
+	refused    map[string]int64 // refusals since start, by rule
+	logger     *logger.Logger
+}
+
+// NewPolicyEngine creates a policy engine enforcing policy. Categories
+// and languages are matched ignoring case.
+func NewPolicyEngine(policy domain.AcceptancePolicy, logger *logger.Logger) *PolicyEngine {
+	e := &PolicyEngine{
+		policy:  policy,
+		refused: make(map[string]int64),
+		logger:  logger.WithComponent("policy-engine"),
+	}
+	if len(policy.Categories) > 0 {
+		e.categories = make(map[string]bool)
+		for _, category := range policy.Categories {
+			e.categories[strings.ToLower(category)] = true
+		}
+	}
+	if len(policy.Languages) > 0 {
+		e.languages = make(map[string]bool)
+		for _, language := range policy.Languages {
+			e.languages[strings.ToLower(language)] = true
+		}
+	}
+	return e
+}
+
+// SetReputation lets the engine enforce the minimum author reputation
+func (e *PolicyEngine) SetReputation(reputation AuthorReputation) {
+	e.reputation = reputation
+}
+
+// SetPinner lets the engine pin attachments before accepting an article
+func (e *PolicyEngine) SetPinner(pinner AttachmentPinner) {
+	e.pinner = pinner
+}
+
+// Evaluate returns a *domain.PolicyRefusal if the policy refuses article
+func (e *PolicyEngine) Evaluate(ctx context.Context, article *domain.Article) error {
+	refusal := e.evaluate(ctx, article)
+	if refusal == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	e.refused[refusal.Rule]++
+	e.mu.Unlock()
+	e.logger.Ctx(ctx).Info("Refusing article by acceptance policy", "article_id", article.ID, "author", article.Author, "rule", refusal.Rule, "reason", refusal.Reason)
+	return refusal
+}
+
+func (e *PolicyEngine) evaluate(ctx context.Context, article *domain.Article) *domain.PolicyRefusal {
+	if e.categories != nil && !e.categories[strings.ToLower(article.Category)] {
+		return &domain.PolicyRefusal{Rule: domain.AcceptCategory, Reason: fmt.Sprintf("category %q is not accepted", article.Category)}
+	}
+
+	if e.languages != nil {
+		language := article.Language
+		if language == "" {
+			language = domain.LanguageUndetermined
+		}
+		if !e.acceptsLanguage(language) {
+			return &domain.PolicyRefusal{Rule: domain.AcceptLanguage, Reason: fmt.Sprintf("language %q is not accepted", language)}
+		}
+	}
+
+	if e.policy.MaxArticleSize > 0 {
+		content, err := article.GetSignableContent()
+		if err != nil {
+			return &domain.PolicyRefusal{Rule: domain.AcceptSize, Reason: err.Error()}
+		}
+		if len(content) > e.policy.MaxArticleSize {
+			return &domain.PolicyRefusal{Rule: domain.AcceptSize, Reason: fmt.Sprintf("article is %d bytes, more than %d", len(content), e.policy.MaxArticleSize)}
+		}
+	}
+
+	if e.policy.MinAuthorReputation > 0 && e.reputation != nil {
+		if score := e.reputation.GetScore(article.Author).Score; score < e.policy.MinAuthorReputation {
+			return &domain.PolicyRefusal{Rule: domain.AcceptReputation, Reason: fmt.Sprintf("author reputation %.1f is below %.1f", score, e.policy.MinAuthorReputation)}
+		}
+	}
+
+	if e.policy.RequireAttachmentsPinned {
+		for _, cid := range article.AttachmentCIDs() {
+			if e.pinner == nil {
+				return &domain.PolicyRefusal{Rule: domain.AcceptAttachments, Reason: "attachments can't be pinned on this node"}
+			}
+			if err := e.pinner.Pin(ctx, cid); err != nil {
+				return &domain.PolicyRefusal{Rule: domain.AcceptAttachments, Reason: fmt.Sprintf("failed to pin attachment %s: %v", cid, err)}
+			}
+		}
+	}
+	return nil
+}
+
+// acceptsLanguage matches a tag and, failing that, its primary language,
+// so accepting "pt" accepts "pt-BR"
+func (e *PolicyEngine) acceptsLanguage(language string) bool {
+	language = strings.ToLower(language)
+	if e.languages[language] {
+		return true
+	}
+	primary, _, _ := strings.Cut(language, "-")
+	return e.languages[primary]
+}
+
+// Status returns the policy in force and the refusals since start
+func (e *PolicyEngine) Status() *domain.AcceptanceStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return &domain.AcceptanceStatus{Policy: e.policy, Refused: maps.Clone(e.refused)}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// ErrSweepRunning is returned when a retention sweep is already in progress
+var ErrSweepRunning = errors.New("retention sweep already running")
+
+// StorageQuota limits how much content synced from other nodes is kept
+type StorageQuota struct {
+	MaxDiskUsage      int64                    // bytes under the measured paths; 0 is unlimited
+	MaxSyncedArticles int                      // 0 is unlimited
+	CategoryMaxAge    map[string]time.Duration // synced articles older than this are removed
+	Paths             []string                 // files and directories counted towards disk usage
+}
+
+// RetentionService removes synced articles the storage quota no longer
+// allows, oldest first. Articles written by this node's own users are
+// never removed.
+type RetentionService struct {
+	articles repository.ArticleRepository
+	users    repository.UserRepository
+	indexer  SearchIndexer // optional; set with SetIndexer
+	quota    StorageQuota
+	logger   *logger.Logger
+
+	mu         sync.Mutex
+	running    bool
+	lastReport *domain.RetentionReport
+
+	now      func() time.Time
+	stopChan chan struct{}
+}
+
+// NewRetentionService creates a new retention sweeper
+func NewRetentionService(articles repository.ArticleRepository, users repository.UserRepository, quota StorageQuota, logger *logger.Logger) *RetentionService {
+	return &RetentionService{
+		articles: articles,
+		users:    users,
+		quota:    quota,
+		logger:   logger.WithComponent("retention"),
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SetIndexer removes swept articles from the search index too
+func (s *RetentionService) SetIndexer(indexer SearchIndexer) {
+	s.indexer = indexer
+}
+
+// Start runs a sweep every interval until Stop is called
+func (s *RetentionService) Start(ctx context.Context, interval time.Duration) {
+	s.logger.Ctx(ctx).Info("Starting retention sweeper",
+		"interval", interval.String(),
+		"max_disk_usage", s.quota.MaxDiskUsage,
+		"max_synced_articles", s.quota.MaxSyncedArticles,
+		"categories", len(s.quota.CategoryMaxAge),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Run(ctx, false); err != nil && err != ErrSweepRunning {
+				s.logger.Ctx(ctx).Warn("Scheduled retention sweep failed", "error", err)
+			}
+		case <-s.stopChan:
+			s.logger.Ctx(ctx).Info("Stopping retention sweeper")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the scheduled sweep loop
+func (s *RetentionService) Stop() {
+	close(s.stopChan)
+}
+
+// LastReport returns the report of the most recent sweep, or nil
+func (s *RetentionService) LastReport() *domain.RetentionReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastReport
+}
+
+// Run removes synced articles past their category's retention, then the
+// oldest beyond the synced-article limit, then enough of the oldest left
+// to bring disk usage under the quota. A dry run only reports what would
+// be removed.
+func (s *RetentionService) Run(ctx context.Context, dryRun bool) (*domain.RetentionReport, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrSweepRunning
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	report := &domain.RetentionReport{
+		StartedAt: s.now(),
+		DryRun:    dryRun,
+		Removed:   []domain.RetentionRemoval{},
+	}
+
+	all, synced, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.DiskUsageBefore = s.diskUsage()
+
+	for _, removal := range s.candidates(all, synced, report.DiskUsageBefore) {
+		if dryRun {
+			report.Removed = append(report.Removed, removal)
+			continue
+		}
+		if err := s.articles.Delete(ctx, removal.ArticleID); err != nil && err != domain.ErrArticleNotFound {
+			report.Failed++
+			s.logger.Ctx(ctx).Warn("Failed to remove synced article", "article_id", removal.ArticleID, "reason", removal.Reason, "error", err)
+			continue
+		}
+		if s.indexer != nil {
+			if err := s.indexer.DeleteArticle(ctx, removal.ArticleID); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to remove swept article from index", "article_id", removal.ArticleID, "error", err)
+			}
+		}
+		report.Removed = append(report.Removed, removal)
+	}
+
+	report.FinishedAt = s.now()
+	s.logger.Ctx(ctx).Info("Retention sweep complete",
+		"dry_run", dryRun,
+		"removed", len(report.Removed),
+		"failed", report.Failed,
+		"disk_usage", report.DiskUsageBefore,
+	)
+
+	s.mu.Lock()
+	s.lastReport = report
+	s.mu.Unlock()
+	return report, nil
+}
+
+// Stats reports current usage against the quota
+func (s *RetentionService) Stats(ctx context.Context) (*domain.StorageStats, error) {
+	all, synced, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.StorageStats{
+		DiskUsage:         s.diskUsage(),
+		MaxDiskUsage:      s.quota.MaxDiskUsage,
+		Articles:          len(all),
+		SyncedArticles:    len(synced),
+		MaxSyncedArticles: s.quota.MaxSyncedArticles,
+		SyncedByCategory:  make(map[string]int),
+		CategoryRetention: make(map[string]string, len(s.quota.CategoryMaxAge)),
+		LastSweep:         s.LastReport(),
+	}
+	for _, article := range synced {
+		stats.SyncedByCategory[article.Category]++
+	}
+	for category, maxAge := range s.quota.CategoryMaxAge {
+		stats.CategoryRetention[category] = maxAge.String()
+	}
+	return stats, nil
+}
+
+// load returns every stored article and the synced ones, newest first.
+// An article is synced when its signing key belongs to no local user.
+func (s *RetentionService) load(ctx context.Context) ([]*domain.Article, []*domain.Article, error) {
+	users, err := s.users.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	local := make(map[string]bool, len(users))
+	for _, user := range users {
+		if user.PublicKey != "" {
+			local[user.PublicKey] = true
+		}
+	}
+
+	all, _, err := s.articles.List(ctx, &domain.ArticleListFilter{Page: 1, Limit: 1 << 30})
+	if err != nil {
+		return nil, nil, err
+	}
+	var synced []*domain.Article
+	for _, article := range all {
+		if !local[article.AuthorPubKey] {
+			synced = append(synced, article)
+		}
+	}
+	return all, synced, nil
+}
+
+// candidates applies the quota to synced articles, which are newest first
+func (s *RetentionService) candidates(all, synced []*domain.Article, usage int64) []domain.RetentionRemoval {
+	var out []domain.RetentionRemoval
+	remove := func(article *domain.Article, reason string) {
+		out = append(out, domain.RetentionRemoval{
+			ArticleID: article.ID,
+			Category:  article.Category,
+			Timestamp: article.Timestamp,
+			Reason:    reason,
+		})
+	}
+
+	now := s.now()
+	kept := make([]*domain.Article, 0, len(synced))
+	for _, article := range synced {
+		if maxAge := s.quota.CategoryMaxAge[article.Category]; maxAge > 0 && now.Sub(article.Timestamp) > maxAge {
+			remove(article, domain.RetentionReasonCategory)
+			continue
+		}
+		kept = append(kept, article)
+	}
+
+	if limit := s.quota.MaxSyncedArticles; limit > 0 && len(kept) > limit {
+		for _, article := range kept[limit:] {
+			remove(article, domain.RetentionReasonCount)
+		}
+		kept = kept[:limit]
+	}
+
+	// Deleted keys are only reclaimed when the store compacts, so the
+	// space an article takes is estimated as its share of current usage
+	if s.quota.MaxDiskUsage > 0 && usage > s.quota.MaxDiskUsage && len(all) > 0 {
+		perArticle := usage / int64(len(all))
+		if perArticle < 1 {
+			perArticle = 1
+		}
+		excess := usage - s.quota.MaxDiskUsage
+		need := int((excess+perArticle-1)/perArticle) - len(out)
+		for i := len(kept) - 1; i >= 0 && need > 0; i-- {
+			remove(kept[i], domain.RetentionReasonDisk)
+			need--
+		}
+	}
+	return out
+}
+
+// diskUsage totals the size of every file under the quota's paths
+func (s *RetentionService) diskUsage() int64 {
+	var total int64
+	for _, root := range s.quota.Paths {
+		_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.Type().IsRegular() {
+				if info, err := d.Info(); err == nil {
+					total += info.Size()
+				}
+			}
+			return nil
+		})
+	}
+	return total
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// MaxRevisionDepth bounds how far Revisions walks back through previous links
+const MaxRevisionDepth = 100
+
+var (
+	// ErrRevisionsUnavailable is returned when no DAG store is configured
+	// or the article was never published as a revision node
+	ErrRevisionsUnavailable = errors.New("article has no revision graph")
+	// ErrInvalidNodePath is returned for malformed partial-fetch paths
+	ErrInvalidNodePath = errors.New("invalid node path")
+)
+
+// publishRevision stores the article as a dag-cbor node linked to previous
+// and records the node CID on the article. Failures are logged, not
+// returned: the JSON blob remains the article's canonical copy.
+func (s *ArticleService) publishRevision(ctx context.Context, article *domain.Article, previous string) {
+	if s.dag == nil || domain.IsLocalCID(article.CID) {
+		return
+	}
+
+	nodeCID, err := s.dag.DagPut(ctx, domain.NewArticleNode(article, previous))
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to publish revision node", "article_id", article.ID, "error", err)
+		return
+	}
+	article.NodeCID = nodeCID
+}
+
+// Revisions walks an article's revision graph from the newest node back,
+// verifying each revision's signature. The walk stops at the first
+// revision that fails verification, since its links can't be trusted.
+func (s *ArticleService) Revisions(ctx context.Context, cid string) ([]*domain.ArticleRevision, error) {
+	article, err := s.articleRepo.GetByCID(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	if s.dag == nil || article.NodeCID == "" {
+		return nil, ErrRevisionsUnavailable
+	}
+
+	revisions, err := s.walkRevisions(ctx, article)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// walkRevisions follows previous links from the article's latest node.
+// If a node can't be fetched, the revisions walked so far are returned
+// with the error.
+func (s *ArticleService) walkRevisions(ctx context.Context, article *domain.Article) ([]*domain.ArticleRevision, error) {
+	var revisions []*domain.ArticleRevision
+	next := article.NodeCID
+	for next != "" && len(revisions) < MaxRevisionDepth {
+		var node domain.ArticleNode
+		if err := s.dag.DagGet(ctx, next, &node); err != nil {
+			return revisions, err
+		}
+
+		revision := &domain.ArticleRevision{CID: next, Node: &node}
+		revision.Verified = node.Type == domain.ArticleNodeType &&
+			node.ID == article.ID &&
+			s.signer.VerifyArticle(node.Article()) == nil
+		revisions = append(revisions, revision)
+
+		if !revision.Verified {
+			s.logger.Ctx(ctx).Warn("Revision failed verification", "article_id", article.ID, "node", next)
+			break
+		}
+
+		next = ""
+		if node.Previous != nil {
+			next = node.Previous.CID
+		}
+	}
+
+	return revisions, nil
+}
+
+// NodeField fetches part of an article's latest revision node, e.g. "title"
+// or "attachments/0", without transferring the rest of the article
+func (s *ArticleService) NodeField(ctx context.Context, cid, path string) (interface{}, error) {
+	path = strings.Trim(path, "/")
+	if path == "" || strings.Contains(path, "//") || strings.Contains(path, "..") {
+		return nil, ErrInvalidNodePath
+	}
+
+	article, err := s.articleRepo.GetByCID(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	if s.dag == nil || article.NodeCID == "" {
+		return nil, ErrRevisionsUnavailable
+	}
+
+	var value interface{}
+	if err := s.dag.DagGet(ctx, article.NodeCID+"/"+path, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
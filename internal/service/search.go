@@ -2,35 +2,353 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
 	"github.com/amiyamandal-dev/newsp2p/internal/repository"
 	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/tracing"
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
 
+// ErrReindexUnsupported is returned when the configured index can't be rebuilt
+var ErrReindexUnsupported = errors.New("search index does not support rebuilding")
+
+// IndexRebuilder is implemented by indexes that can be rebuilt from the repository
+type IndexRebuilder interface {
+	Rebuild(ctx context.Context, source search.ArticleSource) (int, error)
+}
+
+// reindexPageSize is the number of articles read from the repository at a time
+const reindexPageSize = 500
+
+// ReindexReport describes a running or finished reindex
+type ReindexReport struct {
+	Running      bool      `json:"running"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	ArticlesRead int       `json:"articles_read"` // progress while running
+	Documents    int       `json:"documents"`     // written to the new index, once finished
+	DurationMs   int64     `json:"duration_ms"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// NetworkSearcher forwards searches to connected peers
+type NetworkSearcher interface {
+	// SearchPeers returns articles found by peers, deduplicated by CID,
+	// and the number of peers that answered
+	SearchPeers(ctx context.Context, query *search.SearchQuery) ([]*domain.Article, int, error)
+}
+
+// RemoteArticleChecker decides whether an article a peer returned may be
+// shown, as if it had arrived over gossip
+type RemoteArticleChecker interface {
+	CheckRemoteArticle(ctx context.Context, article *domain.Article) error
+}
+
+// TrustScorer rates an article's trustworthiness on a 0-100 scale
+type TrustScorer interface {
+	ArticleTrust(article *domain.Article) float64
+}
+
+// SearchObserver is told how long each search took. kind is the query's
+// scope, or "suggest" for autocomplete.
+type SearchObserver interface {
+	ObserveSearch(kind string, d time.Duration, err error)
+}
+
 // SearchService handles search-related operations
 type SearchService struct {
-	index       search.Index
-	articleRepo repository.ArticleRepository
-	logger      *logger.Logger
+	index        search.Index
+	articleRepo  repository.ArticleRepository
+	network      NetworkSearcher
+	remote       RemoteArticleChecker
+	trust        TrustScorer
+	observer     SearchObserver
+	maxFuzziness int
+
+	// Short-lived cache of local results, purged on every index write.
+	// cacheGen guards against storing results computed before a purge.
+	resultCache *expirable.LRU[string, *search.SearchResult]
+	cacheGen    atomic.Uint64
+
+	reindexMu sync.Mutex
+	reindex   *ReindexReport // running or most recent reindex
+
+	logger *logger.Logger
 }
 
 // NewSearchService creates a new search service
 func NewSearchService(
 	index search.Index,
 	articleRepo repository.ArticleRepository,
+	maxFuzziness int,
 	logger *logger.Logger,
 ) *SearchService {
 	return &SearchService{
-		index:       index,
-		articleRepo: articleRepo,
-		logger:      logger.WithComponent("search-service"),
+		index:        index,
+		articleRepo:  articleRepo,
+		maxFuzziness: maxFuzziness,
+		logger:       logger.WithComponent("search-service"),
+	}
+}
+
+// SetNetworkSearcher enables scope=network searches
+func (s *SearchService) SetNetworkSearcher(network NetworkSearcher) {
+	s.network = network
+}
+
+// SetRemoteChecker drops peer results that fail checker's checks
+func (s *SearchService) SetRemoteChecker(checker RemoteArticleChecker) {
+	s.remote = checker
+}
+
+// SetTrustScorer enables min_trust filtering
+func (s *SearchService) SetTrustScorer(trust TrustScorer) {
+	s.trust = trust
+}
+
+// SetObserver reports search timings to observer
+func (s *SearchService) SetObserver(observer SearchObserver) {
+	s.observer = observer
+}
+
+// observe reports a search that started at start, if anyone is listening
+func (s *SearchService) observe(kind string, start time.Time, err error) {
+	if s.observer != nil {
+		s.observer.ObserveSearch(kind, time.Since(start), err)
+	}
+}
+
+// SetResultCache caches local search results for ttl, holding up to size
+// queries. Network merging and trust filtering still run on every request.
+func (s *SearchService) SetResultCache(size int, ttl time.Duration) {
+	if size <= 0 || ttl <= 0 {
+		s.resultCache = nil
+		return
+	}
+	s.resultCache = expirable.NewLRU[string, *search.SearchResult](size, nil, ttl)
+}
+
+// Search performs a full-text search with filtering. With network scope the
+// first page is topped up with peer results the local index doesn't have.
+func (s *SearchService) Search(ctx context.Context, query *search.SearchQuery) (result *search.SearchResult, err error) {
+	s.applyDefaults(query)
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	scope := query.Scope
+	if scope == "" {
+		scope = search.ScopeLocal
+	}
+	ctx, span := tracing.Start(ctx, "search.query",
+		attribute.String("search.scope", scope),
+		attribute.Int("search.page", query.Page),
+	)
+	defer func(start time.Time) {
+		s.observe(scope, start, err)
+		tracing.End(span, err)
+	}(time.Now())
+
+	if query.MinTrust > 0 && s.trust != nil && query.DocType == search.DocTypeArticle {
+		result, err = s.searchTrusted(ctx, query)
+	} else {
+		result, err = s.searchCached(ctx, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Labels are this node's own, so peers' results can't be held to them
+	labelled := len(query.Labels) > 0 || len(query.ExcludeLabels) > 0
+	if query.Scope == search.ScopeNetwork && s.network != nil && query.Page == 1 && !labelled {
+		s.mergeNetworkResults(ctx, query, result)
+	}
+
+	return result, nil
+}
+
+// maxTrustScan bounds the local hits min_trust filtering considers
+const maxTrustScan = 1000
+
+// searchTrusted drops articles scoring below query.MinTrust before paging,
+// so Total and TotalPages count only the articles kept. Scores come from
+// the live reputation system rather than the values stored at index time,
+// so the first maxTrustScan hits are fetched, filtered and paged here.
+func (s *SearchService) searchTrusted(ctx context.Context, query *search.SearchQuery) (*search.SearchResult, error) {
+	scan := *query
+	scan.Page, scan.Limit = 1, maxTrustScan
+	result, err := s.searchCached(ctx, &scan)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]*domain.Article, 0, len(result.Articles))
+	for _, article := range result.Articles {
+		if s.trustedEnough(query, article) {
+			kept = append(kept, article)
+		}
+	}
+
+	start := min((query.Page-1)*query.Limit, len(kept))
+	end := min(start+query.Limit, len(kept))
+	page := kept[start:end]
+
+	ids := make([]string, len(page))
+	highlights := make(map[string]*search.HitHighlight)
+	for i, article := range page {
+		ids[i] = article.ID
+		if highlight, ok := result.Highlights[article.ID]; ok {
+			highlights[article.ID] = highlight
+		}
+	}
+
+	result.FilteredLowTrust = len(result.Articles) - len(kept)
+	result.Articles = page
+	result.IDs = ids
+	result.Highlights = highlights
+	result.Total = len(kept)
+	result.Page = query.Page
+	result.Limit = query.Limit
+	result.TotalPages = (len(kept) + query.Limit - 1) / query.Limit
+	return result, nil
+}
+
+// trustedEnough reports whether article meets query.MinTrust, if set
+func (s *SearchService) trustedEnough(query *search.SearchQuery, article *domain.Article) bool {
+	return query.MinTrust <= 0 || s.trust == nil || s.trust.ArticleTrust(article) >= query.MinTrust
+}
+
+// mergeNetworkResults appends peer results whose CID isn't already in the
+// local page, up to the page limit. Peer failures only reduce the results.
+func (s *SearchService) mergeNetworkResults(ctx context.Context, query *search.SearchQuery, result *search.SearchResult) {
+	remote, responded, err := s.network.SearchPeers(ctx, query)
+	result.PeersResponded = responded
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Network search failed", "query", query.Query, "error", err)
+		return
+	}
+
+	seen := make(map[string]bool, len(result.Articles))
+	for _, article := range result.Articles {
+		seen[search.ArticleKey(article)] = true
+	}
+
+	for _, article := range remote {
+		if len(result.Articles) >= query.Limit {
+			break
+		}
+		key := search.ArticleKey(article)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !s.trustedEnough(query, article) {
+			result.FilteredLowTrust++
+			continue
+		}
+		if s.remote != nil {
+			if err := s.remote.CheckRemoteArticle(ctx, article); err != nil {
+				s.logger.Ctx(ctx).Debug("Dropping peer search result", "article_id", article.ID, "error", err)
+				continue
+			}
+		}
+		result.Articles = append(result.Articles, article)
+		result.RemoteResults++
+	}
+
+	s.logger.Ctx(ctx).Debug("Network search merged",
+		"query", query.Query,
+		"peers", responded,
+		"remote_results", result.RemoteResults,
+	)
+}
+
+// searchCached serves local results from the result cache when enabled
+func (s *SearchService) searchCached(ctx context.Context, query *search.SearchQuery) (*search.SearchResult, error) {
+	if s.resultCache == nil {
+		return s.searchLocal(ctx, query)
 	}
+
+	key := resultCacheKey(query)
+	if cached, ok := s.resultCache.Get(key); ok {
+		return copyResult(cached), nil
+	}
+
+	gen := s.cacheGen.Load()
+	result, err := s.searchLocal(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if s.cacheGen.Load() == gen {
+		s.resultCache.Add(key, copyResult(result))
+	}
+	return result, nil
+}
+
+// invalidateResults drops cached results after the index changes
+func (s *SearchService) invalidateResults() {
+	if s.resultCache == nil {
+		return
+	}
+	s.cacheGen.Add(1)
+	s.resultCache.Purge()
 }
 
-// Search performs a full-text search with filtering
-func (s *SearchService) Search(ctx context.Context, query *search.SearchQuery) (*search.SearchResult, error) {
+// resultCacheKey normalizes a query so equivalent searches share an entry.
+// Call after applyDefaults.
+func resultCacheKey(query *search.SearchQuery) string {
+	tags := make([]string, len(query.Tags))
+	for i, tag := range query.Tags {
+		tags[i] = strings.ToLower(strings.TrimSpace(tag))
+	}
+	sort.Strings(tags)
+	labels := slices.Sorted(slices.Values(query.Labels))
+	excluded := slices.Sorted(slices.Values(query.ExcludeLabels))
+
+	return fmt.Sprintf("%q|%q|%q|%q|%d|%d|%d|%d|%d|%t|%s|%s|%q|%q",
+		strings.Join(strings.Fields(strings.ToLower(query.Query)), " "),
+		strings.ToLower(query.Author),
+		strings.ToLower(query.Category),
+		strings.Join(tags, ","),
+		query.FromDate.Unix(),
+		query.ToDate.Unix(),
+		query.Page,
+		query.Limit,
+		query.Fuzziness,
+		query.Prefix,
+		query.SortBy,
+		query.DocType,
+		strings.Join(labels, ","),
+		strings.Join(excluded, ","),
+	)
+}
+
+// copyResult deep-copies a result's articles and copies its slices, so
+// callers can change them without touching the cached value
+func copyResult(result *search.SearchResult) *search.SearchResult {
+	copied := *result
+	copied.Articles = make([]*domain.Article, len(result.Articles))
+	for i, article := range result.Articles {
+		copied.Articles[i] = article.Clone()
+	}
+	copied.IDs = append([]string(nil), result.IDs...)
+	copied.Comments = append([]*search.CommentHit(nil), result.Comments...)
+	return &copied
+}
+
+// applyDefaults fills in and clamps paging, fuzziness, sort and type
+func (s *SearchService) applyDefaults(query *search.SearchQuery) {
 	// Set defaults to avoid division by zero
 	if query.Page < 1 {
 		query.Page = 1
@@ -41,12 +359,30 @@ func (s *SearchService) Search(ctx context.Context, query *search.SearchQuery) (
 	if query.Limit > 100 {
 		query.Limit = 100
 	}
+	if query.Fuzziness > s.maxFuzziness {
+		query.Fuzziness = s.maxFuzziness
+	}
+	if query.Fuzziness < 0 {
+		query.Fuzziness = 0
+	}
 
-	// If there's a text query, use the full-text search index
-	if query.Query != "" {
+	if query.SortBy == "" {
+		query.SortBy = search.SortRelevance
+	}
+	if query.DocType == "" {
+		query.DocType = search.DocTypeArticle
+	}
+}
+
+// searchLocal searches the local index or repository
+func (s *SearchService) searchLocal(ctx context.Context, query *search.SearchQuery) (*search.SearchResult, error) {
+	// Use the search index for text queries, for comments, for labels and
+	// for orders the repository's newest-first listing can't produce
+	labelled := len(query.Labels) > 0 || len(query.ExcludeLabels) > 0
+	if query.Query != "" || query.DocType != search.DocTypeArticle || labelled || !repositoryOrdered(query.SortBy) {
 		result, err := s.index.Search(ctx, query)
 		if err != nil {
-			s.logger.Error("Full-text search failed", "error", err)
+			s.logger.Ctx(ctx).Error("Full-text search failed", "error", err)
 			return nil, err
 		}
 
@@ -54,17 +390,18 @@ func (s *SearchService) Search(ctx context.Context, query *search.SearchQuery) (
 		if len(result.IDs) > 0 {
 			articles, err := s.articleRepo.GetByIDs(ctx, result.IDs)
 			if err != nil {
-				s.logger.Error("Failed to fetch articles by IDs", "error", err)
+				s.logger.Ctx(ctx).Error("Failed to fetch articles by IDs", "error", err)
 				return nil, err
 			}
 			result.Articles = articles
 		}
 
-		s.logger.Debug("Full-text search completed",
+		s.logger.Ctx(ctx).Debug("Full-text search completed",
 			"query", query.Query,
 			"results", result.Total,
 			"articles_fetched", len(result.Articles),
 			"page", query.Page,
+			"sort", query.SortBy,
 		)
 		return result, nil
 	}
@@ -82,7 +419,7 @@ func (s *SearchService) Search(ctx context.Context, query *search.SearchQuery) (
 
 	articles, total, err := s.articleRepo.List(ctx, filter)
 	if err != nil {
-		s.logger.Error("Search failed", "error", err)
+		s.logger.Ctx(ctx).Error("Search failed", "error", err)
 		return nil, err
 	}
 
@@ -103,7 +440,7 @@ func (s *SearchService) Search(ctx context.Context, query *search.SearchQuery) (
 		QueryTime:  0,
 	}
 
-	s.logger.Debug("Search completed",
+	s.logger.Ctx(ctx).Debug("Search completed",
 		"query", query.Query,
 		"results", total,
 		"page", query.Page,
@@ -112,18 +449,187 @@ func (s *SearchService) Search(ctx context.Context, query *search.SearchQuery) (
 	return result, nil
 }
 
+// repositoryOrdered reports whether a filter-only search in the given order
+// can be served by the repository, which lists newest first
+func repositoryOrdered(order search.SortOrder) bool {
+	return order == search.SortRelevance || order == search.SortNewest
+}
+
+// Suggest returns autocomplete suggestions for a partially typed query.
+// The last word is completed; term suggestions carry the preceding words
+// so they can replace the whole input.
+func (s *SearchService) Suggest(ctx context.Context, q string, limit int) ([]search.Suggestion, error) {
+	if limit < 1 || limit > 20 {
+		limit = 10
+	}
+
+	words := strings.Fields(q)
+	if len(words) == 0 || strings.HasSuffix(q, " ") {
+		return []search.Suggestion{}, nil
+	}
+	prefix := words[len(words)-1]
+	lead := strings.Join(words[:len(words)-1], " ")
+
+	spanCtx, span := tracing.Start(ctx, "search.suggest")
+	start := time.Now()
+	suggestions, err := s.index.Suggest(spanCtx, prefix, limit)
+	s.observe("suggest", start, err)
+	tracing.End(span, err)
+	if err != nil {
+		s.logger.Ctx(ctx).Error("Suggest failed", "prefix", prefix, "error", err)
+		return nil, err
+	}
+
+	if lead != "" {
+		for i := range suggestions {
+			if suggestions[i].Kind != search.SuggestionTitle {
+				suggestions[i].Text = lead + " " + suggestions[i].Text
+			}
+		}
+	}
+
+	return suggestions, nil
+}
+
+// Reindex rebuilds the search index from every article in the repository
+// and swaps it in, e.g. after a mapping change or index corruption. It
+// returns when the rebuild is done; StartReindex runs it in the background.
+func (s *SearchService) Reindex(ctx context.Context) (*ReindexReport, error) {
+	rebuilder, err := s.beginReindex()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.runReindex(ctx, rebuilder); err != nil {
+		return nil, err
+	}
+	return s.ReindexStatus(), nil
+}
+
+// StartReindex starts a reindex in the background and returns its status.
+// The rebuild outlives ctx's cancellation, so it isn't cut short when the
+// request that started it ends; follow it with ReindexStatus.
+func (s *SearchService) StartReindex(ctx context.Context) (*ReindexReport, error) {
+	rebuilder, err := s.beginReindex()
+	if err != nil {
+		return nil, err
+	}
+	go s.runReindex(context.WithoutCancel(ctx), rebuilder)
+	return s.ReindexStatus(), nil
+}
+
+// ReindexStatus returns the running or most recent reindex, or nil if the
+// index hasn't been rebuilt since startup
+func (s *SearchService) ReindexStatus() *ReindexReport {
+	s.reindexMu.Lock()
+	defer s.reindexMu.Unlock()
+	if s.reindex == nil {
+		return nil
+	}
+	report := *s.reindex
+	return &report
+}
+
+// beginReindex records a new running reindex, refusing to start a second
+func (s *SearchService) beginReindex() (IndexRebuilder, error) {
+	rebuilder, ok := s.index.(IndexRebuilder)
+	if !ok {
+		return nil, ErrReindexUnsupported
+	}
+
+	s.reindexMu.Lock()
+	defer s.reindexMu.Unlock()
+	if s.reindex != nil && s.reindex.Running {
+		return nil, search.ErrRebuildInProgress
+	}
+	s.reindex = &ReindexReport{Running: true, StartedAt: time.Now()}
+	return rebuilder, nil
+}
+
+// runReindex rebuilds the index from the repository a page at a time and
+// records the outcome in the reindex status
+func (s *SearchService) runReindex(ctx context.Context, rebuilder IndexRebuilder) error {
+	defer s.invalidateResults()
+
+	count, err := rebuilder.Rebuild(ctx, func(add func(*domain.Article) error) error {
+		filter := &domain.ArticleListFilter{Page: 1, Limit: reindexPageSize}
+		for {
+			articles, _, err := s.articleRepo.List(ctx, filter)
+			if err != nil {
+				return err
+			}
+			for _, article := range articles {
+				if err := add(article); err != nil {
+					return err
+				}
+			}
+
+			s.reindexMu.Lock()
+			s.reindex.ArticlesRead += len(articles)
+			s.reindexMu.Unlock()
+
+			if len(articles) < reindexPageSize {
+				return nil
+			}
+			last := articles[len(articles)-1]
+			filter.After = &domain.ArticleCursor{Timestamp: last.Timestamp, ID: last.ID}
+		}
+	})
+
+	s.reindexMu.Lock()
+	report := s.reindex
+	report.Running = false
+	report.FinishedAt = time.Now()
+	report.DurationMs = report.FinishedAt.Sub(report.StartedAt).Milliseconds()
+	if err != nil {
+		report.Error = err.Error()
+	} else {
+		report.Documents = count
+	}
+	s.reindexMu.Unlock()
+
+	if err != nil {
+		s.logger.Ctx(ctx).Error("Reindex failed", "error", err)
+		return err
+	}
+	s.logger.Ctx(ctx).Info("Reindex completed", "documents", count, "duration_ms", report.DurationMs)
+	return nil
+}
+
+// IndexComment indexes a comment for search
+func (s *SearchService) IndexComment(ctx context.Context, comment *domain.Comment) error {
+	defer s.invalidateResults()
+	return s.index.IndexComment(ctx, comment)
+}
+
+// DeleteComment removes a comment from the search index
+func (s *SearchService) DeleteComment(ctx context.Context, commentID string) error {
+	defer s.invalidateResults()
+	return s.index.DeleteComment(ctx, commentID)
+}
+
 // IndexArticle indexes an article for search
-func (s *SearchService) IndexArticle(ctx context.Context, article *domain.Article) error {
+func (s *SearchService) IndexArticle(ctx context.Context, article *domain.Article) (err error) {
+	ctx, span := tracing.Start(ctx, "search.index_article", attribute.String("article.id", article.ID))
+	defer func() { tracing.End(span, err) }()
+	defer s.invalidateResults()
 	return s.index.IndexArticle(ctx, article)
 }
 
+// IndexArticles indexes several articles for search in one write
+func (s *SearchService) IndexArticles(ctx context.Context, articles []*domain.Article) error {
+	defer s.invalidateResults()
+	return s.index.IndexArticles(ctx, articles)
+}
+
 // UpdateArticle updates an article in the search index
 func (s *SearchService) UpdateArticle(ctx context.Context, article *domain.Article) error {
+	defer s.invalidateResults()
 	return s.index.UpdateArticle(ctx, article)
 }
 
 // DeleteArticle removes an article from the search index
 func (s *SearchService) DeleteArticle(ctx context.Context, articleID string) error {
+	defer s.invalidateResults()
 	return s.index.DeleteArticle(ctx, articleID)
 }
 
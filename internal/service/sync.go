@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
+	"strconv"
 	"time"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
@@ -12,12 +14,19 @@ import (
 	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
 )
 
+// FeedMirror lays a feed out as a directory tree in the node's MFS
+type FeedMirror interface {
+	MirrorDirectory(ctx context.Context, dir string, entries []ipfs.DirectoryEntry) (string, error)
+}
+
 // SyncService handles background feed synchronization to IPNS
 type SyncService struct {
 	feedRepo    repository.FeedRepository
 	articleRepo repository.ArticleRepository
 	ipfsClient  IPFSClient
 	ipnsManager *ipfs.IPNSManager
+	mirror      FeedMirror
+	mirrorRoot  string
 	logger      *logger.Logger
 	stopChan    chan struct{}
 }
@@ -40,9 +49,16 @@ func NewSyncService(
 	}
 }
 
+// SetMirror mirrors every published feed under root in MFS, as
+// <root>/<feed>/<date>/<slug>.json, so it can be browsed through a gateway
+func (s *SyncService) SetMirror(mirror FeedMirror, root string) {
+	s.mirror = mirror
+	s.mirrorRoot = root
+}
+
 // Start starts the background sync service
 func (s *SyncService) Start(ctx context.Context, intervalMinutes int) {
-	s.logger.Info("Starting feed sync service", "interval_minutes", intervalMinutes)
+	s.logger.Ctx(ctx).Info("Starting feed sync service", "interval_minutes", intervalMinutes)
 
 	ticker := time.NewTicker(time.Duration(intervalMinutes) * time.Minute)
 	defer ticker.Stop()
@@ -55,10 +71,10 @@ func (s *SyncService) Start(ctx context.Context, intervalMinutes int) {
 		case <-ticker.C:
 			s.syncAllFeeds(ctx)
 		case <-s.stopChan:
-			s.logger.Info("Stopping feed sync service")
+			s.logger.Ctx(ctx).Info("Stopping feed sync service")
 			return
 		case <-ctx.Done():
-			s.logger.Info("Context cancelled, stopping sync service")
+			s.logger.Ctx(ctx).Info("Context cancelled, stopping sync service")
 			return
 		}
 	}
@@ -73,15 +89,15 @@ func (s *SyncService) Stop() {
 func (s *SyncService) syncAllFeeds(ctx context.Context) {
 	feeds, err := s.feedRepo.ListDueForSync(ctx)
 	if err != nil {
-		s.logger.Error("Failed to list feeds due for sync", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to list feeds due for sync", "error", err)
 		return
 	}
 
-	s.logger.Debug("Found feeds to sync", "count", len(feeds))
+	s.logger.Ctx(ctx).Debug("Found feeds to sync", "count", len(feeds))
 
 	for _, feed := range feeds {
 		if err := s.syncFeed(ctx, feed); err != nil {
-			s.logger.Error("Failed to sync feed",
+			s.logger.Ctx(ctx).Error("Failed to sync feed",
 				"feed_name", feed.Name,
 				"error", err,
 			)
@@ -91,7 +107,7 @@ func (s *SyncService) syncAllFeeds(ctx context.Context) {
 
 // syncFeed syncs a single feed to IPNS
 func (s *SyncService) syncFeed(ctx context.Context, feed *domain.Feed) error {
-	s.logger.Info("Syncing feed", "feed_name", feed.Name)
+	s.logger.Ctx(ctx).Info("Syncing feed", "feed_name", feed.Name)
 
 	// Get recent articles
 	articles, err := s.articleRepo.ListRecent(ctx, 100)
@@ -128,7 +144,7 @@ func (s *SyncService) syncFeed(ctx context.Context, feed *domain.Feed) error {
 		return fmt.Errorf("failed to upload manifest to IPFS: %w", err)
 	}
 
-	s.logger.Debug("Uploaded manifest to IPFS",
+	s.logger.Ctx(ctx).Debug("Uploaded manifest to IPFS",
 		"feed_name", feed.Name,
 		"manifest_cid", manifestCID,
 		"article_count", len(cids),
@@ -140,7 +156,7 @@ func (s *SyncService) syncFeed(ctx context.Context, feed *domain.Feed) error {
 		return fmt.Errorf("failed to publish to IPNS: %w", err)
 	}
 
-	s.logger.Info("Published feed to IPNS",
+	s.logger.Ctx(ctx).Info("Published feed to IPNS",
 		"feed_name", feed.Name,
 		"manifest_cid", manifestCID,
 		"ipns_path", ipnsPath,
@@ -149,7 +165,7 @@ func (s *SyncService) syncFeed(ctx context.Context, feed *domain.Feed) error {
 	// Unpin old manifest if exists
 	if feed.LastCID != "" && feed.LastCID != manifestCID {
 		if err := s.ipfsClient.Unpin(ctx, feed.LastCID); err != nil {
-			s.logger.Warn("Failed to unpin old manifest",
+			s.logger.Ctx(ctx).Warn("Failed to unpin old manifest",
 				"feed_name", feed.Name,
 				"old_cid", feed.LastCID,
 				"error", err,
@@ -157,24 +173,66 @@ func (s *SyncService) syncFeed(ctx context.Context, feed *domain.Feed) error {
 		}
 	}
 
+	if s.mirror != nil {
+		s.mirrorFeed(ctx, feed, manifestCID, articles)
+	}
+
 	// Update feed record
 	feed.LastCID = manifestCID
 	feed.LastSync = time.Now()
 	feed.IPNSAddress = ipnsPath
 
 	if err := s.feedRepo.Update(ctx, feed); err != nil {
-		s.logger.Error("Failed to update feed record",
+		s.logger.Ctx(ctx).Error("Failed to update feed record",
 			"feed_name", feed.Name,
 			"error", err,
 		)
 		return fmt.Errorf("failed to update feed: %w", err)
 	}
 
-	s.logger.Info("Feed sync completed successfully", "feed_name", feed.Name)
+	s.logger.Ctx(ctx).Info("Feed sync completed successfully", "feed_name", feed.Name)
 
 	return nil
 }
 
+// mirrorFeed rebuilds the feed's MFS directory. The IPNS manifest is the
+// source of truth, so a failure here is logged and the sync carries on.
+func (s *SyncService) mirrorFeed(ctx context.Context, feed *domain.Feed, manifestCID string, articles []*domain.Article) {
+	dir := path.Join(s.mirrorRoot, feed.Name)
+	root, err := s.mirror.MirrorDirectory(ctx, dir, FeedDirectoryEntries(manifestCID, articles))
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Failed to mirror feed into MFS", "feed_name", feed.Name, "path", dir, "error", err)
+		return
+	}
+
+	feed.DirectoryCID = root
+	s.logger.Ctx(ctx).Info("Mirrored feed into MFS", "feed_name", feed.Name, "path", dir, "directory_cid", root)
+}
+
+// FeedDirectoryEntries lays articles out as <date>/<slug>.json next to the
+// feed's manifest.json. Articles with the same slug on the same day are
+// numbered; articles that never reached IPFS are left out.
+func FeedDirectoryEntries(manifestCID string, articles []*domain.Article) []ipfs.DirectoryEntry {
+	entries := []ipfs.DirectoryEntry{{Path: "manifest.json", CID: manifestCID}}
+	taken := make(map[string]bool, len(articles))
+
+	for _, article := range articles {
+		if article.CID == "" || domain.IsLocalCID(article.CID) {
+			continue
+		}
+
+		base := path.Join(article.Timestamp.UTC().Format("2006-01-02"), article.Slug())
+		name := base + ".json"
+		for n := 2; taken[name]; n++ {
+			name = base + "-" + strconv.Itoa(n) + ".json"
+		}
+		taken[name] = true
+
+		entries = append(entries, ipfs.DirectoryEntry{Path: name, CID: article.CID})
+	}
+	return entries
+}
+
 // TriggerSync manually triggers a sync for a specific feed
 func (s *SyncService) TriggerSync(ctx context.Context, feedName string) error {
 	feed, err := s.feedRepo.GetByName(ctx, feedName)
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// TrustGraphService keeps the trust graph of this node's users. Users mark
+// identities as trusted or blocked; trust then flows through the
+// identities they trust, losing weight with every hop, up to maxDepth
+// hops. Scores are computed from the stored edges when asked for, so they
+// follow every change at once.
+type TrustGraphService struct {
+	repo        repository.TrustEdgeRepository
+	userRepo    repository.UserRepository
+	articleRepo repository.ArticleRepository
+	maxDepth    int
+	decay       float64 // share of trust passed on with each hop
+	logger      *logger.Logger
+}
+
+// NewTrustGraphService creates a new trust graph service
+func NewTrustGraphService(
+	repo repository.TrustEdgeRepository,
+	userRepo repository.UserRepository,
+	articleRepo repository.ArticleRepository,
+	maxDepth int,
+	decay float64,
+	logger *logger.Logger,
+) *TrustGraphService {
+	return &TrustGraphService{
+		repo:        repo,
+		userRepo:    userRepo,
+		articleRepo: articleRepo,
+		maxDepth:    maxDepth,
+		decay:       decay,
+		logger:      logger.WithComponent("trust-graph"),
+	}
+}
+
+// SetEdge marks subject as trusted or blocked by the user, replacing any
+// earlier mark of theirs
+func (s *TrustGraphService) SetEdge(ctx context.Context, userID, subject string, req *domain.TrustEdgeRequest) (*domain.TrustEdge, error) {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	edge := &domain.TrustEdge{
+		Truster:   user.Username,
+		Subject:   subject,
+		Kind:      req.Kind,
+		Note:      req.Note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	previous, err := s.repo.Get(ctx, user.Username, subject)
+	switch {
+	case err == nil:
+		edge.CreatedAt = previous.CreatedAt
+	case !errors.Is(err, domain.ErrTrustEdgeNotFound):
+		return nil, err
+	}
+	if err := edge.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, edge); err != nil {
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Trust edge saved", "truster", edge.Truster, "subject", subject, "kind", edge.Kind)
+	return edge, nil
+}
+
+// RemoveEdge withdraws the user's mark on subject
+func (s *TrustGraphService) RemoveEdge(ctx context.Context, userID, subject string) error {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, user.Username, subject); err != nil {
+		return err
+	}
+
+	s.logger.Ctx(ctx).Info("Trust edge removed", "truster", user.Username, "subject", subject)
+	return nil
+}
+
+func (s *TrustGraphService) activeUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, domain.ErrUserNotActive
+	}
+	return user, nil
+}
+
+// Edges returns the marks viewer made, ordered by subject
+func (s *TrustGraphService) Edges(ctx context.Context, viewer string) ([]*domain.TrustEdge, error) {
+	return s.repo.ListByTruster(ctx, viewer)
+}
+
+// Trusters returns the users who trust viewer, ordered by username. Who
+// blocked them isn't revealed.
+func (s *TrustGraphService) Trusters(ctx context.Context, viewer string) ([]*domain.TrustEdge, error) {
+	edges, err := s.repo.ListBySubject(ctx, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(edges, func(e *domain.TrustEdge) bool { return e.Kind != domain.TrustTrusted }), nil
+}
+
+// Network returns viewer's trust in every identity within reach: trusted
+// ones first, strongest first, then blocked ones
+func (s *TrustGraphService) Network(ctx context.Context, viewer string) ([]domain.TrustScore, error) {
+	scores, err := s.walk(ctx, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	network := make([]domain.TrustScore, 0, len(scores))
+	for _, score := range scores {
+		network = append(network, *score)
+	}
+	slices.SortFunc(network, func(a, b domain.TrustScore) int {
+		if a.Blocked != b.Blocked {
+			if a.Blocked {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(b.Trust, a.Trust), strings.Compare(a.Subject, b.Subject))
+	})
+	return network, nil
+}
+
+// Score returns viewer's trust in subject. Identities out of reach have
+// no trust and aren't blocked.
+func (s *TrustGraphService) Score(ctx context.Context, viewer, subject string) (domain.TrustScore, error) {
+	scores, err := s.walk(ctx, viewer)
+	if err != nil {
+		return domain.TrustScore{}, err
+	}
+	if score, ok := scores[subject]; ok {
+		return *score, nil
+	}
+	return domain.TrustScore{Subject: subject}, nil
+}
+
+// Blocked returns the identities viewer blocks, directly or through the
+// identities they trust
+func (s *TrustGraphService) Blocked(ctx context.Context, viewer string) (map[string]bool, error) {
+	scores, err := s.walk(ctx, viewer)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[string]bool)
+	for subject, score := range scores {
+		if score.Blocked {
+			blocked[subject] = true
+		}
+	}
+	return blocked, nil
+}
+
+// Feed lists the articles by identities viewer trusts and doesn't block,
+// newest first, with the trust in each author
+func (s *TrustGraphService) Feed(ctx context.Context, viewer string, page, limit int) ([]*domain.TrustFeedItem, int, error) {
+	scores, err := s.walk(ctx, viewer)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var authors []string
+	for subject, score := range scores {
+		if !score.Blocked && score.Trust > 0 {
+			authors = append(authors, subject)
+		}
+	}
+	if len(authors) == 0 {
+		return []*domain.TrustFeedItem{}, 0, nil
+	}
+
+	articles, total, err := s.articleRepo.List(ctx, &domain.ArticleListFilter{
+		Authors: authors,
+		Page:    max(page, 1),
+		Limit:   min(max(limit, 1), 100),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*domain.TrustFeedItem, len(articles))
+	for i, article := range articles {
+		items[i] = &domain.TrustFeedItem{Article: article, AuthorTrust: domain.TrustScore{Subject: article.Author}}
+		if score, ok := scores[article.Author]; ok {
+			items[i].AuthorTrust = *score
+		}
+	}
+	return items, total, nil
+}
+
+// walk computes viewer's trust in every identity within maxDepth hops.
+// The graph is walked breadth first, so an identity's first trusted edge
+// is on its strongest path. Every edge carries the weight of the hop it
+// is on: 1 for the viewer's own, times decay for each hop further out.
+// Identities blocked at least as strongly as they are trusted don't pass
+// trust on.
+func (s *TrustGraphService) walk(ctx context.Context, viewer string) (map[string]*domain.TrustScore, error) {
+	scores := make(map[string]*domain.TrustScore)
+	blocks := make(map[string]float64) // the strongest block on each identity
+
+	frontier := []string{viewer}
+	weight := 1.0
+	for depth := 1; depth <= s.maxDepth && len(frontier) > 0; depth++ {
+		var reached []string
+		for _, truster := range frontier {
+			edges, err := s.repo.ListByTruster(ctx, truster)
+			if err != nil {
+				return nil, err
+			}
+			for _, edge := range edges {
+				if edge.Subject == viewer {
+					continue
+				}
+				via := edge.Subject
+				if depth > 1 {
+					via = scores[truster].Via
+				}
+
+				score, ok := scores[edge.Subject]
+				if !ok {
+					score = &domain.TrustScore{Subject: edge.Subject, Depth: depth, Via: via}
+					scores[edge.Subject] = score
+				}
+				switch edge.Kind {
+				case domain.TrustBlocked:
+					blocks[edge.Subject] = max(blocks[edge.Subject], weight)
+				case domain.TrustTrusted:
+					if score.Trust > 0 {
+						continue // already reached on a path at least as strong
+					}
+					score.Trust, score.Depth, score.Via = weight, depth, via
+					reached = append(reached, edge.Subject)
+				}
+			}
+		}
+
+		// Blocks on this hop are all known now; later ones weigh less
+		frontier = slices.DeleteFunc(reached, func(id string) bool { return blocks[id] >= scores[id].Trust })
+		weight *= s.decay
+	}
+
+	for subject, score := range scores {
+		score.Blocked = blocks[subject] > 0 && blocks[subject] >= score.Trust
+	}
+	return scores, nil
+}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/media"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+const (
+	// uploadRetention is how long a finished upload can still be subscribed to
+	uploadRetention = time.Minute
+	// pendingUploadTTL drops progress channels opened for uploads that never start
+	pendingUploadTTL = 5 * time.Minute
+)
+
+// MediaStore streams large content to IPFS
+type MediaStore interface {
+	AddStream(ctx context.Context, r io.Reader, chunkSize int64, progress ipfs.ProgressFunc) (string, error)
+}
+
+// Transcoder converts an uploaded audio or video file into other
+// renditions. Every file it writes to outDir is added to IPFS and listed
+// with the original.
+type Transcoder interface {
+	Transcode(ctx context.Context, src, mimeType, outDir string) error
+}
+
+// SizeLimit returns the maximum upload size for a MIME type, or false when
+// the type is not accepted
+type SizeLimit func(mimeType string) (int64, bool)
+
+// UploadService streams media to IPFS and publishes progress to subscribers
+type UploadService struct {
+	store     MediaStore
+	chunkSize int64
+	limit     SizeLimit
+	logger    *logger.Logger
+
+	catalog          repository.MediaRepository // optional; records audio/video uploads
+	spoolDir         string
+	transcoder       Transcoder // optional; needs the catalog
+	transcodeTimeout time.Duration
+	transcodes       chan struct{} // held while a transcode runs
+
+	mu      sync.Mutex
+	uploads map[string]*uploadState
+}
+
+// uploadState tracks one upload and the channels listening to it
+type uploadState struct {
+	owner    string
+	progress domain.UploadProgress
+	notified int64 // bytes sent at the last published update
+	started  bool
+	subs     map[chan domain.UploadProgress]struct{}
+}
+
+// NewUploadService creates a new upload service
+func NewUploadService(store MediaStore, chunkSize int64, limit SizeLimit, logger *logger.Logger) *UploadService {
+	return &UploadService{
+		store:      store,
+		chunkSize:  chunkSize,
+		limit:      limit,
+		logger:     logger.WithComponent("upload-service"),
+		uploads:    make(map[string]*uploadState),
+		transcodes: make(chan struct{}, 1),
+	}
+}
+
+// SetMediaCatalog records audio and video uploads, with the duration read
+// from their headers, so articles can attach them. They are spooled to
+// spoolDir while streaming, or to the system temp directory if it is empty.
+func (s *UploadService) SetMediaCatalog(catalog repository.MediaRepository, spoolDir string) {
+	s.catalog = catalog
+	s.spoolDir = spoolDir
+}
+
+// SetTranscoder runs t on every new audio and video upload in the
+// background, stopping it after timeout. It has no effect without a media
+// catalog to record the renditions in.
+func (s *UploadService) SetTranscoder(t Transcoder, timeout time.Duration) {
+	s.transcoder = t
+	s.transcodeTimeout = timeout
+}
+
+// Media returns the catalog record of an audio or video upload
+func (s *UploadService) Media(ctx context.Context, cid string) (*domain.Media, error) {
+	if s.catalog == nil {
+		return nil, domain.ErrMediaNotFound
+	}
+	return s.catalog.Get(ctx, cid)
+}
+
+// Upload streams r to IPFS. total is the size the client declared (0 if
+// unknown) and is only used for progress; the per-type limit is enforced
+// on the bytes actually read.
+func (s *UploadService) Upload(ctx context.Context, owner, id string, r io.Reader, mimeType string, total int64) (*domain.UploadProgress, error) {
+	limit, ok := s.limit(mimeType)
+	if !ok {
+		return nil, domain.ErrUnsupportedMedia
+	}
+	if total > limit {
+		return nil, domain.ErrUploadTooLarge
+	}
+
+	if err := s.begin(owner, id, mimeType, total); err != nil {
+		return nil, err
+	}
+
+	limited := &limitedReader{r: r, remaining: limit}
+	body := io.Reader(limited)
+
+	// Audio and video are also written to disk, so their headers can be
+	// read wherever the container put them and the transcoder gets a file
+	var spool *os.File
+	if s.catalog != nil && domain.IsTimedMedia(mimeType) {
+		var err error
+		if spool, err = os.CreateTemp(s.spoolDir, "upload-*"); err != nil {
+			err = fmt.Errorf("failed to create spool file: %w", err)
+			s.finish(id, "", nil, err)
+			return nil, err
+		}
+		body = io.TeeReader(limited, spool)
+	}
+
+	cid, err := s.store.AddStream(ctx, body, s.chunkSize, func(sent int64) {
+		s.advance(id, sent)
+	})
+	if limited.exceeded {
+		err = domain.ErrUploadTooLarge
+	}
+
+	var record *domain.Media
+	if spool != nil {
+		if err == nil {
+			record, err = s.record(ctx, owner, cid, mimeType, limit-limited.remaining, spool)
+		} else {
+			removeSpool(spool)
+		}
+	}
+
+	final := s.finish(id, cid, record, err)
+	if err != nil {
+		s.logger.Ctx(ctx).Warn("Upload failed", "upload_id", id, "mime_type", mimeType, "error", err)
+		return nil, err
+	}
+
+	s.logger.Ctx(ctx).Info("Upload complete", "upload_id", id, "cid", cid, "size", final.Sent, "mime_type", mimeType)
+	return &final, nil
+}
+
+// Reserve registers a new upload ID for owner, so its progress can be
+// subscribed to before the file is posted. Reservations that are never
+// used expire.
+func (s *UploadService) Reserve(owner string) string {
+	id := uuid.New().String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.track(owner, id)
+	time.AfterFunc(pendingUploadTTL, func() { s.expire(id, false) })
+	return id
+}
+
+// Subscribe returns a channel of progress updates for an upload owned by
+// owner, which must be reserved or started. The channel is closed once the
+// upload finishes; call the returned function to stop listening.
+func (s *UploadService) Subscribe(owner, id string) (<-chan domain.UploadProgress, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.uploads[id]
+	if !ok || state.owner != owner {
+		return nil, nil, domain.ErrUploadNotFound
+	}
+
+	ch := make(chan domain.UploadProgress, 1)
+	ch <- state.progress
+	if state.progress.Finished() {
+		close(ch)
+		return ch, func() {}, nil
+	}
+
+	state.subs[ch] = struct{}{}
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := state.subs[ch]; ok {
+			delete(state.subs, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// begin marks an upload as started, creating its state if nobody subscribed yet
+func (s *UploadService) begin(owner, id, mimeType string, total int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.uploads[id]
+	if !ok {
+		state = s.track(owner, id)
+	}
+	if state.owner != owner || state.started {
+		return domain.ErrConflict
+	}
+
+	state.started = true
+	state.progress.State = domain.UploadUploading
+	state.progress.MimeType = mimeType
+	state.progress.Total = total
+	s.publish(state)
+	return nil
+}
+
+// advance records bytes sent, publishing roughly once per chunk
+func (s *UploadService) advance(id string, sent int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.uploads[id]
+	if !ok {
+		return
+	}
+	state.progress.Sent = sent
+	if total := state.progress.Total; total > 0 {
+		state.progress.Percent = float64(min(sent, total)) * 100 / float64(total)
+	}
+	if sent-state.notified >= s.chunkSize {
+		state.notified = sent
+		s.publish(state)
+	}
+}
+
+// finish publishes the final state, closes subscribers and schedules cleanup
+func (s *UploadService) finish(id, cid string, record *domain.Media, err error) domain.UploadProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.uploads[id]
+	if err != nil {
+		state.progress.State = domain.UploadFailed
+		state.progress.Error = err.Error()
+	} else {
+		state.progress.State = domain.UploadDone
+		state.progress.CID = cid
+		state.progress.Total = state.progress.Sent
+		state.progress.Percent = 100
+		state.progress.Media = record
+	}
+	s.publish(state)
+
+	for ch := range state.subs {
+		close(ch)
+	}
+	state.subs = make(map[chan domain.UploadProgress]struct{})
+
+	time.AfterFunc(uploadRetention, func() { s.expire(id, true) })
+	return state.progress
+}
+
+// record catalogs a spooled audio/video upload and hands the spool file to
+// the transcoder, removing it if there is none. Uploading content that is
+// already catalogued returns the existing record, unless processing failed
+// last time.
+func (s *UploadService) record(ctx context.Context, owner, cid, mimeType string, size int64, spool *os.File) (*domain.Media, error) {
+	if existing, err := s.catalog.Get(ctx, cid); err == nil && existing.State != domain.MediaFailed {
+		removeSpool(spool)
+		return existing, nil
+	}
+
+	now := time.Now()
+	record := &domain.Media{
+		CID:       cid,
+		Owner:     owner,
+		MimeType:  mimeType,
+		Size:      size,
+		Duration:  media.Duration(spool, size),
+		State:     domain.MediaReady,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if s.transcoder != nil {
+		record.State = domain.MediaProcessing
+	}
+	if err := s.catalog.Save(ctx, record); err != nil {
+		removeSpool(spool)
+		return nil, fmt.Errorf("failed to record media: %w", err)
+	}
+
+	if s.transcoder == nil {
+		removeSpool(spool)
+	} else {
+		go s.transcode(*record, spool)
+	}
+	return record, nil
+}
+
+// transcode runs the transcoder on a spooled upload and records the
+// outcome. Transcodes run one at a time, so a burst of uploads queues up
+// instead of starting an encoder each.
+func (s *UploadService) transcode(record domain.Media, spool *os.File) {
+	s.transcodes <- struct{}{}
+	defer func() { <-s.transcodes }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.transcodeTimeout)
+	renditions, err := s.renditions(ctx, spool.Name(), record.MimeType)
+	cancel()
+	removeSpool(spool)
+
+	record.UpdatedAt = time.Now()
+	if err != nil {
+		record.State = domain.MediaFailed
+		record.Error = err.Error()
+		s.logger.Ctx(ctx).Warn("Transcoding failed", "cid", record.CID, "mime_type", record.MimeType, "error", err)
+	} else {
+		record.State = domain.MediaReady
+		record.Renditions = renditions
+		s.logger.Ctx(ctx).Info("Transcoding complete", "cid", record.CID, "renditions", len(renditions))
+	}
+
+	if err := s.catalog.Save(context.Background(), &record); err != nil {
+		s.logger.Ctx(ctx).Error("Failed to record transcoding result", "cid", record.CID, "error", err)
+	}
+}
+
+// renditions transcodes src into a scratch directory and adds each file
+// the transcoder wrote to IPFS
+func (s *UploadService) renditions(ctx context.Context, src, mimeType string) ([]domain.MediaRendition, error) {
+	outDir, err := os.MkdirTemp(s.spoolDir, "renditions-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rendition directory: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	if err := s.transcoder.Transcode(ctx, src, mimeType, outDir); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read renditions: %w", err)
+	}
+	var renditions []domain.MediaRendition
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		rendition, err := s.addRendition(ctx, filepath.Join(outDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		renditions = append(renditions, rendition)
+	}
+	return renditions, nil
+}
+
+// addRendition streams one transcoded file to IPFS. Its type comes from
+// the extension the transcoder gave it.
+func (s *UploadService) addRendition(ctx context.Context, path string) (domain.MediaRendition, error) {
+	name := filepath.Base(path)
+	f, err := os.Open(path)
+	if err != nil {
+		return domain.MediaRendition{}, fmt.Errorf("failed to open rendition %s: %w", name, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return domain.MediaRendition{}, fmt.Errorf("failed to open rendition %s: %w", name, err)
+	}
+	cid, err := s.store.AddStream(ctx, f, s.chunkSize, nil)
+	if err != nil {
+		return domain.MediaRendition{}, fmt.Errorf("failed to add rendition %s: %w", name, err)
+	}
+	return domain.MediaRendition{Name: name, CID: cid, MimeType: media.TypeByExtension(name), Size: info.Size()}, nil
+}
+
+// removeSpool closes and deletes a spool file
+func removeSpool(spool *os.File) {
+	spool.Close()
+	os.Remove(spool.Name())
+}
+
+// expire forgets an upload; pending-only expiry leaves started uploads alone
+func (s *UploadService) expire(id string, finished bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.uploads[id]
+	if !ok || (!finished && state.started) || (finished && !state.progress.Finished()) {
+		return
+	}
+	for ch := range state.subs {
+		close(ch)
+	}
+	delete(s.uploads, id)
+}
+
+// track registers a pending upload; callers hold s.mu
+func (s *UploadService) track(owner, id string) *uploadState {
+	state := &uploadState{
+		owner:    owner,
+		progress: domain.UploadProgress{ID: id, State: domain.UploadPending},
+		subs:     make(map[chan domain.UploadProgress]struct{}),
+	}
+	s.uploads[id] = state
+	return state
+}
+
+// publish hands the latest snapshot to every subscriber, replacing any
+// update a slow reader hasn't consumed yet; callers hold s.mu
+func (s *UploadService) publish(state *uploadState) {
+	for ch := range state.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- state.progress
+	}
+}
+
+// limitedReader fails once more than remaining bytes have been read
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *limitedReader) Read(buf []byte) (int, error) {
+	if int64(len(buf)) > l.remaining+1 {
+		buf = buf[:l.remaining+1]
+	}
+	n, err := l.r.Read(buf)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		l.exceeded = true
+		return 0, domain.ErrUploadTooLarge
+	}
+	return n, err
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"time"
 
@@ -21,6 +22,7 @@ type UserService struct {
 	userRepo   repository.UserRepository
 	jwtManager *auth.JWTManager
 	bcryptCost int
+	audit      AuditRecorder // optional; records bans
 	logger     *logger.Logger
 }
 
@@ -39,8 +41,55 @@ func NewUserService(
 	}
 }
 
+// SetAuditLog records every deactivation and reactivation in the audit log
+func (s *UserService) SetAuditLog(audit AuditRecorder) {
+	s.audit = audit
+}
+
 // Register registers a new user
 func (s *UserService) Register(ctx context.Context, req *domain.UserRegisterRequest) (*domain.UserResponse, error) {
+	// Generate Ed25519 key pair for article signing
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		s.logger.Ctx(ctx).Error("Failed to generate key pair", "error", err)
+		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+
+	return s.register(ctx, req, keyPair)
+}
+
+// ImportIdentity registers a user with an existing signing key, such as
+// one exported from another node. The key decides the user's ID, so a key
+// can only be imported once.
+func (s *UserService) ImportIdentity(ctx context.Context, req *domain.UserRegisterRequest, privateKey ed25519.PrivateKey) (*domain.UserResponse, error) {
+	keyPair := &crypto.KeyPair{
+		PublicKey:  privateKey.Public().(ed25519.PublicKey),
+		PrivateKey: privateKey,
+	}
+	return s.register(ctx, req, keyPair)
+}
+
+// ExportIdentity returns a user's signing key, once their password checks
+// out
+func (s *UserService) ExportIdentity(ctx context.Context, username, password string) (*domain.User, ed25519.PrivateKey, error) {
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+
+	privateKey, err := crypto.DecryptPrivateKey(user.PrivateKey, user.PasswordHash)
+	if err != nil {
+		// Node users' keys live in the node key file, not the database
+		return nil, nil, fmt.Errorf("user %s has no stored signing key: %w", username, err)
+	}
+	return user, privateKey, nil
+}
+
+// register creates a user whose identity is keyPair
+func (s *UserService) register(ctx context.Context, req *domain.UserRegisterRequest, keyPair *crypto.KeyPair) (*domain.UserResponse, error) {
 	// Validate password length
 	if len(req.Password) < 8 {
 		return nil, fmt.Errorf("password must be at least 8 characters")
@@ -49,7 +98,7 @@ func (s *UserService) Register(ctx context.Context, req *domain.UserRegisterRequ
 	// Check if username exists
 	exists, err := s.userRepo.ExistsByUsername(ctx, req.Username)
 	if err != nil {
-		s.logger.Error("Failed to check username existence", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to check username existence", "error", err)
 		return nil, fmt.Errorf("failed to check username: %w", err)
 	}
 	if exists {
@@ -60,7 +109,7 @@ func (s *UserService) Register(ctx context.Context, req *domain.UserRegisterRequ
 	if req.Email != "" {
 		exists, err = s.userRepo.ExistsByEmail(ctx, req.Email)
 		if err != nil {
-			s.logger.Error("Failed to check email existence", "error", err)
+			s.logger.Ctx(ctx).Error("Failed to check email existence", "error", err)
 			return nil, fmt.Errorf("failed to check email: %w", err)
 		}
 		if exists {
@@ -68,30 +117,26 @@ func (s *UserService) Register(ctx context.Context, req *domain.UserRegisterRequ
 		}
 	}
 
-	// Generate Ed25519 key pair for article signing
-	keyPair, err := crypto.GenerateKeyPair()
-	if err != nil {
-		s.logger.Error("Failed to generate key pair", "error", err)
-		return nil, fmt.Errorf("failed to generate key pair: %w", err)
-	}
-
 	// Generate LibP2P PeerID from public key to be the User ID
 	libp2pPubKey, err := libp2pcrypto.UnmarshalEd25519PublicKey(keyPair.PublicKey)
 	if err != nil {
-		s.logger.Error("Failed to unmarshal public key for libp2p", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to unmarshal public key for libp2p", "error", err)
 		return nil, fmt.Errorf("failed to convert key to libp2p format: %w", err)
 	}
 
 	peerID, err := peer.IDFromPublicKey(libp2pPubKey)
 	if err != nil {
-		s.logger.Error("Failed to generate PeerID", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to generate PeerID", "error", err)
 		return nil, fmt.Errorf("failed to generate peer ID: %w", err)
 	}
+	if _, err := s.userRepo.GetByID(ctx, peerID.String()); err == nil {
+		return nil, domain.ErrUserAlreadyExists
+	}
 
 	// Hash password
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.bcryptCost)
 	if err != nil {
-		s.logger.Error("Failed to hash password", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to hash password", "error", err)
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
@@ -99,7 +144,7 @@ func (s *UserService) Register(ctx context.Context, req *domain.UserRegisterRequ
 	// This allows decryption later using the stored password hash
 	encryptedPrivateKey, err := crypto.EncryptPrivateKey(keyPair.PrivateKey, string(passwordHash))
 	if err != nil {
-		s.logger.Error("Failed to encrypt private key", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to encrypt private key", "error", err)
 		return nil, fmt.Errorf("failed to encrypt private key: %w", err)
 	}
 
@@ -117,11 +162,11 @@ func (s *UserService) Register(ctx context.Context, req *domain.UserRegisterRequ
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
-		s.logger.Error("Failed to create user", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to create user", "error", err)
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	s.logger.Info("User identity created successfully", "peer_id", user.ID, "username", user.Username)
+	s.logger.Ctx(ctx).Info("User identity created successfully", "peer_id", user.ID, "username", user.Username)
 
 	return user.ToResponse(), nil
 }
@@ -134,7 +179,7 @@ func (s *UserService) Login(ctx context.Context, req *domain.UserLoginRequest) (
 		if err == domain.ErrUserNotFound {
 			return nil, domain.ErrInvalidCredentials
 		}
-		s.logger.Error("Failed to get user", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to get user", "error", err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
@@ -151,11 +196,11 @@ func (s *UserService) Login(ctx context.Context, req *domain.UserLoginRequest) (
 	// Generate tokens
 	tokens, err := s.jwtManager.GenerateTokenPair(user.ID, user.Username, user.Email)
 	if err != nil {
-		s.logger.Error("Failed to generate tokens", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to generate tokens", "error", err)
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
-	s.logger.Info("User logged in successfully", "user_id", user.ID, "username", user.Username)
+	s.logger.Ctx(ctx).Info("User logged in successfully", "user_id", user.ID, "username", user.Username)
 
 	return &domain.LoginResponse{
 		User:   user.ToResponse(),
@@ -184,11 +229,11 @@ func (s *UserService) RefreshToken(ctx context.Context, refreshToken string) (*d
 	// Generate new tokens
 	tokens, err := s.jwtManager.GenerateTokenPair(user.ID, user.Username, user.Email)
 	if err != nil {
-		s.logger.Error("Failed to generate tokens", "error", err)
+		s.logger.Ctx(ctx).Error("Failed to generate tokens", "error", err)
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
-	s.logger.Info("Token refreshed successfully", "user_id", user.ID)
+	s.logger.Ctx(ctx).Info("Token refreshed successfully", "user_id", user.ID)
 
 	return tokens, nil
 }
@@ -202,7 +247,7 @@ func (s *UserService) EnsureNodeUser(ctx context.Context, peerID string, pubKey
 	}
 
 	// User not found, create new "Node User"
-	s.logger.Info("Creating new user for P2P Node identity", "peer_id", peerID)
+	s.logger.Ctx(ctx).Info("Creating new user for P2P Node identity", "peer_id", peerID)
 
 	// Convert public key to string
 	pubKeyBytes, err := libp2pcrypto.MarshalPublicKey(pubKey)
@@ -249,6 +294,62 @@ func (s *UserService) GetUser(ctx context.Context, userID string) (*domain.UserR
 	return user.ToResponse(), nil
 }
 
+// ListUsers returns every user, ordered by username
+func (s *UserService) ListUsers(ctx context.Context) ([]*domain.UserResponse, error) {
+	users, err := s.userRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = user.ToResponse()
+	}
+	return responses, nil
+}
+
+// SetActive activates or deactivates a user, found by ID or username.
+// Deactivated users can't log in, publish, vote or comment; tokens they
+// already hold are refused wherever the account is checked.
+func (s *UserService) SetActive(ctx context.Context, ref string, active bool) (*domain.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, ref)
+	if err == domain.ErrUserNotFound {
+		user, err = s.userRepo.GetByUsername(ctx, ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsActive != active {
+		user.IsActive = active
+		user.UpdatedAt = time.Now()
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+		s.logger.Ctx(ctx).Info("User activation changed", "user_id", user.ID, "username", user.Username, "active", active)
+		if s.audit != nil {
+			action := domain.AuditUserDeactivate
+			if active {
+				action = domain.AuditUserActivate
+			}
+			s.audit.Record(ctx, action, user.ID, map[string]string{"username": user.Username})
+		}
+	}
+	return user.ToResponse(), nil
+}
+
+// SetLanguage saves the user's web UI language
+func (s *UserService) SetLanguage(ctx context.Context, userID, language string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user.Language = language
+	user.UpdatedAt = time.Now()
+	return s.userRepo.Update(ctx, user)
+}
+
 // GetUserWithPrivateKey retrieves a user with their private key (for article signing)
 func (s *UserService) GetUserWithPrivateKey(ctx context.Context, userID, password string) (*domain.User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
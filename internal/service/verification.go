@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+)
+
+// Verification explains an article's signature: the signing key and the
+// identities derived from it, whether a user of this node holds that key,
+// the hash of the content that was signed and the state of the revision
+// chain. An invalid signature is reported, not returned as an error.
+func (s *ArticleService) Verification(ctx context.Context, cid string) (*domain.ArticleVerification, error) {
+	article, err := s.GetByCID(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := article.GetSignableContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signed content: %w", err)
+	}
+	sum := sha256.Sum256(content)
+
+	result := &domain.ArticleVerification{
+		CID:         cid,
+		ArticleID:   article.ID,
+		ContentHash: hex.EncodeToString(sum[:]),
+		Signer:      s.identifySigner(ctx, article),
+		Chain:       s.chainStatus(ctx, article, content),
+	}
+	if err := s.signer.VerifyArticle(article); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Valid = true
+	}
+	return result, nil
+}
+
+// identifySigner derives the DID and peer ID of the article's key. A
+// user's ID is the peer ID of their signing key, so the peer ID is also
+// how the key's owner is found.
+func (s *ArticleService) identifySigner(ctx context.Context, article *domain.Article) domain.Signer {
+	signer := domain.Signer{PublicKey: article.AuthorPubKey}
+
+	publicKey, err := crypto.PublicKeyFromString(article.AuthorPubKey)
+	if err != nil {
+		return signer
+	}
+	signer.Fingerprint = crypto.Fingerprint(publicKey)
+	signer.DID = crypto.DIDKey(publicKey)
+
+	libp2pKey, err := libp2pcrypto.UnmarshalEd25519PublicKey(publicKey)
+	if err != nil {
+		return signer
+	}
+	peerID, err := peer.IDFromPublicKey(libp2pKey)
+	if err != nil {
+		return signer
+	}
+	signer.PeerID = peerID.String()
+
+	user, err := s.userRepo.GetByID(ctx, signer.PeerID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			s.logger.Ctx(ctx).Warn("Failed to look up signer", "peer_id", signer.PeerID, "error", err)
+		}
+		return signer
+	}
+	if user.PublicKey == article.AuthorPubKey {
+		signer.KnownUser = true
+		signer.Username = user.Username
+		signer.AuthorMatches = user.Username == article.Author
+	}
+	return signer
+}
+
+// chainStatus walks the revision graph back from the article's latest
+// node. Besides each node's own signature, the newest node must carry the
+// same signed content as the article stored here, and versions must count
+// down from there.
+func (s *ArticleService) chainStatus(ctx context.Context, article *domain.Article, content []byte) domain.ChainStatus {
+	status := domain.ChainStatus{Status: domain.ChainUnavailable, Head: article.NodeCID}
+	if s.dag == nil || article.NodeCID == "" {
+		return status
+	}
+
+	revisions, walkErr := s.walkRevisions(ctx, article)
+	if walkErr != nil {
+		status.Error = walkErr.Error()
+	}
+	if len(revisions) == 0 {
+		return status
+	}
+
+	keyChanged := false
+	for i, rev := range revisions {
+		status.Revisions++
+		var follows bool
+		if i == 0 {
+			headContent, err := rev.Node.Article().GetSignableContent()
+			follows = err == nil && bytes.Equal(headContent, content) && rev.Node.Version == article.Version
+		} else {
+			follows = rev.Node.Version < revisions[i-1].Node.Version
+		}
+		if !rev.Verified || !follows {
+			status.Status = domain.ChainBroken
+			status.BrokenAt = rev.CID
+			return status
+		}
+		status.Verified++
+		if rev.Node.AuthorPubKey != article.AuthorPubKey {
+			keyChanged = true
+		}
+	}
+
+	switch {
+	case walkErr != nil || revisions[len(revisions)-1].Node.Previous != nil:
+		status.Status = domain.ChainTruncated
+	case keyChanged:
+		status.Status = domain.ChainKeyChanged
+	default:
+		status.Status = domain.ChainVerified
+	}
+	return status
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// ErrSelfVote is returned when a user votes on their own article
+var ErrSelfVote = errors.New("authors can't vote on their own articles")
+
+// VoteBroadcaster shares signed votes with peers
+type VoteBroadcaster interface {
+	BroadcastVote(vote *domain.Vote) error
+}
+
+// ReputationRecorder applies votes to an author's reputation and scores
+// voters so their votes can be weighted. previous is the voter's earlier
+// vote on the article, or 0.
+type ReputationRecorder interface {
+	RecordVote(did string, previous, vote int)
+	GetScore(did string) *domain.ReputationScore
+}
+
+// VoteIndexer re-ranks an article in search after its tally changes
+type VoteIndexer interface {
+	UpdateArticle(ctx context.Context, article *domain.Article) error
+}
+
+// NewVoterWeight is how much a vote counts from a DID with no reputation
+// history, or one this node can't put a name to
+const NewVoterWeight = 0.25
+
+// VoteWeight is how much a vote counts given the voter's reputation: 1 at
+// the initial score of 50, 2 at 100 and nothing at 0. DIDs with no articles,
+// votes or endorsements of their own, here or at a trusted peer, count at
+// most NewVoterWeight, so fresh identities can't swing a tally.
+func VoteWeight(score *domain.ReputationScore) float64 {
+	weight := max(0, min(2, score.Score/50))
+	if score.ArticleCount == 0 && score.UpVotes == 0 && score.DownVotes == 0 && score.ReportCount == 0 && score.Peers == 0 && score.Endorsements == 0 {
+		weight = min(weight, NewVoterWeight)
+	}
+	return weight
+}
+
+// VoteService records votes cast here or received on the votes topic and
+// keeps per-article tallies. A voter's latest vote replaces their earlier
+// one.
+type VoteService struct {
+	articleRepo repository.ArticleRepository
+	userRepo    repository.UserRepository
+	signer      *auth.ArticleSigner
+	counter     *VoteCounter
+	broadcaster VoteBroadcaster    // optional; shares local votes with peers
+	reputation  ReputationRecorder // optional; credits votes to authors and weights voters
+	indexer     VoteIndexer        // optional; re-ranks voted articles in search
+	events      events.Publisher   // optional; pushes tallies to real-time clients
+	mu          sync.Mutex         // serialises read-modify-write of a voter's vote, guards names
+	logger      *logger.Logger
+
+	// names maps voter DIDs (public keys) to the usernames reputation is
+	// kept under, learned from local voters and the authors of voted
+	// articles
+	names map[string]string
+}
+
+// NewVoteService creates a new vote service
+func NewVoteService(
+	articleRepo repository.ArticleRepository,
+	userRepo repository.UserRepository,
+	signer *auth.ArticleSigner,
+	logger *logger.Logger,
+) *VoteService {
+	return &VoteService{
+		articleRepo: articleRepo,
+		userRepo:    userRepo,
+		signer:      signer,
+		counter:     NewVoteCounter(),
+		logger:      logger.WithComponent("vote-service"),
+		names:       make(map[string]string),
+	}
+}
+
+// SetBroadcaster publishes votes cast on this node to the votes topic
+func (s *VoteService) SetBroadcaster(broadcaster VoteBroadcaster) {
+	s.broadcaster = broadcaster
+}
+
+// SetReputation credits each vote to the article author's reputation and
+// weights weighted tallies by each voter's
+func (s *VoteService) SetReputation(reputation ReputationRecorder) {
+	s.reputation = reputation
+}
+
+// SetIndexer reindexes an article after every vote on it, so most-voted
+// search follows the weighted tally
+func (s *VoteService) SetIndexer(indexer VoteIndexer) {
+	s.indexer = indexer
+}
+
+// SetEventPublisher publishes the updated tally after every vote
+func (s *VoteService) SetEventPublisher(publisher events.Publisher) {
+	s.events = publisher
+}
+
+// Vote signs and records a user's vote on the article with the given ID
+// or CID, then broadcasts it
+func (s *VoteService) Vote(ctx context.Context, ref, userID string, req *domain.VoteRequest) (*domain.Vote, domain.VoteTally, error) {
+	article, err := findArticle(ctx, s.articleRepo, ref)
+	if err != nil {
+		return nil, domain.VoteTally{}, err
+	}
+
+	user, privateKey, err := loadSigningKey(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, domain.VoteTally{}, err
+	}
+	if article.AuthorPubKey == user.PublicKey {
+		return nil, domain.VoteTally{}, ErrSelfVote
+	}
+
+	vote := &domain.Vote{
+		ArticleID: article.ID,
+		VoterDID:  user.PublicKey,
+		Value:     req.Vote,
+		Reason:    req.Reason,
+		Timestamp: time.Now().Unix(),
+	}
+	if err := vote.Validate(); err != nil {
+		return nil, domain.VoteTally{}, err
+	}
+	if err := s.signer.SignVote(vote, privateKey); err != nil {
+		return nil, domain.VoteTally{}, err
+	}
+
+	s.mu.Lock()
+	s.names[user.PublicKey] = user.Username
+	s.mu.Unlock()
+
+	tally := s.apply(ctx, vote, article)
+
+	if s.broadcaster != nil {
+		go func() {
+			if err := s.broadcaster.BroadcastVote(vote); err != nil {
+				s.logger.Ctx(ctx).Warn("Failed to broadcast vote", "article_id", vote.ArticleID, "error", err)
+			}
+		}()
+	}
+
+	s.logger.Ctx(ctx).Info("Vote recorded", "article_id", article.ID, "user_id", userID, "vote", vote.Value)
+	return vote, tally, nil
+}
+
+// HandleIncomingVote verifies and records a vote received from a peer.
+// Votes on articles this node doesn't hold still count toward the tally
+// but can't be credited to an author.
+func (s *VoteService) HandleIncomingVote(ctx context.Context, vote *domain.Vote) error {
+	if err := vote.Validate(); err != nil {
+		return err
+	}
+	if err := s.signer.VerifyVote(vote); err != nil {
+		s.logger.Ctx(ctx).Warn("Invalid signature on incoming vote", "article_id", vote.ArticleID, "error", err)
+		return err
+	}
+
+	article, err := s.articleRepo.GetByID(ctx, vote.ArticleID)
+	if err != nil && !errors.Is(err, domain.ErrArticleNotFound) {
+		return err
+	}
+	if article != nil && article.AuthorPubKey == vote.VoterDID {
+		return ErrSelfVote
+	}
+
+	s.apply(ctx, vote, article)
+	return nil
+}
+
+// Tally returns the current tally for the article with the given ID or CID
+func (s *VoteService) Tally(ctx context.Context, ref string) (domain.VoteTally, error) {
+	article, err := findArticle(ctx, s.articleRepo, ref)
+	if err != nil {
+		return domain.VoteTally{}, err
+	}
+	return s.weigh(s.counter.Tally(article.ID)), nil
+}
+
+// WeightedScore returns an article's vote score with each vote weighted by
+// its voter's reputation. Without a reputation system every vote counts
+// once.
+func (s *VoteService) WeightedScore(articleID string) float64 {
+	var score float64
+	for voter, value := range s.counter.Voters(articleID) {
+		score += float64(value) * s.voterWeight(voter)
+	}
+	return math.Round(score*100) / 100
+}
+
+// voterWeight is how much a vote from voter counts
+func (s *VoteService) voterWeight(voter string) float64 {
+	if s.reputation == nil {
+		return 1
+	}
+	s.mu.Lock()
+	name, ok := s.names[voter]
+	s.mu.Unlock()
+	if !ok {
+		return NewVoterWeight
+	}
+	return VoteWeight(s.reputation.GetScore(name))
+}
+
+// weigh fills in the weighted score of tally
+func (s *VoteService) weigh(tally domain.VoteTally) domain.VoteTally {
+	tally.Weighted = s.WeightedScore(tally.ArticleID)
+	return tally
+}
+
+// apply records a verified vote, credits the author of article (when
+// known), re-ranks it and announces the new tally
+func (s *VoteService) apply(ctx context.Context, vote *domain.Vote, article *domain.Article) domain.VoteTally {
+	s.mu.Lock()
+	previous := s.counter.Vote(vote.ArticleID, vote.VoterDID)
+	s.counter.Apply(vote.ArticleID, vote.VoterDID, vote.Value)
+	if article != nil && article.AuthorPubKey != "" {
+		s.names[article.AuthorPubKey] = article.Author
+	}
+	s.mu.Unlock()
+
+	if s.reputation != nil && article != nil {
+		s.reputation.RecordVote(article.Author, previous, vote.Value)
+	}
+	tally := s.weigh(s.counter.Tally(vote.ArticleID))
+	if s.indexer != nil && article != nil {
+		if err := s.indexer.UpdateArticle(ctx, article); err != nil {
+			s.logger.Ctx(ctx).Warn("Failed to reindex voted article", "article_id", article.ID, "error", err)
+		}
+	}
+	if s.events != nil {
+		s.events.Publish(domain.EventVoteTally, tally)
+	}
+	return tally
+}
+
+// VoteCounter keeps a running tally of votes seen on the votes topic. A
+// voter's latest vote on an article replaces their earlier one.
+type VoteCounter struct {
+	mu    sync.Mutex
+	votes map[string]map[string]int // article ID -> voter DID -> +1/-1
+}
+
+// NewVoteCounter creates an empty vote counter
+func NewVoteCounter() *VoteCounter {
+	return &VoteCounter{votes: make(map[string]map[string]int)}
+}
+
+// Apply records a vote and returns the article's updated tally
+func (v *VoteCounter) Apply(articleID, voter string, vote int) domain.VoteTally {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	voters, ok := v.votes[articleID]
+	if !ok {
+		voters = make(map[string]int)
+		v.votes[articleID] = voters
+	}
+	switch {
+	case vote > 0:
+		voters[voter] = 1
+	case vote < 0:
+		voters[voter] = -1
+	default:
+		delete(voters, voter)
+	}
+
+	return v.tally(articleID)
+}
+
+// Vote returns a voter's current vote on an article, or 0 if they have
+// not voted
+func (v *VoteCounter) Vote(articleID, voter string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.votes[articleID][voter]
+}
+
+// Voters returns a copy of each voter's current vote on an article
+func (v *VoteCounter) Voters(articleID string) map[string]int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	voters := make(map[string]int, len(v.votes[articleID]))
+	for voter, value := range v.votes[articleID] {
+		voters[voter] = value
+	}
+	return voters
+}
+
+// Tally returns an article's current tally
+func (v *VoteCounter) Tally(articleID string) domain.VoteTally {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.tally(articleID)
+}
+
+func (v *VoteCounter) tally(articleID string) domain.VoteTally {
+	tally := domain.VoteTally{ArticleID: articleID}
+	for _, value := range v.votes[articleID] {
+		if value > 0 {
+			tally.Up++
+		} else {
+			tally.Down++
+		}
+	}
+	tally.Score = tally.Up - tally.Down
+	return tally
+}
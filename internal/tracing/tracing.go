@@ -0,0 +1,88 @@
+// Package tracing records OpenTelemetry spans for requests as they pass
+// through the node: HTTP handlers, services, repositories, IPFS calls and
+// sync streams. Until Setup installs an exporter, spans are no-ops.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+)
+
+// instrumentation names the tracer every span in the node comes from
+const instrumentation = "github.com/amiyamandal-dev/newsp2p"
+
+// Setup exports spans to the OTLP/HTTP collector in cfg and accepts W3C
+// trace context from callers. The returned function flushes buffered
+// spans and must be called on shutdown.
+func Setup(ctx context.Context, cfg config.TracingConfig, version string) (func(context.Context) error, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe service: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// Follow the caller's decision, so a trace is never half recorded
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Start opens a span named name as a child of any span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartKind is Start for spans that are not internal: servers handling a
+// request and clients making one
+func StartKind(ctx context.Context, kind trace.SpanKind, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// End closes span, marking it failed when err is set
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject writes ctx's trace context into carrier, for messages that leave
+// the process outside HTTP, like sync requests
+func Inject(ctx context.Context, carrier map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(carrier))
+}
+
+// Extract returns ctx joined to the trace described by carrier
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
@@ -0,0 +1,156 @@
+package web
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/export"
+)
+
+const (
+	// authorPageSize is the number of articles per author page
+	authorPageSize = 20
+	// authorFeedSize is the number of most recent articles in an author feed
+	authorFeedSize = 50
+)
+
+// AuthorPage renders every article published by one author
+func (h *WebHandler) AuthorPage(c *gin.Context) {
+	ctx := c.Request.Context()
+	author := c.Param("name")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	articles, total, err := h.articleService.List(ctx, &domain.ArticleListFilter{
+		Author: author,
+		Page:   page,
+		Limit:  authorPageSize,
+	})
+	if err != nil {
+		h.logger.Ctx(ctx).Error("Failed to get author articles", "author", author, "error", err)
+		articles = []*domain.Article{}
+	}
+
+	var prevPage, nextPage int
+	if page > 1 {
+		prevPage = page - 1
+	}
+	if page*authorPageSize < total {
+		nextPage = page + 1
+	}
+
+	data := gin.H{
+		"Title":     author,
+		"User":      GetUser(c),
+		"Author":    author,
+		"Articles":  articles,
+		"Total":     total,
+		"PrevPage":  prevPage,
+		"NextPage":  nextPage,
+		"FeedURL":   "/author/" + url.PathEscape(author) + "/rss",
+		"PeerCount": h.getPeerCount(),
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := h.template(c, "author").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(ctx).Error("Template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+	}
+}
+
+// rssFeed is an RSS 2.0 document
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Author      string   `xml:"author,omitempty"`
+	Category    []string `xml:"category,omitempty"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// AuthorFeed serves an author's most recent articles as RSS 2.0
+func (h *WebHandler) AuthorFeed(c *gin.Context) {
+	ctx := c.Request.Context()
+	author := c.Param("name")
+
+	articles, _, err := h.articleService.List(ctx, &domain.ArticleListFilter{
+		Author: author,
+		Page:   1,
+		Limit:  authorFeedSize,
+	})
+	if err != nil {
+		h.logger.Ctx(ctx).Error("Failed to get author articles", "author", author, "error", err)
+		c.String(http.StatusInternalServerError, "Failed to build feed")
+		return
+	}
+
+	// Feed readers need absolute links
+	base := baseURL(c)
+	channel := rssChannel{
+		Title:       author + " - Liberation News",
+		Link:        base + "/author/" + url.PathEscape(author),
+		Description: "Articles published by " + author,
+	}
+	for _, article := range articles {
+		link := base + "/article/" + url.PathEscape(article.CID)
+		channel.Items = append(channel.Items, rssItem{
+			Title:       article.Title,
+			Link:        link,
+			GUID:        rssGUID{Value: article.CID},
+			Author:      article.Author,
+			Category:    article.Tags,
+			PubDate:     article.CreatedAt.UTC().Format(time.RFC1123Z),
+			Description: string(export.RenderHTML(article.Body)),
+		})
+	}
+	if len(articles) > 0 {
+		channel.LastBuildDate = articles[0].CreatedAt.UTC().Format(time.RFC1123Z)
+	}
+
+	out, err := xml.MarshalIndent(rssFeed{Version: "2.0", Channel: channel}, "", "  ")
+	if err != nil {
+		h.logger.Ctx(ctx).Error("Failed to encode feed", "error", err)
+		c.String(http.StatusInternalServerError, "Failed to build feed")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", append([]byte(xml.Header), out...))
+}
+
+// baseURL returns the scheme and host the request was made to
+func baseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
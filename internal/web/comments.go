@@ -0,0 +1,171 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+)
+
+// commentView is a comment with its replies and what the viewer may do
+// with it
+type commentView struct {
+	*domain.Comment
+	ArticleCID string
+	Replies    []*commentView
+	CanReply   bool
+	CanDelete  bool
+}
+
+// SetComments enables the comment thread on article pages
+func (h *WebHandler) SetComments(commentService *service.CommentService) {
+	h.commentService = commentService
+}
+
+// commentThread builds the data for the "comments" partial
+func (h *WebHandler) commentThread(c *gin.Context, cid string) gin.H {
+	user := GetUser(c)
+	data := gin.H{
+		"ArticleCID": cid,
+		"User":       user,
+	}
+
+	comments, total, err := h.commentService.List(c.Request.Context(), cid, 0, 0)
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to list comments", "cid", cid, "error", err)
+		data["Error"] = h.t(c, "comments.error_load")
+		return data
+	}
+
+	// Comments are listed oldest first, so parents come before replies
+	views := make(map[string]*commentView, len(comments))
+	var roots []*commentView
+	for _, comment := range comments {
+		view := &commentView{
+			Comment:    comment,
+			ArticleCID: cid,
+			CanReply:   user != nil,
+			CanDelete:  user != nil && (comment.Author == user.Username || h.isModerator(user)),
+		}
+		views[comment.ID] = view
+		if parent, ok := views[comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, view)
+		} else {
+			roots = append(roots, view)
+		}
+	}
+
+	data["Comments"] = roots
+	data["Count"] = total
+	return data
+}
+
+// renderComments writes the comment thread partial for HTMX swaps
+func (h *WebHandler) renderComments(c *gin.Context, data gin.H) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := h.template(c, "article").ExecuteTemplate(c.Writer, "comments", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+	}
+}
+
+// WebComments renders an article's comment thread
+func (h *WebHandler) WebComments(c *gin.Context) {
+	if h.commentService == nil {
+		c.String(http.StatusNotFound, "Comments are not enabled")
+		return
+	}
+	h.renderComments(c, h.commentThread(c, c.Param("cid")))
+}
+
+// WebCreateComment posts a comment or reply and returns the updated thread
+func (h *WebHandler) WebCreateComment(c *gin.Context) {
+	if h.commentService == nil {
+		c.String(http.StatusNotFound, "Comments are not enabled")
+		return
+	}
+
+	cid := c.Param("cid")
+	user := GetUser(c)
+	if user == nil {
+		if isHTMX(c) {
+			c.Header("HX-Redirect", "/login")
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Redirect(http.StatusSeeOther, "/login")
+		return
+	}
+
+	req := &domain.CommentCreateRequest{
+		Body:     c.PostForm("body"),
+		ParentID: c.PostForm("parent_id"),
+	}
+	_, err := h.commentService.Create(c.Request.Context(), cid, req, user.ID)
+
+	if !isHTMX(c) {
+		// Plain form posts go back to the article
+		c.Redirect(http.StatusSeeOther, "/article/"+url.PathEscape(cid)+"#comments")
+		return
+	}
+
+	data := h.commentThread(c, cid)
+	if err != nil {
+		var validationErr *domain.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			data["Error"] = validationErr.Message
+		case errors.Is(err, domain.ErrUserNotActive):
+			data["Error"] = h.t(c, "comments.error_inactive")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to create comment", "cid", cid, "error", err)
+			data["Error"] = h.t(c, "comments.error_post")
+		}
+		// Keep what the user wrote so it isn't lost
+		if req.ParentID == "" {
+			data["Body"] = req.Body
+		}
+	}
+	h.renderComments(c, data)
+}
+
+// WebDeleteComment removes a comment and its replies and returns the
+// updated thread. Authors may delete their own comments; moderators any.
+func (h *WebHandler) WebDeleteComment(c *gin.Context) {
+	if h.commentService == nil {
+		c.String(http.StatusNotFound, "Comments are not enabled")
+		return
+	}
+
+	user := GetUser(c)
+	if user == nil {
+		c.Header("HX-Redirect", "/login")
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	cid := c.Param("cid")
+	err := h.commentService.Delete(c.Request.Context(), c.Param("id"), user.ID, h.isModerator(user))
+
+	data := h.commentThread(c, cid)
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrForbidden):
+		data["Error"] = h.t(c, "comments.error_not_yours")
+	case errors.Is(err, domain.ErrCommentNotFound):
+		data["Error"] = h.t(c, "comments.error_not_found")
+	default:
+		h.logger.Ctx(c.Request.Context()).Error("Failed to delete comment", "comment_id", c.Param("id"), "error", err)
+		data["Error"] = h.t(c, "comments.error_delete")
+	}
+	h.renderComments(c, data)
+}
+
+// isHTMX reports whether the request was made by HTMX
+func isHTMX(c *gin.Context) bool {
+	return c.GetHeader("HX-Request") == "true"
+}
@@ -0,0 +1,129 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+)
+
+// dashboardPageSize is the number of articles per dashboard page
+const dashboardPageSize = 20
+
+// Article sync states shown on the dashboard
+const (
+	syncLocal     = "local"     // IPFS was unavailable; only this node has it
+	syncPublished = "published" // on IPFS and announced to peers
+)
+
+// dashboardRow is one of the user's articles with its tally and storage state
+type dashboardRow struct {
+	*domain.Article
+	Votes     domain.VoteTally
+	Sync      string
+	PinStatus string // ledger state, or empty when untracked
+	PinError  string
+}
+
+// SetDashboard enables vote tallies and pin status on the dashboard. Either
+// may be nil.
+func (h *WebHandler) SetDashboard(voteService *service.VoteService, pinLedger *service.PinLedgerService) {
+	h.voteService = voteService
+	h.pinLedger = pinLedger
+}
+
+// DashboardPage lists the logged-in user's articles with quick actions.
+// Drafts live in the browser and are listed client-side.
+func (h *WebHandler) DashboardPage(c *gin.Context) {
+	user := GetUser(c)
+	if user == nil {
+		c.Redirect(http.StatusSeeOther, "/login")
+		return
+	}
+	ctx := c.Request.Context()
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	articles, total, err := h.articleService.List(ctx, &domain.ArticleListFilter{
+		Author: user.Username,
+		Page:   page,
+		Limit:  dashboardPageSize,
+	})
+	if err != nil {
+		h.logger.Ctx(ctx).Error("Failed to get user articles", "user_id", user.ID, "error", err)
+		articles = []*domain.Article{}
+	}
+
+	rows := make([]*dashboardRow, 0, len(articles))
+	for _, article := range articles {
+		row := &dashboardRow{Article: article, Sync: syncPublished}
+		if domain.IsLocalCID(article.CID) {
+			row.Sync = syncLocal
+		}
+		if h.voteService != nil {
+			if tally, err := h.voteService.Tally(ctx, article.ID); err == nil {
+				row.Votes = tally
+			}
+		}
+		if h.pinLedger != nil && row.Sync == syncPublished {
+			pin, err := h.pinLedger.Get(ctx, article.CID)
+			switch {
+			case err == nil:
+				row.PinStatus, row.PinError = pin.Status, pin.LastError
+			case !errors.Is(err, domain.ErrPinNotFound):
+				h.logger.Ctx(ctx).Warn("Failed to get pin status", "cid", article.CID, "error", err)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	var prevPage, nextPage int
+	if page > 1 {
+		prevPage = page - 1
+	}
+	if page*dashboardPageSize < total {
+		nextPage = page + 1
+	}
+
+	data := gin.H{
+		"Title":        h.t(c, "page.dashboard"),
+		"User":         user,
+		"Rows":         rows,
+		"Total":        total,
+		"PrevPage":     prevPage,
+		"NextPage":     nextPage,
+		"Deleted":      c.Query("deleted") != "",
+		"DeleteFailed": c.Query("error") == "delete",
+		"PeerCount":    h.getPeerCount(),
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := h.template(c, "dashboard").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(ctx).Error("Template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+	}
+}
+
+// WebDeleteArticle deletes one of the user's articles and returns to the
+// dashboard
+func (h *WebHandler) WebDeleteArticle(c *gin.Context) {
+	article, user := h.editableArticle(c)
+	if article == nil {
+		return
+	}
+
+	if err := h.articleService.Delete(c.Request.Context(), article.ID, user.ID); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Failed to delete article", "cid", article.CID, "error", err)
+		c.Redirect(http.StatusSeeOther, "/dashboard?error=delete")
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/dashboard?deleted=1")
+}
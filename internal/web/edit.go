@@ -0,0 +1,122 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// editableArticle loads the article being edited and checks that the
+// logged-in user wrote it. It writes the response and returns nil when
+// the user may not edit it.
+func (h *WebHandler) editableArticle(c *gin.Context) (*domain.Article, *domain.UserResponse) {
+	user := GetUser(c)
+	if user == nil {
+		c.Redirect(http.StatusSeeOther, "/login")
+		return nil, nil
+	}
+
+	article, err := h.articleService.GetByCID(c.Request.Context(), c.Param("cid"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Article not found")
+		return nil, nil
+	}
+	if article.Author != user.Username {
+		c.String(http.StatusForbidden, "You can only edit your own articles")
+		return nil, nil
+	}
+
+	return article, user
+}
+
+// renderEditForm renders the article form in edit mode
+func (h *WebHandler) renderEditForm(c *gin.Context, status int, data gin.H) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := h.template(c, "create").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+	}
+}
+
+// EditArticlePage renders the edit form pre-filled with the article's
+// current content
+func (h *WebHandler) EditArticlePage(c *gin.Context) {
+	article, user := h.editableArticle(c)
+	if article == nil {
+		return
+	}
+
+	h.renderEditForm(c, http.StatusOK, gin.H{
+		"Title":     h.t(c, "page.edit"),
+		"User":      user,
+		"PeerCount": h.getPeerCount(),
+		"EditCID":   article.CID,
+		"Form": gin.H{
+			"Title":    article.Title,
+			"Body":     article.Body,
+			"Category": article.Category,
+			"Tags":     strings.Join(article.Tags, ", "),
+		},
+	})
+}
+
+// WebEditArticle handles edit form submission. The update is re-signed,
+// published as a new revision and broadcast to peers.
+func (h *WebHandler) WebEditArticle(c *gin.Context) {
+	article, user := h.editableArticle(c)
+	if article == nil {
+		return
+	}
+
+	title := c.PostForm("title")
+	body := c.PostForm("body")
+	category := c.PostForm("category")
+	tags := c.PostForm("tags")
+
+	req := &domain.ArticleUpdateRequest{
+		Title:    title,
+		Body:     body,
+		Category: category,
+		Tags:     parseTags(tags),
+	}
+	if req.Tags == nil {
+		// An empty field clears the tags rather than keeping them
+		req.Tags = []string{}
+	}
+
+	updated, err := h.articleService.Update(c.Request.Context(), article.ID, req, user.ID)
+	if err != nil {
+		status, message := http.StatusInternalServerError, h.t(c, "create.error_update")
+		var validationErr *domain.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			status, message = http.StatusBadRequest, validationErr.Message
+		case errors.Is(err, domain.ErrForbidden):
+			status, message = http.StatusForbidden, h.t(c, "create.error_not_yours")
+		default:
+			h.logger.Ctx(c.Request.Context()).Error("Failed to update article", "cid", article.CID, "error", err)
+		}
+
+		h.renderEditForm(c, status, gin.H{
+			"Title":     h.t(c, "page.edit"),
+			"User":      user,
+			"PeerCount": h.getPeerCount(),
+			"EditCID":   article.CID,
+			"Error":     message,
+			"Form": gin.H{
+				"Title":    title,
+				"Body":     body,
+				"Category": category,
+				"Tags":     tags,
+			},
+		})
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/article/"+updated.CID)
+}
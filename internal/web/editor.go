@@ -0,0 +1,31 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/export"
+)
+
+// previewMaxBytes caps the markdown the editor preview will render
+const previewMaxBytes = 1 << 20
+
+// Preview renders the editor's markdown for the preview pane. It goes
+// through the same goldmark and bluemonday pipeline as published articles,
+// so the preview shows exactly what readers will see.
+func (h *WebHandler) Preview(c *gin.Context) {
+	if GetUser(c) == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, previewMaxBytes)
+	if err := c.Request.ParseForm(); err != nil {
+		c.String(http.StatusRequestEntityTooLarge, "Article too large to preview")
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.String(http.StatusOK, string(export.RenderHTML(c.Request.PostForm.Get("body"))))
+}
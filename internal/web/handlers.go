@@ -1,17 +1,20 @@
 package web
 
 import (
-	"bytes"
 	"html/template"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/microcosm-cc/bluemonday"
-	"github.com/yuin/goldmark"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/auth"
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/export"
+	"github.com/amiyamandal-dev/newsp2p/internal/i18n"
 	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
 	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
 	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
@@ -29,8 +32,16 @@ type WebHandler struct {
 	db             *badger.DB
 	p2pNode        *p2p.P2PNode
 	ipfsClient     *ipfs.Client
+	commentService *service.CommentService
+	moderators     map[string]bool
+	voteService    *service.VoteService
+	pinLedger      *service.PinLedgerService
+	moderation     *service.ModerationService
+	trust          service.TrustScorer // optional; author trust on the moderation page
+	syncService    *p2p.SyncService    // optional; sync progress on the network page
 	logger         *logger.Logger
-	templates      map[string]*template.Template
+	i18n           *i18n.Bundle
+	templates      map[string]map[string]*template.Template // language, then page
 }
 
 // NewWebHandler creates a new web handler
@@ -56,14 +67,8 @@ func NewWebHandler(
 			return s[:length] + "..."
 		},
 		"upper": strings.ToUpper,
-		"markdown": func(s string) template.HTML {
-			var buf bytes.Buffer
-			if err := goldmark.Convert([]byte(s), &buf); err != nil {
-				return template.HTML(template.HTMLEscapeString(s))
-			}
-			// Sanitize the HTML output to prevent XSS
-			return template.HTML(sanitizer.Sanitize(buf.String()))
-		},
+		// Article exports render through the same sanitized pipeline
+		"markdown": export.RenderHTML,
 		"safeHTML": func(s string) template.HTML {
 			// Sanitize any raw HTML content
 			return template.HTML(sanitizer.Sanitize(s))
@@ -74,27 +79,97 @@ func NewWebHandler(
 			}
 			return strings.ToUpper(string([]rune(s)[0]))
 		},
-		"urlquery": template.URLQueryEscaper,
+		"urlquery":   template.URLQueryEscaper,
+		"pathEscape": url.PathEscape,
+		"bytes":      formatBytes,
+		"since": func(t time.Time) string {
+			return time.Since(t).Round(time.Second).String()
+		},
+	}
+
+	// Every language gets its own template set, with T bound to its catalog
+	bundle, err := i18n.Load(localesDir, defaultLanguage)
+	if err != nil {
+		panic(err)
+	}
+	templates := make(map[string]map[string]*template.Template)
+	for _, lang := range bundle.Languages() {
+		code := lang.Code
+		langFuncs := template.FuncMap{
+			"T": func(key string, args ...interface{}) string {
+				return bundle.Translate(code, key, args...)
+			},
+			"lang":      func() string { return code },
+			"languages": bundle.Languages,
+		}
+		for name, fn := range funcMap {
+			langFuncs[name] = fn
+		}
+		templates[code] = parseTemplates(langFuncs)
+	}
+
+	return &WebHandler{
+		articleService: articleService,
+		userService:    userService,
+		searchService:  searchService,
+		jwtManager:     jwtManager,
+		db:             db,
+		p2pNode:        p2pNode,
+		ipfsClient:     ipfsClient,
+		logger:         log.WithComponent("web-handler"),
+		i18n:           bundle,
+		templates:      templates,
 	}
+}
 
-	// Create template map - parse each page with base layout
+// parseTemplates parses each page with the base layout and the components
+// it renders
+func parseTemplates(funcMap template.FuncMap) map[string]*template.Template {
 	templates := make(map[string]*template.Template)
 
 	baseLayout := "web/templates/layouts/base.html"
 	articleListComponent := "web/templates/components/article_list.html"
+	suggestionsComponent := "web/templates/components/suggestions.html"
+	commentsComponent := "web/templates/components/comments.html"
+	networkLiveComponent := "web/templates/components/network_live.html"
 	pages := map[string]string{
-		"home":     "web/templates/pages/home.html",
-		"explore":  "web/templates/pages/explore.html",
-		"login":    "web/templates/pages/login.html",
-		"register": "web/templates/pages/register.html",
-		"create":   "web/templates/pages/create.html",
-		"article":  "web/templates/pages/article.html",
-		"network":  "web/templates/pages/network.html",
+		"home":       "web/templates/pages/home.html",
+		"explore":    "web/templates/pages/explore.html",
+		"login":      "web/templates/pages/login.html",
+		"register":   "web/templates/pages/register.html",
+		"create":     "web/templates/pages/create.html",
+		"article":    "web/templates/pages/article.html",
+		"network":    "web/templates/pages/network.html",
+		"author":     "web/templates/pages/author.html",
+		"dashboard":  "web/templates/pages/dashboard.html",
+		"moderation": "web/templates/pages/moderation.html",
+		"tag":        "web/templates/pages/tag.html",
+		"reader":     "web/templates/pages/reader.html",
 	}
 
 	for name, pagePath := range pages {
 		var tmpl *template.Template
-		if name == "explore" || name == "home" {
+		if name == "explore" {
+			// Explore also serves HTMX search results and suggestions
+			tmpl = template.Must(
+				template.New(name).Funcs(funcMap).ParseFiles(baseLayout, pagePath, articleListComponent, suggestionsComponent),
+			)
+		} else if name == "article" {
+			// Article also serves the HTMX comment thread
+			tmpl = template.Must(
+				template.New(name).Funcs(funcMap).ParseFiles(baseLayout, pagePath, commentsComponent),
+			)
+		} else if name == "network" {
+			// Network also serves the live stats HTMX polls for
+			tmpl = template.Must(
+				template.New(name).Funcs(funcMap).ParseFiles(baseLayout, pagePath, networkLiveComponent),
+			)
+		} else if name == "reader" {
+			// Reader view is a standalone page without the site layout
+			tmpl = template.Must(
+				template.New(name).Funcs(funcMap).ParseFiles(pagePath),
+			)
+		} else if name == "home" || name == "author" || name == "tag" {
 			// Include article list component for pages that need it
 			tmpl = template.Must(
 				template.New(name).Funcs(funcMap).ParseFiles(baseLayout, pagePath, articleListComponent),
@@ -106,18 +181,7 @@ func NewWebHandler(
 		}
 		templates[name] = tmpl
 	}
-
-	return &WebHandler{
-		articleService: articleService,
-		userService:    userService,
-		searchService:  searchService,
-		jwtManager:     jwtManager,
-		db:             db,
-		p2pNode:        p2pNode,
-		ipfsClient:     ipfsClient,
-		logger:         log.WithComponent("web-handler"),
-		templates:      templates,
-	}
+	return templates
 }
 
 // HomePage renders the home page
@@ -131,7 +195,7 @@ func (h *WebHandler) HomePage(c *gin.Context) {
 		Limit: 10,
 	})
 	if err != nil {
-		h.logger.Error("Failed to get articles", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get articles", "error", err)
 		articles = []*domain.Article{}
 	}
 
@@ -146,7 +210,7 @@ func (h *WebHandler) HomePage(c *gin.Context) {
 	}
 
 	data := gin.H{
-		"Title":    "Home",
+		"Title":    h.t(c, "page.home"),
 		"User":     user,
 		"Articles": articles,
 		"Stats": gin.H{
@@ -161,8 +225,8 @@ func (h *WebHandler) HomePage(c *gin.Context) {
 
 	// Render template
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := h.templates["home"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-		h.logger.Error("Template error", "error", err)
+	if err := h.template(c, "home").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 		c.String(http.StatusInternalServerError, "Template error")
 	}
 }
@@ -185,39 +249,66 @@ func (h *WebHandler) ArticlePage(c *gin.Context) {
 		"Article":   article,
 		"PeerCount": h.getPeerCount(),
 	}
+	if h.commentService != nil {
+		data["Thread"] = h.commentThread(c, article.CID)
+	}
 
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := h.templates["article"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-		h.logger.Error("Template error", "error", err)
+	if err := h.template(c, "article").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 		c.String(http.StatusInternalServerError, "Template error")
 	}
 }
 
-// ExplorePage renders the explore/search page
+// explorePageSize is the number of articles per explore or search page
+const explorePageSize = 20
+
+// ExplorePage renders the explore page. HTMX requests for later pages get
+// just the next batch of articles, which the article list appends as the
+// reader scrolls.
 func (h *WebHandler) ExplorePage(c *gin.Context) {
 	ctx := c.Request.Context()
 	user := GetUser(c)
 
-	// Get all articles for exploration
-	articles, _, err := h.articleService.List(ctx, &domain.ArticleListFilter{
-		Page:  1,
-		Limit: 20,
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	articles, total, err := h.articleService.List(ctx, &domain.ArticleListFilter{
+		Page:  page,
+		Limit: explorePageSize,
 	})
 	if err != nil {
-		h.logger.Error("Failed to get articles", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to get articles", "error", err)
 		articles = []*domain.Article{}
 	}
 
 	data := gin.H{
-		"Title":     "Explore",
+		"Title":     h.t(c, "page.explore"),
 		"User":      user,
 		"Articles":  articles,
 		"PeerCount": h.getPeerCount(),
 	}
+	if page*explorePageSize < total {
+		data["NextURL"] = "/explore?page=" + strconv.Itoa(page+1)
+	}
+	if page > 1 {
+		data["PrevPage"] = page - 1
+	}
 
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := h.templates["explore"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-		h.logger.Error("Template error", "error", err)
+	if isHTMX(c) {
+		data["Continued"] = page > 1
+		if err := h.template(c, "explore").ExecuteTemplate(c.Writer, "article_list.html", data); err != nil {
+			h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
+			c.String(http.StatusInternalServerError, "Template error")
+		}
+		return
+	}
+	data["TagCloud"] = h.tagCloud(c)
+	if err := h.template(c, "explore").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 		c.String(http.StatusInternalServerError, "Template error")
 	}
 }
@@ -242,13 +333,13 @@ func (h *WebHandler) LoginPage(c *gin.Context) {
 	}
 
 	data := gin.H{
-		"Title":     "Login",
+		"Title":     h.t(c, "page.login"),
 		"PeerCount": h.getPeerCount(),
 	}
 
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := h.templates["login"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-		h.logger.Error("Template error", "error", err)
+	if err := h.template(c, "login").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 		c.String(http.StatusInternalServerError, "Template error")
 	}
 }
@@ -261,13 +352,13 @@ func (h *WebHandler) RegisterPage(c *gin.Context) {
 	}
 
 	data := gin.H{
-		"Title":     "Register",
+		"Title":     h.t(c, "page.register"),
 		"PeerCount": h.getPeerCount(),
 	}
 
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := h.templates["register"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-		h.logger.Error("Template error", "error", err)
+	if err := h.template(c, "register").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 		c.String(http.StatusInternalServerError, "Template error")
 	}
 }
@@ -285,13 +376,13 @@ func (h *WebHandler) WebLogin(c *gin.Context) {
 	loginResp, err := h.userService.Login(c.Request.Context(), req)
 	if err != nil {
 		data := gin.H{
-			"Title":     "Login",
-			"Error":     "Invalid username or password",
+			"Title":     h.t(c, "page.login"),
+			"Error":     h.t(c, "login.error_invalid"),
 			"PeerCount": h.getPeerCount(),
 		}
 		c.Header("Content-Type", "text/html; charset=utf-8")
-		if err := h.templates["login"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-			h.logger.Error("Template error", "error", err)
+		if err := h.template(c, "login").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+			h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 			c.String(http.StatusInternalServerError, "Template error")
 		}
 		return
@@ -316,19 +407,19 @@ func (h *WebHandler) WebRegister(c *gin.Context) {
 
 	_, err := h.userService.Register(c.Request.Context(), req)
 	if err != nil {
-		errorMsg := "Registration failed"
+		errorMsg := h.t(c, "register.error_failed")
 		if err == domain.ErrUserAlreadyExists {
-			errorMsg = "Username or email already exists"
+			errorMsg = h.t(c, "register.error_exists")
 		}
 
 		data := gin.H{
-			"Title":     "Register",
+			"Title":     h.t(c, "page.register"),
 			"Error":     errorMsg,
 			"PeerCount": h.getPeerCount(),
 		}
 		c.Header("Content-Type", "text/html; charset=utf-8")
-		if err := h.templates["register"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-			h.logger.Error("Template error", "error", err)
+		if err := h.template(c, "register").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+			h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 			c.String(http.StatusInternalServerError, "Template error")
 		}
 		return
@@ -362,14 +453,14 @@ func (h *WebHandler) CreateArticlePage(c *gin.Context) {
 	}
 
 	data := gin.H{
-		"Title":     "Write Article",
+		"Title":     h.t(c, "page.create"),
 		"User":      user,
 		"PeerCount": h.getPeerCount(),
 	}
 
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := h.templates["create"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-		h.logger.Error("Template error", "error", err)
+	if err := h.template(c, "create").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 		c.String(http.StatusInternalServerError, "Template error")
 	}
 }
@@ -387,33 +478,21 @@ func (h *WebHandler) WebCreateArticle(c *gin.Context) {
 	category := c.PostForm("category")
 	tags := c.PostForm("tags")
 
-	tagList := strings.Split(tags, ",")
-	for i := range tagList {
-		tagList[i] = strings.TrimSpace(tagList[i])
-	}
-	// Filter empty tags
-	var cleanTags []string
-	for _, t := range tagList {
-		if t != "" {
-			cleanTags = append(cleanTags, t)
-		}
-	}
-
 	req := &domain.ArticleCreateRequest{
 		Title:    title,
 		Body:     body,
 		Category: category,
-		Tags:     cleanTags,
+		Tags:     parseTags(tags),
 	}
 
 	article, err := h.articleService.Create(c.Request.Context(), req, user.ID, h.getOriginIdentifier(c))
 	if err != nil {
-		h.logger.Error("Failed to create article", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Failed to create article", "error", err)
 		data := gin.H{
-			"Title":     "Write Article",
+			"Title":     h.t(c, "page.create"),
 			"User":      user,
 			"PeerCount": h.getPeerCount(),
-			"Error":     "Failed to create article. Please try again.",
+			"Error":     h.t(c, "create.error_failed"),
 			"Form": gin.H{
 				"Title":    title,
 				"Body":     body,
@@ -422,8 +501,8 @@ func (h *WebHandler) WebCreateArticle(c *gin.Context) {
 			},
 		}
 		c.Header("Content-Type", "text/html; charset=utf-8")
-		if err := h.templates["create"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-			h.logger.Error("Template error", "error", err)
+		if err := h.template(c, "create").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+			h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 			c.String(http.StatusInternalServerError, "Template error")
 		}
 		return
@@ -432,78 +511,82 @@ func (h *WebHandler) WebCreateArticle(c *gin.Context) {
 	c.Redirect(http.StatusSeeOther, "/article/"+article.CID)
 }
 
+// parseTags splits a comma-separated tag field, dropping empty tags
+func parseTags(tags string) []string {
+	var cleanTags []string
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			cleanTags = append(cleanTags, t)
+		}
+	}
+	return cleanTags
+}
+
 // WebSearch handles search requests from the web UI (HTMX)
 func (h *WebHandler) WebSearch(c *gin.Context) {
 	q := c.Query("q")
 	author := c.Query("author")
 	category := c.Query("category")
 	tags := c.QueryArray("tags")
-	
+
+	sortBy, ok := search.ParseSortOrder(c.Query("sort"))
+	if !ok {
+		sortBy = search.SortRelevance
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
 	query := &search.SearchQuery{
 		Query:    q,
 		Author:   author,
 		Category: category,
 		Tags:     tags,
-		Page:     1,
-		Limit:    20,
+		Page:     page,
+		Limit:    explorePageSize,
+		SortBy:   sortBy,
 	}
 
 	result, err := h.searchService.Search(c.Request.Context(), query)
 	if err != nil {
-		h.logger.Error("Search failed", "error", err)
+		h.logger.Ctx(c.Request.Context()).Error("Search failed", "error", err)
 		c.String(http.StatusInternalServerError, "Search failed")
 		return
 	}
 
 	data := gin.H{
-		"Articles": result.Articles,
+		"Articles":   result.Articles,
+		"Highlights": result.Highlights,
+		"Continued":  page > 1,
+	}
+	if page < result.TotalPages {
+		// The next batch keeps the query, filters and sort
+		next := c.Request.URL.Query()
+		next.Set("page", strconv.Itoa(page+1))
+		data["NextURL"] = "/search?" + next.Encode()
 	}
 
 	// Render only the article list component
-	if err := h.templates["explore"].ExecuteTemplate(c.Writer, "article_list.html", data); err != nil {
-		h.logger.Error("Template error", "error", err)
+	if err := h.template(c, "explore").ExecuteTemplate(c.Writer, "article_list.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 		c.String(http.StatusInternalServerError, "Template error")
 	}
 }
 
-// NetworkPage renders the P2P network status page
-func (h *WebHandler) NetworkPage(c *gin.Context) {
-	user := GetUser(c)
-	var peers []gin.H
-	var peerID string
-	var addresses []string
-
-	if h.p2pNode != nil {
-		peerID = h.p2pNode.GetPeerID().String()
-		connectedPeers := h.p2pNode.GetConnectedPeers()
-
-		for _, p := range connectedPeers {
-			peers = append(peers, gin.H{
-				"ID":     p.String(),
-				"Status": "connected",
-			})
-		}
-
-		// Get node addresses for sharing
-		host := h.p2pNode.GetHost()
-		for _, addr := range host.Addrs() {
-			fullAddr := addr.String() + "/p2p/" + peerID
-			addresses = append(addresses, fullAddr)
-		}
-	}
-
-	data := gin.H{
-		"Title":     "P2P Network",
-		"User":      user,
-		"PeerID":    peerID,
-		"Peers":     peers,
-		"PeerCount": len(peers),
-		"Addresses": addresses,
+// WebSuggest renders search suggestions as <option> elements for the explore datalist (HTMX)
+func (h *WebHandler) WebSuggest(c *gin.Context) {
+	suggestions, err := h.searchService.Suggest(c.Request.Context(), c.Query("q"), 8)
+	if err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Suggest failed", "error", err)
+		c.String(http.StatusInternalServerError, "Suggest failed")
+		return
 	}
 
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := h.templates["network"].ExecuteTemplate(c.Writer, "base.html", data); err != nil {
-		h.logger.Error("Template error", "error", err)
+	if err := h.template(c, "explore").ExecuteTemplate(c.Writer, "suggestions.html", gin.H{"Suggestions": suggestions}); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
 		c.String(http.StatusInternalServerError, "Template error")
 	}
 }
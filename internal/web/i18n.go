@@ -0,0 +1,85 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// localesDir holds a message catalog per language, like the templates
+	// it is read relative to the working directory
+	localesDir = "web/locales"
+	// defaultLanguage is served when nothing better matches, and supplies
+	// any message another catalog lacks
+	defaultLanguage = "en"
+
+	CookieLanguage     = "lang"
+	contextLanguageKey = "web_language"
+)
+
+// language picks the language a request is served in: the logged-in
+// user's preference, then the language cookie, then Accept-Language
+func (h *WebHandler) language(c *gin.Context) string {
+	if lang := c.GetString(contextLanguageKey); lang != "" {
+		return lang
+	}
+
+	lang := ""
+	if user := GetUser(c); user != nil && h.i18n.Has(user.Language) {
+		lang = user.Language
+	} else if cookie, err := c.Cookie(CookieLanguage); err == nil && h.i18n.Has(cookie) {
+		lang = cookie
+	} else {
+		lang = h.i18n.Match(c.GetHeader("Accept-Language"))
+	}
+
+	c.Set(contextLanguageKey, lang)
+	return lang
+}
+
+// template returns the named page's templates in the request's language
+func (h *WebHandler) template(c *gin.Context, name string) *template.Template {
+	return h.templates[h.language(c)][name]
+}
+
+// t translates a message into the request's language
+func (h *WebHandler) t(c *gin.Context, key string, args ...interface{}) string {
+	return h.i18n.Translate(h.language(c), key, args...)
+}
+
+// SetLanguage switches the UI language. The choice is kept in a cookie,
+// and saved as the user's preference when they are logged in, so it
+// follows them to other browsers.
+func (h *WebHandler) SetLanguage(c *gin.Context) {
+	lang := c.PostForm("lang")
+	if !h.i18n.Has(lang) {
+		c.String(http.StatusBadRequest, "Unknown language")
+		return
+	}
+
+	SetSecureCookie(c, CookieLanguage, lang, 365*24*3600)
+	if user := GetUser(c); user != nil {
+		if err := h.userService.SetLanguage(c.Request.Context(), user.ID, lang); err != nil {
+			h.logger.Ctx(c.Request.Context()).Warn("Failed to save language preference", "user_id", user.ID, "error", err)
+		}
+	}
+
+	c.Redirect(http.StatusSeeOther, sameSiteReturn(c.GetHeader("Referer")))
+}
+
+// sameSiteReturn reduces a Referer to its path and query, so a redirect
+// back to it can't leave the site
+func sameSiteReturn(referer string) string {
+	ref, err := url.Parse(referer)
+	if err != nil || !strings.HasPrefix(ref.Path, "/") || strings.HasPrefix(ref.Path, "//") {
+		return "/"
+	}
+	if ref.RawQuery != "" {
+		return ref.Path + "?" + ref.RawQuery
+	}
+	return ref.Path
+}
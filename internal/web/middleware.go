@@ -65,6 +65,7 @@ func AuthMiddleware(jwtManager *auth.JWTManager, userService *service.UserServic
 
 		// Set user in context
 		c.Set(ContextUserKey, user)
+		c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), user.ID, user.Username))
 		c.Next()
 	}
 }
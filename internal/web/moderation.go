@@ -0,0 +1,183 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+)
+
+// Moderation page actions
+const (
+	moderationApprove  = "approve"  // dismiss the reports; the article stays
+	moderationHide     = "hide"     // uphold the reports on this node
+	moderationEscalate = "escalate" // uphold the reports and flag the article to peers
+)
+
+// moderationCase is one reported article with every open report against it
+type moderationCase struct {
+	ArticleID   string
+	Article     *domain.Article // nil when the article is no longer stored
+	Reports     []*domain.Report
+	Local       int // reports filed by users of this node
+	Peer        int // reports and flags forwarded by other nodes
+	AuthorTrust float64
+	HasTrust    bool // false when P2P reputation is off
+	Votes       domain.VoteTally
+}
+
+// SetModerators lists the users, by ID or username, who may moderate
+// comments and work the moderation queue
+func (h *WebHandler) SetModerators(moderators []string) {
+	h.moderators = make(map[string]bool, len(moderators))
+	for _, m := range moderators {
+		h.moderators[m] = true
+	}
+}
+
+// isModerator reports whether the user is a moderator or admin
+func (h *WebHandler) isModerator(user *domain.UserResponse) bool {
+	return user != nil && (h.moderators[user.ID] || h.moderators[user.Username])
+}
+
+// SetModeration enables the moderation page
+func (h *WebHandler) SetModeration(moderationService *service.ModerationService) {
+	h.moderation = moderationService
+}
+
+// SetTrustScorer shows author trust scores on the moderation page
+func (h *WebHandler) SetTrustScorer(trust service.TrustScorer) {
+	h.trust = trust
+}
+
+// requireModerator writes the response and returns nil unless a moderator
+// is logged in
+func (h *WebHandler) requireModerator(c *gin.Context) *domain.UserResponse {
+	user := GetUser(c)
+	if user == nil {
+		c.Redirect(http.StatusSeeOther, "/login")
+		return nil
+	}
+	if h.moderation == nil {
+		c.String(http.StatusNotFound, "Moderation is not enabled")
+		return nil
+	}
+	if !h.isModerator(user) {
+		c.String(http.StatusForbidden, "Moderator access required")
+		return nil
+	}
+	return user
+}
+
+// ModerationPage lists reported articles, most recently reported first,
+// with the context a moderator needs to decide on them
+func (h *WebHandler) ModerationPage(c *gin.Context) {
+	user := h.requireModerator(c)
+	if user == nil {
+		return
+	}
+	ctx := c.Request.Context()
+
+	reports, _, err := h.moderation.List(ctx, &domain.ReportListFilter{Status: domain.ReportOpen})
+	if err != nil {
+		h.logger.Ctx(ctx).Error("Failed to list reports", "error", err)
+		c.String(http.StatusInternalServerError, "Failed to read moderation queue")
+		return
+	}
+
+	// Reports are newest first, so cases keep that order
+	var cases []*moderationCase
+	byArticle := make(map[string]*moderationCase)
+	for _, report := range reports {
+		mc, ok := byArticle[report.ArticleID]
+		if !ok {
+			mc = &moderationCase{ArticleID: report.ArticleID}
+			byArticle[report.ArticleID] = mc
+			cases = append(cases, mc)
+		}
+		mc.Reports = append(mc.Reports, report)
+		if report.Source == domain.ReportSourcePeer {
+			mc.Peer++
+		} else {
+			mc.Local++
+		}
+	}
+
+	for _, mc := range cases {
+		article, err := h.articleService.GetByCID(ctx, mc.Reports[0].ArticleCID)
+		if err != nil {
+			continue
+		}
+		mc.Article = article
+		if h.trust != nil {
+			mc.AuthorTrust, mc.HasTrust = h.trust.ArticleTrust(article), true
+		}
+		if h.voteService != nil {
+			if tally, err := h.voteService.Tally(ctx, article.ID); err == nil {
+				mc.Votes = tally
+			}
+		}
+	}
+
+	data := gin.H{
+		"Title":     h.t(c, "page.moderation"),
+		"User":      user,
+		"Cases":     cases,
+		"Done":      c.Query("done"),
+		"Error":     c.Query("error"),
+		"PeerCount": h.getPeerCount(),
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := h.template(c, "moderation").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(ctx).Error("Template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+	}
+}
+
+// WebModerate applies a moderator's decision to every open report on an
+// article and returns to the queue
+func (h *WebHandler) WebModerate(c *gin.Context) {
+	user := h.requireModerator(c)
+	if user == nil {
+		return
+	}
+	ctx := c.Request.Context()
+
+	articleID := c.Param("id")
+	action := c.PostForm("action")
+	req := &domain.ReportDecisionRequest{Note: c.PostForm("note")}
+	if len([]rune(req.Note)) > domain.MaxReportReasonLength {
+		c.Redirect(http.StatusSeeOther, "/moderation?error=note")
+		return
+	}
+
+	var err error
+	switch action {
+	case moderationApprove:
+		_, err = h.moderation.DismissArticle(ctx, articleID, user.ID, req)
+	case moderationHide:
+		_, err = h.moderation.ResolveArticle(ctx, articleID, user.ID, req)
+	case moderationEscalate:
+		req.Broadcast = true
+		_, err = h.moderation.ResolveArticle(ctx, articleID, user.ID, req)
+	default:
+		c.Redirect(http.StatusSeeOther, "/moderation?error=action")
+		return
+	}
+
+	switch {
+	case err == nil:
+		c.Redirect(http.StatusSeeOther, "/moderation?done="+action)
+	case errors.Is(err, service.ErrModerationOffline):
+		c.Redirect(http.StatusSeeOther, "/moderation?error=offline")
+	case errors.Is(err, domain.ErrReportNotFound):
+		c.Redirect(http.StatusSeeOther, "/moderation?error=closed")
+	default:
+		h.logger.Ctx(ctx).Error("Moderation decision failed", "article_id", articleID, "action", action, "error", err)
+		c.Redirect(http.StatusSeeOther, "/moderation?error=failed")
+	}
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+)
+
+// networkPeer is one connected peer as shown on the network page
+type networkPeer struct {
+	p2p.TopologyLink
+	ID           string
+	AgentVersion string
+	Bootstrap    bool
+	Sync         *p2p.PeerSync // nil until this node has synced with the peer
+	Stats        *p2p.PeerSyncStats
+}
+
+// SetSyncService shows article sync progress on the network page
+func (h *WebHandler) SetSyncService(syncService *p2p.SyncService) {
+	h.syncService = syncService
+}
+
+// networkData gathers the live section of the network page: per-peer
+// links, bandwidth, bootstrap connectivity and sync progress
+func (h *WebHandler) networkData() gin.H {
+	data := gin.H{"Online": h.p2pNode != nil}
+	if h.p2pNode == nil {
+		return data
+	}
+
+	topology := h.p2pNode.Topology()
+	discovery := h.p2pNode.GetAutoDiscovery()
+
+	var syncs map[string]p2p.PeerSync
+	stats := make(map[string]*p2p.PeerSyncStats)
+	if h.syncService != nil {
+		syncs = h.syncService.PeerSyncs()
+		syncStats := h.syncService.SyncStats()
+		for i := range syncStats.Peers {
+			stats[syncStats.Peers[i].PeerID] = &syncStats.Peers[i]
+		}
+		data["SyncTotals"] = syncStats.Totals
+		last, interval := h.syncService.GetLastSyncTime(), h.syncService.SyncInterval()
+		data["SyncEnabled"] = true
+		data["LastSync"] = last
+		data["SyncInterval"] = interval
+		// Two missed rounds means the sync loop is stuck
+		data["SyncStalled"] = !last.IsZero() && time.Since(last) > 2*interval
+	}
+
+	// Topology lists peers and links in the same order
+	peers := make([]*networkPeer, len(topology.Peers))
+	synced := 0
+	for i, tp := range topology.Peers {
+		np := &networkPeer{
+			TopologyLink: topology.Links[i],
+			ID:           tp.ID,
+			AgentVersion: tp.AgentVersion,
+			Stats:        stats[tp.ID],
+		}
+		if id, err := peer.Decode(tp.ID); err == nil && discovery != nil {
+			np.Bootstrap = discovery.IsBootstrap(id)
+		}
+		if ps, ok := syncs[tp.ID]; ok {
+			np.Sync = &ps
+			if ps.Error == "" {
+				synced++
+			}
+		}
+		peers[i] = np
+	}
+	// Fastest links first; peers without a latency sample yet go last
+	sort.SliceStable(peers, func(i, j int) bool {
+		li, lj := peers[i].LatencyMS, peers[j].LatencyMS
+		if li == 0 || lj == 0 {
+			return li != 0
+		}
+		return li < lj
+	})
+
+	data["Peers"] = peers
+	data["PeerCount"] = len(peers)
+	data["Synced"] = synced
+	data["Bandwidth"] = h.p2pNode.Bandwidth()
+	data["RoutingTable"] = h.p2pNode.RoutingTableSize()
+	if discovery != nil {
+		data["BootstrapConnected"] = discovery.GetConnectedBootstraps()
+		data["BootstrapKnown"] = discovery.GetKnownBootstraps()
+	}
+	return data
+}
+
+// NetworkPage renders the P2P network status page
+func (h *WebHandler) NetworkPage(c *gin.Context) {
+	data := h.networkData()
+	data["Title"] = h.t(c, "page.network")
+	data["User"] = GetUser(c)
+	if _, ok := data["PeerCount"]; !ok {
+		data["PeerCount"] = 0
+	}
+
+	if h.p2pNode != nil {
+		// Node addresses for sharing
+		peerID := h.p2pNode.GetPeerID().String()
+		var addresses []string
+		for _, addr := range h.p2pNode.GetHost().Addrs() {
+			addresses = append(addresses, addr.String()+"/p2p/"+peerID)
+		}
+		data["PeerID"] = peerID
+		data["Addresses"] = addresses
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := h.template(c, "network").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+	}
+}
+
+// NetworkLive renders the live section of the network page, which HTMX
+// polls to keep the peer list current
+func (h *WebHandler) NetworkLive(c *gin.Context) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := h.template(c, "network").ExecuteTemplate(c.Writer, "network_live.html", h.networkData()); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+	}
+}
+
+// formatBytes renders a byte count, or a rate in bytes per second, with
+// a binary unit
+func formatBytes(n interface{}) string {
+	var v float64
+	switch n := n.(type) {
+	case int64:
+		v = float64(n)
+	case float64:
+		v = n
+	case int:
+		v = float64(n)
+	}
+
+	const unit = 1024
+	if v < unit {
+		return fmt.Sprintf("%.0f B", v)
+	}
+	exp := 0
+	for v >= unit*unit && exp < 4 {
+		v /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", v/unit, "KMGTP"[exp])
+}
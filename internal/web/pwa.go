@@ -0,0 +1,258 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"text/template"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// pwaCacheVersion names the service worker's caches. Bump it when the
+	// worker's caching changes so clients drop what older workers stored.
+	pwaCacheVersion = 1
+	// pwaMaxArticles is how many recently viewed articles stay cached
+	pwaMaxArticles = 50
+	// pwaNetworkTimeoutMS is how long a page request waits on the network
+	// before a cached copy is served instead
+	pwaNetworkTimeoutMS = 4000
+)
+
+// The shell is cached when the service worker installs, so the site opens
+// with its layout intact offline. Other pages aren't cached: they may
+// hold private data, like the dashboard and moderation queue.
+var (
+	pwaShellPages  = []string{"/", "/explore"}
+	pwaShellAssets = []string{
+		"/icon.svg",
+		"https://cdn.tailwindcss.com",
+		"https://unpkg.com/htmx.org@1.9.10",
+		"https://cdn.jsdelivr.net/npm/alpinejs@3.x.x/dist/cdn.min.js",
+	}
+)
+
+// webManifest is the Web App Manifest that makes the site installable
+type webManifest struct {
+	Name            string         `json:"name"`
+	ShortName       string         `json:"short_name"`
+	Description     string         `json:"description"`
+	StartURL        string         `json:"start_url"`
+	Scope           string         `json:"scope"`
+	Display         string         `json:"display"`
+	BackgroundColor string         `json:"background_color"`
+	ThemeColor      string         `json:"theme_color"`
+	Icons           []manifestIcon `json:"icons"`
+}
+
+type manifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type"`
+	Purpose string `json:"purpose"`
+}
+
+// Manifest serves the Web App Manifest
+func (h *WebHandler) Manifest(c *gin.Context) {
+	manifest := webManifest{
+		Name:            "Liberation News",
+		ShortName:       "Liberation",
+		Description:     "Decentralized news over IPFS and libp2p",
+		StartURL:        "/",
+		Scope:           "/",
+		Display:         "standalone",
+		BackgroundColor: "#000000",
+		ThemeColor:      "#000000",
+		Icons: []manifestIcon{
+			{Src: "/icon.svg", Sizes: "any", Type: "image/svg+xml", Purpose: "any maskable"},
+		},
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Manifest error")
+		return
+	}
+	c.Data(http.StatusOK, "application/manifest+json", data)
+}
+
+// pwaIcon is the app icon: the site's initial on black, inside the safe
+// zone maskable icons are cropped to
+const pwaIcon = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 512 512">` +
+	`<rect width="512" height="512" fill="#000"/>` +
+	`<text x="256" y="340" font-family="Ubuntu, Arial, sans-serif" font-size="260" font-weight="700" fill="#fff" text-anchor="middle">L</text>` +
+	`</svg>`
+
+// Icon serves the app icon
+func (h *WebHandler) Icon(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "image/svg+xml", []byte(pwaIcon))
+}
+
+// ServiceWorker serves the service worker script. It must be served from
+// the site root to control every page.
+func (h *WebHandler) ServiceWorker(c *gin.Context) {
+	var buf bytes.Buffer
+	if err := serviceWorkerTemplate.Execute(&buf, gin.H{
+		"Version":     pwaCacheVersion,
+		"Pages":       pwaShellPages,
+		"Assets":      pwaShellAssets,
+		"MaxArticles": pwaMaxArticles,
+		"TimeoutMS":   pwaNetworkTimeoutMS,
+	}); err != nil {
+		h.logger.Ctx(c.Request.Context()).Error("Service worker template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Browsers check for a new worker on navigation; never let a stale
+	// copy sit in an HTTP cache
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/javascript; charset=utf-8", buf.Bytes())
+}
+
+// serviceWorkerTemplate caches the shell on install. Shell pages and
+// articles are fetched network first, falling back to the cache when the
+// network fails or is slow. The most recently viewed MaxArticles articles
+// are kept. Logging out clears cached pages.
+var serviceWorkerTemplate = template.Must(template.New("sw.js").Funcs(template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}).Parse(`// Generated by the server; do not edit
+const SHELL_CACHE = "shell-v{{.Version}}";
+const ARTICLE_CACHE = "articles-v{{.Version}}";
+const PAGES = {{json .Pages}};
+const ASSETS = {{json .Assets}};
+const MAX_ARTICLES = {{.MaxArticles}};
+const TIMEOUT_MS = {{.TimeoutMS}};
+
+self.addEventListener("install", (event) => {
+    event.waitUntil(
+        caches.open(SHELL_CACHE).then((cache) =>
+            Promise.all(PAGES.concat(ASSETS).map((url) => {
+                // Third-party assets can only be fetched opaquely
+                const sameOrigin = new URL(url, self.location).origin === self.location.origin;
+                return fetch(url, sameOrigin ? {} : { mode: "no-cors" })
+                    .then((response) => cache.put(url, response))
+                    .catch(() => {});
+            }))
+        ).then(() => self.skipWaiting())
+    );
+});
+
+self.addEventListener("activate", (event) => {
+    event.waitUntil(
+        caches.keys().then((names) =>
+            Promise.all(names
+                .filter((name) => name !== SHELL_CACHE && name !== ARTICLE_CACHE)
+                .map((name) => caches.delete(name)))
+        ).then(() => self.clients.claim())
+    );
+});
+
+function isArticle(url) {
+    return /^\/article\/[^/]+(\/reader)?$/.test(url.pathname);
+}
+
+// Keep only the most recently viewed articles; cache keys are in
+// insertion order and a re-viewed article is re-inserted
+async function trimArticles(cache) {
+    const keys = await cache.keys();
+    for (let i = 0; i < keys.length - MAX_ARTICLES; i++) {
+        await cache.delete(keys[i]);
+    }
+}
+
+function withTimeout(promise) {
+    return new Promise((resolve, reject) => {
+        const timer = setTimeout(() => reject(new Error("timeout")), TIMEOUT_MS);
+        promise.then((value) => { clearTimeout(timer); resolve(value); },
+                     (err) => { clearTimeout(timer); reject(err); });
+    });
+}
+
+async function offlinePage() {
+    const cache = await caches.open(ARTICLE_CACHE);
+    const keys = await cache.keys();
+    const links = [];
+    for (const request of keys.reverse()) {
+        const response = await cache.match(request);
+        const html = response ? await response.text() : "";
+        const match = html.match(/<title>([^<]*)<\/title>/);
+        const title = match ? match[1] : new URL(request.url).pathname;
+        links.push('<li><a href="' + new URL(request.url).pathname + '">' + title + "</a></li>");
+    }
+    const body = "<!doctype html><meta charset=utf-8><meta name=viewport content='width=device-width'>" +
+        "<title>Offline - Liberation News</title>" +
+        "<body style='font-family:system-ui,sans-serif;max-width:40rem;margin:2rem auto;padding:0 1rem'>" +
+        "<h1>You're offline</h1>" +
+        (links.length ? "<p>Articles you've read are saved on this device:</p><ul>" + links.join("") + "</ul>"
+                      : "<p>Articles you read while online will be saved here.</p>");
+    return new Response(body, { status: 503, headers: { "Content-Type": "text/html; charset=utf-8" } });
+}
+
+async function networkFirst(request, cacheName) {
+    const cache = await caches.open(cacheName);
+    try {
+        const response = await withTimeout(fetch(request));
+        if (response.ok) {
+            await cache.delete(request);
+            await cache.put(request, response.clone());
+            if (cacheName === ARTICLE_CACHE) {
+                await trimArticles(cache);
+            }
+        }
+        return response;
+    } catch (err) {
+        const cached = await cache.match(request);
+        if (cached) {
+            return cached;
+        }
+        return request.mode === "navigate" ? offlinePage() : Response.error();
+    }
+}
+
+self.addEventListener("fetch", (event) => {
+    const request = event.request;
+    if (request.method !== "GET") {
+        return;
+    }
+    const url = new URL(request.url);
+
+    // Shell assets: cache first
+    if (ASSETS.includes(request.url) || ASSETS.includes(url.pathname)) {
+        event.respondWith(caches.match(request.url).then((cached) => cached || fetch(request)));
+        return;
+    }
+    if (url.origin !== self.location.origin) {
+        return;
+    }
+
+    // Cached pages show who was logged in, so logging out clears them
+    if (url.pathname === "/logout") {
+        event.respondWith(
+            Promise.all([
+                caches.delete(ARTICLE_CACHE),
+                caches.open(SHELL_CACHE).then((cache) => Promise.all(PAGES.map((page) => cache.delete(page)))),
+            ]).then(() => fetch(request))
+        );
+        return;
+    }
+
+    // API calls, HTMX fragments and the worker itself are never cached
+    if (url.pathname.startsWith("/api/") || request.headers.get("HX-Request") || url.pathname === "/sw.js") {
+        return;
+    }
+
+    if (isArticle(url)) {
+        event.respondWith(networkFirst(request, ARTICLE_CACHE));
+    } else if (PAGES.includes(url.pathname) && !url.search) {
+        event.respondWith(networkFirst(request, SHELL_CACHE));
+    } else if (request.mode === "navigate") {
+        event.respondWith(fetch(request).catch(() => offlinePage()));
+    }
+});
+`))
@@ -0,0 +1,91 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// tagCloudSize is the number of tags in the explore page's tag cloud
+const tagCloudSize = 30
+
+// tagCloudEntry is one tag in the tag cloud, sized by how often it is used
+type tagCloudEntry struct {
+	domain.TagCount
+	Size string // Tailwind text size class
+}
+
+// tagCloud sizes the most used tags in five steps between the least and
+// most used of them, keeping them in alphabetical order
+func (h *WebHandler) tagCloud(c *gin.Context) []tagCloudEntry {
+	tags, err := h.articleService.ListTags(c.Request.Context(), tagCloudSize)
+	if err != nil || len(tags) == 0 {
+		return nil
+	}
+
+	sizes := []string{"text-xs", "text-sm", "text-base", "text-lg", "text-xl"}
+	lowest, highest := tags[len(tags)-1].Count, tags[0].Count
+	entries := make([]tagCloudEntry, len(tags))
+	for i, tag := range tags {
+		step := 0
+		if highest > lowest {
+			step = (tag.Count - lowest) * (len(sizes) - 1) / (highest - lowest)
+		}
+		entries[i] = tagCloudEntry{TagCount: tag, Size: sizes[step]}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Tag < entries[j].Tag })
+	return entries
+}
+
+// TagPage renders the articles carrying one tag, newest first. Like the
+// explore page, HTMX requests for later pages get just the next batch.
+func (h *WebHandler) TagPage(c *gin.Context) {
+	ctx := c.Request.Context()
+	tag := c.Param("name")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	articles, total, err := h.articleService.List(ctx, &domain.ArticleListFilter{
+		Tags:  []string{tag},
+		Page:  page,
+		Limit: explorePageSize,
+	})
+	if err != nil {
+		h.logger.Ctx(ctx).Error("Failed to get tagged articles", "tag", tag, "error", err)
+		articles = []*domain.Article{}
+	}
+
+	data := gin.H{
+		"Title":     "#" + tag,
+		"User":      GetUser(c),
+		"Tag":       tag,
+		"Articles":  articles,
+		"Total":     total,
+		"PeerCount": h.getPeerCount(),
+	}
+	if page*explorePageSize < total {
+		data["NextURL"] = "/tag/" + url.PathEscape(tag) + "?page=" + strconv.Itoa(page+1)
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if isHTMX(c) {
+		data["Continued"] = page > 1
+		if err := h.template(c, "tag").ExecuteTemplate(c.Writer, "article_list.html", data); err != nil {
+			h.logger.Ctx(ctx).Error("Template error", "error", err)
+			c.String(http.StatusInternalServerError, "Template error")
+		}
+		return
+	}
+	if err := h.template(c, "tag").ExecuteTemplate(c.Writer, "base.html", data); err != nil {
+		h.logger.Ctx(ctx).Error("Template error", "error", err)
+		c.String(http.StatusInternalServerError, "Template error")
+	}
+}
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// KeyFile is the portable form keys are exported in. The private key is
+// encrypted with a passphrase unless exported unencrypted.
+type KeyFile struct {
+	Kind                string `json:"kind"` // node or user
+	Username            string `json:"username,omitempty"`
+	PeerID              string `json:"peer_id"`
+	DID                 string `json:"did"`
+	PublicKey           string `json:"public_key"`
+	PrivateKey          string `json:"private_key,omitempty"`
+	EncryptedPrivateKey string `json:"encrypted_private_key,omitempty"`
+}
+
+// ReadKeyFile parses a key file without touching its private key
+func ReadKeyFile(path string) (*KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file KeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s is not a key file: %w", path, err)
+	}
+	return &file, nil
+}
+
+// Encrypted reports whether the private key needs a passphrase
+func (f *KeyFile) Encrypted() bool {
+	return f.EncryptedPrivateKey != ""
+}
+
+// Key returns the private key, decrypting it with passphrase if needed.
+// The stored public key is checked against it.
+func (f *KeyFile) Key(passphrase string) (ed25519.PrivateKey, error) {
+	var privateKey ed25519.PrivateKey
+	var err error
+	switch {
+	case f.EncryptedPrivateKey != "":
+		if privateKey, err = DecryptPrivateKey(f.EncryptedPrivateKey, passphrase); err != nil {
+			return nil, fmt.Errorf("wrong passphrase or damaged key file: %w", err)
+		}
+	case f.PrivateKey != "":
+		if privateKey, err = PrivateKeyFromString(f.PrivateKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("key file holds no private key")
+	}
+
+	if f.PublicKey != "" && f.PublicKey != PublicKeyToString(privateKey.Public().(ed25519.PublicKey)) {
+		return nil, fmt.Errorf("private key does not match its public key")
+	}
+	return privateKey, nil
+}
+
+// Write saves the key file readable by its owner only
+func (f *KeyFile) Write(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
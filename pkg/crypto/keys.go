@@ -9,6 +9,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"strings"
 
 	"golang.org/x/crypto/pbkdf2"
 )
@@ -45,6 +46,39 @@ func PublicKeyToString(publicKey ed25519.PublicKey) string {
 	return base64.StdEncoding.EncodeToString(publicKey)
 }
 
+// Fingerprint returns a short, human-comparable identifier for a public
+// key: the first 16 bytes of its SHA-256 digest as grouped hex
+func Fingerprint(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	groups := make([]string, 8)
+	for i := range groups {
+		groups[i] = fmt.Sprintf("%X", sum[i*2:i*2+2])
+	}
+	return strings.Join(groups, " ")
+}
+
+// DIDKey returns the did:key identifier nodes use for a public key in DID
+// authentication
+func DIDKey(publicKey ed25519.PublicKey) string {
+	return "did:key:" + base64.RawURLEncoding.EncodeToString(publicKey)
+}
+
+// PublicKeyFromDID parses a did:key identifier made by DIDKey
+func PublicKeyFromDID(did string) (ed25519.PublicKey, error) {
+	encoded, ok := strings.CutPrefix(did, "did:key:")
+	if !ok {
+		return nil, fmt.Errorf("not a did:key identifier: %s", did)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DID: %w", err)
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: got %d, want %d", len(data), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(data), nil
+}
+
 // PrivateKeyToString converts a private key to base64 string
 func PrivateKeyToString(privateKey ed25519.PrivateKey) string {
 	return base64.StdEncoding.EncodeToString(privateKey)
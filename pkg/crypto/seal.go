@@ -0,0 +1,145 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// NewSecretKey generates a random AES-256 key
+func NewSecretKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// EncryptionKey derives the X25519 key a user receives encrypted keys with
+// from their Ed25519 signing key, the same way as RFC 8032 expands the
+// seed, so users need no second key pair
+func EncryptionKey(privateKey ed25519.PrivateKey) (*ecdh.PrivateKey, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key size")
+	}
+	digest := sha512.Sum512(privateKey.Seed())
+	return ecdh.X25519().NewPrivateKey(digest[:32])
+}
+
+// EncryptionKeyToString converts an X25519 public key to base64 string
+func EncryptionKeyToString(publicKey *ecdh.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(publicKey.Bytes())
+}
+
+// EncryptionKeyFromString converts a base64 string to an X25519 public key
+func EncryptionKeyFromString(s string) (*ecdh.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption key: %w", err)
+	}
+	publicKey, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return publicKey, nil
+}
+
+// Seal encrypts plaintext with an AES-256 key using AES-GCM, binding it to
+// aad. Returns base64(nonce || ciphertext).
+func Seal(key, plaintext, aad []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, NonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, aad)), nil
+}
+
+// Open decrypts data sealed with Seal under the same key and aad
+func Open(key []byte, sealed string, aad []byte) ([]byte, error) {
+	combined, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sealed data: %w", err)
+	}
+	if len(combined) < NonceSize {
+		return nil, fmt.Errorf("sealed data too short")
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, combined[:NonceSize], combined[NonceSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// WrapKey encrypts key for the holder of recipient's private key. A fresh
+// ephemeral X25519 key agrees a one-time key with recipient; the ephemeral
+// public key is returned with the wrapped key, both base64.
+func WrapKey(recipient *ecdh.PublicKey, key, aad []byte) (ephemeral, wrapped string, err error) {
+	ephemeralKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	shared, err := ephemeralKey.ECDH(recipient)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to agree key: %w", err)
+	}
+
+	wrapped, err = Seal(wrappingKey(shared, ephemeralKey.PublicKey(), recipient), key, aad)
+	if err != nil {
+		return "", "", err
+	}
+	return EncryptionKeyToString(ephemeralKey.PublicKey()), wrapped, nil
+}
+
+// UnwrapKey decrypts a key wrapped with WrapKey for privateKey
+func UnwrapKey(privateKey *ecdh.PrivateKey, ephemeral, wrapped string, aad []byte) ([]byte, error) {
+	ephemeralKey, err := EncryptionKeyFromString(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := privateKey.ECDH(ephemeralKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to agree key: %w", err)
+	}
+	return Open(wrappingKey(shared, ephemeralKey, privateKey.PublicKey()), wrapped, aad)
+}
+
+// wrappingKey hashes an X25519 shared secret with both public keys into
+// an AES-256 key
+func wrappingKey(shared []byte, ephemeral, recipient *ecdh.PublicKey) []byte {
+	h := sha256.New()
+	h.Write(shared)
+	h.Write(ephemeral.Bytes())
+	h.Write(recipient.Bytes())
+	return h.Sum(nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
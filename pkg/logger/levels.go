@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels holds the level every logger from New starts at and any
+// per-component overrides, keyed by the name given to WithComponent.
+// Changes apply immediately to loggers already handed out.
+type Levels struct {
+	state atomic.Pointer[levelState]
+	mu    sync.Mutex // serialises writers; readers use state
+	known sync.Map   // component names seen by WithComponent
+}
+
+// levelState is replaced, never modified, so Enabled checks need no lock
+type levelState struct {
+	base       zapcore.Level
+	components map[string]zapcore.Level
+}
+
+// LevelSnapshot is the current configuration of a Levels
+type LevelSnapshot struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+	// Known lists every component that has logged so far
+	Known []string `json:"known"`
+}
+
+func newLevels(base zapcore.Level) *Levels {
+	l := &Levels{}
+	l.state.Store(&levelState{base: base, components: map[string]zapcore.Level{}})
+	return l
+}
+
+// enabled reports whether component logs at lvl
+func (l *Levels) enabled(component string, lvl zapcore.Level) bool {
+	state := l.state.Load()
+	if min, ok := state.components[component]; ok {
+		return lvl >= min
+	}
+	return lvl >= state.base
+}
+
+// SetLevel sets the level for loggers without a component override
+func (l *Levels) SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.update(func(s *levelState) { s.base = lvl })
+	return nil
+}
+
+// SetComponent overrides the level for one component. An empty level
+// removes the override.
+func (l *Levels) SetComponent(component, level string) error {
+	if component == "" {
+		return fmt.Errorf("component name is required")
+	}
+	if level == "" {
+		l.update(func(s *levelState) { delete(s.components, component) })
+		return nil
+	}
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.update(func(s *levelState) { s.components[component] = lvl })
+	return nil
+}
+
+// Replace sets the base level and swaps all overrides for components, as
+// when the config file is reloaded. Nothing changes if a level is invalid.
+func (l *Levels) Replace(level string, components map[string]string) error {
+	base, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	parsed := make(map[string]zapcore.Level, len(components))
+	for component, value := range components {
+		lvl, err := parseLevel(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", component, err)
+		}
+		parsed[component] = lvl
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.state.Store(&levelState{base: base, components: parsed})
+	return nil
+}
+
+// Snapshot returns the current levels
+func (l *Levels) Snapshot() LevelSnapshot {
+	state := l.state.Load()
+	snapshot := LevelSnapshot{
+		Level:      state.base.String(),
+		Components: make(map[string]string, len(state.components)),
+		Known:      []string{},
+	}
+	for component, lvl := range state.components {
+		snapshot.Components[component] = lvl.String()
+	}
+	l.known.Range(func(key, _ any) bool {
+		snapshot.Known = append(snapshot.Known, key.(string))
+		return true
+	})
+	sort.Strings(snapshot.Known)
+	return snapshot
+}
+
+func (l *Levels) update(change func(*levelState)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state := l.state.Load()
+	next := &levelState{base: state.base, components: maps.Clone(state.components)}
+	change(next)
+	l.state.Store(next)
+}
+
+// componentCore filters entries by the level of the component its logger
+// was created for. The cores it wraps are built at DebugLevel so an
+// override can go below the base level.
+type componentCore struct {
+	zapcore.Core
+	levels    *Levels
+	component string
+}
+
+func (c *componentCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.enabled(c.component, lvl)
+}
+
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &componentCore{Core: c.Core.With(fields), levels: c.levels, component: c.component}
+}
+
+func (c *componentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// forComponent returns an option that rebinds a logger's core to component
+func (l *Levels) forComponent(component string) zap.Option {
+	l.known.Store(component, struct{}{})
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if cc, ok := core.(*componentCore); ok {
+			core = cc.Core
+		}
+		return &componentCore{Core: core, levels: l, component: component}
+	})
+}
@@ -1,19 +1,41 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Logger wraps zap.Logger to provide application-wide logging
 type Logger struct {
 	*zap.Logger
+	levels *Levels
+}
+
+// FileOptions configures a log file written alongside console output.
+// The file is rotated once it reaches MaxSizeMB; rotated files beyond
+// MaxBackups or older than MaxAgeDays are removed (0 keeps them all).
+type FileOptions struct {
+	Path       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool // gzip rotated files
 }
 
 // New creates a new logger with the specified level and format
 func New(level, format string) (*Logger, error) {
+	return NewWithFile(level, format, FileOptions{})
+}
+
+// NewWithFile creates a logger that also writes to a rotating log file
+// when file.Path is set. The file gets the same format as the console,
+// without colours.
+func NewWithFile(level, format string, file FileOptions) (*Logger, error) {
 	var zapConfig zap.Config
 	var opts []zap.Option
 
@@ -35,12 +57,37 @@ func New(level, format string) (*Logger, error) {
 		zapConfig.DisableStacktrace = true
 	}
 
-	// Set log level
+	// Levels are applied per component by componentCore, so the cores
+	// underneath pass everything through
 	zapLevel, err := parseLevel(level)
 	if err != nil {
 		return nil, err
 	}
-	zapConfig.Level = zap.NewAtomicLevelAt(zapLevel)
+	levels := newLevels(zapLevel)
+	zapConfig.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+
+	if file.Path != "" {
+		encoderConfig := zapConfig.EncoderConfig
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder := zapcore.NewJSONEncoder(encoderConfig)
+		if format != "json" {
+			encoder = zapcore.NewConsoleEncoder(encoderConfig)
+		}
+		writer := zapcore.AddSync(&lumberjack.Logger{
+			Filename:   file.Path,
+			MaxSize:    file.MaxSizeMB,
+			MaxBackups: file.MaxBackups,
+			MaxAge:     file.MaxAgeDays,
+			Compress:   file.Compress,
+		})
+		fileCore := zapcore.NewCore(encoder, writer, zapcore.DebugLevel)
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, fileCore)
+		}))
+	}
+	opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &componentCore{Core: core, levels: levels}
+	}))
 
 	// Build logger
 	zapLogger, err := zapConfig.Build(opts...)
@@ -48,7 +95,13 @@ func New(level, format string) (*Logger, error) {
 		return nil, fmt.Errorf("failed to build logger: %w", err)
 	}
 
-	return &Logger{Logger: zapLogger}, nil
+	return &Logger{Logger: zapLogger, levels: levels}, nil
+}
+
+// Levels returns the levels shared by l and every logger derived from it,
+// or nil for a Logger not built by New
+func (l *Logger) Levels() *Levels {
+	return l.levels
 }
 
 // parseLevel converts string level to zapcore.Level
@@ -73,22 +126,61 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	for key, value := range fields {
 		zapFields = append(zapFields, zap.Any(key, value))
 	}
-	return &Logger{Logger: l.With(zapFields...)}
+	return &Logger{Logger: l.With(zapFields...), levels: l.levels}
 }
 
 // WithError returns a logger with an error field
 func (l *Logger) WithError(err error) *Logger {
-	return &Logger{Logger: l.With(zap.Error(err))}
+	return &Logger{Logger: l.With(zap.Error(err)), levels: l.levels}
 }
 
-// WithComponent returns a logger with a component field
+// WithComponent returns a logger with a component field, logging at the
+// level set for that component (see Levels.SetComponent)
 func (l *Logger) WithComponent(component string) *Logger {
-	return &Logger{Logger: l.With(zap.String("component", component))}
+	zapLogger := l.With(zap.String("component", component))
+	if l.levels != nil {
+		zapLogger = zapLogger.WithOptions(l.levels.forComponent(component))
+	}
+	return &Logger{Logger: zapLogger, levels: l.levels}
+}
+
+// requestIDKey carries a request ID through a context.Context
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying a request ID, which Ctx
+// adds to every entry logged for that request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Ctx returns a logger that tags entries with ctx's request ID and trace
+// ID, or l itself when ctx carries neither
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	if ctx == nil {
+		return l
+	}
+	var fields []zap.Field
+	if id := RequestID(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return &Logger{Logger: l.With(fields...), levels: l.levels}
 }
 
 // Named returns a logger with a name
 func (l *Logger) Named(name string) *Logger {
-	return &Logger{Logger: l.Logger.Named(name)}
+	return &Logger{Logger: l.Logger.Named(name), levels: l.levels}
 }
 
 // Info logs a message with key-value pairs
@@ -0,0 +1,101 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// problemKey marks requests whose errors are reported as problem details
+const problemKey = "response.problem_details"
+
+// Problem is an RFC 7807 problem details body
+type Problem struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Status     int    `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Instance   string `json:"instance,omitempty"`
+	RetryAfter int    `json:"retry_after,omitempty"` // extension member on 429s
+}
+
+// Envelope is the body of every successful v2 response
+type Envelope struct {
+	Data  interface{}       `json:"data"`
+	Meta  *Meta             `json:"meta,omitempty"`
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// Meta describes a page of a cursor-paginated collection. Pass NextCursor
+// back as ?cursor= for the following page; it is empty on the last one.
+type Meta struct {
+	Limit      int    `json:"limit"`
+	Total      *int   `json:"total,omitempty"` // only where counting is cheap
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// ProblemDetails makes Error and the helpers built on it answer with
+// application/problem+json for the routes it is applied to, including
+// errors raised by shared middleware such as auth and rate limiting
+func ProblemDetails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(problemKey, true)
+		c.Next()
+	}
+}
+
+// WriteProblem sends an RFC 7807 problem. The type is about:blank, so the
+// title is the standard text for the status code.
+func WriteProblem(c *gin.Context, status int, detail string) {
+	c.Render(status, problemRender{Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+	}})
+}
+
+// WriteEnvelope sends data in the v2 envelope, mirroring links into a
+// Link header so clients can page without parsing the body
+func WriteEnvelope(c *gin.Context, status int, data interface{}, meta *Meta, links map[string]string) {
+	setLinkHeader(c, links)
+	c.JSON(status, Envelope{Data: data, Meta: meta, Links: links})
+}
+
+// setLinkHeader writes links as an RFC 5988 Link header, sorted by rel
+func setLinkHeader(c *gin.Context, links map[string]string) {
+	if len(links) == 0 {
+		return
+	}
+	rels := make([]string, 0, len(links))
+	for rel := range links {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	parts := make([]string, len(rels))
+	for i, rel := range rels {
+		parts[i] = fmt.Sprintf("<%s>; rel=%q", links[rel], rel)
+	}
+	c.Header("Link", strings.Join(parts, ", "))
+}
+
+// problemRender writes JSON without overriding the problem content type
+type problemRender struct {
+	problem Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/problem+json")
+}
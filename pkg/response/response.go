@@ -2,16 +2,18 @@ package response
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Response represents a standard API response
 type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Message string      `json:"message,omitempty"`
+	Success    bool        `json:"success"`
+	Data       interface{} `json:"data,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	RetryAfter int         `json:"retry_after,omitempty"` // seconds, on 429s
 }
 
 // PaginatedResponse represents a paginated API response
@@ -21,12 +23,41 @@ type PaginatedResponse struct {
 	Pagination Pagination  `json:"pagination"`
 }
 
-// Pagination represents pagination metadata
+// Pagination represents pagination metadata. The cursors are the
+// neighbouring page numbers, accepted back as ?cursor= (or ?page=), and are
+// empty at either end of the collection.
 type Pagination struct {
-	Page       int `json:"page"`
-	Limit      int `json:"limit"`
-	Total      int `json:"total"`
-	TotalPages int `json:"total_pages"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// NewPagination computes the metadata for one page of a collection
+func NewPagination(page, limit, total int) Pagination {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = total / limit
+		if total%limit > 0 {
+			totalPages++
+		}
+	}
+
+	p := Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+	if page < totalPages {
+		p.NextCursor = strconv.Itoa(page + 1)
+	}
+	if page > 1 {
+		p.PrevCursor = strconv.Itoa(min(page-1, max(totalPages, 1)))
+	}
+	return p
 }
 
 // Success sends a successful response
@@ -54,30 +85,53 @@ func SuccessWithMessage(c *gin.Context, message string, data interface{}) {
 	})
 }
 
-// Paginated sends a paginated response
+// Paginated sends a paginated response, with first, prev, next and last
+// page URLs in a Link header
 func Paginated(c *gin.Context, data interface{}, page, limit, total int) {
-	totalPages := 0
-	if limit > 0 {
-		totalPages = total / limit
-		if total%limit > 0 {
-			totalPages++
-		}
-	}
+	pagination := NewPagination(page, limit, total)
+	SetPageLinks(c, pagination)
 
 	c.JSON(http.StatusOK, PaginatedResponse{
-		Success: true,
-		Data:    data,
-		Pagination: Pagination{
-			Page:       page,
-			Limit:      limit,
-			Total:      total,
-			TotalPages: totalPages,
-		},
+		Success:    true,
+		Data:       data,
+		Pagination: pagination,
 	})
 }
 
-// Error sends an error response
+// SetPageLinks sets an RFC 5988 Link header pointing at the first, previous,
+// next and last pages of the request's collection. Every other query
+// parameter is preserved.
+func SetPageLinks(c *gin.Context, p Pagination) {
+	page := func(n int) string {
+		u := *c.Request.URL
+		query := u.Query()
+		query.Del("cursor")
+		query.Set("page", strconv.Itoa(n))
+		u.RawQuery = query.Encode()
+		return u.RequestURI()
+	}
+
+	links := map[string]string{"first": page(1)}
+	if p.TotalPages > 0 {
+		links["last"] = page(p.TotalPages)
+	}
+	if p.NextCursor != "" {
+		links["next"] = page(p.Page + 1)
+	}
+	if p.PrevCursor != "" {
+		prev, _ := strconv.Atoi(p.PrevCursor)
+		links["prev"] = page(prev)
+	}
+	setLinkHeader(c, links)
+}
+
+// Error sends an error response, as problem details on routes using
+// ProblemDetails
 func Error(c *gin.Context, statusCode int, message string) {
+	if c.GetBool(problemKey) {
+		WriteProblem(c, statusCode, message)
+		return
+	}
 	c.JSON(statusCode, Response{
 		Success: false,
 		Error:   message,
@@ -109,6 +163,28 @@ func Conflict(c *gin.Context, message string) {
 	Error(c, http.StatusConflict, message)
 }
 
+// TooManyRequests sends a 429 Too Many Requests response telling the client
+// how many seconds to wait, both in Retry-After and in the body
+func TooManyRequests(c *gin.Context, retryAfter int, message string) {
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	if c.GetBool(problemKey) {
+		c.Render(http.StatusTooManyRequests, problemRender{Problem{
+			Type:       "about:blank",
+			Title:      http.StatusText(http.StatusTooManyRequests),
+			Status:     http.StatusTooManyRequests,
+			Detail:     message,
+			Instance:   c.Request.URL.Path,
+			RetryAfter: retryAfter,
+		}})
+		return
+	}
+	c.JSON(http.StatusTooManyRequests, Response{
+		Success:    false,
+		Error:      message,
+		RetryAfter: retryAfter,
+	})
+}
+
 // InternalServerError sends a 500 Internal Server Error response
 func InternalServerError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, message)
@@ -0,0 +1,145 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// attachmentPins pins every CID except the unreachable ones
+type attachmentPins struct {
+	unreachable map[string]bool
+	pinned      []string
+}
+
+func (p *attachmentPins) Pin(ctx context.Context, cid string) error {
+	if p.unreachable[cid] {
+		return errors.New("no providers found")
+	}
+	p.pinned = append(p.pinned, cid)
+	return nil
+}
+
+func TestAcceptancePolicy(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+	author := SetupTestEnv(t)
+	defer author.Cleanup()
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	const (
+		reachable   = "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"
+		unreachable = "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o"
+	)
+	reputation := p2p.NewReputationSystem(log)
+	pins := &attachmentPins{unreachable: map[string]bool{unreachable: true}}
+	engine := service.NewPolicyEngine(domain.AcceptancePolicy{
+		Categories:               []string{"News", "science"},
+		Languages:                []string{"en", "und"},
+		MaxArticleSize:           1024,
+		MinAuthorReputation:      45,
+		RequireAttachmentsPinned: true,
+	}, log)
+	engine.SetReputation(reputation)
+	engine.SetPinner(pins)
+	env.ArticleService.SetAcceptancePolicy(engine)
+
+	writers := make(map[string]string)
+	for _, name := range []string{"writer", "troll"} {
+		user, err := author.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register: %v", err)
+		}
+		writers[name] = user.ID
+	}
+	publish := func(writer string, req domain.ArticleCreateRequest) *domain.Article {
+		t.Helper()
+		if req.Body == "" {
+			req.Body = "Body of " + req.Title
+		}
+		article, err := author.ArticleService.Create(ctx, &req, writers[writer], "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		return article
+	}
+	refused := func(article *domain.Article, rule string) {
+		t.Helper()
+		err := env.ArticleService.HandleIncomingArticle(article)
+		var refusal *domain.PolicyRefusal
+		if !errors.Is(err, domain.ErrArticleNotAccepted) || !errors.As(err, &refusal) || refusal.Rule != rule {
+			t.Errorf("Expected %q refused by the %s rule, got %v", article.Title, rule, err)
+		}
+	}
+	refusedBy := func(article *domain.Article, rule string) {
+		t.Helper()
+		refused(article, rule)
+		if env.ArticleService.HasArticle(ctx, article.ID) {
+			t.Errorf("Expected %q not to be stored", article.Title)
+		}
+	}
+
+	// 1. Articles within the policy are stored; attachments are pinned first
+	if _, err := author.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: "Bad tag", Body: "Body", Language: "english!"}, writers["writer"], "127.0.0.1"); err == nil {
+		t.Error("Expected an invalid language tag to be rejected")
+	}
+	fine := publish("writer", domain.ArticleCreateRequest{Title: "Fine", Category: "news", Language: "en-GB", Body: "See /ipfs/" + reachable})
+	untagged := publish("writer", domain.ArticleCreateRequest{Title: "Untagged", Category: "science"})
+	for _, article := range []*domain.Article{fine, untagged} {
+		if err := env.ArticleService.HandleIncomingArticle(article); err != nil {
+			t.Errorf("Expected %q accepted, got %v", article.Title, err)
+		}
+	}
+	if !env.ArticleService.HasArticle(ctx, fine.ID) || len(pins.pinned) != 1 || pins.pinned[0] != reachable {
+		t.Errorf("Expected the article stored after pinning its attachment, pinned %v", pins.pinned)
+	}
+	if stored, _ := env.ArticleRepo.GetByID(ctx, fine.ID); stored == nil || stored.Language != "en-GB" {
+		t.Errorf("Expected the language to travel with the article, got %+v", stored)
+	}
+
+	// 2. Each rule refuses on its own
+	refusedBy(publish("writer", domain.ArticleCreateRequest{Title: "Sports", Category: "sports", Language: "en"}), domain.AcceptCategory)
+	refusedBy(publish("writer", domain.ArticleCreateRequest{Title: "Nachrichten", Category: "news", Language: "de"}), domain.AcceptLanguage)
+	refusedBy(publish("writer", domain.ArticleCreateRequest{Title: "Long", Category: "news", Body: strings.Repeat("word ", 300)}), domain.AcceptSize)
+	refusedBy(publish("writer", domain.ArticleCreateRequest{Title: "Missing", Category: "news", Body: "See /ipfs/" + unreachable}), domain.AcceptAttachments)
+
+	reputation.RecordModeration("troll", true)
+	reputation.RecordModeration("troll", true)
+	refusedBy(publish("troll", domain.ArticleCreateRequest{Title: "Trolling", Category: "news"}), domain.AcceptReputation)
+
+	// 3. A revision is judged again; the stored one stays if it's refused
+	updated, err := author.ArticleService.Update(ctx, fine.ID, &domain.ArticleUpdateRequest{Language: "fr"}, writers["writer"])
+	if err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	refused(updated, domain.AcceptLanguage)
+	if stored, _ := env.ArticleRepo.GetByID(ctx, fine.ID); stored == nil || stored.Version != fine.Version || stored.Language != "en-GB" {
+		t.Errorf("Expected the accepted revision to stay, got %+v", stored)
+	}
+
+	// 4. Refusals are counted by rule
+	status := engine.Status()
+	want := map[string]int64{domain.AcceptCategory: 1, domain.AcceptLanguage: 1, domain.AcceptSize: 1, domain.AcceptAttachments: 1, domain.AcceptReputation: 1}
+	want[domain.AcceptLanguage]++ // the refused revision
+	for rule, n := range want {
+		if status.Refused[rule] != n {
+			t.Errorf("Expected %d refusals by %s, got %d", n, rule, status.Refused[rule])
+		}
+	}
+	if status.Policy.MaxArticleSize != 1024 {
+		t.Errorf("Expected the policy in the status, got %+v", status.Policy)
+	}
+
+	// 5. An empty policy accepts everything
+	open := service.NewPolicyEngine(domain.AcceptancePolicy{}, log)
+	if err := open.Evaluate(ctx, &domain.Article{Title: "Anything", Category: "sports", Language: "de", Body: "See /ipfs/" + unreachable}); err != nil {
+		t.Errorf("Expected an empty policy to accept, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestAddOptionsReachDaemon(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries = make(map[string][]url.Values) // command -> query of each call
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		cmd := strings.TrimPrefix(r.URL.Path, "/api/v0/")
+
+		mu.Lock()
+		queries[cmd] = append(queries[cmd], r.URL.Query())
+		mu.Unlock()
+
+		switch cmd {
+		case "add":
+			json.NewEncoder(w).Encode(map[string]string{"Name": "data", "Hash": "bafkreitest", "Size": "5"})
+		case "version":
+			json.NewEncoder(w).Encode(map[string]string{"Version": "0.32.0"})
+		case "dag/put":
+			json.NewEncoder(w).Encode(map[string]interface{}{"Cid": map[string]string{"/": "bafyreitest"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	client := ipfs.NewClient(server.URL, 5*time.Second, false, log)
+
+	// 1. Combinations the daemon would reject or silently upgrade are refused
+	for _, bad := range []ipfs.AddOptions{
+		{CIDVersion: 2},
+		{CIDVersion: 1, Hash: "md5-ish"},
+		{CIDVersion: 0, RawLeaves: true},
+		{CIDVersion: 0, Hash: "blake3"},
+	} {
+		if err := client.SetAddOptions(bad); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+
+	// 2. The defaults send nothing, leaving the daemon's CIDv0 behaviour
+	if _, err := client.Add(ctx, []byte("hello")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	mu.Lock()
+	if q := queries["add"][0]; q.Has("cid-version") || q.Has("hash") || q.Has("raw-leaves") {
+		t.Errorf("Expected no CID options by default, got %v", q)
+	}
+	mu.Unlock()
+
+	// 3. CIDv1 options are passed to Add, Hash and DagPut alike
+	if err := client.SetAddOptions(ipfs.AddOptions{CIDVersion: 1, RawLeaves: false, Hash: "blake3"}); err != nil {
+		t.Fatalf("SetAddOptions failed: %v", err)
+	}
+	if cid, err := client.Add(ctx, []byte("hello")); err != nil || cid != "bafkreitest" {
+		t.Fatalf("Add returned %q (%v)", cid, err)
+	}
+	if _, err := client.Hash(ctx, []byte("hello")); err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if _, err := client.DagPut(ctx, map[string]string{"title": "hello"}); err != nil {
+		t.Fatalf("DagPut failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, q := range queries["add"][1:] {
+		if q.Get("cid-version") != "1" || q.Get("hash") != "blake3" || q.Get("raw-leaves") != "false" {
+			t.Errorf("add call %d: unexpected options %v", i+1, q)
+		}
+	}
+	if q := queries["add"][2]; q.Get("only-hash") != "true" {
+		t.Errorf("Expected Hash to add with only-hash, got %v", q)
+	}
+	if q := queries["dag/put"][0]; q.Get("hash") != "blake3" {
+		t.Errorf("Expected DagPut to use the configured hash, got %v", q)
+	}
+}
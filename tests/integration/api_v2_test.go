@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+func TestAPIV2(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	h := handlers.NewV2Handler(env.ArticleService, nil, log)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	v2 := engine.Group("/api/v2")
+	v2.Use(response.ProblemDetails())
+	v2.GET("/articles", h.ListArticles)
+	v2.GET("/articles/:cid", h.GetArticle)
+	v2.POST("/articles", middleware.AuthMiddleware(env.JWTManager), h.CreateArticle)
+
+	do := func(method, target, token string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	ctx := context.Background()
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "erin", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	token, _, _ := env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+
+	// 1. Errors are problem details, including those from shared middleware
+	for _, tc := range []struct {
+		name, method, target, token string
+		body                        []byte
+		status                      int
+	}{
+		{"missing token", http.MethodPost, "/api/v2/articles", "", []byte(`{}`), http.StatusUnauthorized},
+		{"invalid article", http.MethodPost, "/api/v2/articles", token, []byte(`{"title":"No body"}`), http.StatusUnprocessableEntity},
+		{"bad cursor", http.MethodGet, "/api/v2/articles?cursor=bm9wZQ", "", nil, http.StatusBadRequest},
+		{"missing article", http.MethodGet, "/api/v2/articles/QmMissing", "", nil, http.StatusNotFound},
+	} {
+		w := do(tc.method, tc.target, tc.token, tc.body)
+		if w.Code != tc.status {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.status, w.Code, w.Body.String())
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s: expected problem+json, got %q", tc.name, ct)
+		}
+		var problem response.Problem
+		json.Unmarshal(w.Body.Bytes(), &problem)
+		if problem.Status != tc.status || problem.Title != http.StatusText(tc.status) || problem.Type != "about:blank" {
+			t.Errorf("%s: unexpected problem %+v", tc.name, problem)
+		}
+	}
+
+	// 2. Creating returns the article in the envelope with its location
+	var cids []string
+	for i := 0; i < 5; i++ {
+		body := []byte(fmt.Sprintf(`{"title":"Article %d","body":"Paged with cursors.","category":"technology"}`, i))
+		w := do(http.MethodPost, "/api/v2/articles", token, body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Create failed: %d %s", w.Code, w.Body.String())
+		}
+		var created struct {
+			Data domain.Article `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &created)
+		if loc := w.Header().Get("Location"); loc != "/api/v2/articles/"+created.Data.CID {
+			t.Errorf("Expected Location of the new article, got %q", loc)
+		}
+		cids = append(cids, created.Data.CID)
+	}
+
+	// 3. Cursors walk every article once, newest first, with Link headers
+	var seen []string
+	target := "/api/v2/articles?limit=2&category=technology"
+	for pages := 0; target != ""; pages++ {
+		if pages > 5 {
+			t.Fatal("Pagination did not terminate")
+		}
+		w := do(http.MethodGet, target, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("List failed: %d %s", w.Code, w.Body.String())
+		}
+		var page struct {
+			Data  []domain.Article  `json:"data"`
+			Meta  response.Meta     `json:"meta"`
+			Links map[string]string `json:"links"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &page)
+		for _, a := range page.Data {
+			seen = append(seen, a.CID)
+		}
+		if page.Meta.HasMore != (page.Links["next"] != "") {
+			t.Errorf("has_more %v does not match next link %q", page.Meta.HasMore, page.Links["next"])
+		}
+		if page.Links["next"] != "" && !strings.Contains(w.Header().Get("Link"), `<`+page.Links["next"]+`>; rel="next"`) {
+			t.Errorf("Link header %q lacks the next page", w.Header().Get("Link"))
+		}
+		target = page.Links["next"]
+	}
+	if len(seen) != len(cids) {
+		t.Fatalf("Expected %d articles across pages, got %d", len(cids), len(seen))
+	}
+	for i, cid := range seen {
+		if cid != cids[len(cids)-1-i] {
+			t.Errorf("Position %d: expected %s, got %s", i, cids[len(cids)-1-i], cid)
+		}
+	}
+
+	// 4. Single articles are wrapped the same way
+	w := do(http.MethodGet, "/api/v2/articles/"+cids[0], "", nil)
+	var single response.Envelope
+	if json.Unmarshal(w.Body.Bytes(), &single); w.Code != http.StatusOK || single.Links["self"] != "/api/v2/articles/"+cids[0] {
+		t.Errorf("Unexpected article response %d %s", w.Code, w.Body.String())
+	}
+}
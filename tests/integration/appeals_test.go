@@ -0,0 +1,226 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestAppeals(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+	remote := SetupTestEnv(t)
+	defer remote.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	moderation := service.NewModerationService(badger.NewReportRepo(env.DB), badger.NewModerationCaseRepo(env.DB), env.ArticleRepo, log)
+	broadcaster := mocks.NewMockModerationBroadcaster()
+	moderation.SetBroadcaster(broadcaster)
+	reputation := p2p.NewReputationSystem(log)
+	moderation.SetReputation(reputation)
+	audit := service.NewAuditService(badger.NewAuditRepo(env.DB), log)
+	moderation.SetAuditLog(audit)
+	signer := auth.NewArticleSigner()
+	appeals := service.NewAppealService(badger.NewAppealRepo(env.DB), env.ArticleRepo, env.UserRepo, signer, moderation, log)
+	appeals.SetAuditLog(audit)
+	h := handlers.NewModerationHandler(moderation, log)
+	h.SetAppeals(appeals)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/appeals", h.SubmitAppeal)
+	authed := engine.Group("", middleware.AuthMiddleware(env.JWTManager))
+	authed.POST("/articles/:cid/appeal", h.Appeal)
+	authed.GET("/moderation/appeals", h.ListAppeals)
+	authed.GET("/moderation/appeals/:id", h.GetAppeal)
+	authed.POST("/moderation/appeals/:id/uphold", h.UpholdAppeal)
+	authed.POST("/moderation/appeals/:id/reverse", h.ReverseAppeal)
+
+	ctx := context.Background()
+	tokens := make(map[string]string)
+	users := make(map[string]*domain.UserResponse)
+	for _, name := range []string{"author", "bystander", "mod"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		users[name] = user
+		tokens[name], _, _ = env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	}
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if user != "" {
+			req.Header.Set("Authorization", "Bearer "+tokens[user])
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) *domain.Appeal {
+		t.Helper()
+		var resp struct{ Data domain.Appeal }
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode appeal: %v", err)
+		}
+		return &resp.Data
+	}
+	moderate := func(articleID, action string) *domain.ModerationCase {
+		t.Helper()
+		moderation.Report(ctx, articleID, "reporter", "spam")
+		c, err := moderation.LatestCase(ctx, articleID)
+		if err != nil {
+			t.Fatalf("No case for %s: %v", articleID, err)
+		}
+		if c, err = moderation.ActionCase(ctx, c.ID, users["mod"].ID, &domain.CaseDecisionRequest{Action: action, Broadcast: true}); err != nil {
+			t.Fatalf("Failed to %s: %v", action, err)
+		}
+		return c
+	}
+
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: "Contested", Body: "Body of a contested article", Category: "news"}, users["author"].ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	// 1. Only moderated articles can be appealed, and only by their author
+	if w := do("author", http.MethodPost, "/articles/"+article.ID+"/appeal", `{"reason":"Nothing wrong here"}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for an unmoderated article, got %d", w.Code)
+	}
+	c := moderate(article.ID, domain.CaseActionHide)
+	if !c.Decision.Penalized || reputation.GetScore("author").Score != p2p.InitialScore+p2p.ReportPenalty {
+		t.Fatalf("Expected the author penalized, got %+v", reputation.GetScore("author"))
+	}
+	if w := do("bystander", http.MethodPost, "/articles/"+article.ID+"/appeal", `{"reason":"Not mine"}`); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for someone else's article, got %d", w.Code)
+	}
+	if w := do("author", http.MethodPost, "/articles/"+article.ID+"/appeal", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a reason, got %d", w.Code)
+	}
+
+	// 2. The author's appeal is signed with their key and queued once per case
+	w := do("author", http.MethodPost, "/articles/"+article.ID+"/appeal", `{"reason":"It is satire"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to appeal: %d %s", w.Code, w.Body.String())
+	}
+	appeal := decode(w)
+	if appeal.CaseID != c.ID || appeal.Status != domain.AppealOpen || signer.VerifyAppeal(appeal, article.AuthorPubKey) != nil {
+		t.Errorf("Expected an open appeal of the case signed by the author, got %+v", appeal)
+	}
+	if w := do("author", http.MethodPost, "/articles/"+article.ID+"/appeal", `{"reason":"Again"}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a second appeal, got %d", w.Code)
+	}
+	w = do("mod", http.MethodGet, "/moderation/appeals", "")
+	var queue struct{ Data []domain.Appeal }
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	if len(queue.Data) != 1 || queue.Data[0].ID != appeal.ID {
+		t.Errorf("Expected the appeal in the queue, got %+v", queue.Data)
+	}
+
+	// 3. Reversing restores the article, the reputation and tells peers
+	w = do("mod", http.MethodPost, "/moderation/appeals/"+appeal.ID+"/reverse", `{"note":"Satire is allowed"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to reverse: %d %s", w.Code, w.Body.String())
+	}
+	if decided := decode(w); decided.Status != domain.AppealReversed || decided.Decision == nil || decided.Decision.Moderator != users["mod"].ID {
+		t.Errorf("Expected the appeal reversed by mod, got %+v", decided)
+	}
+	if _, err := env.ArticleService.GetByID(ctx, article.ID); err != nil {
+		t.Errorf("Expected the article served again, got %v", err)
+	}
+	if c, _ = moderation.GetCase(ctx, c.ID); c.Status != domain.CaseReversed || c.Reversal == nil || !c.Reversal.Broadcast {
+		t.Errorf("Expected the case reversed and broadcast, got %+v", c)
+	}
+	if score := reputation.GetScore("author"); score.Score != p2p.InitialScore || score.ReportCount != 0 {
+		t.Errorf("Expected the penalty lifted, got %+v", score)
+	}
+	if actions := broadcaster.Actions(); len(actions) != 2 || actions[1].Action != domain.ModerationDismiss {
+		t.Errorf("Expected the hide followed by a dismiss broadcast, got %+v", actions)
+	}
+	if w := do("mod", http.MethodPost, "/moderation/appeals/"+appeal.ID+"/uphold", ""); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a decided appeal, got %d", w.Code)
+	}
+	for _, action := range []string{domain.AuditAppealFile, domain.AuditModerationReverse, domain.AuditAppealReverse} {
+		if entries, _, _ := audit.List(ctx, &domain.AuditListFilter{Action: action}); len(entries) != 1 || entries[0].Target != article.ID {
+			t.Errorf("Expected one %s entry for the article, got %d", action, len(entries))
+		}
+	}
+
+	// 4. Authors on other nodes appeal with a signature by the article's key
+	writer, err := remote.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "writer", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	peerArticle, err := remote.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: "From afar", Body: "Body from another node", Category: "news"}, writer.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	if err := env.ArticleService.HandleIncomingArticle(peerArticle); err != nil {
+		t.Fatalf("Failed to receive article: %v", err)
+	}
+	moderate(peerArticle.ID, domain.CaseActionUnlist)
+
+	stored, _ := remote.UserRepo.GetByUsername(ctx, "writer")
+	key, err := crypto.DecryptPrivateKey(stored.PrivateKey, stored.PasswordHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := &domain.Appeal{ArticleID: peerArticle.ID, Author: "writer", Reason: "Off topic is not spam", Timestamp: time.Now().Unix()}
+	forged := *signed
+	signer.SignAppeal(signed, key)
+	impostor, _ := crypto.GenerateKeyPair()
+	signer.SignAppeal(&forged, impostor.PrivateKey)
+	body := func(a *domain.Appeal) string {
+		return fmt.Sprintf(`{"article_id":%q,"author":%q,"reason":%q,"timestamp":%d,"signature":%q}`, a.ArticleID, a.Author, a.Reason, a.Timestamp, a.Signature)
+	}
+	if w := do("", http.MethodPost, "/appeals", body(&forged)); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a forged appeal, got %d", w.Code)
+	}
+	w = do("", http.MethodPost, "/appeals", body(signed))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to submit a signed appeal: %d %s", w.Code, w.Body.String())
+	}
+	appeal = decode(w)
+
+	// 5. Upholding leaves the decision and the penalty in place
+	w = do("mod", http.MethodPost, "/moderation/appeals/"+appeal.ID+"/uphold", `{"note":"It is spam"}`)
+	if w.Code != http.StatusOK || decode(w).Status != domain.AppealUpheld {
+		t.Fatalf("Failed to uphold: %d %s", w.Code, w.Body.String())
+	}
+	if stored, _ := env.ArticleRepo.GetByID(ctx, peerArticle.ID); stored.Visibility != domain.VisibilityUnlisted {
+		t.Errorf("Expected the article to stay unlisted, got %q", stored.Visibility)
+	}
+	if score := reputation.GetScore("writer"); score.ReportCount != 1 {
+		t.Errorf("Expected the penalty to stand, got %+v", score)
+	}
+	if entries, _, _ := audit.List(ctx, &domain.AuditListFilter{Action: domain.AuditAppealFile, Actor: "writer"}); len(entries) != 1 {
+		t.Errorf("Expected the remote author recorded as filing the appeal, got %d", len(entries))
+	}
+	w = do("mod", http.MethodGet, "/moderation/appeals?status=all", "")
+	queue.Data = nil
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	if len(queue.Data) != 2 {
+		t.Errorf("Expected both appeals listed, got %d", len(queue.Data))
+	}
+	if w := do("mod", http.MethodGet, "/moderation/appeals", ""); w.Code != http.StatusOK || bytes.Contains(w.Body.Bytes(), []byte(appeal.ID)) {
+		t.Errorf("Expected no open appeals left, got %s", w.Body.String())
+	}
+}
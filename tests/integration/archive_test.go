@@ -0,0 +1,144 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestArchiveExportImport(t *testing.T) {
+	source := SetupTestEnv(t)
+	defer source.Cleanup()
+	target := SetupTestEnv(t)
+	defer target.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	user, err := source.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "erin",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	image := "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"
+	source.IPFS.Storage[image] = []byte("fake image bytes")
+
+	article, err := source.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Archived",
+		Body:     "Portable content ![photo](https://ipfs.io/ipfs/" + image + ")",
+		Category: "technology",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	exporter := service.NewArchiveService(
+		source.ArticleRepo,
+		mocks.NewMockArchiveStore(source.IPFS),
+		source.ArticleService,
+		log,
+	)
+
+	// 1. Empty selections are rejected
+	if _, _, err := exporter.Prepare(ctx, service.ArchiveSelection{Author: "nobody"}); err != service.ErrArchiveEmpty {
+		t.Fatalf("Expected ErrArchiveEmpty, got %v", err)
+	}
+
+	// 2. Export lists the article and its attachment
+	root, manifest, err := exporter.Prepare(ctx, service.ArchiveSelection{IDs: []string{article.ID}})
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if len(manifest.Articles) != 1 || manifest.Articles[0].CID != article.CID {
+		t.Fatalf("Unexpected manifest: %+v", manifest.Articles)
+	}
+	if got := manifest.Articles[0].Attachments; len(got) != 1 || got[0] != image {
+		t.Fatalf("Expected attachment %s, got %v", image, got)
+	}
+
+	var car bytes.Buffer
+	if err := exporter.WriteCAR(ctx, root, &car); err != nil {
+		t.Fatalf("WriteCAR failed: %v", err)
+	}
+
+	// 3. Importing on another node verifies and stores the article
+	targetStore := mocks.NewMockArchiveStore(target.IPFS)
+	importer := service.NewArchiveService(target.ArticleRepo, targetStore, target.ArticleService, log)
+
+	report, err := importer.Import(ctx, bytes.NewReader(car.Bytes()))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(report.Imported) != 1 || len(report.Failed) != 0 {
+		t.Fatalf("Expected 1 imported article, got %+v", report)
+	}
+
+	imported, err := target.ArticleService.GetByCID(ctx, article.CID)
+	if err != nil {
+		t.Fatalf("Imported article not stored: %v", err)
+	}
+	if imported.ID != article.ID || imported.Signature != article.Signature {
+		t.Errorf("Imported article differs from the original")
+	}
+	if _, err := targetStore.Cat(ctx, image); err != nil {
+		t.Errorf("Attachment not imported: %v", err)
+	}
+
+	// 4. Re-importing is a no-op
+	report, err = importer.Import(ctx, bytes.NewReader(car.Bytes()))
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if len(report.Imported) != 0 || len(report.Skipped) != 1 {
+		t.Errorf("Expected article to be skipped, got %+v", report)
+	}
+
+	// 5. Selecting by ID doesn't export what listings hide
+	article.Visibility = domain.VisibilityHidden
+	if err := source.ArticleRepo.Update(ctx, article); err != nil {
+		t.Fatalf("Failed to hide article: %v", err)
+	}
+	if _, _, err := exporter.Prepare(ctx, service.ArchiveSelection{IDs: []string{article.ID}}); err != service.ErrArchiveEmpty {
+		t.Errorf("Expected ErrArchiveEmpty for a hidden article, got %v", err)
+	}
+
+	// 6. A manifest that lists another CID for an article than the archive
+	// holds is refused, so the article isn't stored under a false CID
+	var tampered struct {
+		Roots  []string                     `json:"roots"`
+		Blocks map[string][]byte            `json:"blocks"`
+		Dirs   map[string]map[string]string `json:"dirs"`
+	}
+	if err := json.Unmarshal(car.Bytes(), &tampered); err != nil {
+		t.Fatalf("Failed to decode archive: %v", err)
+	}
+	manifestCID := tampered.Dirs[root][domain.ArchiveManifestPath]
+	manifest.Articles[0].CID = image
+	tampered.Blocks[manifestCID], _ = json.Marshal(manifest)
+	tamperedCAR, _ := json.Marshal(tampered)
+
+	third := SetupTestEnv(t)
+	defer third.Cleanup()
+	victim := service.NewArchiveService(third.ArticleRepo, mocks.NewMockArchiveStore(third.IPFS), third.ArticleService, log)
+	report, err = victim.Import(ctx, bytes.NewReader(tamperedCAR))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(report.Imported) != 0 || len(report.Failed) != 1 || len(report.Warnings) != 1 ||
+		!strings.Contains(report.Warnings[0], domain.ErrCIDMismatch.Error()) {
+		t.Errorf("Expected the tampered entry to fail with a CID mismatch, got %+v", report)
+	}
+	if _, err := third.ArticleRepo.GetByID(ctx, article.ID); err != domain.ErrArticleNotFound {
+		t.Errorf("Expected the tampered article not to be stored, got %v", err)
+	}
+}
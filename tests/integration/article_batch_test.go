@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestBatchArticleCreate(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	searchService := service.NewSearchService(setupSearchIndex(t), env.ArticleRepo, 2, log)
+	articleService := service.NewArticleService(env.ArticleRepo, env.UserRepo, env.IPFS, nil, auth.NewArticleSigner(), searchService, log)
+
+	h := handlers.NewArticleHandler(articleService, log)
+	h.SetMaxBatchSize(3)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/articles/batch", middleware.AuthMiddleware(env.JWTManager), h.CreateBatch)
+
+	ctx := context.Background()
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "grace", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	token, _, _ := env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/articles/batch", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	// 1. Empty and oversized batches are rejected outright
+	if w := post(`{"articles":[]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an empty batch, got %d", w.Code)
+	}
+	items := make([]string, 4)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"title":"Item %d","body":"Imported."}`, i)
+	}
+	if w := post(`{"articles":[` + strings.Join(items, ",") + `]}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 above the batch limit, got %d", w.Code)
+	}
+
+	// 2. Invalid items fail on their own and valid ones are created
+	w := post(`{"articles":[
+		{"title":"Imported from RSS","body":"First feed item.","tags":["rss"]},
+		{"title":"","body":"No title."},
+		{"title":"Second import","body":"Another feed item.","tags":["rss"]}
+	]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Batch failed: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Created int                         `json:"created"`
+			Failed  int                         `json:"failed"`
+			Results []domain.ArticleBatchResult `json:"results"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Data.Created != 2 || resp.Data.Failed != 1 || len(resp.Data.Results) != 3 {
+		t.Fatalf("Expected 2 created and 1 failed, got %+v", resp.Data)
+	}
+	for i, result := range resp.Data.Results {
+		if result.Index != i {
+			t.Errorf("Result %d reports index %d", i, result.Index)
+		}
+		if (i == 1) != (result.Article == nil) || (i == 1) != (result.Error != "") {
+			t.Errorf("Unexpected result %d: %+v", i, result)
+		}
+	}
+
+	// 3. Created items are stored, signed and searchable
+	for _, i := range []int{0, 2} {
+		created := resp.Data.Results[i].Article
+		stored, err := env.ArticleRepo.GetByCID(ctx, created.CID)
+		if err != nil {
+			t.Fatalf("Item %d not stored: %v", i, err)
+		}
+		if stored.Signature == "" || stored.Author != "grace" {
+			t.Errorf("Item %d stored unsigned or with the wrong author: %+v", i, stored)
+		}
+	}
+	result, err := searchService.Search(ctx, &search.SearchQuery{Query: "feed", Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if result.Total != 2 {
+		t.Errorf("Expected both imported articles in the index, got %d", result.Total)
+	}
+}
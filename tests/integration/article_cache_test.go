@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+)
+
+func TestCachedArticleRepo(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+
+	cached, err := repository.NewCachedArticleRepo(env.ArticleRepo, 16)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	article := &domain.Article{
+		ID:     "cache-1",
+		CID:    "cid-1",
+		Title:  "Original",
+		Body:   "Body",
+		Author: "carol",
+	}
+	if err := cached.Create(ctx, article); err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	// 1. Warm the cache and make sure callers get private copies
+	first, err := cached.GetByCID(ctx, "cid-1")
+	if err != nil {
+		t.Fatalf("Failed to get article by CID: %v", err)
+	}
+	first.Title = "Mutated by caller"
+
+	second, err := cached.GetByID(ctx, "cache-1")
+	if err != nil {
+		t.Fatalf("Failed to get article by ID: %v", err)
+	}
+	if second.Title != "Original" {
+		t.Errorf("Cached article was mutated by caller, got title %q", second.Title)
+	}
+
+	// 2. Writes through the decorator invalidate cached entries
+	second.Title = "Updated"
+	if err := cached.Update(ctx, second); err != nil {
+		t.Fatalf("Failed to update article: %v", err)
+	}
+	updated, err := cached.GetByCID(ctx, "cid-1")
+	if err != nil {
+		t.Fatalf("Failed to get updated article: %v", err)
+	}
+	if updated.Title != "Updated" {
+		t.Errorf("Expected updated title, got %q", updated.Title)
+	}
+
+	// 3. Recent lists pick up new articles
+	recent, err := cached.ListRecent(ctx, 10)
+	if err != nil {
+		t.Fatalf("Failed to list recent: %v", err)
+	}
+	if len(recent) != 1 {
+		t.Fatalf("Expected 1 recent article, got %d", len(recent))
+	}
+	if err := cached.Create(ctx, &domain.Article{ID: "cache-2", CID: "cid-2", Title: "Second", Body: "Body", Author: "carol"}); err != nil {
+		t.Fatalf("Failed to create second article: %v", err)
+	}
+	recent, _ = cached.ListRecent(ctx, 10)
+	if len(recent) != 2 {
+		t.Errorf("Expected 2 recent articles after create, got %d", len(recent))
+	}
+
+	// 4. Deletes are not served from cache
+	if err := cached.Delete(ctx, "cache-1"); err != nil {
+		t.Fatalf("Failed to delete article: %v", err)
+	}
+	if _, err := cached.GetByCID(ctx, "cid-1"); err != domain.ErrArticleNotFound {
+		t.Errorf("Expected ErrArticleNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package integration
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// publishBlocklist signs list with key and points the name at it
+func (p *policyPublisher) publishBlocklist(t *testing.T, list domain.Blocklist, key ed25519.PrivateKey) {
+	t.Helper()
+	content, err := list.SignableContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Signature, err = crypto.Sign(content, key); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.current = fmt.Sprintf("blocklist-%d", len(p.docs))
+	p.docs[p.current] = data
+}
+
+type disconnectRecorder struct {
+	closed []string
+}
+
+func (d *disconnectRecorder) ClosePeer(id string) error {
+	d.closed = append(d.closed, id)
+	return nil
+}
+
+func newPeerID(t *testing.T) peer.ID {
+	t.Helper()
+	_, pub, err := libp2pcrypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestBlocklists(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+	author := SetupTestEnv(t)
+	defer author.Cleanup()
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	curator, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	impostor, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ipns := &policyPublisher{docs: make(map[string][]byte)}
+	blocklists := service.NewBlocklistService(badger.NewBlocklistRepo(env.DB), ipns, ipns, log)
+	disconnects := &disconnectRecorder{}
+	blocklists.SetDisconnector(disconnects)
+	env.ArticleService.SetBlocklist(blocklists)
+
+	gater := p2p.NewGater()
+	gater.SetBlocker(blocklists)
+	badPeer, goodPeer := newPeerID(t), newPeerID(t)
+
+	spammer, err := author.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "spammer", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	writer, err := author.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "writer", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	publish := func(userID, title string) *domain.Article {
+		article, err := author.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: title, Body: "Body of " + title, Category: "news"}, userID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		return article
+	}
+	spam := publish(spammer.ID, "Spam")
+	pirated := publish(writer.ID, "Pirated")
+	fine := publish(writer.ID, "Fine")
+
+	// 1. A subscription needs a valid name, source and curator key
+	if _, err := blocklists.Subscribe(ctx, "Bad Name", &domain.BlocklistRequest{Source: "k51-list", Curator: crypto.DIDKey(curator.PublicKey)}); err == nil {
+		t.Error("Expected an invalid name to be rejected")
+	}
+	if _, err := blocklists.Subscribe(ctx, "community", &domain.BlocklistRequest{Source: "k51-list", Curator: "not-a-key"}); err == nil {
+		t.Error("Expected an invalid curator to be rejected")
+	}
+
+	// 2. An edition signed by someone else is refused
+	ipns.publishBlocklist(t, domain.Blocklist{Version: 1, PeerIDs: []string{badPeer.String()}}, impostor.PrivateKey)
+	sub, err := blocklists.Subscribe(ctx, "community", &domain.BlocklistRequest{Source: "k51-list", Curator: crypto.DIDKey(curator.PublicKey)})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if sub.List != nil || sub.LastError != domain.ErrBlocklistSignature.Error() {
+		t.Errorf("Expected the forged edition recorded as an error, got %+v", sub)
+	}
+	if blocklists.PeerBlocked(badPeer.String()) {
+		t.Error("A forged blocklist was applied")
+	}
+
+	// 3. The curator's edition blocks peers, authors (by key or DID) and CIDs
+	ipns.publishBlocklist(t, domain.Blocklist{
+		Version:  2,
+		IssuedAt: time.Now().UTC(),
+		DIDs:     []string{spammer.PublicKey},
+		PeerIDs:  []string{badPeer.String()},
+		CIDs:     []string{pirated.CID},
+	}, curator.PrivateKey)
+	if err := blocklists.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if sub, err = blocklists.Get(ctx, "community"); err != nil || sub.List == nil || sub.List.Version != 2 || sub.LastError != "" || sub.FetchedAt == nil {
+		t.Fatalf("Expected version 2 in force, got %+v (%v)", sub, err)
+	}
+	if !blocklists.PeerBlocked(badPeer.String()) || blocklists.PeerBlocked(goodPeer.String()) {
+		t.Error("Expected only the listed peer blocked")
+	}
+	if len(disconnects.closed) != 1 || disconnects.closed[0] != badPeer.String() {
+		t.Errorf("Expected the listed peer disconnected, got %v", disconnects.closed)
+	}
+	if gater.InterceptPeerDial(badPeer) || !gater.InterceptPeerDial(goodPeer) {
+		t.Error("Expected the gater to refuse only the listed peer")
+	}
+
+	if err := env.ArticleService.HandleIncomingArticle(spam); !errors.Is(err, domain.ErrArticleBlocked) {
+		t.Errorf("Expected the listed author refused, got %v", err)
+	}
+	if err := env.ArticleService.HandleIncomingArticle(pirated); !errors.Is(err, domain.ErrArticleBlocked) {
+		t.Errorf("Expected the listed CID refused, got %v", err)
+	}
+	if env.ArticleService.HasArticle(ctx, spam.ID) || env.ArticleService.HasArticle(ctx, pirated.ID) {
+		t.Error("Expected blocked articles not to be stored")
+	}
+	if err := env.ArticleService.HandleIncomingArticle(fine); err != nil {
+		t.Errorf("Failed to accept an unlisted article: %v", err)
+	}
+
+	// 4. An older edition is refused and the one in force stays
+	ipns.publishBlocklist(t, domain.Blocklist{Version: 1}, curator.PrivateKey)
+	if err := blocklists.Refresh(ctx); !errors.Is(err, domain.ErrBlocklistOutdated) {
+		t.Errorf("Expected ErrBlocklistOutdated, got %v", err)
+	}
+	if !blocklists.PeerBlocked(badPeer.String()) {
+		t.Error("Expected the edition in force to stay after a rollback")
+	}
+
+	// 5. The edition in force applies after a restart, before any fetch
+	restarted := service.NewBlocklistService(badger.NewBlocklistRepo(env.DB), ipns, ipns, log)
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if !restarted.PeerBlocked(badPeer.String()) {
+		t.Error("Expected the stored edition applied on load")
+	}
+	if _, blocked := restarted.ArticleBlocked(spam); !blocked {
+		t.Error("Expected the stored edition to block the listed author")
+	}
+
+	// 6. Unsubscribing stops blocking
+	if err := blocklists.Unsubscribe(ctx, "community"); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if err := blocklists.Unsubscribe(ctx, "community"); !errors.Is(err, domain.ErrBlocklistNotFound) {
+		t.Errorf("Expected ErrBlocklistNotFound, got %v", err)
+	}
+	if blocklists.PeerBlocked(badPeer.String()) || !gater.InterceptPeerDial(badPeer) {
+		t.Error("Expected the peer allowed after unsubscribing")
+	}
+	if err := env.ArticleService.HandleIncomingArticle(spam); err != nil {
+		t.Errorf("Expected the author's article accepted after unsubscribing, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestCircles(t *testing.T) {
+	owner := SetupTestEnv(t)
+	defer owner.Cleanup()
+	member := SetupTestEnv(t)
+	defer member.Cleanup()
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	broadcaster := mocks.NewMockCircleBroadcaster()
+	circles := service.NewCircleService(badger.NewCircleRepo(owner.DB), owner.UserRepo, owner.ArticleRepo, auth.NewArticleSigner(), log)
+	circles.SetBroadcaster(broadcaster)
+	circles.SetReceiver(owner.ArticleService)
+	owner.ArticleService.SetCircles(circles)
+
+	remote := service.NewCircleService(badger.NewCircleRepo(member.DB), member.UserRepo, member.ArticleRepo, auth.NewArticleSigner(), log)
+	remote.SetReceiver(member.ArticleService)
+	member.ArticleService.SetCircles(remote)
+
+	register := func(env *TestEnv, name string) string {
+		t.Helper()
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register: %v", err)
+		}
+		return user.ID
+	}
+	alice, bob, dave := register(owner, "alice"), register(owner, "bob"), register(owner, "dave")
+	carol := register(member, "carol")
+
+	// 1. The owner adds a local user by name and a remote one by key card
+	card, err := remote.KeyCard(ctx, carol)
+	if err != nil {
+		t.Fatalf("Failed to get key card: %v", err)
+	}
+	forged := *card
+	forged.Username = "mallory"
+	if _, err := circles.Create(ctx, alice, &domain.CircleCreateRequest{Name: "Forged", Members: []domain.CircleMemberRequest{{Card: &forged}}}); err == nil {
+		t.Error("Expected a tampered key card to be rejected")
+	}
+	circle, err := circles.Create(ctx, alice, &domain.CircleCreateRequest{
+		Name:    "Editors",
+		Members: []domain.CircleMemberRequest{{Username: "bob"}, {Card: card}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create circle: %v", err)
+	}
+	if circle.Epoch != 1 || len(circle.Members) != 3 {
+		t.Errorf("Expected the first epoch with 3 members, got %+v", circle)
+	}
+
+	// 2. The key message reaches the member's node; tampering is refused
+	waitFor(t, "circle keys", func() bool { return len(broadcaster.Keys()) == 1 })
+	keys := broadcaster.Keys()[0]
+	tampered := keys
+	tampered.Name = "Hijacked"
+	if err := remote.HandleIncomingKeys(ctx, &tampered); err == nil {
+		t.Error("Expected tampered circle keys to be rejected")
+	}
+	if err := remote.HandleIncomingKeys(ctx, &keys); err != nil {
+		t.Fatalf("Failed to handle circle keys: %v", err)
+	}
+	if mine, _ := remote.List(ctx, carol); len(mine) != 1 || mine[0].ID != circle.ID {
+		t.Errorf("Expected carol to see the circle, got %v", mine)
+	}
+
+	// 3. Only members publish; IPFS holds the sealed copy, not the article
+	publish := func(userID, title string) (*domain.Article, error) {
+		return owner.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: title, Body: "Secret body of " + title, Circle: circle.ID}, userID, "127.0.0.1")
+	}
+	if _, err := publish(dave, "Intruder"); !errors.Is(err, domain.ErrNotCircleMember) {
+		t.Errorf("Expected a non-member to be refused, got %v", err)
+	}
+	article, err := publish(alice, "Embargoed")
+	if err != nil {
+		t.Fatalf("Failed to publish to circle: %v", err)
+	}
+	if data := string(owner.IPFS.Storage[article.CID]); data == "" || strings.Contains(data, "Secret body") || strings.Contains(data, "Embargoed") {
+		t.Errorf("Expected only ciphertext on IPFS, got %q", data)
+	}
+
+	// 4. The article stays out of public reads and listings
+	if _, err := owner.ArticleService.GetByID(ctx, article.ID); !errors.Is(err, domain.ErrArticleNotFound) {
+		t.Errorf("Expected the circle article hidden from public reads, got %v", err)
+	}
+	if _, err := owner.ArticleService.GetByCID(ctx, article.CID); !errors.Is(err, domain.ErrArticleNotFound) {
+		t.Errorf("Expected the circle article hidden by CID, got %v", err)
+	}
+	if listed, _, _ := owner.ArticleService.List(ctx, &domain.ArticleListFilter{Page: 1, Limit: 10}); len(listed) != 0 {
+		t.Errorf("Expected no public articles, got %d", len(listed))
+	}
+	if read, _, err := circles.Articles(ctx, circle.ID, bob, 1, 10); err != nil || len(read) != 1 || read[0].Body != article.Body {
+		t.Errorf("Expected bob to read the article, got %v, %v", read, err)
+	}
+	if _, _, err := circles.Articles(ctx, circle.ID, dave, 1, 10); !errors.Is(err, domain.ErrCircleNotFound) {
+		t.Errorf("Expected the circle hidden from non-members, got %v", err)
+	}
+
+	// 5. The member's node opens the sealed copy
+	waitFor(t, "sealed article", func() bool { return len(broadcaster.Articles()) == 1 })
+	shared := broadcaster.Articles()[0]
+	if shared.CID != article.CID {
+		t.Errorf("Expected the sealed copy shared under %s, got %s", article.CID, shared.CID)
+	}
+	if err := remote.HandleIncomingSealed(ctx, shared.CID, &shared.Sealed); err != nil {
+		t.Fatalf("Failed to handle sealed article: %v", err)
+	}
+	if read, _, err := remote.Articles(ctx, circle.ID, carol, 1, 10); err != nil || len(read) != 1 || read[0].Body != article.Body {
+		t.Errorf("Expected carol to read the article, got %v, %v", read, err)
+	}
+	if _, err := member.ArticleService.GetByID(ctx, article.ID); !errors.Is(err, domain.ErrArticleNotFound) {
+		t.Errorf("Expected the opened article hidden from public reads, got %v", err)
+	}
+
+	// 6. Only the owner changes membership; removal rotates the key
+	if _, err := circles.RemoveMember(ctx, circle.ID, bob, "carol"); !errors.Is(err, domain.ErrNotCircleOwner) {
+		t.Errorf("Expected a member to be refused, got %v", err)
+	}
+	if _, err := circles.AddMember(ctx, circle.ID, alice, &domain.CircleMemberRequest{Username: "bob"}); !errors.Is(err, domain.ErrCircleMemberExists) {
+		t.Errorf("Expected a duplicate member to be refused, got %v", err)
+	}
+	rotated, err := circles.RemoveMember(ctx, circle.ID, alice, "carol")
+	if err != nil {
+		t.Fatalf("Failed to remove member: %v", err)
+	}
+	if rotated.Epoch != 2 || len(rotated.Members) != 2 {
+		t.Errorf("Expected the second epoch with 2 members, got %+v", rotated)
+	}
+	waitFor(t, "rotated circle keys", func() bool { return len(broadcaster.Keys()) == 2 })
+	next := broadcaster.Keys()[1]
+	if _, ok := next.Grant(keys.Members[2].PublicKey); ok {
+		t.Error("Expected no key granted to the removed member")
+	}
+	if err := remote.HandleIncomingKeys(ctx, &next); err != nil {
+		t.Fatalf("Failed to handle rotated keys: %v", err)
+	}
+	if _, err := remote.Get(ctx, circle.ID, carol); !errors.Is(err, domain.ErrCircleNotFound) {
+		t.Errorf("Expected the removed member to lose the circle, got %v", err)
+	}
+
+	// 7. The removed member can't open what is published after
+	later, err := publish(bob, "After")
+	if err != nil {
+		t.Fatalf("Failed to publish to circle: %v", err)
+	}
+	waitFor(t, "second sealed article", func() bool { return len(broadcaster.Articles()) == 2 })
+	shared = broadcaster.Articles()[1]
+	if shared.Sealed.Epoch != 2 {
+		t.Errorf("Expected the article sealed for the second epoch, got %d", shared.Sealed.Epoch)
+	}
+	if err := remote.HandleIncomingSealed(ctx, shared.CID, &shared.Sealed); err == nil {
+		t.Error("Expected the removed member's node to fail to open the article")
+	}
+	if member.ArticleService.HasArticle(ctx, later.ID) {
+		t.Error("Expected the later article not stored on the removed member's node")
+	}
+}
+
+func TestCirclesStayOutOfOpLog(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	signer := auth.NewArticleSigner()
+
+	// In distributed mode every local write is replicated as an op
+	publisher := &capturePublisher{}
+	repo := badger.NewDistributedArticleRepo(env.DB, publisher, signer, "node-a", log)
+	articles := service.NewArticleService(repo, env.UserRepo, env.IPFS, nil, signer, nil, log)
+	circles := service.NewCircleService(badger.NewCircleRepo(env.DB), env.UserRepo, repo, signer, log)
+	circles.SetBroadcaster(mocks.NewMockCircleBroadcaster())
+	articles.SetCircles(circles)
+
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "alice", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	circle, err := circles.Create(ctx, user.ID, &domain.CircleCreateRequest{Name: "Editors"})
+	if err != nil {
+		t.Fatalf("Failed to create circle: %v", err)
+	}
+
+	// 1. Public articles are replicated, circle articles aren't
+	public, err := articles.Create(ctx, &domain.ArticleCreateRequest{Title: "Public", Body: "For everyone."}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	secret, err := articles.Create(ctx, &domain.ArticleCreateRequest{Title: "Embargoed", Body: "Secret body.", Circle: circle.ID}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to publish to circle: %v", err)
+	}
+	if len(publisher.ops) != 1 || publisher.ops[0].ArticleID != public.ID {
+		t.Fatalf("Expected only the public article replicated, got %d ops", len(publisher.ops))
+	}
+
+	// 2. Neither are its edits or its deletion
+	if _, err := articles.Update(ctx, secret.ID, &domain.ArticleUpdateRequest{Body: "Revised secret body."}, user.ID); err != nil {
+		t.Fatalf("Failed to update circle article: %v", err)
+	}
+	if err := articles.Delete(ctx, secret.ID, user.ID); err != nil {
+		t.Fatalf("Failed to delete circle article: %v", err)
+	}
+	for _, op := range publisher.ops {
+		if op.ArticleID == secret.ID {
+			t.Errorf("Expected no %s op for the circle article", op.Type)
+		}
+	}
+}
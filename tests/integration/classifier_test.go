@@ -0,0 +1,227 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/classifier"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	newsp2pv1 "github.com/amiyamandal-dev/newsp2p/proto/newsp2p/v1"
+)
+
+// scoreArticle is a stand-in model: anything mentioning "explicit" is nsfw
+func scoreArticle(title, body string) []domain.Classification {
+	if strings.Contains(strings.ToLower(title+" "+body), "explicit") {
+		return []domain.Classification{{Label: "nsfw", Score: 0.92}, {Label: "toxic", Score: 0.2}}
+	}
+	return []domain.Classification{{Label: "nsfw", Score: 0.03}}
+}
+
+func TestContentClassifier(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+	author := SetupTestEnv(t)
+	defer author.Cleanup()
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	failing := false
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "model unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var req classifier.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(classifier.Response{Classifications: scoreArticle(req.Title, req.Body)})
+	}))
+	defer model.Close()
+
+	contentClassifier, err := classifier.New(model.URL, time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create classifier: %v", err)
+	}
+	env.ArticleService.SetClassifier(contentClassifier, false)
+
+	filters := service.NewFilterService(badger.NewFilterListRepo(env.DB), log)
+	moderation := service.NewModerationService(badger.NewReportRepo(env.DB), badger.NewModerationCaseRepo(env.DB), env.ArticleRepo, log)
+	env.ArticleService.SetContentFilter(filters, moderation)
+	if _, err := filters.Put(ctx, "adult", &domain.FilterListRequest{Action: domain.FilterQuarantine, Classifications: []string{"nsfw"}, MinScore: 0.8}); err != nil {
+		t.Fatalf("Failed to save list: %v", err)
+	}
+	if _, err := filters.Put(ctx, "bad-score", &domain.FilterListRequest{Action: domain.FilterTag, Classifications: []string{"nsfw"}, MinScore: 2}); err == nil {
+		t.Error("Expected a min_score above 1 to be rejected")
+	}
+
+	user, err := author.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "remote", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	publish := func(title, body string) *domain.Article {
+		article, err := author.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: title, Body: body, Category: "news"}, user.ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		// A peer's own classifications are never trusted
+		article.Classifications = []domain.Classification{{Label: "safe", Score: 1}}
+		return article
+	}
+
+	// 1. Articles from peers are classified before they are stored
+	clean := publish("Harbour reopens", "Ferries are running again.")
+	if err := env.ArticleService.HandleIncomingArticle(clean); err != nil {
+		t.Fatalf("Failed to receive article: %v", err)
+	}
+	stored, err := env.ArticleRepo.GetByID(ctx, clean.ID)
+	if err != nil {
+		t.Fatalf("Failed to load article: %v", err)
+	}
+	if len(stored.Classifications) != 1 || stored.Classifications[0].Label != "nsfw" || stored.Visibility != "" {
+		t.Errorf("Expected the classifier's scores only, got %+v (%q)", stored.Classifications, stored.Visibility)
+	}
+	if data, _ := stored.ToJSON(); strings.Contains(string(data), "classifications") {
+		t.Error("Expected classifications to stay out of the published JSON")
+	}
+
+	// 2. Filter lists act on confident classifications
+	explicit := publish("Explicit photos leaked", "Graphic material.")
+	if err := env.ArticleService.HandleIncomingArticle(explicit); err != nil {
+		t.Fatalf("Failed to receive article: %v", err)
+	}
+	held, _ := env.ArticleRepo.GetByID(ctx, explicit.ID)
+	if held == nil || held.Visibility != domain.VisibilityQuarantined {
+		t.Fatalf("Expected the nsfw article quarantined, got %+v", held)
+	}
+	cases, _, _ := moderation.ListCases(ctx, &domain.CaseListFilter{})
+	if len(cases) != 1 || cases[0].ArticleID != explicit.ID {
+		t.Fatalf("Expected a moderation case for the nsfw article, got %+v", cases)
+	}
+	verdict := filters.Check(&domain.FilterCheckRequest{Title: "Draft", Classifications: []domain.Classification{{Label: "nsfw", Score: 0.7}}})
+	if len(verdict.Matches) != 0 {
+		t.Errorf("Expected a score below min_score not to match, got %+v", verdict.Matches)
+	}
+
+	// 3. Confident classifications are searchable labels, here once a
+	// moderator releases the article
+	if _, err := moderation.DismissCase(ctx, cases[0].ID, "mod", &domain.CaseDecisionRequest{}); err != nil {
+		t.Fatalf("Failed to dismiss case: %v", err)
+	}
+	released, _ := env.ArticleRepo.GetByID(ctx, explicit.ID)
+	if released == nil || released.Visibility != "" || len(released.Classifications) != 2 {
+		t.Fatalf("Expected the released article to keep its classifications, got %+v", released)
+	}
+	index := setupSearchIndex(t, stored, released)
+	searchService := service.NewSearchService(index, env.ArticleRepo, 2, log)
+	result, err := searchService.Search(ctx, &search.SearchQuery{ExcludeLabels: []string{"nsfw"}})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Articles) != 1 || result.Articles[0].ID != clean.ID {
+		t.Errorf("Expected exclude_label to drop the nsfw article, got %v", articleIDs(result.Articles))
+	}
+	result, err = searchService.Search(ctx, &search.SearchQuery{Labels: []string{"nsfw"}})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Articles) != 1 || result.Articles[0].ID != explicit.ID {
+		t.Errorf("Expected label to keep only the nsfw article, got %v", articleIDs(result.Articles))
+	}
+	if result, _ := searchService.Search(ctx, &search.SearchQuery{Labels: []string{"toxic"}}); result.Total != 0 {
+		t.Errorf("Expected low scores not to be searchable, got %d results", result.Total)
+	}
+
+	// 4. A failing classifier stores articles unclassified, unless required
+	failing = true
+	unscored := publish("Rail strike", "Trains are cancelled.")
+	if err := env.ArticleService.HandleIncomingArticle(unscored); err != nil {
+		t.Fatalf("Expected the article stored unclassified, got %v", err)
+	}
+	if got, _ := env.ArticleRepo.GetByID(ctx, unscored.ID); got == nil || len(got.Classifications) != 0 {
+		t.Errorf("Expected no classifications, got %+v", got)
+	}
+
+	env.ArticleService.SetClassifier(contentClassifier, true)
+	refused := publish("Bridge closed", "Traffic is diverted.")
+	if err := env.ArticleService.HandleIncomingArticle(refused); err == nil {
+		t.Error("Expected the article refused while the required classifier fails")
+	}
+	if env.ArticleService.HasArticle(ctx, refused.ID) {
+		t.Error("Expected the refused article not to be stored")
+	}
+}
+
+// modelServer is a stand-in ClassifierService backed by scoreArticle
+type modelServer struct {
+	newsp2pv1.UnimplementedClassifierServiceServer
+	seen *newsp2pv1.ClassifyRequest
+}
+
+func (m *modelServer) Classify(ctx context.Context, req *newsp2pv1.ClassifyRequest) (*newsp2pv1.ClassifyResponse, error) {
+	if req.Title == "fail" {
+		return nil, status.Error(codes.InvalidArgument, "cannot classify")
+	}
+	m.seen = req
+
+	resp := &newsp2pv1.ClassifyResponse{}
+	for _, c := range scoreArticle(req.Title, req.Body) {
+		resp.Classifications = append(resp.Classifications, &newsp2pv1.Classification{Label: c.Label, Score: c.Score})
+	}
+	return resp, nil
+}
+
+func TestGRPCClassifier(t *testing.T) {
+	model := &modelServer{}
+	server := grpc.NewServer()
+	newsp2pv1.RegisterClassifierServiceServer(server, model)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go server.Serve(listener)
+	defer server.Stop()
+
+	contentClassifier, err := classifier.New("grpc://"+listener.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create classifier: %v", err)
+	}
+	ctx := context.Background()
+
+	article := &domain.Article{ID: "a1", CID: "Qm1", Title: "Explicit scenes", Body: "text", Tags: []string{"film", "review"}, Category: "culture"}
+	results, err := contentClassifier.Classify(ctx, article)
+	if err != nil {
+		t.Fatalf("Classify failed: %v", err)
+	}
+	if len(results) != 2 || results[0] != (domain.Classification{Label: "nsfw", Score: 0.92}) {
+		t.Errorf("Expected the model's scores, got %+v", results)
+	}
+	if seen := model.seen; seen == nil || seen.Id != "a1" || seen.Category != "culture" || len(seen.Tags) != 2 {
+		t.Errorf("Expected the article sent to the model, got %+v", seen)
+	}
+
+	_, err = contentClassifier.Classify(ctx, &domain.Article{ID: "a2", Title: "fail"})
+	if rpcErr, ok := status.FromError(err); !ok || rpcErr.Code() != codes.InvalidArgument || rpcErr.Message() != "cannot classify" {
+		t.Errorf("Expected the model's status, got %v", err)
+	}
+
+	if _, err := classifier.New("ftp://models.example.org", time.Second); err == nil {
+		t.Error("Expected an unsupported scheme to be rejected")
+	}
+}
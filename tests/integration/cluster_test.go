@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestClusterPinReplication(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		pins       = make(map[string]string) // cid -> raw query
+		lastAuth   string
+		lastHeader string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		user, _, _ := r.BasicAuth()
+		lastAuth = user
+		lastHeader = r.Header.Get("Authorization")
+
+		cid := strings.TrimPrefix(r.URL.Path, "/pins/")
+		switch {
+		case r.Method == http.MethodPost:
+			pins[cid] = r.URL.RawQuery
+			json.NewEncoder(w).Encode(map[string]string{"cid": cid})
+		case r.Method == http.MethodDelete:
+			if _, ok := pins[cid]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"message": "pin not found"})
+				return
+			}
+			delete(pins, cid)
+			w.WriteHeader(http.StatusAccepted)
+		case r.URL.Path == "/id":
+			json.NewEncoder(w).Encode(map[string]string{"id": "12D3KooWCluster"})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"cid": cid,
+				"peer_map": map[string]interface{}{
+					"peerA": map[string]string{"status": "pinned"},
+					"peerB": map[string]string{"status": "pinning"},
+					"peerC": map[string]string{"status": "pin_error", "error": "disk full"},
+				},
+			})
+		}
+	}))
+	defer server.Close()
+
+	log, _ := logger.New("error", "text")
+	ctx := context.Background()
+
+	cluster := ipfs.NewClusterClient(server.URL, 2, 3, 5*time.Second, log)
+	cluster.SetBasicAuth("coop", "secret")
+
+	// The local daemon is never contacted for pins once a cluster is set
+	client := ipfs.NewClient("http://127.0.0.1:1", time.Second, true, log)
+	client.SetCluster(cluster)
+
+	// 1. Pins carry the replication factor
+	if err := client.Pin(ctx, "QmArticle"); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	mu.Lock()
+	query, pinned := pins["QmArticle"]
+	auth := lastAuth
+	mu.Unlock()
+	if !pinned || !strings.Contains(query, "replication-min=2") || !strings.Contains(query, "replication-max=3") {
+		t.Errorf("Expected pin with replication factors, got %q", query)
+	}
+	if auth != "coop" {
+		t.Errorf("Expected basic auth user coop, got %q", auth)
+	}
+
+	// 2. Status counts peers by pin state
+	status, err := cluster.Status(ctx, "QmArticle")
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Pinned != 1 || status.Pending != 1 || status.Failed != 1 || status.PeerErrs["peerC"] != "disk full" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	// 3. Unpin removes it from the cluster pinset; API errors surface
+	if err := client.Unpin(ctx, "QmArticle"); err != nil {
+		t.Fatalf("Unpin failed: %v", err)
+	}
+	if err := client.Unpin(ctx, "QmArticle"); err == nil || !strings.Contains(err.Error(), "pin not found") {
+		t.Errorf("Expected cluster error for missing pin, got %v", err)
+	}
+
+	id, err := cluster.ID(ctx)
+	if err != nil || id != "12D3KooWCluster" {
+		t.Errorf("Expected cluster ID, got %q (%v)", id, err)
+	}
+
+	// 4. A token replaces basic auth
+	cluster.SetToken("a-cluster-jwt")
+	if err := client.Pin(ctx, "QmToken"); err != nil {
+		t.Fatalf("Pin with token failed: %v", err)
+	}
+	mu.Lock()
+	header := lastHeader
+	mu.Unlock()
+	if header != "Bearer a-cluster-jwt" {
+		t.Errorf("Expected the bearer token sent, got %q", header)
+	}
+}
@@ -0,0 +1,162 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestComments(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	index := setupSearchIndex(t)
+	comments := service.NewCommentService(badger.NewCommentRepo(env.DB), env.ArticleRepo, env.UserRepo, log)
+	comments.SetIndexer(index)
+	h := handlers.NewCommentHandler(comments, log)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/articles/:cid/comments", h.List)
+	engine.GET("/comments/:id", h.Get)
+	authed := engine.Group("", middleware.AuthMiddleware(env.JWTManager))
+	authed.POST("/articles/:cid/comments", h.Create)
+	authed.PUT("/comments/:id", h.Update)
+	authed.DELETE("/comments/:id", middleware.IdentifyModerators(nil, []string{"mod"}), h.Delete)
+
+	ctx := context.Background()
+	tokens := make(map[string]string)
+	users := make(map[string]*domain.UserResponse)
+	for _, name := range []string{"writer", "ann", "ben", "mod"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		users[name] = user
+		tokens[name], _, _ = env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	}
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Community gardens", Body: "New plots open in the spring.", Category: "local",
+	}, users["writer"].ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	other, _ := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Bus routes", Body: "Two routes are changing.", Category: "local",
+	}, users["writer"].ID, "127.0.0.1")
+
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if user != "" {
+			req.Header.Set("Authorization", "Bearer "+tokens[user])
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) domain.Comment {
+		var resp struct{ Data domain.Comment }
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Data
+	}
+
+	// 1. Creating requires auth, a body and a known article
+	if w := do("", http.MethodPost, "/articles/"+article.ID+"/comments", `{"body":"hi"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", w.Code)
+	}
+	if w := do("ann", http.MethodPost, "/articles/"+article.ID+"/comments", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a body, got %d", w.Code)
+	}
+	if w := do("ann", http.MethodPost, "/articles/QmMissing/comments", `{"body":"hi"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown article, got %d", w.Code)
+	}
+
+	// 2. Comments and replies, by article ID or CID
+	w := do("ann", http.MethodPost, "/articles/"+article.CID+"/comments", `{"body":"Where do I sign up for compost deliveries?"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create failed: %d %s", w.Code, w.Body.String())
+	}
+	top := decode(w)
+	if top.ArticleID != article.ID || top.Author != "ann" {
+		t.Errorf("Unexpected comment: %+v", top)
+	}
+	w = do("ben", http.MethodPost, "/articles/"+article.ID+"/comments", `{"body":"At the library desk.","parent_id":"`+top.ID+`"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Reply failed: %d %s", w.Code, w.Body.String())
+	}
+	reply := decode(w)
+	if w := do("ben", http.MethodPost, "/articles/"+other.ID+"/comments", `{"body":"wrong thread","parent_id":"`+top.ID+`"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a parent on another article, got %d", w.Code)
+	}
+	w = do("ben", http.MethodPost, "/articles/"+article.ID+"/comments", `{"body":"Great news."}`)
+	standalone := decode(w)
+
+	// 3. Listing is public, oldest first and paginated
+	w = do("", http.MethodGet, "/articles/"+article.ID+"/comments?limit=2", "")
+	var list struct {
+		Data       []domain.Comment
+		Pagination struct {
+			Total      int
+			TotalPages int `json:"total_pages"`
+		}
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || list.Pagination.Total != 3 || list.Pagination.TotalPages != 2 || len(list.Data) != 2 {
+		t.Fatalf("Unexpected listing: %d %s", w.Code, w.Body.String())
+	}
+	if list.Data[0].ID != top.ID || list.Data[1].ID != reply.ID {
+		t.Errorf("Expected comments oldest first, got %s, %s", list.Data[0].ID, list.Data[1].ID)
+	}
+
+	// 4. Only the author edits
+	if w := do("ben", http.MethodPut, "/comments/"+top.ID, `{"body":"edited by someone else"}`); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 editing another user's comment, got %d", w.Code)
+	}
+	if w := do("ann", http.MethodPut, "/comments/"+top.ID, `{"body":"Where do I sign up for compost?"}`); w.Code != http.StatusOK {
+		t.Errorf("Edit failed: %d", w.Code)
+	}
+	if got := decode(do("", http.MethodGet, "/comments/"+top.ID, "")); got.Body != "Where do I sign up for compost?" {
+		t.Errorf("Edit not saved: %+v", got)
+	}
+	result, _ := index.Search(ctx, &search.SearchQuery{Query: "compost", DocType: search.DocTypeComment, Page: 1, Limit: 10})
+	if len(result.Comments) != 1 {
+		t.Errorf("Expected the comment to be searchable, got %d hits", len(result.Comments))
+	}
+
+	// 5. Authors delete their own comments; moderators delete any, with replies
+	if w := do("ben", http.MethodDelete, "/comments/"+top.ID, ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 deleting another user's comment, got %d", w.Code)
+	}
+	if w := do("ben", http.MethodDelete, "/comments/"+standalone.ID, ""); w.Code != http.StatusOK {
+		t.Errorf("Author delete failed: %d", w.Code)
+	}
+	if w := do("mod", http.MethodDelete, "/comments/"+top.ID, ""); w.Code != http.StatusOK {
+		t.Fatalf("Moderator delete failed: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("", http.MethodGet, "/comments/"+reply.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected the reply deleted with its parent, got %d", w.Code)
+	}
+	json.Unmarshal(do("", http.MethodGet, "/articles/"+article.ID+"/comments", "").Body.Bytes(), &list)
+	if list.Pagination.Total != 0 {
+		t.Errorf("Expected no comments left, got %d", list.Pagination.Total)
+	}
+	result, _ = index.Search(ctx, &search.SearchQuery{Query: "compost", DocType: search.DocTypeComment, Page: 1, Limit: 10})
+	if len(result.Comments) != 0 {
+		t.Errorf("Expected deleted comments gone from the index, got %d hits", len(result.Comments))
+	}
+}
@@ -0,0 +1,240 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+)
+
+func TestConfigOverrides(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "node.yaml")
+	yaml := "server:\n  port: 9000\nauth:\n  jwt_secret: an-integration-test-secret-of-enough-length\nlogging:\n  level: info\np2p:\n  enabled: true\n" +
+		"data:\n  root: " + filepath.Join(dir, "a") + "\n" +
+		"database:\n  path: " + filepath.Join(dir, "a", "badger_db") + "\n"
+	if err := os.WriteFile(file, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("NEWS_SERVER_PORT", "9050")
+
+	off := false
+	cfg, err := config.LoadWithOverrides(config.Overrides{
+		ConfigFile: file,
+		Port:       9100,
+		LogLevel:   "debug",
+		P2PEnabled: &off,
+		DataRoot:   filepath.Join(dir, "b"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Server.Port != 9100 || cfg.Logging.Level != "debug" || cfg.P2P.Enabled {
+		t.Errorf("Expected flags to beat the file and environment, got port %d, level %s, p2p %v",
+			cfg.Server.Port, cfg.Logging.Level, cfg.P2P.Enabled)
+	}
+	if cfg.Data.Root != filepath.Join(dir, "b") || cfg.Database.Path != filepath.Join(dir, "b", "badger_db") {
+		t.Errorf("Expected stores to follow the data directory, got root %s, database %s", cfg.Data.Root, cfg.Database.Path)
+	}
+	if config.FileUsed() != file {
+		t.Errorf("Expected %s to be reported as the file used, got %s", file, config.FileUsed())
+	}
+
+	if _, err := config.LoadWithOverrides(config.Overrides{ConfigFile: file, LogLevel: "loud"}); err == nil {
+		t.Error("Expected an invalid --log-level to be rejected")
+	}
+}
+
+func TestConfigSecretFiles(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "jwt_secret")
+	if err := os.WriteFile(secret, []byte("a-secret-mounted-from-a-file-not-the-env\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	password := filepath.Join(dir, "cluster_password")
+	if err := os.WriteFile(password, []byte("hunter2"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	dbKey := filepath.Join(dir, "db_key")
+	if err := os.WriteFile(dbKey, []byte(strings.Repeat("ab", 32)+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	token := filepath.Join(dir, "cluster_token")
+	if err := os.WriteFile(token, []byte("a-cluster-jwt\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	port := filepath.Join(dir, "port")
+	if err := os.WriteFile(port, []byte("9999"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	t.Setenv("NEWS_AUTH_JWT_SECRET_FILE", secret)
+	t.Setenv("NEWS_IPFS_CLUSTER_PASSWORD_FILE", password)
+	t.Setenv("NEWS_DATABASE_ENCRYPTION_KEY_FILE", dbKey)
+	t.Setenv("NEWS_IPFS_CLUSTER_TOKEN_FILE", token)
+	t.Setenv("NEWS_SERVER_PORT_FILE", port)
+
+	// No config file, so only the defaults make the keys known
+	t.Chdir(dir)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Auth.JWTSecret != "a-secret-mounted-from-a-file-not-the-env" || cfg.IPFS.Cluster.Password != "hunter2" {
+		t.Errorf("Expected secrets read from files without trailing newlines, got %q and %q", cfg.Auth.JWTSecret, cfg.IPFS.Cluster.Password)
+	}
+	if cfg.IPFS.Cluster.Token != "a-cluster-jwt" {
+		t.Errorf("Expected the cluster token read from its file, got %q", cfg.IPFS.Cluster.Token)
+	}
+	if cfg.Server.Port == 9999 {
+		t.Error("Expected only secrets to be read from files, but server.port was")
+	}
+
+	// The key file encrypts the store; it can't be opened without it
+	key, err := cfg.Database.EncryptionKeyBytes()
+	if err != nil || len(key) != 32 {
+		t.Fatalf("Expected a 32-byte database key, got %d bytes (%v)", len(key), err)
+	}
+	store := filepath.Join(dir, "encrypted_db")
+	db, err := badger.NewEncrypted(store, key)
+	if err != nil {
+		t.Fatalf("Failed to open encrypted store: %v", err)
+	}
+	db.Close()
+	if db, err := badger.New(store); err == nil {
+		db.Close()
+		t.Error("Expected the encrypted store to refuse opening without its key")
+	}
+	if db, err := badger.NewEncrypted(store, key); err != nil {
+		t.Errorf("Expected the encrypted store to reopen with its key: %v", err)
+	} else {
+		db.Close()
+	}
+
+	if err := os.WriteFile(dbKey, []byte("not-hex"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	if _, err := config.Load(); err == nil {
+		t.Error("Expected a malformed database key to be rejected")
+	}
+	os.Unsetenv("NEWS_DATABASE_ENCRYPTION_KEY_FILE")
+
+	// Setting both is ambiguous
+	t.Setenv("NEWS_AUTH_JWT_SECRET", "another-secret-that-is-long-enough-too")
+	if _, err := config.Load(); err == nil {
+		t.Error("Expected both NEWS_AUTH_JWT_SECRET and its _FILE variant to be rejected")
+	}
+	os.Unsetenv("NEWS_AUTH_JWT_SECRET")
+	t.Setenv("NEWS_AUTH_JWT_SECRET_FILE", filepath.Join(dir, "nope"))
+	if _, err := config.Load(); err == nil {
+		t.Error("Expected a missing secret file to be an error")
+	}
+}
+
+func TestConfigProfiles(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	root := filepath.Join(dir, "data")
+	write := func(name, yaml string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(yaml), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	write("node.yaml", "server:\n  port: 9000\nauth:\n  jwt_secret: an-integration-test-secret-of-enough-length\n"+
+		"p2p:\n  rendezvous: mainnet\ndata:\n  root: "+root+"\n"+
+		"database:\n  path: "+filepath.Join(root, "badger_db")+"\n")
+	write("node.testnet.yaml", "server:\n  port: 9001\np2p:\n  rendezvous: testnet\n")
+
+	// The overlay only changes what it lists; state moves under the profile
+	cfg, err := config.LoadWithOverrides(config.Overrides{ConfigFile: filepath.Join(dir, "node.yaml"), Profile: "testnet"})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Server.Port != 9001 || cfg.P2P.Rendezvous != "testnet" || len(cfg.Auth.JWTSecret) == 0 {
+		t.Errorf("Expected the testnet overlay over the base file, got port %d, rendezvous %s", cfg.Server.Port, cfg.P2P.Rendezvous)
+	}
+	if cfg.Database.Path != filepath.Join(root, "profiles", "testnet", "badger_db") {
+		t.Errorf("Expected the database under the profile, got %s", cfg.Database.Path)
+	}
+	if config.ProfileFileUsed() != filepath.Join(dir, "node.testnet.yaml") {
+		t.Errorf("Unexpected overlay reported: %q", config.ProfileFileUsed())
+	}
+
+	// A profile without an overlay just isolates state; the environment
+	// can pick the profile too
+	viper.Reset()
+	t.Setenv("NEWS_DATA_PROFILE", "alice")
+	cfg, err = config.LoadWithOverrides(config.Overrides{ConfigFile: filepath.Join(dir, "node.yaml")})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Server.Port != 9000 || cfg.Data.Profile != "alice" || config.ProfileFileUsed() != "" {
+		t.Errorf("Expected the base settings for a profile without an overlay, got port %d, overlay %q", cfg.Server.Port, config.ProfileFileUsed())
+	}
+
+	viper.Reset()
+	if _, err := config.LoadWithOverrides(config.Overrides{ConfigFile: filepath.Join(dir, "node.yaml"), Profile: "../etc"}); err == nil {
+		t.Error("Expected a profile name with a path in it to be rejected")
+	}
+}
+
+func TestConfigNetworks(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	file := filepath.Join(t.TempDir(), "node.yaml")
+	load := func(yaml string) (*config.Config, error) {
+		viper.Reset()
+		if err := os.WriteFile(file, []byte("auth:\n  jwt_secret: an-integration-test-secret-of-enough-length\n"+yaml), 0o644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		return config.LoadWithOverrides(config.Overrides{ConfigFile: file})
+	}
+
+	// Mainnet is the default and brings its peers and rendezvous
+	cfg, err := load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	mainnet := config.Networks[config.NetworkMainnet]
+	if cfg.Network.Name != config.NetworkMainnet || cfg.P2P.Rendezvous != mainnet.Rendezvous ||
+		len(cfg.P2P.BootstrapPeers) != len(mainnet.BootstrapPeers) || cfg.P2P.BootstrapPeers[0] != mainnet.BootstrapPeers[0] {
+		t.Errorf("Expected mainnet defaults, got network %s, rendezvous %s, peers %v", cfg.Network.Name, cfg.P2P.Rendezvous, cfg.P2P.BootstrapPeers)
+	}
+
+	// Settings in the file beat the network's
+	own := "/ip4/10.0.0.5/tcp/4001/p2p/12D3KooWL1873FCPTiumydhUfp5bqJswTL6niSCL5Nwr3nbb6RYi"
+	if cfg, err = load("network:\n  name: testnet\np2p:\n  bootstrap_peers:\n    - " + own + "\n"); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.P2P.Rendezvous != config.Networks[config.NetworkTestnet].Rendezvous || len(cfg.P2P.BootstrapPeers) != 1 || cfg.P2P.BootstrapPeers[0] != own {
+		t.Errorf("Expected the testnet rendezvous with the listed peer, got %s, %v", cfg.P2P.Rendezvous, cfg.P2P.BootstrapPeers)
+	}
+
+	// A private network has no built-in peers and needs a rendezvous
+	if _, err := load("network:\n  name: newsroom\n"); err == nil {
+		t.Error("Expected a private network without a rendezvous to be rejected")
+	}
+	if cfg, err = load("network:\n  name: newsroom\np2p:\n  rendezvous: newsroom-private\n"); err != nil {
+		t.Fatalf("Failed to load private network: %v", err)
+	}
+	if len(cfg.P2P.BootstrapPeers) != 0 {
+		t.Errorf("Expected no bootstrap peers on a private network, got %v", cfg.P2P.BootstrapPeers)
+	}
+
+	// Following a network policy needs the key it is signed with
+	if _, err := load("network:\n  policy:\n    name: k51-policy\n"); err == nil {
+		t.Error("Expected a policy without a publisher key to be rejected")
+	}
+	if cfg, err = load("network:\n  policy:\n    name: k51-policy\n    publisher_key: did:key:z6Mk\n"); err != nil {
+		t.Fatalf("Failed to load policy config: %v", err)
+	}
+	if cfg.Network.Policy.Interval != time.Hour {
+		t.Errorf("Expected an hourly policy refresh, got %s", cfg.Network.Policy.Interval)
+	}
+}
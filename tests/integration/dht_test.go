@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multihash"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestDHTQueries(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a and c only know each other through b
+	a, b, c := newTestNode(ctx, t), newTestNode(ctx, t), newTestNode(ctx, t)
+	for _, n := range []*p2p.P2PNode{a, c} {
+		if err := n.GetHost().Connect(ctx, peer.AddrInfo{ID: b.GetPeerID(), Addrs: b.GetHost().Addrs()}); err != nil {
+			t.Fatalf("Failed to connect to b: %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for (a.RoutingTableSize() == 0 || b.RoutingTableSize() < 2 || c.RoutingTableSize() == 0) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	hash, _ := multihash.Sum([]byte("article body"), multihash.SHA2_256, -1)
+	content := cid.NewCidV1(cid.Raw, hash)
+	if err := c.GetDHT().Provide(ctx, content, true); err != nil {
+		t.Fatalf("Failed to provide: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	h := handlers.NewNetworkHandler(a, nil, log)
+	engine.GET("/dht/findpeer/:id", h.FindPeer)
+	engine.GET("/dht/findprovs/:cid", h.FindProviders)
+	disabled := handlers.NewNetworkHandler(nil, nil, log)
+	engine.GET("/disabled/:id", disabled.FindPeer)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// 1. A peer reachable only through the DHT
+	var found struct {
+		Data struct {
+			Peer             p2p.DHTPeer
+			RoutingTableSize int `json:"routing_table_size"`
+		}
+	}
+	w := get("/dht/findpeer/" + c.GetPeerID().String())
+	json.Unmarshal(w.Body.Bytes(), &found)
+	if w.Code != http.StatusOK || found.Data.Peer.ID != c.GetPeerID().String() || len(found.Data.Peer.Addresses) == 0 {
+		t.Fatalf("FindPeer failed: %d %s", w.Code, w.Body.String())
+	}
+	if found.Data.RoutingTableSize == 0 {
+		t.Errorf("Expected a non-empty routing table")
+	}
+
+	// 2. Providers of a CID
+	var provs struct {
+		Data struct {
+			Providers []p2p.DHTPeer
+			Count     int
+			Complete  bool
+		}
+	}
+	w = get("/dht/findprovs/" + content.String() + "?limit=1")
+	json.Unmarshal(w.Body.Bytes(), &provs)
+	if w.Code != http.StatusOK || provs.Data.Count != 1 || provs.Data.Providers[0].ID != c.GetPeerID().String() || !provs.Data.Complete {
+		t.Fatalf("FindProviders failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// 3. Bad input and a disabled node
+	for path, want := range map[string]int{
+		"/dht/findpeer/not-a-peer":                          http.StatusBadRequest,
+		"/dht/findprovs/not-a-cid":                          http.StatusBadRequest,
+		"/dht/findprovs/" + content.String() + "?limit=500": http.StatusBadRequest,
+		"/disabled/" + c.GetPeerID().String():               http.StatusServiceUnavailable,
+	} {
+		if w := get(path); w.Code != want {
+			t.Errorf("Expected %d for %s, got %d", want, path, w.Code)
+		}
+	}
+}
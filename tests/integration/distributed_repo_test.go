@@ -0,0 +1,259 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// capturePublisher records ops instead of sending them over pubsub
+type capturePublisher struct {
+	ops []*domain.ArticleOp
+}
+
+func (p *capturePublisher) BroadcastOp(op *domain.ArticleOp) error {
+	p.ops = append(p.ops, op)
+	return nil
+}
+
+func TestDistributedArticleRepoConverges(t *testing.T) {
+	envA := SetupTestEnv(t)
+	defer envA.Cleanup()
+	envB := SetupTestEnv(t)
+	defer envB.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	signer := auth.NewArticleSigner()
+	alice, _ := crypto.GenerateKeyPair()
+	mallory, _ := crypto.GenerateKeyPair()
+	signed := func(article *domain.Article, key *crypto.KeyPair) *domain.Article {
+		t.Helper()
+		article.AuthorPubKey = crypto.PublicKeyToString(key.PublicKey)
+		if err := signer.SignArticle(article, key.PrivateKey); err != nil {
+			t.Fatalf("Failed to sign article: %v", err)
+		}
+		return article
+	}
+	deleting := func(id string, key *crypto.KeyPair) context.Context {
+		t.Helper()
+		deletion := &domain.ArticleDeletion{ArticleID: id, AuthorPubKey: crypto.PublicKeyToString(key.PublicKey), Timestamp: time.Now().Unix()}
+		if err := signer.SignArticleDeletion(deletion, key.PrivateKey); err != nil {
+			t.Fatalf("Failed to sign deletion: %v", err)
+		}
+		return domain.WithArticleDeletion(ctx, deletion)
+	}
+
+	pubA := &capturePublisher{}
+	pubB := &capturePublisher{}
+	repoA := badger.NewDistributedArticleRepo(envA.DB, pubA, signer, "node-a", log)
+	repoB := badger.NewDistributedArticleRepo(envB.DB, pubB, signer, "node-b", log)
+
+	// 1. Node A creates an article and node B applies the op
+	article := signed(&domain.Article{ID: "shared-1", CID: "cid-1", Title: "From A", Body: "Body", Author: "alice"}, alice)
+	if err := repoA.Create(ctx, article); err != nil {
+		t.Fatalf("Node A: failed to create article: %v", err)
+	}
+	if len(pubA.ops) != 1 {
+		t.Fatalf("Node A: expected 1 op, got %d", len(pubA.ops))
+	}
+	if err := repoB.ApplyRemote(ctx, pubA.ops[0]); err != nil {
+		t.Fatalf("Node B: failed to apply op: %v", err)
+	}
+	if got, err := repoB.GetByCID(ctx, "cid-1"); err != nil || got.Title != "From A" {
+		t.Fatalf("Node B: expected replicated article, got %v (%v)", got, err)
+	}
+
+	// 2. Concurrent updates converge on the same winner on both nodes
+	updateA := *article
+	updateA.Title = "Edited on A"
+	signed(&updateA, alice)
+	if err := repoA.Update(ctx, &updateA); err != nil {
+		t.Fatalf("Node A: failed to update: %v", err)
+	}
+	updateB := *article
+	updateB.Title = "Edited on B"
+	signed(&updateB, alice)
+	if err := repoB.Update(ctx, &updateB); err != nil {
+		t.Fatalf("Node B: failed to update: %v", err)
+	}
+
+	if err := repoB.ApplyRemote(ctx, pubA.ops[1]); err != nil {
+		t.Fatalf("Node B: failed to apply op: %v", err)
+	}
+	if err := repoA.ApplyRemote(ctx, pubB.ops[0]); err != nil {
+		t.Fatalf("Node A: failed to apply op: %v", err)
+	}
+
+	gotA, _ := repoA.GetByID(ctx, "shared-1")
+	gotB, _ := repoB.GetByID(ctx, "shared-1")
+	if gotA.Title != gotB.Title {
+		t.Errorf("Nodes diverged: A=%q B=%q", gotA.Title, gotB.Title)
+	}
+
+	// 3. Ops the author didn't sign are rejected
+	forged := *gotA
+	forged.Title = "Forged"
+	tampered := &domain.ArticleOp{Type: domain.OpPut, ArticleID: "shared-1", Article: &forged, Clock: 1000, NodeID: "node-m"}
+	if err := repoB.ApplyRemote(ctx, tampered); !errors.Is(err, domain.ErrInvalidSignature) {
+		t.Errorf("Expected a tampered article rejected, got %v", err)
+	}
+	tampered.Article = signed(&forged, mallory)
+	if err := repoB.ApplyRemote(ctx, tampered); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Expected another author's put rejected, got %v", err)
+	}
+	unsigned := &domain.ArticleOp{Type: domain.OpDelete, ArticleID: "shared-1", Clock: 1000, NodeID: "node-m"}
+	if err := repoB.ApplyRemote(ctx, unsigned); !errors.Is(err, domain.ErrInvalidArticle) {
+		t.Errorf("Expected a delete without the author's deletion rejected, got %v", err)
+	}
+	unsigned.Deletion, _ = domain.ArticleDeletionFrom(deleting("shared-1", mallory))
+	if err := repoB.ApplyRemote(ctx, unsigned); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Expected another author's delete rejected, got %v", err)
+	}
+	if got, err := repoB.GetByID(ctx, "shared-1"); err != nil || got.Title != gotB.Title {
+		t.Errorf("Node B: expected the article untouched, got %v (%v)", got, err)
+	}
+
+	// 4. A clock far ahead of the local one is refused
+	runaway := &domain.ArticleOp{Type: domain.OpPut, ArticleID: "shared-1", Article: signed(&forged, alice), Clock: math.MaxUint64, NodeID: "node-m"}
+	if err := repoB.ApplyRemote(ctx, runaway); !errors.Is(err, domain.ErrInvalidArticle) {
+		t.Errorf("Expected a runaway clock refused, got %v", err)
+	}
+	if got, _ := repoB.GetByID(ctx, "shared-1"); got == nil || got.Title == "Forged" {
+		t.Errorf("Node B: expected the runaway op not applied, got %v", got)
+	}
+
+	// 5. Deletes without the author's deletion stay local
+	local := signed(&domain.Article{ID: "local-1", CID: "cid-local", Title: "Synced", Author: "alice"}, alice)
+	if err := repoA.Create(ctx, local); err != nil {
+		t.Fatalf("Node A: failed to create article: %v", err)
+	}
+	published := len(pubA.ops)
+	if err := repoA.Delete(ctx, "local-1"); err != nil {
+		t.Fatalf("Node A: failed to delete: %v", err)
+	}
+	if len(pubA.ops) != published {
+		t.Error("Node A: expected an unsigned delete not to be replicated")
+	}
+
+	// 6. A delete tombstone wins over the stale put that preceded it
+	if err := repoA.Delete(deleting("shared-1", alice), "shared-1"); err != nil {
+		t.Fatalf("Node A: failed to delete: %v", err)
+	}
+	deleteOp := pubA.ops[len(pubA.ops)-1]
+	if err := repoB.ApplyRemote(ctx, deleteOp); err != nil {
+		t.Fatalf("Node B: failed to apply delete: %v", err)
+	}
+	if err := repoB.ApplyRemote(ctx, pubA.ops[0]); err != nil {
+		t.Fatalf("Node B: failed to apply replayed op: %v", err)
+	}
+	if _, err := repoB.GetByID(ctx, "shared-1"); err != domain.ErrArticleNotFound {
+		t.Errorf("Node B: expected article to stay deleted, got %v", err)
+	}
+
+	// 7. A deleted article gossiped again stays deleted, whatever clock its
+	// relay claims, and isn't replicated again
+	published = len(pubB.ops)
+	if err := repoB.Create(ctx, article); !errors.Is(err, domain.ErrArticleDeleted) {
+		t.Errorf("Node B: expected a re-gossiped deleted article refused, got %v", err)
+	}
+	if len(pubB.ops) != published {
+		t.Error("Node B: expected the refused article not to be replicated")
+	}
+	replayed := *pubA.ops[0]
+	replayed.Clock, replayed.NodeID = deleteOp.Clock+1000, "node-z"
+	if err := repoB.ApplyRemote(ctx, &replayed); err != nil {
+		t.Fatalf("Node B: failed to apply replayed op: %v", err)
+	}
+	if _, err := repoB.GetByID(ctx, "shared-1"); err != domain.ErrArticleNotFound {
+		t.Errorf("Node B: expected a high clock not to resurrect the article, got %v", err)
+	}
+
+	// 8. A deletion that arrives before its article still buries it
+	early := signed(&domain.Article{ID: "early-1", CID: "cid-early", Title: "Retracted", Author: "alice", Timestamp: time.Now().Add(-time.Minute)}, alice)
+	if err := repoA.Create(ctx, early); err != nil {
+		t.Fatalf("Node A: failed to create article: %v", err)
+	}
+	putOp := pubA.ops[len(pubA.ops)-1]
+	if err := repoA.Delete(deleting("early-1", alice), "early-1"); err != nil {
+		t.Fatalf("Node A: failed to delete: %v", err)
+	}
+	if err := repoB.ApplyRemote(ctx, pubA.ops[len(pubA.ops)-1]); err != nil {
+		t.Fatalf("Node B: failed to apply delete: %v", err)
+	}
+	if err := repoB.ApplyRemote(ctx, putOp); err != nil {
+		t.Fatalf("Node B: failed to apply put: %v", err)
+	}
+	if _, err := repoB.GetByID(ctx, "early-1"); err != domain.ErrArticleNotFound {
+		t.Errorf("Node B: expected the late put to stay buried, got %v", err)
+	}
+}
+
+func TestDistributedArticleRepoIngestsRemotePuts(t *testing.T) {
+	envA := SetupTestEnv(t)
+	defer envA.Cleanup()
+	envB := SetupTestEnv(t)
+	defer envB.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	signer := auth.NewArticleSigner()
+	alice, _ := crypto.GenerateKeyPair()
+
+	pubA := &capturePublisher{}
+	pubB := &capturePublisher{}
+	repoA := badger.NewDistributedArticleRepo(envA.DB, pubA, signer, "node-a", log)
+	repoB := badger.NewDistributedArticleRepo(envB.DB, pubB, signer, "node-b", log)
+	articlesB := service.NewArticleService(repoB, envB.UserRepo, envB.IPFS, nil, signer, nil, log)
+	articlesB.SetAcceptancePolicy(service.NewPolicyEngine(domain.AcceptancePolicy{Categories: []string{"news"}}, log))
+	repoB.SetIngester(articlesB)
+
+	put := func(id, category string, version int) *domain.ArticleOp {
+		t.Helper()
+		article := &domain.Article{ID: id, CID: "cid-" + id, Title: id, Body: "Body", Author: "alice", Category: category, Version: version, AuthorPubKey: crypto.PublicKeyToString(alice.PublicKey)}
+		if err := signer.SignArticle(article, alice.PrivateKey); err != nil {
+			t.Fatalf("Failed to sign article: %v", err)
+		}
+		if err := repoA.Update(ctx, article); err != nil {
+			t.Fatalf("Node A: failed to write article: %v", err)
+		}
+		return pubA.ops[len(pubA.ops)-1]
+	}
+
+	// 1. Remote puts pass the node's acceptance policy
+	if err := repoB.ApplyRemote(ctx, put("sports-1", "sports", 1)); !errors.Is(err, domain.ErrArticleNotAccepted) {
+		t.Errorf("Expected the sports article refused, got %v", err)
+	}
+	if articlesB.HasArticle(ctx, "sports-1") {
+		t.Error("Node B: expected the refused article not stored")
+	}
+	if err := repoB.ApplyRemote(ctx, put("news-1", "news", 1)); err != nil {
+		t.Fatalf("Node B: failed to apply op: %v", err)
+	}
+	if !articlesB.HasArticle(ctx, "news-1") {
+		t.Error("Node B: expected the accepted article stored")
+	}
+
+	// 2. Revisions replace the stored copy in place
+	if err := repoB.ApplyRemote(ctx, put("news-1", "News", 2)); err != nil {
+		t.Fatalf("Node B: failed to apply revision: %v", err)
+	}
+	if got, err := repoB.GetByCID(ctx, "cid-news-1"); err != nil || got.Version != 2 {
+		t.Errorf("Node B: expected the revision stored, got %v (%v)", got, err)
+	}
+
+	// 3. Ingested writes aren't replicated again
+	if len(pubB.ops) != 0 {
+		t.Errorf("Node B: expected no ops echoed, got %d", len(pubB.ops))
+	}
+}
@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestEndorsements(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	signer := auth.NewArticleSigner()
+	repo := badger.NewEndorsementRepo(env.DB)
+	reputation := p2p.NewReputationSystem(log)
+	endorsements := service.NewEndorsementService(repo, env.ArticleRepo, env.UserRepo, signer, reputation, log)
+	broadcaster := mocks.NewMockEndorsementBroadcaster()
+	endorsements.SetBroadcaster(broadcaster)
+
+	h := handlers.NewReputationHandler(reputation, log)
+	h.SetEndorsements(endorsements)
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/reputation/:did/endorsements", h.Endorsements)
+	engine.POST("/reputation/:did/endorse", middleware.AuthMiddleware(env.JWTManager), h.Endorse)
+	engine.DELETE("/reputation/:did/endorse", middleware.AuthMiddleware(env.JWTManager), h.RevokeEndorsement)
+
+	ctx := context.Background()
+	tokens := make(map[string]string)
+	for _, name := range []string{"veteran", "newcomer", "outsider"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		tokens[name], _, _ = env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	}
+	for i := 0; i < 3; i++ {
+		reputation.RecordEvent(&p2p.ReputationEvent{DID: "veteran", EventType: p2p.EventArticlePost})
+	}
+
+	call := func(method, user, did, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/reputation/"+did+"/endorse", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokens[user])
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	list := func(did string) []domain.Endorsement {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reputation/"+did+"/endorsements", nil))
+		var resp struct{ Data []domain.Endorsement }
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Data
+	}
+
+	// 1. Only established users endorse, and never themselves
+	if w := call(http.MethodPost, "outsider", "newcomer", `{}`); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for an unestablished endorser, got %d", w.Code)
+	}
+	if w := call(http.MethodPost, "veteran", "veteran", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a self-endorsement, got %d", w.Code)
+	}
+
+	// 2. An endorsement is signed, broadcast and lifts the subject's score
+	if w := call(http.MethodPost, "veteran", "newcomer", `{"note":"we worked together at the Herald"}`); w.Code != http.StatusOK {
+		t.Fatalf("Endorse failed: %d %s", w.Code, w.Body.String())
+	}
+	score := reputation.GetScore("newcomer")
+	if score.Endorsements != 1 || score.Score != p2p.InitialScore+p2p.EndorsementBonus || !reputation.IsEstablished("newcomer") {
+		t.Errorf("Expected the newcomer vouched in, got %+v", score)
+	}
+	if got := list("newcomer"); len(got) != 1 || got[0].Endorser != "veteran" || got[0].Note == "" {
+		t.Errorf("Unexpected endorsements %+v", got)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(broadcaster.Endorsements()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	sent := broadcaster.Endorsements()
+	if len(sent) != 1 {
+		t.Fatalf("Expected the endorsement broadcast, got %+v", sent)
+	}
+	if err := signer.VerifyEndorsement(&sent[0]); err != nil {
+		t.Errorf("Broadcast endorsement does not verify: %v", err)
+	}
+
+	// 3. Revoking withdraws the bonus, and an old record can't bring it back
+	if w := call(http.MethodDelete, "veteran", "newcomer", ""); w.Code != http.StatusOK {
+		t.Fatalf("Revoke failed: %d %s", w.Code, w.Body.String())
+	}
+	if w := call(http.MethodDelete, "veteran", "newcomer", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 revoking twice, got %d", w.Code)
+	}
+	if err := endorsements.HandleIncomingEndorsement(ctx, &sent[0]); err != nil {
+		t.Fatalf("Failed to handle a replayed endorsement: %v", err)
+	}
+	if score := reputation.GetScore("newcomer"); score.Endorsements != 0 || score.Score != p2p.InitialScore || len(list("newcomer")) != 0 {
+		t.Errorf("Expected the endorsement to stay revoked, got %+v", score)
+	}
+
+	// 4. Peer endorsements must be signed by the named user's key
+	keys, _ := crypto.GenerateKeyPair()
+	forged := &domain.Endorsement{Endorser: "veteran", EndorserDID: crypto.PublicKeyToString(keys.PublicKey), Subject: "outsider", Timestamp: time.Now().Unix()}
+	signer.SignEndorsement(forged, keys.PrivateKey)
+	if err := endorsements.HandleIncomingEndorsement(ctx, forged); !errors.Is(err, service.ErrUnknownEndorser) {
+		t.Errorf("Expected an impersonated endorser to be rejected, got %v", err)
+	}
+
+	// 5. Endorsements in effect are restored on restart
+	call(http.MethodPost, "veteran", "outsider", `{}`)
+	restarted := p2p.NewReputationSystem(log)
+	for i := 0; i < 3; i++ {
+		restarted.RecordEvent(&p2p.ReputationEvent{DID: "veteran", EventType: p2p.EventArticlePost})
+	}
+	if err := service.NewEndorsementService(repo, env.ArticleRepo, env.UserRepo, signer, restarted, log).Load(ctx); err != nil {
+		t.Fatalf("Failed to load endorsements: %v", err)
+	}
+	if restarted.GetScore("outsider").Endorsements != 1 || restarted.GetScore("newcomer").Endorsements != 0 {
+		t.Error("Expected only the endorsement in effect restored")
+	}
+}
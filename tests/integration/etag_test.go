@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestArticleETags(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	h := handlers.NewArticleHandler(env.ArticleService, log)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/articles/:cid", middleware.ETag(middleware.CacheRevalidate), h.GetByCID)
+	engine.GET("/articles", middleware.ETag(middleware.CacheRevalidate), h.List)
+
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	ctx := context.Background()
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "frank", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: "Cached", Body: "Served once."}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	// 1. Articles by CID carry a weak validator built on the CID and are
+	// revalidated, since the node decorates them locally
+	w := get("/articles/"+article.CID, "")
+	tag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || !strings.HasPrefix(tag, `W/"`+article.CID+`-`) {
+		t.Fatalf("Expected a weak ETag on the CID, got %d %q", w.Code, tag)
+	}
+	if w.Header().Get("Cache-Control") != middleware.CacheRevalidate {
+		t.Errorf("Unexpected Cache-Control %q", w.Header().Get("Cache-Control"))
+	}
+	for _, inm := range []string{tag, `"other", ` + tag, strings.TrimPrefix(tag, "W/"), "*"} {
+		if w := get("/articles/"+article.CID, inm); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: expected an empty 304, got %d with %d bytes", inm, w.Code, w.Body.Len())
+		}
+	}
+	if w := get("/articles/"+article.CID, `"other"`); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a stale ETag, got %d", w.Code)
+	}
+
+	// A label added by a local filter list changes the tag
+	stored, err := env.ArticleRepo.GetByCID(ctx, article.CID)
+	if err != nil {
+		t.Fatalf("Failed to load article: %v", err)
+	}
+	stored.Labels = []string{"satire"}
+	if err := env.ArticleRepo.Update(ctx, stored); err != nil {
+		t.Fatalf("Failed to label article: %v", err)
+	}
+	if w := get("/articles/"+article.CID, tag); w.Code != http.StatusOK || w.Header().Get("ETag") == tag {
+		t.Errorf("Expected a new ETag once labelled, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// 2. Errors are passed through without validators
+	if w := get("/articles/QmMissing", "*"); w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" {
+		t.Errorf("Expected a plain 404, got %d with ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// 3. Lists are hashed, and the ETag changes with their content
+	w = get("/articles", "")
+	listTag := w.Header().Get("ETag")
+	if listTag == "" || w.Header().Get("Cache-Control") != middleware.CacheRevalidate {
+		t.Fatalf("Expected a revalidated list ETag, got %q %q", listTag, w.Header().Get("Cache-Control"))
+	}
+	if w := get("/articles", listTag); w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for an unchanged list, got %d", w.Code)
+	}
+	if _, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: "Fresh", Body: "Changes the list."}, user.ID, "127.0.0.1"); err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	if w := get("/articles", listTag); w.Code != http.StatusOK || w.Header().Get("ETag") == listTag {
+		t.Errorf("Expected a new list and ETag after publishing, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
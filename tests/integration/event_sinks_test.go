@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/internal/metrics"
+	badgerrepo "github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestEventSinks(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	ctx := context.Background()
+	bus := events.NewBus(log)
+	defer bus.Close()
+
+	// Webhook receiver that fails the first delivery
+	var mu sync.Mutex
+	var deliveries []string
+	attempts := 0
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get(events.HeaderSignature) != events.Sign([]byte("hook-secret"), body) {
+			t.Errorf("Bad signature on %s delivery", r.Header.Get(events.HeaderEventType))
+		}
+		deliveries = append(deliveries, r.Header.Get(events.HeaderEventType)+" "+string(body))
+	}))
+	defer hook.Close()
+
+	audit := service.NewAuditService(badgerrepo.NewAuditRepo(env.DB), log)
+	m := metrics.New()
+	bus.AddSink("webhook", events.NewWebhookSink(hook.URL, "hook-secret", time.Second), domain.EventArticleCreated, domain.EventModerationApplied)
+	bus.AddSink("audit", events.NewAuditSink(audit), domain.EventPeerConnected)
+	bus.AddSink("metrics", m)
+	bus.AddSink("article-ingest", env.ArticleService, domain.EventArticleGossip)
+	stream, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	env.ArticleService.SetEventPublisher(bus)
+	moderation := service.NewModerationService(badgerrepo.NewReportRepo(env.DB), badgerrepo.NewModerationCaseRepo(env.DB), env.ArticleRepo, log)
+	moderation.SetEventPublisher(bus)
+
+	// 1. Services publish; each sink gets the types it asked for
+	user, _ := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "erin", Password: "password123"})
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Harbour strike", Body: "Dock workers walked out at dawn.", Category: "business",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	report, _ := moderation.Report(ctx, article.ID, user.ID, "Off topic")
+	if _, err := moderation.Dismiss(ctx, report.ID, user.ID, &domain.ReportDecisionRequest{Note: "Fine"}); err != nil {
+		t.Fatalf("Failed to dismiss: %v", err)
+	}
+	bus.Publish(domain.EventPeerConnected, domain.PeerEvent{PeerID: "12D3KooWPeer", Peers: 1})
+
+	waitFor(t, "webhook deliveries", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(deliveries) == 2
+	})
+	mu.Lock()
+	if !strings.HasPrefix(deliveries[0], domain.EventArticleCreated+" ") || !strings.Contains(deliveries[0], article.ID) {
+		t.Errorf("Expected the article to be delivered after a retry, got %q", deliveries[0])
+	}
+	var applied struct{ Data domain.ModerationEvent }
+	json.Unmarshal([]byte(strings.TrimPrefix(deliveries[1], domain.EventModerationApplied+" ")), &applied)
+	if applied.Data.ReportID != report.ID || applied.Data.Status != domain.ReportDismissed || applied.Data.Moderator != user.ID {
+		t.Errorf("Unexpected moderation event: %q", deliveries[1])
+	}
+	mu.Unlock()
+
+	waitFor(t, "audit entry", func() bool {
+		entries, _, _ := audit.List(ctx, &domain.AuditListFilter{Action: domain.EventPeerConnected})
+		return len(entries) == 1 && entries[0].Target == "12D3KooWPeer" &&
+			entries[0].Details["peers"] == "1" && entries[0].Actor == domain.AuditActorSystem
+	})
+
+	// 2. Gossiped articles reach the ingest sink but not unfiltered streams
+	if err := env.ArticleRepo.Delete(ctx, article.ID); err != nil {
+		t.Fatalf("Failed to delete article: %v", err)
+	}
+	bus.Publish(domain.EventArticleGossip, article)
+	waitFor(t, "gossiped article to be stored", func() bool {
+		_, err := env.ArticleRepo.GetByID(ctx, article.ID)
+		return err == nil
+	})
+
+	var streamed []string
+	for len(stream) > 0 {
+		streamed = append(streamed, (<-stream).Type)
+	}
+	want := []string{domain.EventArticleCreated, domain.EventModerationApplied, domain.EventPeerConnected, domain.EventArticleReceived}
+	if strings.Join(streamed, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected stream %v, got %v", want, streamed)
+	}
+
+	// 3. The metrics sink counts every public event
+	waitFor(t, "event metrics", func() bool {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return strings.Contains(w.Body.String(), `newsp2p_events_total{type="article.received"} 1`) &&
+			strings.Contains(w.Body.String(), `newsp2p_events_total{type="article.created"} 1`) &&
+			!strings.Contains(w.Body.String(), `type="p2p.article"`)
+	})
+}
+
+// waitFor polls cond for up to 5 seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
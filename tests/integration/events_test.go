@@ -0,0 +1,202 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestEventStreamOverWebSocket(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	bus := events.NewBus(log)
+	env.ArticleService.SetEventPublisher(bus)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/v1/ws", middleware.AuthMiddleware(env.JWTManager),
+		handlers.NewEventsHandler(bus, []string{"http://allowed.example"}, log).Stream)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/ws"
+
+	ctx := context.Background()
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "carol", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	token, _, _ := env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	authHeader := http.Header{"Authorization": {"Bearer " + token}}
+
+	// 1. Anonymous clients and foreign origins are refused
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without a token, got %v", err)
+	}
+	foreign := http.Header{"Authorization": {"Bearer " + token}, "Origin": {"http://evil.example"}}
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, foreign); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected 403 for a foreign origin, got %v", err)
+	}
+
+	// 2. An authenticated client receives every event; a filtered one only its types
+	all, _, err := websocket.DefaultDialer.Dial(wsURL, authHeader)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer all.Close()
+	votesOnly, _, err := websocket.DefaultDialer.Dial(wsURL+"?types=vote.tally", authHeader)
+	if err != nil {
+		t.Fatalf("Dial with filter failed: %v", err)
+	}
+	defer votesOnly.Close()
+
+	// Subscriptions are registered after the upgrade completes
+	deadline := time.Now().Add(5 * time.Second)
+	for bus.Subscribers() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Live update",
+		Body:     "An article announced to connected clients.",
+		Category: "technology",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	votes := service.NewVoteCounter()
+	votes.Apply(article.ID, "did:key:alice", 1)
+	votes.Apply(article.ID, "did:key:bob", 1)
+	tally := votes.Apply(article.ID, "did:key:alice", -1) // a changed vote replaces the earlier one
+	if tally.Up != 1 || tally.Down != 1 || tally.Score != 0 {
+		t.Errorf("Expected one vote each way, got %+v", tally)
+	}
+	bus.Publish(domain.EventVoteTally, tally)
+
+	readEvent := func(conn *websocket.Conn) (event struct {
+		ID   uint64          `json:"id"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}) {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		return event
+	}
+
+	created := readEvent(all)
+	var got domain.Article
+	json.Unmarshal(created.Data, &got)
+	if created.Type != domain.EventArticleCreated || got.ID != article.ID {
+		t.Errorf("Expected article.created for %s, got %s %+v", article.ID, created.Type, got)
+	}
+	if next := readEvent(all); next.Type != domain.EventVoteTally || next.ID <= created.ID {
+		t.Errorf("Expected a later vote.tally event, got %+v", next)
+	}
+
+	if only := readEvent(votesOnly); only.Type != domain.EventVoteTally {
+		t.Errorf("Expected the filtered client to skip straight to vote.tally, got %s", only.Type)
+	}
+
+	// 3. Disconnecting releases the subscription
+	all.Close()
+	votesOnly.Close()
+	deadline = time.Now().Add(5 * time.Second)
+	for bus.Subscribers() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := bus.Subscribers(); n != 0 {
+		t.Errorf("Expected subscribers to be released on disconnect, %d remain", n)
+	}
+}
+
+func TestArticleStreamResumesFromLastEventID(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	bus := events.NewBus(log)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/v1/articles/stream", handlers.NewEventsHandler(bus, nil, log).ArticleStream)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+	streamURL := server.URL + "/api/v1/articles/stream"
+
+	// Events 1-3 happen while the client is away; only articles are streamed
+	bus.Publish(domain.EventArticleCreated, &domain.Article{ID: "seen"})
+	bus.Publish(domain.EventVoteTally, domain.VoteTally{ArticleID: "seen", Up: 1, Score: 1})
+	bus.Publish(domain.EventArticleReceived, &domain.Article{ID: "missed"})
+
+	// 1. A malformed Last-Event-ID is rejected
+	resp, err := http.Get(streamURL + "?last_event_id=abc")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed Last-Event-ID, got %d", resp.StatusCode)
+	}
+
+	// 2. Resuming after event 1 replays the missed article, then streams live ones
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Stream request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (id, name string, article domain.Article) {
+		t.Helper()
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Stream ended early: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "" && id != "":
+				return id, name, article
+			case strings.HasPrefix(line, "id:"):
+				id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+			case strings.HasPrefix(line, "event:"):
+				name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &article)
+			}
+		}
+	}
+
+	if id, name, article := readEvent(); id != "3" || name != domain.EventArticleReceived || article.ID != "missed" {
+		t.Errorf("Expected replay of event 3 (missed), got %s %s %s", id, name, article.ID)
+	}
+
+	bus.Publish(domain.EventArticleCreated, &domain.Article{ID: "live"})
+	if id, name, article := readEvent(); id != "4" || name != domain.EventArticleCreated || article.ID != "live" {
+		t.Errorf("Expected live event 4, got %s %s %s", id, name, article.ID)
+	}
+}
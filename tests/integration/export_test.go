@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestArticleExport(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	h := handlers.NewArticleHandler(env.ArticleService, log)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/articles/:cid/export", h.Export)
+
+	ctx := context.Background()
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "heidi", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Café prices & (rising) rents",
+		Body: "## Summary\n\nRents rose **4%** this year — see [the report](https://example.org/report).\n\n" +
+			"- first point\n- second point\n\n```\ncode stays verbatim\n```\n\n<script>alert(1)</script>\n",
+		Tags:     []string{"economy", "housing"},
+		Category: "business",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	key, _ := crypto.PublicKeyFromString(article.AuthorPubKey)
+	fingerprint := crypto.Fingerprint(key)
+
+	export := func(format string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/"+article.CID+"/export?format="+format, nil))
+		return w
+	}
+
+	// 1. Unknown formats and articles are rejected
+	if w := export("docx"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown format, got %d", w.Code)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/QmMissing/export", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing article, got %d", w.Code)
+	}
+
+	// 2. Every format downloads with provenance metadata
+	cases := []struct {
+		format, contentType, ext string
+	}{
+		{"markdown", "text/markdown; charset=utf-8", ".md"},
+		{"html", "text/html; charset=utf-8", ".html"},
+		{"epub", "application/epub+zip", ".epub"},
+		{"pdf", "application/pdf", ".pdf"},
+	}
+	bodies := make(map[string][]byte)
+	for _, tc := range cases {
+		w := export(tc.format)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s export failed: %d %s", tc.format, w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tc.contentType {
+			t.Errorf("%s: unexpected Content-Type %q", tc.format, ct)
+		}
+		if cd := w.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") || !strings.Contains(cd, tc.ext+`"`) {
+			t.Errorf("%s: unexpected Content-Disposition %q", tc.format, cd)
+		}
+		bodies[tc.format] = w.Body.Bytes()
+	}
+
+	md := string(bodies["markdown"])
+	for _, want := range []string{`cid: "` + article.CID + `"`, `signature_fingerprint: "` + fingerprint + `"`, `author: "heidi"`, "## Summary"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown export lacks %q", want)
+		}
+	}
+
+	page := string(bodies["html"])
+	for _, want := range []string{article.CID, fingerprint, "<h2>Summary</h2>", "<strong>4%</strong>", `<meta name="author" content="heidi">`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML export lacks %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("HTML export kept a script tag")
+	}
+
+	// 3. The EPUB is a valid container whose XML documents parse
+	zr, err := zip.NewReader(bytes.NewReader(bodies["epub"]), int64(len(bodies["epub"])))
+	if err != nil {
+		t.Fatalf("EPUB is not a zip: %v", err)
+	}
+	if zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+		t.Error("EPUB must start with a stored mimetype entry")
+	}
+	for _, f := range zr.File[1:] {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		decoder.Strict = true
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("EPUB %s is not well-formed XML: %v", f.Name, err)
+				break
+			}
+		}
+		if f.Name == "OEBPS/content.opf" && (!strings.Contains(string(data), article.CID) || !strings.Contains(string(data), fingerprint)) {
+			t.Error("EPUB package metadata lacks the CID or fingerprint")
+		}
+	}
+
+	// 4. The PDF is structurally sound and carries the metadata
+	pdf := bodies["pdf"]
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("PDF lacks its header or trailer")
+	}
+	for _, want := range []string{"/Author", "/SignatureFingerprint", "(Summary)", "(code stays verbatim)", `\(rising\)`} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("PDF lacks %q", want)
+		}
+	}
+	start := bytes.LastIndex(pdf, []byte("startxref\n"))
+	var xref int
+	if _, err := fmt.Sscan(string(pdf[start+len("startxref\n"):]), &xref); err != nil || !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Errorf("PDF startxref does not point at the xref table")
+	}
+}
@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// staticSearchProvider answers every search with the same articles
+type staticSearchProvider struct {
+	articles []*domain.Article
+	queries  []*search.SearchQuery
+}
+
+func (p *staticSearchProvider) Search(ctx context.Context, query *search.SearchQuery) (*search.SearchResult, error) {
+	p.queries = append(p.queries, query)
+	return &search.SearchResult{Articles: p.articles, Total: len(p.articles)}, nil
+}
+
+func newTestHost(t *testing.T) host.Host {
+	t.Helper()
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatalf("Failed to create host: %v", err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestFederatedSearch(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	local := &domain.Article{ID: "l1", CID: "cid-shared", Title: "Mesh radio local", Body: "mesh", Author: "a", Timestamp: time.Now()}
+	if err := env.ArticleRepo.Create(ctx, local); err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	index := setupSearchIndex(t, local)
+
+	// Peer B knows the shared article plus one we don't have, and returns
+	// a forgery of it
+	keyPair, _ := crypto.GenerateKeyPair()
+	remote := &domain.Article{
+		ID:           "r1",
+		CID:          "cid-remote",
+		Title:        "Mesh radio remote",
+		Author:       "b",
+		AuthorPubKey: crypto.PublicKeyToString(keyPair.PublicKey),
+		Timestamp:    time.Now(),
+		Version:      1,
+	}
+	if err := auth.NewArticleSigner().SignArticle(remote, keyPair.PrivateKey); err != nil {
+		t.Fatalf("Failed to sign article: %v", err)
+	}
+	forged := *remote
+	forged.ID, forged.CID, forged.Title = "f1", "cid-forged", "Mesh radio forged"
+
+	hostA, hostB := newTestHost(t), newTestHost(t)
+	provider := &staticSearchProvider{articles: []*domain.Article{
+		{ID: "l1-copy", CID: "cid-shared", Title: "Mesh radio local"},
+		remote,
+		&forged,
+	}}
+	p2p.NewFederatedSearch(hostB, provider, 5, 5*time.Second, log)
+
+	if err := hostA.Connect(ctx, peer.AddrInfo{ID: hostB.ID(), Addrs: hostB.Addrs()}); err != nil {
+		t.Fatalf("Failed to connect hosts: %v", err)
+	}
+
+	searchService := service.NewSearchService(index, env.ArticleRepo, 2, log)
+	searchService.SetNetworkSearcher(p2p.NewFederatedSearch(hostA, searchService, 5, 5*time.Second, log))
+	searchService.SetRemoteChecker(env.ArticleService)
+
+	result, err := searchService.Search(ctx, &search.SearchQuery{Query: "mesh", Scope: search.ScopeNetwork})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if result.PeersResponded != 1 || result.RemoteResults != 1 {
+		t.Errorf("Expected 1 peer and 1 remote result, got %d and %d", result.PeersResponded, result.RemoteResults)
+	}
+	if len(result.Articles) != 2 || result.Articles[0].ID != "l1" || result.Articles[1].ID != "r1" {
+		t.Errorf("Expected [l1 r1] without the forgery, got %v", articleIDs(result.Articles))
+	}
+
+	// Forwarded searches stay local on the receiving peer
+	if len(provider.queries) != 1 || provider.queries[0].Scope != search.ScopeLocal {
+		t.Errorf("Expected one local-scoped forwarded query, got %+v", provider.queries)
+	}
+
+	// Local scope never asks peers
+	result, err = searchService.Search(ctx, &search.SearchQuery{Query: "mesh"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Articles) != 1 || len(provider.queries) != 1 {
+		t.Errorf("Expected local-only search, got %v", articleIDs(result.Articles))
+	}
+}
+
+func articleIDs(articles []*domain.Article) []string {
+	ids := make([]string, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+	return ids
+}
@@ -0,0 +1,176 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	shell "github.com/ipfs/go-ipfs-api"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// fakeKeystore answers the kubo key/* endpoints
+type fakeKeystore struct {
+	mu   sync.Mutex
+	keys map[string]string // name -> ID
+}
+
+func (f *fakeKeystore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	type key struct{ Name, Id string }
+	args := r.URL.Query()["arg"]
+	switch r.URL.Path {
+	case "/api/v0/key/list":
+		keys := []key{}
+		for name, id := range f.keys {
+			keys = append(keys, key{name, id})
+		}
+		json.NewEncoder(w).Encode(map[string][]key{"Keys": keys})
+	case "/api/v0/key/gen":
+		f.keys[args[0]] = "k51" + args[0]
+		json.NewEncoder(w).Encode(key{args[0], f.keys[args[0]]})
+	case "/api/v0/key/rm":
+		id := f.keys[args[0]]
+		delete(f.keys, args[0])
+		json.NewEncoder(w).Encode(map[string][]key{"Keys": {{args[0], id}}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeKeystore) has(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.keys[name]
+	return ok
+}
+
+func TestFeedManagement(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	keystore := &fakeKeystore{keys: map[string]string{"self": "k51self"}}
+	server := httptest.NewServer(keystore)
+	defer server.Close()
+
+	log, _ := logger.New("error", "text")
+	manager := ipfs.NewIPNSManager(shell.NewShell(server.URL), log)
+	feeds := service.NewFeedService(badger.NewFeedRepo(env.DB), env.ArticleRepo, env.IPFS, manager, log)
+	h := handlers.NewFeedHandler(feeds, nil, log)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/feeds/:name", h.Get)
+	admin := engine.Group("/feeds", middleware.AuthMiddleware(env.JWTManager), middleware.AdminMiddleware([]string{"root"}))
+	admin.POST("", h.Create)
+	admin.PUT("/:name", h.Update)
+	admin.DELETE("/:name", h.Delete)
+
+	ctx := context.Background()
+	tokens := make(map[string]string)
+	for _, name := range []string{"root", "reader"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		tokens[name], _, _ = env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	}
+
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokens[user])
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) domain.Feed {
+		var resp struct{ Data domain.Feed }
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode feed: %v", err)
+		}
+		return resp.Data
+	}
+
+	// 1. Only admins manage feeds
+	if w := do("reader", http.MethodPost, "/feeds", `{"name":"tech","sync_interval":15}`); w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403 for a non-admin, got %d", w.Code)
+	}
+
+	// 2. Creating a feed generates its key and address
+	w := do("root", http.MethodPost, "/feeds", `{"name":"tech","sync_interval":15}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if feed := decode(w); feed.IPNSKey != "tech" || feed.IPNSAddress != "/ipns/k51tech" || !keystore.has("tech") {
+		t.Errorf("Expected a key for the feed, got %+v", feed)
+	}
+	if w := do("root", http.MethodPost, "/feeds", `{"name":"tech","sync_interval":5}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a duplicate name, got %d", w.Code)
+	}
+
+	// 3. Names that can't be keys, reserved names and bad intervals never reach the keystore
+	for _, body := range []string{
+		`{"name":"../etc","sync_interval":15}`,
+		`{"name":"self","sync_interval":15}`,
+		`{"name":"resolve","sync_interval":15}`,
+		`{"name":"slow","sync_interval":100000}`,
+		`{"name":"tech2"}`,
+	} {
+		if w := do("root", http.MethodPost, "/feeds", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+	if keystore.has("slow") || keystore.has("../etc") {
+		t.Errorf("Expected no keys for rejected feeds")
+	}
+
+	// 4. Updates change the interval within bounds
+	if w := do("root", http.MethodPut, "/feeds/tech", `{"sync_interval":30}`); w.Code != http.StatusOK || decode(w).SyncInterval != 30 {
+		t.Errorf("Expected the interval updated, got %d", w.Code)
+	}
+	for _, body := range []string{`{}`, `{"sync_interval":100000}`} {
+		if w := do("root", http.MethodPut, "/feeds/tech", body); w.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: expected 400, got %d", body, w.Code)
+		}
+	}
+	if w := do("root", http.MethodPut, "/feeds/missing", `{"sync_interval":30}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 updating a missing feed, got %d", w.Code)
+	}
+
+	// 5. Deleting can keep the key, so a recreated feed keeps its address
+	if w := do("root", http.MethodDelete, "/feeds/tech?keep_key=true", ""); w.Code != http.StatusOK || !keystore.has("tech") {
+		t.Fatalf("Expected the key kept, got %d", w.Code)
+	}
+	if w := do("root", http.MethodPost, "/feeds", `{"name":"tech","sync_interval":15}`); w.Code != http.StatusCreated || decode(w).IPNSAddress != "/ipns/k51tech" {
+		t.Errorf("Expected the recreated feed at its old address, got %d", w.Code)
+	}
+
+	// 6. By default the key goes with the feed, and the node key is untouched
+	if w := do("root", http.MethodDelete, "/feeds/tech", ""); w.Code != http.StatusOK || keystore.has("tech") {
+		t.Errorf("Expected the key removed, got %d", w.Code)
+	}
+	if w := do("reader", http.MethodGet, "/feeds/tech", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected the feed gone, got %d", w.Code)
+	}
+	if !keystore.has("self") {
+		t.Errorf("Expected the node's own key to survive")
+	}
+	if err := manager.RemoveKey(ctx, "self"); err == nil || !keystore.has("self") {
+		t.Errorf("Expected removing the self key to be refused")
+	}
+}
@@ -0,0 +1,163 @@
+package integration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// fakeMFS answers kubo's files/* endpoints from a flat path->CID map.
+// Directories are the keys with an empty CID.
+type fakeMFS struct {
+	mu    sync.Mutex
+	nodes map[string]string
+}
+
+func (f *fakeMFS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	args := r.URL.Query()["arg"]
+	fail := func(msg string) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"Message": msg, "Code": 0, "Type": "error"})
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, "/api/v0/") {
+	case "files/mkdir":
+		for dir := args[0]; dir != "/"; dir = dir[:strings.LastIndex(dir, "/")] {
+			f.nodes[dir] = ""
+			if !strings.Contains(dir[1:], "/") {
+				break
+			}
+		}
+	case "files/cp":
+		f.nodes[args[1]] = strings.TrimPrefix(args[0], "/ipfs/")
+	case "files/rm":
+		if _, ok := f.nodes[args[0]]; !ok {
+			fail("file does not exist")
+			return
+		}
+		for p := range f.nodes {
+			if p == args[0] || strings.HasPrefix(p, args[0]+"/") {
+				delete(f.nodes, p)
+			}
+		}
+	case "files/mv":
+		for p, cid := range f.nodes {
+			if p == args[0] || strings.HasPrefix(p, args[0]+"/") {
+				delete(f.nodes, p)
+				f.nodes[args[1]+strings.TrimPrefix(p, args[0])] = cid
+			}
+		}
+	case "files/stat":
+		json.NewEncoder(w).Encode(map[string]string{"Hash": f.hash(args[0]), "Type": "directory"})
+		return
+	default:
+		fail("unexpected command " + r.URL.Path)
+		return
+	}
+	w.Write([]byte("{}"))
+}
+
+// hash derives a stable directory CID from everything below dir
+func (f *fakeMFS) hash(dir string) string {
+	h := sha256.New()
+	for _, p := range f.below(dir) {
+		h.Write([]byte(p + "=" + f.nodes[dir+"/"+p] + ";"))
+	}
+	return "QmMockMFS" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// below lists the file paths under dir, relative to it
+func (f *fakeMFS) below(dir string) []string {
+	var out []string
+	for p, cid := range f.nodes {
+		if cid != "" && strings.HasPrefix(p, dir+"/") {
+			out = append(out, strings.TrimPrefix(p, dir+"/"))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestFeedMirrorLayout(t *testing.T) {
+	mfs := &fakeMFS{nodes: make(map[string]string)}
+	server := httptest.NewServer(mfs)
+	defer server.Close()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	client := ipfs.NewClient(server.URL, 5*time.Second, false, log)
+
+	day := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	articles := []*domain.Article{
+		{ID: "a1", CID: "QmFirst", Title: "Hello, World!", Timestamp: day},
+		{ID: "a2", CID: "QmSecond", Title: "hello world", Timestamp: day.Add(time.Hour)},
+		{ID: "a3", CID: "QmThird", Title: "Café Opens", Timestamp: day.Add(24 * time.Hour)},
+		{ID: "a4", CID: "local-abc", Title: "Never reached IPFS", Timestamp: day},
+		{ID: "a5", CID: "QmFifth", Title: "!!!", Timestamp: day},
+	}
+
+	// 1. Articles land under <date>/<slug>.json next to the manifest
+	entries := service.FeedDirectoryEntries("QmManifest", articles)
+	root, err := client.MirrorDirectory(ctx, "/newsp2p/feeds/global", entries)
+	if err != nil {
+		t.Fatalf("MirrorDirectory failed: %v", err)
+	}
+
+	mfs.mu.Lock()
+	got := mfs.below("/newsp2p/feeds/global")
+	want := []string{
+		"2026-03-14/a5.json",
+		"2026-03-14/hello-world-2.json",
+		"2026-03-14/hello-world.json",
+		"2026-03-15/café-opens.json",
+		"manifest.json",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Unexpected feed layout:\n got %v\nwant %v", got, want)
+	}
+	if mfs.nodes["/newsp2p/feeds/global/2026-03-14/hello-world.json"] != "QmFirst" {
+		t.Errorf("Expected the first article to keep the plain slug")
+	}
+	if root != mfs.hash("/newsp2p/feeds/global") {
+		t.Errorf("Expected the directory CID of the mirrored feed, got %s", root)
+	}
+	mfs.mu.Unlock()
+
+	// 2. A later sync replaces the tree instead of accumulating stale entries
+	if _, err := client.MirrorDirectory(ctx, "/newsp2p/feeds/global", service.FeedDirectoryEntries("QmManifest2", articles[2:3])); err != nil {
+		t.Fatalf("Second MirrorDirectory failed: %v", err)
+	}
+
+	mfs.mu.Lock()
+	defer mfs.mu.Unlock()
+	got = mfs.below("/newsp2p/feeds/global")
+	if strings.Join(got, ",") != "2026-03-15/café-opens.json,manifest.json" {
+		t.Errorf("Expected only the latest feed contents, got %v", got)
+	}
+	for p := range mfs.nodes {
+		if strings.Contains(p, ".staging-") {
+			t.Errorf("Staging directory left behind: %s", p)
+		}
+	}
+
+	// 3. The MFS root itself is never replaced
+	if _, err := client.MirrorDirectory(ctx, "/", entries); err == nil {
+		t.Error("Expected mirroring onto / to be refused")
+	}
+}
@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	shell "github.com/ipfs/go-ipfs-api"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestSeedRemoteFeed(t *testing.T) {
+	publisher, mirror := SetupTestEnv(t), SetupTestEnv(t)
+	defer publisher.Cleanup()
+	defer mirror.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	user, err := publisher.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "grace", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	var cids []string
+	for _, title := range []string{"First", "Second"} {
+		article, err := publisher.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: title, Body: "Published elsewhere."}, user.ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		cids = append(cids, article.CID)
+	}
+
+	// The mirror's IPFS node can reach the publisher's content, which
+	// includes a manifest listing one CID nobody has
+	for cid, data := range publisher.IPFS.Storage {
+		mirror.IPFS.Storage[cid] = data
+	}
+	manifest, _ := json.Marshal(&domain.FeedManifest{Version: "1.0", Articles: append(cids, "QmGone"), TotalCount: 3})
+	manifestCID, _ := mirror.IPFS.Add(ctx, manifest)
+
+	namesys := &fakeNamesys{config: make(map[string]string), records: map[string]string{"/ipns/k51remote": "/ipfs/" + manifestCID}}
+	server := httptest.NewServer(namesys)
+	defer server.Close()
+	manager := ipfs.NewIPNSManager(shell.NewShell(server.URL), log)
+	manager.SetResolveTimeout(2 * time.Second)
+
+	feeds := service.NewFeedService(badger.NewFeedRepo(mirror.DB), mirror.ArticleRepo, mirror.IPFS, manager, log)
+
+	// 1. Without a seeder, seeding is refused
+	if _, err := feeds.Seed(ctx, "k51remote"); !errors.Is(err, service.ErrSeedingUnavailable) {
+		t.Fatalf("Expected ErrSeedingUnavailable, got %v", err)
+	}
+
+	store := mocks.NewMockPinStore()
+	ledger := service.NewPinLedgerService(badger.NewPinRepo(mirror.DB), store, service.PinLedgerConfig{
+		RetryInterval:     time.Hour,
+		ReconcileInterval: time.Hour,
+		MaxBackoff:        time.Hour,
+	}, log)
+	mirror.ArticleService.SetPinTracker(ledger)
+	feeds.SetSeeder(mirror.ArticleService)
+
+	// 2. Every listed article is stored and pinned; the missing one is reported
+	report, err := feeds.Seed(ctx, "k51remote")
+	if err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if report.Name != "/ipns/k51remote" || report.ManifestCID != manifestCID || report.Articles != 3 {
+		t.Errorf("Unexpected report header: %+v", report)
+	}
+	if len(report.Fetched) != 2 || report.Present != 0 || report.Pinned != 2 {
+		t.Errorf("Expected 2 articles fetched and pinned, got %+v", report)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].CID != "QmGone" {
+		t.Errorf("Expected QmGone to fail, got %+v", report.Failed)
+	}
+	for _, cid := range cids {
+		if _, err := mirror.ArticleRepo.GetByCID(ctx, cid); err != nil {
+			t.Errorf("Expected %s to be stored: %v", cid, err)
+		}
+		if !store.Pinned[cid] {
+			t.Errorf("Expected %s to be pinned", cid)
+		}
+	}
+
+	// 3. Seeding again finds everything already stored
+	report, err = feeds.Seed(ctx, "k51remote")
+	if err != nil {
+		t.Fatalf("Second seed failed: %v", err)
+	}
+	if len(report.Fetched) != 0 || report.Present != 2 {
+		t.Errorf("Expected both articles already present, got %+v", report)
+	}
+
+	// 4. Unresolvable names fail as they do for ResolveRemote
+	if _, err := feeds.Seed(ctx, "/ipns/k51missing"); !errors.Is(err, domain.ErrIPNSResolveFailed) {
+		t.Errorf("Expected ErrIPNSResolveFailed, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestFetchArticleFromNetwork(t *testing.T) {
+	reader, publisher := SetupTestEnv(t), SetupTestEnv(t)
+	defer reader.Cleanup()
+	defer publisher.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Two nodes: the publisher serves fetches from its store, the reader asks
+	a, b := newTestNode(ctx, t), newTestNode(ctx, t)
+	reader.ArticleService.SetPeerFetcher(p2p.NewArticleFetcher(a, reader.ArticleService, log))
+	p2p.NewArticleFetcher(b, publisher.ArticleService, log)
+	if err := a.GetHost().Connect(ctx, peer.AddrInfo{ID: b.GetPeerID(), Addrs: b.GetHost().Addrs()}); err != nil {
+		t.Fatalf("Failed to connect nodes: %v", err)
+	}
+
+	user, err := publisher.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "grace", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	create := func(title string) *domain.Article {
+		article, err := publisher.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: title, Body: "Only on the publisher."}, user.ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		return article
+	}
+	fromPeer, fromIPFS, tampered := create("From a peer"), create("From IPFS"), create("Tampered")
+
+	// The reader's IPFS node has one of them
+	reader.IPFS.Storage[fromIPFS.CID] = publisher.IPFS.Storage[fromIPFS.CID]
+
+	// The publisher's copy of another no longer matches its signature
+	tampered.Body = "Edited without signing."
+	if err := publisher.ArticleRepo.Update(ctx, tampered); err != nil {
+		t.Fatalf("Failed to tamper with article: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/articles/fetch", handlers.NewArticleHandler(reader.ArticleService, log).Fetch)
+
+	type fetched struct {
+		Data struct {
+			Article domain.Article `json:"article"`
+			Source  string         `json:"source"`
+			Peer    string         `json:"peer"`
+			Cached  bool           `json:"cached"`
+		}
+	}
+	fetch := func(body string) (int, fetched) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/articles/fetch", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(w, req)
+		var resp fetched
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	// 1. A peer's copy is verified, stored and served locally afterwards
+	code, resp := fetch(`{"cid":"` + fromPeer.CID + `"}`)
+	if code != http.StatusOK || resp.Data.Source != domain.FetchSourcePeer || !resp.Data.Cached {
+		t.Fatalf("Expected a cached fetch from a peer, got %d %+v", code, resp.Data)
+	}
+	if resp.Data.Peer != b.GetPeerID().String() || resp.Data.Article.ID != fromPeer.ID {
+		t.Errorf("Expected %s from %s, got %s from %s", fromPeer.ID, b.GetPeerID(), resp.Data.Article.ID, resp.Data.Peer)
+	}
+	if _, err := reader.ArticleRepo.GetByCID(ctx, fromPeer.CID); err != nil {
+		t.Errorf("Expected the fetched article to be stored: %v", err)
+	}
+	if code, resp := fetch(`{"cid":"` + fromPeer.CID + `"}`); code != http.StatusOK || resp.Data.Source != domain.FetchSourceLocal || resp.Data.Cached {
+		t.Errorf("Expected the second fetch to be local, got %d %+v", code, resp.Data)
+	}
+
+	// 2. IPFS is tried before peers
+	code, resp = fetch(`{"cid":"` + fromIPFS.CID + `"}`)
+	if code != http.StatusOK || resp.Data.Source != domain.FetchSourceIPFS || resp.Data.Article.CID != fromIPFS.CID {
+		t.Errorf("Expected a fetch from IPFS carrying its CID, got %d %+v", code, resp.Data)
+	}
+
+	// 3. Copies with a bad signature are refused and not stored
+	if code, _ := fetch(`{"cid":"` + tampered.CID + `"}`); code != http.StatusBadGateway {
+		t.Errorf("Expected 502 for a tampered copy, got %d", code)
+	}
+	if _, err := reader.ArticleRepo.GetByCID(ctx, tampered.CID); err != domain.ErrArticleNotFound {
+		t.Errorf("Expected the tampered copy not to be stored, got %v", err)
+	}
+
+	// 4. Nobody has it, or nothing was asked for
+	if code, _ := fetch(`{"cid":"QmNowhere"}`); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown CID, got %d", code)
+	}
+	if code, _ := fetch(`{}`); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a CID, got %d", code)
+	}
+
+	// 5. A local copy moderation has hidden isn't served
+	hidden, err := reader.ArticleRepo.GetByCID(ctx, fromPeer.CID)
+	if err != nil {
+		t.Fatalf("Failed to load fetched article: %v", err)
+	}
+	hidden.Visibility = domain.VisibilityHidden
+	if err := reader.ArticleRepo.Update(ctx, hidden); err != nil {
+		t.Fatalf("Failed to hide article: %v", err)
+	}
+	if code, _ := fetch(`{"cid":"` + fromPeer.CID + `"}`); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a hidden article, got %d", code)
+	}
+
+	// 6. Articles in a category the network bans aren't stored
+	banned, err := publisher.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: "Campaign", Body: "Banned on the reader's network.", Category: "politics"}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	reader.ArticleService.SetCategoryPolicy(bannedCategories{"politics"})
+	if code, _ := fetch(`{"cid":"` + banned.CID + `"}`); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for an article in a banned category, got %d", code)
+	}
+	if _, err := reader.ArticleRepo.GetByCID(ctx, banned.CID); err != domain.ErrArticleNotFound {
+		t.Errorf("Expected the banned article not to be stored, got %v", err)
+	}
+}
+
+// bannedCategories is a category policy banning a fixed list
+type bannedCategories []string
+
+func (b bannedCategories) CategoryBanned(category string) bool {
+	return slices.Contains(b, category)
+}
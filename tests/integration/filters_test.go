@@ -0,0 +1,171 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestContentFilterLists(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	filters := service.NewFilterService(badger.NewFilterListRepo(env.DB), log)
+	moderation := service.NewModerationService(badger.NewReportRepo(env.DB), badger.NewModerationCaseRepo(env.DB), env.ArticleRepo, log)
+	env.ArticleService.SetContentFilter(filters, moderation)
+
+	admin := handlers.NewAdminHandler(env.UserService, env.DB, log)
+	admin.SetFilters(filters)
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/filters", admin.ListFilters)
+	engine.POST("/filters/check", admin.CheckFilters)
+	engine.PUT("/filters/:name", admin.PutFilter)
+	engine.DELETE("/filters/:name", admin.DeleteFilter)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	// 1. Lists are validated and managed through the admin API
+	if w := do(http.MethodPut, "/filters/Bad_Name", `{"action":"reject","phrases":["x"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid name to be rejected, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/filters/broken", `{"action":"reject","patterns":["(unclosed"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid pattern to be rejected, got %d", w.Code)
+	}
+	for name, body := range map[string]string{
+		"banned":   `{"action":"reject","phrases":["forbidden word"]}`,
+		"review":   `{"action":"quarantine","patterns":["(?i)miracle cure"],"categories":["health"]}`,
+		"election": `{"action":"tag","phrases":["ballot"],"label":"elections"}`,
+	} {
+		if w := do(http.MethodPut, "/filters/"+name, body); w.Code != http.StatusOK {
+			t.Fatalf("Failed to save %s: %d %s", name, w.Code, w.Body.String())
+		}
+	}
+	var listed struct{ Data []domain.FilterList }
+	json.Unmarshal(do(http.MethodGet, "/filters", "").Body.Bytes(), &listed)
+	if len(listed.Data) != 3 || listed.Data[0].Name != "banned" {
+		t.Errorf("Expected 3 lists ordered by name, got %+v", listed.Data)
+	}
+	var checked struct{ Data domain.FilterVerdict }
+	json.Unmarshal(do(http.MethodPost, "/filters/check", `{"title":"Ballot news","body":"A FORBIDDEN WORD here"}`).Body.Bytes(), &checked)
+	if checked.Data.Action != domain.FilterReject || len(checked.Data.Matches) != 2 {
+		t.Errorf("Expected reject to win over tag, got %+v", checked.Data)
+	}
+	if verdict := filters.Check(&domain.FilterCheckRequest{Body: "unforbidden wordsmith"}); len(verdict.Matches) != 0 {
+		t.Errorf("Expected phrases to match whole words only, got %+v", verdict.Matches)
+	}
+
+	ctx := context.Background()
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "writer", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	publish := func(title, body, category string) (*domain.Article, error) {
+		return env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: title, Body: body, Category: category}, user.ID, "127.0.0.1")
+	}
+
+	// 2. Local submissions: reject fails before anything is published
+	uploads := len(env.IPFS.Storage)
+	if _, err := publish("Plain title", "This has the forbidden word in it.", "news"); err == nil {
+		t.Error("Expected a rejected submission to fail")
+	}
+	if len(env.IPFS.Storage) != uploads {
+		t.Error("Expected a rejected submission not to reach IPFS")
+	}
+
+	// 3. Tag labels the article, outside the published JSON
+	tagged, err := publish("Ballot count", "Counting continues today.", "politics")
+	if err != nil {
+		t.Fatalf("Failed to publish tagged article: %v", err)
+	}
+	if len(tagged.Labels) != 1 || tagged.Labels[0] != "elections" {
+		t.Errorf("Expected the elections label, got %v", tagged.Labels)
+	}
+	if bytes.Contains(env.IPFS.Storage[tagged.CID], []byte("elections")) {
+		t.Error("Expected labels to stay out of the IPFS copy")
+	}
+
+	// 4. Quarantine applies only in its categories and opens a moderation case
+	if article, err := publish("Miracle cure sold out", "A story about marketing.", "business"); err != nil || article.Visibility != "" {
+		t.Errorf("Expected a quarantine list limited to health to skip business, got %v (%v)", article, err)
+	}
+	held, err := publish("Miracle cure found", "Drink this daily.", "health")
+	if err != nil || held.Visibility != domain.VisibilityQuarantined {
+		t.Fatalf("Expected the article quarantined, got %+v (%v)", held, err)
+	}
+	if _, err := env.ArticleService.GetByID(ctx, held.ID); !errors.Is(err, domain.ErrArticleNotFound) {
+		t.Errorf("Expected a quarantined article not to be served, got %v", err)
+	}
+	cases, _, _ := moderation.ListCases(ctx, &domain.CaseListFilter{})
+	if len(cases) != 1 || cases[0].ArticleID != held.ID {
+		t.Fatalf("Expected a case for the quarantined article, got %+v", cases)
+	}
+
+	// 5. Dismissing the case releases the article
+	if _, err := moderation.DismissCase(ctx, cases[0].ID, "mod", &domain.CaseDecisionRequest{}); err != nil {
+		t.Fatalf("Failed to dismiss: %v", err)
+	}
+	if _, err := env.ArticleService.GetByID(ctx, held.ID); err != nil {
+		t.Errorf("Expected the released article to be served, got %v", err)
+	}
+
+	// 6. Copies from peers are screened too, ignoring their visibility and labels
+	author := SetupTestEnv(t)
+	defer author.Cleanup()
+	remote, err := author.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "remote", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register remote author: %v", err)
+	}
+	incoming := func(body string) *domain.Article {
+		article, err := author.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: "From a peer", Body: body, Category: "news"}, remote.ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create remote article: %v", err)
+		}
+		article.Visibility = domain.VisibilityUnlisted
+		article.Labels = []string{"peer-label"}
+		return article
+	}
+	rejected := incoming("Spreading the forbidden word.")
+	if err := env.ArticleService.HandleIncomingArticle(rejected); err == nil {
+		t.Error("Expected a rejected article from a peer to be refused")
+	}
+	if env.ArticleService.HasArticle(ctx, rejected.ID) {
+		t.Error("Expected a rejected article from a peer not to be stored")
+	}
+	clean := incoming("Nothing to see.")
+	if err := env.ArticleService.HandleIncomingArticle(clean); err != nil {
+		t.Fatalf("Failed to accept a clean article: %v", err)
+	}
+	if stored, err := env.ArticleRepo.GetByID(ctx, clean.ID); err != nil || stored.Visibility != "" || len(stored.Labels) != 0 {
+		t.Errorf("Expected the peer's visibility and labels dropped, got %+v (%v)", stored, err)
+	}
+
+	// 7. Deleted lists stop applying
+	if w := do(http.MethodDelete, "/filters/banned", ""); w.Code != http.StatusOK {
+		t.Errorf("Failed to delete list: %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/filters/banned", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected deleting a missing list to 404, got %d", w.Code)
+	}
+	if _, err := publish("Plain title", "This has the forbidden word in it.", "news"); err != nil {
+		t.Errorf("Expected the deleted list to stop rejecting, got %v", err)
+	}
+}
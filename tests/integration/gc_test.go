@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestGarbageCollectionRetention(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	store := mocks.NewMockPinStore()
+	pinRepo := badger.NewPinRepo(env.DB)
+	ledger := service.NewPinLedgerService(pinRepo, store, service.PinLedgerConfig{
+		RetryInterval:     time.Second,
+		ReconcileInterval: time.Hour,
+		MaxBackoff:        time.Minute,
+	}, log)
+	env.ArticleService.SetPinTracker(ledger)
+	env.ArticleService.SetDAGStore(mocks.NewMockDAGStore())
+
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "heidi",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Draft one",
+		Body:     "Body text",
+		Category: "technology",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	var current *domain.Article
+	for _, title := range []string{"Draft two", "Draft three"} {
+		time.Sleep(2 * time.Millisecond) // keep revision timestamps ordered
+		if current, err = env.ArticleService.Update(ctx, article.ID, &domain.ArticleUpdateRequest{Title: title}, user.ID); err != nil {
+			t.Fatalf("Failed to update article: %v", err)
+		}
+	}
+	if pins, _ := ledger.List(ctx, domain.PinPinned); len(pins) != 6 {
+		t.Fatalf("Expected 3 revisions' content and nodes pinned, got %d", len(pins))
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	gc := service.NewGCService(pinRepo, env.ArticleRepo, store, service.RetentionPolicy{
+		RevisionRetention: time.Millisecond,
+	}, log)
+
+	// 1. A dry run lists superseded revisions without touching them
+	report, err := gc.Run(ctx, true, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(report.Unpinned) != 4 || report.RepoGC || len(store.Pinned) != 6 {
+		t.Fatalf("Expected 4 candidates and no changes, got %+v", report)
+	}
+	for _, unpin := range report.Unpinned {
+		if unpin.Reason != domain.GCReasonSuperseded || unpin.CID == current.NodeCID || unpin.CID == current.CID {
+			t.Errorf("Unexpected candidate %+v", unpin)
+		}
+	}
+
+	// 2. A real run unpins them, keeps the current revision and reports reclaimed space
+	report, err = gc.Run(ctx, false, true)
+	if err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+	if len(report.Unpinned) != 4 || report.RemovedBlocks != 4 || report.Reclaimed != 4096 {
+		t.Errorf("Unexpected GC report: %+v", report)
+	}
+	if !store.Pinned[current.CID] || !store.Pinned[current.NodeCID] || len(store.Pinned) != 2 {
+		t.Errorf("Expected only current content and revision to stay pinned, got %v", store.Pinned)
+	}
+	if pins, _ := ledger.List(ctx, ""); len(pins) != 2 {
+		t.Errorf("Expected 2 ledger entries left, got %d", len(pins))
+	}
+	if gc.LastReport() != report {
+		t.Error("Expected LastReport to return the latest run")
+	}
+
+	// 3. Entries for articles that vanished without releasing them are orphans
+	if err := env.ArticleRepo.Delete(ctx, article.ID); err != nil {
+		t.Fatalf("Failed to delete article: %v", err)
+	}
+	report, err = gc.Run(ctx, false, false)
+	if err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+	if len(report.Unpinned) != 2 || report.Unpinned[0].Reason != domain.GCReasonOrphaned || len(store.Pinned) != 0 {
+		t.Errorf("Expected both remaining pins collected as orphans, got %+v", report)
+	}
+}
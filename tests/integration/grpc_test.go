@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/internal/grpcapi"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	newsp2pv1 "github.com/amiyamandal-dev/newsp2p/proto/newsp2p/v1"
+)
+
+func TestGRPCAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	bus := events.NewBus(log)
+	env.ArticleService.SetEventPublisher(bus)
+
+	server := grpcapi.NewServer(env.ArticleService, nil, env.JWTManager, log)
+	server.SetEventBus(bus)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+	articles := newsp2pv1.NewArticleServiceClient(conn)
+	searches := newsp2pv1.NewSearchServiceClient(conn)
+	network := newsp2pv1.NewNetworkServiceClient(conn)
+
+	code := func(err error) codes.Code {
+		return status.Code(err)
+	}
+
+	ctx := context.Background()
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "dave", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	token, _, _ := env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	authorized := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	// 1. Creating requires a token, and invalid articles are rejected
+	create := &newsp2pv1.CreateArticleRequest{Title: "Typed clients", Body: "Served over gRPC.", Tags: []string{"grpc"}, Category: "technology"}
+	if _, err := articles.CreateArticle(ctx, create); code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a token, got %v", err)
+	}
+	if _, err := articles.CreateArticle(authorized, &newsp2pv1.CreateArticleRequest{Title: "No body"}); code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a missing body, got %v", err)
+	}
+
+	created, err := articles.CreateArticle(authorized, create)
+	if err != nil {
+		t.Fatalf("CreateArticle failed: %v", err)
+	}
+	if created.Cid == "" || created.Signature == "" || created.Author != "dave" || len(created.Tags) != 1 {
+		t.Errorf("Unexpected created article %+v", created)
+	}
+
+	// 2. Lookups round-trip every field, including timestamps to the nanosecond
+	stored, err := env.ArticleService.GetByID(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("Article not stored: %v", err)
+	}
+	fetched, err := articles.GetArticle(ctx, &newsp2pv1.GetArticleRequest{Cid: created.Cid})
+	if err != nil {
+		t.Fatalf("GetArticle failed: %v", err)
+	}
+	if fetched.Title != stored.Title || !fetched.Timestamp.AsTime().Equal(stored.Timestamp) || fetched.Version != 1 {
+		t.Errorf("Fetched %+v, stored %+v", fetched, stored)
+	}
+	if _, err := articles.GetArticle(ctx, &newsp2pv1.GetArticleRequest{Id: "missing"}); code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a missing article, got %v", err)
+	}
+	if _, err := articles.GetArticle(ctx, &newsp2pv1.GetArticleRequest{}); code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without cid or id, got %v", err)
+	}
+
+	// 3. Listing pages, and unavailable or unknown services report it
+	create.Title = "Second article"
+	if _, err := articles.CreateArticle(authorized, create); err != nil {
+		t.Fatalf("CreateArticle failed: %v", err)
+	}
+	page, err := articles.ListArticles(ctx, &newsp2pv1.ListArticlesRequest{Limit: 1})
+	if err != nil {
+		t.Fatalf("ListArticles failed: %v", err)
+	}
+	if page.Total != 2 || len(page.Articles) != 1 || page.Limit != 1 {
+		t.Errorf("Expected page 1 of 2 with one article, got total %d, %d articles", page.Total, len(page.Articles))
+	}
+	if _, err := searches.Search(ctx, &newsp2pv1.SearchRequest{Query: "typed"}); code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable without a search index, got %v", err)
+	}
+	stats, err := network.GetStats(ctx, &newsp2pv1.Empty{})
+	if err != nil || stats.Status != "disabled" {
+		t.Errorf("Expected disabled network stats without a node, got %+v, %v", stats, err)
+	}
+	if err := conn.Invoke(ctx, "/newsp2p.v1.ArticleService/DeleteEverything", &newsp2pv1.Empty{}, &newsp2pv1.Empty{}); code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented for an unknown method, got %v", err)
+	}
+
+	// 4. Streaming sends the stored articles, then follows new ones
+	streamCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	stream, err := articles.StreamArticles(streamCtx, &newsp2pv1.StreamArticlesRequest{Follow: true})
+	if err != nil {
+		t.Fatalf("StreamArticles failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("Stream ended after %d stored articles: %v", i, err)
+		}
+	}
+	live, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: "Breaking", Body: "Arrives while streaming."}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	if followed, err := stream.Recv(); err != nil || followed.Id != live.ID {
+		t.Errorf("Expected the stream to follow with %s, got %v, %v", live.ID, followed, err)
+	}
+
+	// 5. Shutting down ends following streams with Unavailable
+	shutdownCtx, stop := context.WithTimeout(ctx, 5*time.Second)
+	defer stop()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+	if _, err := stream.Recv(); err == io.EOF || code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable when the server stops, got %v", err)
+	}
+}
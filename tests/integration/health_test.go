@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestHealthDegradation(t *testing.T) {
+	log, _ := logger.New("error", "text")
+
+	ipfsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/id") {
+			json.NewEncoder(w).Encode(map[string]string{"ID": "12D3KooWTest"})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ipfsServer.Close()
+
+	dir := t.TempDir()
+	db, err := badger.New(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatalf("Failed to open db: %v", err)
+	}
+	index := search.NewBleveIndex(log)
+	if err := index.Open(filepath.Join(dir, "search.bleve")); err != nil {
+		t.Fatalf("Failed to open index: %v", err)
+	}
+	defer index.Close()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	up := handlers.NewHealthHandler(db, ipfs.NewClient(ipfsServer.URL, time.Second, false, log), index, log)
+	down := handlers.NewHealthHandler(db, ipfs.NewClient("http://127.0.0.1:1", time.Second, false, log), index, log)
+	engine.GET("/up", up.Health)
+	engine.GET("/down", down.Health)
+
+	check := func(target string, wantCode int, wantStatus string) handlers.HealthReport {
+		t.Helper()
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		var report handlers.HealthReport
+		json.Unmarshal(w.Body.Bytes(), &report)
+		if w.Code != wantCode || report.Status != wantStatus {
+			t.Fatalf("%s: expected %d %s, got %d %s", target, wantCode, wantStatus, w.Code, w.Body.String())
+		}
+		return report
+	}
+
+	report := check("/up", http.StatusOK, handlers.HealthHealthy)
+	for _, name := range []string{"database", "search", "ipfs"} {
+		if c := report.Components[name]; c == nil || c.Status != handlers.HealthHealthy {
+			t.Errorf("Expected %s healthy, got %+v", name, c)
+		}
+	}
+
+	// IPFS down still serves cached reads
+	report = check("/down", http.StatusOK, handlers.HealthDegraded)
+	if c := report.Components["ipfs"]; c.Status != handlers.HealthDegraded || c.Required {
+		t.Errorf("Expected optional IPFS degraded, got %+v", c)
+	}
+
+	// Losing a required component makes the node unhealthy
+	db.Close()
+	report = check("/up", http.StatusServiceUnavailable, handlers.HealthUnhealthy)
+	if c := report.Components["database"]; c.Status != handlers.HealthUnhealthy || !c.Required {
+		t.Errorf("Expected database unhealthy, got %+v", c)
+	}
+}
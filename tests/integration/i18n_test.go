@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/i18n"
+)
+
+func TestI18nCatalogs(t *testing.T) {
+	bundle, err := i18n.Load("../../web/locales", "en")
+	if err != nil {
+		t.Fatalf("Failed to load catalogs: %v", err)
+	}
+
+	// 1. Every catalog translates every message
+	languages := bundle.Languages()
+	if len(languages) < 2 || languages[0].Code != "en" {
+		t.Fatalf("Expected English first among several languages, got %+v", languages)
+	}
+	for _, lang := range languages {
+		if missing := bundle.Missing(lang.Code); len(missing) > 0 {
+			t.Errorf("%s catalog is missing %v", lang.Code, missing)
+		}
+	}
+
+	// 2. Every message the templates and web handlers use exists
+	used := regexp.MustCompile(`(?:\{\{-?\s*T|h\.t\(c,) "([^"]+)"|\(T "([^"]+)"`)
+	files, _ := filepath.Glob("../../web/templates/*/*.html")
+	handlers, _ := filepath.Glob("../../internal/web/*.go")
+	for _, file := range append(files, handlers...) {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		for _, match := range used.FindAllStringSubmatch(string(data), -1) {
+			key := match[1] + match[2]
+			if bundle.Translate("en", key) == key {
+				t.Errorf("%s uses unknown message %q", filepath.Base(file), key)
+			}
+		}
+	}
+
+	// 3. Accept-Language picks the closest catalog, else the default
+	for header, want := range map[string]string{
+		"es-MX,es;q=0.9,en;q=0.5": "es",
+		"fr-FR,de;q=0.8":          "en",
+		"":                        "en",
+	} {
+		if got := bundle.Match(header); got != want {
+			t.Errorf("Match(%q) = %q, want %q", header, got, want)
+		}
+	}
+
+	// 4. Missing messages fall back to English, then to the key
+	if got := bundle.Translate("es", "tag.articles_other", 3); got != "3 artículos" {
+		t.Errorf("Expected a formatted Spanish message, got %q", got)
+	}
+	if got := bundle.Translate("es", "no.such.key"); got != "no.such.key" {
+		t.Errorf("Expected the key back, got %q", got)
+	}
+}
+
+func TestUserLanguagePreference(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "hablante",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	if user.Language != "" {
+		t.Fatalf("Expected no preference for a new user, got %q", user.Language)
+	}
+
+	if err := env.UserService.SetLanguage(ctx, user.ID, "es"); err != nil {
+		t.Fatalf("Failed to set language: %v", err)
+	}
+	saved, err := env.UserService.GetUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if saved.Language != "es" {
+		t.Errorf("Expected the preference to be saved, got %q", saved.Language)
+	}
+}
@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// fakeOptimizer reports fixed stats and counts optimize calls
+type fakeOptimizer struct {
+	stats     search.IndexStats
+	optimized int
+}
+
+func (f *fakeOptimizer) Stats() (*search.IndexStats, error) {
+	stats := f.stats
+	return &stats, nil
+}
+
+func (f *fakeOptimizer) Optimize(ctx context.Context) (*search.OptimizeReport, error) {
+	f.optimized++
+	return &search.OptimizeReport{Before: &f.stats, After: &search.IndexStats{FileSegments: 1}}, nil
+}
+
+func TestIndexOptimize(t *testing.T) {
+	index := setupSearchIndex(t)
+	ctx := context.Background()
+
+	for i := 0; i < 50; i++ {
+		article := &domain.Article{ID: fmt.Sprintf("opt-%d", i), Title: "Segment test", Body: "body", Timestamp: time.Now()}
+		if err := index.IndexArticle(ctx, article); err != nil {
+			t.Fatalf("IndexArticle failed: %v", err)
+		}
+	}
+	for i := 0; i < 25; i++ {
+		if err := index.DeleteArticle(ctx, fmt.Sprintf("opt-%d", i)); err != nil {
+			t.Fatalf("DeleteArticle failed: %v", err)
+		}
+	}
+
+	report, err := index.Optimize(ctx)
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	if report.After.Documents != 25 {
+		t.Errorf("Expected 25 documents after optimize, got %d", report.After.Documents)
+	}
+	if report.After.FileSegments > 1 {
+		t.Errorf("Expected at most one file segment after optimize, got %d", report.After.FileSegments)
+	}
+}
+
+func TestIndexOptimizeAlongsideWrites(t *testing.T) {
+	index := setupSearchIndex(t)
+	ctx := context.Background()
+
+	for i := 0; i < 50; i++ {
+		article := &domain.Article{ID: fmt.Sprintf("merge-%d", i), Title: "Segment test", Body: "body", Timestamp: time.Now()}
+		if err := index.IndexArticle(ctx, article); err != nil {
+			t.Fatalf("IndexArticle failed: %v", err)
+		}
+	}
+
+	// Writes made while the merge runs land in the index
+	done := make(chan error, 1)
+	go func() {
+		_, err := index.Optimize(ctx)
+		done <- err
+	}()
+	for i := 50; i < 100; i++ {
+		article := &domain.Article{ID: fmt.Sprintf("merge-%d", i), Title: "Segment test", Body: "body", Timestamp: time.Now()}
+		if err := index.IndexArticle(ctx, article); err != nil {
+			t.Fatalf("IndexArticle during optimize failed: %v", err)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+
+	count, err := index.Count()
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 100 {
+		t.Errorf("Expected 100 documents, got %d", count)
+	}
+}
+
+func TestIndexMaintenanceSchedule(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	ctx := context.Background()
+
+	optimizer := &fakeOptimizer{stats: search.IndexStats{FileSegments: 3}}
+	maintenance := service.NewIndexMaintenanceService(optimizer, service.IndexMaintenanceConfig{
+		WindowStart: 0,
+		WindowEnd:   24 * time.Hour, // always in the window
+		MinSegments: 5,
+	}, log)
+
+	// Below thresholds: checked but not optimized
+	if report := maintenance.RunScheduled(ctx); report != nil || optimizer.optimized != 0 {
+		t.Fatalf("Expected no optimization for an unfragmented index")
+	}
+
+	optimizer.stats.FileSegments = 12
+	maintenance = service.NewIndexMaintenanceService(optimizer, service.IndexMaintenanceConfig{
+		WindowStart: 0,
+		WindowEnd:   24 * time.Hour,
+		MinSegments: 5,
+	}, log)
+
+	if report := maintenance.RunScheduled(ctx); report == nil || optimizer.optimized != 1 {
+		t.Fatalf("Expected a scheduled optimization, got %d runs", optimizer.optimized)
+	}
+	// At most once per day
+	if report := maintenance.RunScheduled(ctx); report != nil || optimizer.optimized != 1 {
+		t.Errorf("Expected no second optimization the same day, got %d runs", optimizer.optimized)
+	}
+	if maintenance.LastReport() == nil {
+		t.Error("Expected last report to be recorded")
+	}
+
+	// Outside the window nothing happens
+	closed := service.NewIndexMaintenanceService(optimizer, service.IndexMaintenanceConfig{}, log)
+	if report := closed.RunScheduled(ctx); report != nil {
+		t.Error("Expected no optimization outside the window")
+	}
+}
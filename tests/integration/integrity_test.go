@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	badgerrepo "github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestIntegrityVerification(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "dave",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Integrity",
+		Body:     "Checked content",
+		Category: "technology",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	integrity := service.NewIntegrityService(
+		env.ArticleRepo,
+		badgerrepo.NewArticleRepo(env.DB),
+		nil, // IPFS hashing not available in tests
+		auth.NewArticleSigner(),
+		log,
+	)
+
+	// 1. A freshly created article is clean
+	report, err := integrity.Verify(ctx, false)
+	if err != nil {
+		t.Fatalf("Verification failed: %v", err)
+	}
+	if report.ArticlesChecked != 1 || len(report.Issues) != 0 {
+		t.Fatalf("Expected 1 clean article, got %d checked and issues %+v", report.ArticlesChecked, report.Issues)
+	}
+
+	// 2. Break the CID index and plant a dangling one
+	err = env.DB.Update(func(txn *badger.Txn) error {
+		if err := txn.Delete([]byte("article:cid:" + article.CID)); err != nil {
+			return err
+		}
+		return txn.Set([]byte("article:cid:orphan"), []byte("missing-article"))
+	})
+	if err != nil {
+		t.Fatalf("Failed to corrupt indexes: %v", err)
+	}
+
+	report, err = integrity.Verify(ctx, true)
+	if err != nil {
+		t.Fatalf("Verification failed: %v", err)
+	}
+	kinds := map[string]bool{}
+	for _, issue := range report.Issues {
+		kinds[issue.Kind] = true
+		if !issue.Repaired {
+			t.Errorf("Expected issue to be repaired: %+v", issue)
+		}
+	}
+	if !kinds[domain.IssueMissingIndex] || !kinds[domain.IssueDanglingIndex] {
+		t.Errorf("Expected missing and dangling index issues, got %+v", report.Issues)
+	}
+
+	// 3. After repair the article resolves by CID again and the store is clean
+	if _, err := env.ArticleRepo.GetByCID(ctx, article.CID); err != nil {
+		t.Errorf("Expected CID index to be rebuilt: %v", err)
+	}
+	report, _ = integrity.Verify(ctx, false)
+	if len(report.Issues) != 0 {
+		t.Errorf("Expected no issues after repair, got %+v", report.Issues)
+	}
+
+	// 4. Edited and labelled articles still hash to their CID
+	article, err = env.ArticleService.Update(ctx, article.ID, &domain.ArticleUpdateRequest{
+		Title: "Integrity, revised",
+	}, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to update article: %v", err)
+	}
+	article.Labels = []string{"spam"}
+	article.Classifications = []domain.Classification{{Label: "politics", Score: 0.9}}
+	if err := env.ArticleRepo.Update(ctx, article); err != nil {
+		t.Fatalf("Failed to label article: %v", err)
+	}
+	withCIDs := service.NewIntegrityService(
+		env.ArticleRepo,
+		badgerrepo.NewArticleRepo(env.DB),
+		env.IPFS,
+		auth.NewArticleSigner(),
+		log,
+	)
+	report, _ = withCIDs.Verify(ctx, false)
+	if report.CIDsChecked != 1 || len(report.Issues) != 0 {
+		t.Errorf("Expected 1 clean CID, got %d checked and issues %+v", report.CIDsChecked, report.Issues)
+	}
+
+	// 5. Tampered content fails signature verification
+	article.Body = "Tampered content"
+	if err := env.ArticleRepo.Update(ctx, article); err != nil {
+		t.Fatalf("Failed to update article: %v", err)
+	}
+	report, _ = integrity.Verify(ctx, false)
+	if len(report.Issues) != 1 || report.Issues[0].Kind != domain.IssueInvalidSignature {
+		t.Errorf("Expected an invalid signature issue, got %+v", report.Issues)
+	}
+}
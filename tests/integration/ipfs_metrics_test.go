@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	shell "github.com/ipfs/go-ipfs-api"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestIPFSOperationTimeoutsAndMetrics(t *testing.T) {
+	block := []byte("slow block")
+	prefix := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: 0x12, MhLength: -1}
+	id, _ := prefix.Sum(block)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/api/v0/") {
+		case "block/get":
+			// Hang until the test is done or the client gives up
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			w.Write(block)
+		case "pin/add":
+			if r.URL.Query().Get("arg") == "QmBroken" {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]interface{}{"Message": "pin failed", "Code": 0, "Type": "error"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string][]string{"Pins": {r.URL.Query().Get("arg")}})
+		case "name/publish":
+			json.NewEncoder(w).Encode(map[string]string{"Name": "k51self", "Value": "/ipfs/" + r.URL.Query().Get("arg")})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	client := ipfs.NewClient(server.URL, time.Minute, false, log)
+	client.SetTimeouts(ipfs.Timeouts{Cat: 100 * time.Millisecond, Pin: 5 * time.Second})
+
+	// 1. A stalled read fails at the cat timeout, not the one-minute default
+	start := time.Now()
+	if _, err := client.Cat(ctx, id.String()); err == nil {
+		t.Fatal("Expected the stalled cat to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Cat took %v; expected the 100ms cat timeout to apply", elapsed)
+	}
+
+	// 2. Pins are timed and counted separately
+	if err := client.Pin(ctx, "QmGood"); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	if err := client.Pin(ctx, "QmBroken"); err == nil {
+		t.Fatal("Expected pin of QmBroken to fail")
+	}
+
+	// 3. IPNS operations land in the same metrics
+	manager := ipfs.NewIPNSManager(shell.NewShell(server.URL), log)
+	manager.SetMetrics(client.Metrics())
+	if path, err := manager.Publish(ctx, "QmManifest", "feed-global"); err != nil || path != "/ipns/k51self" {
+		t.Fatalf("Publish returned %q (%v)", path, err)
+	}
+
+	stats := client.Metrics().Snapshot()
+	if cat := stats[ipfs.OpCat]; cat.Count != 1 || cat.Errors != 1 || cat.Timeouts != 1 {
+		t.Errorf("Expected one timed-out cat, got %+v", cat)
+	}
+	if pin := stats[ipfs.OpPin]; pin.Count != 2 || pin.Errors != 1 || pin.Timeouts != 0 {
+		t.Errorf("Expected two pins with one error, got %+v", pin)
+	}
+	if pub := stats[ipfs.OpIPNSPublish]; pub.Count != 1 || pub.Errors != 0 {
+		t.Errorf("Expected one successful publish, got %+v", pub)
+	}
+
+	// 4. Buckets are cumulative: the largest bound holds every operation
+	pin := stats[ipfs.OpPin]
+	if last := pin.Buckets[len(pin.Buckets)-1]; last.Count != pin.Count {
+		t.Errorf("Expected the last bucket to count all %d pins, got %d", pin.Count, last.Count)
+	}
+	if pin.LatencySum <= 0 || pin.LatencyMax <= 0 {
+		t.Errorf("Expected latency to be recorded, got %+v", pin)
+	}
+
+	// 5. A caller's own deadline is reported as a timeout too
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Cat(short, id.String()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded from cat, got %v", err)
+	}
+	if cat := client.Metrics().Snapshot()[ipfs.OpCat]; cat.Timeouts != 2 {
+		t.Errorf("Expected the caller deadline to count as a timeout, got %+v", cat)
+	}
+}
@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	shell "github.com/ipfs/go-ipfs-api"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// fakeNamesys answers the kubo name/* and config endpoints the IPNS manager uses
+type fakeNamesys struct {
+	mu      sync.Mutex
+	pubsub  bool
+	config  map[string]string
+	records map[string]string // /ipns/name -> /ipfs/cid
+}
+
+func (f *fakeNamesys) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	args := r.URL.Query()["arg"]
+	switch r.URL.Path {
+	case "/api/v0/name/pubsub/state":
+		json.NewEncoder(w).Encode(map[string]bool{"Enabled": f.pubsub})
+	case "/api/v0/config":
+		f.config[args[0]] = args[1]
+		json.NewEncoder(w).Encode(map[string]string{"Key": args[0], "Value": args[1]})
+	case "/api/v0/name/resolve":
+		if r.URL.Query().Get("dht-timeout") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		path, ok := f.records[args[0]]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{"Message": "could not resolve name", "Code": 0, "Type": "error"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"Path": path})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestIPNSPubsubRemoteFeed(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	namesys := &fakeNamesys{config: make(map[string]string), records: make(map[string]string)}
+	server := httptest.NewServer(namesys)
+	defer server.Close()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	manager := ipfs.NewIPNSManager(shell.NewShell(server.URL), log)
+	manager.SetResolveTimeout(2 * time.Second)
+
+	// 1. A daemon without pubsub gets it switched on in its config
+	active, err := manager.EnablePubsub(ctx)
+	if err != nil || active {
+		t.Fatalf("Expected pubsub to need a restart, got %v (%v)", active, err)
+	}
+	if namesys.config["Ipns.UsePubsub"] != "true" {
+		t.Errorf("Expected Ipns.UsePubsub to be set, got %v", namesys.config)
+	}
+
+	namesys.mu.Lock()
+	namesys.pubsub = true
+	namesys.mu.Unlock()
+	if active, err := manager.EnablePubsub(ctx); err != nil || !active || !manager.UsingPubsub() {
+		t.Fatalf("Expected pubsub active after restart, got %v (%v)", active, err)
+	}
+
+	// 2. Another node's feed resolves to its manifest
+	manifest, _ := json.Marshal(&domain.FeedManifest{Version: "1.0", Articles: []string{"QmA", "QmB"}, TotalCount: 2})
+	manifestCID, _ := env.IPFS.Add(ctx, manifest)
+	namesys.records["/ipns/k51remote"] = "/ipfs/" + manifestCID
+
+	feeds := service.NewFeedService(badger.NewFeedRepo(env.DB), env.ArticleRepo, env.IPFS, manager, log)
+	remote, err := feeds.ResolveRemote(ctx, "k51remote")
+	if err != nil {
+		t.Fatalf("ResolveRemote failed: %v", err)
+	}
+	if remote.Name != "/ipns/k51remote" || remote.CID != manifestCID || remote.Manifest.TotalCount != 2 || !remote.Pubsub {
+		t.Errorf("Unexpected remote feed: %+v", remote)
+	}
+
+	// 3. Bad names, unknown names and non-manifests are told apart
+	if _, err := feeds.ResolveRemote(ctx, "../etc/passwd"); !errors.Is(err, domain.ErrInvalidIPNSName) {
+		t.Errorf("Expected ErrInvalidIPNSName, got %v", err)
+	}
+	if _, err := feeds.ResolveRemote(ctx, "/ipns/k51missing"); !errors.Is(err, domain.ErrIPNSResolveFailed) {
+		t.Errorf("Expected ErrIPNSResolveFailed, got %v", err)
+	}
+	otherCID, _ := env.IPFS.Add(ctx, []byte(`{"title":"not a feed"}`))
+	namesys.records["/ipns/k51article"] = "/ipfs/" + otherCID
+	if _, err := feeds.ResolveRemote(ctx, "k51article"); !errors.Is(err, service.ErrInvalidManifest) {
+		t.Errorf("Expected ErrInvalidManifest, got %v", err)
+	}
+}
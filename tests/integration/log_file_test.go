@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestLogFileRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "node.log")
+
+	log, err := logger.NewWithFile("info", "text", logger.FileOptions{
+		Path:       path,
+		MaxSizeMB:  1,
+		MaxBackups: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// 1. Entries reach the file, filtered by level and without colour codes
+	log.Debug("not written")
+	log.Info("node started", "port", 8080)
+	log.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the log file to be created: %v", err)
+	}
+	text := string(data)
+	if !strings.Contains(text, "INFO") || !strings.Contains(text, "node started") || !strings.Contains(text, `"port": 8080`) {
+		t.Errorf("Unexpected log file contents: %q", text)
+	}
+	if strings.Contains(text, "not written") || strings.Contains(text, "\x1b[") {
+		t.Errorf("Expected no debug entries or colour codes: %q", text)
+	}
+
+	// 2. Past max_size_mb the file is rotated
+	padding := strings.Repeat("x", 64<<10)
+	for i := 0; i < 24; i++ {
+		log.Info("filler", "padding", padding)
+	}
+	log.Sync()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "logs", "node-*.log"))
+	if len(matches) != 1 {
+		t.Errorf("Expected one rotated file, got %v", matches)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > 1<<20 {
+		t.Errorf("Expected the current file to stay under 1MB, got %v %v", info, err)
+	}
+}
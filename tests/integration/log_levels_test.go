@@ -0,0 +1,123 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	badgerrepo "github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestRuntimeLogLevels(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	path := filepath.Join(t.TempDir(), "node.log")
+	log, err := logger.NewWithFile("info", "json", logger.FileOptions{Path: path, MaxSizeMB: 10})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	syncLog := log.WithComponent("p2p-sync")
+	searchLog := log.WithComponent("search-service")
+
+	audit := service.NewAuditService(badgerrepo.NewAuditRepo(env.DB), log)
+	admin := handlers.NewAdminHandler(env.UserService, env.DB, log)
+	admin.SetAuditLog(audit)
+
+	user, err := env.UserService.Register(context.Background(), &domain.UserRegisterRequest{Username: "root", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	token, _, _ := env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	group := engine.Group("/admin", middleware.AuthMiddleware(env.JWTManager), middleware.AdminMiddleware([]string{"root"}))
+	group.GET("/log-levels", admin.LogLevels)
+	group.PUT("/log-levels", admin.SetLogLevels)
+	do := func(method, body string) (*httptest.ResponseRecorder, logger.LevelSnapshot) {
+		req := httptest.NewRequest(method, "/admin/log-levels", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		var resp struct{ Data logger.LevelSnapshot }
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp.Data
+	}
+	written := func(msg string) bool {
+		log.Sync()
+		data, _ := os.ReadFile(path)
+		return bytes.Contains(data, []byte(msg))
+	}
+
+	// 1. Everything starts at the default level
+	syncLog.Debug("sync debug before")
+	if written("sync debug before") {
+		t.Error("Expected debug entries to be dropped at info")
+	}
+	w, levels := do(http.MethodGet, "")
+	if w.Code != http.StatusOK || levels.Level != "info" || len(levels.Components) != 0 {
+		t.Fatalf("Unexpected levels: %d %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(strings.Join(levels.Known, ","), "p2p-sync") {
+		t.Errorf("Expected p2p-sync among known components, got %v", levels.Known)
+	}
+
+	// 2. One component goes to debug; loggers already handed out follow
+	w, levels = do(http.MethodPut, `{"components": {"p2p-sync": "debug"}}`)
+	if w.Code != http.StatusOK || levels.Components["p2p-sync"] != "debug" || levels.Level != "info" {
+		t.Fatalf("Unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	syncLog.Debug("sync debug after")
+	syncLog.Ctx(context.Background()).Debug("sync debug derived")
+	searchLog.Debug("search debug after")
+	if !written("sync debug after") || !written("sync debug derived") {
+		t.Error("Expected p2p-sync debug entries once its level is lowered")
+	}
+	if written("search debug after") {
+		t.Error("Expected other components to stay at info")
+	}
+
+	// 3. An invalid level changes nothing
+	if w, _ := do(http.MethodPut, `{"level": "error", "components": {"search-service": "loud"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid level, got %d", w.Code)
+	}
+	if _, levels = do(http.MethodGet, ""); levels.Level != "info" || len(levels.Components) != 1 {
+		t.Errorf("Expected levels unchanged, got %+v", levels)
+	}
+
+	// 4. The default can be raised and an override removed
+	_, levels = do(http.MethodPut, `{"level": "warn", "components": {"p2p-sync": ""}}`)
+	if levels.Level != "warn" || len(levels.Components) != 0 {
+		t.Errorf("Unexpected levels: %+v", levels)
+	}
+	syncLog.Info("sync info after reset")
+	if written("sync info after reset") {
+		t.Error("Expected p2p-sync to follow the default once its override is removed")
+	}
+
+	// 5. Each change is audited
+	entries, _, _ := audit.List(context.Background(), &domain.AuditListFilter{Action: domain.AuditConfigChange})
+	var got []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		got = append(got, entries[i].Target+" "+entries[i].Details["old"]+"->"+entries[i].Details["new"])
+	}
+	want := "logging.components.p2p-sync ->debug,logging.level info->warn,logging.components.p2p-sync debug->"
+	if strings.Join(got, ",") != want || entries[0].Actor != "root" {
+		t.Errorf("Unexpected audit entries: %v", got)
+	}
+}
@@ -0,0 +1,241 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/media"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+// wavFile builds a 16-bit mono PCM file of the given length
+func wavFile(sampleRate, seconds int) []byte {
+	data := make([]byte, sampleRate*2*seconds)
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+len(data)))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, []uint32{16})
+	binary.Write(&b, binary.LittleEndian, []uint16{1, 1}) // PCM, mono
+	binary.Write(&b, binary.LittleEndian, []uint32{uint32(sampleRate), uint32(sampleRate * 2)})
+	binary.Write(&b, binary.LittleEndian, []uint16{2, 16})
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+// mp4File builds an ISO media file whose movie header sits after the media
+// data, the way recorders usually write it
+func mp4File(timescale, duration uint32) []byte {
+	box := func(name string, payload []byte) []byte {
+		out := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+		return append(append(out, name...), payload...)
+	}
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:16], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:20], duration)
+
+	var b bytes.Buffer
+	b.Write(box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2")))
+	b.Write(box("mdat", make([]byte, 4096)))
+	b.Write(box("moov", box("mvhd", mvhd)))
+	return b.Bytes()
+}
+
+// mp3File builds a constant bitrate (128 kbit/s, 44.1 kHz) stream of size
+// bytes behind an empty ID3v2 tag
+func mp3File(size int) []byte {
+	out := make([]byte, size)
+	copy(out, "ID3\x04\x00\x00\x00\x00\x00\x00")
+	copy(out[10:], []byte{0xFF, 0xFB, 0x90, 0x64})
+	return out
+}
+
+func TestMediaDuration(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want float64
+	}{
+		{"wav", wavFile(8000, 3), 3},
+		{"mp4", mp4File(1000, 90500), 90.5},
+		{"mp3", mp3File(10 + 16000), 1},
+		{"unknown", []byte("just some text, not a recording"), 0},
+	} {
+		got := media.Duration(bytes.NewReader(tc.data), int64(len(tc.data)))
+		if math.Abs(got-tc.want) > 0.001 {
+			t.Errorf("%s: expected duration %.3f, got %.3f", tc.name, tc.want, got)
+		}
+	}
+}
+
+// transcodeFunc adapts a function to service.Transcoder
+type transcodeFunc func(ctx context.Context, src, mimeType, outDir string) error
+
+func (f transcodeFunc) Transcode(ctx context.Context, src, mimeType, outDir string) error {
+	return f(ctx, src, mimeType, outDir)
+}
+
+// waitForMedia polls until transcoding of cid has finished
+func waitForMedia(t *testing.T, uploads *service.UploadService, cid string) *domain.Media {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		record, err := uploads.Media(context.Background(), cid)
+		if err != nil {
+			t.Fatalf("Failed to get media: %v", err)
+		}
+		if record.State != domain.MediaProcessing {
+			return record
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Transcoding of %s did not finish", cid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMediaUploadAndAttachment(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	ctx := context.Background()
+	spoolDir := t.TempDir()
+
+	mediaRepo := badger.NewMediaRepo(env.DB)
+	uploads := service.NewUploadService(&mocks.MockMediaStore{}, 4096, func(mimeType string) (int64, bool) {
+		return 1 << 20, domain.IsTimedMedia(mimeType) || mimeType == "image/png"
+	}, log)
+	uploads.SetMediaCatalog(mediaRepo, spoolDir)
+
+	// The transcoder sees the spooled original and its type; each file it
+	// writes becomes a rendition
+	var seen string
+	uploads.SetTranscoder(transcodeFunc(func(ctx context.Context, src, mimeType, outDir string) error {
+		if mimeType == "video/mp4" {
+			return errors.New("encoder crashed")
+		}
+		original, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		seen = mimeType
+		return os.WriteFile(filepath.Join(outDir, "podcast.m4a"), original[:1000], 0o644)
+	}), time.Minute)
+
+	// 1. Audio is probed, recorded and transcoded in the background
+	wav := wavFile(8000, 2)
+	result, err := uploads.Upload(ctx, "alice", "upload-audio", bytes.NewReader(wav), "audio/wav", 0)
+	if err != nil {
+		t.Fatalf("Audio upload failed: %v", err)
+	}
+	if result.Media == nil || result.Media.Duration != 2 || result.Media.Size != int64(len(wav)) {
+		t.Fatalf("Expected a 2s media record with the upload size, got %+v", result.Media)
+	}
+	if result.Media.State != domain.MediaProcessing {
+		t.Errorf("Expected the upload to be processing, got %s", result.Media.State)
+	}
+
+	podcast := waitForMedia(t, uploads, result.CID)
+	if podcast.State != domain.MediaReady || seen != "audio/wav" {
+		t.Fatalf("Expected transcoding of audio/wav to succeed, got state %s (saw %q)", podcast.State, seen)
+	}
+	if len(podcast.Renditions) != 1 || podcast.Renditions[0].MimeType != "audio/mp4" || podcast.Renditions[0].Size != 1000 {
+		t.Errorf("Expected one 1000-byte audio/mp4 rendition, got %+v", podcast.Renditions)
+	}
+	if podcast.Owner != "alice" {
+		t.Errorf("Expected owner alice, got %s", podcast.Owner)
+	}
+
+	// 2. A failed transcode keeps the original usable
+	result, err = uploads.Upload(ctx, "alice", "upload-video", bytes.NewReader(mp4File(600, 3000)), "video/mp4", 0)
+	if err != nil {
+		t.Fatalf("Video upload failed: %v", err)
+	}
+	video := waitForMedia(t, uploads, result.CID)
+	if video.State != domain.MediaFailed || video.Error == "" || video.Duration != 5 {
+		t.Errorf("Expected a failed 5s video with an error, got %+v", video)
+	}
+
+	// 3. Images aren't catalogued, and spool files are cleaned up
+	result, err = uploads.Upload(ctx, "alice", "upload-image", bytes.NewReader([]byte("\x89PNG\r\n\x1a\n")), "image/png", 0)
+	if err != nil {
+		t.Fatalf("Image upload failed: %v", err)
+	}
+	if result.Media != nil {
+		t.Errorf("Expected no media record for an image")
+	}
+	if _, err := uploads.Media(ctx, result.CID); !errors.Is(err, domain.ErrMediaNotFound) {
+		t.Errorf("Expected ErrMediaNotFound for an image, got %v", err)
+	}
+	if entries, _ := os.ReadDir(spoolDir); len(entries) != 0 {
+		t.Errorf("Expected the spool directory to be empty, found %d entries", len(entries))
+	}
+
+	// 4. Articles attach catalogued media by CID and sign the metadata
+	env.ArticleService.SetMediaCatalog(mediaRepo)
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "alice", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Episode 1",
+		Body:  "This week's podcast.",
+		Media: []string{podcast.CID, podcast.CID},
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article with media: %v", err)
+	}
+	if len(article.Media) != 1 || article.Media[0] != podcast.Attachment() {
+		t.Fatalf("Expected the podcast attached once, got %+v", article.Media)
+	}
+	if valid, err := env.ArticleService.VerifySignature(ctx, article.CID); err != nil || !valid {
+		t.Fatalf("Expected the article to verify, got %v (%v)", valid, err)
+	}
+
+	// Changing the attached media breaks the signature
+	tampered := *article
+	tampered.Media = []domain.MediaAttachment{video.Attachment()}
+	if err := auth.NewArticleSigner().VerifyArticle(&tampered); err == nil {
+		t.Error("Expected an article with swapped media to be rejected")
+	}
+
+	var validationErr *domain.ValidationError
+	if _, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Episode 2",
+		Body:  "Missing audio.",
+		Media: []string{"bafyUnknown"},
+	}, user.ID, "127.0.0.1"); !errors.As(err, &validationErr) || validationErr.Field != "media" {
+		t.Errorf("Expected a validation error for an unknown media CID, got %v", err)
+	}
+
+	// 5. Updates replace the attachments; an empty list removes them
+	updated, err := env.ArticleService.Update(ctx, article.ID, &domain.ArticleUpdateRequest{
+		Media: []string{video.CID},
+	}, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to update media: %v", err)
+	}
+	if len(updated.Media) != 1 || updated.Media[0].CID != video.CID {
+		t.Errorf("Expected the video attached after the update, got %+v", updated.Media)
+	}
+	updated, err = env.ArticleService.Update(ctx, article.ID, &domain.ArticleUpdateRequest{Media: []string{}}, user.ID)
+	if err != nil || len(updated.Media) != 0 {
+		t.Errorf("Expected the attachments removed, got %+v (%v)", updated.Media, err)
+	}
+}
@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/metrics"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := metrics.New()
+
+	// HTTP requests are labelled by route pattern
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.MetricsMiddleware(m))
+	engine.GET("/articles/:cid", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	engine.GET("/metrics", gin.WrapH(m.Handler()))
+	for _, path := range []string{"/articles/QmOne", "/articles/QmTwo", "/nowhere"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Repository calls, with missing records apart from failures
+	repo := repository.NewInstrumentedArticleRepo(env.ArticleRepo, m)
+	repo.GetByID(ctx, "missing")
+	repo.List(ctx, &domain.ArticleListFilter{Page: 1, Limit: 10})
+
+	// IPFS operations, read from the client's own counters
+	ipfsStats := ipfs.NewMetrics()
+	ipfsStats.Observe(ipfs.OpAdd, 20*time.Millisecond, nil)
+	ipfsStats.Observe(ipfs.OpAdd, 2*time.Second, context.DeadlineExceeded)
+	ipfsStats.Observe(ipfs.OpPin, time.Second, errors.New("pin failed"))
+	m.RegisterIPFS(ipfsStats)
+
+	// Searches and suggestions
+	index := setupSearchIndex(t, &domain.Article{ID: "indexed", Title: "Budget talks", Body: "Indexed for the document count."})
+	searchService := service.NewSearchService(index, env.ArticleRepo, 2, log)
+	searchService.SetObserver(m)
+	m.RegisterSearchIndex(index)
+
+	// Pin ledger entries by status
+	store := mocks.NewMockPinStore()
+	ledger := service.NewPinLedgerService(badger.NewPinRepo(env.DB), store, service.PinLedgerConfig{
+		RetryInterval:     time.Hour,
+		ReconcileInterval: time.Hour,
+		MaxBackoff:        time.Hour,
+	}, log)
+	ledger.Track(ctx, "QmPinned", "article-1")
+	m.RegisterPinLedger(ledger)
+	searchService.Search(ctx, &search.SearchQuery{Query: "budget"})
+	searchService.Suggest(ctx, "bud", 5)
+
+	// Sync outcomes
+	m.ObserveSync(domain.SyncProgress{PeerID: "peer-a", Received: 3, New: 2})
+	m.ObserveSync(domain.SyncProgress{PeerID: "peer-b", Error: "stream reset"})
+
+	// Pubsub messages between two nodes
+	a, b := newTestNode(ctx, t), newTestNode(ctx, t)
+	m.RegisterPubsub(a)
+	m.RegisterNetwork(a)
+	if err := a.GetHost().Connect(ctx, peer.AddrInfo{ID: b.GetPeerID(), Addrs: b.GetHost().Addrs()}); err != nil {
+		t.Fatalf("Failed to connect nodes: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for a.PubsubStats()["news/test"].Received == 0 && time.Now().Before(deadline) {
+		b.Publish("news/test", []byte("hello"))
+		time.Sleep(100 * time.Millisecond)
+	}
+	a.Publish("news/test", []byte("hi"))
+	for a.PubsubStats()["news/test"].Published == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	scrape := string(body)
+
+	for _, want := range []string{
+		`newsp2p_http_requests_total{method="GET",route="/articles/:cid",status="404"} 2`,
+		`newsp2p_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`newsp2p_repository_operation_duration_seconds_count{op="get_by_id",repo="article",result="not_found"} 1`,
+		`newsp2p_repository_operation_duration_seconds_count{op="list",repo="article",result="ok"} 1`,
+		`newsp2p_ipfs_operations_total{op="add",result="ok"} 1`,
+		`newsp2p_ipfs_operations_total{op="add",result="timeout"} 1`,
+		`newsp2p_ipfs_operations_total{op="pin",result="error"} 1`,
+		`newsp2p_ipfs_operation_duration_seconds_count{op="add"} 2`,
+		`newsp2p_search_duration_seconds_count{kind="local",result="ok"} 1`,
+		`newsp2p_search_duration_seconds_count{kind="suggest",result="ok"} 1`,
+		`newsp2p_sync_peer_syncs_total{result="ok"} 1`,
+		`newsp2p_sync_peer_syncs_total{result="error"} 1`,
+		`newsp2p_sync_articles_total{kind="new"} 2`,
+		`newsp2p_pubsub_messages_total{event="published",topic="news/test"} 1`,
+		`newsp2p_p2p_peers 1`,
+		`newsp2p_p2p_dht_routing_table_peers`,
+		`newsp2p_p2p_bandwidth_bytes_total{direction="in"}`,
+		`newsp2p_pubsub_topic_peers{topic="news/test"} 1`,
+		`newsp2p_pins_ledger_entries{status="pinned"} 1`,
+		`newsp2p_pins_ledger_entries{status="failed"} 0`,
+		`newsp2p_search_index_documents 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(scrape, want) {
+			t.Errorf("Expected %s in the scrape", want)
+		}
+	}
+	if !strings.Contains(scrape, `newsp2p_pubsub_messages_total{event="received",topic="news/test"}`) ||
+		strings.Contains(scrape, `newsp2p_pubsub_messages_total{event="received",topic="news/test"} 0`) {
+		t.Errorf("Expected received pubsub messages to be counted")
+	}
+}
@@ -0,0 +1,347 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestModerationWorkflow(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	moderation := service.NewModerationService(badger.NewReportRepo(env.DB), badger.NewModerationCaseRepo(env.DB), env.ArticleRepo, log)
+	broadcaster := mocks.NewMockModerationBroadcaster()
+	moderation.SetBroadcaster(broadcaster)
+	h := handlers.NewModerationHandler(moderation, log)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	authed := engine.Group("", middleware.AuthMiddleware(env.JWTManager))
+	authed.POST("/articles/:cid/report", h.Report)
+	queue := authed.Group("/moderation", middleware.ModeratorMiddleware([]string{"root"}, []string{"mod"}))
+	queue.GET("/reports", h.List)
+	queue.GET("/reports/:id", h.Get)
+	queue.POST("/reports/:id/resolve", h.Resolve)
+	queue.POST("/reports/:id/dismiss", h.Dismiss)
+
+	ctx := context.Background()
+	tokens := make(map[string]string)
+	users := make(map[string]*domain.UserResponse)
+	for _, name := range []string{"author", "reader", "mod"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		users[name] = user
+		tokens[name], _, _ = env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	}
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Disputed claims", Body: "Body text long enough to publish.", Category: "politics",
+	}, users["author"].ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", "Bearer "+tokens[user])
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) domain.Report {
+		var resp struct{ Data domain.Report }
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode report: %v", err)
+		}
+		return resp.Data
+	}
+
+	// 1. Readers report by article ID or CID, once per open report
+	if w := do("reader", http.MethodPost, "/articles/"+article.ID+"/report", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a reason, got %d", w.Code)
+	}
+	if w := do("reader", http.MethodPost, "/articles/QmMissing/report", `{"reason":"spam"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown article, got %d", w.Code)
+	}
+	w := do("reader", http.MethodPost, "/articles/"+article.ID+"/report", `{"reason":"misleading headline"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Report failed: %d %s", w.Code, w.Body.String())
+	}
+	first := decode(w)
+	if first.ArticleCID != article.CID || first.Status != domain.ReportOpen || first.Source != domain.ReportSourceLocal {
+		t.Errorf("Unexpected report: %+v", first)
+	}
+	if w := do("reader", http.MethodPost, "/articles/"+article.CID+"/report", `{"reason":"again"}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a duplicate report, got %d", w.Code)
+	}
+	w = do("mod", http.MethodPost, "/articles/"+article.CID+"/report", `{"reason":"off-topic"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Report by CID failed: %d", w.Code)
+	}
+	second := decode(w)
+
+	// 2. Peer reports join the queue; other actions and unknown articles don't
+	must := func(err error) {
+		if err != nil {
+			t.Fatalf("HandlePeerAction failed: %v", err)
+		}
+	}
+	must(moderation.HandlePeerAction(ctx, article.ID, domain.ModerationFlag, "hate speech", "12D3KooWPeer", time.Now()))
+	must(moderation.HandlePeerAction(ctx, article.ID, domain.ModerationFlag, "hate speech", "12D3KooWPeer", time.Now()))
+	must(moderation.HandlePeerAction(ctx, article.ID, domain.ModerationDismiss, "", "12D3KooWOther", time.Now()))
+	must(moderation.HandlePeerAction(ctx, "unknown-article", domain.ModerationReport, "spam", "12D3KooWPeer", time.Now()))
+
+	// 3. Only moderators and admins see the queue
+	if w := do("reader", http.MethodGet, "/moderation/reports", ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a reader, got %d", w.Code)
+	}
+	w = do("mod", http.MethodGet, "/moderation/reports", "")
+	var list struct {
+		Data       []domain.Report
+		Pagination struct{ Total int }
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || list.Pagination.Total != 3 {
+		t.Fatalf("Expected 3 open reports, got %d (%d)", list.Pagination.Total, w.Code)
+	}
+	if list.Data[0].Source != domain.ReportSourcePeer || list.Data[0].Reporter != "12D3KooWPeer" {
+		t.Errorf("Expected the peer report first, got %+v", list.Data[0])
+	}
+	if w := do("mod", http.MethodGet, "/moderation/reports?status=bogus", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown status, got %d", w.Code)
+	}
+
+	// 4. Resolving with broadcast flags the article to peers
+	w = do("mod", http.MethodPost, "/moderation/reports/"+first.ID+"/resolve", `{"note":"headline contradicts body","broadcast":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Resolve failed: %d %s", w.Code, w.Body.String())
+	}
+	if resolved := decode(w); resolved.Status != domain.ReportResolved || !resolved.Broadcast || resolved.ResolvedAt == nil {
+		t.Errorf("Unexpected resolved report: %+v", resolved)
+	}
+	actions := broadcaster.Actions()
+	if len(actions) != 1 || actions[0] != (mocks.ModerationAction{ArticleID: article.ID, Action: domain.ModerationFlag, Reason: "headline contradicts body"}) {
+		t.Errorf("Unexpected broadcasts: %+v", actions)
+	}
+	if w := do("mod", http.MethodPost, "/moderation/reports/"+first.ID+"/dismiss", ""); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a closed report, got %d", w.Code)
+	}
+
+	// 5. Dismissing without a body stays local
+	if w := do("mod", http.MethodPost, "/moderation/reports/"+second.ID+"/dismiss", ""); w.Code != http.StatusOK {
+		t.Fatalf("Dismiss failed: %d %s", w.Code, w.Body.String())
+	}
+	if len(broadcaster.Actions()) != 1 {
+		t.Error("Dismiss without broadcast reached the network")
+	}
+	w = do("mod", http.MethodGet, "/moderation/reports/"+second.ID, "")
+	if got := decode(w); got.Status != domain.ReportDismissed || got.Broadcast {
+		t.Errorf("Unexpected dismissed report: %+v", got)
+	}
+	if w := do("mod", http.MethodGet, "/moderation/reports?status=all", ""); !bytes.Contains(w.Body.Bytes(), []byte(`"total":3`)) {
+		t.Errorf("Expected every report with status=all: %s", w.Body.String())
+	}
+
+	// 6. A closed report frees the reader to report again
+	if w := do("reader", http.MethodPost, "/articles/"+article.ID+"/report", `{"reason":"still misleading"}`); w.Code != http.StatusCreated {
+		t.Errorf("Expected a new report after the first was closed, got %d", w.Code)
+	}
+
+	// 7. Without P2P, decisions can't be broadcast
+	offline := service.NewModerationService(badger.NewReportRepo(env.DB), badger.NewModerationCaseRepo(env.DB), env.ArticleRepo, log)
+	if _, err := offline.Dismiss(ctx, list.Data[0].ID, "mod", &domain.ReportDecisionRequest{Broadcast: true}); err != service.ErrModerationOffline {
+		t.Errorf("Expected ErrModerationOffline, got %v", err)
+	}
+}
+
+func TestModerationDecidesPerArticle(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	moderation := service.NewModerationService(badger.NewReportRepo(env.DB), badger.NewModerationCaseRepo(env.DB), env.ArticleRepo, log)
+	broadcaster := mocks.NewMockModerationBroadcaster()
+	moderation.SetBroadcaster(broadcaster)
+
+	ctx := context.Background()
+	author, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "author", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	var articles []*domain.Article
+	for _, title := range []string{"Flagged", "Untouched"} {
+		article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+			Title: title, Body: "Body text long enough to publish.", Category: "politics",
+		}, author.ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		articles = append(articles, article)
+	}
+	for _, reporter := range []string{"r1", "r2"} {
+		if _, err := moderation.Report(ctx, articles[0].ID, reporter, "spam"); err != nil {
+			t.Fatalf("Report failed: %v", err)
+		}
+	}
+	moderation.HandlePeerAction(ctx, articles[0].ID, domain.ModerationFlag, "spam", "peer-1", time.Now())
+	moderation.Report(ctx, articles[1].ID, "r1", "off topic")
+
+	// Every open report on the article closes, and peers hear about it once
+	closed, err := moderation.ResolveArticle(ctx, articles[0].ID, "mod", &domain.ReportDecisionRequest{Note: "spam", Broadcast: true})
+	if err != nil || len(closed) != 3 {
+		t.Fatalf("Expected 3 reports closed, got %d (%v)", len(closed), err)
+	}
+	if actions := broadcaster.Actions(); len(actions) != 1 || actions[0].Action != domain.ModerationFlag {
+		t.Errorf("Expected a single flag broadcast, got %+v", actions)
+	}
+
+	open, _, _ := moderation.List(ctx, &domain.ReportListFilter{Status: domain.ReportOpen})
+	if len(open) != 1 || open[0].ArticleID != articles[1].ID {
+		t.Errorf("Expected only the other article's report to stay open, got %+v", open)
+	}
+
+	// Nothing left to decide on the first article
+	if _, err := moderation.DismissArticle(ctx, articles[0].ID, "mod", &domain.ReportDecisionRequest{}); err != domain.ErrReportNotFound {
+		t.Errorf("Expected ErrReportNotFound, got %v", err)
+	}
+}
+
+func TestModerationCases(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	moderation := service.NewModerationService(badger.NewReportRepo(env.DB), badger.NewModerationCaseRepo(env.DB), env.ArticleRepo, log)
+	broadcaster := mocks.NewMockModerationBroadcaster()
+	moderation.SetBroadcaster(broadcaster)
+	store := mocks.NewMockPinStore()
+	moderation.SetUnpinner(store)
+
+	ctx := context.Background()
+	author, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "author", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	publish := func(title string) *domain.Article {
+		article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+			Title: title, Body: "Body text long enough to publish.", Category: "politics",
+		}, author.ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		store.Pin(ctx, article.CID)
+		return article
+	}
+	caseFor := func(articleID string) *domain.ModerationCase {
+		cases, _, err := moderation.ListCases(ctx, &domain.CaseListFilter{})
+		if err != nil {
+			t.Fatalf("Failed to list cases: %v", err)
+		}
+		for _, c := range cases {
+			if c.ArticleID == articleID && !c.Closed() {
+				return c
+			}
+		}
+		t.Fatalf("No undecided case for %s", articleID)
+		return nil
+	}
+	hidden, unlisted, unpinned := publish("Hidden"), publish("Unlisted"), publish("Unpinned")
+
+	// 1. Reports on one article, local or from peers, share a case
+	moderation.Report(ctx, hidden.ID, "r1", "spam")
+	moderation.Report(ctx, hidden.ID, "r2", "spam")
+	moderation.HandlePeerAction(ctx, hidden.ID, domain.ModerationFlag, "scam", "peer-1", time.Now())
+	c := caseFor(hidden.ID)
+	if c.Status != domain.CaseOpen || len(c.ReportIDs) != 3 || len(c.Reasons) != 2 {
+		t.Fatalf("Expected one open case with 3 reports and 2 reasons, got %+v", c)
+	}
+
+	// 2. Assigning puts the case under review
+	c, err = moderation.AssignCase(ctx, c.ID, "mod")
+	if err != nil || c.Status != domain.CaseReviewing || c.Assignee != "mod" {
+		t.Fatalf("Expected the case under review by mod, got %+v (%v)", c, err)
+	}
+	if cases, _, _ := moderation.ListCases(ctx, &domain.CaseListFilter{Assignee: "mod"}); len(cases) != 1 {
+		t.Errorf("Expected 1 case assigned to mod, got %d", len(cases))
+	}
+
+	// 3. Hiding removes the article from every read path and closes the reports
+	if _, err := moderation.ActionCase(ctx, c.ID, "mod", &domain.CaseDecisionRequest{Action: "delete"}); err == nil {
+		t.Error("Expected an unknown action to be rejected")
+	}
+	c, err = moderation.ActionCase(ctx, c.ID, "mod", &domain.CaseDecisionRequest{Action: domain.CaseActionHide, Note: "scam", Broadcast: true})
+	if err != nil || c.Status != domain.CaseActioned || c.Decision == nil || !c.Decision.Broadcast {
+		t.Fatalf("Expected the case actioned and broadcast, got %+v (%v)", c, err)
+	}
+	if _, err := env.ArticleService.GetByID(ctx, hidden.ID); err != domain.ErrArticleNotFound {
+		t.Errorf("Expected a hidden article not to be served, got %v", err)
+	}
+	if _, err := env.ArticleService.GetByCID(ctx, hidden.CID); err != domain.ErrArticleNotFound {
+		t.Errorf("Expected a hidden article not to be served by CID, got %v", err)
+	}
+	open, _, _ := moderation.List(ctx, &domain.ReportListFilter{Status: domain.ReportOpen})
+	if len(open) != 0 {
+		t.Errorf("Expected the case's reports closed, %d still open", len(open))
+	}
+	if actions := broadcaster.Actions(); len(actions) != 1 || actions[0].Action != domain.CaseActionHide || actions[0].Reason != "scam" {
+		t.Errorf("Expected a single hide broadcast, got %+v", actions)
+	}
+	if _, err := moderation.DismissCase(ctx, c.ID, "mod", &domain.CaseDecisionRequest{}); err != domain.ErrCaseClosed {
+		t.Errorf("Expected ErrCaseClosed, got %v", err)
+	}
+
+	// 4. Unlisting keeps the article reachable but out of listings
+	moderation.Report(ctx, unlisted.ID, "r1", "off topic")
+	if _, err := moderation.ActionCase(ctx, caseFor(unlisted.ID).ID, "mod", &domain.CaseDecisionRequest{Action: domain.CaseActionUnlist}); err != nil {
+		t.Fatalf("Failed to unlist: %v", err)
+	}
+	if _, err := env.ArticleService.GetByID(ctx, unlisted.ID); err != nil {
+		t.Errorf("Expected an unlisted article to be served, got %v", err)
+	}
+	listed, _, _ := env.ArticleService.List(ctx, &domain.ArticleListFilter{})
+	if len(listed) != 1 || listed[0].ID != unpinned.ID {
+		t.Errorf("Expected only the unmoderated article listed, got %d", len(listed))
+	}
+
+	// 5. Unpinning drops the content from IPFS but keeps the article
+	moderation.HandlePeerAction(ctx, unpinned.ID, domain.CaseActionUnpin, "", "peer-1", time.Now())
+	if _, err := moderation.ActionCase(ctx, caseFor(unpinned.ID).ID, "mod", &domain.CaseDecisionRequest{Action: domain.CaseActionUnpin}); err != nil {
+		t.Fatalf("Failed to unpin: %v", err)
+	}
+	if pinned, _ := store.PinnedCIDs(ctx); pinned[unpinned.CID] || !pinned[unlisted.CID] {
+		t.Errorf("Expected only the unpinned article's content removed, pinned: %v", pinned)
+	}
+
+	// 6. A new report on a decided article opens a fresh case; dismissing leaves the article alone
+	moderation.Report(ctx, unpinned.ID, "r2", "still spam")
+	fresh := caseFor(unpinned.ID)
+	if len(fresh.ReportIDs) != 1 {
+		t.Errorf("Expected a fresh case with 1 report, got %d", len(fresh.ReportIDs))
+	}
+	if c, err := moderation.DismissCase(ctx, fresh.ID, "mod", &domain.CaseDecisionRequest{Action: domain.CaseActionHide}); err != nil || c.Status != domain.CaseDismissed || c.Decision.Action != "" {
+		t.Errorf("Expected the case dismissed without action, got %+v (%v)", c, err)
+	}
+	if _, err := env.ArticleService.GetByID(ctx, unpinned.ID); err != nil {
+		t.Errorf("Expected a dismissed case to leave the article served, got %v", err)
+	}
+}
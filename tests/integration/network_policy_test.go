@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// policyPublisher stands in for IPNS and IPFS: the name points at
+// whichever document was published last
+type policyPublisher struct {
+	docs    map[string][]byte
+	current string
+}
+
+func (p *policyPublisher) Resolve(ctx context.Context, name string) (string, error) {
+	if p.current == "" {
+		return "", domain.ErrIPNSResolveFailed
+	}
+	return "/ipfs/" + p.current, nil
+}
+
+func (p *policyPublisher) Cat(ctx context.Context, cid string) ([]byte, error) {
+	data, ok := p.docs[cid]
+	if !ok {
+		return nil, fmt.Errorf("no content for %s", cid)
+	}
+	return data, nil
+}
+
+func (p *policyPublisher) publish(t *testing.T, policy domain.NetworkPolicy, key ed25519.PrivateKey) {
+	t.Helper()
+	content, err := policy.SignableContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Signature, err = crypto.Sign(content, key); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.current = fmt.Sprintf("policy-%d", len(p.docs))
+	p.docs[p.current] = data
+}
+
+type bootstrapRecorder struct {
+	added []string
+}
+
+func (b *bootstrapRecorder) AddBootstrapPeer(addr string) error {
+	b.added = append(b.added, addr)
+	return nil
+}
+
+func TestNetworkPolicy(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	publisher, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	impostor, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ipns := &policyPublisher{docs: make(map[string][]byte)}
+	policies, err := service.NewNetworkPolicyService("k51-policy", crypto.DIDKey(publisher.PublicKey), "1.0.0", ipns, ipns, log)
+	if err != nil {
+		t.Fatalf("Failed to create policy service: %v", err)
+	}
+	bootstrap := &bootstrapRecorder{}
+	policies.SetBootstrapper(bootstrap)
+	env.ArticleService.SetCategoryPolicy(policies)
+
+	// 1. Nothing is banned until a policy arrives
+	if err := policies.Refresh(ctx); err == nil {
+		t.Error("Expected an error before anything is published")
+	}
+	if status := policies.Status(); status.Policy != nil || status.LastError == "" || !status.Compatible {
+		t.Errorf("Unexpected status before a policy %+v", status)
+	}
+
+	// 2. A document signed by another key is ignored
+	ipns.publish(t, domain.NetworkPolicy{Version: 1, BannedCategories: []string{"politics"}}, impostor.PrivateKey)
+	if err := policies.Refresh(ctx); !errors.Is(err, domain.ErrPolicySignature) {
+		t.Errorf("Expected ErrPolicySignature, got %v", err)
+	}
+	if policies.CategoryBanned("politics") {
+		t.Error("An unsigned policy was applied")
+	}
+
+	// 3. The publisher's policy is applied
+	ipns.publish(t, domain.NetworkPolicy{
+		Version:            2,
+		IssuedAt:           time.Now().UTC(),
+		BootstrapPeers:     []string{"/dnsaddr/policy.example.org"},
+		BannedCategories:   []string{"spam"},
+		MinProtocolVersion: "1.2",
+	}, publisher.PrivateKey)
+	if err := policies.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	status := policies.Status()
+	if status.Policy == nil || status.Policy.Version != 2 || status.FetchedAt == nil || status.LastError != "" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if status.Compatible {
+		t.Error("Expected 1.0.0 to fall short of a 1.2 minimum")
+	}
+	if len(bootstrap.added) != 1 || bootstrap.added[0] != "/dnsaddr/policy.example.org" {
+		t.Errorf("Expected the policy's bootstrap peer added, got %v", bootstrap.added)
+	}
+
+	// 4. Banned categories are refused locally and from peers
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "judy",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	_, err = env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Buy now",
+		Body:     "Body text",
+		Category: "spam",
+	}, user.ID, "127.0.0.1")
+	var validation *domain.ValidationError
+	if !errors.As(err, &validation) || validation.Field != "category" {
+		t.Errorf("Expected a category validation error, got %v", err)
+	}
+	allowed, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Local news",
+		Body:     "Body text",
+		Category: "news",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create an allowed article: %v", err)
+	}
+	incoming := *allowed
+	incoming.ID = "incoming-spam"
+	incoming.Category = "spam"
+	if err := env.ArticleService.HandleIncomingArticle(&incoming); err == nil {
+		t.Error("Expected an incoming banned article to be refused")
+	}
+	if _, err := env.ArticleRepo.GetByID(ctx, incoming.ID); err != domain.ErrArticleNotFound {
+		t.Errorf("Expected the banned article not stored, got %v", err)
+	}
+
+	// 5. An older edition never replaces the one in force
+	ipns.publish(t, domain.NetworkPolicy{Version: 1}, publisher.PrivateKey)
+	if err := policies.Refresh(ctx); !errors.Is(err, domain.ErrPolicyOutdated) {
+		t.Errorf("Expected ErrPolicyOutdated, got %v", err)
+	}
+	if !policies.CategoryBanned("spam") {
+		t.Error("An outdated policy replaced the one in force")
+	}
+}
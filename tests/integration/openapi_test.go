@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// newTestRouter wires every optional API handler so all routes are
+// registered. The handlers are never called.
+func newTestRouter(t *testing.T) *api.Router {
+	log, _ := logger.New("error", "text")
+	cfg := &config.Config{}
+	cfg.Server.Mode = "test"
+	cfg.RateLimit.RequestsPerMinute = 1000
+	cfg.RateLimit.Burst = 100
+
+	return api.NewRouter(
+		&handlers.AuthHandler{}, &handlers.ArticleHandler{}, &handlers.FeedHandler{},
+		&handlers.SearchHandler{}, &handlers.HealthHandler{}, &handlers.UploadHandler{},
+		&handlers.NetworkHandler{}, &handlers.IntegrityHandler{}, &handlers.IndexMaintenanceHandler{},
+		&handlers.ArchiveHandler{}, &handlers.PinLedgerHandler{}, &handlers.EventsHandler{},
+		&handlers.V2Handler{}, &handlers.ModerationHandler{}, &handlers.VoteHandler{},
+		&handlers.CommentHandler{}, &handlers.ReputationHandler{}, &handlers.AdminHandler{}, nil,
+		auth.NewJWTManager("test-secret", 0, 0), nil, cfg, log,
+	)
+}
+
+func TestOpenAPIGeneratedFromRoutes(t *testing.T) {
+	engine := newTestRouter(t).Setup()
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Spec request failed: %d %s", w.Code, w.Body.String())
+	}
+
+	var doc struct {
+		OpenAPI string
+		Paths   map[string]map[string]struct {
+			Summary      string
+			Undocumented bool `json:"x-undocumented"`
+			Security     []map[string][]string
+			Parameters   []struct{ Name, In string }
+		}
+		Components struct{ Schemas map[string]json.RawMessage }
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Spec is not valid JSON: %v", err)
+	}
+	if doc.OpenAPI == "" {
+		t.Fatal("Expected an openapi version")
+	}
+
+	// Every registered API route is documented
+	for _, route := range engine.Routes() {
+		if route.Path == "/health" || strings.HasPrefix(route.Path, "/api/") {
+			op, ok := doc.Paths[specPath(route.Path)][strings.ToLower(route.Method)]
+			if !ok {
+				t.Errorf("%s %s missing from the spec", route.Method, route.Path)
+			} else if op.Undocumented || op.Summary == "" {
+				t.Errorf("%s %s has no description in the route table", route.Method, route.Path)
+			}
+		}
+	}
+
+	vote := doc.Paths["/api/v1/articles/{cid}/vote"]["post"]
+	if len(vote.Security) == 0 {
+		t.Error("Expected voting to require a bearer token")
+	}
+	if len(vote.Parameters) != 1 || vote.Parameters[0].Name != "cid" || vote.Parameters[0].In != "path" {
+		t.Errorf("Expected the cid path parameter, got %+v", vote.Parameters)
+	}
+	if _, ok := doc.Paths["/api/v1/comments/{id}"]["delete"]; !ok {
+		t.Error("Expected comment deletion in the spec")
+	}
+	for _, name := range []string{"Article", "Comment", "VoteRequest", "Error", "Problem"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("Expected schema %s", name)
+		}
+	}
+
+	// The YAML name serves the same document
+	y := httptest.NewRecorder()
+	engine.ServeHTTP(y, httptest.NewRequest(http.MethodGet, "/docs/openapi.yaml", nil))
+	if y.Body.String() != w.Body.String() {
+		t.Error("Expected openapi.yaml to match openapi.json")
+	}
+}
+
+// specPath rewrites a gin path in OpenAPI form
+func specPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
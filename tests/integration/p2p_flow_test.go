@@ -3,6 +3,7 @@ package integration
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/auth"
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
@@ -11,13 +12,16 @@ import (
 	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
 )
 
-// MockBroadcaster captures broadcasted articles for testing
+// MockBroadcaster hands broadcast articles to the test over a channel,
+// when one is set
 type MockBroadcaster struct {
-	LastArticle *domain.Article
+	broadcast chan *domain.Article
 }
 
 func (m *MockBroadcaster) BroadcastArticle(msgType string, article *domain.Article) error {
-	m.LastArticle = article
+	if m.broadcast != nil {
+		m.broadcast <- article
+	}
 	return nil
 }
 
@@ -45,7 +49,7 @@ func TestP2PBroadcastingFlow(t *testing.T) {
 
 	// Create Article on Node A
 	// We need to inject a mock broadcaster to capture the message
-	mockBroadcaster := &MockBroadcaster{}
+	mockBroadcaster := &MockBroadcaster{broadcast: make(chan *domain.Article, 1)}
 	// Re-create service with mock broadcaster (since SetupTestEnv passes nil)
 	envA.ArticleService = createServiceWithBroadcaster(envA, mockBroadcaster)
 
@@ -66,17 +70,19 @@ func TestP2PBroadcastingFlow(t *testing.T) {
 		t.Fatalf("Node A: Article not found in local DB: %v", err)
 	}
 
-	// Verify it was broadcasted
-	if mockBroadcaster.LastArticle == nil {
+	// Verify it was broadcasted; that happens in the background
+	var transportedArticle *domain.Article
+	select {
+	case transportedArticle = <-mockBroadcaster.broadcast:
+	case <-time.After(2 * time.Second):
 		t.Fatal("Node A: Article was not broadcasted")
 	}
-	if mockBroadcaster.LastArticle.ID != articleA.ID {
+	if transportedArticle.ID != articleA.ID {
 		t.Errorf("Node A: Broadcasted article ID mismatch")
 	}
 
 	// --- Step 2: Simulate Network Transport ---
 	// We take the article from the MockBroadcaster and "send" it to Node B
-	transportedArticle := mockBroadcaster.LastArticle
 
 	// --- Step 3: Node B receives the article ---
 
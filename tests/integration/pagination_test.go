@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+func TestPaginationLinks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/items", func(c *gin.Context) {
+		parser := handlers.NewQueryParamParser(c)
+		page := parser.Pagination(10)
+		if err := parser.Error(); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.Paginated(c, []string{}, page.Page, page.Limit, 25)
+	})
+
+	get := func(target string) (*httptest.ResponseRecorder, response.Pagination) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		var body struct{ Pagination response.Pagination }
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body.Pagination
+	}
+
+	w, first := get("/items?author=alice")
+	if first.TotalPages != 3 || first.NextCursor != "2" || first.PrevCursor != "" {
+		t.Errorf("Unexpected first page: %+v", first)
+	}
+	want := `</items?author=alice&page=1>; rel="first", </items?author=alice&page=3>; rel="last", </items?author=alice&page=2>; rel="next"`
+	if got := w.Header().Get("Link"); got != want {
+		t.Errorf("Unexpected Link header:\n got %s\nwant %s", got, want)
+	}
+
+	// The next cursor is accepted back as ?cursor=
+	w, second := get("/items?cursor=" + first.NextCursor)
+	if second.Page != 2 || second.NextCursor != "3" || second.PrevCursor != "1" {
+		t.Errorf("Unexpected second page: %+v", second)
+	}
+	want = `</items?page=1>; rel="first", </items?page=3>; rel="last", </items?page=3>; rel="next", </items?page=1>; rel="prev"`
+	if got := w.Header().Get("Link"); got != want {
+		t.Errorf("Unexpected Link header:\n got %s\nwant %s", got, want)
+	}
+
+	_, last := get("/items?page=3")
+	if last.NextCursor != "" || last.PrevCursor != "2" {
+		t.Errorf("Unexpected last page: %+v", last)
+	}
+
+	// Past the end, prev points back at the last page
+	_, beyond := get("/items?page=9")
+	if beyond.NextCursor != "" || beyond.PrevCursor != "3" {
+		t.Errorf("Unexpected page past the end: %+v", beyond)
+	}
+}
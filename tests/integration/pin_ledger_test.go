@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestPinLedgerRetryAndReconcile(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	store := mocks.NewMockPinStore()
+	ledger := service.NewPinLedgerService(badger.NewPinRepo(env.DB), store, service.PinLedgerConfig{
+		RetryInterval:     time.Millisecond,
+		ReconcileInterval: time.Hour,
+		MaxBackoff:        4 * time.Millisecond,
+	}, log)
+	env.ArticleService.SetPinTracker(ledger)
+
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "grace",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	// 1. A pin that fails during an outage stays in the ledger as failed
+	store.SetDown(true)
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Pinned while offline",
+		Body:     "Body text",
+		Category: "technology",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	failed, err := ledger.List(ctx, domain.PinFailed)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(failed) != 1 || failed[0].CID != article.CID || failed[0].Attempts != 1 || failed[0].LastError == "" {
+		t.Fatalf("Expected one failed pin for %s, got %+v", article.CID, failed)
+	}
+
+	// 2. Once IPFS is back, the retry loop pins it
+	store.SetDown(false)
+	time.Sleep(5 * time.Millisecond)
+	pinned, err := ledger.RetryDue(ctx)
+	if err != nil || pinned != 1 || !store.Pinned[article.CID] {
+		t.Fatalf("Expected retry to pin the article, got %d (%v)", pinned, err)
+	}
+
+	// 3. Reconciliation notices a pin that disappeared and queues it again
+	store.Drop(article.CID)
+	report, err := ledger.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != article.CID {
+		t.Fatalf("Expected %s reported missing, got %+v", article.CID, report)
+	}
+	if _, err := ledger.RetryDue(ctx); err != nil || !store.Pinned[article.CID] {
+		t.Fatalf("Expected missing pin to be restored (%v)", err)
+	}
+
+	summary, err := ledger.Summary(ctx)
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if summary.ByStatus[domain.PinPinned] != 1 || summary.LastReconcile == nil {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	// 4. Deleting the article releases its ledger entries
+	if err := env.ArticleService.Delete(ctx, article.ID, user.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if pins, _ := ledger.List(ctx, ""); len(pins) != 0 {
+		t.Errorf("Expected ledger to be empty after delete, got %d entries", len(pins))
+	}
+}
@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+func TestPprofEndpoints(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	admin := engine.Group("/admin", middleware.AuthMiddleware(env.JWTManager), middleware.AdminMiddleware([]string{"root"}))
+	admin.GET("/debug/pprof/*profile", handlers.Pprof)
+	admin.POST("/debug/pprof/*profile", handlers.Pprof)
+
+	ctx := context.Background()
+	tokens := make(map[string]string)
+	for _, name := range []string{"root", "reader"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		tokens[name], _, _ = env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	}
+
+	do := func(h http.Handler, user, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if user != "" {
+			req.Header.Set("Authorization", "Bearer "+tokens[user])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// 1. Profiles are for admins only
+	if w := do(engine, "", http.MethodGet, "/admin/debug/pprof/goroutine?debug=1"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", w.Code)
+	}
+	if w := do(engine, "reader", http.MethodGet, "/admin/debug/pprof/goroutine?debug=1"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-admin, got %d", w.Code)
+	}
+
+	// 2. The index links to the profiles relative to wherever it is mounted
+	w := do(engine, "root", http.MethodGet, "/admin/debug/pprof/")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `href="goroutine?debug=2"`) {
+		t.Errorf("Expected the profile index, got %d", w.Code)
+	}
+
+	// 3. Named profiles, as text for reading in a browser
+	w = do(engine, "root", http.MethodGet, "/admin/debug/pprof/goroutine?debug=1")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile:") {
+		t.Errorf("Expected the goroutine profile, got %d %.100s", w.Code, w.Body.String())
+	}
+	if w := do(engine, "root", http.MethodGet, "/admin/debug/pprof/nonsense"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown profile, got %d", w.Code)
+	}
+	if w := do(engine, "root", http.MethodPost, "/admin/debug/pprof/symbol"); w.Code != http.StatusOK {
+		t.Errorf("Expected symbol lookups to be served, got %d", w.Code)
+	}
+
+	// 4. The separate listener serves them at the usual path, without auth
+	w = do(api.PprofEngine(), "", http.MethodGet, "/debug/pprof/heap?debug=1")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "heap profile:") {
+		t.Errorf("Expected the heap profile on the pprof engine, got %d", w.Code)
+	}
+}
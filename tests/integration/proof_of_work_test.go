@@ -0,0 +1,145 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestArticleProofOfWork(t *testing.T) {
+	article := &domain.Article{ID: "a1", CID: "QmFirst", Author: "newcomer", AuthorPubKey: "key"}
+
+	// 1. A proof binds to the article version and its difficulty
+	proof, err := p2p.SolveArticleProof(context.Background(), article, 12)
+	if err != nil {
+		t.Fatalf("Failed to solve proof: %v", err)
+	}
+	if err := p2p.VerifyArticleProof(article, proof, 12); err != nil {
+		t.Errorf("Expected the proof to verify, got %v", err)
+	}
+	if err := p2p.VerifyArticleProof(article, proof, 16); !errors.Is(err, p2p.ErrProofInvalid) {
+		t.Errorf("Expected a proof below the required difficulty to fail, got %v", err)
+	}
+	edited := *article
+	edited.CID = "QmSecond"
+	if err := p2p.VerifyArticleProof(&edited, proof, 12); !errors.Is(err, p2p.ErrProofInvalid) {
+		t.Errorf("Expected a proof reused on another version to fail, got %v", err)
+	}
+	if err := p2p.VerifyArticleProof(article, nil, 12); !errors.Is(err, p2p.ErrProofRequired) {
+		t.Errorf("Expected a missing proof to fail, got %v", err)
+	}
+}
+
+func TestArticleProofOfWorkValidator(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, b := newTestNode(ctx, t), newTestNode(ctx, t)
+	if err := a.GetHost().Connect(ctx, peer.AddrInfo{ID: b.GetPeerID(), Addrs: b.GetHost().Addrs()}); err != nil {
+		t.Fatalf("Failed to connect nodes: %v", err)
+	}
+
+	sender, receiver := p2p.NewBroadcaster(a, log), p2p.NewBroadcaster(b, log)
+	for _, broadcaster := range []*p2p.Broadcaster{sender, receiver} {
+		if err := broadcaster.Start(); err != nil {
+			t.Fatalf("Failed to start broadcaster: %v", err)
+		}
+		defer broadcaster.Stop()
+	}
+
+	// The receiver knows the veteran's key; everyone else is unestablished
+	veteran, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	impostor, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	reputation := p2p.NewReputationSystem(log)
+	for i := 0; i < 3; i++ {
+		reputation.RecordEvent(&p2p.ReputationEvent{DID: crypto.DIDKey(veteran.PublicKey), EventType: p2p.EventArticlePost})
+	}
+	if err := receiver.RequireProofOfWork(reputation, 8); err != nil {
+		t.Fatalf("Failed to require proof of work: %v", err)
+	}
+
+	received := make(chan string, 100)
+	receiver.OnArticle(func(msg *p2p.ArticleMessage) error {
+		received <- msg.Article.ID
+		return nil
+	})
+	signer := auth.NewArticleSigner()
+	publish := func(id, author string, key *crypto.KeyPair) {
+		article := &domain.Article{ID: id, CID: "Qm" + id, Author: author, AuthorPubKey: "key-" + author, Timestamp: time.Now()}
+		if key != nil {
+			article.AuthorPubKey = crypto.PublicKeyToString(key.PublicKey)
+			if err := signer.SignArticle(article, key.PrivateKey); err != nil {
+				t.Fatalf("Failed to sign %s: %v", id, err)
+			}
+		}
+		if err := sender.BroadcastArticle("new", article); err != nil {
+			t.Fatalf("Failed to broadcast %s: %v", id, err)
+		}
+	}
+	await := func(id string) []string {
+		var seen []string
+		deadline := time.After(10 * time.Second)
+		for {
+			select {
+			case got := <-received:
+				seen = append(seen, got)
+				if got == id {
+					return seen
+				}
+			case <-deadline:
+				t.Fatalf("%s never arrived, saw %v", id, seen)
+			}
+		}
+	}
+
+	// 1. Articles signed by an established key need no proof; retry until
+	// the mesh forms
+	deadline := time.Now().Add(10 * time.Second)
+	for delivered := false; !delivered; {
+		publish("veteran-1", "veteran", veteran)
+		select {
+		case <-received:
+			delivered = true
+		case <-time.After(500 * time.Millisecond):
+			if time.Now().After(deadline) {
+				t.Fatal("Established author's article never arrived")
+			}
+		}
+	}
+
+	// 2. Unproven articles from unknown authors are dropped, as are ones
+	// claiming the veteran's name under another key or carrying the
+	// veteran's key without its signature; proven ones are delivered
+	publish("sybil-1", "sybil", nil)
+	publish("impostor-1", "veteran", impostor)
+	forged := &domain.Article{ID: "forged-1", CID: "Qmforged-1", Author: "veteran", AuthorPubKey: crypto.PublicKeyToString(veteran.PublicKey), Signature: "forged", Timestamp: time.Now()}
+	if err := sender.BroadcastArticle("new", forged); err != nil {
+		t.Fatalf("Failed to broadcast forged-1: %v", err)
+	}
+	if err := sender.RequireProofOfWork(p2p.NewReputationSystem(log), 8); err != nil {
+		t.Fatalf("Failed to require proof of work: %v", err)
+	}
+	publish("newcomer-1", "newcomer", nil)
+	time.Sleep(200 * time.Millisecond)
+	for _, id := range await("newcomer-1") {
+		if id == "sybil-1" || id == "impostor-1" || id == "forged-1" {
+			t.Errorf("Expected unproven article %s to be rejected", id)
+		}
+	}
+}
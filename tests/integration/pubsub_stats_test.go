@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestPubsubTopicStats(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, b := newTestNode(ctx, t), newTestNode(ctx, t)
+	// A topic nobody else is in shows up before any message does
+	if _, err := a.JoinTopic("news/quiet"); err != nil {
+		t.Fatalf("Failed to join topic: %v", err)
+	}
+	if err := a.GetHost().Connect(ctx, peer.AddrInfo{ID: b.GetPeerID(), Addrs: b.GetHost().Addrs()}); err != nil {
+		t.Fatalf("Failed to connect nodes: %v", err)
+	}
+	waitFor(t, "a message from B", func() bool {
+		if a.PubsubStats()["news/test"].Received > 0 {
+			return true
+		}
+		b.Publish("news/test", []byte("hello"))
+		time.Sleep(80 * time.Millisecond)
+		return false
+	})
+	a.Publish("news/test", []byte("hi"))
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/pubsub", handlers.NewNetworkHandler(a, nil, log).GetPubsubStats)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pubsub", nil))
+	var resp struct {
+		Data struct{ Topics map[string]p2p.TopicStats }
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode %s: %v", w.Body.String(), err)
+	}
+
+	active := resp.Data.Topics["news/test"]
+	if !active.Joined || active.Peers != 1 || active.Published != 1 || active.Invalid != 0 ||
+		active.LastReceived == nil || active.LastPublished == nil || active.LastPublished.Before(*active.LastReceived) {
+		t.Errorf("Unexpected stats for the active topic: %+v", active)
+	}
+	quiet, ok := resp.Data.Topics["news/quiet"]
+	if !ok || !quiet.Joined || quiet.Peers != 0 || quiet.Received != 0 || quiet.LastReceived != nil {
+		t.Errorf("Expected the quiet topic to be joined with no peers or messages, got %+v", quiet)
+	}
+}
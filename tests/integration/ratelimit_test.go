@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/pkg/response"
+)
+
+func TestGroupRateLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	limit := middleware.GroupRateLimitMiddleware(
+		middleware.RateLimit{RequestsPerMinute: 3},
+		middleware.RateLimit{RequestsPerMinute: 1, Burst: 1},
+		middleware.RateLimit{RequestsPerMinute: 1},
+	)
+	v1 := engine.Group("/api/v1", limit)
+	v1.GET("/articles", ok)
+	v1.POST("/articles", ok)
+	v1.GET("/search", ok)
+	v2 := engine.Group("/api/v2", response.ProblemDetails(), limit)
+	v2.GET("/articles", ok)
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	// Each group counts down on its own
+	w := do(http.MethodGet, "/api/v1/articles")
+	if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "3" || w.Header().Get("RateLimit-Remaining") != "2" {
+		t.Fatalf("Expected 2 of 3 reads left, got %d limit=%s remaining=%s",
+			w.Code, w.Header().Get("RateLimit-Limit"), w.Header().Get("RateLimit-Remaining"))
+	}
+	if reset, err := strconv.Atoi(w.Header().Get("RateLimit-Reset")); err != nil || reset < 1 || reset > 60 {
+		t.Errorf("Expected RateLimit-Reset in seconds within the window, got %q", w.Header().Get("RateLimit-Reset"))
+	}
+	if w := do(http.MethodGet, "/api/v1/search"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Expected search to have its own budget, got %d remaining=%s", w.Code, w.Header().Get("RateLimit-Remaining"))
+	}
+	for i := 0; i < 2; i++ {
+		if w := do(http.MethodPost, "/api/v1/articles"); w.Code != http.StatusOK {
+			t.Fatalf("Expected write %d within rate plus burst, got %d", i+1, w.Code)
+		}
+	}
+
+	// A spent budget answers 429 with a retry hint
+	w = do(http.MethodPost, "/api/v1/articles")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once writes are spent, got %d", w.Code)
+	}
+	var body response.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode 429 body: %v", err)
+	}
+	if body.RetryAfter < 1 || w.Header().Get("Retry-After") != strconv.Itoa(body.RetryAfter) {
+		t.Errorf("Expected matching retry hints, got body=%d header=%s", body.RetryAfter, w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Expected nothing remaining, got %s", w.Header().Get("RateLimit-Remaining"))
+	}
+	if w := do(http.MethodGet, "/api/v1/search"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected search to be spent, got %d", w.Code)
+	}
+
+	// Reads are unaffected, and the budget is shared with v2
+	if w := do(http.MethodGet, "/api/v1/articles"); w.Code != http.StatusOK {
+		t.Errorf("Expected reads to survive spent writes, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/articles"); w.Code != http.StatusOK {
+		t.Errorf("Expected the last read on v2, got %d", w.Code)
+	}
+	w = do(http.MethodGet, "/api/v2/articles")
+	var problem response.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if w.Code != http.StatusTooManyRequests || problem.Status != http.StatusTooManyRequests || problem.RetryAfter < 1 {
+		t.Errorf("Expected a 429 problem with retry_after, got %d %+v", w.Code, problem)
+	}
+}
+
+func TestRateLimitBudgetFallback(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		RequestsPerMinute: 500,
+		Burst:             50,
+		Writes:            config.RateLimitBudget{RequestsPerMinute: 30, Burst: 5},
+	}
+
+	if got := cfg.Budget(cfg.Writes); got.RequestsPerMinute != 30 || got.Burst != 5 {
+		t.Errorf("Expected the writes budget, got %+v", got)
+	}
+	if got := cfg.Budget(cfg.Reads); got.RequestsPerMinute != 500 || got.Burst != 50 {
+		t.Errorf("Expected unset reads to fall back to the top level, got %+v", got)
+	}
+}
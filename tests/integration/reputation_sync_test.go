@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestReputationSync(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, b, c := newTestNode(ctx, t), newTestNode(ctx, t), newTestNode(ctx, t)
+	for _, other := range []*p2p.P2PNode{b, c} {
+		if err := a.GetHost().Connect(ctx, peer.AddrInfo{ID: other.GetPeerID(), Addrs: other.GetHost().Addrs()}); err != nil {
+			t.Fatalf("Failed to connect nodes: %v", err)
+		}
+	}
+
+	// a has seen alice post; b has verified alice and seen spam from eve
+	local := p2p.NewReputationSystem(log)
+	local.SetLocalWeight(0.5)
+	local.RecordEvent(&p2p.ReputationEvent{DID: "alice", EventType: p2p.EventArticlePost})
+	remote := p2p.NewReputationSystem(log)
+	remote.RecordEvent(&p2p.ReputationEvent{DID: "alice", EventType: p2p.EventVerified})
+	remote.RecordEvent(&p2p.ReputationEvent{DID: "eve", EventType: p2p.EventSpam})
+
+	sync := p2p.NewReputationSync(a, local, []peer.ID{b.GetPeerID()}, log)
+	p2p.NewReputationSync(b, remote, nil, log)
+	p2p.NewReputationSync(c, p2p.NewReputationSystem(log), nil, log)
+
+	// 1. A trusted peer's summary is verified and merged
+	merged, err := sync.SyncPeer(ctx, b.GetPeerID())
+	if err != nil || merged != 2 {
+		t.Fatalf("Expected 2 scores merged, got %d, %v", merged, err)
+	}
+
+	// Local evidence and the peer's count half each
+	alice := local.GetScore("alice")
+	want := 0.5*(p2p.InitialScore+p2p.ArticlePostScore) + 0.5*(p2p.InitialScore+p2p.VerifiedBonus)
+	if math.Abs(alice.Score-want) > 1e-9 || alice.Peers != 1 || alice.ArticleCount != 1 {
+		t.Errorf("Expected alice blended to %.1f, got %+v", want, alice)
+	}
+	// With nothing seen locally the peer's view stands
+	if eve := local.GetScore("eve"); eve.Score != p2p.InitialScore+p2p.SpamPenalty || eve.Peers != 1 {
+		t.Errorf("Expected eve at the peer's score, got %+v", eve)
+	}
+	if top := local.GetTopUsers(10); len(top) != 2 {
+		t.Errorf("Expected remote-only DIDs on the leaderboard, got %d", len(top))
+	}
+
+	// 2. Remote evidence is never saved or passed on
+	if scores := local.LocalScores(0); len(scores) != 1 || scores[0].DID != "alice" || scores[0].Score != p2p.InitialScore+p2p.ArticlePostScore {
+		t.Errorf("Expected only local observations shared, got %+v", scores)
+	}
+
+	// 3. Only trusted peers are pulled from
+	if _, err := sync.SyncPeer(ctx, c.GetPeerID()); err == nil {
+		t.Error("Expected an untrusted peer to be refused")
+	}
+	status := sync.Status()
+	if len(status) != 1 || status[0].PeerID != b.GetPeerID().String() || status[0].LastSuccess == nil || status[0].Scores != 2 {
+		t.Errorf("Unexpected sync status %+v", status)
+	}
+
+	// 4. Summaries are bound to their issuer's key
+	summary, err := sync.Summary()
+	if err != nil {
+		t.Fatalf("Failed to sign summary: %v", err)
+	}
+	if err := summary.Verify(); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	summary.Scores[0].Score = 100
+	if err := summary.Verify(); !errors.Is(err, p2p.ErrSummarySignature) {
+		t.Errorf("Expected a tampered summary to fail, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestReputationEndpoints(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	reputation := p2p.NewReputationSystem(log)
+	for did, events := range map[string][]string{
+		"alice": {p2p.EventVerified, p2p.EventArticlePost},
+		"bob":   {p2p.EventArticlePost},
+		"eve":   {p2p.EventSpam},
+	} {
+		for _, event := range events {
+			reputation.RecordEvent(&p2p.ReputationEvent{DID: did, EventType: event, Timestamp: time.Now()})
+		}
+	}
+
+	articles := handlers.NewArticleHandler(env.ArticleService, log)
+	articles.SetTrustScorer(reputation)
+	h := handlers.NewReputationHandler(reputation, log)
+	disabled := handlers.NewReputationHandler(nil, log)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/reputation/top", h.Top)
+	engine.GET("/reputation/:did", h.Get)
+	engine.GET("/disabled/:did", disabled.Get)
+	engine.GET("/articles/:cid", middleware.ETag(middleware.CacheRevalidate), articles.GetByCID)
+	engine.GET("/articles", articles.List)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// 1. A DID's score, with unknown DIDs at the initial score
+	var score struct{ Data p2p.ReputationScore }
+	w := get("/reputation/alice")
+	json.Unmarshal(w.Body.Bytes(), &score)
+	if w.Code != http.StatusOK || score.Data.Score != p2p.InitialScore+p2p.VerifiedBonus+p2p.ArticlePostScore || score.Data.ArticleCount != 1 {
+		t.Errorf("Unexpected score for alice: %d %s", w.Code, w.Body.String())
+	}
+	json.Unmarshal(get("/reputation/stranger").Body.Bytes(), &score)
+	if score.Data.DID != "stranger" || score.Data.Score != p2p.InitialScore {
+		t.Errorf("Expected the initial score for an unknown DID, got %+v", score.Data)
+	}
+
+	// 2. The leaderboard is best first and bounded
+	var top struct{ Data []p2p.ReputationScore }
+	w = get("/reputation/top?limit=2")
+	json.Unmarshal(w.Body.Bytes(), &top)
+	if w.Code != http.StatusOK || len(top.Data) != 2 || top.Data[0].DID != "alice" || top.Data[1].DID != "bob" {
+		t.Errorf("Unexpected leaderboard: %d %s", w.Code, w.Body.String())
+	}
+	for _, path := range []string{"/reputation/top?limit=0", "/reputation/top?limit=500", "/reputation/top?limit=x"} {
+		if w := get(path); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", path, w.Code)
+		}
+	}
+
+	// 3. Without P2P there is no reputation system
+	if w := get("/disabled/alice"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 when reputation is disabled, got %d", w.Code)
+	}
+
+	// 4. Articles carry their author's trust score
+	user, err := env.UserService.Register(context.Background(), &domain.UserRegisterRequest{Username: "alice", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	article, err := env.ArticleService.Create(context.Background(), &domain.ArticleCreateRequest{
+		Title: "Harbour reopens", Body: "Ferries resume on Monday.", Category: "local",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	want := reputation.ArticleTrust(article)
+
+	var one struct {
+		Data struct {
+			CID         string   `json:"cid"`
+			AuthorTrust *float64 `json:"author_trust"`
+		}
+	}
+	w = get("/articles/" + article.CID)
+	json.Unmarshal(w.Body.Bytes(), &one)
+	if w.Code != http.StatusOK || one.Data.CID != article.CID || one.Data.AuthorTrust == nil || *one.Data.AuthorTrust != want {
+		t.Fatalf("Expected author_trust %.2f, got %d %s", want, w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != middleware.CacheRevalidate {
+		t.Errorf("Expected trust-bearing articles to be revalidated, got Cache-Control %q", cc)
+	}
+
+	var list struct {
+		Data []struct {
+			AuthorTrust *float64 `json:"author_trust"`
+		}
+	}
+	json.Unmarshal(get("/articles").Body.Bytes(), &list)
+	if len(list.Data) != 1 || list.Data[0].AuthorTrust == nil || *list.Data[0].AuthorTrust != want {
+		t.Errorf("Expected author_trust on listed articles, got %+v", list.Data)
+	}
+}
+
+func TestReputationPersistence(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	store := badger.NewReputationRepo(env.DB)
+
+	// 1. Scores survive a restart once snapshotted
+	before := p2p.NewReputationSystem(log)
+	before.SetStore(store)
+	before.RecordEvent(&p2p.ReputationEvent{DID: "alice", EventType: p2p.EventVerified})
+	before.RecordVote("bob", 0, 1)
+	if err := before.Snapshot(ctx); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	before.RecordEvent(&p2p.ReputationEvent{DID: "carol", EventType: p2p.EventArticlePost})
+	before.Stop() // saves carol
+
+	after := p2p.NewReputationSystem(log)
+	after.SetStore(store)
+	if err := after.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := after.GetScore("alice").Score; got != p2p.InitialScore+p2p.VerifiedBonus {
+		t.Errorf("Expected alice's score restored, got %.1f", got)
+	}
+	if got := after.GetScore("bob"); got.UpVotes != 1 {
+		t.Errorf("Expected bob's vote restored, got %+v", got)
+	}
+	if got := after.GetScore("carol"); got.ArticleCount != 1 {
+		t.Errorf("Expected the final snapshot to save carol, got %+v", got)
+	}
+
+	// 2. Importing an export replaces every stored score
+	other := p2p.NewReputationSystem(log)
+	other.RecordEvent(&p2p.ReputationEvent{DID: "dave", EventType: p2p.EventSpam})
+	export, err := other.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := handlers.NewReputationHandler(after, log)
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/export", h.Export)
+	engine.POST("/import", h.Import)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/import", bytes.NewReader(export)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Import failed: %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader("not json")))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed export, got %d", w.Code)
+	}
+
+	stored, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].DID != "dave" || stored[0].Score != p2p.InitialScore+p2p.SpamPenalty {
+		t.Errorf("Expected only dave stored after the import, got %+v", stored)
+	}
+
+	// 3. The export round-trips
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	var exported map[string]p2p.ReputationScore
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil || len(exported) != 1 || exported["dave"].DID != "dave" {
+		t.Errorf("Unexpected export: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestRequestIDPropagation(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.RequestIDMiddleware(), middleware.LoggerMiddleware(log))
+	engine.GET("/work", func(c *gin.Context) {
+		// What a handler or service does with the request context
+		log.Ctx(c.Request.Context()).Info("Doing work")
+		c.String(http.StatusOK, logger.RequestID(c.Request.Context()))
+	})
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/work", nil)
+		if id != "" {
+			req.Header.Set(middleware.RequestIDHeader, id)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	// An incoming ID is reused, returned and attached to every entry
+	w := get("trace-abc-123")
+	if got := w.Header().Get(middleware.RequestIDHeader); got != "trace-abc-123" || w.Body.String() != got {
+		t.Errorf("Expected the caller's ID to propagate, got header %q body %q", got, w.Body.String())
+	}
+	entries := logs.TakeAll()
+	if len(entries) != 2 {
+		t.Fatalf("Expected handler and access log entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.ContextMap()["request_id"] != "trace-abc-123" {
+			t.Errorf("Entry %q missing request_id: %v", entry.Message, entry.ContextMap())
+		}
+	}
+
+	// Missing or unsafe IDs are replaced with a fresh one
+	for _, id := range []string{"", "bad id\r\nX-Injected: 1", strings.Repeat("x", 200)} {
+		w := get(id)
+		got := w.Header().Get(middleware.RequestIDHeader)
+		if got == "" || got == id || w.Body.String() != got {
+			t.Errorf("Expected a generated ID for %q, got %q", id, got)
+		}
+	}
+
+	// Requests get distinct IDs
+	if get("").Header().Get(middleware.RequestIDHeader) == get("").Header().Get(middleware.RequestIDHeader) {
+		t.Error("Expected distinct generated IDs")
+	}
+}
@@ -0,0 +1,162 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/config"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestRetentionSweep(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "ivan",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	local, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Written here",
+		Body:     "Body text",
+		Category: "sports",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	// Synced articles are signed by keys no local user holds
+	now := time.Now()
+	synced := []struct {
+		id, category string
+		age          time.Duration
+	}{
+		{"old-sports", "sports", 48 * time.Hour},
+		{"news-1", "news", 1 * time.Hour},
+		{"news-2", "news", 2 * time.Hour},
+		{"news-3", "news", 3 * time.Hour},
+		{"news-4", "news", 4 * time.Hour},
+	}
+	for _, s := range synced {
+		if err := env.ArticleRepo.Create(ctx, &domain.Article{
+			ID:           s.id,
+			CID:          "cid-" + s.id,
+			Title:        s.id,
+			Body:         "Synced body",
+			Author:       "remote",
+			AuthorPubKey: "remote-key",
+			Category:     s.category,
+			Timestamp:    now.Add(-s.age),
+			Version:      1,
+		}); err != nil {
+			t.Fatalf("Failed to store synced article: %v", err)
+		}
+	}
+
+	// 6 articles in 6000 bytes puts each at 1000; a 3500 byte quota needs
+	// three removed in all
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "store.vlog"), make([]byte, 6000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sweeper := service.NewRetentionService(env.ArticleRepo, env.UserRepo, service.StorageQuota{
+		MaxDiskUsage:      3500,
+		MaxSyncedArticles: 3,
+		CategoryMaxAge:    map[string]time.Duration{"sports": 24 * time.Hour},
+		Paths:             []string{dir},
+	}, log)
+
+	// 1. Stats report usage against the quota
+	stats, err := sweeper.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.DiskUsage != 6000 || stats.Articles != 6 || stats.SyncedArticles != 5 || stats.SyncedByCategory["news"] != 4 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if stats.CategoryRetention["sports"] != "24h0m0s" || stats.LastSweep != nil {
+		t.Errorf("Unexpected retention in stats %+v", stats)
+	}
+
+	// 2. A dry run lists candidates, oldest synced first within each rule
+	report, err := sweeper.Run(ctx, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	want := []domain.RetentionRemoval{
+		{ArticleID: "old-sports", Reason: domain.RetentionReasonCategory},
+		{ArticleID: "news-4", Reason: domain.RetentionReasonCount},
+		{ArticleID: "news-3", Reason: domain.RetentionReasonDisk},
+	}
+	if len(report.Removed) != len(want) {
+		t.Fatalf("Expected %d candidates, got %+v", len(want), report.Removed)
+	}
+	for i, removal := range report.Removed {
+		if removal.ArticleID != want[i].ArticleID || removal.Reason != want[i].Reason {
+			t.Errorf("Candidate %d: expected %s (%s), got %s (%s)", i, want[i].ArticleID, want[i].Reason, removal.ArticleID, removal.Reason)
+		}
+	}
+	if _, err := env.ArticleRepo.GetByID(ctx, "old-sports"); err != nil {
+		t.Errorf("Dry run removed an article: %v", err)
+	}
+
+	// 3. A real run removes them and keeps the local article
+	if report, err = sweeper.Run(ctx, false); err != nil || len(report.Removed) != 3 || report.Failed != 0 {
+		t.Fatalf("Sweep failed: %+v, %v", report, err)
+	}
+	for _, removal := range want {
+		if _, err := env.ArticleRepo.GetByID(ctx, removal.ArticleID); err != domain.ErrArticleNotFound {
+			t.Errorf("Expected %s removed, got %v", removal.ArticleID, err)
+		}
+	}
+	for _, id := range []string{local.ID, "news-1", "news-2"} {
+		if _, err := env.ArticleRepo.GetByID(ctx, id); err != nil {
+			t.Errorf("Expected %s kept: %v", id, err)
+		}
+	}
+	if stats, _ = sweeper.Stats(ctx); stats.SyncedArticles != 2 || stats.LastSweep == nil {
+		t.Errorf("Expected stats after the sweep, got %+v", stats)
+	}
+}
+
+func TestRetentionConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	file := filepath.Join(t.TempDir(), "node.yaml")
+	yaml := "auth:\n  jwt_secret: an-integration-test-secret-of-enough-length\n" +
+		"retention:\n  enabled: true\n  max_disk_usage: 1073741824\n  max_synced_articles: 5000\n  categories:\n    sports: 720h\n    entertainment: 168h\n"
+	if err := os.WriteFile(file, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := config.LoadWithOverrides(config.Overrides{ConfigFile: file})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	r := cfg.Retention
+	if r.Interval != time.Hour || r.MaxDiskUsage != 1<<30 || r.MaxSyncedArticles != 5000 {
+		t.Errorf("Unexpected retention config %+v", r)
+	}
+	if r.Categories["sports"] != 720*time.Hour || r.Categories["entertainment"] != 168*time.Hour {
+		t.Errorf("Expected category durations, got %v", r.Categories)
+	}
+
+	if err := os.WriteFile(file, []byte("auth:\n  jwt_secret: an-integration-test-secret-of-enough-length\nretention:\n  max_synced_articles: -1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.LoadWithOverrides(config.Overrides{ConfigFile: file}); err == nil {
+		t.Error("Expected a negative limit to be rejected")
+	}
+}
@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestArticleRevisionGraph(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	dag := mocks.NewMockDAGStore()
+	env.ArticleService.SetDAGStore(dag)
+
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "frank",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "First draft",
+		Body:     "Original body",
+		Category: "technology",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	if article.NodeCID == "" {
+		t.Fatal("Expected a revision node to be published")
+	}
+
+	// 1. Each update links a new signed node to the previous one
+	for _, title := range []string{"Second draft", "Final"} {
+		if _, err := env.ArticleService.Update(ctx, article.ID, &domain.ArticleUpdateRequest{Title: title}, user.ID); err != nil {
+			t.Fatalf("Failed to update article: %v", err)
+		}
+	}
+
+	revisions, err := env.ArticleService.Revisions(ctx, article.CID)
+	if err != nil {
+		t.Fatalf("Revisions failed: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("Expected 3 revisions, got %d", len(revisions))
+	}
+	for i, want := range []string{"Final", "Second draft", "First draft"} {
+		rev := revisions[i]
+		if !rev.Verified || rev.Node.Title != want || rev.Node.Version != 3-i {
+			t.Errorf("Revision %d: got title %q version %d verified %v", i, rev.Node.Title, rev.Node.Version, rev.Verified)
+		}
+	}
+	if revisions[2].Node.Previous != nil {
+		t.Error("Expected the first revision to have no previous link")
+	}
+	// Each revision links its own content, which the article's CID follows
+	for i, rev := range revisions {
+		if rev.Node.Content == nil {
+			t.Fatalf("Revision %d: expected a content link", i)
+		}
+		published, err := domain.FromJSON(env.IPFS.Storage[rev.Node.Content.CID])
+		if err != nil || published.Title != rev.Node.Title {
+			t.Errorf("Revision %d: expected content titled %q, got %v (%v)", i, rev.Node.Title, published, err)
+		}
+	}
+	if current, err := env.ArticleService.GetByID(ctx, article.ID); err != nil || current.CID != revisions[0].Node.Content.CID {
+		t.Errorf("Expected the article's CID to be the latest content, got %v (%v)", current, err)
+	}
+
+	// 2. The current article still verifies after re-signing
+	valid, err := env.ArticleService.VerifySignature(ctx, article.CID)
+	if err != nil || !valid {
+		t.Errorf("Expected updated article to verify, got %v (%v)", valid, err)
+	}
+
+	// 3. Partial fetches resolve single fields and follow links
+	title, err := env.ArticleService.NodeField(ctx, article.CID, "title")
+	if err != nil || title != "Final" {
+		t.Errorf("Expected title field, got %v (%v)", title, err)
+	}
+	previousTitle, err := env.ArticleService.NodeField(ctx, article.CID, "previous/title")
+	if err != nil || previousTitle != "Second draft" {
+		t.Errorf("Expected previous revision title, got %v (%v)", previousTitle, err)
+	}
+	if _, err := env.ArticleService.NodeField(ctx, article.CID, "../x"); err != service.ErrInvalidNodePath {
+		t.Errorf("Expected ErrInvalidNodePath, got %v", err)
+	}
+
+	// 4. A tampered revision stops the walk
+	latest := dag.Nodes[revisions[0].CID].(map[string]interface{})
+	latest["body"] = "Injected"
+	revisions, err = env.ArticleService.Revisions(ctx, article.CID)
+	if err != nil {
+		t.Fatalf("Revisions failed: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Verified {
+		t.Errorf("Expected walk to stop at the tampered revision, got %d revisions", len(revisions))
+	}
+}
+
+func TestIncomingRevisionReplacesArticle(t *testing.T) {
+	author := SetupTestEnv(t)
+	defer author.Cleanup()
+	peer := SetupTestEnv(t)
+	defer peer.Cleanup()
+
+	ctx := context.Background()
+	user, err := author.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "grace",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	original, err := author.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title:    "Draft",
+		Body:     "Original body",
+		Category: "technology",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	if err := peer.ArticleService.HandleIncomingArticle(original); err != nil {
+		t.Fatalf("Failed to receive article: %v", err)
+	}
+
+	revision, err := author.ArticleService.Update(ctx, original.ID, &domain.ArticleUpdateRequest{Title: "Corrected"}, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to update article: %v", err)
+	}
+
+	// 1. A newer signed revision replaces the peer's copy
+	if err := peer.ArticleService.HandleIncomingArticle(revision); err != nil {
+		t.Fatalf("Failed to receive revision: %v", err)
+	}
+	got, err := peer.ArticleRepo.GetByID(ctx, original.ID)
+	if err != nil || got.Title != "Corrected" || got.Version != revision.Version {
+		t.Fatalf("Expected the revision to be stored, got %+v (%v)", got, err)
+	}
+
+	// 2. Replaying the older version changes nothing
+	if err := peer.ArticleService.HandleIncomingArticle(original); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if got, _ := peer.ArticleRepo.GetByID(ctx, original.ID); got.Title != "Corrected" {
+		t.Errorf("Expected the older version to be ignored, got %q", got.Title)
+	}
+
+	// 3. A forged revision is rejected
+	forged := *revision
+	forged.Version++
+	forged.Body = "Injected"
+	if err := peer.ArticleService.HandleIncomingArticle(&forged); err == nil {
+		t.Error("Expected a forged revision to fail verification")
+	}
+	if got, _ := peer.ArticleRepo.GetByID(ctx, original.ID); got.Body != revision.Body {
+		t.Errorf("Expected the forged revision to be ignored, got %q", got.Body)
+	}
+}
@@ -0,0 +1,564 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// setupSearchIndex opens a temporary Bleve index seeded with articles
+func setupSearchIndex(t *testing.T, articles ...*domain.Article) *search.BleveIndex {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "newsp2p-search-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	log, _ := logger.New("error", "text")
+	index := search.NewBleveIndex(log)
+	if err := index.Open(filepath.Join(tmpDir, "search.bleve")); err != nil {
+		t.Fatalf("Failed to open index: %v", err)
+	}
+	t.Cleanup(func() { index.Close() })
+
+	for _, article := range articles {
+		if err := index.IndexArticle(context.Background(), article); err != nil {
+			t.Fatalf("Failed to index article: %v", err)
+		}
+	}
+	return index
+}
+
+func TestFuzzyAndPrefixSearch(t *testing.T) {
+	index := setupSearchIndex(t,
+		&domain.Article{ID: "a1", Title: "Government budget approved", Body: "Parliament voted today.", Timestamp: time.Now()},
+		&domain.Article{ID: "a2", Title: "Decentralized networks", Body: "Peers share content.", Timestamp: time.Now()},
+	)
+	ctx := context.Background()
+
+	cases := []struct {
+		name  string
+		query *search.SearchQuery
+		want  string
+	}{
+		{"fuzzy typo", &search.SearchQuery{Query: "goverment", Fuzziness: 2}, "a1"},
+		{"prefix", &search.SearchQuery{Query: "decentra", Prefix: true}, "a2"},
+		{"wildcard", &search.SearchQuery{Query: "parlia*"}, "a1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := index.Search(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if len(result.IDs) != 1 || result.IDs[0] != tc.want {
+				t.Errorf("Expected [%s], got %v", tc.want, result.IDs)
+			}
+		})
+	}
+
+	// Without fuzziness the typo finds nothing
+	result, err := index.Search(ctx, &search.SearchQuery{Query: "goverment"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if result.Total != 0 {
+		t.Errorf("Expected no exact matches for typo, got %v", result.IDs)
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	index := setupSearchIndex(t,
+		&domain.Article{ID: "h1", Title: "Mesh networks", Body: "Community mesh networks keep <neighbours> online.", Timestamp: time.Now()},
+	)
+
+	result, err := index.Search(context.Background(), &search.SearchQuery{Query: "mesh"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	hl, ok := result.Highlights["h1"]
+	if !ok || len(hl.Body) == 0 || len(hl.Title) == 0 {
+		t.Fatalf("Expected title and body highlights, got %+v", result.Highlights)
+	}
+	if !strings.Contains(hl.Body[0], "<mark>mesh</mark>") {
+		t.Errorf("Expected marked term in body fragment, got %q", hl.Body[0])
+	}
+	if strings.Contains(hl.Body[0], "<neighbours>") {
+		t.Errorf("Expected fragment text to be HTML-escaped, got %q", hl.Body[0])
+	}
+}
+
+func TestSearchSuggest(t *testing.T) {
+	index := setupSearchIndex(t,
+		&domain.Article{ID: "s1", Title: "Privacy tools roundup", Tags: []string{"privacy"}, Timestamp: time.Now()},
+		&domain.Article{ID: "s2", Title: "Private relays explained", Tags: []string{"privacy"}, Timestamp: time.Now()},
+		&domain.Article{ID: "s3", Title: "Weather report", Timestamp: time.Now()},
+	)
+
+	suggestions, err := index.Suggest(context.Background(), "priv", 5)
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+
+	if len(suggestions) == 0 || suggestions[0].Text != "privacy" || suggestions[0].Kind != search.SuggestionTag {
+		t.Fatalf("Expected 'privacy' tag as top suggestion, got %+v", suggestions)
+	}
+
+	titles := 0
+	for _, s := range suggestions {
+		if s.Kind == search.SuggestionTitle {
+			titles++
+			if s.ID == "s3" {
+				t.Errorf("Unexpected title suggestion %q", s.Text)
+			}
+		}
+	}
+	if titles != 2 {
+		t.Errorf("Expected 2 title suggestions, got %+v", suggestions)
+	}
+}
+
+func TestSearchSortOrders(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+	articles := []*domain.Article{
+		{ID: "o1", Title: "Solar power", Body: "solar", Timestamp: base},
+		{ID: "o2", Title: "Solar solar farms", Body: "solar solar solar", Timestamp: base.Add(time.Minute)},
+		{ID: "o3", Title: "Wind and solar", Body: "wind", Timestamp: base.Add(2 * time.Minute)},
+	}
+
+	tmpDir := t.TempDir()
+	log, _ := logger.New("error", "text")
+	index := search.NewBleveIndex(log)
+	if err := index.Open(filepath.Join(tmpDir, "search.bleve")); err != nil {
+		t.Fatalf("Failed to open index: %v", err)
+	}
+	defer index.Close()
+
+	votes := map[string]float64{"o1": 7, "o2": 1, "o3": 3}
+	trust := map[string]float64{"o1": 10, "o2": 20, "o3": 90}
+	index.SetSignalProvider(search.SignalFunc(func(a *domain.Article) search.Signals {
+		return search.Signals{Votes: votes[a.ID], TrustScore: trust[a.ID]}
+	}))
+	for _, article := range articles {
+		if err := index.IndexArticle(context.Background(), article); err != nil {
+			t.Fatalf("Failed to index article: %v", err)
+		}
+	}
+
+	cases := []struct {
+		order search.SortOrder
+		want  string
+	}{
+		{search.SortNewest, "o3,o2,o1"},
+		{search.SortOldest, "o1,o2,o3"},
+		{search.SortMostVoted, "o1,o3,o2"},
+		{search.SortTrust, "o3,o2,o1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.order), func(t *testing.T) {
+			result, err := index.Search(context.Background(), &search.SearchQuery{Query: "solar", SortBy: tc.order})
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if got := strings.Join(result.IDs, ","); got != tc.want {
+				t.Errorf("Expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	if _, ok := search.ParseSortOrder("popular"); ok {
+		t.Error("Expected unknown sort order to be rejected")
+	}
+}
+
+func TestSearchIndexRebuild(t *testing.T) {
+	index := setupSearchIndex(t,
+		&domain.Article{ID: "stale", Title: "Stale entry", Body: "orphan", Timestamp: time.Now()},
+	)
+	ctx := context.Background()
+
+	source := []*domain.Article{
+		{ID: "r1", Title: "Rebuilt harbour story", Body: "harbour", Timestamp: time.Now()},
+		{ID: "r2", Title: "Deleted harbour story", Body: "harbour", Timestamp: time.Now()},
+	}
+
+	count, err := index.Rebuild(ctx, func(add func(*domain.Article) error) error {
+		// A live delete during the rebuild must win over the snapshot
+		if err := index.DeleteArticle(ctx, "r2"); err != nil {
+			return err
+		}
+		for _, article := range source {
+			if err := add(article); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 document written from source, got %d", count)
+	}
+
+	total, err := index.Count()
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("Expected 1 document after rebuild, got %d", total)
+	}
+
+	result, err := index.Search(ctx, &search.SearchQuery{Query: "harbour"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.IDs) != 1 || result.IDs[0] != "r1" {
+		t.Errorf("Expected [r1], got %v", result.IDs)
+	}
+
+	// The swapped-in index keeps accepting writes
+	if err := index.IndexArticle(ctx, &domain.Article{ID: "r3", Title: "After swap", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("IndexArticle after rebuild failed: %v", err)
+	}
+}
+
+func TestSearchReindexJob(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// More than one page of articles
+	base := time.Now().Add(-time.Hour)
+	const total = 501
+	for i := 0; i < total; i++ {
+		article := &domain.Article{
+			ID:        fmt.Sprintf("job-%03d", i),
+			CID:       fmt.Sprintf("QmJob%03d", i),
+			Title:     "Harbour report",
+			Author:    "alice",
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		}
+		if err := env.ArticleRepo.Create(context.Background(), article); err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+	}
+
+	log, _ := logger.New("error", "text")
+	searchService := service.NewSearchService(setupSearchIndex(t), env.ArticleRepo, 2, log)
+	if searchService.ReindexStatus() != nil {
+		t.Fatal("Expected no reindex status before the first run")
+	}
+
+	// The job outlives the request that started it
+	reqCtx, cancel := context.WithCancel(context.Background())
+	report, err := searchService.StartReindex(reqCtx)
+	cancel()
+	if err != nil {
+		t.Fatalf("StartReindex failed: %v", err)
+	}
+	if !report.Running {
+		t.Errorf("Expected the started reindex to be running, got %+v", report)
+	}
+
+	waitFor(t, "reindex to finish", func() bool {
+		return !searchService.ReindexStatus().Running
+	})
+	report = searchService.ReindexStatus()
+	if report.Error != "" || report.ArticlesRead != total || report.Documents != total {
+		t.Errorf("Expected %d articles reindexed, got %+v", total, report)
+	}
+
+	result, err := searchService.Search(context.Background(), &search.SearchQuery{Query: "harbour"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if result.Total != total {
+		t.Errorf("Expected %d hits after reindex, got %d", total, result.Total)
+	}
+}
+
+func TestSearchIndexMappingVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "search.bleve")
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	// An index from a version that didn't record its mapping
+	legacy, err := bleve.New(path, bleve.NewIndexMapping())
+	if err != nil {
+		t.Fatalf("Failed to create legacy index: %v", err)
+	}
+	legacy.Close()
+
+	index := search.NewBleveIndex(log)
+	if err := index.Open(path); err != nil {
+		t.Fatalf("Failed to open index: %v", err)
+	}
+	if !index.Outdated() {
+		t.Fatal("Expected a legacy index to be outdated")
+	}
+
+	_, err = index.Rebuild(ctx, func(add func(*domain.Article) error) error {
+		return add(&domain.Article{ID: "m1", Title: "Blockchain ledger", Timestamp: time.Now()})
+	})
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if index.Outdated() {
+		t.Error("Expected the rebuilt index to be current")
+	}
+	result, err := index.Search(ctx, &search.SearchQuery{Query: "block*"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.IDs) != 1 {
+		t.Errorf("Expected the wildcard to match after rebuild, got %v", result.IDs)
+	}
+	index.Close()
+
+	// The version survives a restart
+	reopened := search.NewBleveIndex(log)
+	if err := reopened.Open(path); err != nil {
+		t.Fatalf("Failed to reopen index: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.Outdated() {
+		t.Error("Expected the reopened index to be current")
+	}
+}
+
+func TestSearchRejectsLeadingWildcards(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	searchService := service.NewSearchService(setupSearchIndex(t), nil, 2, log)
+
+	for _, q := range []string{"*chain", "news ?ock", "b*"} {
+		_, err := searchService.Search(context.Background(), &search.SearchQuery{Query: q})
+		var validationErr *domain.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected %q to be rejected, got %v", q, err)
+		}
+	}
+	if _, err := searchService.Search(context.Background(), &search.SearchQuery{Query: "bl*"}); err != nil {
+		t.Errorf("Expected a bounded wildcard to run: %v", err)
+	}
+}
+
+func TestSearchComments(t *testing.T) {
+	index := setupSearchIndex(t,
+		&domain.Article{ID: "c-art", Title: "Community gardens", Body: "compost", Timestamp: time.Now()},
+	)
+	ctx := context.Background()
+
+	comment := &domain.Comment{ID: "c1", ArticleID: "c-art", Author: "bob", Body: "Great compost tips", CreatedAt: time.Now()}
+	if err := index.IndexComment(ctx, comment); err != nil {
+		t.Fatalf("IndexComment failed: %v", err)
+	}
+
+	cases := []struct {
+		docType      string
+		wantArticles int
+		wantComments int
+	}{
+		{"", 1, 0},
+		{search.DocTypeComment, 0, 1},
+		{search.DocTypeAll, 1, 1},
+	}
+	for _, tc := range cases {
+		result, err := index.Search(ctx, &search.SearchQuery{Query: "compost", DocType: tc.docType})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(result.IDs) != tc.wantArticles || len(result.Comments) != tc.wantComments {
+			t.Errorf("type %q: expected %d articles and %d comments, got %v and %d",
+				tc.docType, tc.wantArticles, tc.wantComments, result.IDs, len(result.Comments))
+		}
+	}
+
+	result, _ := index.Search(ctx, &search.SearchQuery{Query: "compost", DocType: search.DocTypeComment})
+	if len(result.Comments) == 1 {
+		hit := result.Comments[0]
+		if hit.ID != "c1" || hit.ArticleID != "c-art" || hit.Author != "bob" || hit.Timestamp.IsZero() {
+			t.Errorf("Unexpected comment hit: %+v", hit)
+		}
+	}
+
+	// Comments survive a rebuild even though the source only yields articles
+	if _, err := index.Rebuild(ctx, func(add func(*domain.Article) error) error { return nil }); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	result, _ = index.Search(ctx, &search.SearchQuery{Query: "compost", DocType: search.DocTypeComment})
+	if len(result.Comments) != 1 {
+		t.Errorf("Expected comment to survive rebuild, got %d", len(result.Comments))
+	}
+
+	if err := index.DeleteComment(ctx, "c1"); err != nil {
+		t.Fatalf("DeleteComment failed: %v", err)
+	}
+	result, _ = index.Search(ctx, &search.SearchQuery{Query: "compost", DocType: search.DocTypeComment})
+	if len(result.Comments) != 0 {
+		t.Errorf("Expected deleted comment to be gone, got %d", len(result.Comments))
+	}
+}
+
+func TestSearchMinTrust(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	trusted := &domain.Article{ID: "t1", Title: "Tidal energy", Body: "tidal", Author: "carol", Timestamp: time.Now()}
+	spammer := &domain.Article{ID: "t2", Title: "Tidal energy deals", Body: "tidal", Author: "mallory", Timestamp: time.Now()}
+	articles := []*domain.Article{trusted, spammer}
+	for _, article := range articles {
+		if err := env.ArticleRepo.Create(ctx, article); err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+	}
+	index := setupSearchIndex(t, articles...)
+
+	reputation := p2p.NewReputationSystem(log)
+	for i := 0; i < 5; i++ {
+		reputation.RecordEvent(&p2p.ReputationEvent{DID: "mallory", EventType: p2p.EventSpam})
+	}
+
+	searchService := service.NewSearchService(index, env.ArticleRepo, 2, log)
+	searchService.SetTrustScorer(reputation)
+
+	result, err := searchService.Search(ctx, &search.SearchQuery{Query: "tidal", MinTrust: 20})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Articles) != 1 || result.Articles[0].ID != "t1" {
+		t.Errorf("Expected [t1], got %v", articleIDs(result.Articles))
+	}
+	if result.FilteredLowTrust != 1 || result.Total != 1 {
+		t.Errorf("Expected 1 filtered and total 1, got %d and %d", result.FilteredLowTrust, result.Total)
+	}
+
+	// Without a threshold nothing is filtered
+	result, err = searchService.Search(ctx, &search.SearchQuery{Query: "tidal"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Articles) != 2 {
+		t.Errorf("Expected 2 articles, got %v", articleIDs(result.Articles))
+	}
+
+	// Low-trust articles are dropped before paging, so every page but the
+	// last is full and the totals count only what is kept
+	for i := 3; i <= 6; i++ {
+		author := "carol"
+		if i%2 == 0 {
+			author = "mallory"
+		}
+		article := &domain.Article{ID: fmt.Sprintf("t%d", i), Title: "Tidal power", Body: "tidal", Author: author, Timestamp: time.Now()}
+		if err := env.ArticleRepo.Create(ctx, article); err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		if err := index.IndexArticle(ctx, article); err != nil {
+			t.Fatalf("Failed to index article: %v", err)
+		}
+	}
+	seen := map[string]bool{}
+	for page := 1; page <= 2; page++ {
+		result, err = searchService.Search(ctx, &search.SearchQuery{Query: "tidal", MinTrust: 20, Page: page, Limit: 2})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if result.Total != 3 || result.TotalPages != 2 {
+			t.Errorf("Expected 3 results on 2 pages, got %d on %d", result.Total, result.TotalPages)
+		}
+		if want := 2 - (page - 1); len(result.Articles) != want {
+			t.Errorf("Expected %d articles on page %d, got %v", want, page, articleIDs(result.Articles))
+		}
+		for _, article := range result.Articles {
+			if article.Author != "carol" || seen[article.ID] {
+				t.Errorf("Unexpected article %s by %s on page %d", article.ID, article.Author, page)
+			}
+			seen[article.ID] = true
+		}
+	}
+}
+
+// countingIndex counts searches that reach the underlying index
+type countingIndex struct {
+	*search.BleveIndex
+	searches int
+}
+
+func (c *countingIndex) Search(ctx context.Context, query *search.SearchQuery) (*search.SearchResult, error) {
+	c.searches++
+	return c.BleveIndex.Search(ctx, query)
+}
+
+func TestSearchResultCache(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+
+	first := &domain.Article{ID: "k1", Title: "Kelp farming", Body: "kelp", Author: "a", Timestamp: time.Now()}
+	if err := env.ArticleRepo.Create(ctx, first); err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	index := &countingIndex{BleveIndex: setupSearchIndex(t, first)}
+
+	searchService := service.NewSearchService(index, env.ArticleRepo, 2, log)
+	searchService.SetResultCache(10, time.Minute)
+
+	result, err := searchService.Search(ctx, &search.SearchQuery{Query: "Kelp  Farming"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	// Callers may modify their copy, articles included
+	result.Articles[0].Title = "Changed by a caller"
+	result.Articles[0].Tags = append(result.Articles[0].Tags, "changed")
+	result.Articles = result.Articles[:0]
+
+	// Equivalent query is served from cache
+	result, err = searchService.Search(ctx, &search.SearchQuery{Query: "kelp farming"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if index.searches != 1 || len(result.Articles) != 1 {
+		t.Fatalf("Expected 1 index search and 1 article, got %d and %d", index.searches, len(result.Articles))
+	}
+	if result.Articles[0].Title != first.Title || len(result.Articles[0].Tags) != 0 {
+		t.Errorf("Expected the cached article unchanged, got %+v", result.Articles[0])
+	}
+
+	// Indexing a new article invalidates the cache
+	second := &domain.Article{ID: "k2", Title: "Kelp farming co-ops", Body: "kelp", Author: "a", Timestamp: time.Now()}
+	if err := env.ArticleRepo.Create(ctx, second); err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	if err := searchService.IndexArticle(ctx, second); err != nil {
+		t.Fatalf("IndexArticle failed: %v", err)
+	}
+
+	result, err = searchService.Search(ctx, &search.SearchQuery{Query: "kelp farming"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if index.searches != 2 || len(result.Articles) != 2 {
+		t.Errorf("Expected a fresh search with 2 articles, got %d searches and %d articles", index.searches, len(result.Articles))
+	}
+}
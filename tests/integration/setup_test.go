@@ -19,6 +19,7 @@ type TestEnv struct {
 	UserService    *service.UserService
 	ArticleService *service.ArticleService
 	JWTManager     *auth.JWTManager
+	IPFS           *mocks.MockIPFSClient
 	Cleanup        func()
 }
 
@@ -68,6 +69,7 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 		UserService:    userService,
 		ArticleService: articleService,
 		JWTManager:     jwtManager,
+		IPFS:           mockIPFS,
 		Cleanup: func() {
 			db.Close()
 			os.RemoveAll(tmpDir)
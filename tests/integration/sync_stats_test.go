@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// syncStore serves a fixed set of articles and stores what it receives,
+// refusing articles whose title is "bad"
+type syncStore struct {
+	articles map[string]*domain.Article
+}
+
+func (s *syncStore) GetRecent(ctx context.Context, limit int, since time.Time) ([]*domain.Article, error) {
+	articles := make([]*domain.Article, 0, len(s.articles))
+	for _, a := range s.articles {
+		articles = append(articles, a)
+	}
+	return articles, nil
+}
+
+func (s *syncStore) GetByID(ctx context.Context, id string) (*domain.Article, error) {
+	return s.articles[id], nil
+}
+
+func (s *syncStore) HasArticle(ctx context.Context, id string) bool {
+	_, ok := s.articles[id]
+	return ok
+}
+
+func (s *syncStore) HandleIncomingArticle(article *domain.Article) error {
+	if article.Title == "bad" {
+		return errors.New("invalid signature")
+	}
+	s.articles[article.ID] = article
+	return nil
+}
+
+func TestSyncStats(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	ctx := context.Background()
+
+	// B offers three articles: one A already has, one A refuses, one new
+	hostA, hostB := newTestHost(t), newTestHost(t)
+	storeA := &syncStore{articles: map[string]*domain.Article{"held": {ID: "held"}}}
+	storeB := &syncStore{articles: map[string]*domain.Article{
+		"held": {ID: "held"},
+		"bad":  {ID: "bad", Title: "bad"},
+		"new":  {ID: "new"},
+	}}
+	syncA := p2p.NewSyncService(hostA, storeA, storeA, log)
+	syncB := p2p.NewSyncService(hostB, storeB, storeB, log)
+
+	if err := hostA.Connect(ctx, peer.AddrInfo{ID: hostB.ID(), Addrs: hostB.Addrs()}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	syncA.TriggerSync()
+	waitFor(t, "the sync round", func() bool { return syncA.SyncStats().Rounds == 1 })
+	waitFor(t, "B to record the request", func() bool { return len(syncB.SyncStats().Peers) == 1 })
+
+	stats := syncA.SyncStats()
+	if len(stats.Peers) != 1 {
+		t.Fatalf("Expected stats for one peer, got %+v", stats.Peers)
+	}
+	got := stats.Peers[0]
+	if got.PeerID != hostB.ID().String() || !got.Connected || got.Pulls != 1 || got.Failures != 0 ||
+		got.Offered != 3 || got.Duplicates != 1 || got.Accepted != 1 || got.Rejected != 1 ||
+		got.LastSuccess == nil || got.LastError != "" {
+		t.Errorf("Unexpected pull stats: %+v", got)
+	}
+	if stats.Totals.Accepted != 1 || stats.Totals.Pulls != 1 || stats.LastRound == nil {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+	if _, ok := storeA.articles["new"]; !ok {
+		t.Error("Expected the new article to be stored")
+	}
+
+	served := syncB.SyncStats().Peers[0]
+	if served.PeerID != hostA.ID().String() || served.Serves != 1 || served.Sent != 3 || served.Pulls != 0 {
+		t.Errorf("Unexpected serve stats: %+v", served)
+	}
+
+	// Stats outlive the connection, unlike the last outcome per peer
+	hostB.Close()
+	waitFor(t, "the disconnect", func() bool { return len(hostA.Network().Peers()) == 0 })
+	after := syncA.SyncStats().Peers
+	if len(after) != 1 || after[0].Accepted != 1 || after[0].Connected {
+		t.Errorf("Expected stats to outlive the connection, got %+v", after)
+	}
+}
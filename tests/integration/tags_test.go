@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	badgerrepo "github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+)
+
+func TestTagIndex(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "tagger",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	var articles []*domain.Article
+	for _, tags := range [][]string{{"mesh", "Radio"}, {"mesh"}, {"solar"}} {
+		article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+			Title:    "Tagged",
+			Body:     "Tagged content",
+			Category: "technology",
+			Tags:     tags,
+		}, user.ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		articles = append(articles, article)
+	}
+
+	// 1. Tags are counted case-insensitively, most used first
+	tags, err := env.ArticleService.ListTags(ctx, 0)
+	if err != nil {
+		t.Fatalf("Failed to list tags: %v", err)
+	}
+	if len(tags) != 3 || tags[0] != (domain.TagCount{Tag: "mesh", Count: 2}) {
+		t.Fatalf("Expected mesh first of 3 tags, got %+v", tags)
+	}
+
+	listed, total, err := env.ArticleService.List(ctx, &domain.ArticleListFilter{Tags: []string{"RADIO"}})
+	if err != nil {
+		t.Fatalf("Failed to list by tag: %v", err)
+	}
+	if total != 1 || listed[0].ID != articles[0].ID {
+		t.Fatalf("Expected the radio article, got %d articles", total)
+	}
+
+	// 2. Editing the tags moves the article between tag listings
+	if _, err := env.ArticleService.Update(ctx, articles[2].ID, &domain.ArticleUpdateRequest{
+		Tags: []string{"mesh"},
+	}, user.ID); err != nil {
+		t.Fatalf("Failed to update article: %v", err)
+	}
+
+	_, total, err = env.ArticleService.List(ctx, &domain.ArticleListFilter{Tags: []string{"mesh"}})
+	if err != nil || total != 3 {
+		t.Fatalf("Expected 3 mesh articles after the edit, got %d (%v)", total, err)
+	}
+	_, total, err = env.ArticleService.List(ctx, &domain.ArticleListFilter{Tags: []string{"solar"}})
+	if err != nil || total != 0 {
+		t.Fatalf("Expected no solar articles after the edit, got %d (%v)", total, err)
+	}
+
+	// 3. The index stays consistent through the edit
+	issues, err := badgerrepo.NewArticleRepo(env.DB).CheckIndexes(ctx, false)
+	if err != nil {
+		t.Fatalf("Index check failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Expected a clean index, got %+v", issues)
+	}
+}
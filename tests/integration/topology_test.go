@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// newTestNode starts a P2P node on loopback, subscribed to news/test
+func newTestNode(ctx context.Context, t *testing.T) *p2p.P2PNode {
+	log, _ := logger.New("error", "text")
+	node, err := p2p.NewP2PNode(ctx, &p2p.Config{
+		ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"},
+		ProtocolID:  "/liberation/1.0.0",
+		Rendezvous:  "newsp2p-test",
+		DataDir:     t.TempDir(),
+	}, log)
+	if err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	t.Cleanup(func() { node.Close() })
+	if _, err := node.JoinTopic("news/test"); err != nil {
+		t.Fatalf("Failed to join topic: %v", err)
+	}
+	if _, err := node.Subscribe("news/test"); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	return node
+}
+
+func TestNetworkTopology(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, b := newTestNode(ctx, t), newTestNode(ctx, t)
+	if err := a.GetHost().Connect(ctx, peer.AddrInfo{ID: b.GetPeerID(), Addrs: b.GetHost().Addrs()}); err != nil {
+		t.Fatalf("Failed to connect nodes: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/topology", handlers.NewNetworkHandler(a, nil, log).GetTopology)
+	engine.GET("/disabled", handlers.NewNetworkHandler(nil, nil, log).GetTopology)
+
+	var resp struct{ Data p2p.Topology }
+	fetch := func() {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/topology", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Topology failed: %d %s", w.Code, w.Body.String())
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+	}
+
+	// Identify and topic membership settle shortly after connecting
+	deadline := time.Now().Add(5 * time.Second)
+	for fetch(); time.Now().Before(deadline); fetch() {
+		if len(resp.Data.Peers) == 1 && len(resp.Data.Peers[0].Topics) == 1 && len(resp.Data.Peers[0].Protocols) > 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if resp.Data.Self.ID != a.GetPeerID().String() || len(resp.Data.Self.Protocols) == 0 {
+		t.Errorf("Unexpected self: %+v", resp.Data.Self)
+	}
+	if len(resp.Data.Peers) != 1 || resp.Data.Peers[0].ID != b.GetPeerID().String() {
+		t.Fatalf("Expected one peer, got %+v", resp.Data.Peers)
+	}
+	if got := resp.Data.Peers[0]; len(got.Topics) != 1 || got.Topics[0] != "news/test" || len(got.Protocols) == 0 {
+		t.Errorf("Expected the peer's topics and protocols, got %+v", got)
+	}
+	if len(resp.Data.Links) != 1 {
+		t.Fatalf("Expected one link, got %+v", resp.Data.Links)
+	}
+	link := resp.Data.Links[0]
+	if link.Source != a.GetPeerID().String() || link.Target != b.GetPeerID().String() ||
+		link.Transport != "tcp" || link.Direction != "outbound" || link.Connections != 1 {
+		t.Errorf("Unexpected link: %+v", link)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/disabled", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a P2P node, got %d", w.Code)
+	}
+}
@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestArticleCreationTrace(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// Record spans in memory in place of an OTLP exporter
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/add" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"Name": "article", "Hash": "QmTraced", "Size": "1"})
+	}))
+	defer daemon.Close()
+
+	log, _ := logger.New("error", "text")
+	articleRepo := repository.NewInstrumentedArticleRepo(env.ArticleRepo, nil)
+	userRepo := repository.NewInstrumentedUserRepo(env.UserRepo, nil)
+	searchService := service.NewSearchService(setupSearchIndex(t), articleRepo, 1, log)
+	articles := service.NewArticleService(
+		articleRepo,
+		userRepo,
+		ipfs.NewClient(daemon.URL, time.Minute, false, log),
+		&MockBroadcaster{},
+		auth.NewArticleSigner(),
+		searchService,
+		log,
+	)
+
+	user, err := env.UserService.Register(context.Background(), &domain.UserRegisterRequest{
+		Username: "tracer",
+		Email:    "tracer@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	core, logs := observer.New(zap.InfoLevel)
+	accessLog := &logger.Logger{Logger: zap.New(core)}
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.RequestIDMiddleware(), middleware.TracingMiddleware(), middleware.LoggerMiddleware(accessLog))
+	engine.POST("/articles", func(c *gin.Context) {
+		_, err := articles.Create(c.Request.Context(), &domain.ArticleCreateRequest{
+			Title: "Traced article",
+			Body:  "Every step of this creation should show up in one trace.",
+		}, user.ID, c.ClientIP())
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	// The caller's trace is joined rather than a new one started
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/articles", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", w.Code)
+	}
+
+	// The broadcast finishes in the background
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	deadline := time.Now().Add(5 * time.Second)
+	for byName["article.broadcast"] == nil && time.Now().Before(deadline) {
+		for _, span := range recorder.Ended() {
+			if span.SpanContext().TraceID().String() == traceID {
+				byName[span.Name()] = span
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	parentOf := map[string]string{
+		"article.create":       "POST /articles",
+		"repo.user.get_by_id":  "article.create",
+		"article.sign":         "article.create",
+		"ipfs.add":             "article.create",
+		"repo.article.create":  "article.create",
+		"search.index_article": "article.create",
+		"article.broadcast":    "article.create",
+	}
+	server := byName["POST /articles"]
+	if server == nil {
+		t.Fatalf("Expected a server span in the caller's trace, got %v", spanNames(byName))
+	}
+	if got := server.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("Expected the server span under the caller's span, got parent %s", got)
+	}
+	for name, parent := range parentOf {
+		span := byName[name]
+		if span == nil {
+			t.Errorf("Missing span %q in %v", name, spanNames(byName))
+			continue
+		}
+		if span.Parent().SpanID() != byName[parent].SpanContext().SpanID() {
+			t.Errorf("Expected %q under %q", name, parent)
+		}
+	}
+
+	// The access log entry can be matched to the trace
+	entries := logs.FilterMessage("HTTP Request").All()
+	if len(entries) != 1 || entries[0].ContextMap()["trace_id"] != traceID {
+		t.Errorf("Expected the access log tagged with trace_id %s, got %v", traceID, entries)
+	}
+}
+
+func spanNames(spans map[string]sdktrace.ReadOnlySpan) string {
+	names := make([]string, 0, len(spans))
+	for name := range spans {
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,235 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/repository/badger"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+func TestTrustGraph(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	trust := service.NewTrustGraphService(badger.NewTrustEdgeRepo(env.DB), env.UserRepo, env.ArticleRepo, 3, 0.5, log)
+	comments := service.NewCommentService(badger.NewCommentRepo(env.DB), env.ArticleRepo, env.UserRepo, log)
+	reputation := handlers.NewReputationHandler(nil, log)
+	reputation.SetTrustGraph(trust)
+	commentHandler := handlers.NewCommentHandler(comments, log)
+	commentHandler.SetTrustGraph(trust)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/articles/:cid/comments", middleware.OptionalAuthMiddleware(env.JWTManager), commentHandler.List)
+	authed := engine.Group("/trust", middleware.AuthMiddleware(env.JWTManager))
+	authed.GET("/edges", reputation.TrustEdges)
+	authed.PUT("/edges/:did", reputation.SetTrustEdge)
+	authed.DELETE("/edges/:did", reputation.RemoveTrustEdge)
+	authed.GET("/network", reputation.TrustNetwork)
+	authed.GET("/network/:did", reputation.TrustScore)
+	authed.GET("/feed", reputation.TrustFeed)
+
+	ctx := context.Background()
+	tokens := make(map[string]string)
+	users := make(map[string]*domain.UserResponse)
+	for _, name := range []string{"me", "alice", "bob", "carol", "dave", "eve", "spam", "mallory"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		users[name] = user
+		tokens[name], _, _ = env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	}
+
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if user != "" {
+			req.Header.Set("Authorization", "Bearer "+tokens[user])
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	mark := func(user, subject, kind string) {
+		t.Helper()
+		if w := do(user, http.MethodPut, "/trust/edges/"+subject, `{"kind":"`+kind+`"}`); w.Code != http.StatusOK {
+			t.Fatalf("Failed to mark %s as %s for %s: %d %s", subject, kind, user, w.Code, w.Body.String())
+		}
+	}
+
+	// 1. Marks are validated and need a signed-in user
+	if w := do("", http.MethodPut, "/trust/edges/alice", `{"kind":"trusted"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", w.Code)
+	}
+	if w := do("me", http.MethodPut, "/trust/edges/alice", `{"kind":"friend"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown kind, got %d", w.Code)
+	}
+	if w := do("me", http.MethodPut, "/trust/edges/me", `{"kind":"trusted"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for marking yourself, got %d", w.Code)
+	}
+	if w := do("me", http.MethodDelete, "/trust/edges/alice", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing mark, got %d", w.Code)
+	}
+
+	// 2. A graph: me -> alice -> bob -> carol -> dave, with blocks along the way
+	mark("me", "alice", domain.TrustTrusted)
+	mark("alice", "bob", domain.TrustTrusted)
+	mark("bob", "carol", domain.TrustTrusted)
+	mark("carol", "dave", domain.TrustTrusted) // four hops out, beyond max_depth
+	mark("alice", "spam", domain.TrustBlocked) // a contact's block stands
+	mark("bob", "eve", domain.TrustBlocked)    // ...unless overridden by my own trust
+	mark("me", "eve", domain.TrustTrusted)
+	mark("alice", "mallory", domain.TrustTrusted) // my own block beats a contact's trust
+	mark("me", "mallory", domain.TrustBlocked)
+	mark("mallory", "dave", domain.TrustTrusted) // blocked identities don't pass trust on
+
+	w := do("me", http.MethodGet, "/trust/network", "")
+	var network struct{ Data []domain.TrustScore }
+	if err := json.Unmarshal(w.Body.Bytes(), &network); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Failed to get network: %d %s", w.Code, w.Body.String())
+	}
+	scores := make(map[string]domain.TrustScore)
+	var order []string
+	for _, score := range network.Data {
+		scores[score.Subject] = score
+		order = append(order, score.Subject)
+	}
+	want := map[string]domain.TrustScore{
+		"alice":   {Subject: "alice", Trust: 1, Depth: 1, Via: "alice"},
+		"eve":     {Subject: "eve", Trust: 1, Depth: 1, Via: "eve"},
+		"bob":     {Subject: "bob", Trust: 0.5, Depth: 2, Via: "alice"},
+		"carol":   {Subject: "carol", Trust: 0.25, Depth: 3, Via: "alice"},
+		"spam":    {Subject: "spam", Trust: 0, Depth: 2, Via: "alice", Blocked: true},
+		"mallory": {Subject: "mallory", Trust: 0.5, Depth: 2, Via: "alice", Blocked: true},
+	}
+	for subject, expected := range want {
+		if scores[subject] != expected {
+			t.Errorf("Expected %+v, got %+v", expected, scores[subject])
+		}
+	}
+	if _, ok := scores["dave"]; ok || len(scores) != len(want) {
+		t.Errorf("Expected dave out of reach, got %v", order)
+	}
+	if !slices.Equal(order, []string{"alice", "eve", "bob", "carol", "mallory", "spam"}) {
+		t.Errorf("Expected trusted identities strongest first, then blocked ones, got %v", order)
+	}
+
+	w = do("me", http.MethodGet, "/trust/network/dave", "")
+	var one struct{ Data domain.TrustScore }
+	json.Unmarshal(w.Body.Bytes(), &one)
+	if w.Code != http.StatusOK || one.Data != (domain.TrustScore{Subject: "dave"}) {
+		t.Errorf("Expected no trust in dave, got %d %+v", w.Code, one.Data)
+	}
+
+	// 3. Edges in both directions; blocks aren't revealed to their subject
+	var edges struct{ Data []domain.TrustEdge }
+	w = do("alice", http.MethodGet, "/trust/edges", "")
+	json.Unmarshal(w.Body.Bytes(), &edges)
+	if len(edges.Data) != 3 || edges.Data[0].Subject != "bob" || edges.Data[2].Kind != domain.TrustBlocked {
+		t.Errorf("Expected alice's three marks ordered by subject, got %+v", edges.Data)
+	}
+	w = do("eve", http.MethodGet, "/trust/edges?direction=in", "")
+	edges.Data = nil
+	json.Unmarshal(w.Body.Bytes(), &edges)
+	if len(edges.Data) != 1 || edges.Data[0].Truster != "me" {
+		t.Errorf("Expected only me to show as trusting eve, got %+v", edges.Data)
+	}
+	if w := do("eve", http.MethodGet, "/trust/edges?direction=sideways", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown direction, got %d", w.Code)
+	}
+
+	// 4. The feed holds articles by trusted, unblocked identities
+	publish := func(author, title string) *domain.Article {
+		article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{Title: title, Body: "Body of " + title, Category: "news"}, users[author].ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		return article
+	}
+	for _, author := range []string{"alice", "bob", "carol", "dave", "eve", "spam", "mallory", "me"} {
+		publish(author, "News from "+author)
+	}
+	w = do("me", http.MethodGet, "/trust/feed", "")
+	var feed struct {
+		Data       []domain.TrustFeedItem
+		Pagination struct{ Total int }
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Failed to get feed: %d %s", w.Code, w.Body.String())
+	}
+	var authors []string
+	for _, item := range feed.Data {
+		authors = append(authors, item.Author)
+		if item.AuthorTrust != scores[item.Author] {
+			t.Errorf("Expected %s's trust on the item, got %+v", item.Author, item.AuthorTrust)
+		}
+	}
+	slices.Sort(authors)
+	if !slices.Equal(authors, []string{"alice", "bob", "carol", "eve"}) || feed.Pagination.Total != 4 {
+		t.Errorf("Expected the four trusted authors' articles, got %v (total %d)", authors, feed.Pagination.Total)
+	}
+	w = do("dave", http.MethodGet, "/trust/feed", "")
+	feed.Data = nil
+	json.Unmarshal(w.Body.Bytes(), &feed)
+	if w.Code != http.StatusOK || len(feed.Data) != 0 {
+		t.Errorf("Expected an empty feed for a user who trusts nobody, got %d %d", w.Code, len(feed.Data))
+	}
+
+	// 5. Comments by blocked identities are hidden from signed-in readers
+	article := publish("alice", "Open thread")
+	for _, author := range []string{"bob", "spam"} {
+		if _, err := comments.Create(ctx, article.ID, &domain.CommentCreateRequest{Body: "Comment by " + author}, users[author].ID); err != nil {
+			t.Fatalf("Failed to comment: %v", err)
+		}
+	}
+	commenters := func(user, query string) []string {
+		t.Helper()
+		w := do(user, http.MethodGet, "/articles/"+article.ID+"/comments"+query, "")
+		var resp struct{ Data []domain.Comment }
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("Failed to list comments: %d %s", w.Code, w.Body.String())
+		}
+		var names []string
+		for _, comment := range resp.Data {
+			names = append(names, comment.Author)
+		}
+		return names
+	}
+	if names := commenters("me", ""); !slices.Equal(names, []string{"bob"}) {
+		t.Errorf("Expected spam's comment hidden from me, got %v", names)
+	}
+	if names := commenters("me", "?blocked=include"); len(names) != 2 {
+		t.Errorf("Expected blocked=include to show every comment, got %v", names)
+	}
+	if names := commenters("", ""); len(names) != 2 {
+		t.Errorf("Expected anonymous readers to see every comment, got %v", names)
+	}
+
+	// 6. Removing a mark changes the graph at once
+	if w := do("alice", http.MethodDelete, "/trust/edges/spam", ""); w.Code != http.StatusOK {
+		t.Errorf("Failed to remove mark: %d", w.Code)
+	}
+	if names := commenters("me", ""); len(names) != 2 {
+		t.Errorf("Expected spam's comment shown once unblocked, got %v", names)
+	}
+	if w := do("me", http.MethodPut, "/trust/edges/alice", `{"kind":"blocked","note":"changed my mind"}`); w.Code != http.StatusOK {
+		t.Fatalf("Failed to change mark: %d", w.Code)
+	}
+	if score, err := trust.Score(ctx, "me", "bob"); err != nil || score.Trust != 0 || score.Blocked {
+		t.Errorf("Expected bob out of reach once alice is blocked, got %+v (%v)", score, err)
+	}
+}
@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestChunkedUploadProgress(t *testing.T) {
+	log, _ := logger.New("error", "text")
+	limits := map[string]int64{"video/mp4": 64 * 1024}
+	uploads := service.NewUploadService(&mocks.MockMediaStore{}, 4096, func(mimeType string) (int64, bool) {
+		limit, ok := limits[mimeType]
+		return limit, ok
+	}, log)
+
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("x"), 40*1024)
+
+	// 1. Only the owner's reserved or started uploads can be followed
+	if _, _, err := uploads.Subscribe("alice", "upload-0001"); err != domain.ErrUploadNotFound {
+		t.Errorf("Expected ErrUploadNotFound for an unknown upload, got %v", err)
+	}
+	id := uploads.Reserve("alice")
+	if _, _, err := uploads.Subscribe("bob", id); err != domain.ErrUploadNotFound {
+		t.Errorf("Expected ErrUploadNotFound for another owner's reservation, got %v", err)
+	}
+	if _, err := uploads.Upload(ctx, "bob", id, bytes.NewReader(data), "video/mp4", 0); err != domain.ErrConflict {
+		t.Errorf("Expected ErrConflict for another owner's reservation, got %v", err)
+	}
+
+	// 2. A subscriber registered before the upload sees it through to the end
+	updates, unsubscribe, err := uploads.Subscribe("alice", id)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer unsubscribe()
+
+	result, err := uploads.Upload(ctx, "alice", id, bytes.NewReader(data), "video/mp4", int64(len(data)))
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if result.CID == "" || result.Sent != int64(len(data)) {
+		t.Errorf("Expected CID and %d bytes, got %q / %d", len(data), result.CID, result.Sent)
+	}
+
+	var last domain.UploadProgress
+	for update := range updates {
+		last = update
+	}
+	if last.State != domain.UploadDone || last.CID != result.CID || last.Percent != 100 {
+		t.Errorf("Expected final done update with CID, got %+v", last)
+	}
+
+	// 3. Finished uploads can't be reused or watched by other users
+	if _, err := uploads.Upload(ctx, "alice", id, bytes.NewReader(data), "video/mp4", 0); err != domain.ErrConflict {
+		t.Errorf("Expected ErrConflict for reused ID, got %v", err)
+	}
+	if _, _, err := uploads.Subscribe("bob", id); err != domain.ErrUploadNotFound {
+		t.Errorf("Expected ErrUploadNotFound for another owner, got %v", err)
+	}
+
+	// 4. Limits apply to declared and actual sizes, and unknown types are refused
+	big := strings.NewReader(strings.Repeat("x", 80*1024))
+	if _, err := uploads.Upload(ctx, "alice", "upload-0002", big, "video/mp4", 80*1024); err != domain.ErrUploadTooLarge {
+		t.Errorf("Expected ErrUploadTooLarge for declared size, got %v", err)
+	}
+	if _, err := uploads.Upload(ctx, "alice", "upload-0003", big, "video/mp4", 0); err != domain.ErrUploadTooLarge {
+		t.Errorf("Expected ErrUploadTooLarge for undeclared size, got %v", err)
+	}
+	if _, err := uploads.Upload(ctx, "alice", "upload-0004", bytes.NewReader(data), "application/x-msdownload", 0); err != domain.ErrUnsupportedMedia {
+		t.Errorf("Expected ErrUnsupportedMedia, got %v", err)
+	}
+}
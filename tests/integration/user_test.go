@@ -2,9 +2,11 @@ package integration
 
 import (
 	"context"
+	"crypto/ed25519"
 	"testing"
 
 	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
 )
 
 func TestUserFlow(t *testing.T) {
@@ -53,3 +55,102 @@ func TestUserFlow(t *testing.T) {
 		t.Errorf("Expected ErrUserAlreadyExists, got %v", err)
 	}
 }
+
+func TestUserIdentityExportImport(t *testing.T) {
+	source := SetupTestEnv(t)
+	defer source.Cleanup()
+	target := SetupTestEnv(t)
+	defer target.Cleanup()
+
+	ctx := context.Background()
+
+	user, err := source.UserService.Register(ctx, &domain.UserRegisterRequest{
+		Username: "wanderer",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	// 1. Export needs the user's password
+	if _, _, err := source.UserService.ExportIdentity(ctx, "wanderer", "wrong-password"); err != domain.ErrInvalidCredentials {
+		t.Fatalf("Expected invalid credentials, got %v", err)
+	}
+	exported, privateKey, err := source.UserService.ExportIdentity(ctx, "wanderer", "password123")
+	if err != nil {
+		t.Fatalf("Failed to export identity: %v", err)
+	}
+	if exported.PublicKey != crypto.PublicKeyToString(privateKey.Public().(ed25519.PublicKey)) {
+		t.Fatal("Exported private key does not match the user's public key")
+	}
+
+	// 2. Importing on another node keeps the identity, under a new password
+	imported, err := target.UserService.ImportIdentity(ctx, &domain.UserRegisterRequest{
+		Username: "wanderer",
+		Password: "another-password",
+	}, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to import identity: %v", err)
+	}
+	if imported.ID != user.ID || imported.PublicKey != user.PublicKey {
+		t.Errorf("Expected identity %s to move, got %s", user.ID, imported.ID)
+	}
+	if _, err := target.UserService.Login(ctx, &domain.UserLoginRequest{Username: "wanderer", Password: "another-password"}); err != nil {
+		t.Errorf("Failed to log in as the imported user: %v", err)
+	}
+
+	// 3. The key decides the user ID, so it can only be imported once
+	_, err = target.UserService.ImportIdentity(ctx, &domain.UserRegisterRequest{
+		Username: "wanderer2",
+		Password: "another-password",
+	}, privateKey)
+	if err != domain.ErrUserAlreadyExists {
+		t.Errorf("Expected a second import to be refused, got %v", err)
+	}
+}
+
+func TestUserAdministration(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+
+	for _, name := range []string{"zeta", "Alpha", "mid"} {
+		if _, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"}); err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+	}
+
+	// 1. Users are listed by username
+	users, err := env.UserService.ListUsers(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	if len(users) != 3 || users[0].Username != "Alpha" || users[2].Username != "zeta" {
+		t.Fatalf("Expected users ordered by username, got %+v", users)
+	}
+
+	// 2. A deactivated user, looked up by username, can't log in
+	deactivated, err := env.UserService.SetActive(ctx, "mid", false)
+	if err != nil {
+		t.Fatalf("Failed to deactivate user: %v", err)
+	}
+	if deactivated.IsActive {
+		t.Error("Expected user to be deactivated")
+	}
+	if _, err := env.UserService.Login(ctx, &domain.UserLoginRequest{Username: "mid", Password: "password123"}); err != domain.ErrUserNotActive {
+		t.Errorf("Expected a deactivated user's login to fail, got %v", err)
+	}
+
+	// 3. Reactivating by ID restores access
+	if _, err := env.UserService.SetActive(ctx, deactivated.ID, true); err != nil {
+		t.Fatalf("Failed to activate user: %v", err)
+	}
+	if _, err := env.UserService.Login(ctx, &domain.UserLoginRequest{Username: "mid", Password: "password123"}); err != nil {
+		t.Errorf("Failed to log in after reactivation: %v", err)
+	}
+
+	if _, err := env.UserService.SetActive(ctx, "nobody", false); err != domain.ErrUserNotFound {
+		t.Errorf("Expected user not found, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestArticleVerificationDetail(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	ctx := context.Background()
+	dag := mocks.NewMockDAGStore()
+	env.ArticleService.SetDAGStore(dag)
+
+	user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: "grace", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Draft",
+		Body:  "Original body",
+	}, user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+	for _, title := range []string{"Revised", "Final"} {
+		if article, err = env.ArticleService.Update(ctx, article.ID, &domain.ArticleUpdateRequest{Title: title}, user.ID); err != nil {
+			t.Fatalf("Failed to update article: %v", err)
+		}
+	}
+
+	// 1. A local author's article with an intact history
+	v, err := env.ArticleService.Verification(ctx, article.CID)
+	if err != nil {
+		t.Fatalf("Verification failed: %v", err)
+	}
+	if !v.Valid || v.Error != "" {
+		t.Errorf("Expected a valid signature, got error %q", v.Error)
+	}
+
+	publicKey, _ := crypto.PublicKeyFromString(user.PublicKey)
+	did, _ := p2p.CreateDID(publicKey)
+	if v.Signer.PublicKey != user.PublicKey || v.Signer.DID != did.String() || v.Signer.PeerID != user.ID {
+		t.Errorf("Expected the signer's key, DID %s and peer ID %s, got %+v", did, user.ID, v.Signer)
+	}
+	if !v.Signer.KnownUser || v.Signer.Username != "grace" || !v.Signer.AuthorMatches {
+		t.Errorf("Expected the key to belong to the byline, got %+v", v.Signer)
+	}
+
+	content, _ := article.GetSignableContent()
+	sum := sha256.Sum256(content)
+	if v.ContentHash != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected content hash %x, got %s", sum, v.ContentHash)
+	}
+
+	if v.Chain.Status != domain.ChainVerified || v.Chain.Revisions != 3 || v.Chain.Verified != 3 || v.Chain.Head != article.NodeCID {
+		t.Errorf("Expected a verified 3-revision chain from %s, got %+v", article.NodeCID, v.Chain)
+	}
+
+	// 2. A forged middle revision breaks the chain there
+	var head domain.ArticleNode
+	if err := dag.DagGet(ctx, article.NodeCID, &head); err != nil {
+		t.Fatalf("Failed to read head node: %v", err)
+	}
+	middle := head.Previous.CID
+	dag.Nodes[middle].(map[string]interface{})["title"] = "Forged"
+
+	v, _ = env.ArticleService.Verification(ctx, article.CID)
+	if !v.Valid || v.Chain.Status != domain.ChainBroken || v.Chain.BrokenAt != middle || v.Chain.Verified != 1 {
+		t.Errorf("Expected the chain broken at %s after one verified revision, got %+v", middle, v.Chain)
+	}
+	dag.Nodes[middle].(map[string]interface{})["title"] = "Revised"
+
+	// 3. A stored copy edited without re-signing fails, and no longer
+	// matches the head of its history
+	tampered := *article
+	tampered.Body = "Edited behind the author's back"
+	if err := env.ArticleRepo.Update(ctx, &tampered); err != nil {
+		t.Fatalf("Failed to tamper with article: %v", err)
+	}
+	v, _ = env.ArticleService.Verification(ctx, article.CID)
+	if v.Valid || v.Error == "" {
+		t.Errorf("Expected an invalid signature to be reported")
+	}
+	if v.Chain.Status != domain.ChainBroken || v.Chain.BrokenAt != article.NodeCID {
+		t.Errorf("Expected the chain broken at the head, got %+v", v.Chain)
+	}
+
+	// 4. An article from a key no user here holds, without a revision graph
+	keyPair, _ := crypto.GenerateKeyPair()
+	remote := &domain.Article{
+		ID:           "remote-article",
+		CID:          "QmRemoteVerification",
+		Title:        "From afar",
+		Body:         "Signed elsewhere",
+		Author:       "stranger",
+		AuthorPubKey: crypto.PublicKeyToString(keyPair.PublicKey),
+		Timestamp:    time.Now(),
+		Version:      1,
+	}
+	if err := auth.NewArticleSigner().SignArticle(remote, keyPair.PrivateKey); err != nil {
+		t.Fatalf("Failed to sign article: %v", err)
+	}
+	if err := env.ArticleService.HandleIncomingArticle(remote); err != nil {
+		t.Fatalf("Failed to store remote article: %v", err)
+	}
+
+	v, err = env.ArticleService.Verification(ctx, remote.CID)
+	if err != nil {
+		t.Fatalf("Verification failed: %v", err)
+	}
+	if !v.Valid || v.Signer.KnownUser || v.Signer.Username != "" || v.Signer.PeerID == "" {
+		t.Errorf("Expected a valid article from an unknown signer with a peer ID, got %+v", v.Signer)
+	}
+	if v.Chain.Status != domain.ChainUnavailable {
+		t.Errorf("Expected no revision chain, got %s", v.Chain.Status)
+	}
+
+	if _, err := env.ArticleService.Verification(ctx, "QmMissing"); !errors.Is(err, domain.ErrArticleNotFound) {
+		t.Errorf("Expected ErrArticleNotFound, got %v", err)
+	}
+}
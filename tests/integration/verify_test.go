@@ -0,0 +1,176 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+)
+
+// fakeBlockstore answers kubo's block/get and trustless gateway raw block
+// requests from an in-memory map, returning whatever bytes it holds
+// whether or not they match the CID
+type fakeBlockstore struct {
+	mu     sync.Mutex
+	blocks map[string][]byte
+}
+
+func (f *fakeBlockstore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var key string
+	switch {
+	case r.URL.Path == "/api/v0/block/get":
+		key = r.URL.Query().Get("arg")
+	case strings.HasPrefix(r.URL.Path, "/ipfs/") && r.URL.Query().Get("format") == "raw":
+		key = strings.TrimPrefix(r.URL.Path, "/ipfs/")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	data, ok := f.blocks[key]
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"Message":"block not found","Code":0,"Type":"error"}`))
+		return
+	}
+	w.Write(data)
+}
+
+func (f *fakeBlockstore) put(t *testing.T, prefix cid.Prefix, data []byte) cid.Cid {
+	t.Helper()
+	id, err := prefix.Sum(data)
+	if err != nil {
+		t.Fatalf("Failed to hash block: %v", err)
+	}
+	f.mu.Lock()
+	f.blocks[id.String()] = data
+	f.mu.Unlock()
+	return id
+}
+
+// pbNode encodes a dag-pb node holding a UnixFS Data message
+func pbNode(fsType uint64, names []string, links []cid.Cid) []byte {
+	var fsData []byte
+	fsData = protowire.AppendTag(fsData, 1, protowire.VarintType)
+	fsData = protowire.AppendVarint(fsData, fsType)
+
+	var node []byte
+	for i, link := range links {
+		var l []byte
+		l = protowire.AppendTag(l, 1, protowire.BytesType)
+		l = protowire.AppendBytes(l, link.Bytes())
+		l = protowire.AppendTag(l, 2, protowire.BytesType)
+		l = protowire.AppendString(l, names[i])
+		node = protowire.AppendTag(node, 2, protowire.BytesType)
+		node = protowire.AppendBytes(node, l)
+	}
+	node = protowire.AppendTag(node, 1, protowire.BytesType)
+	return protowire.AppendBytes(node, fsData)
+}
+
+func TestCatVerifiesCID(t *testing.T) {
+	store := &fakeBlockstore{blocks: make(map[string][]byte)}
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	ctx := context.Background()
+	log, _ := logger.New("error", "text")
+	client := ipfs.NewClient(server.URL, 5*time.Second, false, log)
+
+	rawPrefix := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: 0x12, MhLength: -1}
+	pbPrefix := cid.Prefix{Version: 0, Codec: cid.DagProtobuf, MhType: 0x12, MhLength: -1}
+
+	first := []byte(`{"title":"Verified",`)
+	second := []byte(`"body":"block by block"}`)
+	leafA := store.put(t, rawPrefix, first)
+	leafB := store.put(t, rawPrefix, second)
+	file := store.put(t, pbPrefix, pbNode(2, []string{"", ""}, []cid.Cid{leafA, leafB}))
+	dir := store.put(t, pbPrefix, pbNode(1, []string{"article.json"}, []cid.Cid{file}))
+
+	// 1. Raw leaves, chunked files and paths through directories all verify
+	if data, err := client.Cat(ctx, leafA.String()); err != nil || !bytes.Equal(data, first) {
+		t.Fatalf("Cat of raw block returned %q (%v)", data, err)
+	}
+	want := append(append([]byte{}, first...), second...)
+	if data, err := client.Cat(ctx, file.String()); err != nil || !bytes.Equal(data, want) {
+		t.Fatalf("Cat of chunked file returned %q (%v)", data, err)
+	}
+	if data, err := client.Cat(ctx, "/ipfs/"+dir.String()+"/article.json"); err != nil || !bytes.Equal(data, want) {
+		t.Fatalf("Cat through directory returned %q (%v)", data, err)
+	}
+
+	// 2. A tampered leaf anywhere in the DAG is an integrity error
+	store.mu.Lock()
+	store.blocks[leafB.String()] = []byte(`"body":"tampered in transit"}`)
+	store.mu.Unlock()
+
+	if _, err := client.Cat(ctx, file.String()); !errors.Is(err, domain.ErrCIDMismatch) {
+		t.Errorf("Expected ErrCIDMismatch for tampered leaf, got %v", err)
+	}
+	if _, err := client.Cat(ctx, dir.String()+"/article.json"); !errors.Is(err, domain.ErrCIDMismatch) {
+		t.Errorf("Expected ErrCIDMismatch through directory, got %v", err)
+	}
+
+	// 3. Unreachable blocks and malformed CIDs are not reported as tampering
+	store.mu.Lock()
+	delete(store.blocks, leafA.String())
+	store.mu.Unlock()
+	if _, err := client.Cat(ctx, leafA.String()); err == nil || errors.Is(err, domain.ErrCIDMismatch) {
+		t.Errorf("Expected a fetch error for missing block, got %v", err)
+	}
+	if _, err := client.Cat(ctx, "not-a-cid"); !errors.Is(err, domain.ErrInvalidCID) {
+		t.Errorf("Expected ErrInvalidCID, got %v", err)
+	}
+}
+
+func TestGatewayCatVerifiesCID(t *testing.T) {
+	store := &fakeBlockstore{blocks: make(map[string][]byte)}
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	ctx := context.Background()
+	gateway := ipfs.NewGateway(server.URL+"/", 5*time.Second)
+
+	rawPrefix := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: 0x12, MhLength: -1}
+	pbPrefix := cid.Prefix{Version: 0, Codec: cid.DagProtobuf, MhType: 0x12, MhLength: -1}
+
+	first := []byte(`{"title":"Through a gateway",`)
+	second := []byte(`"body":"still verified"}`)
+	leafA := store.put(t, rawPrefix, first)
+	leafB := store.put(t, rawPrefix, second)
+	file := store.put(t, pbPrefix, pbNode(2, []string{"", ""}, []cid.Cid{leafA, leafB}))
+
+	// 1. Files are read from raw blocks, with no daemon involved
+	want := append(append([]byte{}, first...), second...)
+	if data, err := gateway.Cat(ctx, file.String()); err != nil || !bytes.Equal(data, want) {
+		t.Fatalf("Gateway Cat returned %q (%v)", data, err)
+	}
+
+	// 2. The gateway isn't trusted: a substituted block is caught
+	store.mu.Lock()
+	store.blocks[leafA.String()] = []byte(`{"title":"Rewritten by the gateway",`)
+	store.mu.Unlock()
+	if _, err := gateway.Cat(ctx, file.String()); !errors.Is(err, domain.ErrCIDMismatch) {
+		t.Errorf("Expected ErrCIDMismatch from a lying gateway, got %v", err)
+	}
+
+	// 3. Missing blocks are fetch errors, not tampering
+	missing, _ := rawPrefix.Sum([]byte("never stored"))
+	if _, err := gateway.Cat(ctx, missing.String()); err == nil || errors.Is(err, domain.ErrCIDMismatch) {
+		t.Errorf("Expected a fetch error for a missing block, got %v", err)
+	}
+}
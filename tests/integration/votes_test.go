@@ -0,0 +1,240 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/api/handlers"
+	"github.com/amiyamandal-dev/newsp2p/internal/api/middleware"
+	"github.com/amiyamandal-dev/newsp2p/internal/auth"
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+	"github.com/amiyamandal-dev/newsp2p/internal/events"
+	"github.com/amiyamandal-dev/newsp2p/internal/p2p"
+	"github.com/amiyamandal-dev/newsp2p/internal/search"
+	"github.com/amiyamandal-dev/newsp2p/internal/service"
+	"github.com/amiyamandal-dev/newsp2p/pkg/crypto"
+	"github.com/amiyamandal-dev/newsp2p/pkg/logger"
+	"github.com/amiyamandal-dev/newsp2p/tests/mocks"
+)
+
+func TestArticleVoting(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	signer := auth.NewArticleSigner()
+	votes := service.NewVoteService(env.ArticleRepo, env.UserRepo, signer, log)
+	broadcaster := mocks.NewMockVoteBroadcaster()
+	reputation := p2p.NewReputationSystem(log)
+	bus := events.NewBus(log)
+	votes.SetBroadcaster(broadcaster)
+	votes.SetReputation(reputation)
+	votes.SetEventPublisher(bus)
+	tallies, stop := bus.Subscribe(domain.EventVoteTally)
+	defer stop()
+
+	h := handlers.NewVoteHandler(votes, log)
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/articles/:cid/votes", h.Tally)
+	engine.POST("/articles/:cid/vote", middleware.AuthMiddleware(env.JWTManager), h.Vote)
+
+	ctx := context.Background()
+	tokens := make(map[string]string)
+	users := make(map[string]*domain.UserResponse)
+	for _, name := range []string{"writer", "reader"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		users[name] = user
+		tokens[name], _, _ = env.JWTManager.GenerateAccessToken(user.ID, user.Username, user.Email)
+	}
+	article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+		Title: "Local elections", Body: "Turnout was higher than expected.", Category: "politics",
+	}, users["writer"].ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to create article: %v", err)
+	}
+
+	vote := func(user, ref, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/articles/"+ref+"/vote", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokens[user])
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	tally := func(ref string) domain.VoteTally {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/"+ref+"/votes", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Tally failed: %d %s", w.Code, w.Body.String())
+		}
+		var resp struct{ Data domain.VoteTally }
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Data
+	}
+
+	// 1. Bad votes, unknown articles and self-votes are rejected
+	if w := vote("reader", article.ID, `{"vote":0}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a zero vote, got %d", w.Code)
+	}
+	if w := vote("reader", "QmMissing", `{"vote":1}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown article, got %d", w.Code)
+	}
+	if w := vote("writer", article.ID, `{"vote":1}`); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for voting on your own article, got %d", w.Code)
+	}
+
+	// 2. A vote is signed by the voter, counted, and broadcast
+	w := vote("reader", article.ID, `{"vote":1,"reason":"well sourced"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Vote failed: %d %s", w.Code, w.Body.String())
+	}
+	if got := tally(article.CID); got.Up != 1 || got.Score != 1 {
+		t.Errorf("Expected one up vote, got %+v", got)
+	}
+	select {
+	case event := <-tallies:
+		if event.Data.(domain.VoteTally).Up != 1 {
+			t.Errorf("Unexpected tally event: %+v", event.Data)
+		}
+	case <-time.After(time.Second):
+		t.Error("No tally event published")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(broadcaster.Votes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	sent := broadcaster.Votes()
+	if len(sent) != 1 || sent[0].VoterDID != users["reader"].PublicKey || sent[0].Reason != "well sourced" {
+		t.Fatalf("Unexpected broadcast votes: %+v", sent)
+	}
+	if err := signer.VerifyVote(&sent[0]); err != nil {
+		t.Errorf("Broadcast vote does not verify: %v", err)
+	}
+	if score := reputation.GetScore("writer"); score.UpVotes != 1 || score.Score != p2p.InitialScore+p2p.UpVoteScore {
+		t.Errorf("Expected the author credited one up vote, got %+v", score)
+	}
+
+	// 3. Changing a vote replaces it, repeating it changes nothing
+	vote("reader", article.CID, `{"vote":-1}`)
+	vote("reader", article.CID, `{"vote":-1}`)
+	if got := tally(article.ID); got.Up != 0 || got.Down != 1 || got.Score != -1 {
+		t.Errorf("Expected the vote to flip, got %+v", got)
+	}
+	if score := reputation.GetScore("writer"); score.UpVotes != 0 || score.DownVotes != 1 || score.Score != p2p.InitialScore+p2p.DownVoteScore {
+		t.Errorf("Expected the author's reputation to reflect only the latest vote, got %+v", score)
+	}
+
+	// 4. Peer votes count only with a valid voter signature
+	keys, _ := crypto.GenerateKeyPair()
+	remote := &domain.Vote{ArticleID: article.ID, VoterDID: crypto.PublicKeyToString(keys.PublicKey), Value: 1, Timestamp: time.Now().Unix()}
+	signer.SignVote(remote, keys.PrivateKey)
+
+	forged := *remote
+	forged.Value = -1
+	if err := votes.HandleIncomingVote(ctx, &forged); err == nil {
+		t.Error("Expected a tampered vote to be rejected")
+	}
+	if err := votes.HandleIncomingVote(ctx, remote); err != nil {
+		t.Fatalf("Failed to apply a peer vote: %v", err)
+	}
+	if got := tally(article.ID); got.Up != 1 || got.Down != 1 {
+		t.Errorf("Expected the peer vote counted, got %+v", got)
+	}
+}
+
+func TestReputationWeightedVotes(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	log, _ := logger.New("error", "text")
+	signer := auth.NewArticleSigner()
+	votes := service.NewVoteService(env.ArticleRepo, env.UserRepo, signer, log)
+	reputation := p2p.NewReputationSystem(log)
+	votes.SetReputation(reputation)
+
+	ctx := context.Background()
+	users := make(map[string]*domain.UserResponse)
+	for _, name := range []string{"writer", "veteran", "newcomer"} {
+		user, err := env.UserService.Register(ctx, &domain.UserRegisterRequest{Username: name, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+		users[name] = user
+	}
+	var articles []*domain.Article
+	for _, title := range []string{"Harbour reopens", "Harbour closes"} {
+		article, err := env.ArticleService.Create(ctx, &domain.ArticleCreateRequest{
+			Title: title, Body: "The harbour authority announced the change.", Category: "local",
+		}, users["writer"].ID, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Failed to create article: %v", err)
+		}
+		articles = append(articles, article)
+	}
+
+	// The index ranks most-voted by the weighted score
+	index := setupSearchIndex(t)
+	index.SetSignalProvider(search.SignalFunc(func(a *domain.Article) search.Signals {
+		return search.Signals{Votes: votes.WeightedScore(a.ID)}
+	}))
+	votes.SetIndexer(index)
+
+	// The veteran has posted five articles: 60, so their vote counts 1.2
+	for i := 0; i < 5; i++ {
+		reputation.RecordEvent(&p2p.ReputationEvent{DID: "veteran", EventType: p2p.EventArticlePost})
+	}
+	if w := service.VoteWeight(reputation.GetScore("veteran")); w != 1.2 {
+		t.Errorf("Expected a 60 reputation to weigh 1.2, got %v", w)
+	}
+	if w := service.VoteWeight(reputation.GetScore("newcomer")); w != service.NewVoterWeight {
+		t.Errorf("Expected a brand-new DID discounted, got %v", w)
+	}
+
+	// 1. Local votes are weighted by the voter's reputation
+	cast := func(user string, article *domain.Article, value int) domain.VoteTally {
+		_, tally, err := votes.Vote(ctx, article.ID, users[user].ID, &domain.VoteRequest{Vote: value})
+		if err != nil {
+			t.Fatalf("Vote failed: %v", err)
+		}
+		return tally
+	}
+	cast("veteran", articles[0], 1)
+	if tally := cast("newcomer", articles[0], -1); tally.Score != 0 || tally.Weighted != 0.95 {
+		t.Errorf("Expected the veteran to outweigh the newcomer, got %+v", tally)
+	}
+
+	// 2. Remote voters this node can't name count as brand-new
+	for i := 0; i < 3; i++ {
+		keys, _ := crypto.GenerateKeyPair()
+		remote := &domain.Vote{ArticleID: articles[1].ID, VoterDID: crypto.PublicKeyToString(keys.PublicKey), Value: 1, Timestamp: time.Now().Unix()}
+		signer.SignVote(remote, keys.PrivateKey)
+		if err := votes.HandleIncomingVote(ctx, remote); err != nil {
+			t.Fatalf("Failed to apply a peer vote: %v", err)
+		}
+	}
+	tally, err := votes.Tally(ctx, articles[1].CID)
+	if err != nil || tally.Score != 3 || tally.Weighted != 0.75 {
+		t.Errorf("Expected three discounted up votes, got %+v, %v", tally, err)
+	}
+
+	// 3. Search ranks by the weighted score, not the raw count
+	result, err := index.Search(ctx, &search.SearchQuery{Query: "harbour", SortBy: search.SortMostVoted})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := strings.Join(result.IDs, ","); got != articles[0].ID+","+articles[1].ID {
+		t.Errorf("Expected the veteran-backed article first, got %s", got)
+	}
+}
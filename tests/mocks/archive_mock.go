@@ -0,0 +1,123 @@
+package mocks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+)
+
+// MockArchiveStore implements service.ArchiveStore on top of MockIPFSClient.
+// Directories are kept as path->CID maps and "CAR" files are JSON bundles
+// of every block reachable from their roots.
+type MockArchiveStore struct {
+	*MockIPFSClient
+	dirs map[string]map[string]string
+}
+
+// mockCAR is the serialized form ExportCAR writes
+type mockCAR struct {
+	Roots  []string                     `json:"roots"`
+	Blocks map[string][]byte            `json:"blocks"`
+	Dirs   map[string]map[string]string `json:"dirs"`
+}
+
+func NewMockArchiveStore(client *MockIPFSClient) *MockArchiveStore {
+	return &MockArchiveStore{
+		MockIPFSClient: client,
+		dirs:           make(map[string]map[string]string),
+	}
+}
+
+func (m *MockArchiveStore) BuildDirectory(ctx context.Context, entries []ipfs.DirectoryEntry) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dir := make(map[string]string, len(entries))
+	hash := sha256.New()
+	for _, entry := range entries {
+		if _, ok := m.Storage[entry.CID]; !ok {
+			return "", errors.New("not found: " + entry.CID)
+		}
+		dir[entry.Path] = entry.CID
+		hash.Write([]byte(entry.Path + "=" + entry.CID + ";"))
+	}
+
+	root := "QmMockDir" + hex.EncodeToString(hash.Sum(nil))[:16]
+	m.dirs[root] = dir
+	return root, nil
+}
+
+// Cat resolves "<root>/<path>" through directories, else falls back to a plain CID
+func (m *MockArchiveStore) Cat(ctx context.Context, cid string) ([]byte, error) {
+	root, rel, ok := strings.Cut(cid, "/")
+	if !ok {
+		return m.MockIPFSClient.Cat(ctx, cid)
+	}
+
+	m.mu.Lock()
+	target, exists := m.dirs[root][rel]
+	m.mu.Unlock()
+	if !exists {
+		return nil, errors.New("not found")
+	}
+	return m.MockIPFSClient.Cat(ctx, target)
+}
+
+// Resolve returns the CID "<root>/<path>" links to, or a plain CID as is
+func (m *MockArchiveStore) Resolve(ctx context.Context, ref string) (string, error) {
+	root, rel, ok := strings.Cut(ref, "/")
+	if !ok {
+		return ref, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	target, exists := m.dirs[root][rel]
+	if !exists {
+		return "", errors.New("not found")
+	}
+	return target, nil
+}
+
+func (m *MockArchiveStore) ExportCAR(ctx context.Context, root string, w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dir, ok := m.dirs[root]
+	if !ok {
+		return errors.New("not found: " + root)
+	}
+
+	car := mockCAR{
+		Roots:  []string{root},
+		Blocks: make(map[string][]byte),
+		Dirs:   map[string]map[string]string{root: dir},
+	}
+	for _, cid := range dir {
+		car.Blocks[cid] = m.Storage[cid]
+	}
+	return json.NewEncoder(w).Encode(car)
+}
+
+func (m *MockArchiveStore) ImportCAR(ctx context.Context, r io.Reader) ([]string, error) {
+	var car mockCAR
+	if err := json.NewDecoder(r).Decode(&car); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for cid, data := range car.Blocks {
+		m.Storage[cid] = data
+	}
+	for root, dir := range car.Dirs {
+		m.dirs[root] = dir
+	}
+	return car.Roots, nil
+}
@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"sync"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// SharedArticle is a sealed article broadcast with the CID it is stored
+// under
+type SharedArticle struct {
+	CID    string
+	Sealed domain.SealedArticle
+}
+
+// MockCircleBroadcaster implements service.CircleBroadcaster by recording
+// every key message and sealed article
+type MockCircleBroadcaster struct {
+	mu       sync.Mutex
+	keys     []domain.CircleKeyMessage
+	articles []SharedArticle
+}
+
+func NewMockCircleBroadcaster() *MockCircleBroadcaster {
+	return &MockCircleBroadcaster{}
+}
+
+func (m *MockCircleBroadcaster) BroadcastCircleKeys(msg *domain.CircleKeyMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = append(m.keys, *msg)
+	return nil
+}
+
+func (m *MockCircleBroadcaster) BroadcastSealedArticle(cid string, sealed *domain.SealedArticle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.articles = append(m.articles, SharedArticle{CID: cid, Sealed: *sealed})
+	return nil
+}
+
+// Keys returns the key messages broadcast so far
+func (m *MockCircleBroadcaster) Keys() []domain.CircleKeyMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.CircleKeyMessage(nil), m.keys...)
+}
+
+// Articles returns the sealed articles broadcast so far
+func (m *MockCircleBroadcaster) Articles() []SharedArticle {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SharedArticle(nil), m.articles...)
+}
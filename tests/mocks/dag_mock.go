@@ -0,0 +1,89 @@
+package mocks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MockDAGStore implements service.DAGStore in memory. Nodes are kept as
+// decoded JSON and paths resolve through {"/": cid} links like dag/get.
+type MockDAGStore struct {
+	mu    sync.Mutex
+	Nodes map[string]interface{}
+}
+
+func NewMockDAGStore() *MockDAGStore {
+	return &MockDAGStore{Nodes: make(map[string]interface{})}
+}
+
+func (m *MockDAGStore) DagPut(ctx context.Context, node interface{}) (string, error) {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+	cid := "bafyMock" + hex.EncodeToString(hash[:])[:24]
+
+	m.mu.Lock()
+	m.Nodes[cid] = decoded
+	m.mu.Unlock()
+	return cid, nil
+}
+
+func (m *MockDAGStore) DagGet(ctx context.Context, ref string, out interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	segments := strings.Split(ref, "/")
+	value, ok := m.Nodes[segments[0]]
+	if !ok {
+		return errors.New("not found: " + segments[0])
+	}
+
+	for _, segment := range segments[1:] {
+		value = m.follow(value)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if value, ok = v[segment]; !ok {
+				return errors.New("no such field: " + segment)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return errors.New("no such index: " + segment)
+			}
+			value = v[i]
+		default:
+			return errors.New("cannot traverse into " + segment)
+		}
+	}
+
+	data, err := json.Marshal(m.follow(value))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// follow resolves a link value to the node it points at
+func (m *MockDAGStore) follow(value interface{}) interface{} {
+	if link, ok := value.(map[string]interface{}); ok && len(link) == 1 {
+		if cid, ok := link["/"].(string); ok {
+			if node, ok := m.Nodes[cid]; ok {
+				return node
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"sync"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// MockEndorsementBroadcaster implements service.EndorsementBroadcaster by
+// recording every endorsement
+type MockEndorsementBroadcaster struct {
+	mu           sync.Mutex
+	endorsements []domain.Endorsement
+}
+
+func NewMockEndorsementBroadcaster() *MockEndorsementBroadcaster {
+	return &MockEndorsementBroadcaster{}
+}
+
+func (m *MockEndorsementBroadcaster) BroadcastEndorsement(endorsement *domain.Endorsement) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endorsements = append(m.endorsements, *endorsement)
+	return nil
+}
+
+// Endorsements returns the endorsements broadcast so far
+func (m *MockEndorsementBroadcaster) Endorsements() []domain.Endorsement {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.Endorsement(nil), m.endorsements...)
+}
@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"sync"
 )
@@ -22,17 +24,23 @@ func (m *MockIPFSClient) Add(ctx context.Context, data []byte) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Simple mock CID generation (not real multihash)
-	// In real tests we might want something deterministic
-	cid := "QmMockCID" + string(data)[:5] // Use first 5 bytes for variance
-	if len(data) > 5 {
-		cid = "QmMockCID" + string(data[:5])
-	}
-	
+	// Mock CIDs are derived from the content (not a real multihash), so
+	// distinct content gets distinct CIDs as it would on IPFS
+	cid := mockCID(data)
 	m.Storage[cid] = data
 	return cid, nil
 }
 
+// Hash returns the CID Add would give data without storing it
+func (m *MockIPFSClient) Hash(ctx context.Context, data []byte) (string, error) {
+	return mockCID(data), nil
+}
+
+func mockCID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "QmMockCID" + hex.EncodeToString(sum[:16])
+}
+
 func (m *MockIPFSClient) Cat(ctx context.Context, cid string) ([]byte, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
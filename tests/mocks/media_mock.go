@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/ipfs"
+)
+
+// MockMediaStore implements service.MediaStore, reading the stream in
+// chunkSize pieces and reporting progress like the IPFS client does
+type MockMediaStore struct{}
+
+func (m *MockMediaStore) AddStream(ctx context.Context, r io.Reader, chunkSize int64, progress ipfs.ProgressFunc) (string, error) {
+	hash := sha256.New()
+	buf := make([]byte, chunkSize)
+	var sent int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := r.Read(buf)
+		if n > 0 {
+			hash.Write(buf[:n])
+			sent += int64(n)
+			if progress != nil {
+				progress(sent)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "bafyMedia" + hex.EncodeToString(hash.Sum(nil))[:24], nil
+}
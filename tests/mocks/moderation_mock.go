@@ -0,0 +1,35 @@
+package mocks
+
+import "sync"
+
+// ModerationAction is one action sent through MockModerationBroadcaster
+type ModerationAction struct {
+	ArticleID string
+	Action    string
+	Reason    string
+}
+
+// MockModerationBroadcaster implements service.ModerationBroadcaster by
+// recording every action
+type MockModerationBroadcaster struct {
+	mu      sync.Mutex
+	actions []ModerationAction
+}
+
+func NewMockModerationBroadcaster() *MockModerationBroadcaster {
+	return &MockModerationBroadcaster{}
+}
+
+func (m *MockModerationBroadcaster) BroadcastModerationAction(articleID, action, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.actions = append(m.actions, ModerationAction{ArticleID: articleID, Action: action, Reason: reason})
+	return nil
+}
+
+// Actions returns the actions broadcast so far
+func (m *MockModerationBroadcaster) Actions() []ModerationAction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ModerationAction(nil), m.actions...)
+}
@@ -0,0 +1,87 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// MockPinStore implements service.PinStore and service.GCStore with a
+// switchable outage
+type MockPinStore struct {
+	mu        sync.Mutex
+	Pinned    map[string]bool
+	Down      bool
+	garbage   int // unpinned blocks not yet collected
+	collected int
+}
+
+func NewMockPinStore() *MockPinStore {
+	return &MockPinStore{Pinned: make(map[string]bool)}
+}
+
+func (m *MockPinStore) Pin(ctx context.Context, cid string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Down {
+		return errors.New("connection refused")
+	}
+	m.Pinned[cid] = true
+	return nil
+}
+
+func (m *MockPinStore) PinnedCIDs(ctx context.Context) (map[string]bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Down {
+		return nil, errors.New("connection refused")
+	}
+	pinned := make(map[string]bool, len(m.Pinned))
+	for cid := range m.Pinned {
+		pinned[cid] = true
+	}
+	return pinned, nil
+}
+
+// SetDown simulates the IPFS daemon going away or coming back
+func (m *MockPinStore) SetDown(down bool) {
+	m.mu.Lock()
+	m.Down = down
+	m.mu.Unlock()
+}
+
+// Drop removes a pin behind the ledger's back, as a manual unpin or GC would
+func (m *MockPinStore) Drop(cid string) {
+	m.mu.Lock()
+	delete(m.Pinned, cid)
+	m.mu.Unlock()
+}
+
+func (m *MockPinStore) Unpin(ctx context.Context, cid string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Down {
+		return errors.New("connection refused")
+	}
+	if m.Pinned[cid] {
+		delete(m.Pinned, cid)
+		m.garbage++
+	}
+	return nil
+}
+
+// RepoGC pretends every unpinned block was collected, 1KiB each
+func (m *MockPinStore) RepoGC(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := m.garbage
+	m.collected += removed
+	m.garbage = 0
+	return removed, nil
+}
+
+func (m *MockPinStore) RepoSize(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return uint64(1<<20 - m.collected*1024), nil
+}
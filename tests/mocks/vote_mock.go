@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"sync"
+
+	"github.com/amiyamandal-dev/newsp2p/internal/domain"
+)
+
+// MockVoteBroadcaster implements service.VoteBroadcaster by recording
+// every vote
+type MockVoteBroadcaster struct {
+	mu    sync.Mutex
+	votes []domain.Vote
+}
+
+func NewMockVoteBroadcaster() *MockVoteBroadcaster {
+	return &MockVoteBroadcaster{}
+}
+
+func (m *MockVoteBroadcaster) BroadcastVote(vote *domain.Vote) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.votes = append(m.votes, *vote)
+	return nil
+}
+
+// Votes returns the votes broadcast so far
+func (m *MockVoteBroadcaster) Votes() []domain.Vote {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.Vote(nil), m.votes...)
+}